@@ -1,13 +1,31 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/annenpolka/cclog/internal/cli"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"golang.org/x/term"
 )
 
+// interruptedExitCode is the exit status after Ctrl-C, like a shell reports a command killed by SIGINT
+const interruptedExitCode = 130
+
 func main() {
+	// The first Ctrl-C cancels the running command so that it can clean up; a second one exits at once.
+	// resume catches interrupts itself while claude runs in the terminal.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	config, err := cli.ParseArgs(os.Args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -16,12 +34,12 @@ func main() {
 	}
 
 	if config.ShowHelp {
-		fmt.Println(cli.GetHelpText())
+		fmt.Println(cli.GetCommandHelpText(config.Command))
 		return
 	}
 
 	// Show title when starting cclog
-	if !config.ShowHelp && !config.TUIMode {
+	if showBanner(config, stdoutIsTerminal()) {
 		fmt.Println("cclog - Claude Conversation Log Converter")
 		fmt.Println("=========================================")
 		fmt.Println()
@@ -49,10 +67,9 @@ func main() {
 			newConfig.IsDirectory = true
 		}
 
-		output, err := cli.RunCommand(newConfig)
+		output, err := runCommand(ctx, newConfig)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 
 		// Print output
@@ -64,20 +81,57 @@ func main() {
 		return
 	}
 
-	output, err := cli.RunCommand(config)
+	output, err := runCommand(ctx, config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Print(output) // Validate reports the deviations behind its error, compress the sessions it did compress
+		exitWithError(err)
 	}
 
-	// Only print to stdout if no output file was specified
-	if config.OutputPath == "" {
+	// Only print to stdout if no output file was specified (export, sync, kb and code print their summary)
+	if config.OutputPath == "" || config.Export || config.Sync || config.KB || config.Code {
 		fmt.Print(output)
 	} else {
 		fmt.Printf("Output written to: %s\n", config.OutputPath)
 	}
 }
 
+// stdoutIsTerminal reports whether stdout is a terminal rather than a pipe or file
+var stdoutIsTerminal = func() bool { return term.IsTerminal(int(os.Stdout.Fd())) }
+
+// showBanner reports whether the title is printed before the output of config: only for markdown read
+// in a terminal, since piped output and the other formats (csv, context, eml, graphs, chat messages)
+// must start with their own content to be parsed or pasted
+func showBanner(config cli.Config, terminal bool) bool {
+	if !terminal || config.Graph {
+		return false
+	}
+	if config.Format != "" && config.Format != formatter.FormatMarkdown {
+		return false
+	}
+	return !config.ShowHelp && !config.TUIMode && !config.SelfUpdate && !config.Resume && !config.ResumeCmd && !config.Replay && !config.Diff && !config.Digest && !config.Summarize && !config.Code && !config.Edits && !config.Serve && !config.Gist && !config.List && !config.Doctor && !config.Validate && !config.ShowVersion
+}
+
+// runCommand runs the command, streaming converted markdown to stdout as it is formatted
+func runCommand(ctx context.Context, config cli.Config) (string, error) {
+	stdout := bufio.NewWriter(os.Stdout)
+	config.Stdout = stdout
+	output, err := cli.RunCommandContext(ctx, config)
+	if flushErr := stdout.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("failed to write output: %w", flushErr)
+	}
+	return output, err
+}
+
+// exitWithError reports the error of a command and exits, with status 130 if it was interrupted
+func exitWithError(err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Interrupted: %v\n", err)
+		os.Exit(interruptedExitCode)
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
 // shouldSetDirectoryFlag checks if the given path is a directory
 func shouldSetDirectoryFlag(path string) bool {
 	stat, err := os.Stat(path)
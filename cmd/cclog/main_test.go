@@ -1,9 +1,18 @@
 package main
 
 import (
+	"bufio"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/annenpolka/cclog/internal/cli"
 )
 
 func TestDirectorySelectionHandling(t *testing.T) {
@@ -73,3 +82,175 @@ func TestShouldSetDirectoryFlag_NonExistent(t *testing.T) {
 		t.Errorf("Expected shouldSetDirectoryFlag to return false for non-existent path")
 	}
 }
+
+func TestImportsUseModulePath(t *testing.T) {
+	// Every in-repo import must use the full module path so that
+	// `go install github.com/annenpolka/cclog/cmd/cclog@latest` keeps working
+	root := filepath.Join("..", "..")
+
+	modFile, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatalf("Failed to open go.mod: %v", err)
+	}
+	defer modFile.Close()
+
+	var modulePath string
+	scanner := bufio.NewScanner(modFile)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+			modulePath = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+			break
+		}
+	}
+	if modulePath != "github.com/annenpolka/cclog" {
+		t.Fatalf("Expected module path github.com/annenpolka/cclog, got %q", modulePath)
+	}
+
+	// Bare prefixes that would indicate an import bypassing the module path
+	barePrefixes := []string{"cclog/", "internal/", "pkg/", "cmd/"}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, imp := range file.Imports {
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+			for _, prefix := range barePrefixes {
+				if strings.HasPrefix(importPath, prefix) {
+					t.Errorf("%s imports %q; use %s/... instead", path, importPath, modulePath)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk source tree: %v", err)
+	}
+}
+
+// TestMain lets a test re-run this binary as cclog: with CCLOG_MAIN_ARGS set it runs main with those
+// arguments, tab separated, as if stdout were a terminal
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv("CCLOG_MAIN_ARGS"); ok {
+		stdoutIsTerminal = func() bool { return true }
+		os.Args = append([]string{"cclog"}, strings.Split(args, "\t")...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs cclog with args in a child process and returns its stdout
+func runMain(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), "CCLOG_MAIN_ARGS="+strings.Join(args, "\t"))
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("cclog %s failed: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return string(out)
+}
+
+func writeMainTestSession(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1","sessionId":"s1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"hi"}]},"uuid":"a1","sessionId":"s1","timestamp":"2025-07-06T05:01:30.618Z"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+func TestMainCSVStartsWithHeader(t *testing.T) {
+	output := runMain(t, writeMainTestSession(t), "--format", "csv")
+
+	if !strings.HasPrefix(output, "session,timestamp,role,type,tool,content_length,uuid\n") {
+		t.Errorf("Expected stdout to begin with the CSV header, got:\n%s", output)
+	}
+}
+
+func TestMainGraphStartsWithDot(t *testing.T) {
+	output := runMain(t, "graph", writeMainTestSession(t))
+
+	if !strings.HasPrefix(output, "digraph") {
+		t.Errorf("Expected stdout to begin with the dot graph, got:\n%s", output)
+	}
+}
+
+func TestMainEMLStartsWithHeaders(t *testing.T) {
+	output := runMain(t, writeMainTestSession(t), "--format", "eml")
+
+	if !strings.HasPrefix(output, "From: ") {
+		t.Errorf("Expected stdout to begin with the message headers, got:\n%s", output)
+	}
+}
+
+func TestMainContextStartsWithTranscript(t *testing.T) {
+	output := runMain(t, writeMainTestSession(t), "--format", "context")
+
+	if !strings.HasPrefix(output, "H: hello\n") {
+		t.Errorf("Expected stdout to begin with the transcript, got:\n%s", output)
+	}
+}
+
+func TestMainSlackStartsWithMessage(t *testing.T) {
+	output := runMain(t, writeMainTestSession(t), "--format", "slack")
+
+	if !strings.HasPrefix(output, "*hello*\n") {
+		t.Errorf("Expected stdout to begin with the first message, got:\n%s", output)
+	}
+}
+
+func TestMainMarkdownShowsBanner(t *testing.T) {
+	output := runMain(t, writeMainTestSession(t))
+
+	if !strings.HasPrefix(output, "cclog - Claude Conversation Log Converter\n") {
+		t.Errorf("Expected the banner before markdown in a terminal, got:\n%s", output)
+	}
+}
+
+func TestShowBanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   cli.Config
+		terminal bool
+		want     bool
+	}{
+		{"markdown in a terminal", cli.Config{}, true, true},
+		{"explicit markdown", cli.Config{Format: "markdown"}, true, true},
+		{"piped markdown", cli.Config{}, false, false},
+		{"csv", cli.Config{Format: "csv"}, true, false},
+		{"tsv", cli.Config{Format: "tsv"}, true, false},
+		{"help", cli.Config{ShowHelp: true}, true, false},
+		{"context", cli.Config{Format: "context"}, true, false},
+		{"slack", cli.Config{Format: "slack"}, true, false},
+		{"discord", cli.Config{Format: "discord"}, true, false},
+		{"eml", cli.Config{Format: "eml"}, true, false},
+		{"graph", cli.Config{Graph: true}, true, false},
+		{"mermaid graph", cli.Config{Graph: true, Format: "mermaid"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := showBanner(tt.config, tt.terminal); got != tt.want {
+				t.Errorf("showBanner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// DirName is the directory next to a markdown file that holds its extracted images
+const DirName = "assets"
+
+// extensions maps the image media types Claude accepts to file extensions
+var extensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Image is an image extracted from a conversation, named by its content hash
+type Image struct {
+	Name string
+	Data []byte
+}
+
+// Extract replaces the base64 image blocks of every message in log, including images inside
+// tool results, with text blocks linking to DirName/<name>, and returns the images to write
+// with Write. Each image is returned once, however often it appears.
+func Extract(log *types.ConversationLog) []Image {
+	seen := make(map[string]bool)
+	var images []Image
+	for _, msg := range log.Messages {
+		if msg.Message != nil {
+			images = extractBlocks(msg.Message.Content, seen, images)
+		}
+	}
+	return images
+}
+
+// extractBlocks replaces the image blocks in content and in the tool results it contains
+func extractBlocks(content types.Content, seen map[string]bool, images []Image) []Image {
+	for i, block := range content.Blocks {
+		switch b := block.(type) {
+		case types.ImageBlock:
+			image, ok := decodeImage(b)
+			if !ok {
+				continue // Not a base64 image; leave it to the formatter
+			}
+			if !seen[image.Name] {
+				seen[image.Name] = true
+				images = append(images, image)
+			}
+			content.Blocks[i] = types.TextBlock{Text: fmt.Sprintf("![image](%s/%s)", DirName, image.Name)}
+		case types.ToolResultBlock:
+			images = extractBlocks(b.Content, seen, images)
+		}
+	}
+	return images
+}
+
+// decodeImage decodes a base64 image block, reporting false if it has no usable data
+func decodeImage(block types.ImageBlock) (Image, bool) {
+	if block.Source.Type != "base64" {
+		return Image{}, false
+	}
+	data, err := base64.StdEncoding.DecodeString(block.Source.Data)
+	if err != nil || len(data) == 0 {
+		return Image{}, false
+	}
+
+	ext, ok := extensions[block.Source.MediaType]
+	if !ok {
+		ext = ".bin"
+	}
+	sum := sha256.Sum256(data)
+	return Image{Name: hex.EncodeToString(sum[:8]) + ext, Data: data}, true
+}
+
+// Write saves images into the DirName directory inside dir, the directory of the markdown file linking them.
+// Images that already exist are not rewritten, since their names are content hashes.
+func Write(dir string, images []Image) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	assetsDir := filepath.Join(dir, DirName)
+	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create image directory: %w", err)
+	}
+	for _, image := range images {
+		path, err := safepath.Join(assetsDir, image.Name)
+		if err != nil {
+			return fmt.Errorf("invalid image name: %w", err)
+		}
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, image.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write image %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package assets
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func imageBlock(data string) types.ImageBlock {
+	return types.ImageBlock{Source: types.ImageSource{
+		Type:      "base64",
+		MediaType: "image/png",
+		Data:      base64.StdEncoding.EncodeToString([]byte(data)),
+	}}
+}
+
+func TestExtract(t *testing.T) {
+	log := &types.ConversationLog{Messages: []types.Message{
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(
+			types.TextBlock{Text: "What is wrong here?"},
+			imageBlock("screenshot"),
+		)}},
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(
+			types.ToolResultBlock{Content: types.BlockContent(imageBlock("screenshot"))},
+			types.ImageBlock{Source: types.ImageSource{Type: "url", URL: "https://example.com/a.png"}},
+		)}},
+	}}
+
+	images := Extract(log)
+	if len(images) != 1 || string(images[0].Data) != "screenshot" || filepath.Ext(images[0].Name) != ".png" {
+		t.Fatalf("Expected the repeated image once, got %+v", images)
+	}
+
+	link := "![image](assets/" + images[0].Name + ")"
+	if got := types.ExtractTextContent(log.Messages[0].Message); got != "What is wrong here?\n"+link {
+		t.Errorf("Unexpected content %q", got)
+	}
+	blocks := log.Messages[1].Message.Blocks()
+	nested := blocks[0].(types.ToolResultBlock).Content.Blocks[0]
+	if text, ok := nested.(types.TextBlock); !ok || text.Text != link {
+		t.Errorf("Expected the tool result image to be linked, got %+v", nested)
+	}
+	if _, ok := blocks[1].(types.ImageBlock); !ok {
+		t.Error("Images without base64 data should be left alone")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := Write(dir, nil); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DirName)); !os.IsNotExist(err) {
+		t.Error("No assets directory should be created without images")
+	}
+
+	if err := Write(dir, []Image{{Name: "a.png", Data: []byte("png")}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, DirName, "a.png"))
+	if err != nil || string(data) != "png" {
+		t.Errorf("Expected the image in the assets directory, got %q, %v", data, err)
+	}
+}
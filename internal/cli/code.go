@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/codeblock"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/safepath"
+)
+
+// RunCode writes every fenced code block of the assistant messages of the input session to its own file
+// in the output directory, named by number and language or, with config.ByPath, after the file of the
+// Edit or Write call of the same message, and lists the files written
+func RunCode(ctx context.Context, config Config, redactor *redact.Redactor) (string, error) {
+	log, err := parser.ParseJSONLFileContext(ctx, config.InputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+	blocks := codeblock.Extract(log)
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("no code blocks in the assistant messages of %s", config.InputPath)
+	}
+
+	var sb strings.Builder
+	for i, block := range blocks {
+		if redactor != nil {
+			block.Code = redactor.Text(block.Code)
+		}
+		path, err := safepath.Join(config.OutputPath, codeblock.FileName(i+1, block, config.ByPath))
+		if err != nil {
+			return "", err
+		}
+		if err := writeOutputFile(path, block.Code); err != nil {
+			return "", err
+		}
+		fmt.Fprintln(&sb, path)
+	}
+	fmt.Fprintf(&sb, "Wrote %d code blocks to %s\n", len(blocks), config.OutputPath)
+	if redactor != nil {
+		fmt.Fprintln(os.Stderr, redactor.Report())
+	}
+	return sb.String(), nil
+}
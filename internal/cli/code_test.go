@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const codeContent = `{"type":"user","message":{"role":"user","content":"Add a helper"},"uuid":"u1","timestamp":"2025-07-06T05:00:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"` + "```go\\nfunc helper() {}\\n```" + `"},{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/src/app/helper.go"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Run:\n\n` + "```bash\\nexport KEY=sk-ant-REDACTED\\n```" + `"},"uuid":"a2","timestamp":"2025-07-06T05:02:00Z"}
+`
+
+func TestRunCode(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, codeContent)
+	output := filepath.Join(t.TempDir(), "code")
+
+	result, err := RunCommand(Config{Code: true, InputPath: input, OutputPath: output, ByPath: true, Redact: true})
+	if err != nil {
+		t.Fatalf("code failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "Wrote 2 code blocks to "+output+"\n") {
+		t.Errorf("Unexpected result:\n%s", result)
+	}
+	for name, want := range map[string]string{"001-helper.go": "func helper() {}\n", "002-bash.sh": "export KEY=[REDACTED"} {
+		data, err := os.ReadFile(filepath.Join(output, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		if !strings.HasPrefix(string(data), want) {
+			t.Errorf("%s = %q, want it to start with %q", name, data, want)
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	writeTestSession(t, empty, `{"type":"assistant","message":{"role":"assistant","content":"No code"},"uuid":"a1","timestamp":"2025-07-06T05:00:00Z"}`+"\n")
+	if _, err := RunCommand(Config{Code: true, InputPath: empty, OutputPath: output}); err == nil {
+		t.Error("Expected an error for a session without code blocks")
+	}
+}
+
+func TestParseArgsCode(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "code", "session.jsonl", "-o", "snippets", "--by-path"})
+	if err != nil || !config.Code || !config.ByPath || config.InputPath != "session.jsonl" || config.OutputPath != "snippets" {
+		t.Errorf("Expected code of session.jsonl into snippets, got %+v, %v", config, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "code", "session.jsonl"}); err == nil {
+		t.Error("Expected an error without an output directory")
+	}
+}
@@ -1,36 +1,269 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/annenpolka/cclog/internal/assets"
+	"github.com/annenpolka/cclog/internal/eml"
 	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/outfile"
 	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/pdf"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/settings"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/replay"
 	"github.com/annenpolka/cclog/pkg/types"
 )
 
 // Config represents command-line configuration
 type Config struct {
+	// Command is the subcommand given as the first argument, e.g. "search" (empty for the convert and browse shortcuts)
+	Command     string
 	InputPath   string
 	OutputPath  string
 	IsDirectory bool
 	ShowHelp    bool
 	IncludeAll  bool
 	ShowUUID    bool
+	ShowModel   bool
 	TUIMode     bool
 	Recursive   bool
 	ShowTitle   bool
+	SelfUpdate  bool
+	Outline     bool
+	Export      bool
+	Sync        bool
+	Gist        bool
+	Graph       bool
+	KB          bool
+	Search      bool
+	Stats       bool
+	Digest      bool
+	Summarize   bool
+	Code        bool
+	Edits       bool
+	Serve       bool
+	Resume      bool
+	ResumeCmd   bool
+	Replay      bool
+	Diff        bool
+	List        bool
+	Last        bool
+	Doctor      bool
+	Validate    bool
+	Compress    bool
+	Index       bool
+	ShowVersion bool
+	Force       bool
+	NoColor     bool
+	ReadOnly    bool
+	// CollapseThreshold collapses messages longer than this many lines into <details> blocks (0 disables)
+	CollapseThreshold int
+	// Profile selects an output profile such as "print"
+	Profile string
+	// Format selects the output format: "markdown" (default), "context", "csv" or "tsv", or for graphs "dot" (default) or "mermaid"
+	Format string
+	// MaxTokens leaves out the oldest messages so each conversation fits in about this many tokens (0 disables)
+	MaxTokens int
+	// MaxDepth, MaxFiles and Ignore limit the TUI's recursive scans (0 and nil mean the configured defaults)
+	MaxDepth int
+	MaxFiles int
+	Ignore   []string
+	// Jobs is the number of files converted concurrently in directory mode (0 means one per CPU)
+	Jobs int
+	// SplitOutput writes one markdown file per conversation into this directory in directory mode
+	SplitOutput string
+	// NameTemplate names the files written by SplitOutput (text/template, e.g. "{{.Date}}-{{.TitleSlug}}.md")
+	NameTemplate string
+	// MaxToolOutput truncates tool results longer than this many characters (0 disables)
+	MaxToolOutput int
+	// Wrap hard-wraps prose lines of messages at this many columns (0 disables)
+	Wrap int
+	// ShowRequestIDs shows the request IDs of assistant messages and keeps API errors, marked with theirs
+	ShowRequestIDs bool
+	// DisableFilters and ExcludePatterns customize the filter rules, in addition to the settings
+	DisableFilters  []string
+	ExcludePatterns []string
+	// Tools and ExcludeTools select the tools whose calls and results are shown (empty keeps all)
+	Tools        []string
+	ExcludeTools []string
+	// Roles keeps only the messages of these roles: user, assistant or tool (empty keeps all)
+	Roles []string
+	// PairTools shows each tool result right after its tool call (with IncludeAll)
+	PairTools bool
+	// ShowTools adds a table of the tool calls after each assistant turn (with IncludeAll)
+	ShowTools bool
+	// ExtractImages writes pasted images to an assets directory next to the output and links them
+	ExtractImages bool
+	// Redact replaces secrets and personal data (API keys, emails, ...) before formatting
+	Redact bool
+	// NoTimestamps leaves the time of each message out of the markdown
+	NoTimestamps bool
+	// Anchors puts an HTML anchor derived from its UUID before each message
+	Anchors bool
+	// Anchor limits the output to the message with this UUID (or UUID prefix) and AnchorContext messages before and after it
+	Anchor        string
+	AnchorContext int
+	// ByPath names the files written by the code command after the file of the Edit or Write call next to each block
+	ByPath bool
+	// LLM has the summarize command ask an LLM (the claude CLI or the Anthropic API) for the summary
+	LLM bool
+	// GitAnnotate marks where the commits of the session's repository were made between its messages
+	GitAnnotate bool
+	// SummaryBlock starts each conversation's markdown with a summary of its prompt, files and outcome
+	SummaryBlock bool
+	// TOC lists the user turns at the top of each conversation's markdown, linking to them
+	TOC bool
+	// Query is the text the search command looks for
+	Query string
+	// Project limits directory conversions, the TUI, search, stats, digest and last to the sessions of matching projects
+	// (the name of their working directory, exactly or as a glob)
+	Project string
+	// SessionID converts the session with this sessionId, found in the Claude projects directory and the extra roots
+	SessionID string
+	// Repair writes a cleaned copy of the input log, with its truncated lines salvaged, to this file and converts it
+	Repair string
+	// Editor is the command the TUI opens files with, overriding the editor setting and $EDITOR
+	Editor string
+	// ExportDir keeps the markdown of files opened from the TUI in this directory, overriding the exportDir setting
+	ExportDir string
+	// Hook is a shell command run after each file written by export, sync, kb, -o and --split-output, overriding the
+	// postExportHook setting
+	Hook string
+	// NoHook runs no hook, not even the one of the postExportHook setting
+	NoHook bool
+	// Public makes the gist created by export gist public instead of secret
+	Public bool
+	// Webhook is the Slack or Discord incoming webhook the messages of --format slack or discord are posted to
+	Webhook string
+	// Dangerous resumes sessions with --dangerously-skip-permissions
+	Dangerous bool
+	// Sort orders the sessions of the list command: date (default), project, title or messages
+	Sort string
+	// Limit lists at most this many sessions (0 lists all)
+	Limit int
+	// Columns selects and orders the columns of the list command (empty shows all)
+	Columns []string
+	// Print0 ends the TSV records of the list command with NUL instead of newline
+	Print0 bool
+	// DiffPath is the session the diff command compares the input with
+	DiffPath string
+	// IndexAction is the action of the index command: build or update
+	IndexAction string
+	// Addr is the address the serve command listens on, e.g. ":9467" (empty means defaultServeAddr)
+	Addr string
+	// Since is the period the digest command reports on, e.g. 7d, 12h or 2025-07-01
+	Since string
+	// OlderThan is the age in days of the sessions the compress command archives
+	OlderThan int
+	// Speed multiplies the pace of the replay command (0 means the original pace)
+	Speed float64
+	// Timezone is the IANA timezone timestamps are rendered in, e.g. "UTC" (empty means the timezone setting or the system timezone)
+	Timezone string
+	// Stdout receives converted markdown as it is formatted instead of RunCommand returning it, so large
+	// sessions are not held in memory as a whole. It is not a flag; nil returns the markdown.
+	Stdout io.Writer
 }
 
 // ParseArgs parses command-line arguments and returns configuration
 func ParseArgs(args []string) (Config, error) {
 	config := Config{}
 	hasPathOption := false
+	anchorContext := defaultAnchorContext
+	start := 1
+
+	// Handle the self-update subcommand before regular option parsing
+	if len(args) >= 2 && args[1] == "self-update" {
+		config.SelfUpdate = true
+		for _, arg := range args[2:] {
+			switch arg {
+			case "-h", "--help":
+				config.Command = "self-update"
+				config.ShowHelp = true
+			case "--force":
+				config.Force = true
+			default:
+				return Config{}, fmt.Errorf("unknown option %s for self-update (see 'cclog self-update -h')", arg)
+			}
+		}
+		return config, nil
+	}
+
+	// A subcommand restricts the options to its own; without one, every option is accepted
+	cmd, hasCommand := command{}, false
+	if len(args) >= 2 {
+		cmd, hasCommand = findCommand(args[1])
+	}
+	if hasCommand {
+		config.Command = cmd.name
+		start = 2
+		switch cmd.name {
+		case "browse":
+			config.TUIMode = true
+			config.Recursive = true
+		case "outline":
+			config.Outline = true
+		case "export":
+			config.Export = true
+		case "sync":
+			config.Sync = true
+		case "graph":
+			config.Graph = true
+		case "kb":
+			config.KB = true
+		case "search":
+			config.Search = true
+		case "stats":
+			config.Stats = true
+		case "digest":
+			config.Digest = true
+		case "summarize":
+			config.Summarize = true
+		case "code":
+			config.Code = true
+		case "edits":
+			config.Edits = true
+		case "serve":
+			config.Serve = true
+		case "resume":
+			config.Resume = true
+		case "resume-cmd":
+			config.ResumeCmd = true
+		case "replay":
+			config.Replay = true
+		case "diff":
+			config.Diff = true
+		case "list":
+			config.List = true
+		case "last":
+			config.Last = true
+		case "doctor":
+			config.Doctor = true
+		case "validate":
+			config.Validate = true
+		case "index":
+			config.Index = true
+		case "compress":
+			config.Compress = true
+			config.OlderThan = defaultCompressAge
+		}
+	}
 
 	// Check if --path option is used to determine default behavior
-	for i := 1; i < len(args); i++ {
+	for i := start; i < len(args); i++ {
 		if args[i] == "--path" {
 			hasPathOption = true
 			break
@@ -38,24 +271,30 @@ func ParseArgs(args []string) (Config, error) {
 	}
 
 	// If no arguments provided or --path option is used, enable TUI mode and recursive mode by default
-	if len(args) < 2 || hasPathOption {
+	if !hasCommand && (len(args) < 2 || hasPathOption) {
 		config.TUIMode = true
 		config.Recursive = true
 		// Continue to process default directory setup below
 	}
 
 	// Only process arguments if we have them
-	if len(args) >= 2 {
-		for i := 1; i < len(args); i++ {
+	if len(args) > start {
+		for i := start; i < len(args); i++ {
 			arg := args[i]
+			if hasCommand && strings.HasPrefix(arg, "-") && len(arg) > 1 && !cmd.accepts(arg) {
+				return Config{}, fmt.Errorf("unknown option %s for %s (see 'cclog %s -h')", arg, cmd.name, cmd.name)
+			}
 
 			switch arg {
 			case "-h", "--help":
 				config.ShowHelp = true
 				return config, nil
+			case "--version":
+				config.ShowVersion = true
+				return config, nil
 			case "-d", "--directory":
 				config.IsDirectory = true
-			case "-o", "--output":
+			case "-o", "--output", "--out":
 				if i+1 >= len(args) {
 					return Config{}, fmt.Errorf("output flag requires a value")
 				}
@@ -65,8 +304,321 @@ func ParseArgs(args []string) (Config, error) {
 				config.IncludeAll = true
 			case "--show-uuid":
 				config.ShowUUID = true
+			case "--show-model":
+				config.ShowModel = true
+			case "--show-request-ids":
+				config.ShowRequestIDs = true
 			case "--show-title":
 				config.ShowTitle = true
+			case "--collapse":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("collapse flag requires a value")
+				}
+				threshold, err := strconv.Atoi(args[i+1])
+				if err != nil || threshold < 0 {
+					return Config{}, fmt.Errorf("collapse flag requires a non-negative number of lines: %s", args[i+1])
+				}
+				config.CollapseThreshold = threshold
+				i++ // Skip next argument as it's the threshold
+			case "--profile":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("profile flag requires a value")
+				}
+				config.Profile = args[i+1]
+				i++ // Skip next argument as it's the profile name
+			case "--format":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("format flag requires a value")
+				}
+				config.Format = args[i+1]
+				i++ // Skip next argument as it's the format name
+			case "--max-depth", "--max-files":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("%s flag requires a value", strings.TrimPrefix(arg, "--"))
+				}
+				limit, err := strconv.Atoi(args[i+1])
+				if err != nil || limit < 1 {
+					return Config{}, fmt.Errorf("%s flag requires a positive number: %s", strings.TrimPrefix(arg, "--"), args[i+1])
+				}
+				if arg == "--max-depth" {
+					config.MaxDepth = limit
+				} else {
+					config.MaxFiles = limit
+				}
+				// Scan limits only apply to the recursive TUI, like -r
+				config.Recursive = true
+				config.TUIMode = true
+				i++ // Skip next argument as it's the limit
+			case "--split-output":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("split-output flag requires a value")
+				}
+				config.SplitOutput = args[i+1]
+				i++ // Skip next argument as it's the output directory
+			case "--name-template":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("name-template flag requires a value")
+				}
+				config.NameTemplate = args[i+1]
+				i++ // Skip next argument as it's the template
+			case "--max-tool-output":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("max-tool-output flag requires a value")
+				}
+				limit, err := strconv.Atoi(args[i+1])
+				if err != nil || limit < 1 {
+					return Config{}, fmt.Errorf("max-tool-output flag requires a positive number: %s", args[i+1])
+				}
+				config.MaxToolOutput = limit
+				i++ // Skip next argument as it's the number of characters
+			case "--wrap":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("wrap flag requires a value")
+				}
+				width, err := strconv.Atoi(args[i+1])
+				if err != nil || width < 1 {
+					return Config{}, fmt.Errorf("wrap flag requires a positive number: %s", args[i+1])
+				}
+				config.Wrap = width
+				i++ // Skip next argument as it's the number of columns
+			case "--max-tokens":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("max-tokens flag requires a value")
+				}
+				limit, err := strconv.Atoi(args[i+1])
+				if err != nil || limit < 1 {
+					return Config{}, fmt.Errorf("max-tokens flag requires a positive number: %s", args[i+1])
+				}
+				config.MaxTokens = limit
+				i++ // Skip next argument as it's the number of tokens
+			case "--timezone":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("timezone flag requires a value")
+				}
+				config.Timezone = args[i+1]
+				i++ // Skip next argument as it's the timezone
+			case "--sort":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("sort flag requires a value")
+				}
+				if !slices.Contains(listSortOrders, args[i+1]) {
+					return Config{}, fmt.Errorf("unknown sort order %q (available: %s)", args[i+1], strings.Join(listSortOrders, ", "))
+				}
+				config.Sort = args[i+1]
+				i++ // Skip next argument as it's the sort order
+			case "--limit":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("limit flag requires a value")
+				}
+				limit, err := strconv.Atoi(args[i+1])
+				if err != nil || limit < 1 {
+					return Config{}, fmt.Errorf("limit flag requires a positive number: %s", args[i+1])
+				}
+				config.Limit = limit
+				i++ // Skip next argument as it's the number of sessions
+			case "--columns":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("columns flag requires a value")
+				}
+				config.Columns = append(config.Columns, splitList(args[i+1])...)
+				if _, err := selectListColumns(config.Columns); err != nil {
+					return Config{}, err
+				}
+				i++ // Skip next argument as it's the column list
+			case "--print0":
+				config.Print0 = true
+			case "--older-than":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("older-than flag requires a value")
+				}
+				days, err := strconv.Atoi(args[i+1])
+				if err != nil || days < 1 {
+					return Config{}, fmt.Errorf("older-than flag requires a positive number of days: %s", args[i+1])
+				}
+				config.OlderThan = days
+				i++ // Skip next argument as it's the number of days
+			case "--addr":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("addr flag requires a value")
+				}
+				if _, _, err := net.SplitHostPort(args[i+1]); err != nil {
+					return Config{}, fmt.Errorf("invalid address %q, expected host:port or :port", args[i+1])
+				}
+				config.Addr = args[i+1]
+				i++ // Skip next argument as it's the address
+			case "--since":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("since flag requires a value")
+				}
+				if _, err := parseSince(args[i+1], time.Now(), time.Local); err != nil {
+					return Config{}, err
+				}
+				config.Since = args[i+1]
+				i++ // Skip next argument as it's the period
+			case "--speed":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("speed flag requires a value")
+				}
+				speed, err := strconv.ParseFloat(args[i+1], 64)
+				if err != nil || speed < replay.MinSpeed || speed > replay.MaxSpeed {
+					return Config{}, fmt.Errorf("speed flag requires a number from %g to %g: %s", replay.MinSpeed, replay.MaxSpeed, args[i+1])
+				}
+				config.Speed = speed
+				i++ // Skip next argument as it's the speed
+			case "--jobs":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("jobs flag requires a value")
+				}
+				jobs, err := strconv.Atoi(args[i+1])
+				if err != nil || jobs < 1 {
+					return Config{}, fmt.Errorf("jobs flag requires a positive number: %s", args[i+1])
+				}
+				config.Jobs = jobs
+				i++ // Skip next argument as it's the number of jobs
+			case "--ignore":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("ignore flag requires a value")
+				}
+				config.Ignore = append(config.Ignore, args[i+1])
+				config.Recursive = true
+				config.TUIMode = true
+				i++ // Skip next argument as it's the pattern
+			case "--disable-filter":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("disable-filter flag requires a value")
+				}
+				config.DisableFilters = append(config.DisableFilters, splitList(args[i+1])...)
+				i++ // Skip next argument as it's the rule list
+			case "--exclude-pattern":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("exclude-pattern flag requires a value")
+				}
+				config.ExcludePatterns = append(config.ExcludePatterns, args[i+1])
+				i++ // Skip next argument as it's the pattern
+			case "--tools", "--exclude-tools":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("%s flag requires a value", strings.TrimPrefix(arg, "--"))
+				}
+				names := splitList(args[i+1])
+				if arg == "--tools" {
+					config.Tools = append(config.Tools, names...)
+				} else {
+					config.ExcludeTools = append(config.ExcludeTools, names...)
+				}
+				i++ // Skip next argument as it's the tool list
+			case "--only", "--roles":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("%s flag requires a value", strings.TrimPrefix(arg, "--"))
+				}
+				roles := splitList(args[i+1])
+				if arg == "--only" {
+					roles = []string{strings.TrimSpace(args[i+1])}
+				}
+				if err := filter.ValidateRoles(roles); err != nil {
+					return Config{}, err
+				}
+				config.Roles = append(config.Roles, roles...)
+				i++ // Skip next argument as it's the role list
+			case "--show-tools":
+				config.ShowTools = true
+			case "--pair-tools":
+				config.PairTools = true
+			case "--extract-images":
+				config.ExtractImages = true
+			case "--redact":
+				config.Redact = true
+			case "--no-timestamps":
+				config.NoTimestamps = true
+			case "--toc":
+				config.TOC = true
+			case "--hook":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("hook flag requires a value")
+				}
+				config.Hook = args[i+1]
+				i++ // Skip next argument as it's the command
+			case "--no-hook":
+				config.NoHook = true
+			case "--public":
+				config.Public = true
+			case "--webhook":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("webhook flag requires a value")
+				}
+				if u, err := url.Parse(args[i+1]); err != nil || u.Scheme != "https" || u.Host == "" {
+					return Config{}, fmt.Errorf("invalid webhook URL, expected an https:// URL")
+				}
+				config.Webhook = args[i+1]
+				i++ // Skip next argument as it's the URL
+			case "--summary-block":
+				config.SummaryBlock = true
+			case "--git-annotate":
+				config.GitAnnotate = true
+			case "--llm":
+				config.LLM = true
+			case "--by-path":
+				config.ByPath = true
+			case "--anchors":
+				config.Anchors = true
+			case "--anchor":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("anchor flag requires a value")
+				}
+				// The focused message is linked to by its anchor
+				config.Anchor = args[i+1]
+				config.Anchors = true
+				i++ // Skip next argument as it's the UUID
+			case "--anchor-context":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("anchor-context flag requires a value")
+				}
+				context, err := strconv.Atoi(args[i+1])
+				if err != nil || context < 0 {
+					return Config{}, fmt.Errorf("anchor-context flag requires a non-negative number: %s", args[i+1])
+				}
+				anchorContext = context
+				i++ // Skip next argument as it's the number of messages
+			case "--force":
+				config.Force = true
+			case "--project":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("project flag requires a value")
+				}
+				if _, err := path.Match(args[i+1], ""); err != nil {
+					return Config{}, fmt.Errorf("invalid project pattern %q: %w", args[i+1], err)
+				}
+				config.Project = args[i+1]
+				i++ // Skip next argument as it's the project name
+			case "--session":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("session flag requires a value")
+				}
+				config.SessionID = args[i+1]
+				i++ // Skip next argument as it's the session ID
+			case "--repair":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("repair flag requires a value")
+				}
+				config.Repair = args[i+1]
+				i++ // Skip next argument as it's the cleaned copy
+			case "--editor":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("editor flag requires a value")
+				}
+				config.Editor = args[i+1]
+				i++ // Skip next argument as it's the editor command
+			case "--export-dir":
+				if i+1 >= len(args) {
+					return Config{}, fmt.Errorf("export-dir flag requires a value")
+				}
+				config.ExportDir = args[i+1]
+				i++ // Skip next argument as it's the export directory
+			case "--dangerous":
+				config.Dangerous = true
+			case "--no-color":
+				config.NoColor = true
+			case "--read-only":
+				config.ReadOnly = true
 			case "--tui":
 				config.TUIMode = true
 			case "-r", "--recursive":
@@ -79,17 +631,200 @@ func ParseArgs(args []string) (Config, error) {
 				config.InputPath = args[i+1]
 				i++ // Skip next argument as it's the input path
 			default:
-				if config.InputPath == "" {
+				if config.Search && config.Query == "" {
+					config.Query = arg
+				} else if (config.Resume || config.ResumeCmd || config.Replay) && !parser.IsLogFile(arg) && config.SessionID == "" {
+					config.SessionID = arg // Resume and replay take a session file or ID
+				} else if config.Export && i == start && arg == "gist" {
+					// 'cclog export gist <file>' uploads one session instead of exporting to a directory
+					config.Export = false
+					config.Gist = true
+				} else if config.Index && config.IndexAction == "" {
+					config.IndexAction = arg
+				} else if config.Command == "show" && config.SessionID == "" {
+					config.SessionID = arg
+				} else if config.InputPath == "" {
 					config.InputPath = arg
+				} else if config.Diff && config.DiffPath == "" {
+					config.DiffPath = arg
 				}
 			}
 		}
 	}
 
-	if config.InputPath == "" && !config.ShowHelp && !config.TUIMode {
+	if config.Search && config.Query == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("search requires a query")
+	}
+
+	if config.Index && !config.ShowHelp && config.IndexAction != indexActionBuild && config.IndexAction != indexActionUpdate {
+		return Config{}, fmt.Errorf("index requires an action: %s or %s", indexActionBuild, indexActionUpdate)
+	}
+
+	// Search, stats, digest, last, list, compress, index, serve and sync look at every session by default, like the TUI
+	if (config.Search || config.Stats || config.Digest || config.Last || config.List || config.Compress || config.Index || config.Serve || config.Sync) && config.InputPath == "" && !config.ShowHelp {
+		config.InputPath = getDefaultTUIDirectory()
+	}
+
+	if config.Diff && config.DiffPath == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("diff requires two sessions")
+	}
+
+	if config.Command == "show" && config.SessionID == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("show requires a session ID")
+	}
+
+	if config.Doctor && config.InputPath != "" {
+		return Config{}, fmt.Errorf("doctor takes no arguments")
+	}
+
+	if config.InputPath == "" && config.SessionID == "" && !config.ShowHelp && !config.TUIMode && !config.Doctor {
 		return Config{}, fmt.Errorf("input path is required")
 	}
 
+	if config.Export && config.OutputPath == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("export requires an output directory (-o DIR)")
+	}
+
+	if config.Sync && config.OutputPath == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("sync requires an output directory (--out DIR)")
+	}
+
+	if config.Gist && !config.ShowHelp {
+		switch {
+		case config.IsDirectory:
+			return Config{}, fmt.Errorf("export gist requires a single session, not -d")
+		case config.OutputPath != "":
+			return Config{}, fmt.Errorf("export gist uploads the markdown instead of writing it, so it takes no -o")
+		case config.Force || config.ExtractImages || config.Hook != "":
+			return Config{}, fmt.Errorf("--force, --extract-images and --hook cannot be used with export gist")
+		}
+	}
+
+	if config.Public && !config.Gist && !config.ShowHelp {
+		return Config{}, fmt.Errorf("--public only applies to export gist")
+	}
+
+	if config.Code && config.OutputPath == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("code requires an output directory (-o DIR)")
+	}
+
+	if config.SplitOutput != "" && !config.IsDirectory && !config.ShowHelp {
+		return Config{}, fmt.Errorf("--split-output requires directory mode (-d)")
+	}
+
+	if config.NameTemplate != "" && config.SplitOutput == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("--name-template requires --split-output")
+	}
+
+	if config.ExtractImages && config.OutputPath == "" && config.SplitOutput == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("--extract-images requires an output file (-o) or --split-output")
+	}
+
+	if config.List && !config.ShowHelp {
+		switch config.Format {
+		case "", listFormatTable, listFormatJSON, listFormatTSV:
+		default:
+			return Config{}, fmt.Errorf("unknown list format %q (available: %s, %s, %s)", config.Format, listFormatTable, listFormatJSON, listFormatTSV)
+		}
+		if config.Print0 && config.Format != listFormatTSV {
+			return Config{}, fmt.Errorf("--print0 requires --format tsv")
+		}
+		if len(config.Columns) > 0 && config.Format == listFormatJSON {
+			return Config{}, fmt.Errorf("--columns cannot be used with --format json")
+		}
+	}
+
+	if config.Compress && !config.ShowHelp {
+		if _, ok := compressExtensions[config.Format]; !ok && config.Format != "" {
+			return Config{}, fmt.Errorf("unknown compress format %q (available: %s, %s)", config.Format, compressFormatGzip, compressFormatZstd)
+		}
+	}
+
+	if !config.Graph && !config.List && !config.Compress && !config.ShowHelp {
+		switch config.Format {
+		case "", formatter.FormatMarkdown, formatter.FormatContext, formatter.FormatCSV, formatter.FormatTSV, formatter.FormatSlack, formatter.FormatDiscord, eml.Format, pdf.Format:
+		default:
+			return Config{}, fmt.Errorf("unknown format %q (available: %s, %s, %s, %s, %s, %s, %s, %s)", config.Format,
+				formatter.FormatMarkdown, formatter.FormatContext, formatter.FormatCSV, formatter.FormatTSV, formatter.FormatSlack, formatter.FormatDiscord, eml.Format, pdf.Format)
+		}
+		if config.Webhook != "" {
+			if !formatter.IsShareFormat(config.Format) {
+				return Config{}, fmt.Errorf("--webhook requires --format %s or %s", formatter.FormatSlack, formatter.FormatDiscord)
+			}
+			if config.OutputPath != "" {
+				return Config{}, fmt.Errorf("--webhook posts the messages instead of printing them, so it cannot be used with -o")
+			}
+		}
+		if config.Format == pdf.Format && config.OutputPath == "" {
+			return Config{}, fmt.Errorf("--format pdf requires an output file (-o)")
+		}
+		if config.Format != "" && config.Format != formatter.FormatMarkdown && config.SplitOutput != "" {
+			return Config{}, fmt.Errorf("--format %s cannot be used with --split-output", config.Format)
+		}
+	}
+
+	if config.ShowTools && !config.ShowHelp {
+		switch config.Format {
+		case formatter.FormatContext, formatter.FormatCSV, formatter.FormatTSV, formatter.FormatSlack, formatter.FormatDiscord:
+			// These formats leave out tool calls or have no place for a table
+			return Config{}, fmt.Errorf("--show-tools cannot be used with --format %s", config.Format)
+		}
+		if !config.IncludeAll {
+			return Config{}, fmt.Errorf("--show-tools requires --include-all, since tool calls are filtered out otherwise")
+		}
+	}
+
+	if config.Anchor != "" && !config.ShowHelp {
+		if config.IsDirectory {
+			return Config{}, fmt.Errorf("--anchor requires a single session, not -d")
+		}
+		config.AnchorContext = anchorContext
+	}
+
+	if config.TOC && !config.ShowHelp {
+		if config.IsDirectory && config.SplitOutput == "" {
+			return Config{}, fmt.Errorf("--toc requires one conversation per document (a file, or -d with --split-output)")
+		}
+		if !isMarkdownFormat(config.Format) {
+			return Config{}, fmt.Errorf("--toc cannot be used with --format %s", config.Format)
+		}
+	}
+
+	if config.SummaryBlock && !config.ShowHelp {
+		if config.IsDirectory {
+			return Config{}, fmt.Errorf("--summary-block requires a single session, not -d (export and kb add it to every session)")
+		}
+		if !isMarkdownFormat(config.Format) {
+			return Config{}, fmt.Errorf("--summary-block cannot be used with --format %s", config.Format)
+		}
+	}
+
+	if config.GitAnnotate && !config.ShowHelp {
+		if config.IsDirectory {
+			return Config{}, fmt.Errorf("--git-annotate requires a single session, not -d")
+		}
+		if !isMarkdownFormat(config.Format) {
+			return Config{}, fmt.Errorf("--git-annotate cannot be used with --format %s", config.Format)
+		}
+	}
+
+	if config.Hook != "" && !config.ShowHelp {
+		if config.NoHook {
+			return Config{}, fmt.Errorf("--hook cannot be used with --no-hook")
+		}
+		if config.OutputPath == "" && config.SplitOutput == "" {
+			return Config{}, fmt.Errorf("--hook runs after each file is written, so it requires --output or --split-output")
+		}
+	}
+
+	if config.Repair != "" && config.IsDirectory && !config.ShowHelp {
+		return Config{}, fmt.Errorf("--repair requires a single file, not -d")
+	}
+
+	if config.KB && config.OutputPath == "" && !config.ShowHelp {
+		return Config{}, fmt.Errorf("kb requires an output directory (--out DIR)")
+	}
+
 	// Set default directory for TUI mode if no input path specified
 	if config.TUIMode && config.InputPath == "" {
 		defaultDir := getDefaultTUIDirectory()
@@ -105,22 +840,24 @@ func ParseArgs(args []string) (Config, error) {
 	return config, nil
 }
 
-// getDefaultTUIDirectory returns the default directory for TUI mode
-// First tries $HOME/.claude/projects, then falls back to $HOME/.config/claude/projects
+// defaultAnchorContext is the number of messages shown before and after the --anchor message
+const defaultAnchorContext = 3
+
+// getDefaultTUIDirectory returns the default directory for TUI mode: the first of claudeProjectsDirs
+// whose parent exists (e.g. ~/.claude), or else the last one
 func getDefaultTUIDirectory() string {
-	home, err := os.UserHomeDir()
+	home, err := os.UserHomeDir() // %USERPROFILE% on Windows
 	if err != nil {
 		return "." // Fallback to current directory
 	}
 
-	// First try $HOME/.claude/projects
-	claudeDir := filepath.Join(home, ".claude", "projects")
-	if _, err := os.Stat(filepath.Join(home, ".claude")); err == nil {
-		return claudeDir
+	dirs := claudeProjectsDirs(home)
+	for _, dir := range dirs[:len(dirs)-1] {
+		if _, err := os.Stat(filepath.Dir(dir)); err == nil {
+			return dir
+		}
 	}
-
-	// Fallback to $HOME/.config/claude/projects
-	return filepath.Join(home, ".config", "claude", "projects")
+	return dirs[len(dirs)-1]
 }
 
 // ensureDefaultDirectoryExists checks if the directory exists without creating it
@@ -131,8 +868,26 @@ func ensureDefaultDirectoryExists(dir string) error {
 
 // RunCommand executes the main command logic
 func RunCommand(config Config) (string, error) {
+	return RunCommandContext(context.Background(), config)
+}
+
+// RunCommandContext executes the main command logic. Scans, conversions and PDF printing stop when ctx
+// is done, e.g. on Ctrl-C, returning an error that wraps ctx.Err().
+func RunCommandContext(ctx context.Context, config Config) (string, error) {
 	if config.ShowHelp {
-		return GetHelpText(), nil
+		return GetCommandHelpText(config.Command), nil
+	}
+
+	if config.SelfUpdate {
+		return RunSelfUpdate(config.Force)
+	}
+
+	if config.ShowVersion {
+		return VersionText(), nil
+	}
+
+	if config.Doctor {
+		return RunDoctor(), nil
 	}
 
 	if config.TUIMode {
@@ -140,97 +895,550 @@ func RunCommand(config Config) (string, error) {
 		return "", nil
 	}
 
-	// Validate input path exists
-	if _, err := os.Stat(config.InputPath); os.IsNotExist(err) {
+	// Validate input path exists (a session ID is looked up instead)
+	if _, err := os.Stat(config.InputPath); os.IsNotExist(err) && config.SessionID == "" {
 		return "", fmt.Errorf("input path does not exist: %s", config.InputPath)
 	}
 
+	if config.Validate {
+		return RunValidate(ctx, config)
+	}
+
+	if config.Compress {
+		return RunCompress(ctx, config)
+	}
+
+	// The last command converts the newest session like a regular conversion
+	if config.Last {
+		session, err := findLastSession(ctx, config.InputPath, config.Project)
+		if err != nil {
+			return "", err
+		}
+		config.InputPath = session
+		config.IsDirectory = false
+	}
+
+	saved, settingsErr := loadSettings()
+	if err := configureFilters(config, saved); err != nil {
+		return "", err
+	}
+
+	if config.SessionID != "" {
+		session, err := findSessionByID(ctx, sessionRoots(saved), config.SessionID)
+		if err != nil {
+			return "", err
+		}
+		config.InputPath = session
+		config.IsDirectory = false
+	}
+
+	// Titles and searchable messages are indexed with the configured filter rules
+	if config.Index {
+		return RunIndex(ctx, config)
+	}
+
+	if config.Resume {
+		return RunResume(config)
+	}
+
+	if config.ResumeCmd {
+		return RunResumeCommandLine(config)
+	}
+
+	timezone, err := formatter.LoadTimezone(resolveTimezone(config.Timezone, saved))
+	if err != nil {
+		return "", err
+	}
+
+	if config.Search {
+		return RunSearch(ctx, config, timezone)
+	}
+
+	if config.Stats {
+		return RunStats(ctx, config, timezone)
+	}
+
+	if config.Digest {
+		return RunDigest(ctx, config, timezone)
+	}
+
+	if config.Serve {
+		return RunServe(ctx, config, timezone)
+	}
+
+	if config.List {
+		return RunList(ctx, config, timezone)
+	}
+
+	// PDFs are for printing, so they use the print profile unless another one is chosen
+	profile := config.Profile
+	if profile == "" && config.Format == pdf.Format {
+		profile = formatter.ProfilePrint
+	}
+	formatOptions, err := formatter.ApplyProfile(formatter.FormatOptions{
+		ShowUUID:          config.ShowUUID,
+		ShowModel:         config.ShowModel,
+		ShowRequestIDs:    config.ShowRequestIDs,
+		ShowPlaceholders:  config.IncludeAll,
+		CollapseThreshold: config.CollapseThreshold,
+		OmitTimestamps:    config.NoTimestamps,
+		MaxToolOutput:     config.MaxToolOutput,
+		PairTools:         config.PairTools,
+		ToolSummary:       config.ShowTools,
+		MaxTokens:         config.MaxTokens,
+		Wrap:              config.Wrap,
+		TableOfContents:   config.TOC,
+		MessageAnchors:    config.Anchors,
+		Timezone:          timezone,
+	}, profile)
+	if err != nil {
+		return "", err
+	}
+
+	// Read-only log mounts (network shares, backup snapshots) must not be written to
+	if (config.OutputPath != "" || config.SplitOutput != "") && isReadOnly(config) {
+		for _, output := range []string{config.OutputPath, config.SplitOutput} {
+			if output == "" {
+				continue
+			}
+			if err := checkOutputOutsideLogs(config.InputPath, output); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// A damaged log is converted from its cleaned copy, which must not be written among read-only logs either
+	if config.Repair != "" {
+		if isReadOnly(config) {
+			if err := checkOutputOutsideLogs(config.InputPath, config.Repair); err != nil {
+				return "", err
+			}
+		}
+		report, err := parser.RepairFile(config.InputPath, config.Repair)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Repaired %s into %s: %s\n", config.InputPath, config.Repair, report)
+		config.InputPath = config.Repair
+	}
+
+	toolFilter := filter.ToolFilter{Include: config.Tools, Exclude: config.ExcludeTools}
+
+	postHook := resolveHook(config, saved)
+
+	var redactor *redact.Redactor
+	if config.Redact {
+		// Redaction must not silently skip the configured patterns
+		if settingsErr != nil {
+			return "", fmt.Errorf("--redact needs the redactPatterns of the config file: %w", settingsErr)
+		}
+		redactor, err = redact.New(saved.RedactPatterns)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if config.Summarize {
+		return RunSummarize(ctx, config, redactor)
+	}
+
+	if config.Code {
+		return RunCode(ctx, config, redactor)
+	}
+
+	if config.Edits {
+		return RunEdits(ctx, config, formatOptions.Location(), redactor)
+	}
+
+	if config.Replay {
+		return RunReplay(config, formatOptions, toolFilter, redactor)
+	}
+
+	if config.Diff {
+		return RunDiff(config, formatOptions, toolFilter, redactor)
+	}
+
+	if config.Export {
+		return RunExport(ctx, config, formatOptions, toolFilter, redactor, postHook)
+	}
+
+	if config.Sync {
+		return RunSync(ctx, config, formatOptions, toolFilter, redactor, postHook)
+	}
+
+	if config.KB {
+		return RunKB(ctx, config, formatOptions, toolFilter, redactor, postHook)
+	}
+
+	if config.Graph {
+		return RunGraph(ctx, config)
+	}
+
 	var markdown string
+	var outputImages []assets.Image
+	// stream writes the markdown of the formats that can be written as they are formatted, instead of markdown
+	var stream func(w io.Writer) error
+	// hookEvent describes the file written with -o to the hook
+	hookEvent := hook.Event{Event: hook.EventWritten, Output: config.OutputPath, Input: config.InputPath}
+	// title names the gist of export gist
+	var title string
+	// chunks are the chat messages of the share formats, posted one by one with --webhook
+	var chunks []string
 
 	if config.IsDirectory {
 		// Parse directory
-		logs, err := parser.ParseJSONLDirectory(config.InputPath)
+		logs, err := parser.ParseJSONLDirectoryContext(ctx, config.InputPath, config.Jobs)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse directory: %w", err)
 		}
+		logs = selectProject(logs, config.Project)
 
-		// Apply filtering to all logs
+		// Apply filtering to all logs. Images are extracted first so that image-only messages are not filtered out as empty.
 		filteredLogs := make([]*types.ConversationLog, len(logs))
+		images := make([][]assets.Image, len(logs))
 		for i, log := range logs {
-			filteredLogs[i] = formatter.FilterConversationLog(log, !config.IncludeAll)
+			if config.ExtractImages {
+				images[i] = assets.Extract(log)
+			}
+			filteredLogs[i] = selectMessages(log, config, toolFilter)
+			if redactor != nil {
+				redactor.Log(filteredLogs[i])
+			}
 		}
 
-		markdown = formatter.FormatMultipleConversationsToMarkdown(filteredLogs, formatter.FormatOptions{
-			ShowUUID:         config.ShowUUID,
-			ShowPlaceholders: config.IncludeAll,
-		})
+		if config.SplitOutput != "" {
+			summary, err := writeSplitOutput(ctx, filteredLogs, images, config.SplitOutput, config, formatOptions, postHook)
+			if err == nil && redactor != nil {
+				summary += redactor.Report() + "\n"
+			}
+			return summary, err
+		}
+
+		switch {
+		case config.Outline:
+			markdown = formatter.FormatMultipleConversationsOutline(filteredLogs)
+		case config.Format == formatter.FormatContext:
+			markdown = formatter.FormatMultipleConversationsContext(filteredLogs, config.MaxTokens)
+		case config.Format == formatter.FormatCSV || config.Format == formatter.FormatTSV:
+			markdown = formatter.FormatConversationsTable(filteredLogs, tableSeparator(config.Format), formatOptions.Location())
+		case formatter.IsShareFormat(config.Format):
+			chunks = formatter.FormatShareChunks(filteredLogs, config.Format, formatOptions)
+			markdown = formatter.JoinShareChunks(chunks)
+		case config.Format == eml.Format:
+			stream = func(w io.Writer) error {
+				return eml.WriteMbox(w, filteredLogs, formatOptions)
+			}
+		default:
+			stream = func(w io.Writer) error {
+				return formatter.WriteMultipleConversationsMarkdown(w, filteredLogs, config.Jobs, formatOptions)
+			}
+		}
+		for _, logImages := range images {
+			outputImages = append(outputImages, logImages...)
+		}
 
 		// Add title if requested
-		if config.ShowTitle && len(filteredLogs) > 0 {
-			title := types.ExtractTitle(filteredLogs[0])
-			markdown = fmt.Sprintf("# %s\n\n%s", title, markdown)
+		if config.ShowTitle && len(filteredLogs) > 0 && isMarkdownFormat(config.Format) {
+			markdown, stream = addTitle(types.ExtractTitle(filteredLogs[0]), markdown, stream)
 		}
 	} else {
 		// Parse single file
-		log, err := parser.ParseJSONLFile(config.InputPath)
+		log, err := parser.ParseJSONLFileContext(ctx, config.InputPath)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse file: %w", err)
 		}
 
-		// Apply filtering
-		filteredLog := formatter.FilterConversationLog(log, !config.IncludeAll)
-		markdown = formatter.FormatConversationToMarkdown(filteredLog, formatter.FormatOptions{
-			ShowUUID:         config.ShowUUID,
-			ShowPlaceholders: config.IncludeAll,
-		})
+		// Apply filtering, after extracting images so that image-only messages are kept
+		if config.ExtractImages {
+			outputImages = assets.Extract(log)
+		}
+		filteredLog := selectMessages(log, config, toolFilter)
+		if config.Anchor != "" {
+			filteredLog, err = formatter.FocusMessages(filteredLog, config.Anchor, config.AnchorContext)
+			if err != nil {
+				return "", fmt.Errorf("%w (messages dropped by the filters are not searched; try --include-all)", err)
+			}
+		}
+		// The summary lists the files of every tool call, including the calls dropped by the filters
+		var block string
+		if config.SummaryBlock {
+			block = summaryBlock(log, redactor)
+		}
+		if config.GitAnnotate {
+			formatOptions.Markers = gitMarkers(ctx, filteredLog, formatOptions.Location(), redactor)
+		}
+		if redactor != nil {
+			redactor.Log(filteredLog)
+		}
+		hookEvent = hook.SessionEvent(hook.EventWritten, config.OutputPath, config.InputPath, filteredLog)
+		title = types.ExtractTitle(filteredLog)
+		switch {
+		case config.Outline:
+			markdown = formatter.FormatConversationOutline(filteredLog)
+		case config.Format == formatter.FormatContext:
+			markdown = formatter.FormatConversationContext(filteredLog, config.MaxTokens)
+		case config.Format == formatter.FormatCSV || config.Format == formatter.FormatTSV:
+			markdown = formatter.FormatConversationsTable([]*types.ConversationLog{filteredLog}, tableSeparator(config.Format), formatOptions.Location())
+		case formatter.IsShareFormat(config.Format):
+			chunks = formatter.FormatShareChunks([]*types.ConversationLog{filteredLog}, config.Format, formatOptions)
+			markdown = formatter.JoinShareChunks(chunks)
+		case config.Format == eml.Format:
+			stream = func(w io.Writer) error {
+				return eml.WriteMessage(w, filteredLog, formatOptions)
+			}
+		default:
+			stream = func(w io.Writer) error {
+				return formatter.WriteConversationMarkdown(w, filteredLog, formatOptions)
+			}
+		}
+
+		if block != "" {
+			markdown, stream = prependMarkdown(block, markdown, stream)
+		}
 
 		// Add title if requested
-		if config.ShowTitle {
-			title := types.ExtractTitle(filteredLog)
-			markdown = fmt.Sprintf("# %s\n\n%s", title, markdown)
+		if config.ShowTitle && isMarkdownFormat(config.Format) {
+			markdown, stream = addTitle(types.ExtractTitle(filteredLog), markdown, stream)
 		}
 	}
 
 	// Write output if specified
 	if config.OutputPath != "" {
-		// Create output directory if it doesn't exist
-		outputDir := filepath.Dir(config.OutputPath)
-		if outputDir != "." {
-			if err := os.MkdirAll(outputDir, 0755); err != nil {
-				return "", fmt.Errorf("failed to create output directory: %w", err)
+		// Images are written first so that the PDF converter can embed them
+		if err := assets.Write(filepath.Dir(config.OutputPath), outputImages); err != nil {
+			return "", err
+		}
+		switch {
+		case config.Format == pdf.Format:
+			if stream != nil {
+				markdown = formatToString(stream)
 			}
+			err = pdf.Write(ctx, markdown, pdfTitle(config), config.OutputPath)
+		case stream != nil:
+			err = writeOutputFileWith(config.OutputPath, stream)
+		default:
+			err = writeOutputFile(config.OutputPath, markdown)
 		}
-
-		if err := os.WriteFile(config.OutputPath, []byte(markdown), 0644); err != nil {
-			return "", fmt.Errorf("failed to write output file: %w", err)
+		if err != nil {
+			return "", err
+		}
+		if postHook != nil {
+			if err := postHook.Run(ctx, hookEvent); err != nil {
+				return "", fmt.Errorf("wrote %s, but %w", config.OutputPath, err)
+			}
+		}
+	} else if config.Webhook != "" {
+		if err := postWebhook(ctx, config, chunks); err != nil {
+			return "", err
+		}
+		markdown = fmt.Sprintf("Posted %d message(s) to the webhook\n", len(chunks))
+	} else if config.Gist {
+		if stream != nil {
+			markdown = formatToString(stream)
+		}
+		gistURL, err := createGist(ctx, config, title, markdown)
+		if err != nil {
+			return "", err
 		}
+		markdown = gistURL + "\n"
+	} else if stream != nil {
+		if config.Stdout == nil {
+			markdown = formatToString(stream)
+		} else if err := stream(config.Stdout); err != nil {
+			return "", fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+
+	// The report goes to stderr so it never ends up in markdown printed to stdout
+	if redactor != nil {
+		fmt.Fprintln(os.Stderr, redactor.Report())
 	}
 
 	return markdown, nil
 }
 
+// writeOutputFile writes content to path, creating its directory if it doesn't exist
+func writeOutputFile(path, content string) error {
+	return writeOutputFileWith(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+}
+
+// writeOutputFileWith creates path, and its directory if it doesn't exist, and writes it with write
+func writeOutputFileWith(path string, write func(w io.Writer) error) error {
+	outputDir := filepath.Dir(path)
+	if outputDir != "." {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	if err := outfile.Create(path, write); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// formatToString returns the markdown written by stream
+func formatToString(stream func(w io.Writer) error) string {
+	var sb strings.Builder
+	stream(&sb) // Writing to a strings.Builder never fails
+	return sb.String()
+}
+
+// addTitle puts a "# title" heading before the markdown, or before the markdown written by stream if it is set
+func addTitle(title, markdown string, stream func(w io.Writer) error) (string, func(w io.Writer) error) {
+	return prependMarkdown(fmt.Sprintf("# %s\n\n", title), markdown, stream)
+}
+
+// prependMarkdown puts header before the output, which is markdown, or written by stream if it is not nil
+func prependMarkdown(header, markdown string, stream func(w io.Writer) error) (string, func(w io.Writer) error) {
+	if stream == nil {
+		return header + markdown, nil
+	}
+	return markdown, func(w io.Writer) error {
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+		return stream(w)
+	}
+}
+
+// selectMessages applies the message filters, then keeps only the selected tools and roles
+func selectMessages(log *types.ConversationLog, config Config, tools filter.ToolFilter) *types.ConversationLog {
+	filtered := formatter.FilterConversationLog(log, !config.IncludeAll)
+	return filter.FilterRoles(tools.Apply(filtered), config.Roles)
+}
+
+// isMarkdownFormat reports whether format produces markdown, which titles can be added to
+func isMarkdownFormat(format string) bool {
+	return format == "" || format == formatter.FormatMarkdown || format == pdf.Format
+}
+
+// pdfTitle returns the document title of a PDF, the output file name without its extension
+func pdfTitle(config Config) string {
+	name := filepath.Base(config.OutputPath)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// tableSeparator returns the field separator of a table format
+func tableSeparator(format string) rune {
+	if format == formatter.FormatTSV {
+		return '\t'
+	}
+	return ','
+}
+
+// selectProject keeps the logs whose project matches pattern (all logs for an empty pattern)
+func selectProject(logs []*types.ConversationLog, pattern string) []*types.ConversationLog {
+	if pattern == "" {
+		return logs
+	}
+	var selected []*types.ConversationLog
+	for _, log := range logs {
+		if types.MatchProject(pattern, types.ProjectName(log)) {
+			selected = append(selected, log)
+		}
+	}
+	return selected
+}
+
+// configureFilters sets the filter rules from the settings and the flags, which add to them
+func configureFilters(config Config, saved settings.Settings) error {
+	disable := append(slices.Clone(saved.DisableFilters), config.DisableFilters...)
+	if config.ShowRequestIDs {
+		// API errors are shown, marked with their request IDs, instead of dropped
+		disable = append(disable, filter.APIErrorRule)
+	}
+	rules, err := filter.FromConfig(filter.Config{
+		Disable:         disable,
+		ExcludePatterns: append(slices.Clone(saved.ExcludePatterns), config.ExcludePatterns...),
+	})
+	if err != nil {
+		return err
+	}
+	filter.SetCurrent(rules)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadSettings reads the saved user settings. Missing settings yield defaults; unreadable ones yield
+// defaults and the error, for the callers that must not go on without them.
+func loadSettings() (settings.Settings, error) {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		return settings.Settings{}, nil
+	}
+	return settings.Load(path)
+}
+
+// resolveTimezone returns the timezone name from the flag or the timezone setting, in that order
+func resolveTimezone(flag string, saved settings.Settings) string {
+	if flag != "" {
+		return flag
+	}
+	return saved.Timezone
+}
+
+// resolveHook returns the post-export hook from the flag or the postExportHook setting, in that order,
+// or nil if there is none or --no-hook is given
+func resolveHook(config Config, saved settings.Settings) *hook.Hook {
+	if config.NoHook {
+		return nil
+	}
+	command := config.Hook
+	if command == "" {
+		command = saved.PostExportHook
+	}
+	if command == "" {
+		return nil
+	}
+	return &hook.Hook{Command: command, Timeout: time.Duration(saved.HookTimeout) * time.Second}
+}
+
 // GetHelpText returns the help text for the command
 func GetHelpText() string {
+	// Without a command, cclog converts its input or opens the TUI, so it takes the options of both
+	convert, _ := findCommand("convert")
+	browse, _ := findCommand("browse")
+	var shortcutOptions []option
+	for _, opt := range options {
+		if convert.accepts(opt.names[0]) || browse.accepts(opt.names[0]) {
+			shortcutOptions = append(shortcutOptions, opt)
+		}
+	}
+
+	var usage strings.Builder
+	usage.WriteString("    cclog [OPTIONS] [input]\n")
+	for _, cmd := range commands {
+		usage.WriteString("    " + cmd.usage + "\n")
+	}
+
 	return strings.TrimSpace(`
 cclog - Claude Conversation Log to Markdown Converter
 
 USAGE:
-    cclog [OPTIONS] [input]
-
+` + usage.String() + `
 ARGUMENTS:
     [input]    Path to JSONL file or directory containing JSONL files
                (If no input provided, opens interactive TUI mode with recursive search)
 
 OPTIONS:
-    -d, --directory    Treat input as directory (parse all .jsonl files)
-    -o, --output FILE  Write output to file instead of stdout
-    --include-all      Include all messages (no filtering of empty/system messages)
-    --show-uuid        Show UUID metadata for each message
-    --show-title       Show conversation title as header
-    --tui              Open interactive file picker (TUI mode)
-    -r, --recursive    Recursively search for .jsonl files and open TUI mode
-    --path PATH        Specify directory path for TUI mode
-    -h, --help         Show this help message
+` + formatOptions(shortcutOptions) + `
+COMMANDS:
+` + formatCommands() + `
+    Run 'cclog <command> -h' for the options of a command.
 
 EXAMPLES:
     # Open interactive file picker with recursive search (default behavior)
@@ -248,6 +1456,21 @@ EXAMPLES:
     # Convert all JSONL files in directory
     cclog -d /path/to/logs -o combined.md
 
+    # Archive every conversation of a project as its own markdown file
+    cclog -d /path/to/logs --split-output archive/
+
+    # Skim a long session as an outline of prompts and replies
+    cclog outline conversation.jsonl
+
+    # Nightly export of all sessions, converting only new or changed ones
+    cclog export ~/.claude/projects -o ~/claude-logs
+
+    # Build a browsable knowledge base of all sessions
+    cclog kb ~/.claude/projects --out ~/claude-kb
+
+    # Render the message tree of an agentic session with Graphviz
+    cclog graph conversation.jsonl | dot -Tsvg -o conversation.svg
+
     # Recursively find and list all JSONL files (explicit recursive mode)
     cclog -r /path/to/logs
 
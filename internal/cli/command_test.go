@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"strings"
@@ -232,6 +233,36 @@ func TestRunCommand(t *testing.T) {
 	}
 }
 
+func TestRunCommandStreamsToStdout(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Fix the build"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"u1"}`
+	if err := os.WriteFile(input, []byte(content+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	want, err := RunCommand(Config{InputPath: input, ShowTitle: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	var stdout strings.Builder
+	output, err := RunCommand(Config{InputPath: input, ShowTitle: true, Stdout: &stdout})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if output != "" || stdout.String() != want {
+		t.Errorf("Expected the markdown written to Stdout only, got %q and:\n%s\nwant:\n%s", output, stdout.String(), want)
+	}
+	if !strings.HasPrefix(want, "# Fix the build\n\n# Conversation Log") {
+		t.Errorf("Expected the title before the markdown, got:\n%s", want)
+	}
+
+	// Formats that are not streamed are still returned
+	output, err = RunCommand(Config{InputPath: input, Outline: true, Stdout: &stdout})
+	if err != nil || output == "" {
+		t.Errorf("Expected the outline to be returned, got %q, %v", output, err)
+	}
+}
+
 func TestRunCommandWithDirectory(t *testing.T) {
 	// Create a temporary directory with test files
 	tempDir := t.TempDir()
@@ -337,3 +368,734 @@ func TestGetDefaultTUIDirectory_FallbackBehavior(t *testing.T) {
 		t.Errorf("Expected %s when .claude doesn't exist, got %s", expected, result)
 	}
 }
+
+func TestParseArgsSelfUpdate(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "self-update"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.SelfUpdate {
+		t.Error("Expected SelfUpdate to be true")
+	}
+	if config.TUIMode {
+		t.Error("self-update should not start TUI mode")
+	}
+
+	config, err = ParseArgs([]string{"cclog", "self-update", "--force"})
+	if err != nil || !config.SelfUpdate || !config.Force {
+		t.Errorf("Expected a forced self-update, got %+v, %v", config, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "self-update", "--bogus"}); err == nil {
+		t.Error("Expected an error for an unknown self-update option")
+	}
+}
+
+func TestParseArgsCollapse(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "file.jsonl", "--collapse", "40"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.CollapseThreshold != 40 {
+		t.Errorf("Expected CollapseThreshold 40, got %d", config.CollapseThreshold)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", "file.jsonl", "--collapse"},
+		{"cclog", "file.jsonl", "--collapse", "abc"},
+		{"cclog", "file.jsonl", "--collapse", "-1"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for args %v", args)
+		}
+	}
+}
+
+func TestParseArgsProfile(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "file.jsonl", "--profile", "print"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Profile != "print" {
+		t.Errorf("Expected Profile print, got %q", config.Profile)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "file.jsonl", "--profile"}); err == nil {
+		t.Error("Expected error for --profile without a value")
+	}
+}
+
+func TestParseArgsNoColor(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "--tui", "--no-color"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.NoColor {
+		t.Error("Expected NoColor to be true")
+	}
+	if !config.TUIMode {
+		t.Error("Expected TUIMode to be true")
+	}
+}
+
+func TestParseArgsOutline(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "outline", "file.jsonl", "-o", "outline.md"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Outline {
+		t.Error("Expected Outline to be true")
+	}
+	if config.InputPath != "file.jsonl" || config.OutputPath != "outline.md" {
+		t.Errorf("Unexpected paths: input %q, output %q", config.InputPath, config.OutputPath)
+	}
+	if config.TUIMode {
+		t.Error("outline should not start TUI mode")
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "outline"}); err == nil {
+		t.Error("Expected error for outline without an input path")
+	}
+}
+
+func TestRunCommandOutline(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.jsonl")
+
+	testContent := `{"type":"user","message":{"role":"user","content":"What does this do?"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"test-uuid"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"It parses logs. More detail follows."}]},"timestamp":"2025-07-06T05:01:30.618Z","uuid":"test-uuid-2"}`
+
+	if err := os.WriteFile(testFile, []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	output, err := RunCommand(Config{InputPath: testFile, Outline: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+
+	if !strings.Contains(output, "1. What does this do?\n   - It parses logs.\n") {
+		t.Errorf("Unexpected outline output:\n%s", output)
+	}
+	if strings.Contains(output, "More detail follows") {
+		t.Error("Outline should only contain the first sentence of the reply")
+	}
+}
+
+func TestParseArgsJobs(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "-d", "logs", "--jobs", "4"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Jobs != 4 {
+		t.Errorf("Expected 4 jobs, got %d", config.Jobs)
+	}
+
+	for _, value := range []string{"0", "-1", "many"} {
+		if _, err := ParseArgs([]string{"cclog", "-d", "logs", "--jobs", value}); err == nil {
+			t.Errorf("Expected error for --jobs %s", value)
+		}
+	}
+}
+
+func TestParseArgsShowModel(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "session.jsonl", "--show-model"})
+	if err != nil || !config.ShowModel {
+		t.Errorf("Expected ShowModel, got %+v, %v", config, err)
+	}
+}
+
+func TestParseArgsWrap(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "session.jsonl", "--wrap", "72"})
+	if err != nil || config.Wrap != 72 {
+		t.Errorf("Expected a wrap width of 72, got %d, %v", config.Wrap, err)
+	}
+	for _, value := range []string{"0", "wide"} {
+		if _, err := ParseArgs([]string{"cclog", "session.jsonl", "--wrap", value}); err == nil {
+			t.Errorf("Expected error for --wrap %s", value)
+		}
+	}
+}
+
+func TestParseArgsToolOutput(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "session.jsonl", "--include-all", "--max-tool-output", "2000"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.MaxToolOutput != 2000 {
+		t.Errorf("Expected a limit of 2000, got %d", config.MaxToolOutput)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "session.jsonl", "--include-all", "--tools", "Bash, Edit", "--exclude-tools", "WebSearch", "--tools", "Write"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(config.Tools, ",") != "Bash,Edit,Write" || strings.Join(config.ExcludeTools, ",") != "WebSearch" {
+		t.Errorf("Unexpected tool lists: %v, %v", config.Tools, config.ExcludeTools)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "session.jsonl", "--include-all", "--pair-tools"})
+	if err != nil || !config.PairTools {
+		t.Errorf("Expected PairTools, got %+v, %v", config, err)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "session.jsonl", "--include-all", "--show-tools"})
+	if err != nil || !config.ShowTools {
+		t.Errorf("Expected ShowTools, got %+v, %v", config, err)
+	}
+	for _, args := range [][]string{
+		{"--show-tools"},
+		{"--include-all", "--show-tools", "--format", "context"},
+		{"--include-all", "--show-tools", "--format", "csv"},
+		{"--include-all", "--show-tools", "--format", "slack"},
+	} {
+		if _, err := ParseArgs(append([]string{"cclog", "session.jsonl"}, args...)); err == nil || !strings.Contains(err.Error(), "--show-tools") {
+			t.Errorf("Expected --show-tools to be rejected with %v, got %v", args, err)
+		}
+	}
+
+	for _, value := range []string{"0", "-5", "lots"} {
+		if _, err := ParseArgs([]string{"cclog", "session.jsonl", "--max-tool-output", value}); err == nil {
+			t.Errorf("Expected error for --max-tool-output %s", value)
+		}
+	}
+}
+
+func TestParseArgsRoles(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "logs", "-d", "--only", "user"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(config.Roles, ",") != "user" {
+		t.Errorf("Expected the user role, got %v", config.Roles)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "logs", "-d", "--roles", "user,assistant"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(config.Roles, ",") != "user,assistant" {
+		t.Errorf("Expected user and assistant roles, got %v", config.Roles)
+	}
+
+	for _, args := range [][]string{{"--only", "user,assistant"}, {"--roles", "human"}} {
+		if _, err := ParseArgs(append([]string{"cclog", "logs"}, args...)); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+func TestParseArgsTimestamps(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "session.jsonl", "--timezone", "UTC"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Timezone != "UTC" {
+		t.Errorf("Expected timezone UTC, got %q", config.Timezone)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "session.jsonl", "--no-timestamps"})
+	if err != nil || !config.NoTimestamps {
+		t.Errorf("Expected NoTimestamps, got %+v, %v", config, err)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "session.jsonl", "--timezone"}); err == nil {
+		t.Error("Expected error for --timezone without a value")
+	}
+}
+
+func TestParseArgsExport(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "export", "logs", "-o", "out", "--force"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Export || !config.Force {
+		t.Errorf("Expected Export and Force, got %+v", config)
+	}
+	if config.InputPath != "logs" || config.OutputPath != "out" {
+		t.Errorf("Unexpected paths: input %q, output %q", config.InputPath, config.OutputPath)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "export", "logs"}); err == nil {
+		t.Error("Expected error for export without an output directory")
+	}
+}
+
+func TestRunCommandExport(t *testing.T) {
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "out")
+	testContent := `{"type":"user","message":{"role":"user","content":"test"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"test-uuid"}`
+	if err := os.WriteFile(filepath.Join(input, "test.jsonl"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := Config{InputPath: input, OutputPath: output, Export: true}
+	summary, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(summary, "1 new, 0 updated, 0 skipped") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+
+	summary, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(summary, "0 new, 0 updated, 1 skipped") {
+		t.Errorf("Unexpected summary on second run %q", summary)
+	}
+}
+
+func TestRunCommandReadOnly(t *testing.T) {
+	parsed, err := ParseArgs([]string{"cclog", "export", "logs", "-o", "out", "--read-only"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !parsed.ReadOnly {
+		t.Error("Expected ReadOnly to be true")
+	}
+
+	input := t.TempDir()
+	testContent := `{"type":"user","message":{"role":"user","content":"test"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"test-uuid"}`
+	if err := os.WriteFile(filepath.Join(input, "test.jsonl"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := Config{InputPath: input, OutputPath: filepath.Join(input, "markdown"), Export: true, ReadOnly: true}
+	if _, err := RunCommand(config); err == nil || !strings.Contains(err.Error(), "read-only log directory") {
+		t.Fatalf("Expected read-only error for output inside the logs, got %v", err)
+	}
+	if _, err := os.Stat(config.OutputPath); !os.IsNotExist(err) {
+		t.Error("Nothing should be written inside a read-only log directory")
+	}
+
+	config.OutputPath = filepath.Join(t.TempDir(), "markdown")
+	if _, err := RunCommand(config); err != nil {
+		t.Fatalf("Output outside the logs should be allowed: %v", err)
+	}
+}
+
+func TestRunCommandGraph(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.jsonl")
+	testContent := `{"type":"user","message":{"role":"user","content":"Hello"},"uuid":"u1","parentUuid":null}
+{"type":"assistant","message":{"role":"assistant","content":"Hi"},"uuid":"a1","parentUuid":"u1"}`
+	if err := os.WriteFile(testFile, []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := ParseArgs([]string{"cclog", "graph", testFile, "--format", "mermaid"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Graph || config.Format != "mermaid" || config.TUIMode {
+		t.Fatalf("Unexpected config %+v", config)
+	}
+
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "flowchart TD\n") || !strings.Contains(result, "m1 --> m2") {
+		t.Errorf("Unexpected graph:\n%s", result)
+	}
+
+	config.Format = "png"
+	if _, err := RunCommand(config); err == nil {
+		t.Error("Expected an error for an unknown graph format")
+	}
+}
+
+func TestRunCommandKB(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "kb", "logs", "--out", "kb"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.KB || config.TUIMode || config.InputPath != "logs" || config.OutputPath != "kb" {
+		t.Errorf("Unexpected kb config %+v", config)
+	}
+	if _, err := ParseArgs([]string{"cclog", "kb", "logs"}); err == nil {
+		t.Error("Expected error for kb without an output directory")
+	}
+
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "kb")
+	testContent := `{"type":"user","cwd":"/work/app","message":{"role":"user","content":"test #demo"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"test-uuid"}`
+	if err := os.WriteFile(filepath.Join(input, "test.jsonl"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	summary, err := RunCommand(Config{InputPath: input, OutputPath: output, KB: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(summary, "indexed 1 sessions in 1 projects with 1 tags") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	for _, page := range []string{"index.md", "tags.md", filepath.Join("projects", "app.md"), filepath.Join("sessions", "test.md")} {
+		if _, err := os.Stat(filepath.Join(output, page)); err != nil {
+			t.Errorf("Expected %s in the knowledge base: %v", page, err)
+		}
+	}
+}
+
+func TestRunCommandSplitOutput(t *testing.T) {
+	if _, err := ParseArgs([]string{"cclog", "logs", "--split-output", "out"}); err == nil {
+		t.Error("Expected error for --split-output without -d")
+	}
+
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "split")
+	sessions := map[string]string{
+		"a.jsonl": `{"type":"user","message":{"role":"user","content":"Fix the parser"},"timestamp":"2025-07-06T12:00:00Z","uuid":"u1"}`,
+		"b.jsonl": `{"type":"user","message":{"role":"user","content":"Fix the parser"},"timestamp":"2025-07-06T12:00:00Z","uuid":"u2"}`,
+		"c.jsonl": `{"type":"user","message":{"role":"user","content":"Add a flag"},"timestamp":"2025-07-08T12:00:00Z","uuid":"u3"}`,
+	}
+	for name, content := range sessions {
+		if err := os.WriteFile(filepath.Join(input, name), []byte(content+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	config, err := ParseArgs([]string{"cclog", "-d", input, "--split-output", output})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	summary, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(summary, "Wrote 3 files") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+
+	entries, err := os.ReadDir(output)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	// Dates follow the local timezone, so only check the title part and the numbering of duplicates
+	joined := strings.Join(names, ",")
+	for _, want := range []string{"-fix-the-parser.md", "-fix-the-parser-2.md", "-add-a-flag.md"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected a file ending in %s, got %v", want, names)
+		}
+	}
+}
+
+func TestRunCommandTimezone(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"hello"},"timestamp":"2025-07-06T23:30:00Z","uuid":"u1"}`
+	if err := os.WriteFile(input, []byte(content+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	markdown, err := RunCommand(Config{InputPath: input, Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(markdown, "**Time:** 2025-07-06 23:30:00") {
+		t.Errorf("Expected the timestamp in UTC:\n%s", markdown)
+	}
+
+	if _, err := RunCommand(Config{InputPath: input, Timezone: "Nowhere/Special"}); err == nil {
+		t.Error("Expected error for an unknown timezone")
+	}
+}
+
+func TestRunCommandRedact(t *testing.T) {
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "out")
+	testContent := `{"type":"user","message":{"role":"user","content":"My key is sk-ant-REDACTED"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"test-uuid"}`
+	if err := os.WriteFile(filepath.Join(input, "test.jsonl"), []byte(testContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	markdown, err := RunCommand(Config{InputPath: filepath.Join(input, "test.jsonl"), Redact: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if strings.Contains(markdown, "sk-ant") || !strings.Contains(markdown, "My key is [REDACTED:api-key]") {
+		t.Errorf("Expected the key to be redacted:\n%s", markdown)
+	}
+
+	summary, err := RunCommand(Config{InputPath: input, OutputPath: output, Export: true, Redact: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(summary, "Redacted 1 secrets (api-key: 1)") {
+		t.Errorf("Expected a redaction report, got %q", summary)
+	}
+	exported, err := os.ReadFile(filepath.Join(output, "test.md"))
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if strings.Contains(string(exported), "sk-ant") {
+		t.Errorf("Exported markdown should not contain the key:\n%s", exported)
+	}
+}
+
+func TestRunCommandRedactMalformedSettings(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	if err := os.MkdirAll(filepath.Join(configDir, "cclog"), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "cclog", "config.json"), []byte(`{"redactPatterns": ["ACME-[0-9]+"],}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, `{"type":"user","message":{"role":"user","content":"Ticket ACME-1234"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"u1"}`)
+
+	markdown, err := RunCommand(Config{InputPath: input, Redact: true})
+	if err == nil || !strings.Contains(err.Error(), "--redact needs the redactPatterns") {
+		t.Fatalf("Expected --redact to fail without its configured patterns, got %v:\n%s", err, markdown)
+	}
+
+	// Commands that do not redact still run with the default settings
+	if _, err := RunCommand(Config{InputPath: input}); err != nil {
+		t.Errorf("Expected a conversion without --redact to succeed, got %v", err)
+	}
+}
+
+func TestRunCommandExtractImages(t *testing.T) {
+	if _, err := ParseArgs([]string{"cclog", "session.jsonl", "--extract-images"}); err == nil {
+		t.Error("Expected error for --extract-images without an output")
+	}
+
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	data := base64.StdEncoding.EncodeToString([]byte("png"))
+	content := `{"type":"user","message":{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + data + `"}}]},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"u1"}`
+	if err := os.WriteFile(input, []byte(content+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	output := filepath.Join(t.TempDir(), "out", "session.md")
+
+	if _, err := RunCommand(Config{InputPath: input, OutputPath: output, ExtractImages: true}); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	markdown, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected the markdown to be written: %v", err)
+	}
+	images, _ := filepath.Glob(filepath.Join(filepath.Dir(output), "assets", "*.png"))
+	if len(images) != 1 {
+		t.Fatalf("Expected one extracted image, got %v", images)
+	}
+	if !strings.Contains(string(markdown), "![image](assets/"+filepath.Base(images[0])+")") {
+		t.Errorf("Expected a link to the image, even in an image-only message:\n%s", markdown)
+	}
+}
+
+func TestRunCommandShowRequestIDs(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"Hello"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"u1"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Hi"}]},"requestId":"req_ok","timestamp":"2025-07-06T05:01:30.618Z","uuid":"a1"}
+{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 529 Overloaded"}]},"isApiErrorMessage":true,"requestId":"req_failed","timestamp":"2025-07-06T05:01:31.618Z","uuid":"a2"}`
+	if err := os.WriteFile(input, []byte(content+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	markdown, err := RunCommand(Config{InputPath: input})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if strings.Contains(markdown, "API Error") || strings.Contains(markdown, "req_ok") {
+		t.Errorf("Expected API errors dropped and no request IDs by default:\n%s", markdown)
+	}
+
+	markdown, err = RunCommand(Config{InputPath: input, ShowRequestIDs: true})
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	for _, want := range []string{"Hi\n\n*Request ID: req_ok*", "> [!CAUTION]\n> API error (request req_failed)\n\nAPI Error: 529 Overloaded"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in:\n%s", want, markdown)
+		}
+	}
+	// Later tests use the default rules again
+	if _, err := RunCommand(Config{InputPath: input}); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+}
+
+func TestRunCommandFilterRules(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"<local-command-stdout>build ok</local-command-stdout>"},"timestamp":"2025-07-06T05:01:29.618Z","uuid":"u1"}
+{"type":"user","message":{"role":"user","content":"Draft for ACME-42"},"timestamp":"2025-07-06T05:02:29.618Z","uuid":"u2"}`
+	if err := os.WriteFile(input, []byte(content+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config, err := ParseArgs([]string{"cclog", input, "--disable-filter", "command-output", "--exclude-pattern", `ACME-\d+`})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	markdown, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(markdown, "build ok") || strings.Contains(markdown, "ACME-42") {
+		t.Errorf("Expected command output kept and the ticket message dropped:\n%s", markdown)
+	}
+
+	if _, err := RunCommand(Config{InputPath: input, DisableFilters: []string{"nonexistent"}}); err == nil {
+		t.Error("Expected error for an unknown filter rule")
+	}
+	// Later tests use the default rules again
+	if _, err := RunCommand(Config{InputPath: input}); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+}
+
+func TestRunCommandProject(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "a.jsonl"), `{"type":"user","cwd":"/src/cclog","message":{"role":"user","content":"cclog prompt"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+	writeTestSession(t, filepath.Join(input, "b.jsonl"), `{"type":"user","cwd":"/src/other","message":{"role":"user","content":"other prompt"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+
+	config, err := ParseArgs([]string{"cclog", "-d", input, "--project", "cc*"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(result, "cclog prompt") || strings.Contains(result, "other prompt") {
+		t.Errorf("Expected only the cclog session, got:\n%s", result)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "search", "prompt", input, "--project", "other"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if strings.Contains(result, "cclog prompt") || !strings.Contains(result, "1 messages in 1 sessions") {
+		t.Errorf("Expected only the other session, got:\n%s", result)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "-d", input, "--project", "["}); err == nil {
+		t.Error("Expected error for a malformed project pattern")
+	}
+}
+
+func TestParseArgsFormat(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "conversation.jsonl", "--format", "context", "--max-tokens", "2000"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Format != "context" || config.MaxTokens != 2000 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", "conversation.jsonl", "--format", "html"},
+		{"cclog", "conversation.jsonl", "--format", "context", "--max-tokens", "0"},
+		{"cclog", "-d", "logs", "--format", "context", "--split-output", "out"},
+		{"cclog", "conversation.jsonl", "--format", "pdf"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args[1:])
+		}
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "conversation.jsonl", "--format", "pdf", "-o", "design.pdf"}); err != nil {
+		t.Errorf("Unexpected error for --format pdf with -o: %v", err)
+	}
+}
+
+func TestRunCommandTOC(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, searchContent)
+
+	config, err := ParseArgs([]string{"cclog", input, "--toc"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(result, "## Contents\n\n1. [Why is the build flaky?](#turn-1)") || !strings.Contains(result, "<a id=\"turn-1\"></a>") {
+		t.Errorf("Expected a table of contents linking to the prompt, got:\n%s", result)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", "-d", "logs", "--toc"},
+		{"cclog", input, "--toc", "--format", "context"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args[1:])
+		}
+	}
+	if _, err := ParseArgs([]string{"cclog", "-d", "logs", "--toc", "--split-output", "out"}); err != nil {
+		t.Errorf("Unexpected error for --toc with --split-output: %v", err)
+	}
+}
+
+func TestRunCommandAnchor(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, `{"type":"user","message":{"role":"user","content":"first"},"uuid":"11111111-0000","timestamp":"2025-07-06T05:00:00.000Z"}
+{"type":"assistant","message":{"role":"assistant","content":"second"},"uuid":"22222222-0000","timestamp":"2025-07-06T05:01:00.000Z"}
+{"type":"user","message":{"role":"user","content":"third"},"uuid":"33333333-0000","timestamp":"2025-07-06T05:02:00.000Z"}
+{"type":"assistant","message":{"role":"assistant","content":"fourth"},"uuid":"44444444-0000","timestamp":"2025-07-06T05:03:00.000Z"}
+`)
+
+	config, err := ParseArgs([]string{"cclog", input, "--anchor", "3333", "--anchor-context", "1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if strings.Contains(result, "first") || !strings.Contains(result, "second") || !strings.Contains(result, "fourth") {
+		t.Errorf("Expected the anchored message with one message around it, got:\n%s", result)
+	}
+	if !strings.Contains(result, `<a id="msg-33333333"></a>`) {
+		t.Errorf("Expected message anchors, got:\n%s", result)
+	}
+
+	config.Anchor = "5555"
+	if _, err := RunCommand(config); err == nil {
+		t.Error("Expected error for an unknown UUID")
+	}
+	if _, err := ParseArgs([]string{"cclog", "-d", "logs", "--anchor", "3333"}); err == nil {
+		t.Error("Expected error for --anchor with -d")
+	}
+	if config, err := ParseArgs([]string{"cclog", input, "--anchor", "3333"}); err != nil || config.AnchorContext != 3 {
+		t.Errorf("Expected 3 messages of context by default, got %+v, %v", config, err)
+	}
+}
+
+func TestClaudeProjectsDirs(t *testing.T) {
+	home := filepath.Join("home", "me")
+	dirs := claudeProjectsDirs(home)
+	if len(dirs) == 0 || dirs[0] != filepath.Join(home, ".claude", "projects") {
+		t.Errorf("Expected ~/.claude/projects to be preferred, got %v", dirs)
+	}
+	for _, dir := range dirs {
+		if filepath.Base(dir) != "projects" {
+			t.Errorf("Expected a projects directory, got %s", dir)
+		}
+	}
+}
+
+func TestParseArgsRepair(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "session.jsonl", "--repair", "cleaned.jsonl"})
+	if err != nil || config.Repair != "cleaned.jsonl" {
+		t.Errorf("Expected the cleaned copy cleaned.jsonl, got %q, %v", config.Repair, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "-d", "logs", "--repair", "cleaned.jsonl"}); err == nil {
+		t.Error("Expected error for --repair with -d")
+	}
+	if _, err := ParseArgs([]string{"cclog", "session.jsonl", "--repair"}); err == nil {
+		t.Error("Expected error for --repair without a value")
+	}
+}
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// option documents a command-line option for the help text
+type option struct {
+	names []string // Spellings accepted by ParseArgs, e.g. "-o" and "--output"
+	usage string   // Left column of the help text, e.g. "-o, --output FILE"
+	help  string   // Description; each line is indented to the description column
+}
+
+// options lists every option in the order the help text shows them
+var options = []option{
+	{[]string{"-d", "--directory"}, "-d, --directory", "Treat input as directory (parse all .jsonl files)"},
+	{[]string{"-o", "--output", "--out"}, "-o, --output FILE", "Write output to file instead of stdout (--out is an alias)"},
+	{[]string{"--jobs"}, "--jobs N", "Convert up to N files at once in directory mode (default: one per CPU)"},
+	{[]string{"--split-output"}, "--split-output DIR", "With -d, write one markdown file per conversation into DIR, named\n<date>-<title>.md, instead of one combined document"},
+	{[]string{"--name-template"}, "--name-template T", "File names for --split-output, e.g. \"{{.Date}}-{{.Project}}-{{.TitleSlug}}.md\"\n(fields: Date, Time, Project, Title, TitleSlug, SessionID)"},
+	{[]string{"--include-all"}, "--include-all", "Include all messages (no filtering of empty/system messages)"},
+	{[]string{"--show-uuid"}, "--show-uuid", "Show UUID metadata for each message"},
+	{[]string{"--show-request-ids"}, "--show-request-ids", "Show the request ID of each assistant message and keep API errors,\nmarked with their request IDs, instead of dropping them"},
+	{[]string{"--show-model"}, "--show-model", "Name the model next to each Assistant heading and show the\nClaude Code version where it changes"},
+	{[]string{"--show-title"}, "--show-title", "Show conversation title as header"},
+	{[]string{"--collapse"}, "--collapse N", "Collapse messages longer than N lines into expandable <details> blocks"},
+	{[]string{"--profile"}, "--profile NAME", "Output profile: default, or print (numbered messages, page breaks, no collapsing)"},
+	{[]string{"--max-tool-output"}, "--max-tool-output N", "With --include-all, cut tool results longer than N characters\nand mark them with \"[truncated X chars]\""},
+	{[]string{"--wrap"}, "--wrap N", "Hard-wrap prose lines of messages at N columns, leaving code blocks,\ntables and headings untouched"},
+	{[]string{"--disable-filter"}, "--disable-filter R", "Keep messages that filter rule R would drop, e.g. command-output\n(rules: type:system, type:summary, meta, empty, api-error, interrupted,\ncommand, bash-input, command-output, caveat)"},
+	{[]string{"--exclude-pattern"}, "--exclude-pattern RE", "Also drop messages whose content matches the regular expression RE"},
+	{[]string{"--tools"}, "--tools A,B", "With --include-all, only show the calls and results of these tools"},
+	{[]string{"--exclude-tools"}, "--exclude-tools A,B", "With --include-all, hide the calls and results of these tools"},
+	{[]string{"--only"}, "--only ROLE", "Only show messages of one role: user (your prompts), assistant or tool (tool results)"},
+	{[]string{"--roles"}, "--roles A,B", "Only show messages of these roles, e.g. user,assistant"},
+	{[]string{"--show-tools"}, "--show-tools", "With --include-all, add a table of the tool calls after each assistant turn\n(tool, input, duration and status)"},
+	{[]string{"--pair-tools"}, "--pair-tools", "With --include-all, show each tool's output right after its call\n(Bash, WebFetch, Read) instead of in the following message"},
+	{[]string{"--extract-images"}, "--extract-images", "Save pasted images to an assets directory next to the output and link them\n(requires -o or --split-output)"},
+	{[]string{"--redact"}, "--redact", "Replace API keys, tokens and email addresses with [REDACTED:kind] and report\nhow many were replaced (extra patterns: redactPatterns in the config file)"},
+	{[]string{"--toc"}, "--toc", "List your prompts at the top of each conversation, linking to them, to navigate\nlong sessions in rendered viewers (not in combined -d documents)"},
+	{[]string{"--summary-block"}, "--summary-block", "Start the markdown with a summary of the session: the first prompt, the files\nread or changed most and the start of the last answer (see 'cclog summarize')"},
+	{[]string{"--git-annotate"}, "--git-annotate", "Mark where the commits of the session's git repository (its working directory)\nwere made between the messages, to find the conversation behind a commit"},
+	{[]string{"--hook"}, "--hook CMD", "Run the shell command CMD after each file is written, with the file and its\nsession in CCLOG_OUTPUT, CCLOG_INPUT, CCLOG_SESSION_ID, CCLOG_PROJECT,\nCCLOG_TITLE and CCLOG_EVENT (overrides the postExportHook setting)"},
+	{[]string{"--no-hook"}, "--no-hook", "Do not run the hook of the postExportHook setting"},
+	{[]string{"--webhook"}, "--webhook URL", "Post the messages of --format slack or discord to this Slack or Discord\nincoming webhook URL (https only) instead of printing them"},
+	{[]string{"--public"}, "--public", "Create a public gist, listed on your profile, instead of a secret one"},
+	{[]string{"--llm"}, "--llm", "Have the claude CLI, or the Anthropic API if ANTHROPIC_API_KEY is set and claude\nis not installed, write the summary from a compact transcript of the session;\nsummaries are cached until the session changes (--force asks again)"},
+	{[]string{"--by-path"}, "--by-path", "Name each code block after the file edited or written in the same response,\ne.g. 003-parser.go instead of 003-go.go"},
+	{[]string{"--anchors"}, "--anchors", "Put an anchor before each message, e.g. #msg-1a2b3c4d for UUID 1a2b3c4d-...,\nto link to a specific exchange"},
+	{[]string{"--anchor"}, "--anchor UUID", "Only show the message with this UUID (or UUID prefix) and the messages around\nit (implies --anchors)"},
+	{[]string{"--anchor-context"}, "--anchor-context N", "Show N messages before and after the --anchor message (default: 3)"},
+	{[]string{"--no-timestamps"}, "--no-timestamps", "Leave message times out of the markdown, e.g. before sharing a conversation"},
+	{[]string{"--timezone"}, "--timezone NAME", "Render timestamps in this timezone, e.g. UTC or Asia/Tokyo (default: system timezone)"},
+	{[]string{"--format"}, "--format NAME", "Output format: markdown (default); context, a compact H:/A: transcript\nwithout tool calls for pasting into an LLM; csv or tsv, one row of metadata\nper message; slack or discord, the prompts and replies as chat messages in\nSlack mrkdwn or Discord markdown under the message size limit; eml, an email\nmessage (an mbox of them with -d) for archiving in mail clients; pdf, printed\nwith an installed converter such as wkhtmltopdf (requires -o)\n(graph: dot, default, or mermaid; list: table, default, json or tsv;\ncompress: gzip, default, or zstd)"},
+	{[]string{"--max-tokens"}, "--max-tokens N", "Leave out the oldest messages so each conversation fits in about N tokens,\ne.g. before feeding an old session into a new prompt"},
+	{[]string{"--force"}, "--force", "Re-export every session, even if it is unchanged since the last run\n(summarize --llm: ask the LLM again instead of using the cached summary;\nself-update: install the latest release even if this build is newer or not a\nrelease)"},
+	{[]string{"--project"}, "--project NAME", "Only list or convert the sessions of project NAME, the name of their working\ndirectory; globs such as 'cclog*' match several projects"},
+	{[]string{"--session"}, "--session ID", "Convert the session with this sessionId, searched for in the Claude projects\ndirectory and extraRoots (a unique prefix of the ID is enough)"},
+	{[]string{"--sort"}, "--sort ORDER", "Sort the list by date (newest first, default), project, title or messages\n(most first)"},
+	{[]string{"--limit"}, "--limit N", "List at most N sessions"},
+	{[]string{"--columns"}, "--columns A,B", "Only list these columns, in this order: date, project, title, messages,\nsession, path"},
+	{[]string{"--print0"}, "--print0", "With --format tsv, end each session with NUL instead of newline and leave\nthe fields unescaped except for tabs, which become spaces, for fzf --read0\nand xargs -0"},
+	{[]string{"--older-than"}, "--older-than N", "Only compress sessions last written more than N days ago (default: 30)"},
+	{[]string{"--addr"}, "--addr ADDR", "Listen on ADDR, host:port or :port for all interfaces (default: 127.0.0.1:9467)"},
+	{[]string{"--since"}, "--since PERIOD", "Report on the sessions with messages in the last PERIOD, e.g. 7d (default),\n2w or 12h, or since a date (YYYY-MM-DD)"},
+	{[]string{"--speed"}, "--speed X", "Replay X times as fast as the original conversation, e.g. 4 or 0.5 (default: 1)"},
+	{[]string{"--dangerous"}, "--dangerous", "Resume with --dangerously-skip-permissions"},
+	{[]string{"--tui"}, "--tui", "Open interactive file picker (TUI mode)"},
+	{[]string{"--editor"}, "--editor CMD", "Open files from the TUI with CMD, e.g. \"code --wait\", instead of $EDITOR\n(default: the editor setting of the config file)"},
+	{[]string{"--export-dir"}, "--export-dir DIR", "Keep the markdown of files opened from the TUI in DIR, named\n<project>/<date>-<title>-<session>.md, instead of temp files; the file is\nreused while the session is unchanged (default: the exportDir setting)"},
+	{[]string{"--no-color"}, "--no-color", "Plain TUI without colors or Unicode symbols (also enabled by NO_COLOR)"},
+	{[]string{"--repair"}, "--repair FILE", "Salvage the truncated lines of a damaged log, e.g. the last line of a crashed\nsession, into the cleaned copy FILE, report the dropped lines and convert FILE"},
+	{[]string{"--read-only"}, "--read-only", "Never write inside the log directory; temporary files go to the cclog\nstate directory (enabled automatically when the logs are not writable)"},
+	{[]string{"-r", "--recursive"}, "-r, --recursive", "Recursively search for .jsonl files and open TUI mode"},
+	{[]string{"--max-depth"}, "--max-depth N", "Search at most N directory levels below the TUI directory (implies -r)"},
+	{[]string{"--max-files"}, "--max-files N", "Stop the recursive search after N .jsonl files (implies -r)"},
+	{[]string{"--ignore"}, "--ignore GLOB", "Skip files and directories matching GLOB in the recursive search\n(by name or path relative to the TUI directory; repeatable; implies -r)"},
+	{[]string{"--path"}, "--path PATH", "Specify directory path for TUI mode"},
+	{[]string{"-h", "--help"}, "-h, --help", "Show this help message"},
+	{[]string{"--version"}, "--version", "Print the version, commit and build date"},
+}
+
+// command is a subcommand with its own options and help text
+type command struct {
+	name    string
+	usage   string
+	summary string
+	options []string // Long names of the accepted options; -h is always accepted
+}
+
+// Options shared by the commands that write markdown
+var (
+	selectionOptions  = []string{"--include-all", "--disable-filter", "--exclude-pattern", "--tools", "--exclude-tools", "--only", "--roles"}
+	conversionOptions = append([]string{"--output", "--show-uuid", "--show-model", "--show-request-ids", "--collapse", "--profile", "--max-tool-output", "--max-tokens", "--wrap", "--pair-tools", "--show-tools", "--redact", "--toc", "--summary-block", "--anchors", "--no-timestamps", "--timezone"}, selectionOptions...)
+)
+
+// commands lists the subcommands in the order the help text shows them
+var commands = []command{
+	{
+		name:    "convert",
+		usage:   "cclog convert [OPTIONS] <input>",
+		summary: "Convert a JSONL file, or a directory with -d, to markdown\n('cclog <input>' is a shortcut)",
+		options: append([]string{"--directory", "--session", "--anchor", "--anchor-context", "--project", "--format", "--jobs", "--split-output", "--name-template", "--show-title", "--extract-images", "--repair", "--git-annotate", "--hook", "--no-hook", "--webhook", "--read-only"}, conversionOptions...),
+	},
+	{
+		name:    "browse",
+		usage:   "cclog browse [OPTIONS] [dir]",
+		summary: "Open the interactive file picker with recursive search and convert the\nselected file ('cclog' without arguments is a shortcut)",
+		options: append([]string{"--project", "--format", "--show-title", "--tui", "--editor", "--export-dir", "--no-color", "--read-only", "--recursive", "--max-depth", "--max-files", "--ignore", "--path"}, conversionOptions...),
+	},
+	{
+		name:    "search",
+		usage:   "cclog search [OPTIONS] <query> [input]",
+		summary: "List the messages containing query, ignoring case, in the sessions under\ninput (default: the Claude projects directory)",
+		options: append([]string{"--project", "--timezone"}, selectionOptions...),
+	},
+	{
+		name:    "stats",
+		usage:   "cclog stats [OPTIONS] [input]",
+		summary: "Count the sessions under input, their messages by role and their tool\ncalls by tool (default: the Claude projects directory)",
+		options: append([]string{"--project", "--timezone"}, selectionOptions...),
+	},
+	{
+		name:    "digest",
+		usage:   "cclog digest [OPTIONS] [input]",
+		summary: "Write a markdown report of the sessions under input (default: the Claude\nprojects directory) with messages in the --since period, grouped by day and\nproject, with their titles, active time and tokens, e.g. for a standup",
+		options: []string{"--since", "--project", "--timezone", "--output"},
+	},
+	{
+		name:    "serve",
+		usage:   "cclog serve [--addr ADDR] [input]",
+		summary: "Serve Prometheus metrics of the sessions under input (default: the Claude\nprojects directory) at /metrics: sessions by project, messages by project and\nrole, and tokens by project, day and kind, to graph your usage over time",
+		options: []string{"--addr", "--project", "--timezone"},
+	},
+	{
+		name:    "list",
+		usage:   "cclog list [OPTIONS] [input]",
+		summary: "Print a table, or JSON or TSV with --format json|tsv, of the sessions under\ninput (default: the Claude projects directory): date, project, title, message\ncount, sessionId and path",
+		options: []string{"--project", "--format", "--sort", "--limit", "--columns", "--print0", "--timezone"},
+	},
+	{
+		name:    "resume",
+		usage:   "cclog resume [--dangerous] <file|sessionId>",
+		summary: "Resume a session with claude -r in its working directory",
+		options: []string{"--dangerous"},
+	},
+	{
+		name:    "resume-cmd",
+		usage:   "cclog resume-cmd [--dangerous] <file|sessionId>",
+		summary: "Print the command that resumes a session, \"cd <cwd> && claude -r <id>\",\nfor scripts and shell aliases",
+		options: []string{"--dangerous"},
+	},
+	{
+		name:    "replay",
+		usage:   "cclog replay [OPTIONS] <file|sessionId>",
+		summary: "Play a session back in the terminal message by message, waiting as long as\nthe original conversation did (space pauses, +/- change the speed, n skips)",
+		options: append([]string{"--speed", "--no-color", "--show-uuid", "--max-tool-output", "--pair-tools", "--redact", "--no-timestamps", "--timezone"}, selectionOptions...),
+	},
+	{
+		name:    "diff",
+		usage:   "cclog diff [OPTIONS] <a.jsonl> <b.jsonl>",
+		summary: "Print a unified diff of the markdown of two sessions, e.g. two attempts at the\nsame task; messages are aligned by uuid or content before their lines are compared",
+		options: append([]string{"--output", "--show-uuid", "--max-tool-output", "--pair-tools", "--redact"}, selectionOptions...),
+	},
+	{
+		name:    "show",
+		usage:   "cclog show [OPTIONS] <sessionId>",
+		summary: "Convert the session with this sessionId, searched for in the Claude\nprojects directory and extraRoots (same as 'cclog --session ID')",
+		options: append([]string{"--anchor", "--anchor-context", "--format", "--show-title", "--git-annotate", "--hook", "--no-hook", "--webhook"}, conversionOptions...),
+	},
+	{
+		name:    "last",
+		usage:   "cclog last [OPTIONS] [dir]",
+		summary: "Convert the most recently modified session under dir (default: the Claude\nprojects directory), e.g. to recap what you just did",
+		options: append([]string{"--project", "--format", "--show-title", "--git-annotate", "--hook", "--no-hook", "--webhook"}, conversionOptions...),
+	},
+	{
+		name:    "outline",
+		usage:   "cclog outline [OPTIONS] <input>",
+		summary: "Print only user prompts and the first sentence of each assistant reply",
+		options: append([]string{"--directory", "--project", "--output", "--jobs", "--show-title", "--redact", "--read-only"}, selectionOptions...),
+	},
+	{
+		name:    "summarize",
+		usage:   "cclog summarize [OPTIONS] <file>",
+		summary: "Print a short summary of a session taken from the log: its first prompt, the\nfiles it read or changed most and the start of its last answer; with --llm, a\nparagraph written by the claude CLI (or the Anthropic API with\nANTHROPIC_API_KEY), cached and shown in the TUI list",
+		options: []string{"--llm", "--force", "--output", "--redact"},
+	},
+	{
+		name:    "code",
+		usage:   "cclog code [OPTIONS] <file> -o DIR",
+		summary: "Save every fenced code block of the assistant's answers to its own file in\nDIR, named by number and language (001-go.go), e.g. to recover snippets from\nold sessions",
+		options: []string{"--output", "--by-path", "--redact", "--read-only"},
+	},
+	{
+		name:    "edits",
+		usage:   "cclog edits [OPTIONS] <file>",
+		summary: "List the files the assistant changed with Edit, MultiEdit and Write in a session,\nwith when and how: the diff of each change, with line numbers of the file where\nClaude Code recorded them",
+		options: []string{"--output", "--timezone", "--redact", "--read-only"},
+	},
+	{
+		name:    "export",
+		usage:   "cclog export [OPTIONS] <input> -o DIR\n    cclog export gist [OPTIONS] <file>",
+		summary: "Write one markdown file per session into DIR, skipping sessions\nunchanged since the last export (--force re-exports everything); with gist,\nupload the markdown of one session as a secret GitHub gist and print its URL\n(authenticated with GITHUB_TOKEN)",
+		options: append([]string{"--force", "--extract-images", "--hook", "--no-hook", "--public", "--read-only"}, conversionOptions...),
+	},
+	{
+		name:    "sync",
+		usage:   "cclog sync [OPTIONS] [input] --out DIR",
+		summary: "Mirror the sessions under input (default: the Claude projects directory) to\nmarkdown files in DIR: convert the sessions new or changed since the last run\nand delete the files of the sessions removed since",
+		options: append([]string{"--force", "--extract-images", "--hook", "--no-hook", "--read-only"}, conversionOptions...),
+	},
+	{
+		name:    "graph",
+		usage:   "cclog graph [--format dot|mermaid] [-o FILE] <input>",
+		summary: "Print the parentUuid message tree, including sidechains, as a Graphviz\n(--format dot, default) or Mermaid (--format mermaid) graph",
+		options: []string{"--directory", "--output", "--jobs", "--format"},
+	},
+	{
+		name:    "kb",
+		usage:   "cclog kb [OPTIONS] <input> --out DIR",
+		summary: "Export every session into DIR/sessions and build index.md (all sessions\nby date), one page per project in DIR/projects and tags.md listing the\nsessions whose prompts contain each #tag",
+		options: append([]string{"--force", "--extract-images", "--hook", "--no-hook", "--read-only"}, conversionOptions...),
+	},
+	{
+		name:    "validate",
+		usage:   "cclog validate <file|dir>",
+		summary: "Check every line of the JSONL files against the Claude Code log schema\n(required fields, their types and known type values) and report the deviations\nwith line numbers, e.g. for logs written by other tools",
+	},
+	{
+		name:    "compress",
+		usage:   "cclog compress [OPTIONS] [input]",
+		summary: "Archive the sessions under input (default: the Claude projects directory) not\nwritten to for --older-than days in place as .jsonl.gz, or .jsonl.zst with\n--format zstd; compressed sessions are still listed, previewed and converted,\nbut claude -r cannot resume them",
+		options: []string{"--older-than", "--format"},
+	},
+	{
+		name:    "index",
+		usage:   "cclog index build|update [input]",
+		summary: "Build or update the SQLite session index with the sessions under input\n(default: the Claude projects directory); list and search then read the index,\nupdated with the changed sessions, and the TUI the titles of unchanged sessions,\ninstead of parsing every session (update also indexes the sessions indexed with\nother filter settings again; build indexes every session again)",
+	},
+	{
+		name:    "doctor",
+		usage:   "cclog doctor",
+		summary: "Report the version, the log directories found, the editor, clipboard and\nexternal programs cclog would use and the terminal capabilities, for bug\nreports",
+	},
+	{
+		name:    "self-update",
+		usage:   "cclog self-update [--force]",
+		summary: "Download the latest release, verify its checksum and replace this binary if\nthe release is newer (--force also replaces newer and development builds)",
+		options: []string{"--force"},
+	},
+}
+
+// findCommand returns the subcommand with the given name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// findOption returns the option spelled arg
+func findOption(arg string) (option, bool) {
+	for _, opt := range options {
+		if slices.Contains(opt.names, arg) {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+// accepts reports whether arg is one of the command's options
+func (c command) accepts(arg string) bool {
+	opt, ok := findOption(arg)
+	if !ok {
+		return false
+	}
+	long := opt.names[len(opt.names)-1]
+	if opt.names[0] == "-o" {
+		long = "--output" // --out is only an alias
+	}
+	return long == "--help" || slices.Contains(c.options, long)
+}
+
+// help returns the usage, description and options of the command
+func (c command) help() string {
+	var sb strings.Builder
+	sb.WriteString("USAGE:\n    " + c.usage + "\n\n")
+	sb.WriteString(indent(c.summary, "    ") + "\n")
+
+	var accepted []option
+	for _, opt := range options {
+		if c.accepts(opt.names[0]) {
+			accepted = append(accepted, opt)
+		}
+	}
+	sb.WriteString("\nOPTIONS:\n" + formatOptions(accepted))
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatOptions renders options as the two-column list of the help text
+func formatOptions(opts []option) string {
+	const column = 19 // Width of the usage column
+	var sb strings.Builder
+	for _, opt := range opts {
+		help := indent(opt.help, strings.Repeat(" ", 4+column))
+		if len(opt.usage) < column {
+			sb.WriteString(fmt.Sprintf("    %-*s%s\n", column, opt.usage, strings.TrimLeft(help, " ")))
+		} else {
+			sb.WriteString("    " + opt.usage + "\n" + help + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatCommands renders the COMMANDS section of the help text
+func formatCommands() string {
+	var sb strings.Builder
+	for _, cmd := range commands {
+		summary := strings.TrimLeft(indent(cmd.summary, strings.Repeat(" ", 23)), " ")
+		sb.WriteString(fmt.Sprintf("    %-19s%s\n", cmd.name, summary))
+	}
+	return sb.String()
+}
+
+// indent prefixes every line of text
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+// GetCommandHelpText returns the help text of a subcommand, or the general help text for ""
+func GetCommandHelpText(name string) string {
+	cmd, ok := findCommand(name)
+	if !ok {
+		return GetHelpText()
+	}
+	return cmd.help()
+}
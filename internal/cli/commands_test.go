@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgsSubcommands(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "convert", "conversation.jsonl", "--show-uuid"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Command != "convert" || config.InputPath != "conversation.jsonl" || !config.ShowUUID || config.TUIMode {
+		t.Errorf("Unexpected convert config %+v", config)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "browse", "--path", "logs"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.TUIMode || !config.Recursive || config.InputPath != "logs" {
+		t.Errorf("Unexpected browse config %+v", config)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "search", "flaky test", "logs", "--only", "user"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Search || config.Query != "flaky test" || config.InputPath != "logs" {
+		t.Errorf("Unexpected search config %+v", config)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "stats"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Stats || config.InputPath == "" {
+		t.Errorf("Expected stats to default to the projects directory, got %+v", config)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "resume", "--dangerous", "session.jsonl"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Resume || !config.Dangerous || config.InputPath != "session.jsonl" {
+		t.Errorf("Unexpected resume config %+v", config)
+	}
+}
+
+func TestParseArgsSubcommandOptions(t *testing.T) {
+	if _, err := ParseArgs([]string{"cclog", "convert", "--tui", "conversation.jsonl"}); err == nil || !strings.Contains(err.Error(), "--tui") {
+		t.Errorf("Expected error naming the TUI option, got %v", err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "graph", "--redact", "conversation.jsonl"}); err == nil {
+		t.Error("Expected error for an option graph does not take")
+	}
+	if _, err := ParseArgs([]string{"cclog", "search"}); err == nil {
+		t.Error("Expected error for search without a query")
+	}
+
+	// Without a subcommand every option is accepted
+	if _, err := ParseArgs([]string{"cclog", "conversation.jsonl", "--redact", "--format", "context"}); err != nil {
+		t.Errorf("Unexpected error for the convert shortcut: %v", err)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "search", "-h"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	help, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.HasPrefix(help, "USAGE:\n    cclog search") || !strings.Contains(help, "--only ROLE") {
+		t.Errorf("Unexpected search help:\n%s", help)
+	}
+	if strings.Contains(help, "--split-output") {
+		t.Errorf("Search help should not list convert options:\n%s", help)
+	}
+
+	general := GetHelpText()
+	for _, name := range []string{"convert", "browse", "search", "stats", "resume"} {
+		if !strings.Contains(general, "cclog "+name) {
+			t.Errorf("Expected the general help to list %s", name)
+		}
+	}
+	if strings.Contains(general, "    --dangerous") {
+		t.Error("The general help should only list the options of the shortcuts")
+	}
+}
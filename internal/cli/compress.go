@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+// Compression formats of the compress command and the default age of the sessions it archives
+const (
+	compressFormatGzip = "gzip"
+	compressFormatZstd = "zstd"
+
+	defaultCompressAge = 30
+)
+
+// compressExtensions maps the compression formats to the extensions of the compressed logs
+var compressExtensions = map[string]string{
+	compressFormatGzip: parser.ExtGzip,
+	compressFormatZstd: parser.ExtZstd,
+}
+
+// RunCompress compresses the .jsonl sessions under the input path that were last written more than
+// config.OlderThan days ago, in place, and lists them with the space saved. Recent sessions are left
+// alone, since Claude Code may still append to them and claude -r only resumes uncompressed sessions.
+func RunCompress(ctx context.Context, config Config) (string, error) {
+	if isReadOnly(config) {
+		return "", fmt.Errorf("compress replaces the logs in place and cannot be used on read-only logs")
+	}
+	format := config.Format
+	if format == "" {
+		format = compressFormatGzip
+	}
+	ext := compressExtensions[format]
+
+	sessions, _, err := export.FindSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -config.OlderThan)
+	var sb strings.Builder
+	compressed := 0
+	var before, after int64
+	for _, session := range sessions {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if parser.LogExt(session) != parser.ExtJSONL {
+			continue // Already compressed
+		}
+		info, err := os.Stat(session)
+		if err != nil {
+			return "", err
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		output, err := parser.CompressLog(session, ext)
+		if errors.Is(err, fs.ErrExist) {
+			fmt.Fprintf(&sb, "Skipped %s: %s already exists\n", session, parser.TrimLogExt(session)+ext)
+			continue
+		}
+		if err != nil {
+			return sb.String(), err
+		}
+		outputInfo, err := os.Stat(output)
+		if err != nil {
+			return sb.String(), err
+		}
+		compressed++
+		before += info.Size()
+		after += outputInfo.Size()
+		fmt.Fprintf(&sb, "%s (%s -> %s)\n", output, formatSize(info.Size()), formatSize(outputInfo.Size()))
+	}
+
+	if compressed == 0 {
+		fmt.Fprintf(&sb, "No uncompressed sessions older than %d days\n", config.OlderThan)
+	} else {
+		fmt.Fprintf(&sb, "Compressed %d sessions older than %d days: %s -> %s\n", compressed, config.OlderThan, formatSize(before), formatSize(after))
+	}
+	return sb.String(), nil
+}
+
+// formatSize formats a file size in B, KB or MB
+func formatSize(size int64) string {
+	switch {
+	case size < 1000:
+		return fmt.Sprintf("%d B", size)
+	case size < 1000*1000:
+		return fmt.Sprintf("%.1f KB", float64(size)/1000)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1000*1000))
+	}
+}
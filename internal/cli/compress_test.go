@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCompress(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "project", "old.jsonl")
+	recent := filepath.Join(dir, "project", "recent.jsonl")
+	writeTestSession(t, old, searchContent)
+	writeTestSession(t, recent, searchContent)
+	modTime := time.Now().AddDate(0, 0, -40)
+	if err := os.Chtimes(old, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := RunCompress(context.Background(), Config{InputPath: dir, OlderThan: 30, Format: compressFormatZstd})
+	if err != nil {
+		t.Fatalf("RunCompress failed: %v", err)
+	}
+	if !strings.Contains(output, "old.jsonl.zst") || !strings.Contains(output, "Compressed 1 sessions older than 30 days") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+	if _, err := os.Stat(old + ".zst"); err != nil {
+		t.Errorf("Expected the old session to be compressed: %v", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("Expected the recent session to be left alone: %v", err)
+	}
+
+	// The compressed session is still found and parsed
+	stats, err := RunStats(context.Background(), Config{InputPath: dir}, time.UTC)
+	if err != nil || !strings.Contains(stats, "Sessions:   2") {
+		t.Errorf("Expected both sessions in the stats, got %v:\n%s", err, stats)
+	}
+
+	output, err = RunCompress(context.Background(), Config{InputPath: dir, OlderThan: 30})
+	if err != nil || !strings.Contains(output, "No uncompressed sessions older than 30 days") {
+		t.Errorf("Expected nothing left to compress, got %v:\n%s", err, output)
+	}
+
+	// A session whose compressed log exists is skipped, keeping both
+	writeTestSession(t, old, searchContent)
+	if err := os.Chtimes(old, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	output, err = RunCompress(context.Background(), Config{InputPath: dir, OlderThan: 30, Format: compressFormatZstd})
+	if err != nil || !strings.Contains(output, "Skipped "+old) {
+		t.Errorf("Expected the session to be skipped, got %v:\n%s", err, output)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Errorf("Expected the skipped session to be kept: %v", err)
+	}
+}
+
+func TestParseArgsCompress(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "compress", "logs"})
+	if err != nil || !config.Compress || config.OlderThan != defaultCompressAge {
+		t.Errorf("Expected compress with the default age, got %+v, %v", config, err)
+	}
+	config, err = ParseArgs([]string{"cclog", "compress", "--older-than", "7", "--format", "zstd", "logs"})
+	if err != nil || config.OlderThan != 7 || config.Format != compressFormatZstd {
+		t.Errorf("Expected zstd after 7 days, got %+v, %v", config, err)
+	}
+	for _, args := range [][]string{
+		{"cclog", "compress", "--older-than", "0", "logs"},
+		{"cclog", "compress", "--format", "xz", "logs"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
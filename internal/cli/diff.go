@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/annenpolka/cclog/internal/diff"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// RunDiff returns a unified diff of the markdown of the input session and config.DiffPath.
+// Timestamps are left out, since two attempts at a task never happen at the same time.
+func RunDiff(config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor) (string, error) {
+	formatOptions.OmitTimestamps = true
+
+	var sides [2][]diff.Block
+	for i, path := range []string{config.InputPath, config.DiffPath} {
+		log, err := parser.ParseJSONLFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse file: %w", err)
+		}
+		selected := selectMessages(log, config, tools)
+		if redactor != nil {
+			redactor.Log(selected)
+		}
+		messages, blocks := formatter.FormatMessagesToMarkdown(selected, formatOptions)
+		for j, block := range blocks {
+			sides[i] = append(sides[i], diff.Block{Key: messages[j].UUID, Text: block})
+		}
+	}
+
+	output := diff.Unified(config.InputPath, config.DiffPath, sides[0], sides[1], diffContext)
+	if config.OutputPath != "" {
+		if err := writeOutputFile(config.OutputPath, output); err != nil {
+			return "", err
+		}
+	}
+
+	// The report goes to stderr so it never ends up in a diff printed to stdout
+	if redactor != nil {
+		fmt.Fprintln(os.Stderr, redactor.Report())
+	}
+	return output, nil
+}
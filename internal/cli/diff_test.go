@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.jsonl")
+	second := filepath.Join(dir, "second.jsonl")
+	writeTestSession(t, first, `{"type":"user","message":{"role":"user","content":"Fix the build"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Done."},"uuid":"a1","timestamp":"2025-07-06T05:01:30.618Z"}
+`)
+	writeTestSession(t, second, `{"type":"user","message":{"role":"user","content":"Fix the build"},"uuid":"x1","timestamp":"2025-07-08T09:00:00.000Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Let me run the tests first."},"uuid":"x2","timestamp":"2025-07-08T09:00:01.000Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Done."},"uuid":"x3","timestamp":"2025-07-08T09:00:02.000Z"}
+`)
+
+	config, err := ParseArgs([]string{"cclog", "diff", first, second})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	for _, want := range []string{"--- " + first + "\n+++ " + second + "\n", "+Let me run the tests first.", " Done."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "**Time:**") || strings.Contains(got, "-Done.") {
+		t.Errorf("Expected timestamps left out and the common reply aligned, got:\n%s", got)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "diff", first}); err == nil {
+		t.Error("Expected error for a single session")
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// defaultDigestSince is the period the digest command reports on by default
+const defaultDigestSince = "7d"
+
+// digestTitleWidth is the number of characters of the session titles shown in the digest
+const digestTitleWidth = 80
+
+// digestIdleGap is the longest pause between two messages counted as time spent on a session
+const digestIdleGap = 30 * time.Minute
+
+// digestSession is a session in the digest, counting only its messages in the reported period
+type digestSession struct {
+	title   string
+	project string
+	start   time.Time
+	active  time.Duration
+	tokens  int
+}
+
+// RunDigest writes a markdown report of the sessions with messages since config.Since, grouped by the
+// day they started and by project, with their titles, the time spent on them and their tokens: a summary
+// of what was worked on with Claude, e.g. for a standup.
+func RunDigest(ctx context.Context, config Config, loc *time.Location) (string, error) {
+	if loc == nil {
+		loc = formatter.GetSystemTimezone()
+	}
+	since, err := parseSince(config.Since, time.Now(), loc)
+	if err != nil {
+		return "", err
+	}
+	paths, _, err := export.FindSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+
+	var sessions []digestSession
+	for _, path := range paths {
+		log, err := parser.ParseJSONLFileContext(ctx, path)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			continue // Unparsable sessions are skipped, as in stats
+		}
+		if !types.MatchProject(config.Project, types.ProjectName(log)) {
+			continue
+		}
+		if session, ok := summarizeDigestSession(log, since); ok {
+			sessions = append(sessions, session)
+		}
+	}
+
+	digest := formatDigest(sessions, since, loc)
+	if config.OutputPath != "" {
+		if err := writeOutputFile(config.OutputPath, digest); err != nil {
+			return "", err
+		}
+	}
+	return digest, nil
+}
+
+// summarizeDigestSession summarizes the messages of a session sent at or after since.
+// It returns false if there are none.
+func summarizeDigestSession(log *types.ConversationLog, since time.Time) (digestSession, bool) {
+	var recent []types.Message
+	var times []time.Time
+	for _, msg := range log.Messages {
+		if !msg.Timestamp.IsZero() && !msg.Timestamp.Before(since) {
+			recent = append(recent, msg)
+			times = append(times, msg.Timestamp)
+		}
+	}
+	if len(recent) == 0 {
+		return digestSession{}, false
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	session := digestSession{
+		title:   types.TruncateTitle(types.ExtractTitle(filter.Current().FilterConversationLog(log, true)), digestTitleWidth),
+		project: types.ProjectName(log),
+		start:   times[0],
+		tokens:  types.SumUsage(recent).Total(),
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap <= digestIdleGap {
+			session.active += gap
+		}
+	}
+	return session, true
+}
+
+// formatDigest renders the digest: a summary line, then a section per day, oldest first, with a
+// subsection per project listing its sessions in the order they started
+func formatDigest(sessions []digestSession, since time.Time, loc *time.Location) string {
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].start.Before(sessions[j].start) })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Digest since %s\n\n", since.In(loc).Format("2006-01-02 15:04"))
+	if len(sessions) == 0 {
+		sb.WriteString("No sessions.\n")
+		return sb.String()
+	}
+
+	projects := make(map[string]bool)
+	var active time.Duration
+	tokens := 0
+	for _, session := range sessions {
+		projects[session.project] = true
+		active += session.active
+		tokens += session.tokens
+	}
+	fmt.Fprintf(&sb, "%d sessions in %d projects, %s, %s tokens\n", len(sessions), len(projects), formatActive(active), formatTokenCount(tokens))
+
+	var day string
+	var dayProjects []string
+	byProject := make(map[string][]digestSession)
+	flush := func() {
+		for _, project := range dayProjects {
+			name := project
+			if name == "" {
+				name = "(no project)"
+			}
+			fmt.Fprintf(&sb, "\n### %s\n\n", name)
+			for _, session := range byProject[project] {
+				fmt.Fprintf(&sb, "- %s %s (%s, %s tokens)\n", session.start.In(loc).Format("15:04"),
+					session.title, formatActive(session.active), formatTokenCount(session.tokens))
+			}
+		}
+		dayProjects = nil
+		byProject = make(map[string][]digestSession)
+	}
+	for _, session := range sessions {
+		start := session.start.In(loc)
+		if d := start.Format("2006-01-02"); d != day {
+			flush()
+			day = d
+			fmt.Fprintf(&sb, "\n## %s (%s)\n", day, start.Format("Monday"))
+		}
+		if _, ok := byProject[session.project]; !ok {
+			dayProjects = append(dayProjects, session.project)
+		}
+		byProject[session.project] = append(byProject[session.project], session)
+	}
+	flush()
+	return sb.String()
+}
+
+// parseSince returns the start of the period given by --since: a number of days or weeks before now
+// (e.g. 7d or 2w), a duration (e.g. 12h) or a date (YYYY-MM-DD, from its midnight in loc). Empty means
+// defaultDigestSince.
+func parseSince(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		value = defaultDigestSince
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return date, nil
+	}
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); err == nil && strings.HasSuffix(value, suffix) && n > 0 {
+			return now.AddDate(0, 0, -n*days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("since flag requires a period such as 7d, 2w or 12h, or a date (YYYY-MM-DD): %s", value)
+}
+
+// formatActive formats the time spent on sessions, e.g. "1h 05m" or "12m"
+func formatActive(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// formatTokenCount abbreviates a number of tokens, e.g. "950", "12.3k" or "1.2M"
+func formatTokenCount(tokens int) string {
+	switch {
+	case tokens < 1000:
+		return strconv.Itoa(tokens)
+	case tokens < 1000*1000:
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(tokens)/(1000*1000))
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// digestSessionContent returns a session in project cwd whose messages start at start, 10 minutes apart,
+// with an assistant answer of 1500 tokens
+func digestSessionContent(cwd, prompt, start string) string {
+	t, _ := time.Parse(time.RFC3339, start)
+	at := func(minutes int) string { return t.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339) }
+	return `{"type":"user","cwd":"` + cwd + `","message":{"role":"user","content":"` + prompt + `"},"uuid":"u1","timestamp":"` + at(0) + `"}
+{"type":"assistant","cwd":"` + cwd + `","message":{"id":"m1","role":"assistant","content":"Done.","usage":{"input_tokens":1000,"output_tokens":500}},"uuid":"a1","timestamp":"` + at(10) + `"}
+{"type":"user","cwd":"` + cwd + `","message":{"role":"user","content":"Thanks"},"uuid":"u2","timestamp":"` + at(20) + `"}
+`
+}
+
+func TestRunDigest(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "alpha", "one.jsonl"), digestSessionContent("/src/alpha", "Fix the login bug", "2025-07-02T09:00:00Z"))
+	writeTestSession(t, filepath.Join(input, "alpha", "two.jsonl"), digestSessionContent("/src/alpha", "Add a logout button", "2025-07-03T14:30:00Z"))
+	writeTestSession(t, filepath.Join(input, "beta", "one.jsonl"), digestSessionContent("/src/beta", "Write the release notes", "2025-07-02T08:15:00Z"))
+	writeTestSession(t, filepath.Join(input, "beta", "old.jsonl"), digestSessionContent("/src/beta", "Too old", "2025-06-20T08:15:00Z"))
+	writeTestSession(t, filepath.Join(input, "broken.jsonl"), "not json\n")
+
+	result, err := RunDigest(context.Background(), Config{InputPath: input, Since: "2025-07-01"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	want := `# Digest since 2025-07-01 00:00
+
+3 sessions in 2 projects, 1h 00m, 4.5k tokens
+
+## 2025-07-02 (Wednesday)
+
+### beta
+
+- 08:15 Write the release notes (20m, 1.5k tokens)
+
+### alpha
+
+- 09:00 Fix the login bug (20m, 1.5k tokens)
+
+## 2025-07-03 (Thursday)
+
+### alpha
+
+- 14:30 Add a logout button (20m, 1.5k tokens)
+`
+	if result != want {
+		t.Errorf("Unexpected digest:\n%s\nwant:\n%s", result, want)
+	}
+
+	result, err = RunDigest(context.Background(), Config{InputPath: input, Since: "2025-07-01", Project: "beta"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	if want := "1 sessions in 1 projects, 20m, 1.5k tokens\n"; !strings.Contains(result, want) {
+		t.Errorf("Expected digest of project beta to contain %q, got:\n%s", want, result)
+	}
+}
+
+func TestRunDigestNoSessions(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "alpha", "one.jsonl"), digestSessionContent("/src/alpha", "Fix the login bug", "2025-07-02T09:00:00Z"))
+
+	result, err := RunDigest(context.Background(), Config{InputPath: input, Since: "2025-08-01"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	if want := "# Digest since 2025-08-01 00:00\n\nNo sessions.\n"; result != want {
+		t.Errorf("RunDigest() = %q, want %q", result, want)
+	}
+}
+
+func TestSummarizeDigestSessionIdleGap(t *testing.T) {
+	input := t.TempDir()
+	path := filepath.Join(input, "one.jsonl")
+	// The last message comes after a break of an hour, which is not counted
+	writeTestSession(t, path, `{"type":"user","message":{"role":"user","content":"a"},"timestamp":"2025-07-02T09:00:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":"b"},"timestamp":"2025-07-02T09:05:00Z"}
+{"type":"user","message":{"role":"user","content":"c"},"timestamp":"2025-07-02T10:05:00Z"}
+`)
+	result, err := RunDigest(context.Background(), Config{InputPath: path, Since: "2025-07-01"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	if want := "- 09:00 a (5m, 0 tokens)\n"; !strings.Contains(result, want) {
+		t.Errorf("Expected digest to contain %q, got:\n%s", want, result)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)},
+		{"7d", time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)},
+		{"2w", time.Date(2025, 6, 26, 12, 0, 0, 0, time.UTC)},
+		{"12h", time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)},
+		{"2025-07-01", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now, time.UTC)
+		if err != nil {
+			t.Errorf("parseSince(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"week", "0d", "-3d", "-1h", "2025-13-01"} {
+		if _, err := parseSince(value, now, time.UTC); err == nil {
+			t.Errorf("parseSince(%q) should fail", value)
+		}
+	}
+}
+
+func TestFormatTokenCount(t *testing.T) {
+	tests := map[int]string{0: "0", 950: "950", 12345: "12.3k", 1234567: "1.2M"}
+	for tokens, want := range tests {
+		if got := formatTokenCount(tokens); got != want {
+			t.Errorf("formatTokenCount(%d) = %q, want %q", tokens, got, want)
+		}
+	}
+}
+
+func TestParseArgsDigest(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "digest", "--since", "2w", "logs"})
+	if err != nil || !config.Digest || config.Since != "2w" || config.InputPath != "logs" {
+		t.Errorf("Expected digest of the last 2 weeks of logs, got %+v, %v", config, err)
+	}
+	for _, args := range [][]string{
+		{"cclog", "digest", "--since", "yesterday", "logs"},
+		{"cclog", "digest", "--since"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/doctor"
+	"github.com/annenpolka/cclog/internal/pdf"
+	"github.com/annenpolka/cclog/internal/settings"
+	"github.com/annenpolka/cclog/pkg/filepicker"
+)
+
+// RunDoctor reports the version, the settings, the log directories, the editor, the clipboard,
+// the external programs and the terminal, as plain text for bug reports
+func RunDoctor() string {
+	report := doctor.Report{Header: strings.TrimSuffix(VersionText(), "\n")}
+
+	saved := checkSettings(&report)
+
+	report.Add("Log directories", doctor.LogDirectory("Claude projects", getDefaultTUIDirectory(), doctor.Problem))
+	for _, root := range saved.ExtraRoots {
+		report.Add("Log directories", doctor.LogDirectory("extra root", expandHome(root), doctor.Problem))
+	}
+	if dir, err := resolveTempDir(false); err != nil {
+		report.Add("Log directories", doctor.Check{Name: "temp files", Detail: err.Error(), Status: doctor.Problem})
+	} else if dir != "" {
+		report.Add("Log directories", doctor.Check{Name: "temp files", Detail: dir + " (the system temp directory is not writable)", Status: doctor.Warning})
+	} else {
+		report.Add("Log directories", doctor.Check{Name: "temp files", Detail: os.TempDir(), Status: doctor.OK})
+	}
+
+	report.Add("Opening sessions", checkEditor(saved)...)
+
+	if backends := filepicker.ClipboardBackends(); len(backends) > 0 {
+		report.Add("Clipboard", doctor.Check{Name: "backends", Detail: strings.Join(backends, ", ") + " (tried in this order)", Status: doctor.OK})
+	} else {
+		report.Add("Clipboard", doctor.Check{Name: "backends", Detail: "none available; the copy keys of the TUI will fail", Status: doctor.Warning})
+	}
+
+	report.Add("Programs", doctor.Program("claude", "resume", doctor.Warning))
+	if converter, err := pdf.FindConverter(); err != nil {
+		report.Add("Programs", doctor.Check{Name: "PDF converter", Detail: err.Error(), Status: doctor.Warning})
+	} else {
+		report.Add("Programs", doctor.Check{Name: "PDF converter", Detail: converter + " (--format pdf)", Status: doctor.OK})
+	}
+
+	report.Add("Terminal", doctor.Terminal()...)
+
+	out := report.String()
+	if problems := report.Problems(); problems > 0 {
+		return out + fmt.Sprintf("\n%d problem(s) found\n", problems)
+	}
+	return out + "\nNo problems found\n"
+}
+
+// checkSettings adds the settings file to the report and returns the settings in effect
+func checkSettings(report *doctor.Report) settings.Settings {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		report.Add("Settings", doctor.Check{Name: "config file", Detail: err.Error(), Status: doctor.Problem})
+		return settings.Settings{}
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		report.Add("Settings", doctor.Check{Name: "config file", Detail: path + " (not created; defaults in use)"})
+		return settings.Settings{}
+	}
+	saved, err := settings.Load(path)
+	if err != nil {
+		report.Add("Settings", doctor.Check{Name: "config file", Detail: err.Error(), Status: doctor.Problem})
+		return settings.Settings{}
+	}
+	report.Add("Settings", doctor.Check{Name: "config file", Detail: path, Status: doctor.OK})
+	return saved
+}
+
+// checkEditor reports the editor the TUI opens sessions with and the other settings of the open key
+func checkEditor(saved settings.Settings) []doctor.Check {
+	if err := filepicker.SetEditor(saved.Editor); err != nil {
+		return []doctor.Check{{Name: "editor", Detail: err.Error(), Status: doctor.Problem}}
+	}
+	var checks []doctor.Check
+	if args, source := filepicker.EditorCommand(); len(args) > 0 {
+		checks = append(checks, doctor.Check{Name: "editor", Detail: strings.Join(args, " ") + " (from " + source + ")", Status: doctor.OK})
+	} else {
+		checks = append(checks, doctor.Check{Name: "editor", Detail: "none found; set $EDITOR or the editor setting", Status: doctor.Problem})
+	}
+
+	openWith := saved.OpenWith
+	if openWith == "" {
+		openWith = filepicker.OpenWithEditor
+	}
+	if err := filepicker.SetOpenWith(saved.OpenWith); err != nil {
+		checks = append(checks, doctor.Check{Name: "open with", Detail: err.Error(), Status: doctor.Problem})
+	} else {
+		checks = append(checks, doctor.Check{Name: "open with", Detail: openWith})
+	}
+	if saved.ExportDir != "" {
+		checks = append(checks, doctor.Check{Name: "export dir", Detail: expandHome(saved.ExportDir)})
+	}
+	return checks
+}
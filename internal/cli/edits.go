@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/edits"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+)
+
+// RunEdits prints the change log of the files the assistant edited or wrote in the input session: per file,
+// when each change was made and its diff, with times in loc
+func RunEdits(ctx context.Context, config Config, loc *time.Location, redactor *redact.Redactor) (string, error) {
+	log, err := parser.ParseJSONLFileContext(ctx, config.InputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+	if redactor != nil {
+		redactor.Log(log)
+	}
+	output := edits.Markdown(edits.Extract(log), loc)
+	if output == "" {
+		return "", fmt.Errorf("no files were edited or written in %s", config.InputPath)
+	}
+	if redactor != nil {
+		fmt.Fprintln(os.Stderr, redactor.Report())
+	}
+	if config.OutputPath != "" {
+		if err := writeOutputFile(config.OutputPath, output); err != nil {
+			return "", err
+		}
+	}
+	return output, nil
+}
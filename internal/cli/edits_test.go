@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editsContent = `{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/src/app/config.go","old_string":"key := \"\"","new_string":"key := \"sk-ant-REDACTED\""}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:00Z"}
+`
+
+func TestRunEdits(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, editsContent)
+
+	result, err := RunCommand(Config{Edits: true, InputPath: input, Timezone: "UTC", Redact: true})
+	if err != nil {
+		t.Fatalf("edits failed: %v", err)
+	}
+	for _, want := range []string{"## `config.go` (1 edit, +1 -1)", "### 2025-07-06 05:01 Edit (+1 -1)", "+key := \"[REDACTED"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected %q in:\n%s", want, result)
+		}
+	}
+
+	// Without --timezone or a timezone setting, times are shown in local time
+	result, err = RunEdits(context.Background(), Config{InputPath: input}, nil, nil)
+	if err != nil || !strings.Contains(result, "## `config.go` (1 edit, +1 -1)") {
+		t.Errorf("Expected the edits in local time, got %v:\n%s", err, result)
+	}
+	if _, err := RunCommand(Config{Edits: true, InputPath: input}); err != nil {
+		t.Errorf("Expected edits without a timezone to succeed, got %v", err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	writeTestSession(t, empty, searchContent)
+	if _, err := RunCommand(Config{Edits: true, InputPath: empty}); err == nil {
+		t.Error("Expected an error for a session without edits")
+	}
+}
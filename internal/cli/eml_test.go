@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertEML(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"one", "two"} {
+		writeTestSession(t, filepath.Join(dir, name+".jsonl"), `{"type":"user","sessionId":"`+name+`","message":{"role":"user","content":"Prompt `+name+`"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","sessionId":"`+name+`","message":{"role":"assistant","content":"Done."},"uuid":"a1","timestamp":"2025-07-06T05:02:00Z"}
+`)
+	}
+
+	output := filepath.Join(t.TempDir(), "one.eml")
+	if _, err := RunCommand(Config{InputPath: filepath.Join(dir, "one.jsonl"), OutputPath: output, Format: "eml"}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected the message: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Not a valid message: %v", err)
+	}
+	if got := msg.Header.Get("Subject"); got != "Prompt one" {
+		t.Errorf("Subject = %q", got)
+	}
+
+	mbox, err := RunCommand(Config{InputPath: dir, IsDirectory: true, Format: "eml"})
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	if n := strings.Count("\n"+mbox, "\nFrom claude-code@cclog.invalid "); n != 2 {
+		t.Errorf("Expected an mbox of 2 messages, got %d:\n%s", n, mbox)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+// RunExport exports every session under the input path to markdown files in the output directory
+func RunExport(ctx context.Context, config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor, postHook *hook.Hook) (string, error) {
+	return runExport(ctx, config, formatOptions, tools, redactor, postHook, false)
+}
+
+// RunSync mirrors the sessions under the input directory to markdown files in the output directory: like
+// RunExport, it converts the new and changed sessions, and it also deletes the files of removed sessions
+func RunSync(ctx context.Context, config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor, postHook *hook.Hook) (string, error) {
+	// The manifest of a directory would read as every session removed from a single file input
+	if info, err := os.Stat(config.InputPath); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("sync requires a directory of sessions: %s", config.InputPath)
+	}
+	return runExport(ctx, config, formatOptions, tools, redactor, postHook, true)
+}
+
+// runExport runs an export, pruning the files of removed sessions for sync
+func runExport(ctx context.Context, config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor, postHook *hook.Hook, prune bool) (string, error) {
+	name, done := "export", "Exported"
+	if prune {
+		name, done = "sync", "Synced"
+	}
+	result, err := export.Run(ctx, export.Options{
+		InputPath:       config.InputPath,
+		OutputDir:       config.OutputPath,
+		EnableFiltering: !config.IncludeAll,
+		Format:          formatOptions,
+		Force:           config.Force,
+		Tools:           tools,
+		Roles:           config.Roles,
+		ExtractImages:   config.ExtractImages,
+		Redactor:        redactor,
+		SummaryBlock:    config.SummaryBlock,
+		Hook:            postHook,
+		Prune:           prune,
+	})
+	if err != nil && ctx.Err() != nil && result != nil {
+		return "", fmt.Errorf("%s interrupted (%s): %w", name, result.Summary(), err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	// Hook failures are reported like failed sessions, although their files were written
+	if len(result.Failed) > 0 || len(result.HookFailed) > 0 {
+		var failures []string
+		for _, failure := range slices.Concat(result.Failed, result.HookFailed) {
+			failures = append(failures, failure.Error())
+		}
+		return "", fmt.Errorf("%s finished with failures (%s):\n  %s", name, result.Summary(), strings.Join(failures, "\n  "))
+	}
+
+	summary := fmt.Sprintf("%s to %s: %s\n", done, config.OutputPath, result.Summary())
+	if redactor != nil {
+		summary += redactor.Report() + "\n"
+	}
+	return summary, nil
+}
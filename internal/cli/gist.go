@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/annenpolka/cclog/internal/gist"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// newGistClient returns the client export gist creates gists with; tests replace it to use a local server
+var newGistClient = func(token string) gist.Client {
+	return gist.Client{Token: token}
+}
+
+// createGist uploads the markdown of the input session as a gist described by its title and returns the URL
+func createGist(ctx context.Context, config Config, title, markdown string) (string, error) {
+	token := os.Getenv(gist.TokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("export gist needs a GitHub token with the gist scope in %s", gist.TokenEnv)
+	}
+	name := types.SlugifyTitle(title, splitSlugMaxRunes) + ".md"
+	url, err := newGistClient(token).Create(ctx, title, name, markdown, config.Public)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gist: %w", err)
+	}
+	return url, nil
+}
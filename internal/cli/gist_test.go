@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/gist"
+)
+
+func TestExportGist(t *testing.T) {
+	var request struct {
+		Description string                       `json:"description"`
+		Public      bool                         `json:"public"`
+		Files       map[string]map[string]string `json:"files"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url":"https://gist.github.com/test/abc123"}`)
+	}))
+	defer server.Close()
+	original := newGistClient
+	t.Cleanup(func() { newGistClient = original })
+	newGistClient = func(token string) gist.Client { return gist.Client{Token: token, URL: server.URL} }
+
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, `{"type":"user","message":{"role":"user","content":"Fix the parser"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Done."},"uuid":"a1","timestamp":"2025-07-06T05:02:00Z"}
+`)
+	config, err := ParseArgs([]string{"cclog", "export", "gist", input})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if !config.Gist || config.Export || config.InputPath != input {
+		t.Fatalf("Expected export gist of %s, got %+v", input, config)
+	}
+
+	t.Setenv(gist.TokenEnv, "")
+	if _, err := RunCommand(config); err == nil || !strings.Contains(err.Error(), gist.TokenEnv) {
+		t.Errorf("Expected an error naming %s, got %v", gist.TokenEnv, err)
+	}
+
+	t.Setenv(gist.TokenEnv, "ghp_test")
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("export gist failed: %v", err)
+	}
+	if result != "https://gist.github.com/test/abc123\n" {
+		t.Errorf("Expected the gist URL, got %q", result)
+	}
+	content := request.Files["fix-the-parser.md"]["content"]
+	if request.Description != "Fix the parser" || request.Public || !strings.Contains(content, "Done.") {
+		t.Errorf("Unexpected gist request: %+v", request)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", "export", "gist", input, "-o", "out"},
+		{"cclog", "export", "gist", "-d", "logs"},
+		{"cclog", "export", "logs", "-o", "out", "--public"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+	// A directory named gist after other arguments is still exported
+	if config, err := ParseArgs([]string{"cclog", "export", "-o", "out", "gist"}); err != nil || config.Gist || config.InputPath != "gist" {
+		t.Errorf("Expected an export of the gist directory, got %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/gitlog"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// commitSlack is how long after the last message of a session its commits are still looked for, e.g.
+// for a commit made by hand after reviewing the changes
+const commitSlack = 15 * time.Minute
+
+// gitMarkers returns a marker for each commit of the repository at the working directory of log made
+// between its first message and commitSlack after its last, with times in loc and subjects redacted if
+// redactor is set. Git failures, such as a working directory that is not a repository, are reported as
+// warnings and yield no markers, so the conversion goes on.
+func gitMarkers(ctx context.Context, log *types.ConversationLog, loc *time.Location, redactor *redact.Redactor) []formatter.Marker {
+	var cwd string
+	var first, last time.Time
+	for _, msg := range log.Messages {
+		if cwd == "" {
+			cwd = msg.CWD
+		}
+		if msg.Timestamp.IsZero() {
+			continue
+		}
+		if first.IsZero() || msg.Timestamp.Before(first) {
+			first = msg.Timestamp
+		}
+		if msg.Timestamp.After(last) {
+			last = msg.Timestamp
+		}
+	}
+	if cwd == "" || first.IsZero() {
+		fmt.Fprintln(os.Stderr, "Warning: --git-annotate needs the working directory and timestamps of the session")
+		return nil
+	}
+
+	commits, err := gitlog.Log(ctx, cwd, first, last.Add(commitSlack))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	markers := make([]formatter.Marker, len(commits))
+	for i, commit := range commits {
+		subject := commit.Subject
+		if redactor != nil {
+			subject = redactor.Text(subject)
+		}
+		markers[i] = formatter.Marker{
+			Time: commit.Time,
+			Text: fmt.Sprintf("Commit `%s` created around here (%s): %s", commit.ShortHash, commit.Time.In(loc).Format("15:04:05"), subject),
+		}
+	}
+	return markers
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertGitAnnotate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	date := "2025-07-06T05:01:30Z"
+	for _, args := range [][]string{{"init", "-q"}, {"commit", "-q", "--allow-empty", "-m", "Fix the parser"}} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	cwd := strings.ReplaceAll(repo, `\`, `\\`)
+	writeTestSession(t, input, `{"type":"user","cwd":"`+cwd+`","message":{"role":"user","content":"Fix the parser"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","cwd":"`+cwd+`","message":{"role":"assistant","content":"Committed the fix."},"uuid":"a1","timestamp":"2025-07-06T05:02:00Z"}
+`)
+
+	result, err := RunCommand(Config{InputPath: input, GitAnnotate: true, Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	marker := "> [!NOTE]\n> Commit `"
+	at := strings.Index(result, marker)
+	if at < 0 || !strings.Contains(result[at:], "` created around here (05:01:30): Fix the parser\n") {
+		t.Fatalf("Expected a commit marker in:\n%s", result)
+	}
+	if !(strings.Index(result, "Fix the parser\n\n") < at && at < strings.Index(result, "Committed the fix.")) {
+		t.Errorf("Expected the marker between the prompt and the answer:\n%s", result)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "-d", "logs", "--git-annotate"}); err == nil {
+		t.Error("Expected an error for --git-annotate with -d")
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+// RunGraph renders the message tree of the input conversations in the configured graph format.
+// Messages are not filtered, since removing them would break the tree.
+func RunGraph(ctx context.Context, config Config) (string, error) {
+	var graph string
+	var err error
+
+	if config.IsDirectory {
+		logs, parseErr := parser.ParseJSONLDirectoryContext(ctx, config.InputPath, config.Jobs)
+		if parseErr != nil {
+			return "", fmt.Errorf("failed to parse directory: %w", parseErr)
+		}
+		graph, err = formatter.FormatMultipleConversationsGraph(logs, config.Format)
+	} else {
+		log, parseErr := parser.ParseJSONLFileContext(ctx, config.InputPath)
+		if parseErr != nil {
+			return "", fmt.Errorf("failed to parse file: %w", parseErr)
+		}
+		graph, err = formatter.FormatConversationGraph(log, config.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if config.OutputPath != "" {
+		if err := writeOutputFile(config.OutputPath, graph); err != nil {
+			return "", err
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/settings"
+)
+
+func TestConvertRunsHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook command is written for sh")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	input := filepath.Join(dir, "session.jsonl")
+	writeTestSession(t, input, `{"type":"user","sessionId":"s1","cwd":"/work/cclog","message":{"role":"user","content":"Fix the parser"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","sessionId":"s1","cwd":"/work/cclog","message":{"role":"assistant","content":"Done."},"uuid":"a1","timestamp":"2025-07-06T05:02:00Z"}
+`)
+	output := filepath.Join(dir, "out", "session.md")
+	record := filepath.Join(dir, "hook.txt")
+
+	command := `echo "$CCLOG_EVENT|$CCLOG_OUTPUT|$CCLOG_SESSION_ID|$CCLOG_PROJECT|$CCLOG_TITLE" > "` + record + `"`
+	if _, err := RunCommand(Config{InputPath: input, OutputPath: output, Hook: command}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("Hook did not run: %v", err)
+	}
+	if want := "written|" + output + "|s1|cclog|Fix the parser\n"; string(got) != want {
+		t.Errorf("Hook saw %q, want %q", got, want)
+	}
+
+	_, err = RunCommand(Config{InputPath: input, OutputPath: output, Hook: "exit 2"})
+	if err == nil || !strings.Contains(err.Error(), "wrote "+output+", but hook for "+output+" failed") {
+		t.Errorf("Expected the hook failure to be reported, got %v", err)
+	}
+}
+
+func TestResolveHook(t *testing.T) {
+	saved := settings.Settings{PostExportHook: "sync-wiki", HookTimeout: 5}
+	if h := resolveHook(Config{}, saved); h == nil || h.Command != "sync-wiki" || h.Timeout != 5*time.Second {
+		t.Errorf("Expected the setting's hook, got %+v", h)
+	}
+	if h := resolveHook(Config{Hook: "upload"}, saved); h == nil || h.Command != "upload" {
+		t.Errorf("Expected the flag to override the setting, got %+v", h)
+	}
+	if h := resolveHook(Config{NoHook: true}, saved); h != nil {
+		t.Errorf("Expected --no-hook to disable the setting's hook, got %+v", h)
+	}
+	if h := resolveHook(Config{}, settings.Settings{}); h != nil {
+		t.Errorf("Expected no hook without flag or setting, got %+v", h)
+	}
+}
+
+func TestParseArgsHook(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "export", "logs", "-o", "out", "--hook", "rsync -a out host:"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Hook != "rsync -a out host:" {
+		t.Errorf("Hook = %q", config.Hook)
+	}
+	for _, args := range [][]string{
+		{"cclog", "session.jsonl", "--hook", "true"},
+		{"cclog", "session.jsonl", "-o", "out.md", "--hook", "true", "--no-hook"},
+		{"cclog", "session.jsonl", "-o", "out.md", "--hook"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
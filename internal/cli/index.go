@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/index"
+	"github.com/annenpolka/cclog/pkg/cclog"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Actions of the index command
+const (
+	indexActionBuild  = "build"
+	indexActionUpdate = "update"
+)
+
+// indexPath locates the session index; tests point it to a temporary file
+var indexPath = index.DefaultPath
+
+// RunIndex builds or updates the session index with the sessions under the input path. Build indexes
+// every session again; update only the new and changed ones and those indexed with other filter rules.
+func RunIndex(ctx context.Context, config Config) (string, error) {
+	path, err := indexPath()
+	if err != nil {
+		return "", err
+	}
+	ix, err := index.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer ix.Close()
+
+	if config.IndexAction == indexActionBuild {
+		if err := ix.Clear(ctx, config.InputPath); err != nil {
+			return "", fmt.Errorf("failed to clear index %s: %w", path, err)
+		}
+	}
+	result, err := ix.Sync(ctx, config.InputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to update index %s: %w", path, err)
+	}
+	return fmt.Sprintf("Indexed %s in %s: %s\n", config.InputPath, path, result), nil
+}
+
+// openSessionIndex opens the session index if one was built, or returns nil. An unreadable index is
+// ignored, since sessions can always be read from the logs.
+func openSessionIndex() *index.Index {
+	path, err := indexPath()
+	if err != nil {
+		return nil
+	}
+	ix, err := index.OpenExisting(path)
+	if err != nil {
+		return nil
+	}
+	return ix
+}
+
+// indexedSessions updates the index with the sessions under root and returns them like
+// cclog.ListSessions, newest first and without the unparsable ones
+func indexedSessions(ctx context.Context, ix *index.Index, root string) ([]cclog.Session, error) {
+	if _, err := ix.Sync(ctx, root); err != nil {
+		return nil, err
+	}
+	indexed, err := ix.Sessions(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []cclog.Session
+	for _, s := range indexed {
+		if s.ParseError != "" {
+			continue
+		}
+		title := s.Title
+		if title == "" {
+			title = types.ExtractTitle(nil) // What ListSessions shows for sessions without messages left after filtering
+		}
+		sessions = append(sessions, cclog.Session{
+			Path:      s.Path,
+			SessionID: s.SessionID,
+			Title:     title,
+			Project:   s.Project,
+			Messages:  s.Messages,
+			ModTime:   s.ModTime,
+		})
+	}
+	return sessions, nil
+}
+
+// searchableInIndex reports whether the index holds the messages a search selects: those kept by
+// the filter rules, without options changing the selection
+func searchableInIndex(config Config) bool {
+	return !config.IncludeAll && len(config.DisableFilters) == 0 && len(config.ExcludePatterns) == 0 &&
+		len(config.Tools) == 0 && len(config.ExcludeTools) == 0 && len(config.Roles) == 0
+}
+
+// searchIndex writes the search results of RunSearch from the index, after updating it with the
+// sessions under the input path. It returns the number of matches and of sessions with matches.
+func searchIndex(ctx context.Context, ix *index.Index, config Config, loc *time.Location, sb *strings.Builder) (int, int, error) {
+	if _, err := ix.Sync(ctx, config.InputPath); err != nil {
+		return 0, 0, err
+	}
+	sessions, err := ix.Sessions(ctx, config.InputPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	byPath := make(map[string]index.Session, len(sessions))
+	for _, s := range sessions {
+		byPath[s.Path] = s
+	}
+	found, err := ix.Search(ctx, config.InputPath, config.Query)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Paths are shown relative to the input directory, or to the directory of an input file, as in RunSearch
+	root := config.InputPath
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	query := strings.ToLower(config.Query)
+	matches, matchedSessions := 0, 0
+	for start := 0; start < len(found); {
+		path := found[start].Path
+		end := start
+		for end < len(found) && found[end].Path == path {
+			end++
+		}
+		session := byPath[path]
+		if types.MatchProject(config.Project, session.Project) {
+			var lines []string
+			for _, match := range found[start:end] {
+				lines = append(lines, fmt.Sprintf("  %s  %s: %s",
+					match.Timestamp.In(loc).Format("2006-01-02 15:04"), match.Role, matchingLine(match.Text, query)))
+			}
+			name := path
+			if rel, err := filepath.Rel(root, path); err == nil {
+				name = rel
+			}
+			sb.WriteString(fmt.Sprintf("%s: %s\n%s\n\n", name, session.Title, strings.Join(lines, "\n")))
+			matches += len(lines)
+			matchedSessions++
+		}
+		start = end
+	}
+	return matches, matchedSessions, nil
+}
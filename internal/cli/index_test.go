@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/index"
+)
+
+// useTestIndex points the session index to a temporary file for the test
+func useTestIndex(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "index.db")
+	indexPath = func() (string, error) { return path, nil }
+	t.Cleanup(func() { indexPath = index.DefaultPath })
+	return path
+}
+
+func TestRunIndex(t *testing.T) {
+	ctx := context.Background()
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), searchContent)
+	writeTestSession(t, filepath.Join(input, "other", "two.jsonl"), strings.ReplaceAll(searchContent, "flaky", "slow"))
+	useTestIndex(t)
+
+	// Without an index, list and search read the logs
+	wantList, err := RunList(ctx, Config{InputPath: input}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSearch, err := RunSearch(ctx, Config{InputPath: input, Query: "flaky"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := RunIndex(ctx, Config{InputPath: input, IndexAction: indexActionBuild})
+	if err != nil || !strings.Contains(output, "2 added, 0 updated, 0 removed, 0 unchanged") {
+		t.Fatalf("Unexpected build output %q, %v", output, err)
+	}
+	output, err = RunIndex(ctx, Config{InputPath: input, IndexAction: indexActionUpdate})
+	if err != nil || !strings.Contains(output, "2 unchanged") {
+		t.Fatalf("Unexpected update output %q, %v", output, err)
+	}
+
+	// With the index, they print the same
+	if got, err := RunList(ctx, Config{InputPath: input}, time.UTC); err != nil || got != wantList {
+		t.Errorf("List from the index differs:\n%s\nwant:\n%s", got, wantList)
+	}
+	if got, err := RunSearch(ctx, Config{InputPath: input, Query: "flaky"}, time.UTC); err != nil || got != wantSearch {
+		t.Errorf("Search from the index differs:\n%s\nwant:\n%s", got, wantSearch)
+	}
+
+	// Sessions removed since the index was built are not listed
+	if err := os.Remove(filepath.Join(input, "other", "two.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := RunList(ctx, Config{InputPath: input}, time.UTC); strings.Contains(got, "two.jsonl") {
+		t.Errorf("Expected the removed session to be dropped from the index:\n%s", got)
+	}
+}
+
+func TestParseArgsIndex(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "index", "update", "logs"})
+	if err != nil || !config.Index || config.IndexAction != indexActionUpdate || config.InputPath != "logs" {
+		t.Errorf("Expected index update of logs, got %+v, %v", config, err)
+	}
+	for _, args := range [][]string{{"cclog", "index"}, {"cclog", "index", "rebuild"}} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
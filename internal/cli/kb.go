@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/kb"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+// RunKB exports every session under the input path into a knowledge base in the output directory
+// and regenerates its chronological, project and tag indexes
+func RunKB(ctx context.Context, config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor, postHook *hook.Hook) (string, error) {
+	result, err := kb.Build(ctx, kb.Options{
+		InputPath:       config.InputPath,
+		OutputDir:       config.OutputPath,
+		EnableFiltering: !config.IncludeAll,
+		Format:          formatOptions,
+		Force:           config.Force,
+		Tools:           tools,
+		Roles:           config.Roles,
+		ExtractImages:   config.ExtractImages,
+		Redactor:        redactor,
+		SummaryBlock:    config.SummaryBlock,
+		Hook:            postHook,
+	})
+	if err != nil {
+		return "", fmt.Errorf("knowledge base build failed: %w", err)
+	}
+
+	// Hook failures are reported like failed sessions, although their files were written
+	if len(result.Export.Failed) > 0 || len(result.Export.HookFailed) > 0 {
+		var failures []string
+		for _, failure := range slices.Concat(result.Export.Failed, result.Export.HookFailed) {
+			failures = append(failures, failure.Error())
+		}
+		return "", fmt.Errorf("knowledge base built with failures (%s):\n  %s", result.Summary(), strings.Join(failures, "\n  "))
+	}
+
+	summary := fmt.Sprintf("Built knowledge base in %s: %s\n", config.OutputPath, result.Summary())
+	if redactor != nil {
+		summary += redactor.Report() + "\n"
+	}
+	return summary, nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// findLastSession returns the most recently modified session under root.
+// If project is not empty, only sessions whose project matches it, exactly or as a glob, are considered.
+func findLastSession(ctx context.Context, root, project string) (string, error) {
+	sessions, _, err := export.FindSessions(ctx, root)
+	if err != nil {
+		return "", err
+	}
+
+	modTimes := make(map[string]int64, len(sessions))
+	for _, session := range sessions {
+		if info, err := os.Stat(session); err == nil {
+			modTimes[session] = info.ModTime().UnixNano()
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return modTimes[sessions[i]] > modTimes[sessions[j]]
+	})
+
+	for _, session := range sessions {
+		if project == "" {
+			return session, nil
+		}
+		// Only parse sessions until the newest one of the project is found
+		log, err := parser.ParseJSONLFileContext(ctx, session)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err == nil && types.MatchProject(project, types.ProjectName(log)) {
+			return session, nil
+		}
+	}
+
+	if project != "" {
+		return "", fmt.Errorf("no session of project %q found in %s", project, root)
+	}
+	return "", fmt.Errorf("no session found in %s", root)
+}
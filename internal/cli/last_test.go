@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommandLast(t *testing.T) {
+	input := t.TempDir()
+	older := filepath.Join(input, "a", "older.jsonl")
+	newer := filepath.Join(input, "b", "newer.jsonl")
+	writeTestSession(t, older, `{"type":"user","cwd":"/src/cclog","message":{"role":"user","content":"older prompt"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+	writeTestSession(t, newer, `{"type":"user","cwd":"/src/other","message":{"role":"user","content":"newer prompt"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	config, err := ParseArgs([]string{"cclog", "last", input})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(result, "newer prompt") || strings.Contains(result, "older prompt") {
+		t.Errorf("Expected the newest session, got:\n%s", result)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "last", input, "--project", "cclog"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if !strings.Contains(result, "older prompt") {
+		t.Errorf("Expected the newest session of the project, got:\n%s", result)
+	}
+
+	config.Project = "missing"
+	if _, err := RunCommand(config); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected error for a project without sessions, got %v", err)
+	}
+}
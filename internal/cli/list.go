@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parallel"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/cclog"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Output formats and sort orders of the list command
+const (
+	listFormatTable = "table"
+	listFormatJSON  = "json"
+	listFormatTSV   = "tsv"
+
+	listSortDate     = "date"
+	listSortProject  = "project"
+	listSortTitle    = "title"
+	listSortMessages = "messages"
+)
+
+// listSortOrders lists the valid --sort values of the list command
+var listSortOrders = []string{listSortDate, listSortProject, listSortTitle, listSortMessages}
+
+// listColumn is a column of the table and TSV output of the list command
+type listColumn struct {
+	name   string // Name for --columns
+	header string // Heading in the table
+	// value returns the column of a session; tsv selects the exact values of the TSV output
+	// over the shorter ones of the table
+	value func(session cclog.Session, loc *time.Location, tsv bool) string
+}
+
+// listColumns lists the columns in their default order
+var listColumns = []listColumn{
+	{"date", "DATE", func(s cclog.Session, loc *time.Location, tsv bool) string {
+		if tsv {
+			return s.ModTime.In(loc).Format(time.RFC3339)
+		}
+		return s.ModTime.In(loc).Format("2006-01-02 15:04")
+	}},
+	{"project", "PROJECT", func(s cclog.Session, _ *time.Location, _ bool) string { return s.Project }},
+	{"title", "TITLE", func(s cclog.Session, _ *time.Location, tsv bool) string {
+		if tsv {
+			return s.Title
+		}
+		return truncateTitle(s.Title)
+	}},
+	{"messages", "MESSAGES", func(s cclog.Session, _ *time.Location, _ bool) string { return strconv.Itoa(s.Messages) }},
+	{"session", "SESSION", func(s cclog.Session, _ *time.Location, _ bool) string { return s.SessionID }},
+	{"path", "PATH", func(s cclog.Session, _ *time.Location, _ bool) string { return s.Path }},
+}
+
+// selectListColumns returns the columns named in names, or all columns for an empty list
+func selectListColumns(names []string) ([]listColumn, error) {
+	if len(names) == 0 {
+		return listColumns, nil
+	}
+	var columns []listColumn
+	for _, name := range names {
+		found := false
+		for _, column := range listColumns {
+			if column.name == strings.ToLower(name) {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var available []string
+			for _, column := range listColumns {
+				available = append(available, column.name)
+			}
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// tsvEscaper escapes the characters that would break a TSV field, so every session stays on one line
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// listTitleMaxRunes limits the width of the title column of the table
+const listTitleMaxRunes = 50
+
+// listSchemaVersion is the version of the JSON output of the list command. It is raised when a field is
+// renamed, removed or changes meaning; added fields keep it.
+const listSchemaVersion = 1
+
+// listOutput is the JSON output of the list command
+type listOutput struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Sessions      []listEntry `json:"sessions"`
+}
+
+// listEntry is a session in the JSON output of the list command
+type listEntry struct {
+	Date      string `json:"date"` // Last modification
+	Project   string `json:"project"`
+	Title     string `json:"title"`
+	Messages  int    `json:"messages"`
+	SessionID string `json:"sessionId"`
+	Path      string `json:"path"`
+	CWD       string `json:"cwd"`
+	Size      int64  `json:"size"` // Bytes, compressed for compressed logs
+	// StartTime and EndTime are the times of the first and last message, omitted without timestamps
+	StartTime string     `json:"startTime,omitempty"`
+	EndTime   string     `json:"endTime,omitempty"`
+	Counts    listCounts `json:"counts"`
+	Tokens    listTokens `json:"tokens"`
+	// Error is set when the log could not be read for the details above
+	Error string `json:"error,omitempty"`
+}
+
+// listCounts counts the messages of a session by role (see filter.MessageRole) and its tool calls
+type listCounts struct {
+	User      int `json:"user"`
+	Assistant int `json:"assistant"`
+	Tool      int `json:"tool"`
+	ToolCalls int `json:"toolCalls"`
+}
+
+// listTokens is the token usage the API reported for the answers of a session
+type listTokens struct {
+	Input         int `json:"input"`
+	Output        int `json:"output"`
+	CacheCreation int `json:"cacheCreation"`
+	CacheRead     int `json:"cacheRead"`
+	Total         int `json:"total"`
+}
+
+// RunList lists the sessions under the input path with their date (last modification), project, title,
+// message count, sessionId and path, like the TUI list. Sessions are sorted by config.Sort, newest first by default.
+// They are read from the session index, updated with the changed sessions first, if one was built.
+func RunList(ctx context.Context, config Config, loc *time.Location) (string, error) {
+	sessions, err := listSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+	if loc == nil {
+		loc = formatter.GetSystemTimezone()
+	}
+
+	var selected []cclog.Session
+	for _, session := range sessions {
+		if types.MatchProject(config.Project, session.Project) {
+			selected = append(selected, session)
+		}
+	}
+	sortSessions(selected, config.Sort)
+	if config.Limit > 0 && len(selected) > config.Limit {
+		selected = selected[:config.Limit]
+	}
+
+	if config.Format == listFormatJSON {
+		entries, err := parallel.MapContext(ctx, selected, 0, func(session cclog.Session) listEntry {
+			return newListEntry(ctx, session, loc)
+		})
+		if err != nil {
+			return "", err
+		}
+		data, err := json.MarshalIndent(listOutput{SchemaVersion: listSchemaVersion, Sessions: entries}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+
+	columns, err := selectListColumns(config.Columns)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if config.Format == listFormatTSV {
+		// With --print0, records end with NUL and fields are written as they are, except that tabs, which
+		// separate the fields, become spaces; paths round-trip exactly unless they contain a tab
+		terminator := "\n"
+		if config.Print0 {
+			terminator = "\x00"
+		}
+		for _, session := range selected {
+			fields := make([]string, len(columns))
+			for i, column := range columns {
+				fields[i] = column.value(session, loc, true)
+				if config.Print0 {
+					fields[i] = strings.ReplaceAll(fields[i], "\t", " ")
+				} else {
+					fields[i] = tsvEscaper.Replace(fields[i])
+				}
+			}
+			sb.WriteString(strings.Join(fields, "\t") + terminator)
+		}
+		return sb.String(), nil
+	}
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, session := range selected {
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			fields[i] = column.value(session, loc, false)
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	w.Flush()
+	return sb.String(), nil
+}
+
+// newListEntry returns the JSON entry of session, with the details read from its log
+func newListEntry(ctx context.Context, session cclog.Session, loc *time.Location) listEntry {
+	entry := listEntry{
+		Date:      session.ModTime.In(loc).Format(time.RFC3339),
+		Project:   session.Project,
+		Title:     session.Title,
+		Messages:  session.Messages,
+		SessionID: session.SessionID,
+		Path:      session.Path,
+	}
+	if info, err := os.Stat(session.Path); err == nil {
+		entry.Size = info.Size()
+	}
+	log, err := parser.ParseJSONLFileContext(ctx, session.Path)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	var first, last time.Time
+	for _, msg := range log.Messages {
+		if entry.CWD == "" {
+			entry.CWD = msg.CWD
+		}
+		switch filter.MessageRole(msg) {
+		case filter.RoleUser:
+			entry.Counts.User++
+		case filter.RoleAssistant:
+			entry.Counts.Assistant++
+		case filter.RoleTool:
+			entry.Counts.Tool++
+		}
+		entry.Counts.ToolCalls += len(toolUseNames(msg.Message))
+		if msg.Timestamp.IsZero() {
+			continue
+		}
+		if first.IsZero() || msg.Timestamp.Before(first) {
+			first = msg.Timestamp
+		}
+		if msg.Timestamp.After(last) {
+			last = msg.Timestamp
+		}
+	}
+	if !first.IsZero() {
+		entry.StartTime = first.In(loc).Format(time.RFC3339)
+		entry.EndTime = last.In(loc).Format(time.RFC3339)
+	}
+	usage := types.SumUsage(log.Messages)
+	entry.Tokens = listTokens{
+		Input:         usage.InputTokens,
+		Output:        usage.OutputTokens,
+		CacheCreation: usage.CacheCreationInputTokens,
+		CacheRead:     usage.CacheReadInputTokens,
+		Total:         usage.Total(),
+	}
+	return entry
+}
+
+// listSessions returns the sessions under root from the session index if there is one, or from the logs
+func listSessions(ctx context.Context, root string) ([]cclog.Session, error) {
+	if ix := openSessionIndex(); ix != nil {
+		defer ix.Close()
+		sessions, err := indexedSessions(ctx, ix, root)
+		if err == nil || ctx.Err() != nil {
+			return sessions, err
+		}
+		// A broken index falls back to reading the logs
+	}
+	return cclog.ListSessionsContext(ctx, root)
+}
+
+// truncateTitle shortens a title to listTitleMaxRunes, marking truncation with an ellipsis
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= listTitleMaxRunes {
+		return title
+	}
+	return string(runes[:listTitleMaxRunes-3]) + "..."
+}
+
+// sortSessions sorts sessions, which ListSessions returns newest first, by order.
+// Ties keep the newest session first.
+func sortSessions(sessions []cclog.Session, order string) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		switch order {
+		case listSortProject:
+			return a.Project < b.Project
+		case listSortTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case listSortMessages:
+			return a.Messages > b.Messages
+		default:
+			return a.ModTime.After(b.ModTime)
+		}
+	})
+}
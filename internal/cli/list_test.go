@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunList(t *testing.T) {
+	root := t.TempDir()
+	older := filepath.Join(root, "-src-app", "older-id.jsonl")
+	newer := filepath.Join(root, "-src-tool", "newer-id.jsonl")
+	writeTestSession(t, older, `{"type":"user","cwd":"/src/app","message":{"role":"user","content":"Fix the build"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","cwd":"/src/app","message":{"id":"m1","role":"assistant","content":"Done.","usage":{"input_tokens":100,"output_tokens":20}},"uuid":"a1","timestamp":"2025-07-06T05:01:30.618Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":"Thanks"},"uuid":"u2","timestamp":"2025-07-06T05:01:31.618Z"}
+`)
+	writeTestSession(t, newer, `{"type":"user","cwd":"/src/tool","sessionId":"newer-id","message":{"role":"user","content":"Add a flag"},"uuid":"u1","timestamp":"2025-07-07T05:01:29.618Z"}
+`)
+	past := time.Date(2025, 7, 6, 5, 2, 0, 0, time.UTC)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	config, err := ParseArgs([]string{"cclog", "list", root, "--timezone", "UTC"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(result), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "DATE") {
+		t.Fatalf("Expected a header and 2 sessions, got:\n%s", result)
+	}
+	if !strings.Contains(lines[1], "tool") || !strings.Contains(lines[2], "2025-07-06 05:02  app") || !strings.Contains(lines[2], "Fix the build") {
+		t.Errorf("Expected the newest session first, got:\n%s", result)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "list", root, "--format", "json", "--sort", "messages", "--limit", "1", "--timezone", "UTC"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	var output listOutput
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		t.Fatalf("Expected JSON output: %v\n%s", err, result)
+	}
+	entries := output.Sessions
+	if output.SchemaVersion != listSchemaVersion || len(entries) != 1 || entries[0].SessionID != "older-id" || entries[0].Messages != 3 || entries[0].Path != older {
+		t.Fatalf("Expected the session with the most messages, got %+v", output)
+	}
+	entry := entries[0]
+	if entry.CWD != "/src/app" || entry.StartTime != "2025-07-06T05:01:29Z" || entry.EndTime != "2025-07-06T05:01:31Z" || entry.Size == 0 {
+		t.Errorf("Unexpected session details %+v", entry)
+	}
+	if entry.Counts != (listCounts{User: 2, Assistant: 1}) || entry.Tokens != (listTokens{Input: 100, Output: 20, Total: 120}) {
+		t.Errorf("Unexpected counts %+v and tokens %+v", entry.Counts, entry.Tokens)
+	}
+	// The field names are part of the schema
+	for _, field := range []string{`"schemaVersion": 1`, `"sessionId"`, `"startTime"`, `"toolCalls"`, `"cacheRead"`} {
+		if !strings.Contains(result, field) {
+			t.Errorf("Expected %s in:\n%s", field, result)
+		}
+	}
+
+	config, err = ParseArgs([]string{"cclog", "list", root, "--format", "tsv", "--columns", "session,path", "--sort", "project"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if want := "older-id\t" + older + "\nnewer-id\t" + newer + "\n"; result != want {
+		t.Errorf("Expected TSV rows without a header, got %q", result)
+	}
+
+	config.Print0 = true
+	config.Columns = []string{"path"}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if want := older + "\x00" + newer + "\x00"; result != want {
+		t.Errorf("Expected NUL-terminated paths, got %q", result)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", "list", "--sort", "size"},
+		{"cclog", "list", "--columns", "size"},
+		{"cclog", "list", "--print0"},
+		{"cclog", "list", "--format", "json", "--columns", "path"},
+		{"cclog", "list", "--format", "csv"},
+		{"cclog", "list", "--limit", "0"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args[1:])
+		}
+	}
+}
+
+func TestTSVEscaper(t *testing.T) {
+	if got := tsvEscaper.Replace("a\tb\nc\\d"); got != `a\tb\nc\\d` {
+		t.Errorf("Unexpected escaping %q", got)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cli
+
+import "path/filepath"
+
+// claudeProjectsDirs lists where Claude Code keeps its projects directory, in order of preference:
+// ~/.claude/projects, then ~/.config/claude/projects
+func claudeProjectsDirs(home string) []string {
+	return []string{
+		filepath.Join(home, ".claude", "projects"),
+		filepath.Join(home, ".config", "claude", "projects"),
+	}
+}
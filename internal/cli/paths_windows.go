@@ -0,0 +1,18 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// claudeProjectsDirs lists where Claude Code keeps its projects directory, in order of preference:
+// %USERPROFILE%\.claude\projects, then %APPDATA%\claude\projects
+func claudeProjectsDirs(home string) []string {
+	dirs := []string{filepath.Join(home, ".claude", "projects")}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		dirs = append(dirs, filepath.Join(appData, "claude", "projects"))
+	}
+	return dirs
+}
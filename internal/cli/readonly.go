@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/settings"
+)
+
+// isWritableDir reports whether a file can be created in dir
+func isWritableDir(dir string) bool {
+	probe, err := os.CreateTemp(dir, ".cclog-probe-*")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return true
+}
+
+// logDirectory returns the directory holding the logs at inputPath
+func logDirectory(inputPath string) string {
+	if info, err := os.Stat(inputPath); err == nil && !info.IsDir() {
+		return filepath.Dir(inputPath)
+	}
+	return inputPath
+}
+
+// isReadOnly reports whether the logs at inputPath must be treated as read-only,
+// either because --read-only was given or because their directory cannot be written
+func isReadOnly(config Config) bool {
+	return config.ReadOnly || !isWritableDir(logDirectory(config.InputPath))
+}
+
+// resolveTempDir returns the directory for temporary files.
+// The system temp directory ("") is used unless the logs are read-only or it cannot be written,
+// in which case temporary files go to the cclog state directory.
+func resolveTempDir(readOnly bool) (string, error) {
+	if !readOnly && isWritableDir(os.TempDir()) {
+		return "", nil
+	}
+
+	dir, err := settings.TempDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return dir, nil
+}
+
+// checkOutputOutsideLogs returns an error if outputPath lies inside the read-only log directory
+func checkOutputOutsideLogs(inputPath, outputPath string) error {
+	logDir, err := filepath.Abs(logDirectory(inputPath))
+	if err != nil {
+		return nil
+	}
+	output, err := filepath.Abs(outputPath)
+	if err != nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(logDir, output)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return fmt.Errorf("output %s is inside the read-only log directory %s; choose an output path elsewhere", outputPath, logDir)
+}
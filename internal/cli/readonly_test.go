@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckOutputOutsideLogs(t *testing.T) {
+	logs := t.TempDir()
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{name: "inside log directory", output: filepath.Join(logs, "out.md"), wantErr: true},
+		{name: "nested inside log directory", output: filepath.Join(logs, "a", "b"), wantErr: true},
+		{name: "sibling with common prefix", output: logs + "-export", wantErr: false},
+		{name: "elsewhere", output: filepath.Join(t.TempDir(), "out.md"), wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOutputOutsideLogs(logs, tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkOutputOutsideLogs(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveTempDir(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	dir, err := resolveTempDir(false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dir != "" {
+		t.Errorf("Writable system temp directory should be used, got %q", dir)
+	}
+
+	dir, err = resolveTempDir(true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := filepath.Join(configDir, "cclog", "tmp"); dir != want {
+		t.Errorf("Read-only mode should use %q, got %q", want, dir)
+	}
+	if !isWritableDir(dir) {
+		t.Errorf("State temp directory %q should be created and writable", dir)
+	}
+}
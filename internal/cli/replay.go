@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/replay"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runReplayProgram is a variable that can be replaced in tests to avoid starting a terminal program
+var runReplayProgram = func(model replay.Model) error {
+	_, err := tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
+
+// RunReplay plays the session of the input file back in the terminal, one message at a time
+func RunReplay(config Config, formatOptions formatter.FormatOptions, tools filter.ToolFilter, redactor *redact.Redactor) (string, error) {
+	log, err := parser.ParseJSONLFile(config.InputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+	selected := selectMessages(log, config, tools)
+	if redactor != nil {
+		redactor.Log(selected)
+	}
+
+	messages, blocks := formatter.FormatMessagesToMarkdown(selected, formatOptions)
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("no messages to replay in %s", config.InputPath)
+	}
+
+	model := replay.NewModel(replay.NewFrames(messages, blocks), config.Speed)
+	model.SetPlain(config.NoColor || os.Getenv("NO_COLOR") != "")
+	if err := runReplayProgram(model); err != nil {
+		return "", fmt.Errorf("replay error: %w", err)
+	}
+	return "", nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/replay"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRunReplay(t *testing.T) {
+	session := filepath.Join(t.TempDir(), "abc-123.jsonl")
+	writeTestSession(t, session, searchContent)
+
+	var got replay.Model
+	original := runReplayProgram
+	runReplayProgram = func(model replay.Model) error {
+		got = model
+		return nil
+	}
+	defer func() { runReplayProgram = original }()
+
+	config, err := ParseArgs([]string{"cclog", "replay", session, "--speed", "4", "--no-color"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := RunCommand(config); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if got.Speed() != 4 {
+		t.Errorf("Expected speed 4, got %g", got.Speed())
+	}
+
+	// Show every message and check the plain view
+	for i := 0; i < 2; i++ {
+		updated, _ := got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+		got = updated.(replay.Model)
+	}
+	view := got.View()
+	for _, want := range []string{"Why is the build flaky?", "The FLAKY test depends on time.", "2/2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the replay to contain %q, got:\n%s", want, view)
+		}
+	}
+}
+
+func TestParseArgsReplay(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "replay", "abc-123"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.Replay || config.SessionID != "abc-123" || config.InputPath != "" {
+		t.Errorf("Expected a session ID argument to be looked up, got %+v", config)
+	}
+
+	for _, speed := range []string{"0", "fast", "1000"} {
+		if _, err := ParseArgs([]string{"cclog", "replay", "abc-123", "--speed", speed}); err == nil {
+			t.Errorf("Expected error for --speed %s", speed)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/annenpolka/cclog/internal/shellquote"
+	"github.com/annenpolka/cclog/pkg/filepicker"
+)
+
+// execCommand is a variable that can be replaced in tests to mock os/exec.Command
+var execCommand = exec.Command
+
+// RunResume resumes the session of the input file with claude in the session's working directory,
+// like the resume key of the TUI
+func RunResume(config Config) (string, error) {
+	name, args, dir, err := filepicker.ResumeCommand(config.InputPath, config.Dangerous)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the session to resume: %w", err)
+	}
+
+	cmd := execCommand(name, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// claude shares the terminal, so Ctrl-C interrupts cclog too, which must outlive it instead of
+	// orphaning it. The interrupts are caught rather than ignored, as ignored signals stay ignored in claude.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to execute command '%s %v' in dir '%s': %w", name, args, dir, err)
+	}
+	return "", nil
+}
+
+// RunResumeCommandLine returns the shell command that resumes the session of the input file from its
+// working directory, e.g. "cd /src/app && claude -r <sessionId>" (a PowerShell command on Windows),
+// for scripts and shell aliases
+func RunResumeCommandLine(config Config) (string, error) {
+	name, args, dir, err := filepicker.ResumeCommand(config.InputPath, config.Dangerous)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the session to resume: %w", err)
+	}
+
+	return shellquote.ChangeDirAndRun(dir, append([]string{name}, args...)) + "\n", nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunResume(t *testing.T) {
+	cwd := t.TempDir()
+	session := filepath.Join(t.TempDir(), "abc-123.jsonl")
+	writeTestSession(t, session, `{"type":"user","cwd":"`+cwd+`","message":{"role":"user","content":"hi"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+
+	var got []string
+	original := execCommand
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		got = append([]string{name}, args...)
+		return exec.Command("true")
+	}
+	defer func() { execCommand = original }()
+
+	if _, err := RunResume(Config{InputPath: session, Dangerous: true}); err != nil {
+		t.Fatalf("RunResume failed: %v", err)
+	}
+	if want := "claude -r abc-123 --dangerously-skip-permissions"; strings.Join(got, " ") != want {
+		t.Errorf("Expected %q, got %q", want, strings.Join(got, " "))
+	}
+
+	if _, err := RunResume(Config{InputPath: filepath.Join(cwd, "notes.txt")}); err == nil {
+		t.Error("Expected error for a file that is not a session")
+	}
+}
+
+func TestRunResumeSurvivesInterrupt(t *testing.T) {
+	cwd := t.TempDir()
+	session := filepath.Join(t.TempDir(), "abc-123.jsonl")
+	writeTestSession(t, session, `{"type":"user","cwd":"`+cwd+`","message":{"role":"user","content":"hi"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+
+	// Ctrl-C in claude also reaches cclog, which must wait for claude instead of exiting
+	original := execCommand
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command("sh", "-c", "kill -INT $PPID; kill -INT $PPID; sleep 0.1")
+	}
+	defer func() { execCommand = original }()
+
+	if _, err := RunResume(Config{InputPath: session}); err != nil {
+		t.Fatalf("RunResume failed: %v", err)
+	}
+}
+
+func TestRunResumeCommandLine(t *testing.T) {
+	cwd := filepath.Join(t.TempDir(), "my project")
+	session := filepath.Join(t.TempDir(), "abc-123.jsonl")
+	writeTestSession(t, session, `{"type":"user","cwd":"`+cwd+`","message":{"role":"user","content":"hi"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+
+	config, err := ParseArgs([]string{"cclog", "resume-cmd", session, "--dangerous"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	want := "cd '" + cwd + "' && claude -r abc-123 --dangerously-skip-permissions\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "resume-cmd", "abc-123"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.SessionID != "abc-123" || config.InputPath != "" {
+		t.Errorf("Expected a session ID argument to be looked up, got %+v", config)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// searchSnippetWidth is the number of characters of a matching line shown in search results
+const searchSnippetWidth = 100
+
+// RunSearch lists the messages containing the query, ignoring case, in the sessions under the input path.
+// Sessions are listed with their title, and each match with its time, role and matching line.
+// The session index is searched instead of the logs if one was built and the options allow it.
+func RunSearch(ctx context.Context, config Config, loc *time.Location) (string, error) {
+	if loc == nil {
+		loc = formatter.GetSystemTimezone()
+	}
+	if searchableInIndex(config) {
+		if ix := openSessionIndex(); ix != nil {
+			defer ix.Close()
+			var sb strings.Builder
+			matches, matchedSessions, err := searchIndex(ctx, ix, config, loc, &sb)
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			if err == nil {
+				return searchSummary(&sb, matches, matchedSessions, config.Query), nil
+			}
+			// A broken index falls back to reading the logs
+		}
+	}
+
+	sessions, root, err := export.FindSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+
+	query := strings.ToLower(config.Query)
+	toolFilter := filter.ToolFilter{Include: config.Tools, Exclude: config.ExcludeTools}
+	var sb strings.Builder
+	matches, matchedSessions := 0, 0
+	for _, session := range sessions {
+		log, err := parser.ParseJSONLFileContext(ctx, session)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			continue // Unparsable sessions are skipped, as in the TUI
+		}
+		if !types.MatchProject(config.Project, types.ProjectName(log)) {
+			continue
+		}
+		selected := selectMessages(log, config, toolFilter)
+
+		var lines []string
+		for _, msg := range selected.Messages {
+			text := types.ExtractTextContent(msg.Message)
+			if !strings.Contains(strings.ToLower(text), query) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s  %s: %s",
+				msg.Timestamp.In(loc).Format("2006-01-02 15:04"), filter.MessageRole(msg), matchingLine(text, query)))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		name := session
+		if rel, err := filepath.Rel(root, session); err == nil {
+			name = rel
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n%s\n\n", name, types.ExtractTitle(selected), strings.Join(lines, "\n")))
+		matches += len(lines)
+		matchedSessions++
+	}
+
+	return searchSummary(&sb, matches, matchedSessions, config.Query), nil
+}
+
+// searchSummary returns the search results in sb followed by their count
+func searchSummary(sb *strings.Builder, matches, matchedSessions int, query string) string {
+	if matches == 0 {
+		return fmt.Sprintf("No messages contain %q\n", query)
+	}
+	sb.WriteString(fmt.Sprintf("%d messages in %d sessions contain %q\n", matches, matchedSessions, query))
+	return sb.String()
+}
+
+// matchingLine returns the first line of text containing query (in lowercase), shortened for display
+func matchingLine(text, query string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(strings.ToLower(line), query) {
+			return types.TruncateTitle(line, searchSnippetWidth)
+		}
+	}
+	// The query spans several lines
+	return types.TruncateTitle(strings.Join(strings.Fields(text), " "), searchSnippetWidth)
+}
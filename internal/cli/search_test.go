@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const searchContent = `{"type":"user","message":{"role":"user","content":"Why is the build flaky?"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Let me look.\nThe FLAKY test depends on time."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:30.618Z"}
+`
+
+func writeTestSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), searchContent)
+	writeTestSession(t, filepath.Join(input, "project", "two.jsonl"), `{"type":"user","message":{"role":"user","content":"unrelated"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}`)
+
+	result, err := RunSearch(context.Background(), Config{InputPath: input, Query: "flaky"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunSearch failed: %v", err)
+	}
+	for _, want := range []string{
+		filepath.Join("project", "one.jsonl") + ": Why is the build flaky?",
+		"2025-07-06 05:01  user: Why is the build flaky?",
+		"assistant: The FLAKY test depends on time.",
+		"2 messages in 1 sessions",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "two.jsonl") {
+		t.Errorf("Sessions without matches should not be listed:\n%s", result)
+	}
+
+	result, err = RunSearch(context.Background(), Config{InputPath: input, Query: "flaky", Roles: []string{"user"}}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunSearch failed: %v", err)
+	}
+	if strings.Contains(result, "assistant:") {
+		t.Errorf("Expected only user messages, got:\n%s", result)
+	}
+
+	result, err = RunSearch(context.Background(), Config{InputPath: input, Query: "nowhere"}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunSearch failed: %v", err)
+	}
+	if result != "No messages contain \"nowhere\"\n" {
+		t.Errorf("Unexpected result %q", result)
+	}
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/annenpolka/cclog/internal/selfupdate"
+)
+
+// RunSelfUpdate replaces the running binary with the latest GitHub release if it is newer, or with force
+// even if it is not, e.g. to replace a development build
+func RunSelfUpdate(force bool) (string, error) {
+	updater, err := selfupdate.NewUpdater(Version)
+	if err != nil {
+		return "", err
+	}
+	updater.Force = force
+
+	result, err := updater.Update()
+	if err != nil {
+		return "", fmt.Errorf("self-update failed: %w", err)
+	}
+
+	if !result.Updated {
+		return fmt.Sprintf("cclog is already up to date (%s)\n", result.LatestVersion), nil
+	}
+	return fmt.Sprintf("Updated cclog from %s to %s\n", result.PreviousVersion, result.LatestVersion), nil
+}
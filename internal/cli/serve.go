@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/metrics"
+)
+
+// defaultServeAddr is the address serve listens on without --addr; only local clients can connect
+const defaultServeAddr = "127.0.0.1:9467"
+
+// serveShutdownTimeout bounds how long serve waits for scrapes in progress when it is stopped
+const serveShutdownTimeout = 5 * time.Second
+
+// RunServe serves the Prometheus metrics of the sessions under the input path at /metrics until ctx is
+// done, e.g. by Ctrl-C, with the days of the token metrics in loc
+func RunServe(ctx context.Context, config Config, loc *time.Location) (string, error) {
+	addr := config.Addr
+	if addr == "" {
+		addr = defaultServeAddr
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", &metrics.Collector{Root: config.InputPath, Project: config.Project, Location: loc})
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(os.Stderr, "Serving metrics of %s at http://%s/metrics (Ctrl-C to stop)\n", config.InputPath, listener.Addr())
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	select {
+	case err := <-served:
+		return "", fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return "", err
+	}
+	return "", nil
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunServe(t *testing.T) {
+	root := t.TempDir()
+	writeTestSession(t, filepath.Join(root, "-src-app", "session.jsonl"), searchContent)
+
+	// Find a free port for the server
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := RunServe(ctx, Config{InputPath: root, Addr: addr}, time.UTC)
+		done <- err
+	}()
+
+	var body string
+	for attempt := 0; attempt < 50; attempt++ {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err == nil {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			body = string(data)
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !strings.Contains(body, "cclog_sessions{project=") {
+		t.Errorf("Expected metrics, got:\n%s", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected serve to stop cleanly, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop")
+	}
+}
+
+func TestParseArgsServe(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "serve", "--addr", ":9100"})
+	if err != nil || !config.Serve || config.Addr != ":9100" || config.InputPath == "" {
+		t.Errorf("Expected serve on :9100 of the default directory, got %+v, %v", config, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "serve", "--addr", "9100"}); err == nil {
+		t.Error("Expected an error for an address without a port separator")
+	}
+}
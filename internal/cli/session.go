@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/settings"
+)
+
+// sessionRoots returns the directories searched for a session ID: the Claude projects directory
+// and the extra roots of the settings
+func sessionRoots(saved settings.Settings) []string {
+	roots := []string{getDefaultTUIDirectory()}
+	for _, root := range saved.ExtraRoots {
+		roots = append(roots, expandHome(root))
+	}
+	return roots
+}
+
+// findSessionByID returns the session file with the given sessionId under roots.
+// Session files are named after their sessionId, so file names are checked first, and a unique
+// prefix of the ID is enough. Files whose messages carry the sessionId, e.g. renamed ones, are found as a fallback.
+func findSessionByID(ctx context.Context, roots []string, id string) (string, error) {
+	var sessions []string
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil {
+			continue // Extra roots may be unmounted
+		}
+		found, _, err := export.FindSessions(ctx, root)
+		if err != nil {
+			return "", err
+		}
+		for _, session := range found {
+			if !seen[session] {
+				seen[session] = true
+				sessions = append(sessions, session)
+			}
+		}
+	}
+
+	var prefixed []string
+	for _, session := range sessions {
+		name := parser.TrimLogExt(filepath.Base(session))
+		if name == id {
+			return session, nil
+		}
+		if strings.HasPrefix(name, id) {
+			prefixed = append(prefixed, session)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0], nil
+	}
+	if len(prefixed) > 1 {
+		return "", fmt.Errorf("session ID %q is ambiguous, it matches:\n  %s", id, strings.Join(prefixed, "\n  "))
+	}
+
+	for _, session := range sessions {
+		log, err := parser.ParseJSONLFileContext(ctx, session)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		for _, msg := range log.Messages {
+			if msg.SessionID == id {
+				return session, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no session with ID %q found in %s", id, strings.Join(roots, ", "))
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindSessionByID(t *testing.T) {
+	root := t.TempDir()
+	extra := t.TempDir()
+	named := filepath.Join(root, "project", "41eb70c6-2cac-4420-834b-ceaea98a7494.jsonl")
+	renamed := filepath.Join(extra, "renamed.jsonl")
+	writeTestSession(t, named, `{"type":"user","message":{"role":"user","content":"hi"},"uuid":"u1"}`)
+	writeTestSession(t, filepath.Join(root, "project", "41eb0000-0000-0000-0000-000000000000.jsonl"), `{"type":"user","message":{"role":"user","content":"hi"},"uuid":"u1"}`)
+	writeTestSession(t, renamed, `{"type":"user","sessionId":"9f3c2a10-aaaa-bbbb-cccc-dddddddddddd","message":{"role":"user","content":"hi"},"uuid":"u1"}`)
+	roots := []string{root, extra, filepath.Join(root, "unmounted")}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"41eb70c6-2cac-4420-834b-ceaea98a7494", named},
+		{"41eb70", named},
+		{"9f3c2a10-aaaa-bbbb-cccc-dddddddddddd", renamed},
+	}
+	for _, tt := range tests {
+		got, err := findSessionByID(context.Background(), roots, tt.id)
+		if err != nil {
+			t.Errorf("findSessionByID(context.Background(), %q) failed: %v", tt.id, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("findSessionByID(context.Background(), %q) = %s, want %s", tt.id, got, tt.want)
+		}
+	}
+
+	if _, err := findSessionByID(context.Background(), roots, "41eb"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguous ID error, got %v", err)
+	}
+	if _, err := findSessionByID(context.Background(), roots, "missing"); err == nil {
+		t.Error("Expected error for an unknown session ID")
+	}
+}
+
+func TestParseArgsSession(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "show", "41eb70c6", "--only", "user"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.SessionID != "41eb70c6" || config.InputPath != "" || config.TUIMode {
+		t.Errorf("Unexpected show config %+v", config)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "--session", "41eb70c6", "-o", "out.md"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.SessionID != "41eb70c6" || config.OutputPath != "out.md" {
+		t.Errorf("Unexpected --session config %+v", config)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "show"}); err == nil {
+		t.Error("Expected error for show without a session ID")
+	}
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/assets"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/parallel"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// splitSlugMaxRunes caps the title part of split output file names
+	splitSlugMaxRunes = 50
+	// DefaultNameTemplate names split output files when no template is configured
+	DefaultNameTemplate = "{{.Date}}-{{.TitleSlug}}.md"
+)
+
+// splitNameData is the data available to file name templates
+type splitNameData struct {
+	Date      string // Date of the first message, e.g. 2025-07-06
+	Time      string // Time of the first message, e.g. 1504
+	Project   string // Name of the working directory
+	Title     string // Conversation title with characters unsafe in file names replaced
+	TitleSlug string // Lowercase hyphenated title
+	SessionID string
+}
+
+// writeSplitOutput writes each conversation to its own markdown file in dir, named by the file name template.
+// images holds the images extracted from each conversation, written next to its file.
+func writeSplitOutput(ctx context.Context, logs []*types.ConversationLog, images [][]assets.Image, dir string, config Config, formatOptions formatter.FormatOptions, postHook *hook.Hook) (string, error) {
+	nameTemplate, err := parseNameTemplate(resolveNameTemplate(config.NameTemplate))
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Names are chosen in order so that duplicates are numbered the same way whatever the number of jobs
+	used := make(map[string]bool)
+	paths := make([]string, len(logs))
+	for i, log := range logs {
+		name, err := splitFileName(nameTemplate, log, formatOptions.Location(), used)
+		if err != nil {
+			return "", err
+		}
+		paths[i] = filepath.Join(dir, name)
+	}
+
+	// Each file is written as it is formatted, so no more than one conversation per job is held as markdown
+	indexes := make([]int, len(logs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	errs, err := parallel.MapContext(ctx, indexes, config.Jobs, func(i int) error {
+		log := logs[i]
+		return writeOutputFileWith(paths[i], func(w io.Writer) error {
+			if config.ShowTitle {
+				if _, err := fmt.Fprintf(w, "# %s\n\n", types.ExtractTitle(log)); err != nil {
+					return err
+				}
+			}
+			if config.Outline {
+				_, err := io.WriteString(w, formatter.FormatConversationOutline(log))
+				return err
+			}
+			return formatter.WriteConversationMarkdown(w, log, formatOptions)
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return "", err
+		}
+		// Images are written one file at a time, since conversations may share them
+		if err := assets.Write(filepath.Dir(paths[i]), images[i]); err != nil {
+			return "", err
+		}
+	}
+
+	// Hooks run one at a time after every file is written, so a slow upload never holds back the others
+	if postHook != nil {
+		for i, log := range logs {
+			if err := postHook.Run(ctx, hook.SessionEvent(hook.EventWritten, paths[i], config.InputPath, log)); err != nil {
+				return "", fmt.Errorf("wrote %d files to %s, but %w", len(logs), dir, err)
+			}
+		}
+	}
+
+	return fmt.Sprintf("Wrote %d files to %s\n", len(logs), dir), nil
+}
+
+// resolveNameTemplate returns the template from the flag, the nameTemplate setting or the default, in that order
+func resolveNameTemplate(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if saved, _ := loadSettings(); saved.NameTemplate != "" {
+		return saved.NameTemplate
+	}
+	return DefaultNameTemplate
+}
+
+// parseNameTemplate parses a file name template and checks that it only uses known fields
+func parseNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, splitNameData{}); err != nil {
+		return nil, fmt.Errorf("invalid name template %q: %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// splitFileName names a conversation's file with the template, numbering names already in used.
+// Dates and times are taken in loc. Names may contain subdirectories but must stay inside the output directory.
+func splitFileName(tmpl *template.Template, log *types.ConversationLog, loc *time.Location, used map[string]bool) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, newSplitNameData(log, loc)); err != nil {
+		return "", fmt.Errorf("failed to name output file: %w", err)
+	}
+
+	name, err := safepath.Clean(sb.String())
+	if err != nil {
+		return "", fmt.Errorf("invalid name template output: %w", err)
+	}
+	if filepath.Ext(name) == "" {
+		name += ".md"
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+	used[name] = true
+	return name, nil
+}
+
+// newSplitNameData collects the template fields of a conversation, with its start time in loc
+func newSplitNameData(log *types.ConversationLog, loc *time.Location) splitNameData {
+	title := types.ExtractTitle(log)
+	data := splitNameData{
+		Date:      "undated",
+		Time:      "0000",
+		Title:     safepath.Name(title, "conversation"),
+		TitleSlug: types.SlugifyTitle(title, splitSlugMaxRunes),
+		SessionID: safepath.Name(parser.TrimLogExt(filepath.Base(log.FilePath)), "session"),
+		Project:   "unknown",
+	}
+
+	for _, msg := range log.Messages {
+		if !msg.Timestamp.IsZero() {
+			local := msg.Timestamp.In(loc)
+			data.Date = local.Format("2006-01-02")
+			data.Time = local.Format("1504")
+			break
+		}
+	}
+	for _, msg := range log.Messages {
+		if msg.CWD != "" && msg.CWD != "/" {
+			data.Project = safepath.Name(filepath.Base(filepath.Clean(msg.CWD)), "unknown")
+			break
+		}
+	}
+	return data
+}
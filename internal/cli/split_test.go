@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestSplitFileName(t *testing.T) {
+	log := &types.ConversationLog{
+		FilePath: "/logs/abc-123.jsonl",
+		Messages: []types.Message{{
+			Type:      "user",
+			CWD:       "/home/me/my app",
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Fix: parser/lexer bug")},
+			Timestamp: time.Date(2025, 7, 6, 12, 0, 0, 0, time.Local),
+		}},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{DefaultNameTemplate, "2025-07-06-fix-parser-lexer-bug.md"},
+		{"{{.Date}}-{{.Project}}-{{.TitleSlug}}.md", "2025-07-06-my app-fix-parser-lexer-bug.md"},
+		{"{{.Project}}/{{.Title}}", "my app/Fix- parser-lexer bug.md"},
+		{"{{.SessionID}}-{{.Time}}.markdown", "abc-123-1200.markdown"},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseNameTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("parseNameTemplate(%q) failed: %v", tt.template, err)
+		}
+		got, err := splitFileName(tmpl, log, time.Local, map[string]bool{})
+		if err != nil {
+			t.Fatalf("splitFileName(%q) failed: %v", tt.template, err)
+		}
+		if got != tt.want {
+			t.Errorf("Template %q named %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFileNameCollisions(t *testing.T) {
+	tmpl, err := parseNameTemplate("{{.Project}}.md")
+	if err != nil {
+		t.Fatalf("parseNameTemplate failed: %v", err)
+	}
+	log := &types.ConversationLog{Messages: []types.Message{{Type: "user", CWD: "/work/app"}}}
+	used := map[string]bool{}
+	for _, want := range []string{"app.md", "app-2.md", "app-3.md"} {
+		if got, _ := splitFileName(tmpl, log, time.Local, used); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestNameTemplateErrors(t *testing.T) {
+	for _, text := range []string{"{{.Unknown}}.md", "{{.Date"} {
+		if _, err := parseNameTemplate(text); err == nil {
+			t.Errorf("Expected error for template %q", text)
+		}
+	}
+
+	tmpl, err := parseNameTemplate("../{{.TitleSlug}}.md")
+	if err != nil {
+		t.Fatalf("parseNameTemplate failed: %v", err)
+	}
+	if _, err := splitFileName(tmpl, &types.ConversationLog{}, time.Local, map[string]bool{}); err == nil {
+		t.Error("Expected error for a name outside the output directory")
+	}
+}
+
+func TestSplitFileNameCraftedTitle(t *testing.T) {
+	log := &types.ConversationLog{
+		FilePath: "/logs/abc.jsonl",
+		Messages: []types.Message{{
+			Type:    "user",
+			CWD:     "/home/me/..",
+			Message: &types.MessageBody{Role: "user", Content: types.TextContent("../../.bashrc")},
+		}},
+	}
+
+	// Fields never contain path separators, so titles cannot leave the output directory
+	tests := map[string]string{
+		"{{.Title}}.md":                 "-..-.bashrc.md",
+		"{{.Project}}/{{.Title}}.md":    "home/-..-.bashrc.md",
+		"{{.SessionID}}/{{.TitleSlug}}": "abc/bashrc.md",
+	}
+	for text, want := range tests {
+		tmpl, err := parseNameTemplate(text)
+		if err != nil {
+			t.Fatalf("parseNameTemplate(%q) failed: %v", text, err)
+		}
+		if got, err := splitFileName(tmpl, log, time.Local, map[string]bool{}); err != nil || got != filepath.FromSlash(want) {
+			t.Errorf("Template %q named %q (%v), want %q", text, got, err, want)
+		}
+	}
+}
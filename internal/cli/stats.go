@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// RunStats counts the sessions under the input path, their messages by role, their tokens and their tool calls by tool.
+// Messages are counted after filtering, so the numbers match the converted markdown; tokens are those the API
+// reported for every answer, and tool calls all those of the tools that --tool and --exclude-tool keep.
+func RunStats(ctx context.Context, config Config, loc *time.Location) (string, error) {
+	sessions, _, err := export.FindSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+	if loc == nil {
+		loc = formatter.GetSystemTimezone()
+	}
+
+	toolFilter := filter.ToolFilter{Include: config.Tools, Exclude: config.ExcludeTools}
+	roles := make(map[string]int)
+	tools := make(map[string]int)
+	parsed, unparsable, messages, toolCalls := 0, 0, 0, 0
+	var first, last time.Time
+	var usage types.Usage
+	for _, session := range sessions {
+		log, err := parser.ParseJSONLFileContext(ctx, session)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			unparsable++
+			continue
+		}
+		if !types.MatchProject(config.Project, types.ProjectName(log)) {
+			continue
+		}
+		parsed++
+		usage = usage.Add(types.SumUsage(log.Messages))
+
+		// Tool calls are mostly in messages the default filter drops, so they are counted before it
+		for _, msg := range log.Messages {
+			for _, name := range toolUseNames(msg.Message) {
+				if toolFilter.Keeps(name) {
+					tools[name]++
+					toolCalls++
+				}
+			}
+		}
+
+		for _, msg := range selectMessages(log, config, toolFilter).Messages {
+			messages++
+			roles[filter.MessageRole(msg)]++
+			if msg.Timestamp.IsZero() {
+				continue
+			}
+			if first.IsZero() || msg.Timestamp.Before(first) {
+				first = msg.Timestamp
+			}
+			if msg.Timestamp.After(last) {
+				last = msg.Timestamp
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Sessions:   %d\n", parsed))
+	if unparsable > 0 {
+		sb.WriteString(fmt.Sprintf("Unparsable: %d\n", unparsable))
+	}
+	roleCounts := make([]string, 0, len(filter.Roles)+1)
+	other := messages
+	for _, role := range filter.Roles {
+		roleCounts = append(roleCounts, fmt.Sprintf("%s %d", role, roles[role]))
+		other -= roles[role]
+	}
+	if other > 0 {
+		roleCounts = append(roleCounts, fmt.Sprintf("other %d", other)) // e.g. summaries with --include-all
+	}
+	sb.WriteString(fmt.Sprintf("Messages:   %d (%s)\n", messages, strings.Join(roleCounts, ", ")))
+	if !first.IsZero() {
+		sb.WriteString(fmt.Sprintf("Period:     %s to %s\n", first.In(loc).Format("2006-01-02"), last.In(loc).Format("2006-01-02")))
+	}
+	if usage.Total() > 0 {
+		sb.WriteString(fmt.Sprintf("Tokens:     %d (input %d, output %d, cache write %d, cache read %d)\n", usage.Total(),
+			usage.InputTokens, usage.OutputTokens, usage.CacheCreationInputTokens, usage.CacheReadInputTokens))
+	}
+	sb.WriteString(fmt.Sprintf("Tool calls: %d\n", toolCalls))
+
+	names := make([]string, 0, len(tools))
+	width := 0
+	for name := range tools {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tools[names[i]] != tools[names[j]] {
+			return tools[names[i]] > tools[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("  %-*s  %d\n", width, name, tools[name]))
+	}
+	return sb.String(), nil
+}
+
+// toolUseNames returns the names of the tools called in a message
+func toolUseNames(message *types.MessageBody) []string {
+	var names []string
+	for _, block := range message.Blocks() {
+		if use, ok := block.(types.ToolUseBlock); ok {
+			names = append(names, use.Name)
+		}
+	}
+	return names
+}
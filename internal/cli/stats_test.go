@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStats(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), searchContent)
+	writeTestSession(t, filepath.Join(input, "project", "two.jsonl"), searchContent)
+	writeTestSession(t, filepath.Join(input, "broken.jsonl"), "not json\n")
+
+	result, err := RunStats(context.Background(), Config{InputPath: input}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunStats failed: %v", err)
+	}
+	for _, want := range []string{
+		"Sessions:   2\n",
+		"Unparsable: 1\n",
+		"Messages:   4 (user 2, assistant 2, tool 0)\n",
+		"Period:     2025-07-06 to 2025-07-06\n",
+		"Tool calls: 2\n  Bash  2\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected stats to contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestRunStatsTokens(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), digestSessionContent("/src/alpha", "Fix the login bug", "2025-07-02T09:00:00Z"))
+
+	result, err := RunStats(context.Background(), Config{InputPath: input}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunStats failed: %v", err)
+	}
+	if want := "Tokens:     1500 (input 1000, output 500, cache write 0, cache read 0)\n"; !strings.Contains(result, want) {
+		t.Errorf("Expected stats to contain %q, got:\n%s", want, result)
+	}
+}
+
+func TestRunStatsToolOnlyMessages(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), `{"type":"user","message":{"role":"user","content":"Run the tests"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:10Z"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},"uuid":"u2","timestamp":"2025-07-06T05:01:20Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"main.go"}}]},"uuid":"a2","timestamp":"2025-07-06T05:01:30Z"}
+{"type":"assistant","message":{"role":"assistant","content":"All tests pass."},"uuid":"a3","timestamp":"2025-07-06T05:01:40Z"}
+`)
+
+	result, err := RunStats(context.Background(), Config{InputPath: input}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunStats failed: %v", err)
+	}
+	if want := "Tool calls: 2\n  Bash  1\n  Read  1\n"; !strings.Contains(result, want) {
+		t.Errorf("Expected tool calls of filtered messages to be counted, got:\n%s", result)
+	}
+
+	result, err = RunStats(context.Background(), Config{InputPath: input, ExcludeTools: []string{"read"}}, time.UTC)
+	if err != nil {
+		t.Fatalf("RunStats failed: %v", err)
+	}
+	if want := "Tool calls: 1\n  Bash  1\n"; !strings.Contains(result, want) {
+		t.Errorf("Expected --exclude-tool to drop Read, got:\n%s", result)
+	}
+}
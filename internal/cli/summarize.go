@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/summary"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// summaryCachePath and newSummarizer locate the summary cache and choose the LLM; tests replace them
+var (
+	summaryCachePath = summary.DefaultCachePath
+	newSummarizer    = summary.NewSummarizer
+)
+
+// RunSummarize prints the summary of the input session: its first prompt, the files it touched most and
+// the start of its last answer, taken from the log without an LLM, or with config.LLM a paragraph
+// written by an LLM
+func RunSummarize(ctx context.Context, config Config, redactor *redact.Redactor) (string, error) {
+	log, err := parser.ParseJSONLFileContext(ctx, config.InputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file: %w", err)
+	}
+	var block string
+	if config.LLM {
+		block, err = llmSummaryBlock(ctx, config, log, redactor)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		block = summaryBlock(log, redactor)
+	}
+	if block == "" {
+		return "", fmt.Errorf("nothing to summarize in %s: no prompts, answers or file changes", config.InputPath)
+	}
+	if redactor != nil {
+		fmt.Fprintln(os.Stderr, redactor.Report())
+	}
+	if config.OutputPath != "" {
+		if err := writeOutputFile(config.OutputPath, block); err != nil {
+			return "", err
+		}
+	}
+	return block, nil
+}
+
+// summaryBlock returns the summary section of an unfiltered log, redacted if redactor is set, or ""
+// if there is nothing to summarize
+func summaryBlock(log *types.ConversationLog, redactor *redact.Redactor) string {
+	s := summary.Extract(log)
+	if redactor != nil {
+		s = s.Map(redactor.Text)
+	}
+	return s.Markdown()
+}
+
+// llmSummaryBlock returns the summary section with the LLM summary of log, from the summary cache if the
+// log is unchanged since it was summarized, unless config.Force. The transcript sent to the LLM and the
+// summary are redacted if redactor is set; the summary is redacted too because the cached one may have
+// been written from an unredacted transcript. It returns "" if no message is left to summarize.
+func llmSummaryBlock(ctx context.Context, config Config, log *types.ConversationLog, redactor *redact.Redactor) (string, error) {
+	info, err := os.Stat(config.InputPath)
+	if err != nil {
+		return "", err
+	}
+	cachePath, err := summaryCachePath()
+	if err != nil {
+		return "", err
+	}
+	cache, err := summary.LoadCache(cachePath)
+	if err != nil {
+		return "", err
+	}
+	if cached, ok := cache.Lookup(config.InputPath, info.Size(), info.ModTime()); ok && !config.Force {
+		return llmSummaryMarkdown(redactSummary(cached, redactor)), nil
+	}
+
+	if redactor != nil {
+		redactor.Log(log)
+	}
+	transcript := summary.Transcript(log)
+	if transcript == "" {
+		return "", nil
+	}
+	summarizer, err := newSummarizer()
+	if err != nil {
+		return "", err
+	}
+	text, err := summarizer.Summarize(ctx, transcript)
+	if err != nil {
+		return "", err
+	}
+	cache.Put(config.InputPath, info.Size(), info.ModTime(), summarizer.Name(), text)
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return llmSummaryMarkdown(redactSummary(text, redactor)), nil
+}
+
+// redactSummary returns text with its secrets replaced if redactor is set
+func redactSummary(text string, redactor *redact.Redactor) string {
+	if redactor == nil {
+		return text
+	}
+	return redactor.Text(text)
+}
+
+// llmSummaryMarkdown renders an LLM summary as a "Summary" section like summary.Summary.Markdown
+func llmSummaryMarkdown(text string) string {
+	return "## Summary\n\n" + text + "\n\n"
+}
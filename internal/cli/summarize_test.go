@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/summary"
+)
+
+const summarizeContent = `{"type":"user","cwd":"/src/app","message":{"role":"user","content":"Fix the flaky test"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/src/app/parser_test.go"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:10Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":"The test no longer depends on the clock."},"uuid":"a2","timestamp":"2025-07-06T05:02:00Z"}
+`
+
+const summarizeBlock = "## Summary\n\n- **Asked:** Fix the flaky test\n- **Files:** `parser_test.go`\n- **Outcome:** The test no longer depends on the clock.\n\n"
+
+func TestRunSummarize(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, summarizeContent)
+
+	result, err := RunCommand(Config{Summarize: true, InputPath: input})
+	if err != nil {
+		t.Fatalf("summarize failed: %v", err)
+	}
+	if result != summarizeBlock {
+		t.Errorf("summarize = %q, want %q", result, summarizeBlock)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	writeTestSession(t, empty, `{"type":"summary","summary":"Nothing"}`+"\n")
+	if _, err := RunSummarize(context.Background(), Config{InputPath: empty}, nil); err == nil {
+		t.Error("Expected an error for a session without prompts or answers")
+	}
+}
+
+func TestConvertSummaryBlock(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, summarizeContent)
+
+	result, err := RunCommand(Config{InputPath: input, SummaryBlock: true, ShowTitle: true})
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	// The summary follows the title and lists the file of the tool call the filters drop
+	if want := "# Fix the flaky test\n\n" + summarizeBlock + "# Conversation Log"; !strings.HasPrefix(result, want) {
+		t.Errorf("Expected output to start with %q, got:\n%s", want, result)
+	}
+}
+
+func TestParseArgsSummaryBlock(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "summarize", "session.jsonl"})
+	if err != nil || !config.Summarize || config.InputPath != "session.jsonl" {
+		t.Errorf("Expected summarize of session.jsonl, got %+v, %v", config, err)
+	}
+	if config, err := ParseArgs([]string{"cclog", "export", "logs", "-o", "out", "--summary-block"}); err != nil || !config.SummaryBlock {
+		t.Errorf("Expected export with summaries, got %+v, %v", config, err)
+	}
+	for _, args := range [][]string{
+		{"cclog", "-d", "logs", "--summary-block"},
+		{"cclog", "session.jsonl", "--summary-block", "--format", "csv"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+// fakeSummarizer answers with a fixed summary and counts the transcripts it was given
+type fakeSummarizer struct {
+	calls       *int
+	transcripts *[]string
+}
+
+func (fakeSummarizer) Name() string { return "fake" }
+
+func (f fakeSummarizer) Summarize(ctx context.Context, transcript string) (string, error) {
+	*f.calls++
+	*f.transcripts = append(*f.transcripts, transcript)
+	return "The user fixed a flaky test.", nil
+}
+
+func TestRunSummarizeLLM(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "summaries.json")
+	calls := 0
+	var transcripts []string
+	summaryCachePath = func() (string, error) { return cachePath, nil }
+	newSummarizer = func() (summary.Summarizer, error) {
+		return fakeSummarizer{calls: &calls, transcripts: &transcripts}, nil
+	}
+	t.Cleanup(func() {
+		summaryCachePath = summary.DefaultCachePath
+		newSummarizer = summary.NewSummarizer
+	})
+
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, summarizeContent)
+	want := "## Summary\n\nThe user fixed a flaky test.\n\n"
+
+	for _, config := range []Config{
+		{InputPath: input, LLM: true},
+		{InputPath: input, LLM: true},              // Cached
+		{InputPath: input, LLM: true, Force: true}, // Asked again
+	} {
+		result, err := RunSummarize(context.Background(), config, nil)
+		if err != nil {
+			t.Fatalf("RunSummarize failed: %v", err)
+		}
+		if result != want {
+			t.Errorf("RunSummarize() = %q, want %q", result, want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the LLM to be asked twice, got %d", calls)
+	}
+	if want := "H: Fix the flaky test\n\nA: The test no longer depends on the clock.\n"; transcripts[0] != want {
+		t.Errorf("Transcript = %q, want %q", transcripts[0], want)
+	}
+
+	cache, err := summary.LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	info, _ := os.Stat(input)
+	if got, ok := cache.Lookup(input, info.Size(), info.ModTime()); !ok || got != "The user fixed a flaky test." {
+		t.Errorf("Expected the summary to be cached, got %q, %t", got, ok)
+	}
+}
+
+func TestRunSummarizeLLMRedactsCachedSummary(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "summaries.json")
+	calls := 0
+	var transcripts []string
+	summaryCachePath = func() (string, error) { return cachePath, nil }
+	newSummarizer = func() (summary.Summarizer, error) {
+		return fakeSummarizer{calls: &calls, transcripts: &transcripts}, nil
+	}
+	t.Cleanup(func() {
+		summaryCachePath = summary.DefaultCachePath
+		newSummarizer = summary.NewSummarizer
+	})
+
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, summarizeContent)
+	// A summary cached by a run without --redact
+	cache, err := summary.LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	info, _ := os.Stat(input)
+	cache.Put(input, info.Size(), info.ModTime(), "fake", "The user mailed alice@example.com about the test.")
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := RunSummarize(context.Background(), Config{InputPath: input, LLM: true}, redactor)
+	if err != nil {
+		t.Fatalf("RunSummarize failed: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected the cached summary to be used, got %d LLM calls", calls)
+	}
+	if strings.Contains(result, "alice@example.com") || !strings.Contains(result, "[REDACTED:email]") {
+		t.Errorf("Expected the cached summary to be redacted, got %q", result)
+	}
+}
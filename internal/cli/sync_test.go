@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSync(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "one.jsonl"), searchContent)
+	writeTestSession(t, filepath.Join(input, "project", "two.jsonl"), searchContent)
+
+	config, err := ParseArgs([]string{"cclog", "sync", input, "--out", output})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	result, err := RunCommand(config)
+	if err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	if !strings.Contains(result, "Synced to "+output+": 2 new, 0 updated, 0 skipped") {
+		t.Errorf("Unexpected summary %q", result)
+	}
+
+	if err := os.Remove(filepath.Join(input, "project", "two.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if !strings.Contains(result, "0 new, 0 updated, 1 skipped, 1 removed") {
+		t.Errorf("Unexpected summary %q", result)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project", "two.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected the markdown of the removed session to be deleted: %v", err)
+	}
+
+	if _, err := RunCommand(Config{Sync: true, InputPath: filepath.Join(input, "project", "one.jsonl"), OutputPath: output}); err == nil {
+		t.Error("Expected an error for a single file input")
+	}
+	if _, err := ParseArgs([]string{"cclog", "sync", input}); err == nil {
+		t.Error("Expected an error without an output directory")
+	}
+}
@@ -2,16 +2,101 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/annenpolka/cclog/internal/settings"
+	"github.com/annenpolka/cclog/internal/summary"
 	"github.com/annenpolka/cclog/pkg/filepicker"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // RunTUI starts the TUI file picker and returns the selected file
 func RunTUI(config Config) (string, error) {
+	settingsPath, pathErr := settings.DefaultPath()
+	var saved settings.Settings
+	canSave := false
+	if pathErr == nil {
+		saved, canSave = loadTUISettings(settingsPath, os.Stderr)
+	}
+
+	// Colors must be set before the model creates its styled components
+	if err := filepicker.ApplyTheme(saved.Theme, saved.Colors); err != nil {
+		return "", fmt.Errorf("invalid theme in %s: %w", settingsPath, err)
+	}
+
+	// Keep preview and editor temp files off read-only mounts
+	tempDir, err := resolveTempDir(isReadOnly(config))
+	if err != nil {
+		return "", err
+	}
+	filepicker.SetTempDir(tempDir)
+	filepicker.RemoveStaleTempFiles(filepicker.StaleTempFileAge)
+
+	// Titles and previews use the configured filter rules
+	if err := configureFilters(config, saved); err != nil {
+		return "", err
+	}
+
+	// Bound recursive scans; flags take precedence over the configured limits
+	if err := filepicker.SetScanLimits(scanLimits(config, saved)); err != nil {
+		return "", fmt.Errorf("invalid scan limits: %w", err)
+	}
+	if err := filepicker.SetProjectFilter(config.Project); err != nil {
+		return "", err
+	}
+	if err := filepicker.SetEditor(resolveEditor(config.Editor, saved)); err != nil {
+		return "", err
+	}
+	if err := filepicker.SetOpenWith(saved.OpenWith); err != nil {
+		return "", fmt.Errorf("invalid settings in %s: %w", settingsPath, err)
+	}
+	filepicker.SetExportDir(resolveExportDir(config.ExportDir, saved))
+
+	// Summaries written by 'cclog summarize --llm' are shown for the selected session
+	if cachePath, err := summaryCachePath(); err == nil {
+		if cache, err := summary.LoadCache(cachePath); err == nil {
+			filepicker.SetSummaryCache(cache)
+		}
+	}
+
+	// Unchanged sessions are listed from the session index, if one was built, without parsing them
+	if ix := openSessionIndex(); ix != nil {
+		defer ix.Close()
+		filepicker.SetSessionIndex(ix)
+	}
+
 	// Create and run the TUI model
 	model := filepicker.NewModel(config.InputPath, config.Recursive)
-	program := tea.NewProgram(model)
+	if err := model.SetKeyMap(saved.Keys); err != nil {
+		return "", fmt.Errorf("invalid key bindings in %s: %w", settingsPath, err)
+	}
+
+	// Restore the preview split ratio from the previous session
+	if saved.SplitRatio > 0 {
+		model.SetSplitRatio(saved.SplitRatio)
+	}
+	initialSplitRatio := model.SplitRatio()
+	model.SetSources(listSources(saved.ExtraRoots))
+	model.SetHomeDir(getDefaultTUIDirectory())
+	model.SetPlain(config.NoColor || os.Getenv("NO_COLOR") != "")
+	if saved.RefreshInterval > 0 {
+		model.SetRefreshInterval(time.Duration(saved.RefreshInterval) * time.Second)
+	}
+
+	// Restore the recently viewed sessions
+	statePath, statePathErr := settings.DefaultStatePath()
+	var state settings.State
+	if statePathErr == nil {
+		state, _ = settings.LoadState(statePath) // Unreadable state starts an empty history
+	}
+	model.SetRecentSessions(state.RecentSessions)
+
+	program := tea.NewProgram(model, tea.WithMouseCellMotion())
 
 	finalModel, err := program.Run()
 	if err != nil {
@@ -20,6 +105,18 @@ func RunTUI(config Config) (string, error) {
 
 	// Get the selected file
 	if m, ok := finalModel.(filepicker.Model); ok {
+		// Persist the split ratio if the user changed it
+		if canSave && m.SplitRatio() != initialSplitRatio {
+			saved.SplitRatio = m.SplitRatio()
+			settings.Save(settingsPath, saved) // Failing to save preferences is not fatal
+		}
+
+		// Persist the recent sessions if any were opened
+		if statePathErr == nil && !slices.Equal(m.RecentSessions(), state.RecentSessions) {
+			state.RecentSessions = m.RecentSessions()
+			settings.SaveState(statePath, state) // Failing to save history is not fatal
+		}
+
 		selectedFile := m.GetSelectedFile()
 		if selectedFile == "" {
 			return "", nil // User cancelled, not an error
@@ -29,3 +126,73 @@ func RunTUI(config Config) (string, error) {
 
 	return "", fmt.Errorf("unexpected model type")
 }
+
+// loadTUISettings loads the settings at path and reports whether preferences changed in the TUI may be
+// saved back to it. Unreadable settings are reported on stderr and fall back to defaults, but are not
+// saved over, so that a typo in the config file does not cost the rest of it.
+func loadTUISettings(path string, stderr io.Writer) (settings.Settings, bool) {
+	saved, err := settings.Load(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v; using default settings\n", err)
+		return settings.Settings{}, false
+	}
+	return saved, true
+}
+
+// resolveEditor returns the editor command of the --editor flag, or else of the settings ("" for the default)
+func resolveEditor(flag string, saved settings.Settings) string {
+	if flag != "" {
+		return flag
+	}
+	return saved.Editor
+}
+
+// resolveExportDir returns the export directory of the --export-dir flag, or else of the settings ("" for temp files)
+func resolveExportDir(flag string, saved settings.Settings) string {
+	if flag != "" {
+		return expandHome(flag)
+	}
+	return expandHome(saved.ExportDir)
+}
+
+// scanLimits combines the scan limits from the command line and the settings.
+// Limits given as flags replace the configured ones; ignore patterns from both apply.
+func scanLimits(config Config, saved settings.Settings) filepicker.ScanLimits {
+	limits := filepicker.ScanLimits{
+		MaxDepth: saved.MaxDepth,
+		MaxFiles: saved.MaxFiles,
+		Ignore:   append(slices.Clone(saved.Ignore), config.Ignore...),
+	}
+	if config.MaxDepth > 0 {
+		limits.MaxDepth = config.MaxDepth
+	}
+	if config.MaxFiles > 0 {
+		limits.MaxFiles = config.MaxFiles
+	}
+	return limits
+}
+
+// listSources returns the directories the TUI can switch between:
+// the default Claude projects directory, the working directory and any configured extra roots
+func listSources(extraRoots []string) []filepicker.Source {
+	sources := []filepicker.Source{{Name: "Claude projects", Dir: getDefaultTUIDirectory()}}
+	if cwd, err := os.Getwd(); err == nil {
+		sources = append(sources, filepicker.Source{Name: "working directory", Dir: cwd})
+	}
+	for _, root := range extraRoots {
+		sources = append(sources, filepicker.Source{Name: "extra root", Dir: expandHome(root)})
+	}
+	return sources
+}
+
+// expandHome replaces a leading "~/" with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
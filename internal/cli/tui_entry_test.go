@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/annenpolka/cclog/internal/settings"
 	"github.com/annenpolka/cclog/pkg/filepicker"
 	"github.com/charmbracelet/x/exp/teatest"
 )
@@ -23,3 +27,112 @@ func TestRunTUI_WithTeatest(t *testing.T) {
 		t.Error("Expected final model to exist")
 	}
 }
+
+func TestListSources(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	sources := listSources([]string{"~/logs", "/srv/logs"})
+
+	if len(sources) != 4 {
+		t.Fatalf("Expected 4 sources, got %+v", sources)
+	}
+	if sources[0].Dir != getDefaultTUIDirectory() {
+		t.Errorf("Expected default Claude directory first, got %s", sources[0].Dir)
+	}
+	if cwd, _ := os.Getwd(); sources[1].Dir != cwd {
+		t.Errorf("Expected working directory second, got %s", sources[1].Dir)
+	}
+	if want := filepath.Join(home, "logs"); sources[2].Dir != want {
+		t.Errorf("Expected ~ to expand to %s, got %s", want, sources[2].Dir)
+	}
+	if sources[3].Dir != "/srv/logs" {
+		t.Errorf("Expected /srv/logs, got %s", sources[3].Dir)
+	}
+}
+
+func TestScanLimits(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "--max-depth", "2", "--ignore", "archive*", "--ignore", "node_modules"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !config.TUIMode || !config.Recursive {
+		t.Errorf("Scan limit flags should open the recursive TUI, got %+v", config)
+	}
+
+	saved := settings.Settings{MaxDepth: 5, MaxFiles: 1000, Ignore: []string{"backup"}}
+	limits := scanLimits(config, saved)
+	if limits.MaxDepth != 2 || limits.MaxFiles != 1000 {
+		t.Errorf("Expected flag depth 2 and configured max files 1000, got %+v", limits)
+	}
+	if strings.Join(limits.Ignore, ",") != "backup,archive*,node_modules" {
+		t.Errorf("Expected configured and flag ignore patterns, got %v", limits.Ignore)
+	}
+
+	if _, err := ParseArgs([]string{"cclog", "--max-files", "0"}); err == nil {
+		t.Error("Expected error for a zero file limit")
+	}
+}
+
+func TestLoadTUISettings(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"editor": "subl -w"}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	var stderr strings.Builder
+	saved, canSave := loadTUISettings(valid, &stderr)
+	if saved.Editor != "subl -w" || !canSave || stderr.Len() != 0 {
+		t.Errorf("Unexpected result for valid settings: %+v, %v, %q", saved, canSave, stderr.String())
+	}
+
+	if _, canSave := loadTUISettings(filepath.Join(dir, "missing.json"), &stderr); !canSave || stderr.Len() != 0 {
+		t.Errorf("Expected missing settings to be created on save, got %v, %q", canSave, stderr.String())
+	}
+
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"editor": "subl -w",}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	saved, canSave = loadTUISettings(malformed, &stderr)
+	if saved.Editor != "" || canSave {
+		t.Errorf("Expected defaults that are not saved over malformed settings, got %+v, %v", saved, canSave)
+	}
+	if !strings.Contains(stderr.String(), "Warning: failed to parse settings "+malformed) {
+		t.Errorf("Expected the parse error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestResolveEditor(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "browse", "--editor", "code --wait"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saved := settings.Settings{Editor: "subl -w"}
+	if got := resolveEditor(config.Editor, saved); got != "code --wait" {
+		t.Errorf("Expected the flag to override the setting, got %q", got)
+	}
+	if got := resolveEditor("", saved); got != "subl -w" {
+		t.Errorf("Expected the configured editor, got %q", got)
+	}
+}
+
+func TestResolveExportDir(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "browse", "--export-dir", "/tmp/exports"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saved := settings.Settings{ExportDir: "~/cclog-exports"}
+	if got := resolveExportDir(config.ExportDir, saved); got != "/tmp/exports" {
+		t.Errorf("Expected the flag to override the setting, got %q", got)
+	}
+	home, _ := os.UserHomeDir()
+	if got := resolveExportDir("", saved); got != filepath.Join(home, "cclog-exports") {
+		t.Errorf("Expected the configured directory under the home directory, got %q", got)
+	}
+	if got := resolveExportDir("", settings.Settings{}); got != "" {
+		t.Errorf("Expected temp files by default, got %q", got)
+	}
+}
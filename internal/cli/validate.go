@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/validate"
+)
+
+// RunValidate checks every line of the JSONL files under the input path against the Claude Code log schema.
+// It returns one "path:line: field: problem" line per deviation followed by a summary, and an error
+// if any deviation was found so that scripts can rely on the exit status.
+func RunValidate(ctx context.Context, config Config) (string, error) {
+	sessions, _, err := export.FindSessions(ctx, config.InputPath)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	lines, deviations, invalidFiles := 0, 0, 0
+	for _, session := range sessions {
+		issues, checked, err := validate.File(ctx, session)
+		if err != nil {
+			return "", err
+		}
+		lines += checked
+		deviations += len(issues)
+		if len(issues) > 0 {
+			invalidFiles++
+		}
+		for _, issue := range issues {
+			sb.WriteString(session + ":" + strings.TrimPrefix(issue.String(), "line ") + "\n")
+		}
+	}
+
+	if deviations == 0 {
+		sb.WriteString(fmt.Sprintf("Checked %d lines in %d files: no deviations\n", lines, len(sessions)))
+		return sb.String(), nil
+	}
+	sb.WriteString(fmt.Sprintf("Checked %d lines in %d files: %d deviations in %d files\n", lines, len(sessions), deviations, invalidFiles))
+	return sb.String(), fmt.Errorf("%d deviations from the Claude Code log schema", deviations)
+}
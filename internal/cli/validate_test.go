@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	input := t.TempDir()
+	writeTestSession(t, filepath.Join(input, "project", "valid.jsonl"), `{"type":"user","message":{"role":"user","content":"Hi"},"uuid":"u1","parentUuid":null,"sessionId":"s1","timestamp":"2025-07-06T05:01:29.618Z"}`+"\n")
+
+	result, err := RunValidate(context.Background(), Config{InputPath: input})
+	if err != nil {
+		t.Fatalf("RunValidate failed: %v\n%s", err, result)
+	}
+	if !strings.HasSuffix(result, "Checked 1 lines in 1 files: no deviations\n") {
+		t.Errorf("Unexpected report %q", result)
+	}
+
+	broken := filepath.Join(input, "broken.jsonl")
+	writeTestSession(t, broken, "{\"type\":\"summary\",\"summary\":\"Title\",\"leafUuid\":\"u1\"}\n{\"type\":\"summary\"}\n")
+	result, err = RunValidate(context.Background(), Config{InputPath: input})
+	if err == nil {
+		t.Fatal("Expected an error for deviations")
+	}
+	for _, want := range []string{
+		broken + ":2: summary: missing required field\n",
+		broken + ":2: leafUuid: missing required field\n",
+		": 2 deviations in 1 files\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestParseArgsValidate(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "validate", "logs"})
+	if err != nil || !config.Validate || config.InputPath != "logs" {
+		t.Fatalf("Expected the validate command, got %+v (%v)", config, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "validate"}); err == nil {
+		t.Error("Expected error without input")
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version, Commit and Date describe the cclog build, set at build time via
+// -ldflags "-X github.com/annenpolka/cclog/internal/cli.Version=v1.2.3 -X ...cli.Commit=abc1234 -X ...cli.Date=2025-07-06T05:00:00Z".
+// Builds without them, e.g. by go install, fall back to the module version and VCS information embedded by Go.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// readBuildInfo is a variable that can be replaced in tests to mock debug.ReadBuildInfo
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildVersion returns the version, commit and build date, filling in what the linker flags left empty
+func buildVersion() (version, commit, date string) {
+	version, commit, date = Version, Commit, Date
+	info, ok := readBuildInfo()
+	if !ok {
+		return version, commit, date
+	}
+	if version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if commit == "" {
+				commit = setting.Value
+			}
+		case "vcs.time":
+			if date == "" {
+				date = setting.Value
+			}
+		}
+	}
+	return version, commit, date
+}
+
+// VersionText returns the line printed by --version, e.g.
+// "cclog v1.2.3 (commit abc1234, built 2025-07-06T05:00:00Z, go1.24.0 darwin/arm64)"
+func VersionText() string {
+	version, commit, date := buildVersion()
+	details := ""
+	if commit != "" {
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		details += "commit " + commit + ", "
+	}
+	if date != "" {
+		details += "built " + date + ", "
+	}
+	return fmt.Sprintf("cclog %s (%s%s %s/%s)\n", version, details, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
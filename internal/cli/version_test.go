@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestVersionText(t *testing.T) {
+	originalInfo := readBuildInfo
+	originalVersion, originalCommit, originalDate := Version, Commit, Date
+	defer func() {
+		readBuildInfo = originalInfo
+		Version, Commit, Date = originalVersion, originalCommit, originalDate
+	}()
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Version: "v0.9.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "0123456789abcdef"},
+				{Key: "vcs.time", Value: "2025-07-01T00:00:00Z"},
+			},
+		}, true
+	}
+
+	// go install builds fall back to the embedded module and VCS information
+	Version, Commit, Date = "dev", "", ""
+	if got := VersionText(); !strings.HasPrefix(got, "cclog v0.9.0 (commit 0123456, built 2025-07-01T00:00:00Z, go") {
+		t.Errorf("Unexpected version text %q", got)
+	}
+
+	// Linker flags take precedence
+	Version, Commit, Date = "v1.2.3", "abc1234", "2025-07-06T05:00:00Z"
+	if got := VersionText(); !strings.HasPrefix(got, "cclog v1.2.3 (commit abc1234, built 2025-07-06T05:00:00Z, go") {
+		t.Errorf("Unexpected version text %q", got)
+	}
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	Version, Commit, Date = "dev", "", ""
+	if got := VersionText(); !strings.HasPrefix(got, "cclog dev (go") {
+		t.Errorf("Unexpected version text %q", got)
+	}
+}
+
+func TestParseArgsVersionAndDoctor(t *testing.T) {
+	config, err := ParseArgs([]string{"cclog", "--version"})
+	if err != nil || !config.ShowVersion {
+		t.Fatalf("Expected --version to be parsed, got %+v (%v)", config, err)
+	}
+	if output, err := RunCommand(config); err != nil || !strings.HasPrefix(output, "cclog ") {
+		t.Errorf("Unexpected version output %q (%v)", output, err)
+	}
+
+	config, err = ParseArgs([]string{"cclog", "doctor"})
+	if err != nil || !config.Doctor {
+		t.Fatalf("Expected the doctor command, got %+v (%v)", config, err)
+	}
+	if _, err := ParseArgs([]string{"cclog", "doctor", "extra"}); err == nil {
+		t.Error("Expected error for arguments to doctor")
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("EDITOR", "nvim")
+
+	output := RunDoctor()
+	for _, want := range []string{"Settings", "Log directories", "not found", "editor     nvim (from $EDITOR)", "Clipboard", "Programs", "Terminal", "problem(s) found"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, output)
+		}
+	}
+}
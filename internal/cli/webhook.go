@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/webhook"
+)
+
+// webhookClient sends the webhook requests, nil for http.DefaultClient; tests point it at a TLS test server
+var webhookClient *http.Client
+
+// postWebhook posts the chat messages of a share format to the webhook of config, in the payload field
+// of the chat service the format is written for
+func postWebhook(ctx context.Context, config Config, chunks []string) error {
+	field := webhook.SlackField
+	if config.Format == formatter.FormatDiscord {
+		field = webhook.DiscordField
+	}
+	return webhook.Webhook{URL: config.Webhook, Field: field, Client: webhookClient}.Post(ctx, chunks)
+}
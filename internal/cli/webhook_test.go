@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertShareFormats(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.jsonl")
+	writeTestSession(t, input, `{"type":"user","message":{"role":"user","content":"Fix the parser"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":"Fixed **the bug** in [parser.go](https://example.com/parser.go)."},"uuid":"a1","timestamp":"2025-07-06T05:02:00Z"}
+`)
+
+	result, err := RunCommand(Config{InputPath: input, Format: "slack", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	want := "*Fix the parser*\n\n*User* · 2025-07-06 05:01\nFix the parser\n\n*Assistant* · 2025-07-06 05:02\nFixed *the bug* in <https://example.com/parser.go|parser.go>.\n"
+	if result != want {
+		t.Errorf("Unexpected slack output:\n%q\nwant:\n%q", result, want)
+	}
+
+	var posted []map[string]string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		posted = append(posted, payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	webhookClient = server.Client()
+	defer func() { webhookClient = nil }()
+
+	config, err := ParseArgs([]string{"cclog", input, "--format", "discord", "--webhook", server.URL})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	result, err = RunCommand(config)
+	if err != nil {
+		t.Fatalf("Posting failed: %v", err)
+	}
+	if result != "Posted 1 message(s) to the webhook\n" {
+		t.Errorf("Unexpected result %q", result)
+	}
+	if len(posted) != 1 || !strings.Contains(posted[0]["content"], "Fixed **the bug** in [parser.go](https://example.com/parser.go).") {
+		t.Errorf("Unexpected payloads: %q", posted)
+	}
+
+	for _, args := range [][]string{
+		{"cclog", input, "--webhook", server.URL},
+		{"cclog", input, "--format", "slack", "--webhook", server.URL, "-o", "out.txt"},
+		{"cclog", input, "--format", "slack", "--webhook", "hooks.slack.com/services/x"},
+		{"cclog", input, "--format", "slack", "--webhook", "http://hooks.slack.com/services/x"},
+	} {
+		if _, err := ParseArgs(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package codeblock extracts the fenced code blocks of assistant messages, e.g. to recover snippets
+// from old sessions.
+package codeblock
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// fileTools maps the tools writing files to the input field holding the path
+var fileTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// extensions maps the languages of code fences to file extensions; unknown languages made of letters and
+// digits keep their name and other blocks are saved as .txt
+var extensions = map[string]string{
+	"bash":       "sh",
+	"c++":        "cpp",
+	"console":    "sh",
+	"csharp":     "cs",
+	"golang":     "go",
+	"javascript": "js",
+	"jsx":        "jsx",
+	"kotlin":     "kt",
+	"markdown":   "md",
+	"patch":      "diff",
+	"python":     "py",
+	"python3":    "py",
+	"ruby":       "rb",
+	"rust":       "rs",
+	"shell":      "sh",
+	"text":       "txt",
+	"typescript": "ts",
+	"yml":        "yaml",
+	"zsh":        "sh",
+}
+
+// Block is a fenced code block of an assistant message
+type Block struct {
+	Language  string // The first word of the fence's info string, e.g. "go", or ""
+	Code      string
+	Timestamp time.Time
+	// Path is the file of the Edit or Write tool calls of the same response, if they all write one file
+	Path string
+}
+
+// Extract returns the fenced code blocks of the assistant messages of log in order. Blocks still open
+// at the end of a message, e.g. in an interrupted answer, are kept.
+func Extract(log *types.ConversationLog) []Block {
+	// Claude Code writes each content block of a response on its own line, so the text and the tool
+	// calls of a response are on different lines sharing the message ID and requestId
+	edited := make(map[string][]string)
+	for _, msg := range log.Messages {
+		if key := responseKey(msg); key != "" {
+			edited[key] = append(edited[key], editedPaths(msg)...)
+		}
+	}
+
+	var blocks []Block
+	for _, msg := range log.Messages {
+		if msg.Type != "assistant" || msg.Message == nil {
+			continue
+		}
+		paths := editedPaths(msg)
+		if key := responseKey(msg); key != "" {
+			paths = edited[key]
+		}
+		filePath := onlyPath(paths)
+		for _, block := range parseFences(types.ExtractTextContent(msg.Message)) {
+			block.Timestamp = msg.Timestamp
+			block.Path = filePath
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// FileName names the nth block (1-based) after its number and language, e.g. "003-go.go", or with
+// byPath after the base name of its Path if it has one, e.g. "003-parser.go"
+func FileName(n int, block Block, byPath bool) string {
+	if byPath && block.Path != "" {
+		if base := path.Base(strings.ReplaceAll(block.Path, `\`, "/")); base != "." && base != "/" {
+			return safepath.Name(fmt.Sprintf("%03d-%s", n, base), fmt.Sprintf("%03d.txt", n))
+		}
+	}
+	language := strings.ToLower(block.Language)
+	if language == "" {
+		return fmt.Sprintf("%03d.txt", n)
+	}
+	ext, ok := extensions[language]
+	if !ok {
+		ext = "txt"
+		if isWord(language) {
+			ext = language
+		}
+	}
+	return safepath.Name(fmt.Sprintf("%03d-%s.%s", n, language, ext), fmt.Sprintf("%03d.txt", n))
+}
+
+// isWord reports whether s, a lowercased language, consists of ASCII letters and digits only
+func isWord(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// responseKey identifies the API response of an assistant log line by its message ID or requestId, or
+// returns "" if it has neither
+func responseKey(msg types.Message) string {
+	switch {
+	case msg.Type != "assistant" || msg.Message == nil:
+		return ""
+	case msg.Message.ID != "":
+		return "id:" + msg.Message.ID
+	case msg.RequestID != "":
+		return "request:" + msg.RequestID
+	}
+	return ""
+}
+
+// editedPaths returns the files of the Edit and Write calls of msg
+func editedPaths(msg types.Message) []string {
+	var paths []string
+	for _, block := range msg.Message.Blocks() {
+		use, ok := block.(types.ToolUseBlock)
+		if !ok {
+			continue
+		}
+		field, ok := fileTools[use.Name]
+		if !ok {
+			continue
+		}
+		if p, _ := use.Input[field].(string); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// onlyPath returns the file all of paths name, or "" if there are none or several
+func onlyPath(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	for _, p := range paths[1:] {
+		if p != paths[0] {
+			return ""
+		}
+	}
+	return paths[0]
+}
+
+// parseFences returns the code blocks fenced with ``` or ~~~ in text. A block is closed by a fence of
+// the same character at least as long as the one opening it.
+func parseFences(text string) []Block {
+	var blocks []Block
+	var fence string
+	var current *Block
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			if marker := fenceMarker(trimmed); marker != "" {
+				fence = marker
+				current = &Block{}
+				if fields := strings.Fields(trimmed[len(marker):]); len(fields) > 0 {
+					current.Language = fields[0]
+				}
+				lines = nil
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) && trimmed == marker {
+			current.Code = strings.Join(lines, "\n") + "\n"
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if current != nil && len(lines) > 0 {
+		current.Code = strings.Join(lines, "\n") + "\n"
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of at least three backticks or tildes line starts with, or ""
+func fenceMarker(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	return line[:n]
+}
@@ -0,0 +1,101 @@
+package codeblock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+const sessionContent = `{"type":"user","message":{"role":"user","content":"` + "```go\\nfunc ignored() {}\\n```" + `"},"uuid":"u1","timestamp":"2025-07-06T05:00:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Like this:\n\n` + "```go title\\nfunc a() {}\\n\\n```" + `\n\nThen:\n\n` + "~~~~\\n```\\nnested\\n```\\n~~~~" + `"},{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/src/app/parser.go"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","message":{"role":"assistant","content":"` + "```Python\\nprint(1)\\n```\\n```sh\\nunfinished" + `"},"uuid":"a2","timestamp":"2025-07-06T05:02:00Z"}
+`
+
+func TestExtract(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	log, err := parser.ParseJSONLFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse session: %v", err)
+	}
+
+	blocks := Extract(log)
+	want := []Block{
+		{Language: "go", Code: "func a() {}\n\n", Path: "/src/app/parser.go"},
+		{Language: "", Code: "```\nnested\n```\n", Path: "/src/app/parser.go"},
+		{Language: "Python", Code: "print(1)\n"},
+		{Language: "sh", Code: "unfinished\n"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("Extract() returned %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, block := range blocks {
+		if block.Language != want[i].Language || block.Code != want[i].Code || block.Path != want[i].Path {
+			t.Errorf("Block %d = %+v, want %+v", i, block, want[i])
+		}
+	}
+	if blocks[0].Timestamp.IsZero() {
+		t.Error("Expected blocks to carry the time of their message")
+	}
+}
+
+// splitContent is written like Claude Code writes responses: one line per content block, sharing the
+// message ID and requestId
+const splitContent = `{"type":"assistant","requestId":"req1","message":{"id":"msg1","role":"assistant","content":[{"type":"text","text":"` + "```go\\nfunc a() {}\\n```" + `"}]},"uuid":"a1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","requestId":"req1","message":{"id":"msg1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/src/app/a.go"}}]},"uuid":"a2","timestamp":"2025-07-06T05:01:01Z"}
+{"type":"assistant","requestId":"req2","message":{"id":"msg2","role":"assistant","content":[{"type":"text","text":"` + "```go\\nfunc b() {}\\n```" + `"}]},"uuid":"a3","timestamp":"2025-07-06T05:02:00Z"}
+{"type":"assistant","requestId":"req3","message":{"role":"assistant","content":[{"type":"text","text":"` + "```go\\nfunc c() {}\\n```" + `"}]},"uuid":"a4","timestamp":"2025-07-06T05:03:00Z"}
+{"type":"assistant","requestId":"req3","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/src/app/c.go"}}]},"uuid":"a5","timestamp":"2025-07-06T05:03:01Z"}
+{"type":"assistant","requestId":"req3","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/src/app/c.go"}}]},"uuid":"a6","timestamp":"2025-07-06T05:03:02Z"}
+`
+
+func TestExtractSplitResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(splitContent), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	log, err := parser.ParseJSONLFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse session: %v", err)
+	}
+
+	blocks := Extract(log)
+	// The Write of the first response names the block on the line before it; the second response writes
+	// nothing; the third is matched by requestId
+	want := []string{"/src/app/a.go", "", "/src/app/c.go"}
+	if len(blocks) != len(want) {
+		t.Fatalf("Extract() returned %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, block := range blocks {
+		if block.Path != want[i] {
+			t.Errorf("Block %d has path %q, want %q", i, block.Path, want[i])
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		block  Block
+		byPath bool
+		want   string
+	}{
+		{Block{Language: "go"}, false, "001-go.go"},
+		{Block{Language: "Python"}, false, "001-python.py"},
+		{Block{Language: "toml"}, false, "001-toml.toml"},
+		{Block{}, false, "001.txt"},
+		{Block{Language: "go", Path: "/src/app/parser.go"}, false, "001-go.go"},
+		{Block{Language: "go", Path: "/src/app/parser.go"}, true, "001-parser.go"},
+		{Block{Language: "go", Path: `C:\src\main.go`}, true, "001-main.go"},
+		{Block{Language: "go"}, true, "001-go.go"},
+		{Block{Language: "../../x"}, false, "001-..-..-x.txt"},
+	}
+	for _, tt := range tests {
+		if got := FileName(1, tt.block, tt.byPath); got != tt.want {
+			t.Errorf("FileName(1, %+v, %v) = %q, want %q", tt.block, tt.byPath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package diff compares two conversations. Messages are aligned first, so that a reply inserted in one
+// attempt does not shift every following message, then the lines of unaligned messages are compared.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Block is a message of a conversation: its markdown and the key identifying it across sessions (its uuid)
+type Block struct {
+	Key  string
+	Text string
+}
+
+// maxCells bounds the size of the table used to compare two sequences. Larger regions are shown as
+// entirely removed and added instead of using gigabytes of memory on two unrelated sessions.
+const maxCells = 16 << 20
+
+// pair is a step of an alignment: A and B are indices into the two sequences, or -1 when the
+// element only exists on the other side
+type pair struct {
+	A, B int
+}
+
+// align returns a longest common subsequence alignment of two sequences of lengths n and m,
+// with the unmatched elements of a before those of b in each gap
+func align(n, m int, equal func(i, j int) bool) []pair {
+	var pairs []pair
+	if n*m > maxCells {
+		for i := 0; i < n; i++ {
+			pairs = append(pairs, pair{i, -1})
+		}
+		for j := 0; j < m; j++ {
+			pairs = append(pairs, pair{-1, j})
+		}
+		return pairs
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case equal(i, j):
+			pairs = append(pairs, pair{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			pairs = append(pairs, pair{i, -1})
+			i++
+		default:
+			pairs = append(pairs, pair{-1, j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		pairs = append(pairs, pair{i, -1})
+	}
+	for ; j < m; j++ {
+		pairs = append(pairs, pair{-1, j})
+	}
+	return pairs
+}
+
+// edit is a line of the diff: ' ' for a line in both, '-' for a removed line and '+' for an added line
+type edit struct {
+	op   byte
+	line string
+}
+
+// diffLines compares two lists of lines
+func diffLines(a, b []string) []edit {
+	var edits []edit
+	var removed, added []edit
+	for _, p := range align(len(a), len(b), func(i, j int) bool { return a[i] == b[j] }) {
+		switch {
+		case p.A >= 0 && p.B >= 0:
+			// Removed lines come before added lines, like diff -u
+			edits = append(append(append(edits, removed...), added...), edit{' ', a[p.A]})
+			removed, added = nil, nil
+		case p.A >= 0:
+			removed = append(removed, edit{'-', a[p.A]})
+		default:
+			added = append(added, edit{'+', b[p.B]})
+		}
+	}
+	return append(append(edits, removed...), added...)
+}
+
+// diffBlocks aligns the messages by key or identical text and compares the lines of the rest
+func diffBlocks(a, b []Block) []edit {
+	equal := func(i, j int) bool {
+		return (a[i].Key != "" && a[i].Key == b[j].Key) || a[i].Text == b[j].Text
+	}
+
+	var edits []edit
+	var removed, added []string
+	flush := func() {
+		edits = append(edits, diffLines(removed, added)...)
+		removed, added = nil, nil
+	}
+	for _, p := range align(len(a), len(b), equal) {
+		switch {
+		case p.A >= 0 && p.B >= 0:
+			flush()
+			edits = append(edits, diffLines(lines(a[p.A].Text), lines(b[p.B].Text))...)
+		case p.A >= 0:
+			removed = append(removed, lines(a[p.A].Text)...)
+		default:
+			added = append(added, lines(b[p.B].Text)...)
+		}
+	}
+	flush()
+	return edits
+}
+
+// lines splits text into lines without a trailing empty line
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Unified returns a unified diff of two conversations with context lines around each change,
+// or "" if their markdown is identical
+func Unified(nameA, nameB string, a, b []Block, context int) string {
+	hunks := writeHunks(diffBlocks(a, b), context)
+	if hunks == "" {
+		return ""
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB) + hunks
+}
+
+// Text returns the hunks of a unified diff of two texts without file names, or "" if they are identical.
+// Line numbers count from the start of the texts.
+func Text(a, b string, context int) string {
+	return writeHunks(diffLines(lines(a), lines(b)), context)
+}
+
+// writeHunks returns the hunks of edits with context lines around each change
+func writeHunks(edits []edit, context int) string {
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		// Find the next change and extend the hunk while changes are at most 2*context lines apart
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for next := first; next < len(edits); next++ {
+			if edits[next].op == ' ' {
+				continue
+			}
+			if next-last > 2*context {
+				break
+			}
+			last = next
+		}
+		from := max(first-context, start)
+		to := min(last+context+1, len(edits))
+		writeHunk(&sb, edits, from, to)
+		start = to
+	}
+	return sb.String()
+}
+
+// writeHunk writes edits[from:to] with a "@@ -l,s +l,s @@" header
+func writeHunk(sb *strings.Builder, edits []edit, from, to int) {
+	// Line numbers are 1-based positions in each side
+	lineA, lineB := 1, 1
+	for _, e := range edits[:from] {
+		if e.op != '+' {
+			lineA++
+		}
+		if e.op != '-' {
+			lineB++
+		}
+	}
+	countA, countB := 0, 0
+	for _, e := range edits[from:to] {
+		if e.op != '+' {
+			countA++
+		}
+		if e.op != '-' {
+			countB++
+		}
+	}
+	// An empty side starts before its first line, like diff -u
+	if countA == 0 {
+		lineA--
+	}
+	if countB == 0 {
+		lineB--
+	}
+
+	sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB))
+	for _, e := range edits[from:to] {
+		sb.WriteByte(e.op)
+		sb.WriteString(e.line + "\n")
+	}
+}
@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedIdentical(t *testing.T) {
+	blocks := []Block{{Key: "u1", Text: "### User\n\nhello\n\n"}}
+	if got := Unified("a", "b", blocks, blocks, 3); got != "" {
+		t.Errorf("Expected no diff, got:\n%s", got)
+	}
+}
+
+func TestUnifiedInsertedMessage(t *testing.T) {
+	a := []Block{
+		{Key: "u1", Text: "### User\n\nFix the build\n\n"},
+		{Key: "a1", Text: "### Assistant\n\nDone.\n\n"},
+	}
+	b := []Block{
+		{Key: "x1", Text: "### User\n\nFix the build\n\n"},
+		{Key: "x2", Text: "### Assistant\n\nLet me run the tests first.\n\n"},
+		{Key: "x3", Text: "### Assistant\n\nDone.\n\n"},
+	}
+
+	// The new message is added as a whole instead of shifting the reply that follows it
+	want := "--- a.jsonl\n+++ b.jsonl\n@@ -3,4 +3,8 @@\n" +
+		" Fix the build\n \n" +
+		"+### Assistant\n+\n+Let me run the tests first.\n+\n" +
+		" ### Assistant\n \n"
+	if got := Unified("a.jsonl", "b.jsonl", a, b, 2); got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedChangedMessageByKey(t *testing.T) {
+	a := []Block{{Key: "u1", Text: "### User\n\nfirst\n\n"}, {Key: "a1", Text: "one\ntwo\nthree\n"}}
+	b := []Block{{Key: "u1", Text: "### User\n\nfirst\n\n"}, {Key: "a1", Text: "one\n2\nthree\n"}}
+
+	got := Unified("a", "b", a, b, 1)
+	if !strings.Contains(got, "@@ -5,3 +5,3 @@\n one\n-two\n+2\n three\n") {
+		t.Errorf("Expected the changed line of the message, got:\n%s", got)
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var a, b []Block
+	for i := 0; i < 20; i++ {
+		text := strings.Repeat("x", i+1) + "\n"
+		a = append(a, Block{Text: text})
+		if i == 2 || i == 17 {
+			text = "changed\n"
+		}
+		b = append(b, Block{Text: text})
+	}
+
+	got := Unified("a", "b", a, b, 3)
+	if strings.Count(got, "@@ -") != 2 {
+		t.Errorf("Expected two hunks, got:\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,6 +1,6 @@") || !strings.Contains(got, "@@ -15,6 +15,6 @@") {
+		t.Errorf("Unexpected hunk ranges:\n%s", got)
+	}
+}
+
+func TestUnifiedEmptySide(t *testing.T) {
+	got := Unified("a", "b", nil, []Block{{Text: "new\n"}}, 3)
+	if got != "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n" {
+		t.Errorf("Unexpected diff against an empty conversation:\n%s", got)
+	}
+}
+
+func TestText(t *testing.T) {
+	got := Text("a\nb\nc\n", "a\nB\nc\nd\n", 1)
+	want := "@@ -1,3 +1,4 @@\n a\n-b\n+B\n c\n+d\n"
+	if got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got := Text("same\n", "same", 3); got != "" {
+		t.Errorf("Expected no diff of identical texts, got %q", got)
+	}
+}
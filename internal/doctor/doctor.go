@@ -0,0 +1,161 @@
+// Package doctor collects the diagnostics of `cclog doctor`: where session logs are found, which editor,
+// clipboard and external programs are available and what the terminal supports. The report is plain text
+// so it can be pasted into bug reports as is.
+package doctor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	// Info describes the environment without judging it
+	Info Status = iota
+	// OK means the feature works
+	OK
+	// Warning means cclog works with fewer features, e.g. without a PDF converter
+	Warning
+	// Problem means a feature is broken, e.g. the log directory is missing
+	Problem
+)
+
+// label is the marker of a status at the start of its line
+func (s Status) label() string {
+	switch s {
+	case OK:
+		return "[ok]  "
+	case Warning:
+		return "[warn]"
+	case Problem:
+		return "[fail]"
+	}
+	return "      "
+}
+
+// Check is one line of the report
+type Check struct {
+	Name   string
+	Detail string
+	Status Status
+}
+
+// Section is a titled group of checks
+type Section struct {
+	Title  string
+	Checks []Check
+}
+
+// Report is the result of cclog doctor
+type Report struct {
+	Header   string // First line, e.g. the version
+	Sections []Section
+}
+
+// Add appends checks to the section titled title, which is created at the end if it does not exist yet
+func (r *Report) Add(title string, checks ...Check) {
+	for i := range r.Sections {
+		if r.Sections[i].Title == title {
+			r.Sections[i].Checks = append(r.Sections[i].Checks, checks...)
+			return
+		}
+	}
+	r.Sections = append(r.Sections, Section{Title: title, Checks: checks})
+}
+
+// Problems counts the checks with status Problem
+func (r Report) Problems() int {
+	count := 0
+	for _, section := range r.Sections {
+		for _, check := range section.Checks {
+			if check.Status == Problem {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// String renders the report with one check per line, names aligned within each section
+func (r Report) String() string {
+	var sb strings.Builder
+	if r.Header != "" {
+		sb.WriteString(strings.TrimRight(r.Header, "\n") + "\n")
+	}
+	for _, section := range r.Sections {
+		width := 0
+		for _, check := range section.Checks {
+			width = max(width, len(check.Name))
+		}
+		sb.WriteString("\n" + section.Title + "\n")
+		for _, check := range section.Checks {
+			line := fmt.Sprintf("  %s %-*s  %s", check.Status.label(), width, check.Name, check.Detail)
+			sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// lookPath is a variable that can be replaced in tests to mock exec.LookPath
+var lookPath = exec.LookPath
+
+// Program checks whether an external program is on the PATH and names what it is used for.
+// A missing program is reported with status missing.
+func Program(name, purpose string, missing Status) Check {
+	path, err := lookPath(name)
+	if err != nil {
+		return Check{Name: name, Detail: "not found (needed for " + purpose + ")", Status: missing}
+	}
+	return Check{Name: name, Detail: path + " (" + purpose + ")", Status: OK}
+}
+
+// LogDirectory checks that a directory of session logs exists and counts its projects and sessions.
+// A missing directory is reported with status missing.
+func LogDirectory(name, dir string, missing Status) Check {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return Check{Name: name, Detail: dir + " (not found)", Status: missing}
+	}
+	if !info.IsDir() {
+		return Check{Name: name, Detail: dir + " (not a directory)", Status: Problem}
+	}
+
+	projects := make(map[string]bool)
+	sessions := 0
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Unreadable subdirectories are skipped like in the TUI
+		}
+		if !d.IsDir() && parser.IsLogFile(path) {
+			sessions++
+			projects[filepath.Dir(path)] = true
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return Check{Name: name, Detail: fmt.Sprintf("%s (unreadable: %v)", dir, walkErr), Status: Problem}
+	}
+	if sessions == 0 {
+		return Check{Name: name, Detail: dir + " (no sessions)", Status: Warning}
+	}
+	return Check{Name: name, Detail: fmt.Sprintf("%s (%s, %s)", dir, count(len(projects), "project"), count(sessions, "session")), Status: OK}
+}
+
+// count formats n with noun, adding "s" unless n is 1
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
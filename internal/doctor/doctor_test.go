@@ -0,0 +1,83 @@
+package doctor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	var report Report
+	report.Header = "cclog v1.0.0"
+	report.Add("Programs", Check{Name: "claude", Detail: "/usr/bin/claude", Status: OK})
+	report.Add("Terminal", Check{Name: "TERM", Detail: "xterm"})
+	report.Add("Programs", Check{Name: "PDF converter", Detail: "missing", Status: Problem})
+
+	want := "cclog v1.0.0\n\n" +
+		"Programs\n" +
+		"  [ok]   claude         /usr/bin/claude\n" +
+		"  [fail] PDF converter  missing\n\n" +
+		"Terminal\n" +
+		"         TERM  xterm\n"
+	if got := report.String(); got != want {
+		t.Errorf("Unexpected report:\n%s\nwant:\n%s", got, want)
+	}
+	if report.Problems() != 1 {
+		t.Errorf("Expected 1 problem, got %d", report.Problems())
+	}
+}
+
+func TestLogDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app/a.jsonl", "app/b.jsonl", "lib/c.jsonl", "lib/notes.txt"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if check := LogDirectory("projects", dir, Problem); check.Status != OK || !strings.HasSuffix(check.Detail, "(2 projects, 3 sessions)") {
+		t.Errorf("Unexpected check %+v", check)
+	}
+	if check := LogDirectory("projects", filepath.Join(dir, "app", "a.jsonl"), Problem); check.Status != Problem {
+		t.Errorf("Expected a file to be a problem, got %+v", check)
+	}
+	if check := LogDirectory("extra", filepath.Join(dir, "missing"), Warning); check.Status != Warning || !strings.Contains(check.Detail, "not found") {
+		t.Errorf("Expected a missing directory with the given status, got %+v", check)
+	}
+	if check := LogDirectory("empty", t.TempDir(), Problem); check.Status != Warning {
+		t.Errorf("Expected a warning for a directory without sessions, got %+v", check)
+	}
+}
+
+func TestProgram(t *testing.T) {
+	original := lookPath
+	defer func() { lookPath = original }()
+	lookPath = func(name string) (string, error) {
+		if name == "claude" {
+			return "/usr/local/bin/claude", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	if check := Program("claude", "resume", Warning); check.Status != OK || check.Detail != "/usr/local/bin/claude (resume)" {
+		t.Errorf("Unexpected check %+v", check)
+	}
+	if check := Program("xclip", "copying", Warning); check.Status != Warning || !strings.Contains(check.Detail, "not found") {
+		t.Errorf("Unexpected check %+v", check)
+	}
+}
+
+func TestTerminalNotATerminal(t *testing.T) {
+	original := isTerminal
+	defer func() { isTerminal = original }()
+	isTerminal = func() bool { return false }
+
+	checks := Terminal()
+	if len(checks) != 1 || checks[0].Status != Warning {
+		t.Errorf("Expected a single warning when the output is not a terminal, got %+v", checks)
+	}
+}
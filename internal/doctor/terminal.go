@@ -0,0 +1,51 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// getenv and isTerminal are variables that can be replaced in tests
+var (
+	getenv     = os.Getenv
+	isTerminal = func() bool { return term.IsTerminal(int(os.Stdout.Fd())) }
+)
+
+// Terminal describes what the terminal supports: whether output goes to a terminal, its size,
+// TERM, the color profile used by the TUI and the environment variables that change it
+func Terminal() []Check {
+	if !isTerminal() {
+		return []Check{{Name: "output", Detail: "not a terminal (the TUI needs one)", Status: Warning}}
+	}
+
+	checks := []Check{{Name: "output", Detail: "terminal", Status: OK}}
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		checks = append(checks, Check{Name: "size", Detail: fmt.Sprintf("%dx%d", width, height)})
+	}
+	checks = append(checks, Check{Name: "TERM", Detail: valueOrUnset(getenv("TERM"))})
+	checks = append(checks, Check{Name: "colors", Detail: lipgloss.ColorProfile().Name()})
+
+	background := "light"
+	if lipgloss.HasDarkBackground() {
+		background = "dark"
+	}
+	checks = append(checks, Check{Name: "background", Detail: background + " (used by the auto theme)"})
+
+	for _, variable := range []string{"COLORTERM", "NO_COLOR", "TMUX"} {
+		if value := getenv(variable); value != "" {
+			checks = append(checks, Check{Name: variable, Detail: value})
+		}
+	}
+	return checks
+}
+
+// valueOrUnset returns value, or "(unset)" if it is empty
+func valueOrUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
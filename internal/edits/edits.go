@@ -0,0 +1,274 @@
+// Package edits collects the changes the assistant made to files with the Edit, MultiEdit and Write tools
+// into a per-file change log, with the diff of each change where the log allows one.
+package edits
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/diff"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// diffContext is the number of unchanged lines around each change of a diff rebuilt from a tool's input
+const diffContext = 3
+
+// Change is a change of a file by a tool call
+type Change struct {
+	Tool      string // Edit, MultiEdit or Write
+	Timestamp time.Time
+	// Created marks a Write that created the file
+	Created bool
+	// Diff holds unified diff hunks, with line numbers of the file when Claude Code recorded the patch and
+	// of the replaced text otherwise, or "" for a Write whose previous content is unknown
+	Diff           string
+	Added, Removed int // Lines added and removed by Diff, or for Write without a diff the lines written
+}
+
+// File is the change log of a file
+type File struct {
+	Path    string // Relative to the working directory when inside it
+	Changes []Change
+}
+
+// Added returns the number of lines added by the changes of the file
+func (f File) Added() int {
+	n := 0
+	for _, change := range f.Changes {
+		n += change.Added
+	}
+	return n
+}
+
+// Removed returns the number of lines removed by the changes of the file
+func (f File) Removed() int {
+	n := 0
+	for _, change := range f.Changes {
+		n += change.Removed
+	}
+	return n
+}
+
+// call is a tool call changing a file, waiting for its result
+type call struct {
+	path   string
+	change Change
+	input  map[string]interface{}
+}
+
+// Extract returns the files changed in log, in the order they were first changed, with their changes in
+// the order they were made. Calls whose result is an error, such as an Edit whose old_string was not
+// found or a rejected permission, changed nothing and are left out.
+func Extract(log *types.ConversationLog) []File {
+	var calls []*call
+	byID := make(map[string]*call)
+	failed := make(map[*call]bool)
+	for _, msg := range log.Messages {
+		if msg.Message == nil {
+			continue
+		}
+		for _, block := range msg.Message.Blocks() {
+			switch b := block.(type) {
+			case types.ToolUseBlock:
+				if b.Name != "Edit" && b.Name != "MultiEdit" && b.Name != "Write" {
+					continue
+				}
+				path, _ := b.Input["file_path"].(string)
+				if path == "" {
+					continue
+				}
+				c := &call{path: relativeTo(msg.CWD, path), change: Change{Tool: b.Name, Timestamp: msg.Timestamp}, input: b.Input}
+				c.change.Diff = inputDiff(b.Name, b.Input)
+				calls = append(calls, c)
+				if b.ID != "" {
+					byID[b.ID] = c
+				}
+			case types.ToolResultBlock:
+				c, ok := byID[b.ToolUseID]
+				if !ok {
+					continue
+				}
+				if b.IsError {
+					failed[c] = true
+					continue
+				}
+				metadata, _ := msg.ToolUseResult.(map[string]interface{})
+				if attached, ok := b.ToolUseResult.(map[string]interface{}); ok {
+					metadata = attached // Result moved next to its tool_use
+				}
+				applyResult(c, metadata)
+			}
+		}
+	}
+
+	var files []File
+	index := make(map[string]int)
+	for _, c := range calls {
+		if failed[c] {
+			continue
+		}
+		c.change.Added, c.change.Removed = countLines(c.change.Diff)
+		if content, _ := c.input["content"].(string); c.change.Diff == "" && content != "" {
+			c.change.Added = len(strings.Split(strings.TrimSuffix(content, "\n"), "\n")) // A Write without a diff
+		}
+		i, ok := index[c.path]
+		if !ok {
+			i = len(files)
+			index[c.path] = i
+			files = append(files, File{Path: c.path})
+		}
+		files[i].Changes = append(files[i].Changes, c.change)
+	}
+	return files
+}
+
+// applyResult takes the patch Claude Code recorded in the toolUseResult metadata of a call, which has the
+// line numbers of the file, and whether a Write created the file
+func applyResult(c *call, metadata map[string]interface{}) {
+	if metadata == nil {
+		return
+	}
+	if patch := structuredPatch(metadata["structuredPatch"]); patch != "" {
+		c.change.Diff = patch
+	}
+	if kind, _ := metadata["type"].(string); kind == "create" && c.change.Tool == "Write" {
+		c.change.Created = true
+		content, _ := c.input["content"].(string)
+		c.change.Diff = diff.Text("", content, diffContext)
+	}
+}
+
+// inputDiff rebuilds the diff of an Edit or MultiEdit from the replaced and replacing text of its input.
+// A Write only has the new content, so its diff is left to its result.
+func inputDiff(tool string, input map[string]interface{}) string {
+	switch tool {
+	case "Edit":
+		return replacementDiff(input)
+	case "MultiEdit":
+		var sb strings.Builder
+		edits, _ := input["edits"].([]interface{})
+		for _, edit := range edits {
+			if fields, ok := edit.(map[string]interface{}); ok {
+				sb.WriteString(replacementDiff(fields))
+			}
+		}
+		return sb.String()
+	}
+	return ""
+}
+
+// replacementDiff returns the diff of the old_string and new_string of an edit
+func replacementDiff(fields map[string]interface{}) string {
+	oldString, _ := fields["old_string"].(string)
+	newString, _ := fields["new_string"].(string)
+	return diff.Text(oldString, newString, diffContext)
+}
+
+// structuredPatch renders the structuredPatch of a toolUseResult, a list of hunks with their line ranges
+// and lines prefixed with ' ', '-' or '+', as unified diff hunks, or returns "" if it is missing or malformed
+func structuredPatch(value interface{}) string {
+	hunks, _ := value.([]interface{})
+	var sb strings.Builder
+	for _, hunk := range hunks {
+		fields, ok := hunk.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		oldStart, ok1 := fields["oldStart"].(float64)
+		oldLines, ok2 := fields["oldLines"].(float64)
+		newStart, ok3 := fields["newStart"].(float64)
+		newLines, ok4 := fields["newLines"].(float64)
+		lines, ok5 := fields["lines"].([]interface{})
+		if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 {
+			return ""
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", int(oldStart), int(oldLines), int(newStart), int(newLines))
+		for _, line := range lines {
+			text, ok := line.(string)
+			if !ok {
+				return ""
+			}
+			sb.WriteString(text + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// countLines returns the number of added and removed lines of unified diff hunks
+func countLines(hunks string) (added, removed int) {
+	for _, line := range strings.Split(hunks, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// relativeTo returns path relative to the working directory cwd if it is inside it
+func relativeTo(cwd, path string) string {
+	if cwd == "" {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Markdown renders the change log of files with the times of the changes in loc (nil means local
+// time), or returns "" if no file was changed
+func Markdown(files []File, loc *time.Location) string {
+	if len(files) == 0 {
+		return ""
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	changes, added, removed := 0, 0, 0
+	for _, file := range files {
+		changes += len(file.Changes)
+		added += file.Added()
+		removed += file.Removed()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# File edits\n\n")
+	fmt.Fprintf(&sb, "%s changed in %s (+%d -%d)\n\n", plural(len(files), "file"), plural(changes, "edit"), added, removed)
+	for _, file := range files {
+		fmt.Fprintf(&sb, "## `%s` (%s, +%d -%d)\n\n", file.Path, plural(len(file.Changes), "edit"), file.Added(), file.Removed())
+		for _, change := range file.Changes {
+			heading := change.Tool
+			if !change.Timestamp.IsZero() {
+				heading = change.Timestamp.In(loc).Format("2006-01-02 15:04") + " " + heading
+			}
+			switch {
+			case change.Created:
+				fmt.Fprintf(&sb, "### %s (created, %s)\n\n", heading, plural(change.Added, "line"))
+			case change.Diff == "":
+				fmt.Fprintf(&sb, "### %s (%s written)\n\n", heading, plural(change.Added, "line"))
+			default:
+				fmt.Fprintf(&sb, "### %s (+%d -%d)\n\n", heading, change.Added, change.Removed)
+			}
+			if change.Diff != "" {
+				sb.WriteString(formatter.CodeBlock("diff", change.Diff) + "\n\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// plural returns n and noun, with an "s" unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
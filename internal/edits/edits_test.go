@@ -0,0 +1,97 @@
+package edits
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+const sessionContent = `{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/src/app/main.go","content":"package main\n\nfunc main() {}\n"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"File created"}]},"toolUseResult":{"type":"create","filePath":"/src/app/main.go","structuredPatch":[]},"uuid":"r1","timestamp":"2025-07-06T05:01:01Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/src/app/main.go","old_string":"func main() {}","new_string":"func main() {\n\trun()\n}"}}]},"uuid":"a2","timestamp":"2025-07-06T05:02:00Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"Updated"}]},"toolUseResult":{"structuredPatch":[{"oldStart":2,"oldLines":2,"newStart":2,"newLines":4,"lines":[" ","-func main() {}","+func main() {","+\trun()","+}"]}]},"uuid":"r2","timestamp":"2025-07-06T05:02:01Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/src/app/main.go","old_string":"missing","new_string":"x"}}]},"uuid":"a3","timestamp":"2025-07-06T05:03:00Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"String to replace not found","is_error":true}]},"uuid":"r3","timestamp":"2025-07-06T05:03:01Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"MultiEdit","input":{"file_path":"/etc/app.conf","edits":[{"old_string":"a = 1","new_string":"a = 2"},{"old_string":"b = 1","new_string":"b = 2"}]}}]},"uuid":"a4","timestamp":"2025-07-06T05:04:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t5","name":"Write","input":{"file_path":"/src/app/README.md","content":"# App\n\nDocs\n"}}]},"uuid":"a5","timestamp":"2025-07-06T05:05:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t6","name":"Read","input":{"file_path":"/src/app/go.mod"}}]},"uuid":"a6","timestamp":"2025-07-06T05:06:00Z"}
+`
+
+func TestExtract(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	log, err := parser.ParseJSONLFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse session: %v", err)
+	}
+
+	files := Extract(log)
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if want := "main.go,/etc/app.conf,README.md"; strings.Join(paths, ",") != want {
+		t.Fatalf("Files = %v, want %s", paths, want)
+	}
+
+	main := files[0]
+	if len(main.Changes) != 2 {
+		t.Fatalf("Expected the failed edit of main.go to be left out, got %+v", main.Changes)
+	}
+	if created := main.Changes[0]; !created.Created || created.Added != 3 || !strings.HasPrefix(created.Diff, "@@ -0,0 +1,3 @@\n+package main\n") {
+		t.Errorf("Unexpected creation %+v", created)
+	}
+	// The recorded patch has the line numbers of the file
+	if edit := main.Changes[1]; edit.Diff != "@@ -2,2 +2,4 @@\n \n-func main() {}\n+func main() {\n+\trun()\n+}\n" || edit.Added != 3 || edit.Removed != 1 {
+		t.Errorf("Unexpected edit %+v", edit)
+	}
+
+	// Without a recorded patch, the diff is rebuilt from the replaced texts
+	if conf := files[1].Changes[0]; conf.Diff != "@@ -1,1 +1,1 @@\n-a = 1\n+a = 2\n@@ -1,1 +1,1 @@\n-b = 1\n+b = 2\n" || conf.Added != 2 || conf.Removed != 2 {
+		t.Errorf("Unexpected MultiEdit %+v", conf)
+	}
+	if readme := files[2].Changes[0]; readme.Diff != "" || readme.Created || readme.Added != 3 {
+		t.Errorf("Unexpected Write without result %+v", readme)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	at := time.Date(2025, 7, 6, 5, 1, 0, 0, time.UTC)
+	files := []File{
+		{Path: "main.go", Changes: []Change{
+			{Tool: "Write", Timestamp: at, Created: true, Diff: "@@ -0,0 +1,1 @@\n+package main\n", Added: 1},
+			{Tool: "Write", Timestamp: at.Add(time.Minute), Added: 2},
+		}},
+	}
+	want := "# File edits\n\n" +
+		"1 file changed in 2 edits (+3 -0)\n\n" +
+		"## `main.go` (2 edits, +3 -0)\n\n" +
+		"### 2025-07-06 14:01 Write (created, 1 line)\n\n" +
+		"```diff\n@@ -0,0 +1,1 @@\n+package main\n```\n\n" +
+		"### 2025-07-06 14:02 Write (2 lines written)\n\n"
+	if got := Markdown(files, time.FixedZone("JST", 9*3600)); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+	if Markdown(nil, time.UTC) != "" {
+		t.Error("Expected no markdown without changes")
+	}
+}
+
+func TestMarkdownLengthensFence(t *testing.T) {
+	// A diff of a markdown file may contain fences itself
+	files := []File{
+		{Path: "README.md", Changes: []Change{
+			{Tool: "Edit", Diff: "@@ -1,1 +1,3 @@\n+```go\n+x\n+```\n", Added: 3},
+		}},
+	}
+	got := Markdown(files, time.UTC)
+	if !strings.Contains(got, "````diff\n@@ -1,1 +1,3 @@\n+```go\n+x\n+```\n````\n\n") {
+		t.Errorf("Expected a longer fence around the diff, got %q", got)
+	}
+}
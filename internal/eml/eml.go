@@ -0,0 +1,136 @@
+// Package eml renders conversations as email: an RFC 822 message per conversation, or an mbox of
+// them, so sessions can be archived and searched in the mail clients teams already use.
+package eml
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Format is the --format name of email output
+const Format = "eml"
+
+// Addresses of the messages; the host is reserved, so they can never reach anyone
+const (
+	fromAddress = "Claude Code <claude-code@cclog.invalid>"
+	toAddress   = "cclog <cclog@cclog.invalid>"
+	mboxSender  = "claude-code@cclog.invalid"
+)
+
+// mboxFrom matches the body lines an mbox reader would take for the start of a message, and lines
+// already quoted like them, which are quoted once more (the mboxrd convention)
+var mboxFrom = regexp.MustCompile(`(?m)^(>*From )`)
+
+// WriteMessage writes log as an RFC 822 message with CRLF line endings: the title is the subject, the
+// first message's time the date and the session ID the Message-ID, and the body is the markdown of
+// the conversation, quoted-printable encoded
+func WriteMessage(w io.Writer, log *types.ConversationLog, opt formatter.FormatOptions) error {
+	message, err := render(log, opt)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(message)
+	return err
+}
+
+// WriteMbox writes logs as an mbox, one message per conversation in the format of WriteMessage with
+// LF line endings, each after a "From " separator line
+func WriteMbox(w io.Writer, logs []*types.ConversationLog, opt formatter.FormatOptions) error {
+	buffered := bufio.NewWriter(w)
+	for _, log := range logs {
+		message, err := render(log, opt)
+		if err != nil {
+			return err
+		}
+		message = bytes.ReplaceAll(message, []byte("\r\n"), []byte("\n"))
+		message = mboxFrom.ReplaceAll(message, []byte(">$1"))
+		date := startTime(log)
+		if date.IsZero() {
+			date = time.Unix(0, 0)
+		}
+		fmt.Fprintf(buffered, "From %s %s\n", mboxSender, date.UTC().Format(time.ANSIC))
+		buffered.Write(message)
+		buffered.WriteString("\n")
+	}
+	return buffered.Flush()
+}
+
+// render returns the RFC 822 message of log
+func render(log *types.ConversationLog, opt formatter.FormatOptions) ([]byte, error) {
+	var body bytes.Buffer
+	qp := quotedprintable.NewWriter(&body)
+	if err := formatter.WriteConversationMarkdown(qp, log, opt); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	var sb bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&sb, "%s: %s\r\n", name, value)
+	}
+	header("From", fromAddress)
+	header("To", toAddress)
+	header("Subject", mime.QEncoding.Encode("utf-8", singleLine(types.ExtractTitle(log))))
+	if date := startTime(log); !date.IsZero() {
+		header("Date", date.In(opt.Location()).Format(time.RFC1123Z))
+	}
+	id := types.SessionID(log)
+	if id == "" {
+		id = parser.TrimLogExt(filepath.Base(log.FilePath))
+	}
+	if id = messageIDPart(id); id != "" {
+		header("Message-ID", "<"+id+"@cclog.invalid>")
+		header("X-Cclog-Session-Id", id)
+	}
+	if project := types.ProjectName(log); project != "" {
+		header("X-Cclog-Project", mime.QEncoding.Encode("utf-8", singleLine(project)))
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	sb.WriteString("\r\n")
+	sb.Write(body.Bytes())
+	if !bytes.HasSuffix(body.Bytes(), []byte("\r\n")) {
+		sb.WriteString("\r\n")
+	}
+	return sb.Bytes(), nil
+}
+
+// startTime returns the time of the first message of log with one, or the zero time
+func startTime(log *types.ConversationLog) time.Time {
+	for _, msg := range formatter.SortMessagesChronologically(log.Messages) {
+		if !msg.Timestamp.IsZero() {
+			return msg.Timestamp
+		}
+	}
+	return time.Time{}
+}
+
+// singleLine joins the lines of a header value, which must not contain line breaks
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// messageIDPart keeps the characters of s allowed in the left part of a Message-ID
+func messageIDPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > ' ' && r < 0x7f && !strings.ContainsRune(`()<>[]:;@\,"`, r) {
+			return r
+		}
+		return -1
+	}, s)
+}
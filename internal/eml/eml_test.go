@@ -0,0 +1,89 @@
+package eml
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func testLog(sessionID, prompt string) *types.ConversationLog {
+	ts := time.Date(2025, 7, 6, 5, 1, 0, 0, time.UTC)
+	msg := func(offset int, role, text string) types.Message {
+		return types.Message{
+			Type:      role,
+			SessionID: sessionID,
+			CWD:       "/work/cclog",
+			Timestamp: ts.Add(time.Duration(offset) * time.Minute),
+			Message:   &types.MessageBody{Role: role, Content: types.TextContent(text)},
+		}
+	}
+	return &types.ConversationLog{
+		FilePath: sessionID + ".jsonl",
+		Messages: []types.Message{
+			msg(0, "user", prompt),
+			msg(1, "assistant", "From the logs: the parser drops the last line. Café fixed."),
+		},
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteMessage(&sb, testLog("s1", "Fix the parsér"), formatter.FormatOptions{Timezone: time.UTC}); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+	if strings.Contains(strings.ReplaceAll(sb.String(), "\r\n", ""), "\n") {
+		t.Errorf("Expected CRLF line endings only")
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Not a valid message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Fix the parsér" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if date, err := msg.Header.Date(); err != nil || !date.Equal(time.Date(2025, 7, 6, 5, 1, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, %v", date, err)
+	}
+	if id := msg.Header.Get("Message-ID"); id != "<s1@cclog.invalid>" {
+		t.Errorf("Message-ID = %q", id)
+	}
+	if project := msg.Header.Get("X-Cclog-Project"); project != "cclog" {
+		t.Errorf("X-Cclog-Project = %q", project)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("Invalid body: %v", err)
+	}
+	if !strings.Contains(string(body), "Fix the parsér") || !strings.Contains(string(body), "Café fixed.") {
+		t.Errorf("Body is missing the exchange:\n%s", body)
+	}
+}
+
+func TestWriteMbox(t *testing.T) {
+	var sb strings.Builder
+	logs := []*types.ConversationLog{testLog("s1", "First"), testLog("s2", "Second")}
+	if err := WriteMbox(&sb, logs, formatter.FormatOptions{Timezone: time.UTC}); err != nil {
+		t.Fatalf("WriteMbox failed: %v", err)
+	}
+	mbox := sb.String()
+	if strings.Contains(mbox, "\r") {
+		t.Errorf("Expected LF line endings in the mbox")
+	}
+	separators := strings.Count(mbox, "\nFrom claude-code@cclog.invalid ") + 1
+	if !strings.HasPrefix(mbox, "From claude-code@cclog.invalid Sun Jul  6 05:01:00 2025\n") || separators != 2 {
+		t.Errorf("Expected 2 messages with separators:\n%s", mbox)
+	}
+	// Body lines starting with "From " are quoted so they do not start a message
+	if !strings.Contains(mbox, "\n>From the logs") {
+		t.Errorf("Expected the From line of the reply to be quoted:\n%s", mbox)
+	}
+}
@@ -0,0 +1,419 @@
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/assets"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/ignore"
+	"github.com/annenpolka/cclog/internal/outfile"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/internal/summary"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// ManifestName is the file in the output directory that records exported content hashes
+const ManifestName = ".cclog-export.json"
+
+// Options controls a batch export
+type Options struct {
+	InputPath       string // JSONL file or directory searched recursively for .jsonl files
+	OutputDir       string
+	EnableFiltering bool
+	Format          formatter.FormatOptions
+	Force           bool              // Export every session even if its content is unchanged
+	Tools           filter.ToolFilter // Selects the tools whose calls and results are exported
+	Roles           []string          // Exports only the messages of these roles (empty keeps all)
+	ExtractImages   bool              // Save pasted images to an assets directory next to each exported file
+	Redactor        *redact.Redactor  // Replaces secrets before formatting (nil disables redaction)
+	SummaryBlock    bool              // Start each exported file with a summary of the session (see summary.Extract)
+	Hook            *hook.Hook        // Run after each new, updated or removed file (nil runs nothing)
+	// Prune deletes the files of the sessions exported by earlier runs that are no longer under InputPath,
+	// making the output a mirror of the input
+	Prune bool
+}
+
+// Result summarizes a batch export
+type Result struct {
+	New     []string // Sessions exported for the first time
+	Updated []string // Sessions whose content or format options changed
+	Skipped []string // Sessions whose content is unchanged since the last export
+	Removed []string // Sessions gone from the input whose files were deleted (see Options.Prune)
+	Failed  []error  // Sessions that could not be exported
+	// HookFailed holds the errors of hook runs; their files were exported and are not retried
+	HookFailed []error
+}
+
+// Summary returns a one-line count of new, updated, skipped, removed and failed sessions and of the sessions
+// whose hook failed
+func (r *Result) Summary() string {
+	summary := fmt.Sprintf("%d new, %d updated, %d skipped", len(r.New), len(r.Updated), len(r.Skipped))
+	if len(r.Removed) > 0 {
+		summary += fmt.Sprintf(", %d removed", len(r.Removed))
+	}
+	if len(r.Failed) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(r.Failed))
+	}
+	if len(r.HookFailed) > 0 {
+		summary += fmt.Sprintf(", hook failed for %d", len(r.HookFailed))
+	}
+	return summary
+}
+
+// manifest maps session paths relative to the input to their content hash
+type manifest struct {
+	Root  string            `json:"root,omitempty"` // Absolute input directory the paths are relative to
+	Files map[string]string `json:"files"`
+}
+
+// Run exports every session under opts.InputPath to markdown in opts.OutputDir,
+// skipping sessions whose content hash matches the manifest from the previous run.
+// When ctx is done, the manifest of the sessions exported so far is saved and ctx.Err() is returned with the partial result.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	sessions, root, err := FindSessions(ctx, opts.InputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	manifestPath := filepath.Join(opts.OutputDir, ManifestName)
+	previous, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	if opts.Prune && previous.Root != "" && previous.Root != root && len(previous.Files) > 0 {
+		return nil, fmt.Errorf("output directory %s mirrors %s, not %s; use another output directory", opts.OutputDir, previous.Root, root)
+	}
+	current := manifest{Root: root, Files: make(map[string]string)}
+
+	result := &Result{}
+	found := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			break
+		}
+		rel, err := filepath.Rel(root, session)
+		if err != nil {
+			rel = filepath.Base(session)
+		}
+		rel = filepath.ToSlash(rel)
+		found[rel] = true
+		outputPath, err := safepath.Join(opts.OutputDir, MarkdownPath(rel))
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Errorf("%s: %w", session, err))
+			continue
+		}
+
+		hash, err := hashSession(session, opts)
+		if err != nil {
+			result.Failed = append(result.Failed, err)
+			continue
+		}
+
+		previousHash, known := previous.Files[rel]
+		if known && previousHash == hash && !opts.Force && fileExists(outputPath) {
+			current.Files[rel] = hash
+			result.Skipped = append(result.Skipped, rel)
+			continue
+		}
+
+		log, err := exportSession(ctx, session, outputPath, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				if known {
+					current.Files[rel] = previousHash
+				}
+				break
+			}
+			result.Failed = append(result.Failed, err)
+			// Keep the previous hash so the session is retried next time
+			if known {
+				current.Files[rel] = previousHash
+			}
+			continue
+		}
+
+		current.Files[rel] = hash
+		event := hook.EventNew
+		if known {
+			event = hook.EventUpdated
+			result.Updated = append(result.Updated, rel)
+		} else {
+			result.New = append(result.New, rel)
+		}
+
+		if opts.Hook != nil {
+			if err := opts.Hook.Run(ctx, sessionEvent(event, outputPath, session, log, opts.Redactor)); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				result.HookFailed = append(result.HookFailed, err)
+			}
+		}
+	}
+
+	if opts.Prune && ctx.Err() == nil {
+		prune(ctx, opts, root, previous, found, result)
+	}
+	if err := ctx.Err(); err != nil {
+		// Sessions not reached keep their previous hash, so an interrupted export can be resumed
+		for rel, hash := range previous.Files {
+			if _, ok := current.Files[rel]; !ok {
+				current.Files[rel] = hash
+			}
+		}
+	}
+	if err := saveManifest(manifestPath, current); err != nil {
+		return result, err
+	}
+	return result, ctx.Err()
+}
+
+// prune deletes the files of the sessions of the previous manifest that were not found under the input,
+// and the directories left empty, and runs the hook for each. Only files the manifest records are
+// deleted, so files added to the output directory by hand are kept, and files a session found under the
+// input maps to, e.g. after x.jsonl was compressed to x.jsonl.gz, are kept too. Manifests of older
+// versions do not record the input directory, so nothing is pruned until the next run records it.
+func prune(ctx context.Context, opts Options, root string, previous manifest, found map[string]bool, result *Result) {
+	if previous.Root == "" {
+		return
+	}
+	kept := make(map[string]bool, len(found))
+	for rel := range found {
+		kept[MarkdownPath(rel)] = true
+	}
+	for _, rel := range slices.Sorted(maps.Keys(previous.Files)) {
+		if found[rel] || kept[MarkdownPath(rel)] || ctx.Err() != nil {
+			continue
+		}
+		outputPath, err := safepath.Join(opts.OutputDir, MarkdownPath(rel))
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Errorf("%s: %w", rel, err))
+			continue
+		}
+		if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+			result.Failed = append(result.Failed, fmt.Errorf("failed to remove %s: %w", outputPath, err))
+			continue
+		}
+		removeEmptyDirs(filepath.Dir(outputPath), opts.OutputDir)
+		result.Removed = append(result.Removed, rel)
+
+		if opts.Hook != nil {
+			event := hook.Event{Event: hook.EventRemoved, Output: outputPath, Input: filepath.Join(root, filepath.FromSlash(rel))}
+			if err := opts.Hook.Run(ctx, event); err != nil && ctx.Err() == nil {
+				result.HookFailed = append(result.HookFailed, err)
+			}
+		}
+	}
+}
+
+// removeEmptyDirs removes dir and its parents up to, but not including, top while they are empty
+func removeEmptyDirs(dir, top string) {
+	top = filepath.Clean(top)
+	for dir = filepath.Clean(dir); dir != top && strings.HasPrefix(dir, top+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return // Not empty
+		}
+	}
+}
+
+// MarkdownPath returns the path of the exported markdown for a session path relative to the input
+func MarkdownPath(rel string) string {
+	return parser.TrimLogExt(rel) + ".md"
+}
+
+// FindSessions returns the log files, compressed or not, to export and the root their output paths are relative to.
+// Paths excluded by the .cclogignore of an input directory are left out. The walk stops with ctx.Err() when ctx is done.
+func FindSessions(ctx context.Context, inputPath string) ([]string, string, error) {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("input path does not exist: %s", inputPath)
+	}
+	if !info.IsDir() {
+		return []string{inputPath}, filepath.Dir(inputPath), nil
+	}
+
+	ignored, err := ignore.Load(inputPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var sessions []string
+	err = filepath.WalkDir(inputPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(inputPath, path)
+		if relErr == nil && rel != "." && ignored.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && parser.IsLogFile(d.Name()) {
+			sessions = append(sessions, path)
+		}
+		return nil
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, "", ctxErr
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search %s: %w", inputPath, err)
+	}
+
+	sort.Strings(sessions)
+	return sessions, inputPath, nil
+}
+
+// hashSession hashes the session content together with the options that affect its output
+func hashSession(session string, opts Options) (string, error) {
+	data, err := os.ReadFile(session)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", session, err)
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	fmt.Fprintf(h, "\x00filtering=%t format=%+v", opts.EnableFiltering, opts.Format)
+	// A nil Timezone prints like UTC above, so name the resolved location to tell them apart
+	fmt.Fprintf(h, " timezone=%s", opts.Format.Location())
+	// Customized filter rules change the output; the default rules keep earlier hashes valid
+	if rules := filter.Current().Names(); opts.EnableFiltering && !slices.Equal(rules, filter.Default().Names()) {
+		fmt.Fprintf(h, " rules=%s", strings.Join(rules, "\x00"))
+	}
+	if opts.Tools.Active() {
+		fmt.Fprintf(h, " tools=%s", opts.Tools)
+	}
+	if len(opts.Roles) > 0 {
+		fmt.Fprintf(h, " roles=%s", strings.Join(opts.Roles, ","))
+	}
+	if opts.ExtractImages {
+		fmt.Fprint(h, " images=true")
+	}
+	if opts.Redactor != nil {
+		fmt.Fprintf(h, " redact=%s", opts.Redactor.Fingerprint())
+	}
+	if opts.SummaryBlock {
+		fmt.Fprint(h, " summary=true")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sessionEvent describes an exported session to the hook, with its title redacted like the markdown
+func sessionEvent(event, outputPath, session string, log *types.ConversationLog, redactor *redact.Redactor) hook.Event {
+	e := hook.SessionEvent(event, outputPath, session, log)
+	if redactor != nil {
+		e.Title = redactor.Text(e.Title)
+	}
+	return e
+}
+
+// exportSession converts a single session to markdown, writes it to outputPath and returns the parsed log
+func exportSession(ctx context.Context, session, outputPath string, opts Options) (*types.ConversationLog, error) {
+	log, err := parser.ParseJSONLFileContext(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", session, err)
+	}
+
+	// Images are extracted before filtering so that image-only messages are kept
+	var images []assets.Image
+	if opts.ExtractImages {
+		images = assets.Extract(log)
+	}
+	// The summary lists the files of every tool call, including the calls dropped by the filters
+	var summaryBlock string
+	if opts.SummaryBlock {
+		s := summary.Extract(log)
+		if opts.Redactor != nil {
+			s = s.Map(opts.Redactor.Text)
+		}
+		summaryBlock = s.Markdown()
+	}
+	filteredLog := formatter.FilterConversationLog(log, opts.EnableFiltering)
+	filteredLog = filter.FilterRoles(opts.Tools.Apply(filteredLog), opts.Roles)
+	if opts.Redactor != nil {
+		opts.Redactor.Log(filteredLog)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+	if err := writeMarkdown(outputPath, summaryBlock, filteredLog, opts.Format); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return log, assets.Write(filepath.Dir(outputPath), images)
+}
+
+// writeMarkdown writes header followed by the markdown of log to path as it is formatted
+func writeMarkdown(path, header string, log *types.ConversationLog, format formatter.FormatOptions) error {
+	return outfile.Create(path, func(w io.Writer) error {
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+		return formatter.WriteConversationMarkdown(w, log, format)
+	})
+}
+
+// loadManifest reads the manifest at path. A missing file yields an empty manifest.
+func loadManifest(path string) (manifest, error) {
+	m := manifest{Files: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to parse export manifest %s: %w", path, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+	return m, nil
+}
+
+// saveManifest writes the manifest to path
+func saveManifest(path string, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write export manifest: %w", err)
+	}
+	return nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,316 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+const sessionContent = `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"hi there"}]},"uuid":"u2","timestamp":"2025-07-06T05:01:30.618Z"}
+`
+
+func writeSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+}
+
+func TestRunSkipsUnchangedSessions(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "project-a", "one.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(input, "project-b", "two.jsonl"), sessionContent)
+
+	opts := Options{InputPath: input, OutputDir: output, EnableFiltering: true}
+
+	result, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("First export failed: %v", err)
+	}
+	if got := result.Summary(); got != "2 new, 0 updated, 0 skipped" {
+		t.Errorf("First export summary = %q", got)
+	}
+	exported, err := os.ReadFile(filepath.Join(output, "project-a", "one.md"))
+	if err != nil {
+		t.Fatalf("Expected exported markdown: %v", err)
+	}
+	if !strings.Contains(string(exported), "hi there") {
+		t.Errorf("Exported markdown is missing content:\n%s", exported)
+	}
+
+	result, err = Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Second export failed: %v", err)
+	}
+	if got := result.Summary(); got != "0 new, 0 updated, 2 skipped" {
+		t.Errorf("Unchanged export summary = %q", got)
+	}
+
+	// Change one session and add another
+	writeSession(t, filepath.Join(input, "project-a", "one.jsonl"), sessionContent+sessionContent)
+	writeSession(t, filepath.Join(input, "project-b", "three.jsonl"), sessionContent)
+
+	result, err = Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Third export failed: %v", err)
+	}
+	if got := result.Summary(); got != "1 new, 1 updated, 1 skipped" {
+		t.Errorf("Changed export summary = %q", got)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "project-a/one.jsonl" {
+		t.Errorf("Expected project-a/one.jsonl to be updated, got %v", result.Updated)
+	}
+}
+
+func TestRunReexportsWhenNeeded(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "one.jsonl"), sessionContent)
+
+	opts := Options{InputPath: input, OutputDir: output, EnableFiltering: true}
+	if _, err := Run(context.Background(), opts); err != nil {
+		t.Fatalf("Initial export failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"force", func(o *Options) { o.Force = true }},
+		{"format options changed", func(o *Options) { o.Format = formatter.FormatOptions{ShowUUID: true} }},
+		{"summary block added", func(o *Options) { o.SummaryBlock = true }},
+		{"output deleted", func(o *Options) { os.Remove(filepath.Join(output, "one.md")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := opts
+			tt.modify(&o)
+			result, err := Run(context.Background(), o)
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			if len(result.Updated) != 1 {
+				t.Errorf("Expected session to be re-exported, got %s", result.Summary())
+			}
+		})
+	}
+}
+
+func TestRunReportsFailures(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "good.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(input, "bad.jsonl"), "{not json\n")
+
+	result, err := Run(context.Background(), Options{InputPath: input, OutputDir: output, EnableFiltering: true})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if got := result.Summary(); got != "1 new, 0 updated, 0 skipped, 1 failed" {
+		t.Errorf("Summary = %q", got)
+	}
+
+	// The failed session is retried on the next run
+	writeSession(t, filepath.Join(input, "bad.jsonl"), sessionContent)
+	result, err = Run(context.Background(), Options{InputPath: input, OutputDir: output, EnableFiltering: true})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(result.New) != 1 || result.New[0] != "bad.jsonl" {
+		t.Errorf("Expected fixed session to be exported, got %s", result.Summary())
+	}
+}
+
+func TestFindSessionsRespectsIgnoreFile(t *testing.T) {
+	input := t.TempDir()
+	writeSession(t, filepath.Join(input, "project", "one.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(input, "archive", "old.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(input, ".cclogignore"), "archive/\n")
+
+	sessions, _, err := FindSessions(context.Background(), input)
+	if err != nil {
+		t.Fatalf("FindSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || filepath.Base(sessions[0]) != "one.jsonl" {
+		t.Errorf("Expected only project/one.jsonl, got %v", sessions)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	input := t.TempDir()
+	output := filepath.Join(t.TempDir(), "out")
+	writeSession(t, filepath.Join(input, "project", "one.jsonl"), sessionContent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, Options{InputPath: input, OutputDir: output}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the export to be cancelled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project", "one.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing exported after cancellation, got %v", err)
+	}
+}
+
+func TestRunSummaryBlock(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "one.jsonl"), sessionContent)
+
+	if _, err := Run(context.Background(), Options{InputPath: input, OutputDir: output, EnableFiltering: true, SummaryBlock: true}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	exported, err := os.ReadFile(filepath.Join(output, "one.md"))
+	if err != nil {
+		t.Fatalf("Expected exported markdown: %v", err)
+	}
+	if want := "## Summary\n\n- **Asked:** hello\n- **Outcome:** hi there\n\n# Conversation Log"; !strings.HasPrefix(string(exported), want) {
+		t.Errorf("Expected exported markdown to start with %q, got:\n%s", want, exported)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook command is written for sh")
+	}
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "one.jsonl"), sessionContent)
+	record := filepath.Join(t.TempDir(), "hooks.txt")
+
+	opts := Options{InputPath: input, OutputDir: output, EnableFiltering: true,
+		Hook: &hook.Hook{Command: `echo "$CCLOG_EVENT $CCLOG_OUTPUT $CCLOG_TITLE" >> "` + record + `"`}}
+	for range 2 {
+		if _, err := Run(context.Background(), opts); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	}
+	writeSession(t, filepath.Join(input, "one.jsonl"), sessionContent+sessionContent)
+	if _, err := Run(context.Background(), opts); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("Hook did not run: %v", err)
+	}
+	md := filepath.Join(output, "one.md")
+	// The unchanged session of the second run is skipped without running the hook
+	want := "new " + md + " hello\nupdated " + md + " hello\n"
+	if string(got) != want {
+		t.Errorf("Hook runs = %q, want %q", got, want)
+	}
+
+	opts.Force = true
+	opts.Hook = &hook.Hook{Command: "exit 1"}
+	result, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if got := result.Summary(); got != "0 new, 1 updated, 0 skipped, hook failed for 1" {
+		t.Errorf("Summary = %q", got)
+	}
+}
+
+func TestRunPrune(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "project-a", "one.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(input, "project-b", "two.jsonl"), sessionContent)
+	opts := Options{InputPath: input, OutputDir: output, EnableFiltering: true, Prune: true}
+	if _, err := Run(context.Background(), opts); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	// A file added by hand is not the export's to delete
+	notes := filepath.Join(output, "project-a", "notes.md")
+	writeSession(t, notes, "my notes")
+
+	for _, project := range []string{"project-a", "project-b"} {
+		if err := os.RemoveAll(filepath.Join(input, project)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if got := result.Summary(); got != "0 new, 0 updated, 0 skipped, 2 removed" {
+		t.Errorf("Summary = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project-a", "one.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected the markdown of the removed session to be deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project-b")); !os.IsNotExist(err) {
+		t.Errorf("Expected the emptied directory to be deleted: %v", err)
+	}
+	if _, err := os.Stat(notes); err != nil {
+		t.Errorf("Expected the file added by hand to be kept: %v", err)
+	}
+
+	// Removed sessions leave the manifest, so they are only removed once
+	result, err = Run(context.Background(), opts)
+	if err != nil || len(result.Removed) != 0 {
+		t.Errorf("Third sync removed %v, %v", result.Removed, err)
+	}
+}
+
+func TestRunPruneKeepsCompressedSession(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	session := filepath.Join(input, "project", "one.jsonl")
+	writeSession(t, session, sessionContent)
+	opts := Options{InputPath: input, OutputDir: output, EnableFiltering: true, Prune: true}
+	if _, err := Run(context.Background(), opts); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	// one.jsonl.gz maps to the one.md of one.jsonl, which must not be deleted as the file of a removed session
+	if _, err := parser.CompressLog(session, parser.ExtGzip); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("Removed = %v, want none", result.Removed)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project", "one.md")); err != nil {
+		t.Errorf("Expected the markdown of the compressed session to be kept: %v", err)
+	}
+}
+
+func TestRunPruneRefusesAnotherInput(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(first, "project", "one.jsonl"), sessionContent)
+	writeSession(t, filepath.Join(second, "other", "two.jsonl"), sessionContent)
+	if _, err := Run(context.Background(), Options{InputPath: first, OutputDir: output, Prune: true}); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	_, err := Run(context.Background(), Options{InputPath: second, OutputDir: output, Prune: true})
+	if err == nil || !strings.Contains(err.Error(), "mirrors") {
+		t.Fatalf("Expected syncing another input to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "project", "one.md")); err != nil {
+		t.Errorf("Expected the files mirrored from the first input to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "other", "two.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be exported from the second input: %v", err)
+	}
+}
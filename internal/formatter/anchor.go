@@ -0,0 +1,64 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// messageAnchorLength is the number of UUID characters in a message anchor, enough to be unique within a session
+const messageAnchorLength = 8
+
+// MessageAnchor returns the id of the HTML anchor before a message, e.g. "msg-1a2b3c4d" for UUID
+// "1a2b3c4d-...". It only depends on the UUID, so links to it keep working when the session is
+// converted again with other options. Returns "" for messages without a UUID.
+func MessageAnchor(uuid string) string {
+	if uuid == "" {
+		return ""
+	}
+	if len(uuid) > messageAnchorLength {
+		uuid = uuid[:messageAnchorLength]
+	}
+	return "msg-" + uuid
+}
+
+// messageAnchorTag returns the HTML anchor placed before a message, or "" for messages without a UUID
+func messageAnchorTag(uuid string) string {
+	id := MessageAnchor(uuid)
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf("<a id=\"%s\"></a>\n\n", id)
+}
+
+// FocusMessages returns a copy of log with the message whose UUID starts with uuid and up to context
+// messages before and after it, in chronological order. A UUID prefix matching several messages is an error.
+func FocusMessages(log *types.ConversationLog, uuid string, context int) (*types.ConversationLog, error) {
+	messages := SortMessagesChronologically(log.Messages)
+	found := -1
+	var prefixed []int
+	for i, msg := range messages {
+		if msg.UUID == uuid {
+			found = i
+			break
+		}
+		if msg.UUID != "" && strings.HasPrefix(msg.UUID, uuid) {
+			prefixed = append(prefixed, i)
+		}
+	}
+	if found < 0 {
+		switch len(prefixed) {
+		case 0:
+			return nil, fmt.Errorf("no message with UUID %q in %s", uuid, log.FilePath)
+		case 1:
+			found = prefixed[0]
+		default:
+			return nil, fmt.Errorf("message UUID prefix %q is ambiguous (%d messages)", uuid, len(prefixed))
+		}
+	}
+
+	start := max(found-context, 0)
+	end := min(found+context+1, len(messages))
+	return &types.ConversationLog{Messages: messages[start:end], FilePath: log.FilePath}, nil
+}
@@ -0,0 +1,65 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func anchorTestLog() *types.ConversationLog {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	var messages []types.Message
+	for i := 9; i >= 0; i-- { // Out of order, to check that context follows the timestamps
+		messages = append(messages, types.Message{
+			Type:      "user",
+			UUID:      fmt.Sprintf("%d0000000-aaaa-bbbb-cccc-%012d", i, i),
+			Timestamp: ts.Add(time.Duration(i) * time.Minute),
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent(fmt.Sprintf("Message %d", i))},
+		})
+	}
+	return &types.ConversationLog{FilePath: "session.jsonl", Messages: messages}
+}
+
+func TestMessageAnchor(t *testing.T) {
+	if got := MessageAnchor("1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d"); got != "msg-1a2b3c4d" {
+		t.Errorf("Unexpected anchor %q", got)
+	}
+	if got := MessageAnchor(""); got != "" {
+		t.Errorf("Expected no anchor without a UUID, got %q", got)
+	}
+
+	got := FormatConversationToMarkdown(anchorTestLog(), FormatOptions{Timezone: time.UTC, MessageAnchors: true})
+	if !strings.Contains(got, "<a id=\"msg-30000000\"></a>\n\n### User") {
+		t.Errorf("Expected an anchor before each message, got:\n%s", got)
+	}
+	if plain := FormatConversationToMarkdown(anchorTestLog(), FormatOptions{Timezone: time.UTC}); strings.Contains(plain, "<a id") {
+		t.Errorf("Expected no anchors by default, got:\n%s", plain)
+	}
+}
+
+func TestFocusMessages(t *testing.T) {
+	focused, err := FocusMessages(anchorTestLog(), "50000000", 2)
+	if err != nil {
+		t.Fatalf("FocusMessages failed: %v", err)
+	}
+	var got []string
+	for _, msg := range focused.Messages {
+		got = append(got, types.ExtractTextContent(msg.Message))
+	}
+	if want := "Message 3,Message 4,Message 5,Message 6,Message 7"; strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+
+	if focused, err := FocusMessages(anchorTestLog(), "00000000-aaaa-bbbb-cccc-000000000000", 3); err != nil || len(focused.Messages) != 4 {
+		t.Errorf("Expected the first message and 3 after it, got %v, %v", focused, err)
+	}
+	if _, err := FocusMessages(anchorTestLog(), "ffff", 1); err == nil {
+		t.Error("Expected error for an unknown UUID")
+	}
+	if _, err := FocusMessages(anchorTestLog(), "", 1); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected error for an ambiguous prefix, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// collapseContent collapses message content longer than threshold lines.
+// The first threshold lines stay visible; the rest is wrapped in a <details> block,
+// or replaced by a short marker when truncate is set (for renderers without HTML support).
+func collapseContent(content string, threshold int, truncate bool) string {
+	if threshold <= 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	if len(lines) <= threshold {
+		return content
+	}
+
+	visible := lines[:threshold]
+	hidden := lines[threshold:]
+
+	// Close a code fence left open in the visible part and reopen it in the hidden part
+	if fence, open := openCodeFence(visible); open {
+		visible = append(append([]string{}, visible...), "```")
+		hidden = append([]string{fence}, hidden...)
+	}
+
+	if truncate {
+		return fmt.Sprintf("%s\n\n*[... %d more lines collapsed]*", strings.Join(visible, "\n"), len(lines)-threshold)
+	}
+
+	return fmt.Sprintf("%s\n\n<details>\n<summary>Show %d more lines</summary>\n\n%s\n\n</details>",
+		strings.Join(visible, "\n"), len(lines)-threshold, strings.Join(hidden, "\n"))
+}
+
+// openCodeFence reports whether lines end inside a fenced code block and returns its opening line
+func openCodeFence(lines []string) (string, bool) {
+	var opener string
+	open := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if open {
+				open = false
+				opener = ""
+			} else {
+				open = true
+				opener = strings.TrimSpace(line)
+			}
+		}
+	}
+	return opener, open
+}
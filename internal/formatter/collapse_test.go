@@ -0,0 +1,95 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestCollapseContent(t *testing.T) {
+	long := "line1\nline2\nline3\nline4\nline5"
+
+	tests := []struct {
+		name        string
+		content     string
+		threshold   int
+		truncate    bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:      "disabled threshold leaves content unchanged",
+			content:   long,
+			threshold: 0,
+			contains:  []string{long},
+		},
+		{
+			name:        "short content is not collapsed",
+			content:     "line1\nline2",
+			threshold:   3,
+			contains:    []string{"line1\nline2"},
+			notContains: []string{"<details>"},
+		},
+		{
+			name:      "long content is wrapped in details",
+			content:   long,
+			threshold: 2,
+			contains: []string{
+				"line1\nline2\n\n<details>",
+				"<summary>Show 3 more lines</summary>",
+				"line3\nline4\nline5\n\n</details>",
+			},
+		},
+		{
+			name:        "truncate drops collapsed lines",
+			content:     long,
+			threshold:   2,
+			truncate:    true,
+			contains:    []string{"line1\nline2", "*[... 3 more lines collapsed]*"},
+			notContains: []string{"line3", "<details>"},
+		},
+		{
+			name:      "open code fence is closed and reopened",
+			content:   "intro\n```go\nfmt.Println(1)\nfmt.Println(2)\n```",
+			threshold: 3,
+			contains:  []string{"```go\nfmt.Println(1)\n```\n\n<details>", "```go\nfmt.Println(2)\n```"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := collapseContent(tt.content, tt.threshold, tt.truncate)
+			for _, want := range tt.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("Expected result to contain %q, got:\n%s", want, result)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("Expected result not to contain %q, got:\n%s", unwanted, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatConversationToMarkdownWithCollapse(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29.618Z")
+	log := &types.ConversationLog{
+		FilePath: "/test/path/sample.jsonl",
+		Messages: []types.Message{
+			{
+				Type:      "assistant",
+				Timestamp: timestamp,
+				Message:   &types.MessageBody{Role: "assistant", Content: types.TextContent("a\nb\nc\nd")},
+			},
+		},
+	}
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{CollapseThreshold: 2})
+	if !strings.Contains(markdown, "<summary>Show 2 more lines</summary>") {
+		t.Errorf("Expected collapsed message, got:\n%s", markdown)
+	}
+}
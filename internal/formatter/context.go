@@ -0,0 +1,127 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// FormatMarkdown is the default output format
+	FormatMarkdown = "markdown"
+	// FormatContext is a compact transcript for pasting into a language model prompt
+	FormatContext = "context"
+)
+
+// contextSeparator separates conversations in a context transcript
+const contextSeparator = "---"
+
+// contextTurn is a prompt or reply in a context transcript
+type contextTurn struct {
+	prefix string // "H:" or "A:", or "" for a separator between conversations
+	text   string
+}
+
+// String renders the turn as a paragraph of the transcript
+func (t contextTurn) String() string {
+	if t.prefix == "" {
+		return contextSeparator
+	}
+	return t.prefix + " " + t.text
+}
+
+// FormatConversationContext renders a conversation as a compact transcript for pasting into a
+// language model prompt: prompts as "H: ..." and replies as "A: ...", without headers, timestamps
+// or tool calls. With maxTokens > 0, the oldest turns are left out until the estimated size fits.
+func FormatConversationContext(log *types.ConversationLog, maxTokens int) string {
+	return FormatMultipleConversationsContext([]*types.ConversationLog{log}, maxTokens)
+}
+
+// FormatMultipleConversationsContext renders conversations as one context transcript, separated by "---".
+// maxTokens applies to the whole transcript, so the oldest conversations are trimmed first.
+func FormatMultipleConversationsContext(logs []*types.ConversationLog, maxTokens int) string {
+	var turns []contextTurn
+	for i, log := range logs {
+		if i > 0 && len(turns) > 0 {
+			turns = append(turns, contextTurn{})
+		}
+		turns = append(turns, contextTurns(log)...)
+	}
+
+	omitted := 0
+	if maxTokens > 0 {
+		turns, omitted = trimContextTurns(turns, maxTokens)
+	}
+
+	parts := make([]string, 0, len(turns)+1)
+	if omitted > 0 {
+		parts = append(parts, omittedNote(omitted))
+	}
+	for _, turn := range turns {
+		parts = append(parts, turn.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// contextTurns returns the prompts and replies of a conversation in order.
+// Consecutive assistant messages, such as text split around tool calls, form one reply.
+func contextTurns(log *types.ConversationLog) []contextTurn {
+	var turns []contextTurn
+	for _, msg := range SortMessagesChronologically(log.Messages) {
+		var prefix string
+		switch msg.Type {
+		case "user":
+			prefix = "H:"
+		case "assistant":
+			prefix = "A:"
+		default:
+			continue
+		}
+		text := strings.TrimSpace(types.ExtractTextContent(msg.Message))
+		if text == "" {
+			continue // Tool calls and results carry no text
+		}
+
+		if n := len(turns); n > 0 && turns[n-1].prefix == prefix {
+			turns[n-1].text += "\n\n" + text
+			continue
+		}
+		turns = append(turns, contextTurn{prefix: prefix, text: text})
+	}
+	return turns
+}
+
+// trimContextTurns drops turns from the start until the transcript fits in maxTokens, always keeping
+// the last turn. It returns the remaining turns and the number of prompts and replies dropped.
+func trimContextTurns(turns []contextTurn, maxTokens int) ([]contextTurn, int) {
+	total := 0
+	for _, turn := range turns {
+		total += EstimateTokens(turn.String())
+	}
+
+	omitted := 0
+	for len(turns) > 1 && total+EstimateTokens(omittedNote(omitted)) > maxTokens {
+		total -= EstimateTokens(turns[0].String())
+		if turns[0].prefix != "" {
+			omitted++
+		}
+		turns = turns[1:]
+	}
+	// A transcript never starts with a separator
+	for len(turns) > 0 && turns[0].prefix == "" {
+		turns = turns[1:]
+	}
+	return turns, omitted
+}
+
+// omittedNote tells the reader that earlier turns were left out
+func omittedNote(omitted int) string {
+	if omitted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%d earlier messages omitted]", omitted)
+}
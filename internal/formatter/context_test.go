@@ -0,0 +1,79 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func contextTestLog() *types.ConversationLog {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	msg := func(offset int, msgType string, content types.Content) types.Message {
+		return types.Message{
+			Type:      msgType,
+			Timestamp: ts.Add(time.Duration(offset) * time.Second),
+			Message:   &types.MessageBody{Role: msgType, Content: content},
+		}
+	}
+	return &types.ConversationLog{
+		FilePath: "session.jsonl",
+		Messages: []types.Message{
+			msg(0, "user", types.TextContent("Fix the failing test")),
+			msg(1, "assistant", types.BlockContent(
+				types.TextBlock{Text: "Let me run it."},
+				types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]interface{}{"command": "go test"}},
+			)),
+			msg(2, "user", types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent("FAIL")})),
+			msg(3, "assistant", types.TextContent("The test is fixed now.")),
+			msg(4, "user", types.TextContent("Thanks")),
+		},
+	}
+}
+
+func TestFormatConversationContext(t *testing.T) {
+	result := FormatConversationContext(contextTestLog(), 0)
+	want := "H: Fix the failing test\n\nA: Let me run it.\n\nThe test is fixed now.\n\nH: Thanks\n"
+	if result != want {
+		t.Errorf("Unexpected transcript:\n%q\nwant:\n%q", result, want)
+	}
+
+	multiple := FormatMultipleConversationsContext([]*types.ConversationLog{contextTestLog(), contextTestLog()}, 0)
+	if strings.Count(multiple, "\n\n---\n\n") != 1 {
+		t.Errorf("Expected one separator between conversations, got:\n%s", multiple)
+	}
+}
+
+func TestFormatConversationContextMaxTokens(t *testing.T) {
+	result := FormatConversationContext(contextTestLog(), 12)
+	if !strings.HasPrefix(result, "[2 earlier messages omitted]\n\nH: Thanks\n") {
+		t.Errorf("Expected the oldest turns to be left out, got:\n%s", result)
+	}
+	if EstimateTokens(result) > 12 {
+		t.Errorf("Expected about 12 tokens at most, got %d", EstimateTokens(result))
+	}
+
+	// The last turn is kept even if it does not fit
+	if result := FormatConversationContext(contextTestLog(), 1); !strings.HasSuffix(result, "H: Thanks\n") {
+		t.Errorf("Expected the last turn to be kept, got:\n%s", result)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"日本語", 3},
+		{"go 言語", 3},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
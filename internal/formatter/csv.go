@@ -0,0 +1,80 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// FormatCSV is one comma-separated row of metadata per message, for spreadsheets and data analysis
+	FormatCSV = "csv"
+	// FormatTSV is FormatCSV separated by tabs
+	FormatTSV = "tsv"
+)
+
+// tableColumns are the columns of FormatCSV and FormatTSV output
+var tableColumns = []string{"session", "timestamp", "role", "type", "tool", "content_length", "uuid"}
+
+// FormatConversationsTable renders one row of metadata per message of logs, after a header row:
+// the session file, the timestamp in loc (RFC 3339), the role (user, assistant or tool), the message type,
+// the tools called or answered, the number of characters of text and tool output, and the UUID.
+// comma separates the fields, e.g. ',' for CSV or '\t' for TSV.
+func FormatConversationsTable(logs []*types.ConversationLog, comma rune, loc *time.Location) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Comma = comma
+	w.Write(tableColumns)
+
+	for _, log := range logs {
+		session := filepath.Base(log.FilePath)
+		messages := SortMessagesChronologically(log.Messages)
+		toolNames := collectToolNames(messages)
+		for _, msg := range messages {
+			var timestamp string
+			if !msg.Timestamp.IsZero() {
+				timestamp = msg.Timestamp.In(loc).Format(time.RFC3339)
+			}
+			tools, length := messageTools(msg, toolNames)
+			w.Write([]string{
+				session,
+				timestamp,
+				filter.MessageRole(msg),
+				msg.Type,
+				strings.Join(tools, " "),
+				strconv.Itoa(length),
+				msg.UUID,
+			})
+		}
+	}
+
+	w.Flush()
+	return sb.String()
+}
+
+// messageTools returns the names of the tools a message calls or answers, and the number of characters
+// of its text and tool output
+func messageTools(msg types.Message, toolNames map[string]string) ([]string, int) {
+	length := utf8.RuneCountInString(types.ExtractTextContent(msg.Message))
+	var tools []string
+	for _, block := range msg.Message.Blocks() {
+		switch b := block.(type) {
+		case types.ToolUseBlock:
+			if b.Name != "" {
+				tools = append(tools, b.Name)
+			}
+		case types.ToolResultBlock:
+			if name := toolNames[b.ToolUseID]; name != "" {
+				tools = append(tools, name)
+			}
+			length += utf8.RuneCountInString(b.Content.PlainText())
+		}
+	}
+	return tools, length
+}
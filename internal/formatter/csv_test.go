@@ -0,0 +1,49 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestFormatConversationsTable(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	log := &types.ConversationLog{
+		FilePath: "/logs/session.jsonl",
+		Messages: []types.Message{
+			{Type: "user", UUID: "u1", Timestamp: ts, Message: &types.MessageBody{Role: "user", Content: types.TextContent("Hello, \"world\"")}},
+			{Type: "assistant", UUID: "a1", Timestamp: ts.Add(time.Second), Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(
+				types.TextBlock{Text: "Running"},
+				types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]interface{}{"command": "ls"}},
+			)}},
+			{Type: "user", UUID: "r1", Timestamp: ts.Add(2 * time.Second), Message: &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent("a.go\nb.go")})}},
+		},
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(FormatConversationsTable([]*types.ConversationLog{log}, ',', time.UTC))).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"session", "timestamp", "role", "type", "tool", "content_length", "uuid"},
+		{"session.jsonl", "2025-07-06T05:00:00Z", "user", "user", "", "14", "u1"},
+		{"session.jsonl", "2025-07-06T05:00:01Z", "assistant", "assistant", "Bash", "7", "a1"},
+		{"session.jsonl", "2025-07-06T05:00:02Z", "tool", "user", "Bash", "9", "r1"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("Row %d: got %v, want %v", i, rows[i], want[i])
+		}
+	}
+
+	tsv := FormatConversationsTable([]*types.ConversationLog{log}, '\t', time.UTC)
+	if !strings.HasPrefix(tsv, "session\ttimestamp\trole\t") {
+		t.Errorf("Expected tab-separated output, got:\n%s", tsv)
+	}
+}
@@ -20,10 +20,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "user",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Hello, how are you?",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello, how are you?")},
 			},
 			expected: true,
 		},
@@ -32,15 +29,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "assistant",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role": "assistant",
-					"content": []interface{}{
-						map[string]interface{}{
-							"type": "text",
-							"text": "I'm doing well, thank you!",
-						},
-					},
-				},
+				Message:   &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.TextBlock{Text: "I'm doing well, thank you!"})},
 			},
 			expected: true,
 		},
@@ -49,10 +38,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "system",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "system",
-					"content": "System reminder",
-				},
+				Message:   &types.MessageBody{Role: "system", Content: types.TextContent("System reminder")},
 			},
 			expected: false,
 		},
@@ -61,7 +47,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "assistant",
 				Timestamp: timestamp,
-				Message:   map[string]interface{}{},
+				Message:   &types.MessageBody{},
 			},
 			expected: false,
 		},
@@ -70,10 +56,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "assistant",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "assistant",
-					"content": "API Error: Request was aborted.",
-				},
+				Message:   &types.MessageBody{Role: "assistant", Content: types.TextContent("API Error: Request was aborted.")},
 			},
 			expected: false,
 		},
@@ -82,10 +65,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "user",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "[Request interrupted by user]",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("[Request interrupted by user]")},
 			},
 			expected: false,
 		},
@@ -94,10 +74,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "user",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "<command-name>/add-dir</command-name>",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("<command-name>/add-dir</command-name>")},
 			},
 			expected: false,
 		},
@@ -106,10 +83,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "user",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "<bash-input>git status</bash-input>",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("<bash-input>git status</bash-input>")},
 			},
 			expected: false,
 		},
@@ -119,10 +93,7 @@ func TestIsContentfulMessage(t *testing.T) {
 				Type:      "user",
 				Timestamp: timestamp,
 				IsMeta:    true,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Some meta content",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Some meta content")},
 			},
 			expected: false,
 		},
@@ -131,9 +102,7 @@ func TestIsContentfulMessage(t *testing.T) {
 			message: types.Message{
 				Type:      "summary",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"summary": "Test conversation summary",
-				},
+				Message:   &types.MessageBody{Summary: "Test conversation summary"},
 			},
 			expected: false,
 		},
@@ -166,26 +135,17 @@ func TestFilterMessages(t *testing.T) {
 		{
 			Type:      "user",
 			Timestamp: timestamp,
-			Message: map[string]interface{}{
-				"role":    "user",
-				"content": "Hello",
-			},
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello")},
 		},
 		{
 			Type:      "system",
 			Timestamp: timestamp,
-			Message: map[string]interface{}{
-				"role":    "system",
-				"content": "System message",
-			},
+			Message:   &types.MessageBody{Role: "system", Content: types.TextContent("System message")},
 		},
 		{
 			Type:      "assistant",
 			Timestamp: timestamp,
-			Message: map[string]interface{}{
-				"role":    "assistant",
-				"content": "Response",
-			},
+			Message:   &types.MessageBody{Role: "assistant", Content: types.TextContent("Response")},
 		},
 		{
 			Type:      "assistant",
@@ -217,18 +177,12 @@ func TestFilterConversationLog(t *testing.T) {
 			{
 				Type:      "user",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Test message",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Test message")},
 			},
 			{
 				Type:      "system",
 				Timestamp: timestamp,
-				Message: map[string]interface{}{
-					"role":    "system",
-					"content": "System message",
-				},
+				Message:   &types.MessageBody{Role: "system", Content: types.TextContent("System message")},
 			},
 		},
 	}
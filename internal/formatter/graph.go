@@ -0,0 +1,187 @@
+package formatter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// GraphFormatDot renders the message tree as a Graphviz digraph
+	GraphFormatDot = "dot"
+	// GraphFormatMermaid renders the message tree as a Mermaid flowchart
+	GraphFormatMermaid = "mermaid"
+)
+
+// graphLabelMaxRunes caps the message excerpt shown in each node
+const graphLabelMaxRunes = 40
+
+// graphNode is a message in the parentUuid tree
+type graphNode struct {
+	id        string // Identifier in the rendered graph
+	label     string
+	sidechain bool
+}
+
+// graphEdge links a parent message to its child
+type graphEdge struct {
+	from, to string
+}
+
+// conversationGraph is the message tree of one conversation
+type conversationGraph struct {
+	name  string
+	nodes []graphNode
+	edges []graphEdge
+}
+
+// FormatConversationGraph renders the parentUuid message tree of a conversation, including sidechains,
+// in the given format ("dot" or "mermaid")
+func FormatConversationGraph(log *types.ConversationLog, format string) (string, error) {
+	return FormatMultipleConversationsGraph([]*types.ConversationLog{log}, format)
+}
+
+// FormatMultipleConversationsGraph renders the message trees of several conversations in one graph,
+// each in its own cluster
+func FormatMultipleConversationsGraph(logs []*types.ConversationLog, format string) (string, error) {
+	nextID := 0
+	graphs := make([]conversationGraph, len(logs))
+	for i, log := range logs {
+		graphs[i] = buildConversationGraph(log, &nextID)
+	}
+
+	switch format {
+	case GraphFormatDot, "":
+		return renderDotGraph(graphs, len(logs) > 1), nil
+	case GraphFormatMermaid:
+		return renderMermaidGraph(graphs, len(logs) > 1), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (available: %s, %s)", format, GraphFormatDot, GraphFormatMermaid)
+	}
+}
+
+// buildConversationGraph collects the nodes and parent edges of a conversation.
+// nextID numbers nodes across conversations so identifiers stay unique in a combined graph.
+func buildConversationGraph(log *types.ConversationLog, nextID *int) conversationGraph {
+	graph := conversationGraph{name: filepath.Base(log.FilePath)}
+	ids := make(map[string]string)
+
+	for _, msg := range log.Messages {
+		if msg.UUID == "" {
+			continue // Summaries and other entries outside the tree
+		}
+		*nextID++
+		id := fmt.Sprintf("m%d", *nextID)
+		ids[msg.UUID] = id
+		graph.nodes = append(graph.nodes, graphNode{id: id, label: graphLabel(msg), sidechain: msg.IsSidechain})
+	}
+
+	for _, msg := range log.Messages {
+		if msg.UUID == "" || msg.ParentUUID == nil {
+			continue
+		}
+		// Parents outside the file (e.g. in a resumed session) leave the message as a root
+		if parent, ok := ids[*msg.ParentUUID]; ok {
+			graph.edges = append(graph.edges, graphEdge{from: parent, to: ids[msg.UUID]})
+		}
+	}
+	return graph
+}
+
+// graphLabel describes a message by its type and the start of its text,
+// or by the tools it uses when it has no text
+func graphLabel(msg types.Message) string {
+	content := singleLine(types.ExtractTextContent(msg.Message))
+	if content == "" {
+		var tools []string
+		for _, block := range msg.Message.Blocks() {
+			switch b := block.(type) {
+			case types.ToolUseBlock:
+				tools = append(tools, b.Name)
+			case types.ToolResultBlock:
+				tools = append(tools, "result")
+			}
+		}
+		if len(tools) > 0 {
+			content = "[" + strings.Join(tools, ", ") + "]"
+		}
+	}
+	return msg.Type + ": " + truncateRunes(content, graphLabelMaxRunes)
+}
+
+// renderDotGraph renders conversation graphs as a Graphviz digraph. Sidechain messages are dashed.
+func renderDotGraph(graphs []conversationGraph, clustered bool) string {
+	var sb strings.Builder
+	sb.WriteString("digraph conversation {\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for i, graph := range graphs {
+		indent := "  "
+		if clustered {
+			sb.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i+1))
+			sb.WriteString(fmt.Sprintf("    label=%s;\n", dotQuote(graph.name)))
+			indent = "    "
+		}
+		for _, node := range graph.nodes {
+			style := ""
+			if node.sidechain {
+				style = ", style=dashed"
+			}
+			sb.WriteString(fmt.Sprintf("%s%s [label=%s%s];\n", indent, node.id, dotQuote(node.label), style))
+		}
+		for _, edge := range graph.edges {
+			sb.WriteString(fmt.Sprintf("%s%s -> %s;\n", indent, edge.from, edge.to))
+		}
+		if clustered {
+			sb.WriteString("  }\n")
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// renderMermaidGraph renders conversation graphs as a Mermaid flowchart. Sidechain messages use the sidechain class.
+func renderMermaidGraph(graphs []conversationGraph, clustered bool) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+
+	var sidechains []string
+	for i, graph := range graphs {
+		indent := "  "
+		if clustered {
+			sb.WriteString(fmt.Sprintf("  subgraph conversation_%d [%s]\n", i+1, mermaidQuote(graph.name)))
+			indent = "    "
+		}
+		for _, node := range graph.nodes {
+			sb.WriteString(fmt.Sprintf("%s%s[%s]\n", indent, node.id, mermaidQuote(node.label)))
+			if node.sidechain {
+				sidechains = append(sidechains, node.id)
+			}
+		}
+		for _, edge := range graph.edges {
+			sb.WriteString(fmt.Sprintf("%s%s --> %s\n", indent, edge.from, edge.to))
+		}
+		if clustered {
+			sb.WriteString("  end\n")
+		}
+	}
+
+	if len(sidechains) > 0 {
+		sb.WriteString("  classDef sidechain stroke-dasharray: 5 5\n")
+		sb.WriteString(fmt.Sprintf("  class %s sidechain\n", strings.Join(sidechains, ",")))
+	}
+	return sb.String()
+}
+
+// dotQuote returns text as a Graphviz quoted string
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// mermaidQuote returns text as a Mermaid quoted label
+func mermaidQuote(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, "#quot;") + `"`
+}
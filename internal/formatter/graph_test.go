@@ -0,0 +1,87 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func graphTestLog() *types.ConversationLog {
+	parent := func(uuid string) *string { return &uuid }
+	text := func(role, content string) *types.MessageBody {
+		return &types.MessageBody{Role: role, Content: types.TextContent(content)}
+	}
+	return &types.ConversationLog{
+		FilePath: "/logs/session.jsonl",
+		Messages: []types.Message{
+			{Type: "summary"},
+			{Type: "user", UUID: "u1", Message: text("user", `Fix the "flaky" test`)},
+			{Type: "assistant", UUID: "a1", ParentUUID: parent("u1"), Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{Name: "Task"})}},
+			{Type: "user", UUID: "s1", ParentUUID: parent("a1"), IsSidechain: true, Message: text("user", "Investigate the test")},
+			{Type: "assistant", UUID: "a2", ParentUUID: parent("u1"), Message: text("assistant", "Retrying with a different approach")},
+			{Type: "user", UUID: "orphan", ParentUUID: parent("missing"), Message: text("user", "Continued session")},
+		},
+	}
+}
+
+func TestFormatConversationGraphDot(t *testing.T) {
+	graph, err := FormatConversationGraph(graphTestLog(), GraphFormatDot)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"digraph conversation {\n",
+		`m1 [label="user: Fix the \"flaky\" test"];`,
+		`m2 [label="assistant: [Task]"];`,
+		`m3 [label="user: Investigate the test", style=dashed];`,
+		"m1 -> m2;",
+		"m2 -> m3;",
+		"m1 -> m4;",
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("Expected %q in:\n%s", want, graph)
+		}
+	}
+	if strings.Contains(graph, "-> m5") {
+		t.Errorf("Message with a parent outside the file should be a root:\n%s", graph)
+	}
+	if strings.Contains(graph, "cluster") {
+		t.Errorf("A single conversation should not be clustered:\n%s", graph)
+	}
+}
+
+func TestFormatConversationGraphMermaid(t *testing.T) {
+	graph, err := FormatConversationGraph(graphTestLog(), GraphFormatMermaid)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"flowchart TD\n",
+		`m1["user: Fix the #quot;flaky#quot; test"]`,
+		"m1 --> m2",
+		"class m3 sidechain",
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("Expected %q in:\n%s", want, graph)
+		}
+	}
+}
+
+func TestFormatMultipleConversationsGraph(t *testing.T) {
+	graph, err := FormatMultipleConversationsGraph([]*types.ConversationLog{graphTestLog(), graphTestLog()}, GraphFormatDot)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(graph, "subgraph cluster_2 {") || !strings.Contains(graph, "m6 -> m7;") {
+		t.Errorf("Each conversation should get its own cluster with unique node ids:\n%s", graph)
+	}
+}
+
+func TestFormatConversationGraphUnknownFormat(t *testing.T) {
+	if _, err := FormatConversationGraph(graphTestLog(), "svg"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -2,17 +2,47 @@ package formatter
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
+	"github.com/annenpolka/cclog/internal/parallel"
 	"github.com/annenpolka/cclog/pkg/types"
 )
 
 // FormatOptions controls how messages are formatted
 type FormatOptions struct {
-	ShowUUID         bool
-	ShowPlaceholders bool
+	ShowUUID          bool
+	ShowPlaceholders  bool
+	CollapseThreshold int  // Collapse messages longer than this many lines (0 disables)
+	TruncateCollapsed bool // Drop collapsed lines instead of wrapping them in <details>
+	NumberMessages    bool // Prefix message headings with their position in the conversation
+	PageBreaks        bool // Insert page breaks between conversations for printing
+	OmitTimestamps    bool // Leave out the time of each message
+	MaxToolOutput     int  // Truncate tool results longer than this many characters (0 disables)
+	PairTools         bool // With ShowPlaceholders, show each tool result right after its tool call
+	MaxTokens         int  // Leave out the oldest messages so each conversation fits in about this many tokens (0 disables)
+	TableOfContents   bool // List the user turns at the top of single-conversation output, linking to anchors before them
+	MessageAnchors    bool // Put an HTML anchor derived from its UUID before each message (see MessageAnchor)
+	ShowThinking      bool // Show the assistant's thinking blocks as a quote before the rest of the message
+	Wrap              int  // Hard-wrap prose lines longer than this many columns, leaving code untouched (0 disables)
+	ToolSummary       bool // After each assistant turn, add a table of its tool calls with their duration and status
+	ShowModel         bool // Name the model next to assistant headings and the Claude Code version where it changes
+	ShowRequestIDs    bool // Show the request ID of assistant messages and mark API errors with theirs
+	// Timezone renders message timestamps in this location (nil means the system timezone)
+	Timezone *time.Location
+	// Markers are placed as notes before the first message after their time, or after the last message
+	// (sorted by time; single conversations only)
+	Markers []Marker
+}
+
+// Location returns the timezone timestamps are rendered in
+func (o FormatOptions) Location() *time.Location {
+	if o.Timezone == nil {
+		return GetSystemTimezone()
+	}
+	return o.Timezone
 }
 
 // FormatConversationToMarkdown converts a single conversation log to markdown with optional FormatOptions
@@ -22,39 +52,74 @@ func FormatConversationToMarkdown(log *types.ConversationLog, options ...FormatO
 		opt = options[0]
 	}
 	var sb strings.Builder
+	WriteConversationMarkdown(&sb, log, opt)
+	return sb.String()
+}
 
-	// Header
-	sb.WriteString("# Conversation Log\n\n")
-	sb.WriteString(fmt.Sprintf("**File:** `%s`\n", log.FilePath))
-	sb.WriteString(fmt.Sprintf("**Messages:** %d\n\n", len(log.Messages)))
+// WriteConversationMarkdown writes the markdown of FormatConversationToMarkdown to w one message at a time,
+// so the document is never held in memory as a whole. With MaxTokens, all messages are formatted
+// before writing to find those that fit. It returns the first error of w.
+func WriteConversationMarkdown(w io.Writer, log *types.ConversationLog, opt FormatOptions) error {
+	header := fmt.Sprintf("# Conversation Log\n\n**File:** `%s`\n**Messages:** %d\n\n", log.FilePath, len(log.Messages))
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
 
 	// Sort messages by timestamp for chronological order
-	messages := make([]types.Message, len(log.Messages))
-	copy(messages, log.Messages)
-	sort.Slice(messages, func(i, j int) bool {
-		return messages[i].Timestamp.Before(messages[j].Timestamp)
-	})
+	messages := SortMessagesChronologically(log.Messages)
+	if opt.PairTools && opt.ShowPlaceholders {
+		messages = pairToolResults(messages)
+	}
 
-	// Process messages
-	for _, msg := range messages {
-		if msg.Type == "summary" {
-			continue // Skip summary messages for now
+	if opt.MaxTokens <= 0 {
+		if opt.TableOfContents {
+			if _, err := io.WriteString(w, formatTableOfContents(messages, 0)); err != nil {
+				return err
+			}
 		}
-
-		sb.WriteString(formatMessage(msg, opt))
-		sb.WriteString("\n")
+		return eachMessageBlock(messages, opt, func(block string) error {
+			_, err := io.WriteString(w, block)
+			return err
+		})
 	}
 
-	return sb.String()
+	// The table of contents is not counted against the token limit
+	rendered, omitted := fitTokens(formatMessages(messages, opt), remainingTokens(opt.MaxTokens, header))
+	if opt.TableOfContents {
+		if _, err := io.WriteString(w, formatTableOfContents(messages, omitted)); err != nil {
+			return err
+		}
+	}
+	for _, message := range rendered {
+		if _, err := io.WriteString(w, message); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // FormatMultipleConversationsToMarkdown converts multiple conversation logs to markdown with optional FormatOptions
 func FormatMultipleConversationsToMarkdown(logs []*types.ConversationLog, options ...FormatOptions) string {
+	return FormatMultipleConversationsToMarkdownJobs(logs, 1, options...)
+}
+
+// FormatMultipleConversationsToMarkdownJobs formats conversations like FormatMultipleConversationsToMarkdown,
+// converting them concurrently with at most jobs workers (0 means one per CPU). The output does not depend on jobs.
+func FormatMultipleConversationsToMarkdownJobs(logs []*types.ConversationLog, jobs int, options ...FormatOptions) string {
 	opt := FormatOptions{ShowUUID: false}
 	if len(options) > 0 {
 		opt = options[0]
 	}
 	var sb strings.Builder
+	WriteMultipleConversationsMarkdown(&sb, logs, jobs, opt)
+	return sb.String()
+}
+
+// WriteMultipleConversationsMarkdown writes the markdown of FormatMultipleConversationsToMarkdownJobs to w.
+// Conversations are formatted concurrently in batches of one per worker and each batch is written
+// before the next is formatted, so at most jobs conversations are held in memory as markdown.
+func WriteMultipleConversationsMarkdown(w io.Writer, logs []*types.ConversationLog, jobs int, opt FormatOptions) error {
+	var sb strings.Builder
 
 	// Main header
 	sb.WriteString("# Claude Conversation Logs\n\n")
@@ -68,57 +133,195 @@ func FormatMultipleConversationsToMarkdown(logs []*types.ConversationLog, option
 			strings.ToLower(strings.ReplaceAll(filename, ".", ""))))
 	}
 	sb.WriteString("\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
 
 	// Individual conversations
-	for _, log := range logs {
-		filename := filepath.Base(log.FilePath)
-		sb.WriteString(fmt.Sprintf("## %s\n\n", filename))
-
-		// Sort messages by timestamp
-		messages := make([]types.Message, len(log.Messages))
-		copy(messages, log.Messages)
-		sort.Slice(messages, func(i, j int) bool {
-			return messages[i].Timestamp.Before(messages[j].Timestamp)
+	batch := parallel.Jobs(jobs)
+	for start := 0; start < len(logs); start += batch {
+		sections := parallel.Map(logs[start:min(start+batch, len(logs))], jobs, func(log *types.ConversationLog) string {
+			return formatConversationSection(log, opt)
 		})
-
-		for _, msg := range messages {
-			if msg.Type == "summary" {
-				continue
+		for i, section := range sections {
+			if opt.PageBreaks && start+i > 0 {
+				section = pageBreak + section
+			}
+			if _, err := io.WriteString(w, section); err != nil {
+				return err
 			}
-			sb.WriteString(formatMessage(msg, opt))
-			sb.WriteString("\n")
 		}
+	}
+	return nil
+}
+
+// formatConversationSection formats one conversation of a combined document
+func formatConversationSection(log *types.ConversationLog, opt FormatOptions) string {
+	var sb strings.Builder
+	filename := filepath.Base(log.FilePath)
+	sb.WriteString(fmt.Sprintf("## %s\n\n", filename))
 
-		sb.WriteString("---\n\n")
+	// Sort messages by timestamp
+	messages := SortMessagesChronologically(log.Messages)
+	if opt.PairTools && opt.ShowPlaceholders {
+		messages = pairToolResults(messages)
 	}
 
+	rendered, _ := fitTokens(formatMessages(messages, opt), remainingTokens(opt.MaxTokens, sb.String()))
+	for _, message := range rendered {
+		sb.WriteString(message)
+	}
+
+	sb.WriteString("---\n\n")
 	return sb.String()
 }
 
-// formatMessage formats a single message to markdown with optional FormatOptions
-func formatMessage(msg types.Message, options ...FormatOptions) string {
+// formatMessages renders the messages of a conversation, each followed by a blank line.
+// Summary messages are skipped.
+func formatMessages(messages []types.Message, opt FormatOptions) []string {
+	var rendered []string
+	eachMessageBlock(messages, opt, func(block string) error {
+		rendered = append(rendered, block)
+		return nil
+	})
+	return rendered
+}
+
+// eachMessageBlock renders the messages like formatMessages, passing each block to fn as soon as it
+// is rendered. It stops at the first error of fn.
+func eachMessageBlock(messages []types.Message, opt FormatOptions, fn func(block string) error) error {
+	toolNames := collectToolNames(messages)
+	var tools toolSummary
+	lastVersion := ""
+	number := 0
+	turn := 0 // User turns, numbered for the table of contents
+	markers := markerQueue(opt.Markers)
+	for _, msg := range messages {
+		if msg.Type == "summary" {
+			continue // Skip summary messages for now
+		}
+		number++
+		if opt.ShowModel {
+			// The version rarely changes within a session, so it is only shown when it does
+			version := msg.Version
+			if version == lastVersion {
+				msg.Version = ""
+			}
+			lastVersion = version
+		}
+		block := formatMessageWithTools(msg, number, opt, toolNames) + "\n"
+		if opt.TableOfContents && isUserTurn(msg) {
+			turn++
+			block = turnAnchor(turn) + block
+		}
+		if opt.MessageAnchors {
+			block = messageAnchorTag(msg.UUID) + block
+		}
+		// Markers follow the tools of the previous turn, which are summed up before the next prompt
+		block = markers.before(msg) + block
+		if opt.ToolSummary {
+			if isUserTurn(msg) {
+				block = tools.flush() + block
+			}
+			tools.add(msg)
+		}
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+	if summary := tools.flush() + markers.rest(); summary != "" {
+		return fn(summary)
+	}
+	return nil
+}
+
+// FormatMessagesToMarkdown renders each message of a conversation, in chronological order, as its own
+// markdown block, e.g. to show them one at a time. The blocks line up with the returned messages;
+// summary messages are left out and MaxTokens and ToolSummary are ignored.
+func FormatMessagesToMarkdown(log *types.ConversationLog, options ...FormatOptions) ([]types.Message, []string) {
 	opt := FormatOptions{ShowUUID: false}
 	if len(options) > 0 {
 		opt = options[0]
 	}
+
+	opt.ToolSummary = false // Tables would not line up with a message
+	messages := SortMessagesChronologically(log.Messages)
+	if opt.PairTools && opt.ShowPlaceholders {
+		messages = pairToolResults(messages)
+	}
+	var kept []types.Message
+	for _, msg := range messages {
+		if msg.Type != "summary" {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, formatMessages(kept, opt)
+}
+
+// formatMessage formats a single message to markdown with optional FormatOptions.
+// number is the 1-based position of the message, shown when NumberMessages is set.
+func formatMessage(msg types.Message, number int, options ...FormatOptions) string {
+	opt := FormatOptions{ShowUUID: false}
+	if len(options) > 0 {
+		opt = options[0]
+	}
+	return formatMessageWithTools(msg, number, opt, nil)
+}
+
+// formatMessageWithTools formats a single message to markdown.
+// toolNames maps tool_use ids of the conversation to tool names, so tool results can be shown by their tool's renderer.
+func formatMessageWithTools(msg types.Message, number int, opt FormatOptions, toolNames map[string]string) string {
 	var sb strings.Builder
 
 	// Determine message type and format accordingly
+	var role string
 	switch msg.Type {
 	case "user":
-		sb.WriteString("### User\n\n")
+		role = "User"
 	case "assistant":
-		sb.WriteString("### Assistant\n\n")
+		role = "Assistant"
 	default:
-		sb.WriteString(fmt.Sprintf("### %s\n\n", strings.Title(msg.Type)))
+		role = strings.Title(msg.Type)
+	}
+	if model := messageModel(msg); opt.ShowModel && model != "" {
+		role += " (" + model + ")"
+	}
+	if opt.NumberMessages {
+		sb.WriteString(fmt.Sprintf("### %d. %s\n\n", number, role))
+	} else {
+		sb.WriteString(fmt.Sprintf("### %s\n\n", role))
 	}
 
-	// Add timestamp using system timezone
-	localTime := msg.Timestamp.In(GetSystemTimezone())
-	sb.WriteString(fmt.Sprintf("**Time:** %s\n\n", localTime.Format("2006-01-02 15:04:05")))
+	// Add timestamp in the configured timezone (skipped for messages without one)
+	if !msg.Timestamp.IsZero() && !opt.OmitTimestamps {
+		localTime := msg.Timestamp.In(opt.Location())
+		sb.WriteString(fmt.Sprintf("**Time:** %s\n\n", localTime.Format("2006-01-02 15:04:05")))
+	}
+	if opt.ShowModel && msg.Version != "" {
+		sb.WriteString(fmt.Sprintf("**Version:** Claude Code %s\n\n", msg.Version))
+	}
+	apiError := opt.ShowRequestIDs && isAPIError(msg)
+	if apiError {
+		requestID := "no request ID"
+		if msg.RequestID != "" {
+			requestID = "request " + msg.RequestID
+		}
+		sb.WriteString(fmt.Sprintf("> [!CAUTION]\n> API error (%s)\n\n", requestID))
+	}
 
 	// Extract and format message content
-	content := ExtractMessageContent(msg.Message, opt.ShowPlaceholders)
+	var content string
+	if opt.ShowPlaceholders {
+		message, toolUseResult := truncateToolOutputs(msg.Message, msg.ToolUseResult, opt.MaxToolOutput)
+		content = extractContentWithPlaceholders(message, toolNames, toolUseResult)
+	} else {
+		content = types.ExtractTextContent(msg.Message)
+	}
+	if opt.ShowThinking {
+		content = withThinking(content, types.ExtractThinking(msg.Message))
+	}
+	content = collapseContent(content, opt.CollapseThreshold, opt.TruncateCollapsed)
+	content = wrapProse(content, opt.Wrap)
 	if content != "" {
 		sb.WriteString(content)
 		sb.WriteString("\n\n")
@@ -128,101 +331,105 @@ func formatMessage(msg types.Message, options ...FormatOptions) string {
 	if opt.ShowUUID && msg.UUID != "" {
 		sb.WriteString(fmt.Sprintf("*UUID: %s*\n\n", msg.UUID))
 	}
+	if opt.ShowRequestIDs && !apiError && msg.RequestID != "" {
+		sb.WriteString(fmt.Sprintf("*Request ID: %s*\n\n", msg.RequestID))
+	}
 
 	return sb.String()
 }
 
+// isAPIError reports whether a message is the error Claude Code records for a failed API request
+func isAPIError(msg types.Message) bool {
+	return msg.IsAPIErrorMessage ||
+		(msg.Type == "assistant" && strings.HasPrefix(strings.TrimSpace(types.ExtractTextContent(msg.Message)), "API Error"))
+}
+
+// messageModel returns the model that wrote an assistant message, or "" if unknown.
+// Messages made up by Claude Code itself, e.g. for API errors, are marked "<synthetic>" and have none.
+func messageModel(msg types.Message) string {
+	if msg.Message == nil {
+		return ""
+	}
+	model := msg.Message.Model
+	if strings.HasPrefix(model, "<") {
+		return ""
+	}
+	return model
+}
+
 // ExtractMessageContent extracts readable content from the message field with optional informative placeholders
-func ExtractMessageContent(message interface{}, showPlaceholders ...bool) string {
+func ExtractMessageContent(message *types.MessageBody, showPlaceholders ...bool) string {
 	showPlaceholdersBool := false
 	if len(showPlaceholders) > 0 {
 		showPlaceholdersBool = showPlaceholders[0]
 	}
-	if message == nil {
-		return ""
-	}
-
-	// Try to convert to map
-	msgMap, ok := message.(map[string]interface{})
-	if !ok {
-		return fmt.Sprintf("%v", message)
+	if !showPlaceholdersBool {
+		// Plain text extraction is shared with the message filter
+		return types.ExtractTextContent(message)
 	}
+	return extractContentWithPlaceholders(message, nil, nil)
+}
 
-	// Extract content field
-	content, exists := msgMap["content"]
-	if !exists {
+// extractContentWithPlaceholders extracts readable content, rendering tools with their registered renderers
+// and describing other non-text content with informative placeholders.
+// toolNamesByID maps tool_use ids to tool names. toolUseResult is the message's tool result metadata;
+// if nil, it is looked up inside the message.
+func extractContentWithPlaceholders(message *types.MessageBody, toolNamesByID map[string]string, toolUseResult interface{}) string {
+	if message == nil {
 		return ""
 	}
+	if toolUseResult == nil {
+		toolUseResult = message.ToolUseResult
+	}
 
 	// Handle string content
-	if str, ok := content.(string); ok {
-		if showPlaceholdersBool {
-			return generatePlaceholderForContent(str, msgMap)
-		}
-		return str
+	if !message.Content.IsBlocks() {
+		return generatePlaceholderForContent(message.Content.Text, message)
 	}
 
 	// Handle array content (Claude's complex message format)
-	if contentArray, ok := content.([]interface{}); ok {
-		var parts []string
-		var hasToolUse bool
-		var hasToolResult bool
-		var toolNames []string
-		var toolOperations []string
-
-		for _, item := range contentArray {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				if itemType, exists := itemMap["type"]; exists {
-					switch itemType {
-					case "text":
-						if text, exists := itemMap["text"]; exists {
-							if textStr, ok := text.(string); ok {
-								parts = append(parts, textStr)
-							}
-						}
-					case "tool_use":
-						hasToolUse = true
-						if toolName, exists := itemMap["name"]; exists {
-							if toolNameStr, ok := toolName.(string); ok {
-								toolNames = append(toolNames, toolNameStr)
-							}
-						}
-					case "tool_result":
-						hasToolResult = true
-						if toolUseID, exists := itemMap["tool_use_id"]; exists {
-							if toolID, ok := toolUseID.(string); ok {
-								toolOperations = append(toolOperations, toolID)
-							}
-						}
-					}
-				}
+	var parts []string
+	var hasToolUse bool
+	var hasToolResult bool
+	var toolNames []string
+	for _, block := range message.Content.Blocks {
+		switch b := block.(type) {
+		case types.TextBlock:
+			parts = append(parts, b.Text)
+		case types.ToolUseBlock:
+			if rendered := renderToolUse(b); rendered != "" {
+				parts = append(parts, rendered)
+				continue
 			}
-		}
-
-		result := strings.Join(parts, "\n")
-		if showPlaceholdersBool {
-			if result == "" && (hasToolUse || hasToolResult) {
-				// Generate more specific placeholder for tool operations
-				return generatePlaceholderForToolOperation(msgMap, hasToolUse, hasToolResult, toolNames, toolOperations)
+			hasToolUse = true
+			if b.Name != "" {
+				toolNames = append(toolNames, b.Name)
 			}
-			return generatePlaceholderForContent(result, msgMap)
+		case types.ToolResultBlock:
+			if rendered := renderToolResult(b, toolNamesByID, toolUseResult); rendered != "" {
+				parts = append(parts, rendered)
+				continue
+			}
+			hasToolResult = true
 		}
-		return result
 	}
 
-	return fmt.Sprintf("%v", content)
+	result := strings.Join(parts, "\n")
+	if result == "" && (hasToolUse || hasToolResult) {
+		// Generate more specific placeholder for tool operations
+		return generatePlaceholderForToolOperation(message, hasToolUse, hasToolResult, toolNames)
+	}
+	return generatePlaceholderForContent(result, message)
 }
 
 // generatePlaceholderForContent generates informative placeholders for filtered content
-func generatePlaceholderForContent(content string, msgMap map[string]interface{}) string {
+func generatePlaceholderForContent(content string, message *types.MessageBody) string {
 	if content == "" {
 		// Check for tool use result metadata for empty content
-		if toolUseResult, exists := msgMap["toolUseResult"]; exists {
-			if turMap, ok := toolUseResult.(map[string]interface{}); ok {
-				return generatePlaceholderForToolUseResult(turMap)
-			}
+		if turMap, ok := message.ToolUseResult.(map[string]interface{}); ok {
+			return generatePlaceholderForToolUseResult(turMap)
 		}
-		return "*[Empty message content]*"
+		return emptyContentPlaceholder
 	}
 
 	// Check for system warning messages
@@ -259,7 +466,7 @@ func generatePlaceholderForContent(content string, msgMap map[string]interface{}
 }
 
 // generatePlaceholderForToolOperation generates placeholders for tool use/result operations with empty content
-func generatePlaceholderForToolOperation(msgMap map[string]interface{}, hasToolUse, hasToolResult bool, toolNames, toolOperations []string) string {
+func generatePlaceholderForToolOperation(message *types.MessageBody, hasToolUse, hasToolResult bool, toolNames []string) string {
 	if hasToolUse && len(toolNames) > 0 {
 		if len(toolNames) == 1 {
 			return fmt.Sprintf("*[Tool used: %s (no output)]*", toolNames[0])
@@ -269,15 +476,37 @@ func generatePlaceholderForToolOperation(msgMap map[string]interface{}, hasToolU
 
 	if hasToolResult {
 		// Check for tool use result metadata
-		if toolUseResult, exists := msgMap["toolUseResult"]; exists {
-			if turMap, ok := toolUseResult.(map[string]interface{}); ok {
-				return generatePlaceholderForToolUseResult(turMap)
-			}
+		if turMap, ok := message.ToolUseResult.(map[string]interface{}); ok {
+			return generatePlaceholderForToolUseResult(turMap)
 		}
 		return "*[Tool operation completed (no output)]*"
 	}
 
-	return "*[Empty message content]*"
+	return emptyContentPlaceholder
+}
+
+// emptyContentPlaceholder describes a message without text or tool content
+const emptyContentPlaceholder = "*[Empty message content]*"
+
+// withThinking puts thinking, quoted, before the content of a message. A message holding only
+// thinking is not described as empty.
+func withThinking(content, thinking string) string {
+	if thinking == "" {
+		return content
+	}
+	lines := strings.Split(strings.TrimSpace(thinking), "\n")
+	var sb strings.Builder
+	sb.WriteString("> **Thinking**\n>\n")
+	for i, line := range lines {
+		sb.WriteString(strings.TrimRight("> "+line, " "))
+		if i < len(lines)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	if content == "" || content == emptyContentPlaceholder {
+		return sb.String()
+	}
+	return sb.String() + "\n\n" + content
 }
 
 // generatePlaceholderForToolUseResult generates specific placeholders based on tool use result metadata
@@ -1,6 +1,8 @@
 package formatter
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -19,10 +21,7 @@ func TestFormatConversationToMarkdownWithoutUUID(t *testing.T) {
 				Type:      "user",
 				UUID:      "user-uuid-1",
 				Timestamp: timestamp1,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Hello, how are you?",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello, how are you?")},
 			},
 		},
 	}
@@ -39,6 +38,77 @@ func TestFormatConversationToMarkdownWithoutUUID(t *testing.T) {
 	}
 }
 
+func TestFormatConversationToMarkdownOmitTimestamps(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29.618Z")
+	log := &types.ConversationLog{
+		Messages: []types.Message{{
+			Type:      "user",
+			Timestamp: timestamp,
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello")},
+		}},
+	}
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{OmitTimestamps: true})
+	if strings.Contains(markdown, "**Time:**") {
+		t.Errorf("Markdown should not contain timestamps:\n%s", markdown)
+	}
+	if !strings.Contains(markdown, "### User\n\nHello") {
+		t.Errorf("Expected the role heading followed by the content:\n%s", markdown)
+	}
+}
+
+func TestFormatConversationToMarkdownShowThinking(t *testing.T) {
+	thinkingOnly := &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ThinkingBlock{Thinking: "Look at the failing test\n\nthen fix it"})}
+	withReply := &types.MessageBody{Role: "assistant", Content: types.BlockContent(
+		types.ThinkingBlock{Thinking: "Short"},
+		types.TextBlock{Text: "Fixed."},
+	)}
+	log := &types.ConversationLog{Messages: []types.Message{
+		{Type: "assistant", Message: thinkingOnly},
+		{Type: "assistant", Message: withReply},
+	}}
+
+	for _, placeholders := range []bool{false, true} {
+		markdown := FormatConversationToMarkdown(log, FormatOptions{ShowThinking: true, ShowPlaceholders: placeholders})
+		if !strings.Contains(markdown, "### Assistant\n\n> **Thinking**\n>\n> Look at the failing test\n>\n> then fix it\n") {
+			t.Errorf("Expected a thinking-only message as a quote (placeholders %v):\n%s", placeholders, markdown)
+		}
+		if !strings.Contains(markdown, "> Short\n\nFixed.") {
+			t.Errorf("Expected the thinking before the reply (placeholders %v):\n%s", placeholders, markdown)
+		}
+	}
+
+	if markdown := FormatConversationToMarkdown(log); strings.Contains(markdown, "Thinking") {
+		t.Errorf("Expected thinking to be hidden by default:\n%s", markdown)
+	}
+}
+
+func TestFormatConversationToMarkdownShowModel(t *testing.T) {
+	log := &types.ConversationLog{Messages: []types.Message{
+		{Type: "user", Version: "1.0.43", Message: &types.MessageBody{Role: "user", Content: types.TextContent("Hi")}},
+		{Type: "assistant", Version: "1.0.43", Message: &types.MessageBody{Role: "assistant", Model: "claude-sonnet-4-20250514", Content: types.TextContent("Hello")}},
+		{Type: "assistant", Version: "1.0.44", Message: &types.MessageBody{Role: "assistant", Model: "<synthetic>", Content: types.TextContent("API Error")}},
+	}}
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowModel: true})
+	for _, want := range []string{
+		"### User\n\n**Version:** Claude Code 1.0.43\n\nHi",
+		"### Assistant (claude-sonnet-4-20250514)\n\nHello",
+		"### Assistant\n\n**Version:** Claude Code 1.0.44\n\nAPI Error",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in:\n%s", want, markdown)
+		}
+	}
+	if strings.Count(markdown, "**Version:**") != 2 {
+		t.Errorf("Expected the version only where it changes:\n%s", markdown)
+	}
+
+	if markdown := FormatConversationToMarkdown(log); strings.Contains(markdown, "claude-sonnet") || strings.Contains(markdown, "Version") {
+		t.Errorf("Expected no model or version by default:\n%s", markdown)
+	}
+}
+
 func TestFormatConversationToMarkdownWithUUID(t *testing.T) {
 	// Test with UUID enabled
 	timestamp1, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29.618Z")
@@ -50,10 +120,7 @@ func TestFormatConversationToMarkdownWithUUID(t *testing.T) {
 				Type:      "user",
 				UUID:      "user-uuid-1",
 				Timestamp: timestamp1,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Hello, how are you?",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello, how are you?")},
 			},
 		},
 	}
@@ -78,24 +145,13 @@ func TestFormatConversationToMarkdown(t *testing.T) {
 				Type:      "user",
 				UUID:      "user-uuid-1",
 				Timestamp: timestamp1,
-				Message: map[string]interface{}{
-					"role":    "user",
-					"content": "Hello, how are you?",
-				},
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello, how are you?")},
 			},
 			{
 				Type:      "assistant",
 				UUID:      "assistant-uuid-1",
 				Timestamp: timestamp2,
-				Message: map[string]interface{}{
-					"role": "assistant",
-					"content": []interface{}{
-						map[string]interface{}{
-							"type": "text",
-							"text": "I'm doing well, thank you!",
-						},
-					},
-				},
+				Message:   &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.TextBlock{Text: "I'm doing well, thank you!"})},
 			},
 		},
 	}
@@ -149,10 +205,7 @@ func TestFormatMultipleConversationsToMarkdown(t *testing.T) {
 					Type:      "user",
 					UUID:      "user-uuid-1",
 					Timestamp: timestamp1,
-					Message: map[string]interface{}{
-						"role":    "user",
-						"content": "First conversation",
-					},
+					Message:   &types.MessageBody{Role: "user", Content: types.TextContent("First conversation")},
 				},
 			},
 		},
@@ -163,10 +216,7 @@ func TestFormatMultipleConversationsToMarkdown(t *testing.T) {
 					Type:      "user",
 					UUID:      "user-uuid-2",
 					Timestamp: timestamp1,
-					Message: map[string]interface{}{
-						"role":    "user",
-						"content": "Second conversation",
-					},
+					Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Second conversation")},
 				},
 			},
 		},
@@ -198,28 +248,17 @@ func TestFormatMultipleConversationsToMarkdown(t *testing.T) {
 func TestExtractMessageContent(t *testing.T) {
 	tests := []struct {
 		name     string
-		message  interface{}
+		message  *types.MessageBody
 		expected string
 	}{
 		{
-			name: "simple string content",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "Hello world",
-			},
+			name:     "simple string content",
+			message:  &types.MessageBody{Role: "user", Content: types.TextContent("Hello world")},
 			expected: "Hello world",
 		},
 		{
-			name: "complex content array",
-			message: map[string]interface{}{
-				"role": "assistant",
-				"content": []interface{}{
-					map[string]interface{}{
-						"type": "text",
-						"text": "Response text",
-					},
-				},
-			},
+			name:     "complex content array",
+			message:  &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.TextBlock{Text: "Response text"})},
 			expected: "Response text",
 		},
 		{
@@ -242,87 +281,64 @@ func TestExtractMessageContent(t *testing.T) {
 func TestExtractMessageContentWithPlaceholders(t *testing.T) {
 	tests := []struct {
 		name             string
-		message          interface{}
+		message          *types.MessageBody
 		showPlaceholders bool
 		expectedWithout  string
 		expectedWith     string
 	}{
 		{
-			name: "meta message with isMeta flag",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "Caveat: The messages below were generated by the user while running local commands.",
-			},
+			name:             "meta message with isMeta flag",
+			message:          &types.MessageBody{Role: "user", Content: types.TextContent("Caveat: The messages below were generated by the user while running local commands.")},
 			showPlaceholders: true,
 			expectedWithout:  "Caveat: The messages below were generated by the user while running local commands.",
 			expectedWith:     "*[System warning message - contains caveats about local commands]*",
 		},
 		{
-			name: "command execution message",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "<command-name>/ide</command-name>\n<command-message>ide</command-message>\n<command-args></command-args>",
-			},
+			name:             "command execution message",
+			message:          &types.MessageBody{Role: "user", Content: types.TextContent("<command-name>/ide</command-name>\n<command-message>ide</command-message>\n<command-args></command-args>")},
 			showPlaceholders: true,
 			expectedWithout:  "<command-name>/ide</command-name>\n<command-message>ide</command-message>\n<command-args></command-args>",
 			expectedWith:     "*[Command executed: /ide]*",
 		},
 		{
-			name: "command output message",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "<local-command-stdout>Connected to Visual Studio Code.</local-command-stdout>",
-			},
+			name:             "command output message",
+			message:          &types.MessageBody{Role: "user", Content: types.TextContent("<local-command-stdout>Connected to Visual Studio Code.</local-command-stdout>")},
 			showPlaceholders: true,
 			expectedWithout:  "<local-command-stdout>Connected to Visual Studio Code.</local-command-stdout>",
 			expectedWith:     "*[Command output: Connected to Visual Studio Code.]*",
 		},
 		{
-			name: "empty content",
-			message: map[string]interface{}{
-				"role":    "assistant",
-				"content": "",
-			},
+			name:             "empty content",
+			message:          &types.MessageBody{Role: "assistant", Content: types.TextContent("")},
 			showPlaceholders: true,
 			expectedWithout:  "",
 			expectedWith:     "*[Empty message content]*",
 		},
 		{
 			name: "empty content with tool use result",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "",
-				"toolUseResult": map[string]interface{}{
-					"type":     "create",
-					"filePath": "/tmp/test.txt",
-					"content":  "",
-				},
-			},
+			message: &types.MessageBody{Role: "user", Content: types.TextContent(""), ToolUseResult: map[string]interface{}{
+				"type":     "create",
+				"filePath": "/tmp/test.txt",
+				"content":  "",
+			}},
 			showPlaceholders: true,
 			expectedWithout:  "",
 			expectedWith:     "*[File created: /tmp/test.txt (empty)]*",
 		},
 		{
 			name: "empty content with command result",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "",
-				"toolUseResult": map[string]interface{}{
-					"stdout":      "",
-					"stderr":      "",
-					"interrupted": false,
-				},
-			},
+			message: &types.MessageBody{Role: "user", Content: types.TextContent(""), ToolUseResult: map[string]interface{}{
+				"stdout":      "",
+				"stderr":      "",
+				"interrupted": false,
+			}},
 			showPlaceholders: true,
 			expectedWithout:  "",
 			expectedWith:     "*[Command executed successfully (no output)]*",
 		},
 		{
-			name: "normal message unchanged",
-			message: map[string]interface{}{
-				"role":    "user",
-				"content": "This is a normal user message",
-			},
+			name:             "normal message unchanged",
+			message:          &types.MessageBody{Role: "user", Content: types.TextContent("This is a normal user message")},
 			showPlaceholders: true,
 			expectedWithout:  "This is a normal user message",
 			expectedWith:     "This is a normal user message",
@@ -345,3 +361,142 @@ func TestExtractMessageContentWithPlaceholders(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatMultipleConversationsToMarkdownJobs(t *testing.T) {
+	var logs []*types.ConversationLog
+	for i := 0; i < 10; i++ {
+		logs = append(logs, &types.ConversationLog{
+			FilePath: strings.Repeat("x", i+1) + ".jsonl",
+			Messages: []types.Message{{
+				Type:      "user",
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent(strings.Repeat("hello ", i+1))},
+				Timestamp: time.Date(2025, 7, 6, 5, i, 0, 0, time.UTC),
+			}},
+		})
+	}
+
+	sequential := FormatMultipleConversationsToMarkdown(logs, FormatOptions{PageBreaks: true})
+	concurrent := FormatMultipleConversationsToMarkdownJobs(logs, 4, FormatOptions{PageBreaks: true})
+	if sequential != concurrent {
+		t.Errorf("Concurrent output differs from sequential output:\n%s\n---\n%s", sequential, concurrent)
+	}
+}
+
+func TestFormatConversationMaxTokens(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	var messages []types.Message
+	for i := 0; i < 10; i++ {
+		messages = append(messages, types.Message{
+			Type:      "user",
+			Timestamp: ts.Add(time.Duration(i) * time.Minute),
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent(fmt.Sprintf("Message %d %s", i, strings.Repeat("word ", 40)))},
+		})
+	}
+	log := &types.ConversationLog{FilePath: "session.jsonl", Messages: messages}
+
+	full := FormatConversationToMarkdown(log, FormatOptions{Timezone: time.UTC})
+	trimmed := FormatConversationToMarkdown(log, FormatOptions{Timezone: time.UTC, MaxTokens: 200})
+	if EstimateTokens(trimmed) > 200 || EstimateTokens(full) <= 200 {
+		t.Fatalf("Expected the trimmed output to fit in 200 tokens, got %d (full: %d)", EstimateTokens(trimmed), EstimateTokens(full))
+	}
+	if !strings.Contains(trimmed, "earlier messages omitted to fit the token limit.*") {
+		t.Errorf("Expected a note on the omitted messages, got:\n%s", trimmed)
+	}
+	if strings.Contains(trimmed, "Message 0 ") || !strings.Contains(trimmed, "Message 9 ") {
+		t.Errorf("Expected the oldest messages to be left out first, got:\n%s", trimmed)
+	}
+
+	combined := FormatMultipleConversationsToMarkdown([]*types.ConversationLog{log}, FormatOptions{Timezone: time.UTC, MaxTokens: 200})
+	if strings.Contains(combined, "Message 0 ") || !strings.Contains(combined, "Message 9 ") {
+		t.Errorf("Expected each conversation of a combined document to be trimmed, got:\n%s", combined)
+	}
+}
+
+func TestFormatMessagesToMarkdown(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	log := &types.ConversationLog{Messages: []types.Message{
+		{Type: "assistant", Timestamp: ts.Add(time.Minute), Message: &types.MessageBody{Role: "assistant", Content: types.TextContent("second")}},
+		{Type: "summary", Timestamp: ts},
+		{Type: "user", Timestamp: ts, Message: &types.MessageBody{Role: "user", Content: types.TextContent("first")}},
+	}}
+
+	messages, blocks := FormatMessagesToMarkdown(log, FormatOptions{Timezone: time.UTC, NumberMessages: true})
+	if len(messages) != 2 || len(blocks) != 2 {
+		t.Fatalf("Expected 2 messages without the summary, got %d messages and %d blocks", len(messages), len(blocks))
+	}
+	if messages[0].Type != "user" || !strings.HasPrefix(blocks[0], "### 1. User") || !strings.Contains(blocks[0], "first") {
+		t.Errorf("Expected the user message first, got %q", blocks[0])
+	}
+	if !strings.HasPrefix(blocks[1], "### 2. Assistant") || !strings.Contains(blocks[1], "second") {
+		t.Errorf("Expected the assistant reply second, got %q", blocks[1])
+	}
+}
+
+// countingWriter records the number of writes and fails after limit of them (0 never fails)
+type countingWriter struct {
+	sb     strings.Builder
+	writes int
+	limit  int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.limit > 0 && w.writes > w.limit {
+		return 0, errors.New("disk full")
+	}
+	return w.sb.Write(p)
+}
+
+func (w *countingWriter) String() string {
+	return w.sb.String()
+}
+
+func TestWriteConversationMarkdown(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	var messages []types.Message
+	for i := 0; i < 5; i++ {
+		messages = append(messages, types.Message{
+			Type:      "user",
+			UUID:      fmt.Sprintf("u%d", i),
+			Timestamp: ts.Add(time.Duration(i) * time.Minute),
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent(fmt.Sprintf("Message %d", i))},
+		})
+	}
+	log := &types.ConversationLog{FilePath: "session.jsonl", Messages: messages}
+
+	for _, opt := range []FormatOptions{{}, {TableOfContents: true, MessageAnchors: true}, {MaxTokens: 30}} {
+		var w countingWriter
+		if err := WriteConversationMarkdown(&w, log, opt); err != nil {
+			t.Fatalf("WriteConversationMarkdown failed: %v", err)
+		}
+		if want := FormatConversationToMarkdown(log, opt); w.String() != want {
+			t.Errorf("Streamed markdown with %+v differs:\n%s\nwant:\n%s", opt, w.String(), want)
+		}
+		if opt.MaxTokens == 0 && w.writes <= len(messages) {
+			t.Errorf("Expected one write per message, got %d writes", w.writes)
+		}
+	}
+
+	w := countingWriter{limit: 2}
+	if err := WriteConversationMarkdown(&w, log, FormatOptions{}); err == nil || w.writes != 3 {
+		t.Errorf("Expected the first write error to stop formatting, got %v after %d writes", err, w.writes)
+	}
+}
+
+func TestWriteMultipleConversationsMarkdown(t *testing.T) {
+	var logs []*types.ConversationLog
+	for i := 0; i < 7; i++ {
+		logs = append(logs, &types.ConversationLog{
+			FilePath: fmt.Sprintf("session-%d.jsonl", i),
+			Messages: []types.Message{{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("hello")}}},
+		})
+	}
+
+	var w countingWriter
+	if err := WriteMultipleConversationsMarkdown(&w, logs, 3, FormatOptions{PageBreaks: true}); err != nil {
+		t.Fatalf("WriteMultipleConversationsMarkdown failed: %v", err)
+	}
+	if want := FormatMultipleConversationsToMarkdown(logs, FormatOptions{PageBreaks: true}); w.String() != want {
+		t.Errorf("Streamed markdown differs:\n%s\nwant:\n%s", w.String(), want)
+	}
+}
@@ -0,0 +1,49 @@
+package formatter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Marker is a note placed between the messages of a conversation at the time of an event outside it,
+// e.g. a git commit
+type Marker struct {
+	Time time.Time
+	Text string // Markdown on one line
+}
+
+// markerQueue hands out the markers sorted by time as the messages pass them
+type markerQueue []Marker
+
+// before returns the markers of the queue older than msg as note callouts and removes them. Messages
+// without a timestamp pass no marker.
+func (q *markerQueue) before(msg types.Message) string {
+	if msg.Timestamp.IsZero() {
+		return ""
+	}
+	n := 0
+	for n < len(*q) && !(*q)[n].Time.After(msg.Timestamp) {
+		n++
+	}
+	text := formatMarkers((*q)[:n])
+	*q = (*q)[n:]
+	return text
+}
+
+// rest returns the markers left after the last message and empties the queue
+func (q *markerQueue) rest() string {
+	text := formatMarkers(*q)
+	*q = nil
+	return text
+}
+
+// formatMarkers renders markers as one note callout each
+func formatMarkers(markers []Marker) string {
+	var sb strings.Builder
+	for _, marker := range markers {
+		sb.WriteString("> [!NOTE]\n> " + marker.Text + "\n\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,24 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkers(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	got := FormatConversationToMarkdown(anchorTestLog(), FormatOptions{Timezone: time.UTC, Markers: []Marker{
+		{Time: ts.Add(90 * time.Second), Text: "Commit `abc1234`"},
+		{Time: ts.Add(2 * time.Minute), Text: "At message 2"},
+		{Time: ts.Add(time.Hour), Text: "After the end"},
+	}})
+
+	// A marker comes before the first message after it, or at its time
+	if want := "Message 1\n\n\n> [!NOTE]\n> Commit `abc1234`\n\n> [!NOTE]\n> At message 2\n\n### User\n\n**Time:** 2025-07-06 05:02:00\n\nMessage 2"; !strings.Contains(got, want) {
+		t.Errorf("Expected %q in:\n%s", want, got)
+	}
+	if want := "Message 9\n\n\n> [!NOTE]\n> After the end\n\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("Expected the output to end with %q, got:\n%s", want, got)
+	}
+}
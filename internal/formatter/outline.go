@@ -0,0 +1,118 @@
+package formatter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// outlineSummaryMaxRunes caps the length of an assistant summary line
+const outlineSummaryMaxRunes = 200
+
+// FormatConversationOutline renders a skimmable outline of a conversation:
+// each user prompt followed by the first sentence of the assistant's response
+func FormatConversationOutline(log *types.ConversationLog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Conversation Outline\n\n")
+	sb.WriteString(fmt.Sprintf("**File:** `%s`\n\n", log.FilePath))
+	writeOutline(&sb, log)
+
+	return sb.String()
+}
+
+// FormatMultipleConversationsOutline renders outlines for multiple conversation logs
+func FormatMultipleConversationsOutline(logs []*types.ConversationLog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Conversation Outlines\n\n")
+	for _, log := range logs {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", filepath.Base(log.FilePath)))
+		writeOutline(&sb, log)
+	}
+
+	return sb.String()
+}
+
+// writeOutline writes the numbered prompt list for a single conversation
+func writeOutline(sb *strings.Builder, log *types.ConversationLog) {
+	number := 0
+	summarized := true // Only the first assistant reply after each prompt is summarized
+	for _, msg := range SortMessagesChronologically(log.Messages) {
+		content := strings.TrimSpace(types.ExtractTextContent(msg.Message))
+		if content == "" {
+			continue
+		}
+
+		switch msg.Type {
+		case "user":
+			number++
+			summarized = false
+			sb.WriteString(fmt.Sprintf("%d. %s\n", number, singleLine(content)))
+		case "assistant":
+			if summarized || number == 0 {
+				continue
+			}
+			summarized = true
+			if summary := firstSentence(content); summary != "" {
+				sb.WriteString(fmt.Sprintf("   - %s\n", summary))
+			}
+		}
+	}
+
+	if number == 0 {
+		sb.WriteString("*No user prompts found.*\n")
+	}
+	sb.WriteString("\n")
+}
+
+// singleLine collapses all whitespace runs, including newlines, into single spaces
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// firstSentence returns the first sentence of the first prose line in text.
+// Code blocks are skipped, and the result is truncated to outlineSummaryMaxRunes.
+func firstSentence(text string) string {
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" {
+			continue
+		}
+		return truncateRunes(cutSentence(singleLine(trimmed)), outlineSummaryMaxRunes)
+	}
+	return ""
+}
+
+// cutSentence returns text up to and including its first sentence terminator
+func cutSentence(text string) string {
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '。', '！', '？':
+			return string(runes[:i+1])
+		case '.', '!', '?':
+			// Require a following space so that "e.g" or "v1.2" do not end the sentence
+			if i+1 == len(runes) || runes[i+1] == ' ' {
+				return string(runes[:i+1])
+			}
+		}
+	}
+	return text
+}
+
+// truncateRunes shortens text to at most max runes, marking truncation with an ellipsis
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,71 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestFormatConversationOutline(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	msg := func(offset int, msgType string, content types.Content) types.Message {
+		return types.Message{
+			Type:      msgType,
+			Timestamp: ts.Add(time.Duration(offset) * time.Second),
+			Message:   &types.MessageBody{Role: msgType, Content: content},
+		}
+	}
+
+	log := &types.ConversationLog{
+		FilePath: "session.jsonl",
+		Messages: []types.Message{
+			msg(0, "user", types.TextContent("Fix the failing\ntest please")),
+			msg(1, "assistant", types.TextContent("I'll look at the test first. Then I will fix it.")),
+			msg(2, "assistant", types.TextContent("The test is fixed now.")),
+			msg(3, "user", types.BlockContent(types.ToolResultBlock{Content: types.TextContent("ok")})),
+			msg(4, "user", types.TextContent("Now update the docs")),
+			msg(5, "assistant", types.TextContent("```go\ncode()\n```\nDone, see README.md for details!")),
+		},
+	}
+
+	result := FormatConversationOutline(log)
+
+	for _, want := range []string{
+		"1. Fix the failing test please\n",
+		"   - I'll look at the test first.\n",
+		"2. Now update the docs\n",
+		"   - Done, see README.md for details!\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected outline to contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "The test is fixed now") {
+		t.Error("Only the first assistant reply after a prompt should be summarized")
+	}
+	if strings.Contains(result, "3.") {
+		t.Error("Tool results should not be listed as prompts")
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"One. Two.", "One."},
+		{"Use v1.2 here. Then stop.", "Use v1.2 here."},
+		{"了解しました。修正します。", "了解しました。"},
+		{"\n\nNo terminator", "No terminator"},
+		{"```\nonly code\n```", ""},
+		{strings.Repeat("a", 300), strings.Repeat("a", 197) + "..."},
+	}
+
+	for _, tt := range tests {
+		if got := firstSentence(tt.input); got != tt.want {
+			t.Errorf("firstSentence(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
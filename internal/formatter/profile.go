@@ -0,0 +1,30 @@
+package formatter
+
+import "fmt"
+
+const (
+	// ProfileDefault keeps the format options as given
+	ProfileDefault = "default"
+	// ProfilePrint produces output suited for printing or PDF conversion
+	ProfilePrint = "print"
+)
+
+// pageBreak forces a page break when the markdown is rendered to HTML and printed
+const pageBreak = "<div style=\"page-break-before: always;\"></div>\n\n"
+
+// ApplyProfile adjusts format options for the named output profile
+func ApplyProfile(opt FormatOptions, profile string) (FormatOptions, error) {
+	switch profile {
+	case "", ProfileDefault:
+		return opt, nil
+	case ProfilePrint:
+		// Printed pages cannot expand collapsed sections
+		opt.CollapseThreshold = 0
+		opt.TruncateCollapsed = false
+		opt.NumberMessages = true
+		opt.PageBreaks = true
+		return opt, nil
+	default:
+		return opt, fmt.Errorf("unknown profile: %s (available: %s, %s)", profile, ProfileDefault, ProfilePrint)
+	}
+}
@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestApplyProfile(t *testing.T) {
+	base := FormatOptions{ShowUUID: true, CollapseThreshold: 20, TruncateCollapsed: true}
+
+	opts, err := ApplyProfile(base, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(opts, base) {
+		t.Errorf("Default profile should leave options unchanged, got %+v", opts)
+	}
+
+	opts, err = ApplyProfile(base, ProfilePrint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !opts.ShowUUID {
+		t.Error("Print profile should keep ShowUUID")
+	}
+	if opts.CollapseThreshold != 0 || opts.TruncateCollapsed {
+		t.Error("Print profile should disable collapsing")
+	}
+	if !opts.NumberMessages || !opts.PageBreaks {
+		t.Error("Print profile should enable message numbers and page breaks")
+	}
+
+	if _, err := ApplyProfile(base, "fancy"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
+
+func TestFormatMultipleConversationsPrintProfile(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 1, 29, 0, time.UTC)
+	newLog := func(file string) *types.ConversationLog {
+		return &types.ConversationLog{
+			FilePath: file,
+			Messages: []types.Message{
+				{Type: "user", Timestamp: ts, Message: &types.MessageBody{Role: "user", Content: types.TextContent("Question")}},
+				{Type: "summary"},
+				{Type: "assistant", Timestamp: ts.Add(time.Second), Message: &types.MessageBody{Role: "assistant", Content: types.TextContent("Answer")}},
+			},
+		}
+	}
+
+	opts, err := ApplyProfile(FormatOptions{}, ProfilePrint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	result := FormatMultipleConversationsToMarkdown([]*types.ConversationLog{newLog("a.jsonl"), newLog("b.jsonl")}, opts)
+
+	if !strings.Contains(result, "### 1. User") || !strings.Contains(result, "### 2. Assistant") {
+		t.Errorf("Expected numbered headings skipping summaries, got:\n%s", result)
+	}
+	if count := strings.Count(result, pageBreak); count != 1 {
+		t.Errorf("Expected 1 page break between 2 conversations, got %d", count)
+	}
+}
@@ -0,0 +1,250 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// FormatSlack is Slack mrkdwn split into chat messages, for pasting or posting to a Slack channel
+	FormatSlack = "slack"
+	// FormatDiscord is Discord markdown split into chat messages, for pasting or posting to a Discord channel
+	FormatDiscord = "discord"
+)
+
+// shareLimits are the characters a chat message of each share format may hold. Slack truncates longer
+// messages and Discord rejects them.
+var shareLimits = map[string]int{
+	FormatSlack:   4000,
+	FormatDiscord: 2000,
+}
+
+// ShareSeparator marks where one chat message ends and the next begins in pasted share output
+const ShareSeparator = "-------- 8< --------"
+
+// IsShareFormat reports whether format is FormatSlack or FormatDiscord
+func IsShareFormat(format string) bool {
+	_, ok := shareLimits[format]
+	return ok
+}
+
+// FormatShareChunks renders conversations in a share format as chat messages under its size limit: the
+// title of each conversation, then each prompt and reply with its role and time. Like the context
+// format, tool calls are left out and consecutive replies form one. Messages are split between turns
+// where possible, then between lines; code blocks split across messages are closed and reopened.
+func FormatShareChunks(logs []*types.ConversationLog, format string, opt FormatOptions) []string {
+	var blocks []string
+	for _, log := range logs {
+		blocks = append(blocks, shareTurns(log, format, opt)...)
+	}
+	return chunkShare(blocks, shareLimits[format])
+}
+
+// JoinShareChunks joins chat messages for pasting, separated by ShareSeparator lines
+func JoinShareChunks(chunks []string) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+	return strings.Join(chunks, "\n\n"+ShareSeparator+"\n\n") + "\n"
+}
+
+// shareTurns renders the title and the turns of a conversation, each as a block of text
+func shareTurns(log *types.ConversationLog, format string, opt FormatOptions) []string {
+	bold := func(s string) string { return "**" + s + "**" }
+	text := func(s string) string { return s }
+	if format == FormatSlack {
+		bold = func(s string) string { return "*" + s + "*" }
+		text = slackText
+	}
+
+	blocks := []string{bold(text(types.ExtractTitle(log)))}
+	var role string
+	for _, msg := range SortMessagesChronologically(log.Messages) {
+		var next string
+		switch msg.Type {
+		case "user":
+			next = "User"
+		case "assistant":
+			next = "Assistant"
+		default:
+			continue
+		}
+		content := strings.TrimSpace(types.ExtractTextContent(msg.Message))
+		if content == "" {
+			continue // Tool calls and results carry no text
+		}
+		content = text(content)
+
+		if next == role {
+			blocks[len(blocks)-1] += "\n\n" + content
+			continue
+		}
+		role = next
+		heading := bold(role)
+		if !msg.Timestamp.IsZero() && !opt.OmitTimestamps {
+			heading += " · " + msg.Timestamp.In(opt.Location()).Format("2006-01-02 15:04")
+		}
+		blocks = append(blocks, heading+"\n"+content)
+	}
+	return blocks
+}
+
+// Markdown constructs rewritten to Slack mrkdwn
+var (
+	slackEmphasis = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__|\*([^*\n]+)\*`)
+	slackStrike   = regexp.MustCompile(`~~([^~\n]+)~~`)
+	slackLink     = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	slackHeading  = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	slackBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+)
+
+// slackEscaper escapes the characters Slack reserves for its control sequences
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackText rewrites the markdown of a message as Slack mrkdwn: bold, italics, strikethrough, links,
+// headings and bullets are translated, code is kept verbatim and fences lose their language, which
+// Slack would show as text. &, < and > are escaped, except the > of quotes.
+func slackText(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if !inCode {
+				line = line[:strings.Index(line, "```")+3]
+			}
+			inCode = !inCode
+			lines[i] = line
+			continue
+		}
+		if inCode {
+			lines[i] = slackEscaper.Replace(line)
+			continue
+		}
+		quote := ""
+		for strings.HasPrefix(line, ">") {
+			quote += ">"
+			line = strings.TrimPrefix(line, ">")
+		}
+		if quote != "" {
+			quote += " "
+			line = strings.TrimPrefix(line, " ")
+		}
+		if m := slackHeading.FindStringSubmatch(line); m != nil {
+			line = "**" + m[1] + "**"
+		}
+		line = slackBullet.ReplaceAllString(line, "$1• ")
+		lines[i] = quote + slackInline(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackInline translates the inline markdown of a line outside of `code` spans
+func slackInline(line string) string {
+	parts := strings.Split(line, "`")
+	for i := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = slackEscaper.Replace(parts[i]) // Inside a code span
+			continue
+		}
+		part := slackEscaper.Replace(parts[i])
+		part = slackLink.ReplaceAllString(part, "<$2|$1>")
+		part = slackEmphasis.ReplaceAllStringFunc(part, func(m string) string {
+			switch {
+			case strings.HasPrefix(m, "**"), strings.HasPrefix(m, "__"):
+				return "*" + m[2:len(m)-2] + "*"
+			default:
+				return "_" + m[1:len(m)-1] + "_"
+			}
+		})
+		parts[i] = slackStrike.ReplaceAllString(part, "~$1~")
+	}
+	return strings.Join(parts, "`")
+}
+
+// chunkShare packs blocks into chat messages of at most limit characters, separated by blank lines.
+// Blocks too long for a message are split between lines, and lines too long between characters.
+func chunkShare(blocks []string, limit int) []string {
+	var chunks []string
+	var current string
+	for _, block := range blocks {
+		if current != "" && utf8.RuneCountInString(current)+2+utf8.RuneCountInString(block) <= limit {
+			current += "\n\n" + block
+			continue
+		}
+		if current != "" {
+			chunks = append(chunks, current)
+		}
+		current = ""
+		if utf8.RuneCountInString(block) <= limit {
+			current = block
+			continue
+		}
+		pieces := splitBlock(block, limit)
+		chunks = append(chunks, pieces[:len(pieces)-1]...)
+		current = pieces[len(pieces)-1]
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitBlock splits a block longer than limit characters into pieces between lines. A code block open
+// at the end of a piece is closed there and reopened at the start of the next.
+func splitBlock(block string, limit int) []string {
+	const fence = "```"
+	// Room kept in each piece for closing and reopening a fence
+	room := limit - 2*(len(fence)+1)
+	var pieces []string
+	var current []string
+	size := 0
+	inCode := false
+	flush := func() {
+		piece := strings.Join(current, "\n")
+		if inCode {
+			piece += "\n" + fence
+		}
+		pieces = append(pieces, piece)
+		current, size = nil, 0
+		if inCode {
+			current, size = []string{fence}, len(fence)
+		}
+	}
+	for _, line := range strings.Split(block, "\n") {
+		for _, part := range splitRunes(line, room) {
+			n := utf8.RuneCountInString(part)
+			if len(current) > 0 && size+1+n > room {
+				flush()
+			}
+			if len(current) > 0 {
+				size++
+			}
+			current = append(current, part)
+			size += n
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			inCode = !inCode
+		}
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, strings.Join(current, "\n"))
+	}
+	return pieces
+}
+
+// splitRunes splits s into parts of at most n characters
+func splitRunes(s string, n int) []string {
+	if utf8.RuneCountInString(s) <= n {
+		return []string{s}
+	}
+	var parts []string
+	runes := []rune(s)
+	for len(runes) > n {
+		parts = append(parts, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return append(parts, string(runes))
+}
@@ -0,0 +1,86 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestFormatShareChunks(t *testing.T) {
+	opt := FormatOptions{Timezone: time.UTC}
+	got := JoinShareChunks(FormatShareChunks([]*types.ConversationLog{contextTestLog()}, FormatSlack, opt))
+	// The tool result is a user message without text, so the replies around it form one turn
+	want := "*Fix the failing test*\n\n" +
+		"*User* · 2025-07-06 05:00\nFix the failing test\n\n" +
+		"*Assistant* · 2025-07-06 05:00\nLet me run it.\n\nThe test is fixed now.\n\n" +
+		"*User* · 2025-07-06 05:00\nThanks\n"
+	if got != want {
+		t.Errorf("Unexpected slack output:\n%q\nwant:\n%q", got, want)
+	}
+
+	got = JoinShareChunks(FormatShareChunks([]*types.ConversationLog{contextTestLog()}, FormatDiscord, FormatOptions{OmitTimestamps: true}))
+	if !strings.HasPrefix(got, "**Fix the failing test**\n\n**User**\nFix the failing test\n\n**Assistant**\n") {
+		t.Errorf("Unexpected discord output:\n%s", got)
+	}
+}
+
+func TestSlackText(t *testing.T) {
+	tests := []struct {
+		name, markdown, want string
+	}{
+		{"emphasis", "**bold**, *italic* and ~~gone~~", "*bold*, _italic_ and ~gone~"},
+		{"link", "See [the docs](https://example.com/?a=1&b=2)", "See <https://example.com/?a=1&amp;b=2|the docs>"},
+		{"heading", "## Next steps", "*Next steps*"},
+		{"bullets", "- one\n  * two", "• one\n  • two"},
+		{"escaping", "if a < b && c > d", "if a &lt; b &amp;&amp; c &gt; d"},
+		{"quote", "> **quoted** <text>", "> *quoted* &lt;text&gt;"},
+		{"code span", "run `**not bold** <x>` now", "run `**not bold** &lt;x&gt;` now"},
+		{"code block", "```go\nx := *p ** 2\n```\n**after**", "```\nx := *p ** 2\n```\n*after*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackText(tt.markdown); got != tt.want {
+				t.Errorf("slackText(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkShare(t *testing.T) {
+	// Turns that fit are packed together
+	if got := chunkShare([]string{"aaaa", "bbbb", "cccc"}, 10); len(got) != 2 || got[0] != "aaaa\n\nbbbb" || got[1] != "cccc" {
+		t.Errorf("Unexpected chunks: %q", got)
+	}
+
+	// A long code block is split between lines, closing and reopening the fence
+	var lines []string
+	for range 30 {
+		lines = append(lines, strings.Repeat("x", 20))
+	}
+	block := "Here:\n```\n" + strings.Join(lines, "\n") + "\n```\nDone"
+	chunks := chunkShare([]string{block}, 100)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %q", chunks)
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 100 {
+			t.Errorf("Chunk %d has %d characters: %q", i, n, chunk)
+		}
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Errorf("Chunk %d leaves a code block open: %q", i, chunk)
+		}
+	}
+	if joined := strings.Join(chunks, "\n"); strings.Count(joined, strings.Repeat("x", 20)) != 30 || !strings.HasSuffix(joined, "Done") {
+		t.Errorf("Chunks lost content: %q", chunks)
+	}
+
+	// Lines longer than a message are cut
+	for _, chunk := range chunkShare([]string{strings.Repeat("é", 250)}, 100) {
+		if utf8.RuneCountInString(chunk) > 100 {
+			t.Errorf("Chunk too long: %d characters", utf8.RuneCountInString(chunk))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package formatter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// SortMessagesChronologically returns a copy of messages sorted by timestamp.
+// Messages without a timestamp (e.g. summary lines) keep their original position
+// relative to their neighbours instead of being sorted to the top as year-0001 entries.
+func SortMessagesChronologically(messages []types.Message) []types.Message {
+	keys := effectiveTimestamps(messages)
+
+	indices := make([]int, len(messages))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return keys[indices[i]].Before(keys[indices[j]])
+	})
+
+	sorted := make([]types.Message, len(messages))
+	for i, idx := range indices {
+		sorted[i] = messages[idx]
+	}
+	return sorted
+}
+
+// effectiveTimestamps returns a sort key for each message.
+// A zero timestamp inherits the timestamp of the closest preceding timestamped message,
+// or of the first following one when it appears before any timestamped message.
+func effectiveTimestamps(messages []types.Message) []time.Time {
+	keys := make([]time.Time, len(messages))
+
+	var last time.Time
+	for i, msg := range messages {
+		if !msg.Timestamp.IsZero() {
+			last = msg.Timestamp
+		}
+		keys[i] = last
+	}
+
+	// Backfill leading zero timestamps with the first known timestamp
+	var next time.Time
+	for i := len(messages) - 1; i >= 0; i-- {
+		if !messages[i].Timestamp.IsZero() {
+			next = messages[i].Timestamp
+		}
+		if keys[i].IsZero() {
+			keys[i] = next
+		}
+	}
+
+	return keys
+}
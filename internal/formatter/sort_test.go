@@ -0,0 +1,113 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestSortMessagesChronologically(t *testing.T) {
+	t1, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29Z")
+	t2, _ := time.Parse(time.RFC3339, "2025-07-06T05:02:29Z")
+	t3, _ := time.Parse(time.RFC3339, "2025-07-06T05:03:29Z")
+
+	tests := []struct {
+		name     string
+		messages []types.Message
+		want     []string
+	}{
+		{
+			name: "timestamped messages are sorted",
+			messages: []types.Message{
+				{UUID: "b", Timestamp: t2},
+				{UUID: "a", Timestamp: t1},
+				{UUID: "c", Timestamp: t3},
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "zero timestamp keeps position after its predecessor",
+			messages: []types.Message{
+				{UUID: "a", Timestamp: t1},
+				{UUID: "summary", Type: "summary"},
+				{UUID: "b", Timestamp: t2},
+			},
+			want: []string{"a", "summary", "b"},
+		},
+		{
+			name: "leading zero timestamp is not sorted to the top",
+			messages: []types.Message{
+				{UUID: "b", Timestamp: t2},
+				{UUID: "summary", Type: "summary"},
+				{UUID: "a", Timestamp: t1},
+			},
+			want: []string{"a", "b", "summary"},
+		},
+		{
+			name: "zero timestamp before any timestamped message stays first",
+			messages: []types.Message{
+				{UUID: "summary", Type: "summary"},
+				{UUID: "b", Timestamp: t2},
+				{UUID: "a", Timestamp: t1},
+			},
+			want: []string{"a", "summary", "b"},
+		},
+		{
+			name: "all zero timestamps keep file order",
+			messages: []types.Message{
+				{UUID: "x"},
+				{UUID: "y"},
+				{UUID: "z"},
+			},
+			want: []string{"x", "y", "z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := SortMessagesChronologically(tt.messages)
+			if len(sorted) != len(tt.want) {
+				t.Fatalf("Expected %d messages, got %d", len(tt.want), len(sorted))
+			}
+			for i, msg := range sorted {
+				if msg.UUID != tt.want[i] {
+					t.Errorf("Position %d: expected %s, got %s", i, tt.want[i], msg.UUID)
+				}
+			}
+		})
+	}
+}
+
+func TestSortMessagesChronologicallyDoesNotModifyInput(t *testing.T) {
+	t1, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29Z")
+	t2, _ := time.Parse(time.RFC3339, "2025-07-06T05:02:29Z")
+
+	messages := []types.Message{
+		{UUID: "b", Timestamp: t2},
+		{UUID: "a", Timestamp: t1},
+	}
+
+	SortMessagesChronologically(messages)
+
+	if messages[0].UUID != "b" || messages[1].UUID != "a" {
+		t.Error("Input slice should not be modified")
+	}
+}
+
+func TestFormatMessageOmitsZeroTimestamp(t *testing.T) {
+	msg := types.Message{
+		Type:    "user",
+		Message: &types.MessageBody{Role: "user", Content: types.TextContent("No timestamp here")},
+	}
+
+	result := formatMessage(msg, 1)
+
+	if strings.Contains(result, "**Time:**") {
+		t.Error("Message without timestamp should not render a Time line")
+	}
+	if strings.Contains(result, "0001-01-01") {
+		t.Error("Zero timestamp should not be rendered")
+	}
+}
@@ -1,6 +1,9 @@
 package formatter
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // GetSystemTimezone returns the system's local timezone
 // This uses time.Local which automatically handles:
@@ -10,3 +13,16 @@ import "time"
 func GetSystemTimezone() *time.Location {
 	return time.Local
 }
+
+// LoadTimezone looks up a timezone by IANA name (e.g. "UTC", "Asia/Tokyo").
+// An empty name returns nil, which means the system timezone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
@@ -2,8 +2,11 @@ package formatter
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
 )
 
 func TestGetSystemTimezone(t *testing.T) {
@@ -89,3 +92,45 @@ func TestGetSystemTimezoneDefaultBehavior(t *testing.T) {
 		t.Errorf("GetSystemTimezone() result should match time.Local behavior")
 	}
 }
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); err != nil || loc != nil {
+		t.Errorf("Empty name should mean the system timezone, got %v, %v", loc, err)
+	}
+	loc, err := LoadTimezone("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadTimezone failed: %v", err)
+	}
+	if loc.String() != "Asia/Tokyo" {
+		t.Errorf("Expected Asia/Tokyo, got %s", loc)
+	}
+	if _, err := LoadTimezone("Mars/Olympus"); err == nil {
+		t.Error("Expected error for an unknown timezone")
+	}
+}
+
+func TestFormatWithTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("Timezone database unavailable: %v", err)
+	}
+	log := &types.ConversationLog{Messages: []types.Message{{
+		Type:      "user",
+		Message:   &types.MessageBody{Role: "user", Content: types.TextContent("hello")},
+		Timestamp: time.Date(2025, 7, 6, 23, 30, 0, 0, time.UTC),
+	}}}
+
+	tests := []struct {
+		loc  *time.Location
+		want string
+	}{
+		{time.UTC, "**Time:** 2025-07-06 23:30:00"},
+		{tokyo, "**Time:** 2025-07-07 08:30:00"},
+	}
+	for _, tt := range tests {
+		markdown := FormatConversationToMarkdown(log, FormatOptions{Timezone: tt.loc})
+		if !strings.Contains(markdown, tt.want) {
+			t.Errorf("Expected %q in %s output:\n%s", tt.want, tt.loc, markdown)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// tocEntryMaxRunes limits the length of a table of contents entry
+const tocEntryMaxRunes = 80
+
+// tocEscaper escapes the characters that would end the text of a markdown link early
+var tocEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// isUserTurn reports whether msg is a prompt written by the user, which starts a turn of the conversation
+func isUserTurn(msg types.Message) bool {
+	return filter.MessageRole(msg) == filter.RoleUser && strings.TrimSpace(types.ExtractTextContent(msg.Message)) != ""
+}
+
+// turnAnchor returns the HTML anchor placed before the nth user turn (1-based)
+func turnAnchor(n int) string {
+	return fmt.Sprintf("<a id=\"turn-%d\"></a>\n\n", n)
+}
+
+// formatTableOfContents lists the user turns of a conversation, each linking to its anchor.
+// The first skip messages were left out of the output, so their turns are not listed,
+// though the rest keep their numbers. Returns "" for a conversation without user turns.
+func formatTableOfContents(messages []types.Message, skip int) string {
+	var sb strings.Builder
+	turn := 0
+	position := 0
+	for _, msg := range messages {
+		if msg.Type == "summary" {
+			continue // Not rendered, like in formatMessages
+		}
+		position++
+		if !isUserTurn(msg) {
+			continue
+		}
+		turn++
+		if position <= skip {
+			continue
+		}
+		text := truncateRunes(singleLine(types.ExtractTextContent(msg.Message)), tocEntryMaxRunes)
+		sb.WriteString(fmt.Sprintf("%d. [%s](#turn-%d)\n", turn, tocEscaper.Replace(text), turn))
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "## Contents\n\n" + sb.String() + "\n"
+}
@@ -0,0 +1,64 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func tocTestLog() *types.ConversationLog {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	return &types.ConversationLog{FilePath: "session.jsonl", Messages: []types.Message{
+		{Type: "user", Timestamp: ts, Message: &types.MessageBody{Role: "user", Content: types.TextContent("Fix the [flaky]\ntest")}},
+		{Type: "assistant", Timestamp: ts.Add(time.Second), Message: &types.MessageBody{Role: "assistant", Content: types.TextContent("Done.")}},
+		{Type: "user", Timestamp: ts.Add(2 * time.Second), Message: &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent("ok")})}},
+		{Type: "user", Timestamp: ts.Add(3 * time.Second), Message: &types.MessageBody{Role: "user", Content: types.TextContent(strings.Repeat("long ", 30))}},
+	}}
+}
+
+func TestFormatConversationTableOfContents(t *testing.T) {
+	got := FormatConversationToMarkdown(tocTestLog(), FormatOptions{Timezone: time.UTC, TableOfContents: true})
+
+	want := "## Contents\n\n1. [Fix the \\[flaky\\] test](#turn-1)\n2. [" + strings.Repeat("long ", 15) + "lo...](#turn-2)\n\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("Expected the table of contents\n%s\ngot:\n%s", want, got)
+	}
+	if !strings.Contains(got, "<a id=\"turn-1\"></a>\n\n### User") || !strings.Contains(got, "<a id=\"turn-2\"></a>\n\n### User") {
+		t.Errorf("Expected anchors before the user turns, got:\n%s", got)
+	}
+	if strings.Contains(got, "turn-3") {
+		t.Errorf("Expected tool results not to be listed, got:\n%s", got)
+	}
+	if strings.Index(got, "## Contents") > strings.Index(got, "### User") {
+		t.Errorf("Expected the table of contents before the messages, got:\n%s", got)
+	}
+
+	if plain := FormatConversationToMarkdown(tocTestLog(), FormatOptions{Timezone: time.UTC}); strings.Contains(plain, "Contents") || strings.Contains(plain, "<a id") {
+		t.Errorf("Expected no table of contents by default, got:\n%s", plain)
+	}
+}
+
+func TestFormatConversationTableOfContentsMaxTokens(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	var messages []types.Message
+	for i := 0; i < 10; i++ {
+		messages = append(messages, types.Message{
+			Type:      "user",
+			Timestamp: ts.Add(time.Duration(i) * time.Minute),
+			Message:   &types.MessageBody{Role: "user", Content: types.TextContent(fmt.Sprintf("Prompt %d %s", i+1, strings.Repeat("word ", 40)))},
+		})
+	}
+	got := FormatConversationToMarkdown(&types.ConversationLog{Messages: messages}, FormatOptions{Timezone: time.UTC, TableOfContents: true, MaxTokens: 200})
+
+	if strings.Contains(got, "(#turn-1)") || !strings.Contains(got, "10. [Prompt 10") {
+		t.Errorf("Expected only the kept turns to be listed with their numbers, got:\n%s", got)
+	}
+	for i := 1; i <= 10; i++ {
+		if strings.Contains(got, fmt.Sprintf("(#turn-%d)", i)) != strings.Contains(got, fmt.Sprintf("<a id=\"turn-%d\">", i)) {
+			t.Errorf("Expected turn %d to be listed exactly when its anchor is in the output", i)
+		}
+	}
+}
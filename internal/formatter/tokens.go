@@ -0,0 +1,62 @@
+package formatter
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// EstimateTokens approximates the number of tokens a language model tokenizer produces for text.
+// ASCII text averages about four characters per token, while other scripts, such as Japanese,
+// take about one token per character. The estimate errs on the high side.
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// fitTokens leaves out rendered messages from the start until the rest fits in about maxTokens,
+// and puts a note in their place saying how many were left out. The last message is always kept.
+// A maxTokens of 0 or less keeps every message. Also returns the number of messages left out.
+func fitTokens(rendered []string, maxTokens int) ([]string, int) {
+	if maxTokens <= 0 {
+		return rendered, 0
+	}
+
+	total := 0
+	for _, message := range rendered {
+		total += EstimateTokens(message)
+	}
+
+	omitted := 0
+	for omitted < len(rendered)-1 && total+EstimateTokens(omittedMessagesNote(omitted)) > maxTokens {
+		total -= EstimateTokens(rendered[omitted])
+		omitted++
+	}
+	if omitted == 0 {
+		return rendered, 0
+	}
+	return append([]string{omittedMessagesNote(omitted)}, rendered[omitted:]...), omitted
+}
+
+// remainingTokens returns the part of a token limit left after text, or 0 if there is no limit.
+// At least one token remains, so a limit stays in force however long text is.
+func remainingTokens(maxTokens int, text string) int {
+	if maxTokens <= 0 {
+		return 0
+	}
+	return max(1, maxTokens-EstimateTokens(text))
+}
+
+// omittedMessagesNote tells the reader of trimmed markdown how many earlier messages were left out
+func omittedMessagesNote(omitted int) string {
+	if omitted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("*%d earlier messages omitted to fit the token limit.*\n\n", omitted)
+}
@@ -0,0 +1,285 @@
+package formatter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// toolExcerptLines is the number of output lines shown by the built-in tool renderers
+	toolExcerptLines = 20
+	// toolExcerptRunes caps the length of single-line excerpts such as fetched web pages
+	toolExcerptRunes = 500
+)
+
+// ToolRenderer presents the input and result of one tool as markdown.
+// Either function may be nil, and returning "" from it falls back to the generic placeholder.
+type ToolRenderer struct {
+	// Use renders a tool_use block from the tool input
+	Use func(input map[string]interface{}) string
+	// Result renders a tool_result block from its text output and the toolUseResult metadata (nil if absent)
+	Result func(output string, metadata map[string]interface{}) string
+}
+
+// toolRenderers maps tool names to their renderers
+var toolRenderers = DefaultToolRenderers()
+
+// DefaultToolRenderers returns the built-in renderers for Claude Code's tools
+func DefaultToolRenderers() map[string]ToolRenderer {
+	return map[string]ToolRenderer{
+		"Bash":     {Use: renderBashUse, Result: renderBashResult},
+		"WebFetch": {Use: renderWebFetchUse, Result: renderWebFetchResult},
+		"Read":     {Use: renderReadUse, Result: renderReadResult},
+	}
+}
+
+// RegisterToolRenderer sets the renderer used for the tool with the given name, replacing any existing one.
+// It is not safe to call concurrently with formatting.
+func RegisterToolRenderer(name string, renderer ToolRenderer) {
+	toolRenderers[name] = renderer
+}
+
+// collectToolNames maps tool_use ids to tool names so that results can be rendered by their tool
+func collectToolNames(messages []types.Message) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range messages {
+		for _, block := range msg.Message.Blocks() {
+			if use, ok := block.(types.ToolUseBlock); ok && use.ID != "" && use.Name != "" {
+				names[use.ID] = use.Name
+			}
+		}
+	}
+	return names
+}
+
+// renderToolUse renders a tool_use block with its tool's renderer, or returns "" if there is none
+func renderToolUse(use types.ToolUseBlock) string {
+	renderer, ok := toolRenderers[use.Name]
+	if !ok || renderer.Use == nil {
+		return ""
+	}
+	return renderer.Use(use.Input)
+}
+
+// renderToolResult renders a tool_result block with the renderer of the tool that produced it,
+// or returns "" if the tool is unknown or has no result renderer
+func renderToolResult(result types.ToolResultBlock, toolNames map[string]string, toolUseResult interface{}) string {
+	renderer, ok := toolRenderers[toolNames[result.ToolUseID]]
+	if !ok || renderer.Result == nil {
+		return ""
+	}
+	if result.ToolUseResult != nil {
+		toolUseResult = result.ToolUseResult // Result moved next to its tool_use by pairToolResults
+	}
+	metadata, _ := toolUseResult.(map[string]interface{})
+	return renderer.Result(result.Content.PlainText(), metadata)
+}
+
+// pairToolResults moves each tool_result into the message of its tool_use, directly after it,
+// so that a call and its output read as one unit even though the log keeps them in separate messages.
+// Only tools with both a Use and a Result renderer are paired; other results stay where they are.
+// The toolUseResult metadata of a moved result travels with the block, and messages left without
+// content are dropped. The input messages are not modified.
+func pairToolResults(messages []types.Message) []types.Message {
+	// Message index of each pairable tool_use, by id
+	uses := make(map[string]int)
+	for i, msg := range messages {
+		for _, block := range msg.Message.Blocks() {
+			use, ok := block.(types.ToolUseBlock)
+			if !ok {
+				continue
+			}
+			if renderer, ok := toolRenderers[use.Name]; ok && use.ID != "" && renderer.Use != nil && renderer.Result != nil {
+				uses[use.ID] = i
+			}
+		}
+	}
+
+	// Results answering a tool_use of an earlier message, with their metadata attached
+	moved := make(map[string]types.ToolResultBlock)
+	for j, msg := range messages {
+		for _, block := range msg.Message.Blocks() {
+			result, ok := block.(types.ToolResultBlock)
+			if i, used := uses[result.ToolUseID]; !ok || !used || i >= j {
+				continue
+			}
+			if msg.ToolUseResult != nil {
+				result.ToolUseResult = msg.ToolUseResult
+			} else if msg.Message.ToolUseResult != nil {
+				result.ToolUseResult = msg.Message.ToolUseResult
+			}
+			moved[result.ToolUseID] = result
+		}
+	}
+	if len(moved) == 0 {
+		return messages
+	}
+
+	paired := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		changed := false
+		var content []types.ContentBlock
+		for _, block := range msg.Message.Blocks() {
+			switch b := block.(type) {
+			case types.ToolUseBlock:
+				content = append(content, b)
+				if result, ok := moved[b.ID]; ok {
+					content = append(content, result)
+					changed = true
+				}
+			case types.ToolResultBlock:
+				if _, ok := moved[b.ToolUseID]; ok {
+					changed = true
+					continue
+				}
+				content = append(content, b)
+			default:
+				content = append(content, b)
+			}
+		}
+		if !changed {
+			paired = append(paired, msg)
+			continue
+		}
+		if len(content) == 0 {
+			continue // Only held results that moved to their tool_use
+		}
+		body := *msg.Message
+		body.Content = types.BlockContent(content...)
+		msg.Message = &body
+		paired = append(paired, msg)
+	}
+	return paired
+}
+
+// renderBashUse shows the command in a shell code block, preceded by its description
+func renderBashUse(input map[string]interface{}) string {
+	command, _ := input["command"].(string)
+	if command == "" {
+		return ""
+	}
+	header := "**Bash**"
+	if description, _ := input["description"].(string); description != "" {
+		header += ": " + description
+	}
+	return header + "\n\n" + CodeBlock("bash", command)
+}
+
+// renderBashResult shows stdout and stderr, preferring the structured metadata over the raw output
+func renderBashResult(output string, metadata map[string]interface{}) string {
+	stdout, stderr := output, ""
+	if metadata != nil {
+		if s, ok := metadata["stdout"].(string); ok {
+			stdout = s
+		}
+		stderr, _ = metadata["stderr"].(string)
+	}
+
+	var parts []string
+	if strings.TrimSpace(stdout) != "" {
+		parts = append(parts, "**Output:**\n\n"+CodeBlock("", excerptLines(stdout, toolExcerptLines)))
+	}
+	if strings.TrimSpace(stderr) != "" {
+		parts = append(parts, "**Error output:**\n\n"+CodeBlock("", excerptLines(stderr, toolExcerptLines)))
+	}
+	if interrupted, _ := metadata["interrupted"].(bool); interrupted {
+		parts = append(parts, "*[Command interrupted]*")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderWebFetchUse shows the fetched URL and the prompt used to process it
+func renderWebFetchUse(input map[string]interface{}) string {
+	url, _ := input["url"].(string)
+	if url == "" {
+		return ""
+	}
+	result := fmt.Sprintf("**WebFetch:** <%s>", url)
+	if prompt, _ := input["prompt"].(string); prompt != "" {
+		result += "\n\n> " + singleLine(prompt)
+	}
+	return result
+}
+
+// renderWebFetchResult shows the HTTP status and an excerpt of the processed page
+func renderWebFetchResult(output string, metadata map[string]interface{}) string {
+	text := output
+	var status string
+	if metadata != nil {
+		if result, ok := metadata["result"].(string); ok && result != "" {
+			text = result
+		}
+		if code, ok := metadata["code"].(float64); ok {
+			status = fmt.Sprintf("%d", int(code))
+			if codeText, _ := metadata["codeText"].(string); codeText != "" {
+				status += " " + codeText
+			}
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	header := "**Fetched**"
+	if status != "" {
+		header += " (" + status + ")"
+	}
+	return header + "\n\n" + truncateRunes(excerptLines(text, toolExcerptLines), toolExcerptRunes)
+}
+
+// renderReadUse shows the file path and the requested line range
+func renderReadUse(input map[string]interface{}) string {
+	path, _ := input["file_path"].(string)
+	if path == "" {
+		return ""
+	}
+	result := fmt.Sprintf("**Read:** `%s`", path)
+	offset, hasOffset := input["offset"].(float64)
+	limit, hasLimit := input["limit"].(float64)
+	switch {
+	case hasOffset && hasLimit:
+		result += fmt.Sprintf(" (lines %d-%d)", int(offset), int(offset+limit)-1)
+	case hasOffset:
+		result += fmt.Sprintf(" (from line %d)", int(offset))
+	case hasLimit:
+		result += fmt.Sprintf(" (first %d lines)", int(limit))
+	}
+	return result
+}
+
+// renderReadResult shows a snippet of the file in a code block highlighted by its extension
+func renderReadResult(output string, metadata map[string]interface{}) string {
+	content, path := output, ""
+	if file, ok := metadata["file"].(map[string]interface{}); ok {
+		if c, ok := file["content"].(string); ok {
+			content = c
+		}
+		path, _ = file["filePath"].(string)
+	}
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+	language := strings.TrimPrefix(filepath.Ext(path), ".")
+	return CodeBlock(language, excerptLines(content, toolExcerptLines))
+}
+
+// CodeBlock wraps text in a fenced code block, lengthening the fence if the text contains one
+func CodeBlock(language, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+// excerptLines keeps the first n lines of text, noting how many were left out
+func excerptLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-n)
+}
@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// toolConversation builds an assistant tool_use message followed by the user message carrying its result
+func toolConversation(name string, input map[string]interface{}, output string, metadata map[string]interface{}) *types.ConversationLog {
+	return &types.ConversationLog{
+		Messages: []types.Message{
+			{
+				Type:    "assistant",
+				Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{ID: "toolu_1", Name: name, Input: input})},
+			},
+			{
+				Type:          "user",
+				Message:       &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "toolu_1", Content: types.TextContent(output)})},
+				ToolUseResult: metadata,
+			},
+		},
+	}
+}
+
+func TestToolRenderers(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     *types.ConversationLog
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "Bash shows command and output",
+			log: toolConversation("Bash",
+				map[string]interface{}{"command": "go test ./...", "description": "Run tests"},
+				"ok  pkg",
+				map[string]interface{}{"stdout": "ok  pkg", "stderr": "warning: cached", "interrupted": false}),
+			want:    []string{"**Bash**: Run tests", "```bash\ngo test ./...\n```", "**Output:**\n\n```\nok  pkg\n```", "**Error output:**\n\n```\nwarning: cached\n```"},
+			notWant: []string{"Tool used", "Tool operation completed"},
+		},
+		{
+			name: "WebFetch shows URL and excerpt",
+			log: toolConversation("WebFetch",
+				map[string]interface{}{"url": "https://example.com", "prompt": "Summarize the page"},
+				"",
+				map[string]interface{}{"code": float64(200), "codeText": "OK", "result": "Example Domain is for examples."}),
+			want: []string{"**WebFetch:** <https://example.com>", "> Summarize the page", "**Fetched** (200 OK)", "Example Domain is for examples."},
+		},
+		{
+			name: "Read shows path and snippet",
+			log: toolConversation("Read",
+				map[string]interface{}{"file_path": "/src/main.go", "offset": float64(10), "limit": float64(5)},
+				"",
+				map[string]interface{}{"type": "text", "file": map[string]interface{}{"filePath": "/src/main.go", "content": "package main\n"}}),
+			want: []string{"**Read:** `/src/main.go` (lines 10-14)", "```go\npackage main\n```"},
+		},
+		{
+			name: "unknown tool keeps placeholder",
+			log: toolConversation("Grep",
+				map[string]interface{}{"pattern": "TODO"},
+				"",
+				nil),
+			want: []string{"*[Tool used: Grep (no output)]*", "*[Tool operation completed (no output)]*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := FormatConversationToMarkdown(tt.log, FormatOptions{ShowPlaceholders: true})
+			for _, want := range tt.want {
+				if !strings.Contains(markdown, want) {
+					t.Errorf("Expected %q in:\n%s", want, markdown)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(markdown, notWant) {
+					t.Errorf("Did not expect %q in:\n%s", notWant, markdown)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterToolRenderer(t *testing.T) {
+	t.Cleanup(func() { toolRenderers = DefaultToolRenderers() })
+
+	RegisterToolRenderer("Grep", ToolRenderer{
+		Use: func(input map[string]interface{}) string {
+			return "Searched for `" + input["pattern"].(string) + "`"
+		},
+	})
+
+	log := toolConversation("Grep", map[string]interface{}{"pattern": "TODO"}, "", nil)
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true})
+	if !strings.Contains(markdown, "Searched for `TODO`") {
+		t.Errorf("Registered renderer should be used:\n%s", markdown)
+	}
+	// Without a Result function the generic placeholder is kept
+	if !strings.Contains(markdown, "*[Tool operation completed (no output)]*") {
+		t.Errorf("Missing result renderer should fall back to the placeholder:\n%s", markdown)
+	}
+}
+
+func TestCodeBlockLengthensFence(t *testing.T) {
+	got := CodeBlock("md", "```go\nx\n```")
+	if !strings.HasPrefix(got, "````md\n") || !strings.HasSuffix(got, "\n````") {
+		t.Errorf("Fence should be longer than the one in the content, got:\n%s", got)
+	}
+}
+
+func TestExcerptLines(t *testing.T) {
+	text := strings.Repeat("line\n", toolExcerptLines+3)
+	got := excerptLines(text, toolExcerptLines)
+	if !strings.HasSuffix(got, "... (3 more lines)") {
+		t.Errorf("Expected omitted line count, got:\n%s", got)
+	}
+}
+
+func TestPairToolResults(t *testing.T) {
+	log := toolConversation("Bash",
+		map[string]interface{}{"command": "go test ./..."},
+		"ok  pkg",
+		map[string]interface{}{"stdout": "ok  pkg"})
+	log.Messages = append(log.Messages, types.Message{
+		Type:    "assistant",
+		Message: &types.MessageBody{Role: "assistant", Content: types.TextContent("All tests pass.")},
+	})
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true, PairTools: true})
+	want := "### Assistant\n\n**Bash**\n\n```bash\ngo test ./...\n```\n**Output:**\n\n```\nok  pkg\n```\n\n\n### Assistant\n\nAll tests pass."
+	if !strings.Contains(markdown, want) {
+		t.Errorf("Expected the output right after the command:\n%s", markdown)
+	}
+	if strings.Contains(markdown, "### User") {
+		t.Errorf("The message that only held the result should be dropped:\n%s", markdown)
+	}
+
+	// The log itself is left untouched
+	if blocks := log.Messages[1].Message.Blocks(); len(blocks) != 1 || blocks[0].BlockType() != "tool_result" {
+		t.Error("Pairing should not modify the parsed messages")
+	}
+
+	// Tools without renderers are not paired
+	unknown := toolConversation("Grep", map[string]interface{}{"pattern": "TODO"}, "main.go", nil)
+	if paired := pairToolResults(unknown.Messages); len(paired) != 2 {
+		t.Errorf("Expected results of unknown tools to stay in place, got %d messages", len(paired))
+	}
+}
@@ -0,0 +1,156 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// toolSummaryInputRunes caps the length of the input shown for each tool call in a summary table
+const toolSummaryInputRunes = 60
+
+// toolSummaryInputKeys are the input fields that best describe a tool call, in order of preference
+var toolSummaryInputKeys = []string{"command", "file_path", "path", "url", "pattern", "query", "description", "prompt"}
+
+// exitCodePattern finds the exit code in the output of a failed command
+var exitCodePattern = regexp.MustCompile(`Exit code (\d+)`)
+
+// toolCall is a tool_use of an assistant turn and what is known about its result
+type toolCall struct {
+	id       string
+	name     string
+	input    string
+	started  time.Time
+	duration time.Duration // 0 if it cannot be derived
+	status   string        // "" until the result is seen
+	failed   bool
+}
+
+// toolSummary collects the tool calls of one assistant turn for the summary table written at its end
+type toolSummary struct {
+	calls []*toolCall
+	byID  map[string]*toolCall
+}
+
+// add records the tool calls and tool results of a message
+func (s *toolSummary) add(msg types.Message) {
+	for _, block := range msg.Message.Blocks() {
+		switch b := block.(type) {
+		case types.ToolUseBlock:
+			call := &toolCall{id: b.ID, name: b.Name, input: summarizeToolInput(b.Input), started: msg.Timestamp}
+			s.calls = append(s.calls, call)
+			if b.ID != "" {
+				if s.byID == nil {
+					s.byID = make(map[string]*toolCall)
+				}
+				s.byID[b.ID] = call
+			}
+		case types.ToolResultBlock:
+			call, ok := s.byID[b.ToolUseID]
+			if !ok {
+				continue
+			}
+			metadata, _ := msg.ToolUseResult.(map[string]interface{})
+			if attached, ok := b.ToolUseResult.(map[string]interface{}); ok {
+				metadata = attached // Result moved next to its tool_use by pairToolResults
+			}
+			call.duration = toolDuration(metadata, call.started, msg.Timestamp)
+			call.status, call.failed = toolStatus(b, metadata)
+		}
+	}
+}
+
+// flush returns the summary table of the calls collected so far, or "" if there were none,
+// and starts a new turn
+func (s *toolSummary) flush() string {
+	if len(s.calls) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	failed := 0
+	sb.WriteString("| Tool | Input | Duration | Status |\n| --- | --- | --- | --- |\n")
+	for _, call := range s.calls {
+		duration := ""
+		if call.duration > 0 {
+			duration = formatToolDuration(call.duration)
+		}
+		status := call.status
+		if status == "" {
+			status = "no result"
+		}
+		if call.failed {
+			failed++
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", tableCell(call.name), call.input, duration, tableCell(status)))
+	}
+	if failed > 0 {
+		sb.WriteString(fmt.Sprintf("\n> [!WARNING]\n> %d of %d tool calls failed\n", failed, len(s.calls)))
+	}
+	s.calls, s.byID = nil, nil
+	return sb.String() + "\n"
+}
+
+// summarizeToolInput picks the most telling input field of a tool call as a code span for a table cell
+func summarizeToolInput(input map[string]interface{}) string {
+	for _, key := range toolSummaryInputKeys {
+		if value, ok := input[key].(string); ok && strings.TrimSpace(value) != "" {
+			text := tableCell(truncateRunes(singleLine(value), toolSummaryInputRunes))
+			if strings.Contains(text, "`") {
+				return text
+			}
+			return "`" + text + "`"
+		}
+	}
+	return ""
+}
+
+// toolDuration takes the duration reported by the tool if any, or else the time between the call and its result
+func toolDuration(metadata map[string]interface{}, started, finished time.Time) time.Duration {
+	for _, key := range []string{"durationMs", "totalDurationMs"} {
+		if ms, ok := metadata[key].(float64); ok && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	if started.IsZero() || finished.IsZero() || !finished.After(started) {
+		return 0
+	}
+	return finished.Sub(started)
+}
+
+// toolStatus describes the outcome of a tool_result: the exit code of a failed command, the HTTP status
+// of a fetch, an interruption, or "ok". failed reports whether the call did not succeed.
+func toolStatus(result types.ToolResultBlock, metadata map[string]interface{}) (status string, failed bool) {
+	if interrupted, _ := metadata["interrupted"].(bool); interrupted {
+		return "interrupted", true
+	}
+	if result.IsError {
+		if match := exitCodePattern.FindStringSubmatch(result.Content.PlainText()); match != nil {
+			return "exit " + match[1], true
+		}
+		return "error", true
+	}
+	if code, ok := metadata["code"].(float64); ok {
+		status = fmt.Sprintf("HTTP %d", int(code))
+		return status, code >= 400
+	}
+	return "ok", false
+}
+
+// formatToolDuration rounds a duration for display, e.g. "850ms", "2.1s" or "1m5s"
+func formatToolDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// tableCell escapes text for a cell of a GitHub-flavored markdown table
+func tableCell(text string) string {
+	return strings.ReplaceAll(singleLine(text), "|", "\\|")
+}
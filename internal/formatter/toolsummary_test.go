@@ -0,0 +1,59 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestToolSummary(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	user := func(text string, at time.Duration) types.Message {
+		return types.Message{Type: "user", Timestamp: ts.Add(at), Message: &types.MessageBody{Role: "user", Content: types.TextContent(text)}}
+	}
+	use := func(id, name string, input map[string]interface{}, at time.Duration) types.Message {
+		return types.Message{Type: "assistant", Timestamp: ts.Add(at), Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{ID: id, Name: name, Input: input})}}
+	}
+	result := func(id, output string, isError bool, metadata interface{}, at time.Duration) types.Message {
+		return types.Message{Type: "user", Timestamp: ts.Add(at), ToolUseResult: metadata, Message: &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: id, Content: types.TextContent(output), IsError: isError})}}
+	}
+	log := &types.ConversationLog{Messages: []types.Message{
+		user("Fix the build", 0),
+		use("t1", "Bash", map[string]interface{}{"command": "go test ./... | tail"}, time.Second),
+		result("t1", "Error: Exit code 1", true, nil, 3*time.Second+100*time.Millisecond),
+		use("t2", "WebFetch", map[string]interface{}{"url": "https://example.com"}, 4*time.Second),
+		result("t2", "page", false, map[string]interface{}{"code": 200.0, "durationMs": 850.0}, 9*time.Second),
+		use("t3", "Read", map[string]interface{}{"file_path": "main.go"}, 10*time.Second),
+		user("Thanks", time.Minute),
+	}}
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true, ToolSummary: true})
+	want := "| Tool | Input | Duration | Status |\n| --- | --- | --- | --- |\n" +
+		"| Bash | `go test ./... \\| tail` | 2.1s | exit 1 |\n" +
+		"| WebFetch | `https://example.com` | 850ms | HTTP 200 |\n" +
+		"| Read | `main.go` |  | no result |\n" +
+		"\n> [!WARNING]\n> 1 of 3 tool calls failed\n\n### User"
+	if !strings.Contains(markdown, want) {
+		t.Errorf("Expected the summary of the first turn before the next prompt:\n%s", markdown)
+	}
+	if strings.Count(markdown, "| Tool |") != 1 {
+		t.Errorf("Expected one summary table, got:\n%s", markdown)
+	}
+
+	if markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true}); strings.Contains(markdown, "| Tool |") {
+		t.Errorf("Expected no summary by default:\n%s", markdown)
+	}
+}
+
+func TestToolSummaryAtEnd(t *testing.T) {
+	log := &types.ConversationLog{Messages: []types.Message{
+		{Type: "assistant", Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{ID: "t1", Name: "Grep", Input: map[string]interface{}{"pattern": "TODO"}})}},
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent("none")})}},
+	}}
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true, ToolSummary: true})
+	if !strings.Contains(markdown, "| Grep | `TODO` |  | ok |\n\n") || strings.Contains(markdown, "[!WARNING]") {
+		t.Errorf("Expected the summary of the last turn at the end:\n%s", markdown)
+	}
+}
@@ -0,0 +1,81 @@
+package formatter
+
+import (
+	"fmt"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// truncateToolOutputs returns a copy of message and toolUseResult in which tool_result text and
+// toolUseResult strings longer than max characters are cut short with a "[truncated X chars]" marker.
+// Other content, such as prompts and replies, is left as is. A max of 0 or less disables truncation.
+func truncateToolOutputs(message *types.MessageBody, toolUseResult interface{}, max int) (*types.MessageBody, interface{}) {
+	if max <= 0 {
+		return message, toolUseResult
+	}
+
+	if message != nil && message.Content.IsBlocks() {
+		content := make([]types.ContentBlock, len(message.Content.Blocks))
+		for i, block := range message.Content.Blocks {
+			content[i] = block
+			if result, ok := block.(types.ToolResultBlock); ok {
+				result.Content = truncateContent(result.Content, max)
+				result.ToolUseResult = truncateStrings(result.ToolUseResult, max)
+				content[i] = result
+			}
+		}
+		body := *message
+		body.Content = types.BlockContent(content...)
+		body.ToolUseResult = truncateStrings(body.ToolUseResult, max)
+		message = &body
+	}
+
+	return message, truncateStrings(toolUseResult, max)
+}
+
+// truncateContent returns a copy of the content of a tool result with its text cut to max characters
+func truncateContent(content types.Content, max int) types.Content {
+	if !content.IsBlocks() {
+		return types.TextContent(truncateToolOutput(content.Text, max))
+	}
+	blocks := make([]types.ContentBlock, len(content.Blocks))
+	for i, block := range content.Blocks {
+		if text, ok := block.(types.TextBlock); ok {
+			text.Text = truncateToolOutput(text.Text, max)
+			block = text
+		}
+		blocks[i] = block
+	}
+	return types.BlockContent(blocks...)
+}
+
+// truncateStrings returns a copy of a decoded JSON value with every string cut to max characters
+func truncateStrings(v interface{}, max int) interface{} {
+	switch v := v.(type) {
+	case string:
+		return truncateToolOutput(v, max)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = truncateStrings(item, max)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = truncateStrings(item, max)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// truncateToolOutput keeps the first max characters of s and notes how many were cut
+func truncateToolOutput(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + fmt.Sprintf("\n[truncated %d chars]", len(runes)-max)
+}
@@ -0,0 +1,41 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestTruncateToolOutput(t *testing.T) {
+	if got := truncateToolOutput("short", 10); got != "short" {
+		t.Errorf("Short output should be kept, got %q", got)
+	}
+	if got := truncateToolOutput("ééééé", 2); got != "éé\n[truncated 3 chars]" {
+		t.Errorf("Expected truncation by characters, got %q", got)
+	}
+}
+
+func TestFormatMaxToolOutput(t *testing.T) {
+	message := &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent(strings.Repeat("x", 100))})}
+	log := &types.ConversationLog{Messages: []types.Message{
+		{
+			Type:    "assistant",
+			Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{ID: "t1", Name: "Bash", Input: map[string]interface{}{"command": "make test"}})},
+		},
+		{Type: "user", Message: message, ToolUseResult: map[string]interface{}{"stdout": strings.Repeat("x", 100)}},
+	}}
+
+	markdown := FormatConversationToMarkdown(log, FormatOptions{ShowPlaceholders: true, MaxToolOutput: 30})
+	if !strings.Contains(markdown, strings.Repeat("x", 30)+"\n[truncated 70 chars]") {
+		t.Errorf("Expected the Bash output to be truncated:\n%s", markdown)
+	}
+	if strings.Contains(markdown, strings.Repeat("x", 31)) {
+		t.Errorf("Output beyond the limit should be dropped:\n%s", markdown)
+	}
+
+	// The log itself is left untouched
+	if content := message.Blocks()[0].(types.ToolResultBlock).Content.Text; content != strings.Repeat("x", 100) {
+		t.Error("Truncation should not modify the parsed message")
+	}
+}
@@ -0,0 +1,93 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// listMarkerPattern matches the marker of a list item, e.g. "- ", "* " or "12. ", after any indentation
+var listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+
+// blockMarkerPattern matches words that start a list item, quote or heading when they begin a line
+var blockMarkerPattern = regexp.MustCompile(`^(?:[-*+>]|#{1,6}|\d+[.)])$`)
+
+// quotePrefixPattern matches the blockquote markers at the start of a line, e.g. "> > "
+var quotePrefixPattern = regexp.MustCompile(`^(?:>\s?)+`)
+
+// wrapProse hard-wraps the prose lines of markdown content at width columns, breaking between words.
+// Fenced and indented code, tables, headings and HTML lines are left untouched. List items continue
+// under their text and quoted lines keep their quote markers. Words longer than width are not split.
+// A width of 0 or less leaves content unchanged.
+func wrapProse(content string, width int) string {
+	if width <= 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	wrapped := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			wrapped = append(wrapped, line)
+			continue
+		}
+		if inFence || ansi.StringWidth(line) <= width || !isProseLine(line) {
+			wrapped = append(wrapped, line)
+			continue
+		}
+		wrapped = append(wrapped, wrapLine(line, width)...)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// isProseLine reports whether a line is text that can be wrapped rather than code, a table row,
+// a heading or HTML
+func isProseLine(line string) bool {
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return false // Indented code block
+	}
+	text := strings.TrimSpace(quotePrefixPattern.ReplaceAllString(line, ""))
+	return !strings.HasPrefix(text, "|") && !strings.HasPrefix(text, "#") && !strings.HasPrefix(text, "<")
+}
+
+// wrapLine breaks a long prose line between words. The quote markers, indentation and list marker of
+// the line are kept on the first line; continuation lines repeat the quote markers and are indented
+// under the text. A trailing hard line break (two spaces) stays at the end of the last line.
+func wrapLine(line string, width int) []string {
+	first := quotePrefixPattern.FindString(line)
+	indent := first
+	rest := line[len(first):]
+	if marker := listMarkerPattern.FindString(rest); marker != "" {
+		first += marker
+		indent += strings.Repeat(" ", ansi.StringWidth(marker))
+		rest = rest[len(marker):]
+	} else {
+		lead := rest[:len(rest)-len(strings.TrimLeft(rest, " "))]
+		first += lead
+		indent += lead
+	}
+
+	var lines []string
+	current := first
+	empty := true // Whether current holds no word yet
+	for _, word := range strings.Fields(rest) {
+		// A word that would read as a list marker, quote or heading at the start of a line stays on this one
+		if !empty && ansi.StringWidth(current)+1+ansi.StringWidth(word) > width && !blockMarkerPattern.MatchString(word) {
+			lines = append(lines, current)
+			current, empty = indent, true
+		}
+		if !empty {
+			current += " "
+		}
+		current += word
+		empty = false
+	}
+	if strings.HasSuffix(line, "  ") {
+		current += "  "
+	}
+	return append(lines, current)
+}
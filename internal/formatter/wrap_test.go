@@ -0,0 +1,92 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestWrapProse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		width   int
+		want    string
+	}{
+		{
+			name:    "paragraph",
+			content: "The quick brown fox jumps over the lazy dog",
+			width:   16,
+			want:    "The quick brown\nfox jumps over\nthe lazy dog",
+		},
+		{
+			name:    "short lines unchanged",
+			content: "short\nlines",
+			width:   10,
+			want:    "short\nlines",
+		},
+		{
+			name:    "code fence untouched",
+			content: "```\nlet result = compute(first_argument, second_argument)\n```",
+			width:   20,
+			want:    "```\nlet result = compute(first_argument, second_argument)\n```",
+		},
+		{
+			name:    "indented code, table and heading untouched",
+			content: "    indented code that is long\n| a long | table row |\n## A heading that is long",
+			width:   10,
+			want:    "    indented code that is long\n| a long | table row |\n## A heading that is long",
+		},
+		{
+			name:    "list item continues under its text",
+			content: "- first item that wraps",
+			width:   12,
+			want:    "- first item\n  that wraps",
+		},
+		{
+			name:    "quote keeps its marker",
+			content: "> quoted text that wraps",
+			width:   13,
+			want:    "> quoted text\n> that wraps",
+		},
+		{
+			name:    "long words are not split",
+			content: "see https://example.com/a/very/long/path now",
+			width:   10,
+			want:    "see\nhttps://example.com/a/very/long/path\nnow",
+		},
+		{
+			name:    "markers do not start a line",
+			content: "one two - three",
+			width:   8,
+			want:    "one two -\nthree",
+		},
+		{
+			name:    "disabled",
+			content: "The quick brown fox",
+			width:   0,
+			want:    "The quick brown fox",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapProse(tt.content, tt.width); got != tt.want {
+				t.Errorf("wrapProse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatConversationWrap(t *testing.T) {
+	log := &types.ConversationLog{Messages: []types.Message{{
+		Type:    "user",
+		Message: &types.MessageBody{Role: "user", Content: types.TextContent(strings.Repeat("word ", 30))},
+	}}}
+	markdown := FormatConversationToMarkdown(log, FormatOptions{Wrap: 40})
+	for _, line := range strings.Split(markdown, "\n") {
+		if len(line) > 40 {
+			t.Errorf("Expected lines of at most 40 columns, got %q", line)
+		}
+	}
+}
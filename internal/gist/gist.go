@@ -0,0 +1,90 @@
+// Package gist creates GitHub gists, to share a converted session e.g. in a code review discussion.
+package gist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Settings of the GitHub API
+const (
+	apiURL     = "https://api.github.com/gists"
+	apiVersion = "2022-11-28"
+	// TokenEnv names the environment variable holding the GitHub token, which needs the gist scope
+	TokenEnv = "GITHUB_TOKEN"
+)
+
+// Client creates gists with the GitHub REST API
+type Client struct {
+	Token  string
+	URL    string       // Empty means apiURL
+	Client *http.Client // Nil means http.DefaultClient
+}
+
+// apiResponse holds the fields of a create gist response and error that cclog needs
+type apiResponse struct {
+	HTMLURL string `json:"html_url"`
+	Message string `json:"message"`
+}
+
+// Create creates a gist with a single file and returns its URL. Gists are secret, only reachable by
+// their URL, unless public is set.
+func (c Client) Create(ctx context.Context, description, name, content string, public bool) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"description": description,
+		"public":      public,
+		"files": map[string]map[string]string{
+			name: {"content": content},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	url := c.URL
+	if url == "" {
+		url = apiURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cclog")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("GitHub API request failed: %w", err)
+	}
+
+	var decoded apiResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", fmt.Errorf("GitHub API returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if resp.StatusCode != http.StatusCreated {
+		if decoded.Message != "" {
+			return "", fmt.Errorf("GitHub API returned %s: %s", resp.Status, decoded.Message)
+		}
+		return "", fmt.Errorf("GitHub API returned %s", resp.Status)
+	}
+	if decoded.HTMLURL == "" {
+		return "", fmt.Errorf("GitHub API returned no gist URL")
+	}
+	return decoded.HTMLURL, nil
+}
@@ -0,0 +1,48 @@
+package gist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer ghp_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"Bad credentials"}`)
+			return
+		}
+		var request struct {
+			Description string                       `json:"description"`
+			Public      bool                         `json:"public"`
+			Files       map[string]map[string]string `json:"files"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Description != "Fix the parser" ||
+			request.Public || request.Files["fix-the-parser.md"]["content"] != "# Conversation\n" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			io.WriteString(w, `{"message":"unexpected request"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url":"https://gist.github.com/test/abc123"}`)
+	}))
+	defer server.Close()
+
+	url, err := Client{Token: "ghp_test", URL: server.URL}.Create(context.Background(), "Fix the parser", "fix-the-parser.md", "# Conversation\n", false)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if url != "https://gist.github.com/test/abc123" {
+		t.Errorf("URL = %q", url)
+	}
+
+	_, err = Client{Token: "wrong", URL: server.URL}.Create(context.Background(), "", "a.md", "a", false)
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized: Bad credentials") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+}
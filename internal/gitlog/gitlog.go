@@ -0,0 +1,74 @@
+// Package gitlog reads the commits of a git repository made in a time range, to relate sessions to the
+// commits they produced.
+package gitlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logFormat separates the fields of each commit with the unit separator and ends it with the record separator
+const logFormat = "--format=%H%x1f%h%x1f%aI%x1f%s%x1e"
+
+// Commit is a commit of a repository
+type Commit struct {
+	Hash      string
+	ShortHash string
+	Time      time.Time // The author date, which rebases and amends keep
+	Subject   string
+}
+
+// Log returns the commits of all branches of the repository containing dir authored between since and
+// until, oldest first
+func Log(ctx context.Context, dir string, since, until time.Time) ([]Commit, error) {
+	// git filters on the committer date, which is never before the author date
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "log", "--all", "--since="+since.Format(time.RFC3339), logFormat)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git log failed in %s: %w\n%s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+	commits, err := parseLog(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	var inRange []Commit
+	for _, commit := range commits {
+		if !commit.Time.Before(since) && !commit.Time.After(until) {
+			inRange = append(inRange, commit)
+		}
+	}
+	sort.SliceStable(inRange, func(i, j int) bool { return inRange[i].Time.Before(inRange[j].Time) })
+	return inRange, nil
+}
+
+// parseLog parses the output of git log with logFormat
+func parseLog(output string) ([]Commit, error) {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected git log output %q", record)
+		}
+		at, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit date %q: %w", fields[2], err)
+		}
+		commits = append(commits, Commit{Hash: fields[0], ShortHash: fields[1], Time: at, Subject: fields[3]})
+	}
+	return commits, nil
+}
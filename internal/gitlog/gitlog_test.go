@@ -0,0 +1,67 @@
+package gitlog
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLog(t *testing.T) {
+	commits, err := parseLog("aaaa\x1fa1\x1f2025-07-06T14:03:00+09:00\x1fFix the parser\x1e\nbbbb\x1fb2\x1f2025-07-06T05:00:00Z\x1fAdd a\x1fsubject\x1e\n")
+	if err == nil {
+		t.Fatalf("Expected an error for a record with extra fields, got %+v", commits)
+	}
+
+	commits, err = parseLog("aaaa\x1fa1\x1f2025-07-06T14:03:00+09:00\x1fFix the parser\x1e\n\n")
+	if err != nil {
+		t.Fatalf("parseLog failed: %v", err)
+	}
+	want := Commit{Hash: "aaaa", ShortHash: "a1", Time: time.Date(2025, 7, 6, 5, 3, 0, 0, time.UTC), Subject: "Fix the parser"}
+	if len(commits) != 1 || commits[0].Hash != want.Hash || commits[0].ShortHash != want.ShortHash || !commits[0].Time.Equal(want.Time) || commits[0].Subject != want.Subject {
+		t.Errorf("parseLog() = %+v, want %+v", commits, want)
+	}
+}
+
+func TestLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	git("2025-07-06T05:00:00Z", "init", "-q")
+	for _, commit := range []struct{ date, subject string }{
+		{"2025-07-05T05:00:00Z", "Before"},
+		{"2025-07-06T05:03:00Z", "During"},
+		{"2025-07-06T05:10:00Z", "Also during"},
+		{"2025-07-07T05:00:00Z", "After"},
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "file"), []byte(commit.subject), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(commit.date, "add", "file")
+		git(commit.date, "commit", "-q", "-m", commit.subject)
+	}
+
+	since := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	commits, err := Log(context.Background(), dir, since, since.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "During" || commits[1].Subject != "Also during" || len(commits[0].ShortHash) < 7 {
+		t.Errorf("Unexpected commits %+v", commits)
+	}
+
+	if _, err := Log(context.Background(), t.TempDir(), since, since.Add(time.Hour)); err == nil {
+		t.Error("Expected an error outside a repository")
+	}
+}
@@ -0,0 +1,102 @@
+// Package hook runs the post-export hook, a shell command run after each file cclog writes, e.g. to push
+// exports to a wiki, upload them or trigger a sync. The file and its session are described to the
+// command in environment variables, never in the command line, since titles come from conversations.
+package hook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// DefaultTimeout bounds each run of a hook without a timeout setting
+const DefaultTimeout = 60 * time.Second
+
+// Events of the CCLOG_EVENT variable
+const (
+	EventNew     = "new"     // export: a session exported for the first time
+	EventUpdated = "updated" // export: a session exported again because it changed
+	EventRemoved = "removed" // sync: the file of a session gone from the input was deleted
+	EventWritten = "written" // conversions with -o or --split-output
+)
+
+// Hook is a shell command run after a file is written or, by sync, removed
+type Hook struct {
+	Command string
+	Timeout time.Duration // Zero means DefaultTimeout
+	Output  io.Writer     // Receives the output of the command; nil means os.Stderr, keeping stdout for markdown
+}
+
+// Event describes a written file to the hook
+type Event struct {
+	Event     string // EventNew, EventUpdated or EventWritten
+	Output    string // The written file
+	Input     string // The log or directory it was converted from
+	SessionID string
+	Project   string
+	Title     string
+}
+
+// SessionEvent describes the file output converted from the session log read from input
+func SessionEvent(event, output, input string, log *types.ConversationLog) Event {
+	return Event{
+		Event:     event,
+		Output:    output,
+		Input:     input,
+		SessionID: types.SessionID(log),
+		Project:   types.ProjectName(log),
+		Title:     types.ExtractTitle(filter.Current().FilterConversationLog(log, true)),
+	}
+}
+
+// Env returns the environment variables describing the event: CCLOG_EVENT, CCLOG_OUTPUT, CCLOG_INPUT,
+// CCLOG_SESSION_ID, CCLOG_PROJECT and CCLOG_TITLE
+func (e Event) Env() []string {
+	return []string{
+		"CCLOG_EVENT=" + e.Event,
+		"CCLOG_OUTPUT=" + e.Output,
+		"CCLOG_INPUT=" + e.Input,
+		"CCLOG_SESSION_ID=" + e.SessionID,
+		"CCLOG_PROJECT=" + e.Project,
+		"CCLOG_TITLE=" + e.Title,
+	}
+}
+
+// Run runs the command for event with the shell, stopping it after the timeout. A command that exits
+// with an error or times out is an error naming the file.
+func (h *Hook) Run(ctx context.Context, event Event) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output := h.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	cmd := shellCommand(ctx, h.Command)
+	cmd.Env = append(os.Environ(), event.Env()...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Processes the command started in the background must not keep Run waiting for their output
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("hook for %s timed out after %s", event.Output, timeout)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("hook for %s failed: %w", event.Output, err)
+	}
+}
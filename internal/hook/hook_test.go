@@ -0,0 +1,57 @@
+package hook
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// requireShell skips tests whose commands are written for sh
+func requireShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in these tests are written for sh")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+}
+
+func TestRunPassesEventInEnvironment(t *testing.T) {
+	requireShell(t)
+	var out strings.Builder
+	h := &Hook{Command: `echo "$CCLOG_EVENT|$CCLOG_OUTPUT|$CCLOG_INPUT|$CCLOG_SESSION_ID|$CCLOG_PROJECT|$CCLOG_TITLE"`, Output: &out}
+	event := Event{Event: EventNew, Output: "out/a.md", Input: "in/a.jsonl", SessionID: "s1", Project: "proj", Title: "Fix `rm -rf` $HOME; echo"}
+	if err := h.Run(context.Background(), event); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "new|out/a.md|in/a.jsonl|s1|proj|Fix `rm -rf` $HOME; echo\n"
+	if out.String() != want {
+		t.Errorf("Hook output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunReportsFailure(t *testing.T) {
+	requireShell(t)
+	h := &Hook{Command: "echo oops >&2; exit 3", Output: &strings.Builder{}}
+	err := h.Run(context.Background(), Event{Output: "a.md"})
+	if err == nil || !strings.Contains(err.Error(), "hook for a.md failed") || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Run error = %v, want the file and exit status", err)
+	}
+}
+
+func TestRunTimesOut(t *testing.T) {
+	requireShell(t)
+	h := &Hook{Command: "sleep 10", Timeout: 100 * time.Millisecond, Output: &strings.Builder{}}
+	start := time.Now()
+	err := h.Run(context.Background(), Event{Output: "a.md"})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Run error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run returned after %s, want it stopped at the timeout", elapsed)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package hook
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand runs command with the POSIX shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
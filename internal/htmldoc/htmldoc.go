@@ -0,0 +1,50 @@
+// Package htmldoc renders converted conversations as standalone HTML documents, for browsers and PDF printers.
+package htmldoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// style lays out the document for reading on screen and for printing, where each conversation
+// of a combined document starts on a new page
+const style = `body { font-family: sans-serif; font-size: 11pt; line-height: 1.5; margin: 0 auto; max-width: 48em; }
+pre { background: #f4f4f4; padding: 0.6em; white-space: pre-wrap; word-wrap: break-word; font-size: 9pt; }
+code { font-family: monospace; }
+h2 { page-break-before: always; }
+h2:first-of-type { page-break-before: avoid; }
+h3 { page-break-after: avoid; border-bottom: 1px solid #ddd; }
+img { max-width: 100%; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.5em; }
+`
+
+// policy keeps the markup cclog writes into markdown, such as message anchors, collapsed <details> blocks
+// and code block languages, and removes scripts, event handlers and styles that conversation content could bring in
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").OnElements("a")
+	p.AllowElements("details", "summary")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	return p
+}()
+
+// Render converts markdown to a standalone HTML document with the given title.
+// Raw HTML in the markdown is sanitized, so conversation content cannot inject scripts.
+func Render(markdown, title string) (string, error) {
+	var body bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM), goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()))
+	if err := md.Convert([]byte(markdown), &body); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+	sanitized := policy.SanitizeBytes(body.Bytes())
+	return fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n%s</body>\n</html>\n",
+		html.EscapeString(title), style, sanitized), nil
+}
@@ -0,0 +1,24 @@
+package htmldoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	markdown := "## Conversation\n\n<a id=\"msg-1a2b3c4d\"></a>\n\n**User:** <script>alert(1)</script><img src=\"x.png\" onerror=\"alert(2)\">\n\n" +
+		"```go\nfmt.Println()\n```\n\n<details>\n<summary>Show more</summary>\n\nhidden\n\n</details>\n"
+	got, err := Render(markdown, "a <b> title")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, want := range []string{"<title>a &lt;b&gt; title</title>", "<h2>Conversation</h2>", "<strong>User:</strong>", `<code class="language-go">`, "page-break-before",
+		`<a id="msg-1a2b3c4d">`, "<details>", "<summary>Show more</summary>", `<img src="x.png">`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected HTML to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<script>") || strings.Contains(got, "onerror") {
+		t.Errorf("Expected scripts to be removed, got:\n%s", got)
+	}
+}
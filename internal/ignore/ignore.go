@@ -0,0 +1,158 @@
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the ignore file read from the root of a scan
+const FileName = ".cclogignore"
+
+// rule is one pattern line of an ignore file
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool // Line started with "!": re-include matching paths
+	dirOnly bool // Line ended with "/": only match directories
+}
+
+// Matcher decides which paths below a scan root are ignored, using gitignore syntax.
+// A nil Matcher ignores nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// Load reads the ignore file in root. A missing file yields a nil Matcher.
+func Load(root string) (*Matcher, error) {
+	path := filepath.Join(root, FileName)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Parse(lines), nil
+}
+
+// Parse builds a Matcher from the lines of an ignore file. Blank lines and "#" comments are skipped.
+func Parse(lines []string) *Matcher {
+	m := &Matcher{}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r rule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // Escaped leading "#" or "!"
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns without a slash match at any depth; others are relative to the root
+		if strings.Contains(line, "/") {
+			line = strings.TrimPrefix(line, "/")
+		} else {
+			line = "**/" + line
+		}
+		pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue // Malformed character classes match nothing, like in git
+		}
+		r.pattern = pattern
+		m.rules = append(m.rules, r)
+	}
+	return m
+}
+
+// Match reports whether the path rel, relative to the scan root, is ignored.
+// Paths inside an ignored directory are ignored too, as in git.
+func (m *Matcher) Match(rel string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(rel, isDir)
+}
+
+// match applies the rules to a single path; the last matching rule wins
+func (m *Matcher) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.pattern.MatchString(rel) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates a gitignore glob into a regular expression.
+// "*" and "?" do not cross directories; "**" does.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
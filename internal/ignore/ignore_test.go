@@ -0,0 +1,72 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m := Parse([]string{
+		"# archived sessions",
+		"archive/",
+		"*.bak.jsonl",
+		"/experiments",
+		"scratch/**/tmp-*.jsonl",
+		"drafts/*",
+		"!drafts/keep.jsonl",
+		"",
+	})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"archive", true, true},
+		{"archive/old.jsonl", false, true},
+		{"project/archive/old.jsonl", false, true},
+		{"archive", false, false}, // Directory-only pattern
+		{"session.bak.jsonl", false, true},
+		{"project/session.bak.jsonl", false, true},
+		{"session.jsonl", false, false},
+		{"experiments/a.jsonl", false, true},
+		{"project/experiments/a.jsonl", false, false}, // Anchored to the root
+		{"scratch/tmp-1.jsonl", false, true},
+		{"scratch/a/b/tmp-2.jsonl", false, true},
+		{"scratch/a/keep.jsonl", false, false},
+		{"drafts/wip.jsonl", false, true},
+		{"drafts/keep.jsonl", false, false}, // Re-included by negation
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %t) = %t, want %t", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m *Matcher
+	if m.Match("anything.jsonl", false) {
+		t.Error("A nil matcher should ignore nothing")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil || m != nil {
+		t.Fatalf("Expected nil matcher without an ignore file, got %v, %v", m, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("old/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+	m, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !m.Match("old/a.jsonl", false) {
+		t.Error("Expected old/ to be ignored")
+	}
+}
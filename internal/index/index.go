@@ -0,0 +1,460 @@
+// Package index keeps an optional SQLite index of sessions for people with tens of thousands of them:
+// the title, project, timestamps and estimated tokens of each session, and the text of its messages
+// for full-text search. Entries are keyed by the absolute path of the log and refreshed when its size
+// or modification time or the filter rules change, so the index never serves stale data for a changed log.
+package index
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/settings"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+
+	_ "modernc.org/sqlite" // database/sql driver "sqlite"
+)
+
+// schemaVersion is stored in user_version; an index of another version is rebuilt from scratch
+const schemaVersion = 2
+
+// schema creates the tables. Messages are searched with the trigram tokenizer, which matches
+// substrings of at least three characters regardless of case, like the search command does.
+const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	path       TEXT PRIMARY KEY,
+	size       INTEGER NOT NULL,
+	mod_time   INTEGER NOT NULL,
+	session_id TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	project    TEXT NOT NULL,
+	messages   INTEGER NOT NULL,
+	tokens     INTEGER NOT NULL,
+	first_time INTEGER NOT NULL,
+	last_time  INTEGER NOT NULL,
+	error      TEXT NOT NULL,
+	rules      TEXT NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS messages USING fts5(
+	text, path UNINDEXED, role UNINDEXED, timestamp UNINDEXED, tokenize = 'trigram'
+);
+`
+
+// Index is an open session index
+type Index struct {
+	db *sql.DB
+}
+
+// Session is the indexed summary of a log
+type Session struct {
+	// Path is the path of the log as found under the root given to Sessions
+	Path      string
+	SessionID string
+	// Title is the title of the filtered conversation, "" if no message is left after filtering
+	Title   string
+	Project string
+	// Messages counts all messages; Tokens estimates the tokens of the messages kept by the filter rules
+	Messages   int
+	Tokens     int
+	FirstTime  time.Time
+	LastTime   time.Time
+	ModTime    time.Time
+	Size       int64
+	ParseError string // Set when the log could not be parsed
+}
+
+// Match is a message containing the text searched for
+type Match struct {
+	Path      string // As in Session
+	Role      string // The role of filter.MessageRole
+	Timestamp time.Time
+	Text      string
+}
+
+// Result counts what Sync did with the logs
+type Result struct {
+	Added, Updated, Removed, Unchanged int
+}
+
+// String summarizes the result, e.g. "3 added, 1 updated, 0 removed, 120 unchanged"
+func (r Result) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d removed, %d unchanged", r.Added, r.Updated, r.Removed, r.Unchanged)
+}
+
+// DefaultPath returns the location of the index, e.g. ~/.config/cclog/index.db
+func DefaultPath() (string, error) {
+	dir, err := settings.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "index.db"), nil
+}
+
+// OpenExisting opens the index at path if it exists. It returns nil without an error if there is none,
+// since the index is optional.
+func OpenExisting(path string) (*Index, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return Open(path)
+}
+
+// Open opens the index at path, creating it and its directory if needed
+func Open(path string) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1) // SQLite allows one writer; this also serializes readers of the TUI scans
+	ix := &Index{db: db}
+	if err := ix.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	return ix, nil
+}
+
+// migrate creates the tables, dropping those of an index written by another schema version
+func (ix *Index) migrate() error {
+	var version int
+	if err := ix.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version != schemaVersion {
+		if _, err := ix.db.Exec("DROP TABLE IF EXISTS sessions; DROP TABLE IF EXISTS messages"); err != nil {
+			return err
+		}
+	}
+	if _, err := ix.db.Exec(schema); err != nil {
+		return err
+	}
+	_, err := ix.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+	return err
+}
+
+// Close closes the index
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Clear removes the entries of the logs under root, so the next Sync indexes them again
+func (ix *Index) Clear(ctx context.Context, root string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	tx, err := ix.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	where, args := underRoot(absRoot)
+	for _, table := range []string{"sessions", "messages"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE "+where, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Sync brings the entries of the logs under root (a log file or a directory searched like export.FindSessions)
+// up to date: new and changed logs are parsed and indexed, and the entries of removed logs are dropped.
+// Titles and searchable messages follow the filter rules installed with filter.SetCurrent, and logs
+// indexed with other rules are indexed again.
+func (ix *Index) Sync(ctx context.Context, root string) (Result, error) {
+	var result Result
+	paths, _, err := export.FindSessions(ctx, root)
+	if err != nil {
+		return result, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return result, err
+	}
+
+	// Size, modification time and filter rules of the indexed logs under root
+	type stamp struct {
+		size, modTime int64
+		rules         string
+	}
+	indexed := make(map[string]stamp)
+	where, args := underRoot(absRoot)
+	rows, err := ix.db.QueryContext(ctx, "SELECT path, size, mod_time, rules FROM sessions WHERE "+where, args...)
+	if err != nil {
+		return result, err
+	}
+	for rows.Next() {
+		var path string
+		var s stamp
+		if err := rows.Scan(&path, &s.size, &s.modTime, &s.rules); err != nil {
+			rows.Close()
+			return result, err
+		}
+		indexed[path] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	tx, err := ix.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	rules := rulesFingerprint()
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return result, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Removed since it was found; its entry is dropped below
+		}
+		previous, known := indexed[abs]
+		delete(indexed, abs)
+		if known && previous.size == info.Size() && previous.modTime == info.ModTime().UnixNano() && previous.rules == rules {
+			result.Unchanged++
+			continue
+		}
+		if err := indexLog(ctx, tx, abs, info, rules); err != nil {
+			return result, err
+		}
+		if known {
+			result.Updated++
+		} else {
+			result.Added++
+		}
+	}
+
+	// What is left was not found again
+	for path := range indexed {
+		if err := deleteLog(ctx, tx, path); err != nil {
+			return result, err
+		}
+		result.Removed++
+	}
+	return result, tx.Commit()
+}
+
+// indexLog parses a log and replaces its entries, recording the fingerprint of the filter rules used.
+// Unparsable logs are indexed with their error.
+func indexLog(ctx context.Context, tx *sql.Tx, path string, info os.FileInfo, rules string) error {
+	if err := deleteLog(ctx, tx, path); err != nil {
+		return err
+	}
+	session := Session{Size: info.Size(), ModTime: info.ModTime()}
+	var kept []types.Message
+	log, err := parser.ParseJSONLFileContext(ctx, path)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		session.ParseError = err.Error()
+	} else {
+		filtered := filter.Current().FilterConversationLog(log, true)
+		kept = filtered.Messages
+		session.SessionID = types.SessionID(log)
+		if len(filtered.Messages) > 0 {
+			session.Title = types.ExtractTitle(filtered)
+		}
+		session.Project = types.ProjectName(log)
+		session.Messages = len(log.Messages)
+		for _, msg := range log.Messages {
+			if msg.Timestamp.IsZero() {
+				continue
+			}
+			if session.FirstTime.IsZero() || msg.Timestamp.Before(session.FirstTime) {
+				session.FirstTime = msg.Timestamp
+			}
+			if msg.Timestamp.After(session.LastTime) {
+				session.LastTime = msg.Timestamp
+			}
+		}
+	}
+
+	for _, msg := range kept {
+		text := types.ExtractTextContent(msg.Message)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		session.Tokens += formatter.EstimateTokens(text)
+		if _, err := tx.ExecContext(ctx, "INSERT INTO messages (text, path, role, timestamp) VALUES (?, ?, ?, ?)",
+			text, path, filter.MessageRole(msg), unixNano(msg.Timestamp)); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO sessions
+		(path, size, mod_time, session_id, title, project, messages, tokens, first_time, last_time, error, rules)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		path, session.Size, session.ModTime.UnixNano(), session.SessionID, session.Title, session.Project,
+		session.Messages, session.Tokens, unixNano(session.FirstTime), unixNano(session.LastTime), session.ParseError, rules)
+	return err
+}
+
+// rulesFingerprint identifies the filter rules installed with filter.SetCurrent, which decide the
+// indexed titles and messages
+func rulesFingerprint() string {
+	return strings.Join(filter.Current().Names(), "\x00")
+}
+
+// deleteLog removes the entries of a log
+func deleteLog(ctx context.Context, tx *sql.Tx, path string) error {
+	for _, table := range []string{"sessions", "messages"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE path = ?", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the entry of the log at path if it is indexed with this size and modification time
+// and the current filter rules, i.e. if the log has not changed since it was indexed
+func (ix *Index) Lookup(path string, size int64, modTime time.Time) (Session, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Session{}, false
+	}
+	rows, err := ix.db.Query(sessionQuery+" WHERE path = ? AND size = ? AND mod_time = ? AND rules = ?",
+		abs, size, modTime.UnixNano(), rulesFingerprint())
+	if err != nil {
+		return Session{}, false
+	}
+	sessions, err := scanSessions(rows, func(string) string { return path })
+	if err != nil || len(sessions) == 0 {
+		return Session{}, false
+	}
+	return sessions[0], true
+}
+
+// Sessions returns the indexed logs under root, newest first, with their paths as found under root
+func (ix *Index) Sessions(ctx context.Context, root string) ([]Session, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	where, args := underRoot(absRoot)
+	rows, err := ix.db.QueryContext(ctx, sessionQuery+" WHERE "+where+" ORDER BY mod_time DESC, path", args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSessions(rows, relativePath(root, absRoot))
+}
+
+// Search returns the indexed messages under root containing query, ignoring case, in the order of
+// their logs' paths and of the messages in each log. Queries of three characters or more use the
+// full-text index; shorter ones scan the message text.
+func (ix *Index) Search(ctx context.Context, root, query string) ([]Match, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	where, args := underRoot(absRoot)
+	if utf8.RuneCountInString(query) >= 3 {
+		where += " AND messages MATCH ?"
+		args = append(args, `"`+strings.ReplaceAll(query, `"`, `""`)+`"`)
+	} else {
+		where += ` AND text LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(query)+"%")
+	}
+	rows, err := ix.db.QueryContext(ctx, "SELECT path, role, timestamp, text FROM messages WHERE "+where+" ORDER BY path, rowid", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	display := relativePath(root, absRoot)
+	var matches []Match
+	for rows.Next() {
+		var match Match
+		var timestamp int64
+		if err := rows.Scan(&match.Path, &match.Role, &timestamp, &match.Text); err != nil {
+			return nil, err
+		}
+		match.Path = display(match.Path)
+		match.Timestamp = fromUnixNano(timestamp)
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// sessionQuery selects the columns read by scanSessions
+const sessionQuery = `SELECT path, size, mod_time, session_id, title, project, messages, tokens,
+	first_time, last_time, error FROM sessions`
+
+// scanSessions reads the rows of sessionQuery, turning the stored paths into displayed ones with display
+func scanSessions(rows *sql.Rows, display func(string) string) ([]Session, error) {
+	defer rows.Close()
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		var modTime, firstTime, lastTime int64
+		if err := rows.Scan(&s.Path, &s.Size, &modTime, &s.SessionID, &s.Title, &s.Project,
+			&s.Messages, &s.Tokens, &firstTime, &lastTime, &s.ParseError); err != nil {
+			return nil, err
+		}
+		s.Path = display(s.Path)
+		s.ModTime = time.Unix(0, modTime)
+		s.FirstTime = fromUnixNano(firstTime)
+		s.LastTime = fromUnixNano(lastTime)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// underRoot returns the condition selecting the paths of the logs at or under absRoot
+func underRoot(absRoot string) (string, []interface{}) {
+	prefix := strings.TrimSuffix(absRoot, string(filepath.Separator)) + string(filepath.Separator)
+	return `(path = ? OR path LIKE ? ESCAPE '\')`, []interface{}{absRoot, escapeLike(prefix) + "%"}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// relativePath returns a function turning indexed absolute paths into paths under root as given,
+// the way export.FindSessions returns them
+func relativePath(root, absRoot string) func(string) string {
+	return func(path string) string {
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil {
+			return path
+		}
+		if rel == "." {
+			return root
+		}
+		return filepath.Join(root, rel)
+	}
+}
+
+// unixNano stores a time, with 0 for the zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano reads a time stored by unixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,191 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+const (
+	helloLine = `{"type":"user","uuid":"u1","sessionId":"s1","cwd":"/home/me/webapp","timestamp":"2025-07-06T05:00:00Z","message":{"role":"user","content":"Fix the Login form"}}` + "\n"
+	replyLine = `{"type":"assistant","uuid":"u2","sessionId":"s1","timestamp":"2025-07-06T05:01:00Z","message":{"role":"assistant","content":[{"type":"text","text":"The login form is fixed"}]}}` + "\n"
+)
+
+func writeLog(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openTestIndex(t *testing.T) *Index {
+	t.Helper()
+	ix, err := Open(filepath.Join(t.TempDir(), "state", "index.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { ix.Close() })
+	return ix
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	first := filepath.Join(root, "webapp", "s1.jsonl")
+	second := filepath.Join(root, "webapp", "s2.jsonl")
+	writeLog(t, first, helloLine+replyLine)
+	writeLog(t, second, "not json\n{}\n")
+	ix := openTestIndex(t)
+
+	result, err := ix.Sync(ctx, root)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result != (Result{Added: 2}) {
+		t.Errorf("Expected 2 added logs, got %s", result)
+	}
+
+	sessions, err := ix.Sessions(ctx, root)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %+v, %v", sessions, err)
+	}
+	var indexed Session
+	for _, s := range sessions {
+		if s.Path == first {
+			indexed = s
+		} else if s.ParseError == "" {
+			t.Errorf("Expected a parse error for %s", s.Path)
+		}
+	}
+	if indexed.SessionID != "s1" || indexed.Title != "Fix the Login form" || indexed.Project != "webapp" || indexed.Messages != 2 || indexed.Tokens == 0 {
+		t.Errorf("Unexpected session %+v", indexed)
+	}
+	if !indexed.FirstTime.Equal(time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)) || !indexed.LastTime.Equal(time.Date(2025, 7, 6, 5, 1, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected times %v, %v", indexed.FirstTime, indexed.LastTime)
+	}
+
+	info, err := os.Stat(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Lookup(first, info.Size(), info.ModTime()); !ok {
+		t.Error("Expected Lookup to find the unchanged log")
+	}
+	if _, ok := ix.Lookup(first, info.Size()+1, info.ModTime()); ok {
+		t.Error("Expected Lookup to miss a changed log")
+	}
+
+	// Only the changed log is indexed again, and removed logs are dropped
+	writeLog(t, first, helloLine)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(first, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(second); err != nil {
+		t.Fatal(err)
+	}
+	result, err = ix.Sync(ctx, root)
+	if err != nil || result != (Result{Updated: 1, Removed: 1}) {
+		t.Errorf("Expected 1 updated and 1 removed log, got %s, %v", result, err)
+	}
+	result, err = ix.Sync(ctx, root)
+	if err != nil || result != (Result{Unchanged: 1}) {
+		t.Errorf("Expected 1 unchanged log, got %s, %v", result, err)
+	}
+
+	if err := ix.Clear(ctx, root); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if sessions, _ := ix.Sessions(ctx, root); len(sessions) != 0 {
+		t.Errorf("Expected no sessions after Clear, got %d", len(sessions))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	writeLog(t, filepath.Join(root, "webapp", "s1.jsonl"), helloLine+replyLine)
+	writeLog(t, filepath.Join(root, "other", "s2.jsonl"), strings.ReplaceAll(replyLine, "login", "signup"))
+	ix := openTestIndex(t)
+	if _, err := ix.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	for _, query := range []string{"LOGIN FORM", "in", `"`} {
+		matches, err := ix.Search(ctx, root, query)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		want := 2 // The user and assistant messages of s1
+		if query == `"` {
+			want = 0
+		}
+		if len(matches) != want {
+			t.Errorf("Search(%q): expected %d matches, got %+v", query, want, matches)
+		}
+	}
+
+	matches, _ := ix.Search(ctx, filepath.Join(root, "webapp"), "form")
+	if len(matches) != 2 || matches[0].Role != "user" || matches[1].Role != "assistant" || matches[0].Path != filepath.Join(root, "webapp", "s1.jsonl") {
+		t.Errorf("Expected both messages of s1 in order, got %+v", matches)
+	}
+}
+
+func TestOpenExisting(t *testing.T) {
+	ix, err := OpenExisting(filepath.Join(t.TempDir(), "index.db"))
+	if ix != nil || err != nil {
+		t.Errorf("Expected no index, got %v, %v", ix, err)
+	}
+}
+
+func TestSyncFilteredSession(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	path := filepath.Join(root, "meta.jsonl")
+	writeLog(t, path, `{"type":"user","uuid":"u1","isMeta":true,"timestamp":"2025-07-06T05:00:00Z","message":{"role":"user","content":"Caveat"}}`+"\n")
+	ix := openTestIndex(t)
+	if _, err := ix.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	sessions, err := ix.Sessions(ctx, root)
+	if err != nil || len(sessions) != 1 || sessions[0].Title != "" || sessions[0].Messages != 1 {
+		t.Errorf("Expected a session without a title, got %+v, %v", sessions, err)
+	}
+}
+
+func TestSyncFilterRulesChanged(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	path := filepath.Join(root, "meta.jsonl")
+	writeLog(t, path, `{"type":"user","uuid":"u1","isMeta":true,"timestamp":"2025-07-06T05:00:00Z","message":{"role":"user","content":"Caveat"}}`+"\n")
+	ix := openTestIndex(t)
+	if _, err := ix.Sync(ctx, root); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Entries indexed with other filter rules are stale
+	filter.SetCurrent(filter.Default().Without("meta"))
+	t.Cleanup(func() { filter.SetCurrent(filter.Default()) })
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Lookup(path, info.Size(), info.ModTime()); ok {
+		t.Error("Expected Lookup to miss an entry indexed with other filter rules")
+	}
+	result, err := ix.Sync(ctx, root)
+	if err != nil || result.Updated != 1 {
+		t.Fatalf("Expected the session to be indexed again, got %v, %v", result, err)
+	}
+	if session, ok := ix.Lookup(path, info.Size(), info.ModTime()); !ok || session.Tokens == 0 {
+		t.Errorf("Expected the tokens of the kept meta message, got %+v, %t", session, ok)
+	}
+}
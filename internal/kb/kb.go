@@ -0,0 +1,389 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/hook"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/redact"
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+const (
+	// SessionsDir is the directory inside the knowledge base that holds the exported sessions
+	SessionsDir = "sessions"
+	// ProjectsDir is the directory inside the knowledge base that holds the per-project index pages
+	ProjectsDir = "projects"
+	// IndexPage is the global chronological index
+	IndexPage = "index.md"
+	// TagsPage is the tag index
+	TagsPage = "tags.md"
+)
+
+// hashtagPattern matches #tags in user prompts. Tags start with a letter so issue numbers like #12 are not tags.
+var hashtagPattern = regexp.MustCompile(`(?:^|\s)#([A-Za-z][A-Za-z0-9_-]*)`)
+
+// Options controls a knowledge base build
+type Options struct {
+	InputPath       string // JSONL file or directory searched recursively for .jsonl files
+	OutputDir       string
+	EnableFiltering bool
+	Format          formatter.FormatOptions
+	Force           bool              // Re-export every session even if its content is unchanged
+	Tools           filter.ToolFilter // Selects the tools whose calls and results are exported
+	Roles           []string          // Exports only the messages of these roles (empty keeps all)
+	ExtractImages   bool              // Save pasted images next to the exported sessions
+	Redactor        *redact.Redactor  // Replaces secrets in sessions and index pages (nil disables redaction)
+	SummaryBlock    bool              // Start each exported session with its summary
+	Hook            *hook.Hook        // Run after each new or updated session (nil runs nothing)
+}
+
+// Result summarizes a knowledge base build
+type Result struct {
+	Export   *export.Result
+	Sessions int // Sessions listed in the indexes
+	Projects int
+	Tags     int
+}
+
+// Summary returns a one-line count of exported sessions and index entries
+func (r *Result) Summary() string {
+	return fmt.Sprintf("%s; indexed %d sessions in %d projects with %d tags",
+		r.Export.Summary(), r.Sessions, r.Projects, r.Tags)
+}
+
+// session is an exported session listed in the indexes
+type session struct {
+	title   string
+	project string
+	start   time.Time
+	link    string // Exported markdown path relative to the knowledge base root, with forward slashes
+	tags    []string
+}
+
+// Build exports every session under opts.InputPath into the sessions directory of opts.OutputDir,
+// then regenerates the global chronological index, one index page per project and the tag index.
+// When ctx is done, the indexes are left as they were and ctx.Err() is returned.
+func Build(ctx context.Context, opts Options) (*Result, error) {
+	exported, err := export.Run(ctx, export.Options{
+		InputPath:       opts.InputPath,
+		OutputDir:       filepath.Join(opts.OutputDir, SessionsDir),
+		EnableFiltering: opts.EnableFiltering,
+		Format:          opts.Format,
+		Force:           opts.Force,
+		Tools:           opts.Tools,
+		Roles:           opts.Roles,
+		ExtractImages:   opts.ExtractImages,
+		Redactor:        opts.Redactor,
+		SummaryBlock:    opts.SummaryBlock,
+		Hook:            opts.Hook,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := collectSessions(ctx, opts.InputPath, opts.Format.Location(), opts.Redactor.Clone())
+	if err != nil {
+		return nil, err
+	}
+
+	projects := groupByProject(sessions)
+	tags := groupByTag(sessions)
+
+	// Project pages are regenerated from scratch so pages of removed projects don't linger
+	projectsDir := filepath.Join(opts.OutputDir, ProjectsDir)
+	if err := os.RemoveAll(projectsDir); err != nil {
+		return nil, fmt.Errorf("failed to clear project pages: %w", err)
+	}
+	if err := os.MkdirAll(projectsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project directory: %w", err)
+	}
+
+	slugs := projectSlugs(projects)
+	for name, list := range projects {
+		path, err := safepath.Join(projectsDir, slugs[name]+".md")
+		if err != nil {
+			return nil, err
+		}
+		if err := writePage(path, renderProjectPage(name, list)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writePage(filepath.Join(opts.OutputDir, IndexPage), renderIndexPage(sessions, projects, slugs)); err != nil {
+		return nil, err
+	}
+	if err := writePage(filepath.Join(opts.OutputDir, TagsPage), renderTagsPage(tags, slugs)); err != nil {
+		return nil, err
+	}
+
+	return &Result{Export: exported, Sessions: len(sessions), Projects: len(projects), Tags: len(tags)}, nil
+}
+
+// collectSessions reads the index metadata of every session, newest first, with start times in loc.
+// Titles and tags are taken after redaction when redactor is set; pass a clone so the
+// replacements already counted by the export are not counted twice.
+// Sessions that cannot be parsed are left out; the export already reports them.
+func collectSessions(ctx context.Context, inputPath string, loc *time.Location, redactor *redact.Redactor) ([]session, error) {
+	paths, root, err := export.FindSessions(ctx, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []session
+	for _, path := range paths {
+		log, err := parser.ParseJSONLFileContext(ctx, path)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		if redactor != nil {
+			redactor.Log(log)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = filepath.Base(path)
+		}
+		rel = filepath.ToSlash(rel)
+
+		sessions = append(sessions, session{
+			title:   types.ExtractTitle(log),
+			project: projectName(log, rel),
+			start:   startTime(log).In(loc),
+			link:    SessionsDir + "/" + export.MarkdownPath(rel),
+			tags:    extractTags(log),
+		})
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].start.After(sessions[j].start)
+	})
+	return sessions, nil
+}
+
+// projectName names a session's project after its working directory,
+// falling back to the directory the session file is in
+func projectName(log *types.ConversationLog, rel string) string {
+	for _, msg := range log.Messages {
+		if msg.CWD != "" && msg.CWD != "/" {
+			return filepath.Base(filepath.Clean(msg.CWD))
+		}
+	}
+	if dir := filepath.Base(filepath.Dir(filepath.FromSlash(rel))); dir != "." {
+		return dir
+	}
+	return "other"
+}
+
+// startTime returns the timestamp of the first message that has one
+func startTime(log *types.ConversationLog) time.Time {
+	for _, msg := range log.Messages {
+		if !msg.Timestamp.IsZero() {
+			return msg.Timestamp
+		}
+	}
+	return time.Time{}
+}
+
+// extractTags returns the sorted, lowercased #tags written in user prompts outside of code blocks
+func extractTags(log *types.ConversationLog) []string {
+	seen := make(map[string]bool)
+	for _, msg := range log.Messages {
+		if msg.Type != "user" || msg.IsMeta {
+			continue
+		}
+		inCode := false
+		for _, line := range strings.Split(types.ExtractTextContent(msg.Message), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				inCode = !inCode
+				continue
+			}
+			if inCode {
+				continue
+			}
+			for _, match := range hashtagPattern.FindAllStringSubmatch(line, -1) {
+				seen[strings.ToLower(match[1])] = true
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// groupByProject lists the sessions of each project, keeping their order
+func groupByProject(sessions []session) map[string][]session {
+	projects := make(map[string][]session)
+	for _, s := range sessions {
+		projects[s.project] = append(projects[s.project], s)
+	}
+	return projects
+}
+
+// groupByTag lists the sessions carrying each tag, keeping their order
+func groupByTag(sessions []session) map[string][]session {
+	tags := make(map[string][]session)
+	for _, s := range sessions {
+		for _, tag := range s.tags {
+			tags[tag] = append(tags[tag], s)
+		}
+	}
+	return tags
+}
+
+// projectSlugs assigns each project a unique file name, numbering projects whose names only differ in case or punctuation
+func projectSlugs(projects map[string][]session) map[string]string {
+	slugs := make(map[string]string)
+	used := make(map[string]bool)
+	for _, name := range sortedKeys(projects) {
+		base := slugify(name)
+		slug := base
+		for n := 2; used[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[slug] = true
+		slugs[name] = slug
+	}
+	return slugs
+}
+
+// slugify turns a project name into a file name
+func slugify(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	slug := strings.Trim(sb.String(), "-.")
+	if slug == "" {
+		return "project"
+	}
+	return slug
+}
+
+// renderIndexPage renders the project list and every session grouped by month, newest first
+func renderIndexPage(sessions []session, projects map[string][]session, slugs map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("# Knowledge Base\n\n")
+	sb.WriteString(fmt.Sprintf("[Tags](%s)\n\n", TagsPage))
+
+	sb.WriteString("## Projects\n\n")
+	for _, name := range sortedKeys(projects) {
+		sb.WriteString(fmt.Sprintf("- [%s](%s/%s.md) (%d sessions)\n", escapeLinkText(name), ProjectsDir, slugs[name], len(projects[name])))
+	}
+
+	sb.WriteString("\n## Sessions\n")
+	month := ""
+	for _, s := range sessions {
+		if m := sessionMonth(s); m != month {
+			month = m
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", month))
+		}
+		sb.WriteString(fmt.Sprintf("- %s · [%s](%s/%s.md)%s\n",
+			sessionLink(s, ""), escapeLinkText(s.project), ProjectsDir, slugs[s.project], tagLinks(s.tags, "")))
+	}
+	return sb.String()
+}
+
+// renderProjectPage renders the sessions of one project, newest first
+func renderProjectPage(name string, sessions []session) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", name))
+	sb.WriteString(fmt.Sprintf("[Index](../%s) · [Tags](../%s)\n\n", IndexPage, TagsPage))
+	for _, s := range sessions {
+		sb.WriteString(fmt.Sprintf("- %s%s\n", sessionLink(s, "../"), tagLinks(s.tags, "../")))
+	}
+	return sb.String()
+}
+
+// renderTagsPage renders one section per tag listing its sessions, newest first
+func renderTagsPage(tags map[string][]session, slugs map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("# Tags\n\n")
+	sb.WriteString(fmt.Sprintf("[Index](%s)\n", IndexPage))
+	if len(tags) == 0 {
+		sb.WriteString("\nNo tags yet. Write #tags in your prompts to list sessions here.\n")
+		return sb.String()
+	}
+
+	for _, tag := range sortedKeys(tags) {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", tag))
+		for _, s := range tags[tag] {
+			sb.WriteString(fmt.Sprintf("- %s · [%s](%s/%s.md)\n",
+				sessionLink(s, ""), escapeLinkText(s.project), ProjectsDir, slugs[s.project]))
+		}
+	}
+	return sb.String()
+}
+
+// sessionLink renders the start time and a link to the exported session. prefix leads from the page to the root.
+func sessionLink(s session, prefix string) string {
+	when := "(no date)"
+	if !s.start.IsZero() {
+		when = s.start.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("%s [%s](%s%s)", when, escapeLinkText(s.title), prefix, s.link)
+}
+
+// sessionMonth returns the month heading a session is listed under in the global index
+func sessionMonth(s session) string {
+	if s.start.IsZero() {
+		return "Undated"
+	}
+	return s.start.Format("2006-01")
+}
+
+// tagLinks renders links to the tag index sections of tags, led by a separator, or "" if there are none
+func tagLinks(tags []string, prefix string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	links := make([]string, len(tags))
+	for i, tag := range tags {
+		links[i] = fmt.Sprintf("[#%s](%s%s#%s)", tag, prefix, TagsPage, tag)
+	}
+	return " · " + strings.Join(links, " ")
+}
+
+// escapeLinkText escapes the characters that would end markdown link text early
+func escapeLinkText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(text)
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys(m map[string][]session) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writePage writes a generated index page
+func writePage(path, content string) error {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
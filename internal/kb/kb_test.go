@@ -0,0 +1,148 @@
+package kb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+}
+
+func readPage(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected page %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func sessionJSONL(cwd, prompt, timestamp string) string {
+	return `{"type":"user","cwd":"` + cwd + `","message":{"role":"user","content":"` + prompt + `"},"uuid":"u1","timestamp":"` + timestamp + `"}
+{"type":"assistant","cwd":"` + cwd + `","message":{"role":"assistant","content":[{"type":"text","text":"done"}]},"uuid":"u2","timestamp":"` + timestamp + `"}
+`
+}
+
+func TestBuild(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	writeSession(t, filepath.Join(input, "-home-me-alpha", "one.jsonl"),
+		sessionJSONL("/home/me/alpha", "Fix the parser #bug #Parser", "2025-06-01T10:00:00Z"))
+	writeSession(t, filepath.Join(input, "-home-me-alpha", "two.jsonl"),
+		sessionJSONL("/home/me/alpha", "Add a flag for issue #12", "2025-07-02T10:00:00Z"))
+	writeSession(t, filepath.Join(input, "-home-me-beta", "three.jsonl"),
+		sessionJSONL("/home/me/beta", "Crash on start #bug", "2025-07-03T10:00:00Z"))
+
+	result, err := Build(context.Background(), Options{InputPath: input, OutputDir: output, EnableFiltering: true})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if got := result.Summary(); got != "3 new, 0 updated, 0 skipped; indexed 3 sessions in 2 projects with 2 tags" {
+		t.Errorf("Summary = %q", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(output, "sessions", "-home-me-alpha", "one.md")); err != nil {
+		t.Errorf("Expected exported session: %v", err)
+	}
+
+	index := readPage(t, filepath.Join(output, "index.md"))
+	for _, want := range []string{
+		"[alpha](projects/alpha.md) (2 sessions)",
+		"[beta](projects/beta.md) (1 sessions)",
+		"[Crash on start #bug](sessions/-home-me-beta/three.md)",
+		"[#bug](tags.md#bug) [#parser](tags.md#parser)",
+		"[Tags](tags.md)",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("Index is missing %q:\n%s", want, index)
+		}
+	}
+	// Newest sessions come first, grouped by month
+	if strings.Index(index, "three.md") > strings.Index(index, "one.md") {
+		t.Errorf("Expected newest session first:\n%s", index)
+	}
+	if !strings.Contains(index, "### 2025-06") {
+		t.Errorf("Expected month headings:\n%s", index)
+	}
+
+	alpha := readPage(t, filepath.Join(output, "projects", "alpha.md"))
+	for _, want := range []string{"# alpha", "[Index](../index.md)", "(../sessions/-home-me-alpha/two.md)", "[#bug](../tags.md#bug)"} {
+		if !strings.Contains(alpha, want) {
+			t.Errorf("Project page is missing %q:\n%s", want, alpha)
+		}
+	}
+	if strings.Contains(alpha, "three.md") {
+		t.Errorf("Project page lists another project's session:\n%s", alpha)
+	}
+
+	tags := readPage(t, filepath.Join(output, "tags.md"))
+	bug := tags[strings.Index(tags, "## bug"):strings.Index(tags, "## parser")]
+	if !strings.Contains(bug, "one.md") || !strings.Contains(bug, "three.md") || strings.Contains(bug, "two.md") {
+		t.Errorf("Unexpected bug tag section:\n%s", bug)
+	}
+	if strings.Contains(tags, "## 12") {
+		t.Errorf("Issue numbers should not become tags:\n%s", tags)
+	}
+}
+
+func TestBuildRemovesStaleProjectPages(t *testing.T) {
+	input := t.TempDir()
+	output := t.TempDir()
+	path := filepath.Join(input, "one.jsonl")
+	writeSession(t, path, sessionJSONL("/work/old", "hello", "2025-07-01T10:00:00Z"))
+	if _, err := Build(context.Background(), Options{InputPath: input, OutputDir: output}); err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+
+	writeSession(t, path, sessionJSONL("/work/new", "hello", "2025-07-01T10:00:00Z"))
+	if _, err := Build(context.Background(), Options{InputPath: input, OutputDir: output}); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "projects", "old.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected stale project page to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "projects", "new.md")); err != nil {
+		t.Errorf("Expected new project page: %v", err)
+	}
+}
+
+func TestExtractTagsSkipsCodeBlocks(t *testing.T) {
+	input := t.TempDir()
+	path := filepath.Join(input, "one.jsonl")
+	writeSession(t, path, sessionJSONL("/work/app", "Style this #css\\n```\\ncolor: #fff;\\n```", "2025-07-01T10:00:00Z"))
+
+	sessions, err := collectSessions(context.Background(), path, time.UTC, nil)
+	if err != nil {
+		t.Fatalf("collectSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || strings.Join(sessions[0].tags, ",") != "css" {
+		t.Errorf("Expected only the css tag, got %+v", sessions)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"cclog":        "cclog",
+		"My Project":   "my-project",
+		"api.v2":       "api.v2",
+		"日本語":          "project",
+		"--weird--":    "weird",
+		"Mixed_Case-1": "mixed_case-1",
+	}
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package metrics exposes the usage recorded in the logs as Prometheus metrics: sessions by project,
+// messages by project and role, and tokens by project, day and kind, for graphing Claude usage over time.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/parallel"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Collector scans the logs under Root for each scrape. Parsed logs are kept in memory and only parsed
+// again when their size or modification time changes, so scrapes of an unchanged directory are cheap.
+type Collector struct {
+	Root     string
+	Project  string         // Only count the sessions of matching projects (see types.MatchProject); empty counts all
+	Location *time.Location // Days of the token metrics are dates in this location
+
+	mu    sync.Mutex
+	files map[string]fileStats
+}
+
+// fileStats is what a log contributes to the metrics
+type fileStats struct {
+	size     int64
+	modTime  time.Time
+	project  string
+	failed   bool                   // The log could not be parsed
+	messages map[string]int         // By role (see filter.MessageRole)
+	tokens   map[string]types.Usage // By date
+}
+
+// tokenKinds names the kinds of tokens of a Usage in the kind label
+var tokenKinds = []struct {
+	name  string
+	count func(types.Usage) int
+}{
+	{"input", func(u types.Usage) int { return u.InputTokens }},
+	{"output", func(u types.Usage) int { return u.OutputTokens }},
+	{"cache_creation", func(u types.Usage) int { return u.CacheCreationInputTokens }},
+	{"cache_read", func(u types.Usage) int { return u.CacheReadInputTokens }},
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var sb strings.Builder
+	if err := c.Write(r.Context(), &sb); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	io.WriteString(w, sb.String())
+}
+
+// Write scans the logs, parsing those that changed since the last call, and writes the metrics to w
+func (c *Collector) Write(ctx context.Context, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+	paths, _, err := export.FindSessions(ctx, c.Root)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]fileStats, len(paths))
+	var changed []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Removed since the walk
+		}
+		if stats, ok := c.files[path]; ok && stats.size == info.Size() && stats.modTime.Equal(info.ModTime()) {
+			files[path] = stats
+			continue
+		}
+		changed = append(changed, path)
+	}
+	parsed, err := parallel.MapContext(ctx, changed, 0, func(path string) fileStats {
+		return c.parse(ctx, path)
+	})
+	if err != nil {
+		return err
+	}
+	for i, path := range changed {
+		files[path] = parsed[i]
+	}
+	c.files = files
+
+	writeMetrics(w, c.aggregate(), time.Since(start))
+	return nil
+}
+
+// parse reads a log into its contribution to the metrics
+func (c *Collector) parse(ctx context.Context, path string) fileStats {
+	var stats fileStats
+	if info, err := os.Stat(path); err == nil {
+		stats.size, stats.modTime = info.Size(), info.ModTime()
+	}
+	log, err := parser.ParseJSONLFileContext(ctx, path)
+	if err != nil {
+		stats.failed = true
+		return stats
+	}
+	stats.project = types.ProjectName(log)
+	stats.messages = make(map[string]int)
+	stats.tokens = make(map[string]types.Usage)
+	byDate := make(map[string][]types.Message)
+	for _, msg := range log.Messages {
+		stats.messages[filter.MessageRole(msg)]++
+		if msg.Message != nil && msg.Message.Usage != nil && !msg.Timestamp.IsZero() {
+			date := msg.Timestamp.In(c.location()).Format("2006-01-02")
+			byDate[date] = append(byDate[date], msg)
+		}
+	}
+	for date, messages := range byDate {
+		stats.tokens[date] = types.SumUsage(messages)
+	}
+	return stats
+}
+
+// location returns the location of the days of the token metrics
+func (c *Collector) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// totals are the metrics summed over the logs
+type totals struct {
+	sessions map[string]int            // By project
+	failed   int                       // Logs that could not be parsed
+	messages map[[2]string]int         // By project and role
+	tokens   map[[2]string]types.Usage // By project and date
+	logs     int                       // Logs found, including other projects
+}
+
+// aggregate sums the metrics of the logs of the selected projects
+func (c *Collector) aggregate() totals {
+	t := totals{
+		sessions: make(map[string]int),
+		messages: make(map[[2]string]int),
+		tokens:   make(map[[2]string]types.Usage),
+	}
+	for _, stats := range c.files {
+		t.logs++
+		if stats.failed {
+			t.failed++
+			continue
+		}
+		if !types.MatchProject(c.Project, stats.project) {
+			continue
+		}
+		t.sessions[stats.project]++
+		for role, n := range stats.messages {
+			t.messages[[2]string{stats.project, role}] += n
+		}
+		for date, usage := range stats.tokens {
+			key := [2]string{stats.project, date}
+			t.tokens[key] = t.tokens[key].Add(usage)
+		}
+	}
+	return t
+}
+
+// writeMetrics writes the totals in the Prometheus text format, with the series of each metric sorted by labels
+func writeMetrics(w io.Writer, t totals, scan time.Duration) {
+	header := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	header("cclog_sessions", "gauge", "Sessions in the log directory, by project.")
+	for _, project := range slices.Sorted(maps.Keys(t.sessions)) {
+		fmt.Fprintf(w, "cclog_sessions{project=%s} %d\n", label(project), t.sessions[project])
+	}
+
+	header("cclog_unparsable_sessions", "gauge", "Logs in the log directory that could not be parsed.")
+	fmt.Fprintf(w, "cclog_unparsable_sessions %d\n", t.failed)
+
+	header("cclog_messages_total", "counter", "Messages in the sessions, by project and role (user, assistant, tool, ...).")
+	for _, key := range sortedPairs(t.messages) {
+		fmt.Fprintf(w, "cclog_messages_total{project=%s,role=%s} %d\n", label(key[0]), label(key[1]), t.messages[key])
+	}
+
+	header("cclog_tokens_total", "counter", "Tokens the API reported for the answers, by project, day and kind.")
+	for _, key := range sortedPairs(t.tokens) {
+		for _, kind := range tokenKinds {
+			fmt.Fprintf(w, "cclog_tokens_total{project=%s,date=%s,kind=%s} %d\n", label(key[0]), label(key[1]), label(kind.name), kind.count(t.tokens[key]))
+		}
+	}
+
+	header("cclog_scanned_logs", "gauge", "Logs found in the log directory, in all projects.")
+	fmt.Fprintf(w, "cclog_scanned_logs %d\n", t.logs)
+
+	header("cclog_scan_duration_seconds", "gauge", "Time taken to scan the logs for this scrape.")
+	fmt.Fprintf(w, "cclog_scan_duration_seconds %g\n", scan.Seconds())
+}
+
+// sortedPairs returns the keys of m, pairs of label values, in order
+func sortedPairs[V any](m map[[2]string]V) [][2]string {
+	keys := slices.Collect(maps.Keys(m))
+	slices.SortFunc(keys, func(a, b [2]string) int {
+		if c := strings.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(a[1], b[1])
+	})
+	return keys
+}
+
+// labelEscaper escapes a label value of the text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes a label value
+func label(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const appSession = `{"type":"user","cwd":"/src/app","message":{"role":"user","content":"Fix the build"},"uuid":"u1","timestamp":"2025-07-06T05:00:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Running"}],"usage":{"input_tokens":100,"output_tokens":20}},"uuid":"a1","timestamp":"2025-07-06T05:00:10Z"}
+{"type":"assistant","cwd":"/src/app","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}],"usage":{"input_tokens":100,"output_tokens":20}},"uuid":"a2","timestamp":"2025-07-06T05:00:11Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},"uuid":"r1","timestamp":"2025-07-06T05:00:12Z"}
+{"type":"assistant","cwd":"/src/app","message":{"id":"m2","role":"assistant","content":"Done","usage":{"input_tokens":5,"output_tokens":1,"cache_read_input_tokens":50}},"uuid":"a3","timestamp":"2025-07-06T23:30:00Z"}
+`
+
+func TestCollector(t *testing.T) {
+	root := t.TempDir()
+	writeSession(t, filepath.Join(root, "-src-app", "a.jsonl"), appSession)
+	writeSession(t, filepath.Join(root, "-src-tool", "b.jsonl"), `{"type":"user","cwd":"/src/tool","message":{"role":"user","content":"Add \"quotes\""},"uuid":"u1","timestamp":"2025-07-06T05:00:00Z"}`+"\n")
+	writeSession(t, filepath.Join(root, "-src-tool", "broken.jsonl"), "not json\n")
+
+	c := &Collector{Root: root, Location: time.FixedZone("JST", 9*3600)}
+	var sb strings.Builder
+	if err := c.Write(context.Background(), &sb); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := sb.String()
+	for _, want := range []string{
+		"# TYPE cclog_sessions gauge\ncclog_sessions{project=\"app\"} 1\ncclog_sessions{project=\"tool\"} 1\n",
+		"cclog_unparsable_sessions 1\n",
+		"cclog_messages_total{project=\"app\",role=\"assistant\"} 3\n",
+		"cclog_messages_total{project=\"app\",role=\"tool\"} 1\n",
+		"cclog_messages_total{project=\"app\",role=\"user\"} 1\n",
+		// The lines of one answer repeat its usage, and days are dates in the collector's location
+		"cclog_tokens_total{project=\"app\",date=\"2025-07-06\",kind=\"input\"} 100\n",
+		"cclog_tokens_total{project=\"app\",date=\"2025-07-07\",kind=\"cache_read\"} 50\n",
+		"cclog_scanned_logs 3\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+
+	// Changed logs are parsed again, others are kept
+	writeSession(t, filepath.Join(root, "-src-tool", "broken.jsonl"), `{"type":"user","cwd":"/src/tool","message":{"role":"user","content":"Fixed"},"uuid":"u1","timestamp":"2025-07-06T05:00:00Z"}`+"\n")
+	c.Project = "tool"
+	sb.Reset()
+	if err := c.Write(context.Background(), &sb); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := sb.String(); !strings.Contains(got, "cclog_sessions{project=\"tool\"} 2\n") || strings.Contains(got, `project="app"`) || !strings.Contains(got, "cclog_unparsable_sessions 0\n") {
+		t.Errorf("Expected the repaired log and only the tool project, got:\n%s", got)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	root := t.TempDir()
+	writeSession(t, filepath.Join(root, "a.jsonl"), appSession)
+	recorder := httptest.NewRecorder()
+	(&Collector{Root: root}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != ContentType || !strings.Contains(recorder.Body.String(), "cclog_sessions{") {
+		t.Errorf("Unexpected response %d %q:\n%s", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	(&Collector{Root: filepath.Join(root, "missing")}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Expected an error for a missing directory, got %d", recorder.Code)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := label("a\"b\\c\nd"); got != `"a\"b\\c\nd"` {
+		t.Errorf("label() = %s", got)
+	}
+}
@@ -0,0 +1,32 @@
+// Package outfile writes output files through a buffer, so that markdown can be streamed into a file
+// as it is formatted without losing the errors of the final flush and close.
+package outfile
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// Create creates or truncates the file at path and writes it with write. See Write.
+func Create(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return Write(file, write)
+}
+
+// Write writes file with write through a buffer, flushes it and closes file. It returns the first error
+// of writing, flushing and closing; the file is closed in any case.
+func Write(file *os.File, write func(w io.Writer) error) error {
+	buffered := bufio.NewWriter(file)
+	err := write(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package outfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.md")
+	err := Create(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "# Title\n")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "# Title\n" {
+		t.Errorf("Expected the written content, got %q, %v", data, err)
+	}
+
+	if err := Create(filepath.Join(t.TempDir(), "missing", "out.md"), func(io.Writer) error { return nil }); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("format failed")
+	if err := Write(file, func(io.Writer) error { return failed }); !errors.Is(err, failed) {
+		t.Errorf("Expected the error of write, got %v", err)
+	}
+	if err := file.Close(); err == nil {
+		t.Error("Expected the file to be closed")
+	}
+}
@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Jobs returns the number of workers to use for n, where 0 or less means one per CPU
+func Jobs(n int) int {
+	if n <= 0 {
+		return runtime.NumCPU()
+	}
+	return n
+}
+
+// Map applies fn to every item using at most jobs workers (0 means one per CPU).
+// Results keep the order of items regardless of which worker finishes first.
+func Map[T, R any](items []T, jobs int, fn func(T) R) []R {
+	results, _ := MapContext(context.Background(), items, jobs, fn)
+	return results
+}
+
+// MapContext is Map that stops handing out items once ctx is done. Items already being processed
+// are finished; the results are incomplete and ctx.Err() is returned.
+func MapContext[T, R any](ctx context.Context, items []T, jobs int, fn func(T) R) ([]R, error) {
+	results := make([]R, len(items))
+	workers := min(Jobs(jobs), len(items))
+	if workers <= 1 {
+		for i, item := range items {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			results[i] = fn(item)
+		}
+		return results, ctx.Err()
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = fn(items[i])
+			}
+		}()
+	}
+dispatch:
+	for i := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return results, ctx.Err()
+}
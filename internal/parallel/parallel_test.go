@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapKeepsOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+	got := Map(items, 3, func(n int) int {
+		// Later items finish first
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n * 10
+	})
+	for i, n := range items {
+		if got[i] != n*10 {
+			t.Fatalf("Map result %v is out of order", got)
+		}
+	}
+}
+
+func TestMapBoundsWorkers(t *testing.T) {
+	var running, peak int32
+	Map(make([]int, 20), 2, func(int) int {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return 0
+	})
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent workers, saw %d", peak)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	if Jobs(3) != 3 {
+		t.Error("Expected explicit job count to be kept")
+	}
+	if Jobs(0) < 1 {
+		t.Error("Expected at least one job by default")
+	}
+}
+
+func TestMapContextStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	_, err := MapContext(ctx, make([]int, 100), 2, func(int) int {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			cancel()
+		}
+		return 0
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if calls > 5 {
+		t.Errorf("Expected no new items after cancellation, got %d calls", calls)
+	}
+}
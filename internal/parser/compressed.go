@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extensions of log files: Claude Code writes .jsonl, and archived sessions may be compressed with gzip or zstd
+const (
+	ExtJSONL = ".jsonl"
+	ExtGzip  = ".jsonl.gz"
+	ExtZstd  = ".jsonl.zst"
+)
+
+// LogExtensions lists the extensions of the log files cclog reads
+var LogExtensions = []string{ExtJSONL, ExtGzip, ExtZstd}
+
+// LogExt returns the log extension of a file name, ignoring case, or "" if it is not a log file
+func LogExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range LogExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// IsLogFile reports whether a file name has one of the LogExtensions
+func IsLogFile(name string) bool {
+	return LogExt(name) != ""
+}
+
+// TrimLogExt removes the log extension of a file name, e.g. giving the sessionId of "<id>.jsonl.gz"
+func TrimLogExt(name string) string {
+	return name[:len(name)-len(LogExt(name))]
+}
+
+// OpenLog opens a log file for reading, decompressing .jsonl.gz and .jsonl.zst files
+func OpenLog(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var decompressed io.ReadCloser
+	switch LogExt(path) {
+	case ExtGzip:
+		decompressed, err = gzip.NewReader(file)
+	case ExtZstd:
+		var decoder *zstd.Decoder
+		decoder, err = zstd.NewReader(file)
+		if err == nil {
+			decompressed = decoder.IOReadCloser()
+		}
+	default:
+		return file, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	return &compressedFile{ReadCloser: decompressed, file: file}, nil
+}
+
+// compressedFile closes both the decompressor and the file under it
+type compressedFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+// Close implements io.Closer
+func (f *compressedFile) Close() error {
+	err := f.ReadCloser.Close()
+	if fileErr := f.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// CompressLog compresses the .jsonl log at path in place into path.gz (ext ExtGzip) or path.zst
+// (ext ExtZstd), keeping its permissions and modification time so it stays in the same place in
+// session lists, and removes the original. It returns the path of the compressed log. An existing
+// compressed log is never replaced: the error then wraps fs.ErrExist and the original is kept.
+func CompressLog(path, ext string) (string, error) {
+	if LogExt(path) != ExtJSONL {
+		return "", fmt.Errorf("%s is not an uncompressed .jsonl log", path)
+	}
+	output := TrimLogExt(path) + ext
+	if _, err := os.Lstat(output); err == nil {
+		return "", fmt.Errorf("%s: %w", output, fs.ErrExist)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cclog-compress-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once installed
+
+	var encoder io.WriteCloser
+	switch ext {
+	case ExtGzip:
+		encoder = gzip.NewWriter(tmp)
+	case ExtZstd:
+		encoder, err = zstd.NewWriter(tmp)
+	default:
+		err = fmt.Errorf("unknown compressed log extension %q", ext)
+	}
+	if err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	_, err = io.Copy(encoder, in)
+	if closeErr := encoder.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = installNew(tmp.Name(), output)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	in.Close() // Before removing it, for Windows
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove %s after compressing it: %w", path, err)
+	}
+	return output, nil
+}
+
+// installNew moves the file at tmp to path unless path exists. A hard link fails if path exists, where
+// a rename would replace it; file systems without hard links fall back to a rename after a check.
+func installNew(tmp, path string) error {
+	err := os.Link(tmp, path)
+	if err == nil {
+		os.Remove(tmp) // Only leaves a second name of the file if it fails
+		return nil
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if _, statErr := os.Lstat(path); statErr == nil {
+		return &os.LinkError{Op: "rename", Old: tmp, New: path, Err: fs.ErrExist}
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogExt(t *testing.T) {
+	tests := []struct {
+		name, ext, trimmed string
+	}{
+		{"abc.jsonl", ExtJSONL, "abc"},
+		{"abc.jsonl.gz", ExtGzip, "abc"},
+		{"ABC.JSONL.ZST", ExtZstd, "ABC"},
+		{"abc.gz", "", "abc.gz"},
+		{"notes.md", "", "notes.md"},
+	}
+	for _, tt := range tests {
+		if got := LogExt(tt.name); got != tt.ext {
+			t.Errorf("LogExt(%q) = %q, want %q", tt.name, got, tt.ext)
+		}
+		if got := TrimLogExt(tt.name); got != tt.trimmed {
+			t.Errorf("TrimLogExt(%q) = %q, want %q", tt.name, got, tt.trimmed)
+		}
+	}
+}
+
+func TestCompressLog(t *testing.T) {
+	sample, err := os.ReadFile(filepath.Join("..", "..", "testdata", "sample.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ext := range []string{ExtGzip, ExtZstd} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "session.jsonl")
+			if err := os.WriteFile(path, sample, 0600); err != nil {
+				t.Fatal(err)
+			}
+			modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+			if err := os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := CompressLog(path, ext)
+			if err != nil {
+				t.Fatalf("CompressLog failed: %v", err)
+			}
+			if output != filepath.Join(dir, "session"+ext) {
+				t.Errorf("Unexpected output %s", output)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Expected the original log to be removed, got %v", err)
+			}
+			info, err := os.Stat(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.ModTime().Equal(modTime) || info.Mode().Perm() != 0600 {
+				t.Errorf("Expected the modification time and permissions to be kept, got %v, %v", info.ModTime(), info.Mode())
+			}
+
+			// The compressed log parses like the original, also as part of a directory
+			log, err := ParseJSONLFile(output)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", output, err)
+			}
+			if len(log.Messages) != 11 {
+				t.Errorf("Expected 11 messages, got %d", len(log.Messages))
+			}
+			logs, err := ParseJSONLDirectory(dir)
+			if err != nil || len(logs) != 1 {
+				t.Errorf("Expected the compressed log in the directory, got %d logs, %v", len(logs), err)
+			}
+
+			if _, err := CompressLog(output, ext); err == nil {
+				t.Error("Expected an error when compressing a compressed log")
+			}
+		})
+	}
+}
+
+func TestCompressLogKeepsExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	existing := filepath.Join(dir, "session"+ExtGzip)
+	if err := os.WriteFile(path, []byte(`{"type":"user"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("existing archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CompressLog(path, ExtGzip); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("Expected an error for an existing compressed log, got %v", err)
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "existing archive" {
+		t.Errorf("Expected the existing compressed log to be kept, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the original log to be kept: %v", err)
+	}
+
+	// A compressed log created while compressing is not replaced either
+	tmp := filepath.Join(dir, "tmp")
+	if err := os.WriteFile(tmp, []byte("new archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := installNew(tmp, existing); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected installNew to refuse an existing file, got %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "existing archive" {
+		t.Errorf("Expected the existing compressed log to be kept, got %q", data)
+	}
+}
+
+func TestOpenLogCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl.gz")
+	if err := os.WriteFile(path, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJSONLFile(path); err == nil {
+		t.Error("Expected an error for a corrupt gzip log")
+	}
+}
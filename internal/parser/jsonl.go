@@ -2,18 +2,35 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/annenpolka/cclog/internal/ignore"
+	"github.com/annenpolka/cclog/internal/parallel"
 	"github.com/annenpolka/cclog/pkg/types"
 )
 
-// ParseJSONLFile parses a single JSONL file and returns a ConversationLog
+// cancelCheckInterval is the number of lines parsed between checks for cancellation
+const cancelCheckInterval = 256
+
+// ParseJSONLFile parses a single JSONL file, which may be compressed (see OpenLog), and returns a ConversationLog.
+// A malformed final line without a trailing newline is ignored: it is a message
+// Claude Code is still writing, and will be complete the next time the file is read.
 func ParseJSONLFile(filePath string) (*types.ConversationLog, error) {
-	file, err := os.Open(filePath)
+	return ParseJSONLFileContext(context.Background(), filePath)
+}
+
+// ParseJSONLFileContext parses a file like ParseJSONLFile, stopping with ctx.Err() when ctx is done
+func ParseJSONLFileContext(ctx context.Context, filePath string) (*types.ConversationLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, err := OpenLog(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
@@ -23,10 +40,22 @@ func ParseJSONLFile(filePath string) (*types.ConversationLog, error) {
 	scanner := bufio.NewScanner(file)
 	// Expand buffer size to handle large JSONL lines (up to 1MB)
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	// Track whether the current line was cut off by the end of the file rather than a newline
+	unterminated := false
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		unterminated = token != nil && bytes.IndexByte(data[:advance], '\n') < 0
+		return advance, token, err
+	})
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
+		if lineNum%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
@@ -34,6 +63,9 @@ func ParseJSONLFile(filePath string) (*types.ConversationLog, error) {
 
 		var msg types.Message
 		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			if unterminated {
+				break // Truncated trailing line of a session being written
+			}
 			return nil, fmt.Errorf("failed to unmarshal line %d in file %s: %w", lineNum, filePath, err)
 		}
 
@@ -50,26 +82,62 @@ func ParseJSONLFile(filePath string) (*types.ConversationLog, error) {
 	}, nil
 }
 
-// ParseJSONLDirectory parses all JSONL files in a directory
+// ParseJSONLDirectory parses all log files (see LogExtensions) in a directory, except those excluded by its .cclogignore.
+// Files are parsed concurrently, one worker per CPU.
 func ParseJSONLDirectory(dirPath string) ([]*types.ConversationLog, error) {
-	files, err := filepath.Glob(filepath.Join(dirPath, "*.jsonl"))
+	return ParseJSONLDirectoryJobs(dirPath, 0)
+}
+
+// ParseJSONLDirectoryJobs parses a directory like ParseJSONLDirectory using at most jobs workers
+// (0 means one per CPU). Logs are returned in file name order, and the reported error is that of the first failing file.
+func ParseJSONLDirectoryJobs(dirPath string, jobs int) ([]*types.ConversationLog, error) {
+	return ParseJSONLDirectoryContext(context.Background(), dirPath, jobs)
+}
+
+// ParseJSONLDirectoryContext parses a directory like ParseJSONLDirectoryJobs. When ctx is done,
+// no more files are parsed and ctx.Err() is returned.
+func ParseJSONLDirectoryContext(ctx context.Context, dirPath string, jobs int) ([]*types.ConversationLog, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSONL files in %s: %w", dirPath, err)
+	}
+
+	ignored, err := ignore.Load(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var included []string
+	for _, entry := range entries {
+		if !entry.IsDir() && IsLogFile(entry.Name()) && !ignored.Match(entry.Name(), false) {
+			included = append(included, filepath.Join(dirPath, entry.Name()))
+		}
+	}
+
+	type parsed struct {
+		log *types.ConversationLog
+		err error
+	}
+	results, err := parallel.MapContext(ctx, included, jobs, func(file string) parsed {
+		log, err := ParseJSONLFileContext(ctx, file)
+		return parsed{log, err}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to glob JSONL files in %s: %w", dirPath, err)
+		return nil, err
 	}
 
 	var logs []*types.ConversationLog
-	for _, file := range files {
-		log, err := ParseJSONLFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse file %s: %w", file, err)
+	for i, result := range results {
+		if result.err != nil {
+			return nil, fmt.Errorf("failed to parse file %s: %w", included[i], result.err)
 		}
 
 		// Skip empty files (files with no messages)
-		if len(log.Messages) == 0 {
+		if len(result.log.Messages) == 0 {
 			continue
 		}
 
-		logs = append(logs, log)
+		logs = append(logs, result.log)
 	}
 
 	return logs, nil
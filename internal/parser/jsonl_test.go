@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
 )
 
 func TestParseJSONLFile(t *testing.T) {
@@ -58,6 +63,30 @@ func TestParseJSONLFile(t *testing.T) {
 	}
 }
 
+func TestParseJSONLFileMalformedContent(t *testing.T) {
+	// A malformed block or content of an unexpected shape must not fail the session
+	content := `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":"ls"},{"type":"text","text":"done"}]},"uuid":"u2"}
+{"type":"user","message":{"role":"user","content":{"unexpected":true}},"uuid":"u3"}
+{"type":"user","message":{"role":"user","content":42},"uuid":"u4"}
+`
+	tmpFile := filepath.Join(t.TempDir(), "malformed.jsonl")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	log, err := ParseJSONLFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to parse JSONL file with malformed content: %v", err)
+	}
+	if len(log.Messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(log.Messages))
+	}
+	if text := types.ExtractTextContent(log.Messages[1].Message); text != "done" {
+		t.Errorf("Expected the well-formed block of the message to be kept, got %q", text)
+	}
+}
+
 func TestParseJSONLFileNotFound(t *testing.T) {
 	_, err := ParseJSONLFile("nonexistent.jsonl")
 	if err == nil {
@@ -111,30 +140,13 @@ func TestParseJSONLFileLargeLines(t *testing.T) {
 	}
 
 	// Verify the large message was parsed correctly
-	// Message.Message is interface{}, need to cast to map for content access
-	if msg, ok := log.Messages[0].Message.(map[string]interface{}); ok {
-		if content, ok := msg["content"].(string); ok {
-			if len(content) != 80*1024 {
-				t.Errorf("Expected large message content length %d, got %d", 80*1024, len(content))
-			}
-		} else {
-			t.Error("Failed to extract content from large message")
-		}
-	} else {
-		t.Error("Failed to cast large message to map")
+	if content := types.ExtractTextContent(log.Messages[0].Message); len(content) != 80*1024 {
+		t.Errorf("Expected large message content length %d, got %d", 80*1024, len(content))
 	}
 
 	// Verify the normal message was also parsed
-	if msg, ok := log.Messages[1].Message.(map[string]interface{}); ok {
-		if content, ok := msg["content"].(string); ok {
-			if content != "normal message" {
-				t.Errorf("Expected normal message content 'normal message', got '%s'", content)
-			}
-		} else {
-			t.Error("Failed to extract content from normal message")
-		}
-	} else {
-		t.Error("Failed to cast normal message to map")
+	if content := types.ExtractTextContent(log.Messages[1].Message); content != "normal message" {
+		t.Errorf("Expected normal message content 'normal message', got '%s'", content)
 	}
 }
 
@@ -205,3 +217,124 @@ func TestParseJSONLDirectoryWithEmptyFiles(t *testing.T) {
 		t.Errorf("Expected 1 message in valid log, got %d", len(logs[0].Messages))
 	}
 }
+
+func TestParseJSONLFileTruncatedFinalLine(t *testing.T) {
+	complete := `{"type":"user","message":{"role":"user","content":"Hello"},"uuid":"1"}`
+	partial := `{"type":"assistant","message":{"role":"assis`
+
+	tests := []struct {
+		name      string
+		content   string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "truncated final line is ignored",
+			content:   complete + "\n" + partial,
+			wantCount: 1,
+		},
+		{
+			name:      "complete final line without newline is kept",
+			content:   complete + "\n" + complete,
+			wantCount: 2,
+		},
+		{
+			name:    "malformed final line with newline is an error",
+			content: complete + "\n" + partial + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed line before the end is an error",
+			content: partial + "\n" + complete,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			log, err := ParseJSONLFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(log.Messages) != tt.wantCount {
+				t.Errorf("Expected %d messages, got %d", tt.wantCount, len(log.Messages))
+			}
+		})
+	}
+}
+
+func TestParseJSONLDirectoryRespectsIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1"}` + "\n"
+	for _, name := range []string{"keep.jsonl", "scratch-1.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write session: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".cclogignore"), []byte("scratch-*\n"), 0644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	logs, err := ParseJSONLDirectory(dir)
+	if err != nil {
+		t.Fatalf("ParseJSONLDirectory failed: %v", err)
+	}
+	if len(logs) != 1 || filepath.Base(logs[0].FilePath) != "keep.jsonl" {
+		t.Errorf("Expected only keep.jsonl, got %d logs", len(logs))
+	}
+}
+
+func TestParseJSONLDirectoryJobsKeepsFileOrder(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		content := fmt.Sprintf(`{"type":"user","message":{"role":"user","content":"message %d"},"uuid":"u%d"}`+"\n", i, i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("session-%02d.jsonl", i)), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write session: %v", err)
+		}
+	}
+
+	logs, err := ParseJSONLDirectoryJobs(dir, 4)
+	if err != nil {
+		t.Fatalf("ParseJSONLDirectoryJobs failed: %v", err)
+	}
+	if len(logs) != 20 {
+		t.Fatalf("Expected 20 logs, got %d", len(logs))
+	}
+	for i, log := range logs {
+		if want := fmt.Sprintf("session-%02d.jsonl", i); filepath.Base(log.FilePath) != want {
+			t.Errorf("Log %d is %s, want %s", i, filepath.Base(log.FilePath), want)
+		}
+	}
+}
+
+func TestParseJSONLContextCancelled(t *testing.T) {
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i := 0; i < 1000; i++ {
+		lines.WriteString(fmt.Sprintf(`{"type":"user","message":{"role":"user","content":"message %d"},"uuid":"u%d"}`+"\n", i, i))
+	}
+	path := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(path, []byte(lines.String()), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseJSONLFileContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the file parse to be cancelled, got %v", err)
+	}
+	if _, err := ParseJSONLDirectoryContext(ctx, dir, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the directory parse to be cancelled, got %v", err)
+	}
+}
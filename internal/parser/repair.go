@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// maxSalvageAttempts caps the number of cut points tried for one broken line
+const maxSalvageAttempts = 100
+
+// RepairReport describes what Repair did with the lines of a log
+type RepairReport struct {
+	// Kept is the number of lines copied as they were
+	Kept int
+	// Salvaged lists the numbers of truncated lines that were completed, e.g. a final line cut off by a crash
+	Salvaged []int
+	// Dropped lists the lines that could not be salvaged
+	Dropped []DroppedLine
+}
+
+// DroppedLine is a line Repair left out of the repaired log
+type DroppedLine struct {
+	Line   int
+	Reason string
+}
+
+// String summarizes the report, e.g. "kept 120 lines, salvaged line 121, dropped 1 line (line 57: ...)"
+func (r RepairReport) String() string {
+	parts := []string{fmt.Sprintf("kept %d %s", r.Kept, plural(r.Kept, "line", "lines"))}
+	if len(r.Salvaged) > 0 {
+		lines := make([]string, len(r.Salvaged))
+		for i, line := range r.Salvaged {
+			lines[i] = fmt.Sprintf("%d", line)
+		}
+		parts = append(parts, fmt.Sprintf("salvaged %s %s", plural(len(lines), "line", "lines"), strings.Join(lines, ", ")))
+	}
+	if len(r.Dropped) > 0 {
+		reasons := make([]string, len(r.Dropped))
+		for i, dropped := range r.Dropped {
+			reasons[i] = fmt.Sprintf("line %d: %s", dropped.Line, dropped.Reason)
+		}
+		parts = append(parts, fmt.Sprintf("dropped %d %s (%s)", len(r.Dropped), plural(len(r.Dropped), "line", "lines"), strings.Join(reasons, "; ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural returns singular for a count of 1, and otherwise plural
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// Repair copies the lines of r that parse as messages to w, one per line. Lines that do not parse are
+// salvaged if they are a message cut short, by dropping the incomplete last field and closing the open
+// strings, arrays and objects; other lines are dropped. Empty lines are left out. Lines are not limited
+// in length, unlike in ParseJSONLFile.
+func Repair(r io.Reader, w io.Writer) (RepairReport, error) {
+	var report RepairReport
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		data, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return report, fmt.Errorf("failed to read line %d: %w", lineNum, readErr)
+		}
+
+		if line := strings.TrimSpace(data); line != "" {
+			err := decodeMessage(line)
+			if err == nil {
+				report.Kept++
+			} else if salvaged, ok := salvageLine(line); ok {
+				line = salvaged
+				report.Salvaged = append(report.Salvaged, lineNum)
+			} else {
+				report.Dropped = append(report.Dropped, DroppedLine{Line: lineNum, Reason: err.Error()})
+				line = ""
+			}
+			if line != "" {
+				if _, err := io.WriteString(w, line+"\n"); err != nil {
+					return report, fmt.Errorf("failed to write repaired log: %w", err)
+				}
+			}
+		}
+
+		if readErr != nil {
+			return report, nil
+		}
+	}
+}
+
+// RepairFile writes the repaired copy of the log at path, which may be compressed, to output, which must be a different file.
+// The copy is written to a temporary file first, so a failed repair leaves no partial output.
+func RepairFile(path, output string) (RepairReport, error) {
+	if same, err := samePath(path, output); err == nil && same {
+		return RepairReport{}, fmt.Errorf("the repaired copy must not overwrite the log %s", path)
+	}
+
+	in, err := OpenLog(path)
+	if err != nil {
+		return RepairReport{}, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".cclog-repair-*.jsonl")
+	if err != nil {
+		return RepairReport{}, fmt.Errorf("failed to create repaired log: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op after the rename
+
+	report, err := Repair(in, tmp)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write repaired log: %w", closeErr)
+	}
+	if err != nil {
+		return RepairReport{}, err
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return RepairReport{}, fmt.Errorf("failed to write repaired log: %w", err)
+	}
+	return report, nil
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) (bool, error) {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+	return absA == absB, nil
+}
+
+// decodeMessage checks that a line is a message the parser accepts
+func decodeMessage(line string) error {
+	var msg types.Message
+	if err := json.Unmarshal([]byte(line), &msg); err != nil {
+		return err
+	}
+	if msg.Type == "" {
+		return errors.New("no type field")
+	}
+	return nil
+}
+
+// salvageLine completes a line cut short in the middle of a JSON object. It first closes the line as it
+// is, which keeps a string cut in the middle, then cuts it before each comma from the end, dropping the
+// incomplete last member, until the result is a message. It returns false if no cut gives one.
+func salvageLine(line string) (string, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return "", false
+	}
+
+	type cut struct {
+		end     int    // Length of the line kept
+		closers string // Closes the strings, arrays and objects open at end
+	}
+	var cuts []cut
+	var open []byte // Closers of the open arrays and objects, innermost last
+	inString, escaped := false, false
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			open = append(open, '}')
+		case '[':
+			open = append(open, ']')
+		case '}', ']':
+			if len(open) == 0 || open[len(open)-1] != ch {
+				return "", false // Not a truncation: the brackets do not match
+			}
+			open = open[:len(open)-1]
+		case ',':
+			cuts = append(cuts, cut{end: i, closers: reverse(open)})
+		}
+	}
+
+	// The whole line, with a cut string closed
+	end := line
+	if inString {
+		end = strings.TrimSuffix(end, "\\") + `"`
+	}
+	if candidate := end + reverse(open); decodeMessage(candidate) == nil {
+		return candidate, true
+	}
+
+	for i := len(cuts) - 1; i >= 0 && len(cuts)-i <= maxSalvageAttempts; i-- {
+		if candidate := line[:cuts[i].end] + cuts[i].closers; decodeMessage(candidate) == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// reverse returns the closers of the open arrays and objects in the order they must be written
+func reverse(open []byte) string {
+	closers := make([]byte, len(open))
+	for i, ch := range open {
+		closers[len(open)-1-i] = ch
+	}
+	return string(closers)
+}
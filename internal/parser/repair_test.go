@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSalvageLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+		ok   bool
+	}{
+		{
+			name: "string cut in the middle",
+			line: `{"type":"user","uuid":"u1","message":{"role":"user","content":"Hello wor`,
+			want: `{"type":"user","uuid":"u1","message":{"role":"user","content":"Hello wor"}}`,
+			ok:   true,
+		},
+		{
+			name: "string cut after a backslash",
+			line: `{"type":"user","message":{"role":"user","content":"C:\`,
+			want: `{"type":"user","message":{"role":"user","content":"C:"}}`,
+			ok:   true,
+		},
+		{
+			name: "key cut in the middle",
+			line: `{"type":"assistant","uuid":"u2","message":{"role":"assistant","content":[{"type":"text","text":"Hi"}],"mod`,
+			want: `{"type":"assistant","uuid":"u2","message":{"role":"assistant","content":[{"type":"text","text":"Hi"}]}}`,
+			ok:   true,
+		},
+		{
+			name: "block cut in the middle",
+			line: `{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":[{"type":"text","text":"Hi"},{"type":"tool_use","id":"t1","input":{"comm`,
+			want: `{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":[{"type":"text","text":"Hi"},{"type":"tool_use","id":"t1"}]}}`,
+			ok:   true,
+		},
+		{
+			name: "cut before the type",
+			line: `{"uuid":"u4","ty`,
+		},
+		{
+			name: "not an object",
+			line: `garbage`,
+		},
+		{
+			name: "mismatched brackets",
+			line: `{"type":"user","message":{"content":[}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := salvageLine(tt.line)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("salvageLine() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"user","uuid":"u1","message":{"role":"user","content":"Hello"}}`,
+		``,
+		`not json`,
+		`{"type":"assistant","uuid":"u2","message":{"role":"assistant","content":"Hi"}}`,
+		`{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":"Cut of`,
+	}, "\n")
+
+	var out strings.Builder
+	report, err := Repair(strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if report.Kept != 2 || len(report.Salvaged) != 1 || report.Salvaged[0] != 5 || len(report.Dropped) != 1 || report.Dropped[0].Line != 3 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if !strings.HasPrefix(report.String(), "kept 2 lines, salvaged line 5, dropped 1 line (line 3: ") {
+		t.Errorf("Unexpected report text %q", report.String())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[2] != `{"type":"assistant","uuid":"u3","message":{"role":"assistant","content":"Cut of"}}` {
+		t.Errorf("Unexpected repaired log:\n%s", out.String())
+	}
+}
+
+func TestRepairFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "session.jsonl")
+	content := `{"type":"user","uuid":"u1","timestamp":"2025-07-06T05:00:00.000Z","message":{"role":"user","content":"Hello"}}` + "\n" +
+		`{"type":"assistant","uuid":"u2","timestamp":"2025-07-06T05:00:01.000Z","message":{"role":"assistant","content":[{"type":"text","text":"Half an ans`
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "repaired.jsonl")
+	report, err := RepairFile(input, output)
+	if err != nil {
+		t.Fatalf("RepairFile failed: %v", err)
+	}
+	if report.Kept != 1 || len(report.Salvaged) != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	// The cleaned copy parses, including the salvaged answer
+	log, err := ParseJSONLFile(output)
+	if err != nil {
+		t.Fatalf("Failed to parse the repaired log: %v", err)
+	}
+	if len(log.Messages) != 2 || !strings.Contains(string(mustRead(t, output)), "Half an ans") {
+		t.Errorf("Expected both messages in the repaired log, got %d", len(log.Messages))
+	}
+
+	if _, err := RepairFile(input, input); err == nil {
+		t.Error("Expected an error when the repaired copy would overwrite the log")
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,120 @@
+// Package pdf prints converted conversations to PDF. The markdown is rendered to HTML in Go
+// and printed by an external converter, since there is no pure-Go HTML to PDF printer.
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/htmldoc"
+)
+
+// Format is the --format name of PDF output
+const Format = "pdf"
+
+// converter is an external program that prints an HTML file to PDF
+type converter struct {
+	name string
+	args func(htmlPath, pdfPath string) []string
+}
+
+// converters lists the supported programs in order of preference
+var converters = []converter{
+	{"wkhtmltopdf", func(htmlPath, pdfPath string) []string {
+		return []string{"--quiet", "--disable-javascript", "--enable-local-file-access", htmlPath, pdfPath}
+	}},
+	{"weasyprint", func(htmlPath, pdfPath string) []string {
+		return []string{htmlPath, pdfPath}
+	}},
+	{"chromium", chromeArgs},
+	{"chromium-browser", chromeArgs},
+	{"google-chrome", chromeArgs},
+	{"pandoc", func(htmlPath, pdfPath string) []string {
+		return []string{"-f", "html", "-o", pdfPath, htmlPath}
+	}},
+}
+
+// chromeArgs prints with a headless Chrome or Chromium
+func chromeArgs(htmlPath, pdfPath string) []string {
+	return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf=" + pdfPath, "file://" + htmlPath}
+}
+
+// stopTimeout is how long a stopped converter's processes may keep its output open
+const stopTimeout = time.Second
+
+// lookPath and execCommand are variables that can be replaced in tests to mock os/exec
+var (
+	lookPath    = exec.LookPath
+	execCommand = exec.CommandContext
+)
+
+// Write prints markdown to a PDF file at path with the first converter found on the PATH.
+// The intermediate HTML file is written next to path, so relative image links keep working, and removed afterwards.
+// When ctx is done, the converter is stopped and its partial PDF removed.
+func Write(ctx context.Context, markdown, title, path string) error {
+	conv, program, err := findConverter()
+	if err != nil {
+		return err
+	}
+
+	document, err := htmldoc.Render(markdown, title)
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	htmlFile, err := os.CreateTemp(filepath.Dir(absPath), ".cclog-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary HTML file: %w", err)
+	}
+	defer os.Remove(htmlFile.Name())
+	if _, err := htmlFile.WriteString(document); err != nil {
+		htmlFile.Close()
+		return fmt.Errorf("failed to write temporary HTML file: %w", err)
+	}
+	if err := htmlFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary HTML file: %w", err)
+	}
+
+	cmd := execCommand(ctx, program, conv.args(htmlFile.Name(), absPath)...)
+	// Browsers start helper processes that keep the output pipe open after the converter is killed
+	cmd.WaitDelay = stopTimeout
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			os.Remove(absPath)
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s failed to create %s: %w\n%s", conv.name, path, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// findConverter returns the first available converter and the path of its program
+func findConverter() (converter, string, error) {
+	names := make([]string, len(converters))
+	for i, conv := range converters {
+		if program, err := lookPath(conv.name); err == nil {
+			return conv, program, nil
+		}
+		names[i] = conv.name
+	}
+	return converter{}, "", fmt.Errorf("no PDF converter found; install one of %s, or convert the markdown of --profile print yourself", strings.Join(names, ", "))
+}
+
+// FindConverter returns the path of the converter that Write would use, or an error listing the
+// converters to install
+func FindConverter() (string, error) {
+	_, program, err := findConverter()
+	return program, err
+}
@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockConverters makes lookPath find only the named programs and records the converter invocation
+func mockConverters(t *testing.T, available ...string) *[]string {
+	t.Helper()
+	var got []string
+	originalLookPath, originalExecCommand := lookPath, execCommand
+	lookPath = func(name string) (string, error) {
+		for _, program := range available {
+			if program == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		got = append([]string{name}, args...)
+		return exec.CommandContext(ctx, "true")
+	}
+	t.Cleanup(func() { lookPath, execCommand = originalLookPath, originalExecCommand })
+	return &got
+}
+
+func TestWrite(t *testing.T) {
+	got := mockConverters(t, "weasyprint", "pandoc")
+	dir := t.TempDir()
+	output := filepath.Join(dir, "out", "design.pdf")
+
+	if err := Write(context.Background(), "# Design\n", "design", output); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(*got) != 3 || (*got)[0] != "/usr/bin/weasyprint" || (*got)[2] != output {
+		t.Errorf("Expected weasyprint to print to %s, got %v", output, *got)
+	}
+	if filepath.Dir((*got)[1]) != filepath.Dir(output) {
+		t.Errorf("Expected the HTML file next to the output, got %s", (*got)[1])
+	}
+	if _, err := os.Stat((*got)[1]); !os.IsNotExist(err) {
+		t.Errorf("Expected the HTML file to be removed, got %v", err)
+	}
+}
+
+func TestWriteWithoutConverter(t *testing.T) {
+	mockConverters(t)
+
+	err := Write(context.Background(), "# Design\n", "design", filepath.Join(t.TempDir(), "design.pdf"))
+	if err == nil || !strings.Contains(err.Error(), "wkhtmltopdf") {
+		t.Errorf("Expected an error listing the converters, got %v", err)
+	}
+}
+
+func TestWriteConverterFailure(t *testing.T) {
+	mockConverters(t, "wkhtmltopdf")
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "echo broken font >&2; exit 1")
+	}
+
+	err := Write(context.Background(), "# Design\n", "design", filepath.Join(t.TempDir(), "design.pdf"))
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || !strings.Contains(err.Error(), "broken font") {
+		t.Errorf("Expected the converter's error output, got %v", err)
+	}
+}
+
+func TestWriteCancelled(t *testing.T) {
+	mockConverters(t, "wkhtmltopdf")
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		// The converter writes part of the PDF and hangs until it is killed
+		return exec.CommandContext(ctx, "sh", "-c", `echo partial > "$1"; sleep 10`, "sh", args[len(args)-1])
+	}
+	output := filepath.Join(t.TempDir(), "design.pdf")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := Write(ctx, "# Design\n", "design", output); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the conversion to be stopped, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected the partial PDF to be removed, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(output))
+	if len(entries) != 0 {
+		t.Errorf("Expected the temporary HTML file to be removed, found %d files", len(entries))
+	}
+}
@@ -0,0 +1,171 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// CustomRuleName names the rules built from user-defined patterns in reports
+const CustomRuleName = "custom"
+
+// Rule replaces the matches of a pattern with a placeholder naming the kind of secret.
+// If the pattern has a group named "keep", that part of the match is kept (e.g. the key name in "password=...").
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules detects common API keys, tokens and email addresses
+var DefaultRules = []Rule{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"api-key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", regexp.MustCompile(`(?i)(?P<keep>aws_secret_access_key["']?\s*[=:]\s*["']?)[A-Za-z0-9/+=]{40}`)},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+// Redactor replaces secrets in conversation logs and counts the replacements.
+// It is safe for concurrent use.
+type Redactor struct {
+	rules  []Rule
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// New returns a Redactor using the default rules followed by the user-defined regular expressions in patterns
+func New(patterns []string) (*Redactor, error) {
+	rules := append([]Rule(nil), DefaultRules...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, Rule{Name: CustomRuleName, Pattern: re})
+	}
+	return &Redactor{rules: rules, counts: make(map[string]int)}, nil
+}
+
+// Clone returns a Redactor with the same rules and its own counts. Cloning nil returns nil.
+func (r *Redactor) Clone() *Redactor {
+	if r == nil {
+		return nil
+	}
+	return &Redactor{rules: r.rules, counts: make(map[string]int)}
+}
+
+// Fingerprint identifies the rules, so cached output can be regenerated when they change
+func (r *Redactor) Fingerprint() string {
+	var sb strings.Builder
+	for _, rule := range r.rules {
+		sb.WriteString(rule.Name + "=" + rule.Pattern.String() + "\n")
+	}
+	return sb.String()
+}
+
+// Text replaces the secrets in s and returns the result
+func (r *Redactor) Text(s string) string {
+	for _, rule := range r.rules {
+		matches := len(rule.Pattern.FindAllStringIndex(s, -1))
+		if matches == 0 {
+			continue
+		}
+		s = rule.Pattern.ReplaceAllString(s, "${keep}[REDACTED:"+rule.Name+"]")
+		r.mu.Lock()
+		r.counts[rule.Name] += matches
+		r.mu.Unlock()
+	}
+	return s
+}
+
+// Log redacts every message of log in place, including tool inputs and results
+func (r *Redactor) Log(log *types.ConversationLog) {
+	for i := range log.Messages {
+		if body := log.Messages[i].Message; body != nil {
+			body.Content = r.content(body.Content)
+			body.Summary = r.Text(body.Summary)
+			body.ToolUseResult = r.value(body.ToolUseResult)
+		}
+		log.Messages[i].ToolUseResult = r.value(log.Messages[i].ToolUseResult)
+	}
+}
+
+// content redacts the text, thinking and tool blocks of message or tool result content
+func (r *Redactor) content(c types.Content) types.Content {
+	if !c.IsBlocks() {
+		return types.TextContent(r.Text(c.Text))
+	}
+	for i, block := range c.Blocks {
+		switch b := block.(type) {
+		case types.TextBlock:
+			b.Text = r.Text(b.Text)
+			c.Blocks[i] = b
+		case types.ThinkingBlock:
+			b.Thinking = r.Text(b.Thinking)
+			c.Blocks[i] = b
+		case types.ToolUseBlock:
+			r.value(b.Input)
+		case types.ToolResultBlock:
+			b.Content = r.content(b.Content)
+			b.ToolUseResult = r.value(b.ToolUseResult)
+			c.Blocks[i] = b
+		case types.UnknownBlock:
+			r.value(b.Fields)
+		}
+	}
+	return c
+}
+
+// value redacts the strings inside a decoded JSON value
+func (r *Redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.Text(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = r.value(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Report summarizes the replacements by rule, e.g. "Redacted 3 secrets (email: 2, api-key: 1)"
+func (r *Redactor) Report() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.counts))
+	total := 0
+	for name, n := range r.counts {
+		names = append(names, name)
+		total += n
+	}
+	if total == 0 {
+		return "Redacted 0 secrets"
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.counts[names[i]] != r.counts[names[j]] {
+			return r.counts[names[i]] > r.counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %d", name, r.counts[name])
+	}
+	return fmt.Sprintf("Redacted %d secrets (%s)", total, strings.Join(parts, ", "))
+}
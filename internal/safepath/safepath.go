@@ -0,0 +1,50 @@
+// Package safepath builds output paths from names derived from conversations, such as titles, project
+// names and file name templates, so that a crafted title like "../../.bashrc" cannot write outside the
+// output directory.
+package safepath
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Name turns s into a single file name component: path separators, control characters and characters
+// that are invalid in Windows file names become "-", and leading and trailing dots and spaces are removed.
+// An empty result becomes fallback.
+func Name(s, fallback string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, r == 0x7f, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// Clean cleans a relative path, which may use forward slashes, and returns an error if it is empty,
+// absolute or leaves its base directory, e.g. "../x" or "a/../../x"
+func Clean(rel string) (string, error) {
+	name := filepath.Clean(filepath.FromSlash(strings.TrimSpace(rel)))
+	switch {
+	case name == ".", filepath.IsAbs(name), filepath.VolumeName(name) != "", strings.HasPrefix(name, string(filepath.Separator)):
+		return "", fmt.Errorf("path %q is not relative to the output directory", rel)
+	case name == "..", strings.HasPrefix(name, ".."+string(filepath.Separator)):
+		return "", fmt.Errorf("path %q is outside the output directory", rel)
+	}
+	return name, nil
+}
+
+// Join joins a relative path to dir like filepath.Join, but returns an error if the path would leave dir
+func Join(dir, rel string) (string, error) {
+	name, err := Clean(rel)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,49 @@
+package safepath
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Fix the build", "Fix the build"},
+		{"../../.bashrc", "-..-.bashrc"},
+		{`a/b\c:d*e?f"g<h>i|j`, "a-b-c-d-e-f-g-h-i-j"},
+		{"line\nbreak\x00", "line-break-"},
+		{" .hidden. ", "hidden"},
+		{"..", "fallback"},
+		{"", "fallback"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.in, "fallback"); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	dir := filepath.Join("out", "dir")
+	valid := map[string]string{
+		"a.md":         filepath.Join(dir, "a.md"),
+		"project/a.md": filepath.Join(dir, "project", "a.md"),
+		"a/../b.md":    filepath.Join(dir, "b.md"),
+		" ./x/./y.md ": filepath.Join(dir, "x", "y.md"),
+		"..name.md":    filepath.Join(dir, "..name.md"),
+		"a/..b/c.md":   filepath.Join(dir, "a", "..b", "c.md"),
+	}
+	for rel, want := range valid {
+		got, err := Join(dir, rel)
+		if err != nil || got != want {
+			t.Errorf("Join(%q) = %q (%v), want %q", rel, got, err, want)
+		}
+	}
+
+	for _, rel := range []string{"", ".", "..", "../x.md", "a/../../x.md", "/etc/passwd", "../../.bashrc"} {
+		if got, err := Join(dir, rel); err == nil {
+			t.Errorf("Expected error for %q, got %q", rel, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package selfupdate
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIBaseURL is the GitHub API endpoint used to look up releases
+	DefaultAPIBaseURL = "https://api.github.com"
+	// DefaultRepository is the GitHub repository that publishes cclog releases
+	DefaultRepository = "annenpolka/cclog"
+	// checksumsAssetName is the release asset listing SHA-256 checksums of binaries
+	checksumsAssetName = "checksums.txt"
+)
+
+// Release represents a GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset represents a downloadable file attached to a GitHub release
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Updater checks for and installs new cclog releases
+type Updater struct {
+	APIBaseURL     string
+	Repository     string
+	CurrentVersion string
+	ExecutablePath string
+	GOOS           string
+	GOARCH         string
+	Client         *http.Client
+	// Force installs the latest release even if the current version is newer or not a release
+	Force bool
+}
+
+// Result describes the outcome of an update
+type Result struct {
+	PreviousVersion string
+	LatestVersion   string
+	Updated         bool
+}
+
+// NewUpdater creates an Updater for the running binary
+func NewUpdater(currentVersion string) (*Updater, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate running executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	return &Updater{
+		APIBaseURL:     DefaultAPIBaseURL,
+		Repository:     DefaultRepository,
+		CurrentVersion: currentVersion,
+		ExecutablePath: exe,
+		GOOS:           runtime.GOOS,
+		GOARCH:         runtime.GOARCH,
+		Client:         &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// AssetName returns the release asset name for the updater's platform
+func (u *Updater) AssetName() string {
+	name := fmt.Sprintf("cclog-%s-%s", u.GOOS, u.GOARCH)
+	if u.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// LatestRelease fetches the latest release metadata from GitHub
+func (u *Updater) LatestRelease() (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimSuffix(u.APIBaseURL, "/"), u.Repository)
+	body, err := u.get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode release metadata: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("latest release has no tag name")
+	}
+	return &release, nil
+}
+
+// Update replaces the running binary with the latest release if it is newer. Development builds,
+// whose version is not a release version, are only replaced with Force.
+func (u *Updater) Update() (*Result, error) {
+	release, err := u.LatestRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		PreviousVersion: u.CurrentVersion,
+		LatestVersion:   release.TagName,
+	}
+	latest, ok := parseVersion(release.TagName)
+	if !ok {
+		return nil, fmt.Errorf("latest release %s is not a semantic version", release.TagName)
+	}
+	current, ok := parseVersion(u.CurrentVersion)
+	if !ok && !u.Force {
+		return nil, fmt.Errorf("%s is not a release build; use --force to replace it with %s", u.CurrentVersion, release.TagName)
+	}
+	if ok && slices.Compare(current[:], latest[:]) >= 0 && !u.Force {
+		return result, nil
+	}
+
+	binaryAsset, ok := findAsset(release, u.AssetName())
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s; download a build for your platform from https://github.com/%s/releases", release.TagName, u.GOOS, u.GOARCH, u.Repository)
+	}
+	checksumAsset, ok := findAsset(release, checksumsAssetName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install unverified binary", release.TagName, checksumsAssetName)
+	}
+
+	checksums, err := u.get(checksumAsset.BrowserDownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download checksums: %w", err)
+	}
+	expected, err := findChecksum(checksums, binaryAsset.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := u.get(binaryAsset.BrowserDownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", binaryAsset.Name, err)
+	}
+	if err := verifyChecksum(binary, expected); err != nil {
+		return nil, err
+	}
+
+	if err := replaceExecutable(u.ExecutablePath, binary); err != nil {
+		return nil, err
+	}
+
+	result.Updated = true
+	return result, nil
+}
+
+// get performs a GET request and returns the response body
+func (u *Updater) get(url string) ([]byte, error) {
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cclog-self-update")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// findAsset returns the release asset with the given name
+func findAsset(release *Release, name string) (Asset, bool) {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// findChecksum looks up the SHA-256 checksum for name in a sha256sum-style listing
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(checksums)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary mode files with a leading '*'
+		if strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum found for %s", name)
+}
+
+// verifyChecksum checks data against an expected hex-encoded SHA-256 checksum
+func verifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// replaceExecutable atomically replaces the executable at path with data
+func replaceExecutable(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".cclog-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	// Windows cannot overwrite a running executable, but it can rename it
+	oldPath := path + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		// Restore the previous binary
+		os.Rename(oldPath, path)
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(oldPath)
+
+	return nil
+}
+
+// parseVersion parses a release version, MAJOR.MINOR.PATCH with an optional leading "v". Versions with
+// a suffix, such as the v1.2.3-4-gabc1234 of git describe or Go pseudo-versions, are not releases.
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) != 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part != strconv.Itoa(n) {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
@@ -0,0 +1,236 @@
+package selfupdate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newReleaseServer serves a fake GitHub release with the given binary and checksum listing
+func newReleaseServer(t *testing.T, tag string, binary []byte, checksums string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/repos/annenpolka/cclog/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		release := Release{
+			TagName: tag,
+			Assets: []Asset{
+				{Name: "cclog-linux-amd64", BrowserDownloadURL: server.URL + "/download/cclog-linux-amd64"},
+				{Name: "checksums.txt", BrowserDownloadURL: server.URL + "/download/checksums.txt"},
+			},
+		}
+		json.NewEncoder(w).Encode(release)
+	})
+	mux.HandleFunc("/download/cclog-linux-amd64", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	mux.HandleFunc("/download/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, checksums)
+	})
+
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestUpdater creates an Updater pointing at server with a fake installed executable
+func newTestUpdater(t *testing.T, server *httptest.Server, currentVersion string) *Updater {
+	t.Helper()
+
+	exe := filepath.Join(t.TempDir(), "cclog")
+	if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to create fake executable: %v", err)
+	}
+
+	return &Updater{
+		APIBaseURL:     server.URL,
+		Repository:     DefaultRepository,
+		CurrentVersion: currentVersion,
+		ExecutablePath: exe,
+		GOOS:           "linux",
+		GOARCH:         "amd64",
+		Client:         server.Client(),
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUpdateReplacesBinary(t *testing.T) {
+	binary := []byte("new binary")
+	checksums := sha256Hex(binary) + "  cclog-linux-amd64\n"
+	server := newReleaseServer(t, "v1.1.0", binary, checksums)
+	updater := newTestUpdater(t, server, "v1.0.0")
+
+	result, err := updater.Update()
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !result.Updated {
+		t.Error("Expected binary to be updated")
+	}
+	if result.LatestVersion != "v1.1.0" {
+		t.Errorf("Expected latest version v1.1.0, got %s", result.LatestVersion)
+	}
+
+	data, err := os.ReadFile(updater.ExecutablePath)
+	if err != nil {
+		t.Fatalf("Failed to read executable: %v", err)
+	}
+	if string(data) != "new binary" {
+		t.Errorf("Expected executable to be replaced, got %q", data)
+	}
+	if _, err := os.Stat(updater.ExecutablePath + ".old"); !os.IsNotExist(err) {
+		t.Error("Expected old binary to be removed")
+	}
+}
+
+func TestUpdateAlreadyUpToDate(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, "v1.1.0", binary, sha256Hex(binary)+"  cclog-linux-amd64\n")
+	updater := newTestUpdater(t, server, "1.1.0")
+
+	result, err := updater.Update()
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if result.Updated {
+		t.Error("Expected no update when versions match")
+	}
+
+	data, _ := os.ReadFile(updater.ExecutablePath)
+	if string(data) != "old binary" {
+		t.Error("Executable should not change when already up to date")
+	}
+}
+
+func TestUpdateKeepsNewerBuild(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, "v1.1.0", binary, sha256Hex(binary)+"  cclog-linux-amd64\n")
+	updater := newTestUpdater(t, server, "v1.10.0")
+
+	result, err := updater.Update()
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if result.Updated {
+		t.Error("Expected a newer build not to be downgraded")
+	}
+}
+
+func TestUpdateDevelopmentBuild(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, "v1.1.0", binary, sha256Hex(binary)+"  cclog-linux-amd64\n")
+
+	for _, version := range []string{"dev", "v1.1.0-3-gabc1234-dirty", "(devel)"} {
+		updater := newTestUpdater(t, server, version)
+		if _, err := updater.Update(); err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Errorf("%s: expected a development build to be refused without --force, got %v", version, err)
+		}
+		data, _ := os.ReadFile(updater.ExecutablePath)
+		if string(data) != "old binary" {
+			t.Errorf("%s: executable should not change without --force", version)
+		}
+	}
+
+	updater := newTestUpdater(t, server, "dev")
+	updater.Force = true
+	result, err := updater.Update()
+	if err != nil || !result.Updated {
+		t.Fatalf("Expected a forced update of a development build, got %+v, %v", result, err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [3]int
+		ok      bool
+	}{
+		{"v1.2.3", [3]int{1, 2, 3}, true},
+		{"1.10.0", [3]int{1, 10, 0}, true},
+		{"v1.2", [3]int{}, false},
+		{"v1.2.3-rc1", [3]int{}, false},
+		{"v0.0.0-20250706050000-abc123456789", [3]int{}, false},
+		{"dev", [3]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVersion(tt.version)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseVersion(%q) = %v, %v, want %v, %v", tt.version, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUpdateRejectsChecksumMismatch(t *testing.T) {
+	binary := []byte("tampered binary")
+	checksums := sha256Hex([]byte("expected binary")) + "  cclog-linux-amd64\n"
+	server := newReleaseServer(t, "v1.1.0", binary, checksums)
+	updater := newTestUpdater(t, server, "v1.0.0")
+
+	_, err := updater.Update()
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected checksum mismatch error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(updater.ExecutablePath)
+	if string(data) != "old binary" {
+		t.Error("Executable should not change when checksum verification fails")
+	}
+}
+
+func TestUpdateMissingPlatformBinary(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, "v1.1.0", binary, sha256Hex(binary)+"  cclog-linux-amd64\n")
+	updater := newTestUpdater(t, server, "v1.0.0")
+	updater.GOOS = "plan9"
+
+	if _, err := updater.Update(); err == nil {
+		t.Error("Expected error for missing platform binary")
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		goos   string
+		goarch string
+		want   string
+	}{
+		{goos: "linux", goarch: "amd64", want: "cclog-linux-amd64"},
+		{goos: "darwin", goarch: "arm64", want: "cclog-darwin-arm64"},
+		{goos: "windows", goarch: "amd64", want: "cclog-windows-amd64.exe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			u := &Updater{GOOS: tt.goos, GOARCH: tt.goarch}
+			if got := u.AssetName(); got != tt.want {
+				t.Errorf("AssetName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte("abc123  cclog-linux-amd64\nDEF456 *cclog-windows-amd64.exe\n")
+
+	if sum, err := findChecksum(checksums, "cclog-linux-amd64"); err != nil || sum != "abc123" {
+		t.Errorf("Expected abc123, got %q (err %v)", sum, err)
+	}
+	if sum, err := findChecksum(checksums, "cclog-windows-amd64.exe"); err != nil || sum != "def456" {
+		t.Errorf("Expected def456, got %q (err %v)", sum, err)
+	}
+	if _, err := findChecksum(checksums, "cclog-darwin-arm64"); err == nil {
+		t.Error("Expected error for missing checksum")
+	}
+}
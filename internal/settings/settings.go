@@ -0,0 +1,98 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Settings holds user preferences persisted between cclog runs
+type Settings struct {
+	// SplitRatio is the share of the TUI height given to the preview (0 means default)
+	SplitRatio float64 `json:"splitRatio,omitempty"`
+	// ExtraRoots lists additional log directories the TUI can switch to ("~/" is expanded)
+	ExtraRoots []string `json:"extraRoots,omitempty"`
+	// Theme names the TUI color preset: "auto" (default), "dark" or "light"
+	Theme string `json:"theme,omitempty"`
+	// Colors overrides individual theme colors, keyed by color name (e.g. "directory")
+	Colors map[string]string `json:"colors,omitempty"`
+	// Keys rebinds TUI actions, keyed by action name (e.g. "quit": ["q", "ctrl+q"])
+	Keys map[string][]string `json:"keys,omitempty"`
+	// RefreshInterval is the number of seconds between background re-scans of the TUI file list (0 disables them)
+	RefreshInterval int `json:"refreshInterval,omitempty"`
+	// MaxDepth limits how many directory levels below the TUI directory are searched recursively (0 means unlimited)
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// MaxFiles stops recursive searches after this many .jsonl files (0 means unlimited)
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// Ignore lists glob patterns of files and directories skipped by recursive searches (e.g. "archive*")
+	Ignore []string `json:"ignore,omitempty"`
+	// NameTemplate names the files written by --split-output when the --name-template flag is not given
+	NameTemplate string `json:"nameTemplate,omitempty"`
+	// Timezone is the IANA timezone (e.g. "UTC") timestamps are rendered in when the --timezone flag is not given
+	Timezone string `json:"timezone,omitempty"`
+	// RedactPatterns lists regular expressions replaced by --redact in addition to the built-in secret rules
+	RedactPatterns []string `json:"redactPatterns,omitempty"`
+	// DisableFilters names default filter rules to skip, e.g. "command-output" to keep local command output
+	DisableFilters []string `json:"disableFilters,omitempty"`
+	// ExcludePatterns are regular expressions; messages whose content matches one are filtered out
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+	// Editor is the command the TUI opens files with, e.g. "code --wait", when the --editor flag is not given
+	// (empty means $EDITOR, $VISUAL or a default editor)
+	Editor string `json:"editor,omitempty"`
+	// OpenWith is how the TUI opens sessions: "editor" (the default) or "browser" to render them as HTML
+	// in the default browser
+	OpenWith string `json:"openWith,omitempty"`
+	// ExportDir keeps the markdown of sessions opened from the TUI in this directory instead of temp files,
+	// when the --export-dir flag is not given ("~/" is expanded)
+	ExportDir string `json:"exportDir,omitempty"`
+	// PostExportHook is a shell command run after each file written by export, sync, kb, -o and --split-output,
+	// when the --hook flag is not given; the file and its session are passed in CCLOG_* environment variables
+	PostExportHook string `json:"postExportHook,omitempty"`
+	// HookTimeout is the number of seconds a hook may run before it is stopped (0 means 60)
+	HookTimeout int `json:"hookTimeout,omitempty"`
+}
+
+// DefaultPath returns the settings file location, e.g. ~/.config/cclog/config.json
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "cclog", "config.json"), nil
+}
+
+// Load reads settings from path. A missing file yields empty settings.
+func Load(path string) (Settings, error) {
+	var s Settings
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Settings{}, fmt.Errorf("failed to parse settings %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes settings to path, creating its directory if needed
+func Save(path string, s Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.SplitRatio != 0 {
+		t.Errorf("Expected empty settings, got %+v", s)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cclog", "config.json")
+
+	if err := Save(path, Settings{SplitRatio: 0.6}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if s.SplitRatio != 0.6 {
+		t.Errorf("Expected SplitRatio 0.6, got %f", s.SplitRatio)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid settings file")
+	}
+}
+
+func TestSaveAndLoadState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cclog", "state.json")
+
+	if s, err := LoadState(path); err != nil || len(s.RecentSessions) != 0 {
+		t.Fatalf("Expected empty state for missing file, got %+v, %v", s, err)
+	}
+
+	want := []string{"/logs/b.jsonl", "/logs/a.jsonl"}
+	if err := SaveState(path, State{RecentSessions: want}); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(s.RecentSessions) != 2 || s.RecentSessions[0] != want[0] || s.RecentSessions[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, s.RecentSessions)
+	}
+}
@@ -0,0 +1,77 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State holds data cclog records about its own usage, such as recently viewed sessions
+type State struct {
+	// RecentSessions lists the paths of sessions opened through cclog, most recent first
+	RecentSessions []string `json:"recentSessions,omitempty"`
+}
+
+// StateDir returns the directory cclog writes its own files to, e.g. ~/.config/cclog
+func StateDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "cclog"), nil
+}
+
+// DefaultStatePath returns the state file location, e.g. ~/.config/cclog/state.json
+func DefaultStatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// TempDir returns the directory for temporary files when the system temp directory
+// cannot be used, e.g. ~/.config/cclog/tmp
+func TempDir() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tmp"), nil
+}
+
+// LoadState reads state from path. A missing file yields empty state.
+func LoadState(path string) (State, error) {
+	var s State
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SaveState writes state to path, creating its directory if needed
+func SaveState(path string, s State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
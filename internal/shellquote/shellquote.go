@@ -0,0 +1,44 @@
+// Package shellquote quotes arguments for POSIX shells and PowerShell, for command lines that are printed
+// or copied for the user to paste. Commands cclog runs itself are started with argument lists instead.
+// Command and ChangeDirAndRun use the usual shell of the platform: PowerShell on Windows, POSIX shells elsewhere.
+package shellquote
+
+import "strings"
+
+// Quote quotes s for POSIX shells unless it only contains characters that need no quoting
+func Quote(s string) string {
+	if isPlain(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// PowerShell quotes s for PowerShell unless it only contains characters that need no quoting
+func PowerShell(s string) string {
+	if isPlain(s) {
+		return s
+	}
+	// Single-quoted strings are literal; only the quote itself is escaped, by doubling it
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Join quotes each word for POSIX shells and joins them with spaces
+func Join(words []string) string {
+	return join(words, Quote)
+}
+
+// join quotes each word with quote and joins them with spaces
+func join(words []string, quote func(string) string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isPlain reports whether s is not empty and only contains characters that no shell treats specially
+func isPlain(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:+,", r))
+	}) < 0
+}
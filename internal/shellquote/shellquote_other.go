@@ -0,0 +1,13 @@
+//go:build !windows
+
+package shellquote
+
+// Command quotes the words of a command line for POSIX shells
+func Command(words []string) string {
+	return Join(words)
+}
+
+// ChangeDirAndRun returns a POSIX shell command line that changes to dir and then runs words
+func ChangeDirAndRun(dir string, words []string) string {
+	return "cd " + Quote(dir) + " && " + Join(words)
+}
@@ -0,0 +1,43 @@
+package shellquote
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"/src/app":     "/src/app",
+		"/src/my app":  "'/src/my app'",
+		"it's":         `'it'\''s'`,
+		"":             "''",
+		"--flag=value": "--flag=value",
+		"id;rm -rf ~":  "'id;rm -rf ~'",
+		"$(whoami)":    "'$(whoami)'",
+	}
+	for input, want := range tests {
+		if got := Quote(input); got != want {
+			t.Errorf("Quote(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestPowerShell(t *testing.T) {
+	tests := map[string]string{
+		`C:\Users\me\app`:   `'C:\Users\me\app'`,
+		"abc-123":           "abc-123",
+		`C:\my app`:         `'C:\my app'`,
+		"it's":              "'it''s'",
+		"$env:USERPROFILE":  "'$env:USERPROFILE'",
+		"id; Remove-Item x": "'id; Remove-Item x'",
+		"":                  "''",
+	}
+	for input, want := range tests {
+		if got := PowerShell(input); got != want {
+			t.Errorf("PowerShell(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	if got := Join([]string{"claude", "-r", "a b"}); got != "claude -r 'a b'" {
+		t.Errorf("Unexpected command line %s", got)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package shellquote
+
+// Command quotes the words of a command line for PowerShell
+func Command(words []string) string {
+	return join(words, PowerShell)
+}
+
+// ChangeDirAndRun returns a PowerShell command line that changes to dir and then runs words.
+// -LiteralPath keeps wildcard characters such as [ in the directory from being expanded.
+func ChangeDirAndRun(dir string, words []string) string {
+	return "Set-Location -LiteralPath " + PowerShell(dir) + "; " + Command(words)
+}
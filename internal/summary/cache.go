@@ -0,0 +1,102 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/settings"
+)
+
+// Cache stores the LLM summaries of sessions in a JSON file, keyed by the absolute path of the log.
+// A summary is only served while the size and modification time of its log are unchanged.
+type Cache struct {
+	path     string
+	sessions map[string]CacheEntry
+}
+
+// CacheEntry is the cached summary of a log
+type CacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // Unix nanoseconds
+	Backend string `json:"backend"` // Name of the Summarizer that wrote it
+	Summary string `json:"summary"`
+}
+
+// cacheFile is the format of the cache file
+type cacheFile struct {
+	Sessions map[string]CacheEntry `json:"sessions"`
+}
+
+// DefaultCachePath returns the location of the summary cache, e.g. ~/.config/cclog/summaries.json
+func DefaultCachePath() (string, error) {
+	dir, err := settings.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "summaries.json"), nil
+}
+
+// LoadCache reads the cache at path. A missing file yields an empty cache.
+func LoadCache(path string) (*Cache, error) {
+	c := &Cache{path: path, sessions: make(map[string]CacheEntry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary cache: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse summary cache %s: %w", path, err)
+	}
+	if file.Sessions != nil {
+		c.sessions = file.Sessions
+	}
+	return c, nil
+}
+
+// Lookup returns the cached summary of the log at path if it was written for this size and modification time
+func (c *Cache) Lookup(path string, size int64, modTime time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	entry, ok := c.sessions[cacheKey(path)]
+	if !ok || entry.Size != size || entry.ModTime != modTime.UnixNano() {
+		return "", false
+	}
+	return entry.Summary, true
+}
+
+// Put records the summary of the log at path, written by backend, replacing the previous one
+func (c *Cache) Put(path string, size int64, modTime time.Time, backend, summary string) {
+	c.sessions[cacheKey(path)] = CacheEntry{Size: size, ModTime: modTime.UnixNano(), Backend: backend, Summary: summary}
+}
+
+// Save writes the cache to its file, creating its directory if needed
+func (c *Cache) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create summary cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cacheFile{Sessions: c.sessions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary cache: %w", err)
+	}
+	return nil
+}
+
+// cacheKey returns the absolute form of path, so a log is found whatever directory it was named from
+func cacheKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,51 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "summaries.json")
+	modTime := time.Date(2025, 7, 6, 5, 1, 29, 618, time.UTC)
+
+	cache, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if _, ok := cache.Lookup("session.jsonl", 10, modTime); ok {
+		t.Error("Expected an empty cache")
+	}
+	cache.Put("session.jsonl", 10, modTime, "claude", "Fixed a test.")
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	cache, err = LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	wd, _ := os.Getwd()
+	if got, ok := cache.Lookup(filepath.Join(wd, "session.jsonl"), 10, modTime); !ok || got != "Fixed a test." {
+		t.Errorf("Lookup() = %q, %t", got, ok)
+	}
+	if _, ok := cache.Lookup("session.jsonl", 11, modTime); ok {
+		t.Error("Expected no summary for a log of another size")
+	}
+	if _, ok := cache.Lookup("session.jsonl", 10, modTime.Add(time.Nanosecond)); ok {
+		t.Error("Expected no summary for a log modified since")
+	}
+	var nilCache *Cache
+	if _, ok := nilCache.Lookup("session.jsonl", 10, modTime); ok {
+		t.Error("Expected no summary from a nil cache")
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCache(path); err == nil {
+		t.Error("Expected an error for a corrupt cache")
+	}
+}
@@ -0,0 +1,188 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// prompt asks the LLM for the summary; the transcript follows it
+const prompt = "Summarize this Claude Code session in one paragraph of at most 80 words: what the user wanted, " +
+	"what was done and how it ended. Reply with the paragraph only, without a heading."
+
+// transcriptMaxTokens caps the transcript sent to the LLM; the oldest messages are left out of longer sessions
+const transcriptMaxTokens = 30000
+
+// Settings of the Anthropic API backend
+const (
+	apiURL       = "https://api.anthropic.com/v1/messages"
+	apiVersion   = "2023-06-01"
+	apiModel     = "claude-haiku-4-5"
+	apiMaxTokens = 400
+	// APIKeyEnv names the environment variable holding the API key
+	APIKeyEnv = "ANTHROPIC_API_KEY"
+)
+
+// lookPath and execCommand are variables that can be replaced in tests to mock os/exec
+var (
+	lookPath    = exec.LookPath
+	execCommand = exec.CommandContext
+)
+
+// Summarizer is a backend writing a paragraph summary of a session transcript with an LLM
+type Summarizer interface {
+	// Name identifies the backend in the summary cache, e.g. "claude"
+	Name() string
+	// Summarize returns the summary of transcript, a compacted session from Transcript
+	Summarize(ctx context.Context, transcript string) (string, error)
+}
+
+// NewSummarizer returns the LLM backend to use: the claude CLI if it is on the PATH, which uses its own
+// login, or else the Anthropic API if APIKeyEnv is set
+func NewSummarizer() (Summarizer, error) {
+	if program, err := lookPath("claude"); err == nil {
+		return ClaudeCLI{Program: program}, nil
+	}
+	if key := os.Getenv(APIKeyEnv); key != "" {
+		return API{Key: key}, nil
+	}
+	return nil, fmt.Errorf("no LLM to summarize with: install the claude CLI or set %s", APIKeyEnv)
+}
+
+// Transcript compacts log for the LLM: the messages kept by the current filter rules as an H:/A:
+// transcript without tool calls (see formatter.FormatConversationContext), leaving out the oldest
+// messages of long sessions
+func Transcript(log *types.ConversationLog) string {
+	return formatter.FormatConversationContext(filter.Current().FilterConversationLog(log, true), transcriptMaxTokens)
+}
+
+// ClaudeCLI summarizes with the local claude CLI in print mode, passing the transcript on stdin
+type ClaudeCLI struct {
+	Program string // Path of the claude program
+}
+
+// Name implements Summarizer
+func (ClaudeCLI) Name() string { return "claude" }
+
+// Summarize implements Summarizer
+func (c ClaudeCLI) Summarize(ctx context.Context, transcript string) (string, error) {
+	cmd := execCommand(ctx, c.Program, "-p", prompt)
+	cmd.Stdin = strings.NewReader(transcript)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("claude failed: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	return checkSummary(string(output))
+}
+
+// API summarizes with the Anthropic Messages API
+type API struct {
+	Key    string
+	Model  string       // Empty means apiModel
+	URL    string       // Empty means apiURL
+	Client *http.Client // Nil means http.DefaultClient
+}
+
+// Name implements Summarizer
+func (a API) Name() string { return "api:" + a.model() }
+
+// model returns the model the summaries are written with
+func (a API) model() string {
+	if a.Model == "" {
+		return apiModel
+	}
+	return a.Model
+}
+
+// apiResponse holds the fields of a Messages API response and error that summaries need
+type apiResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Summarize implements Summarizer
+func (a API) Summarize(ctx context.Context, transcript string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      a.model(),
+		"max_tokens": apiMaxTokens,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt + "\n\n" + transcript},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	url := a.URL
+	if url == "" {
+		url = apiURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("x-api-key", a.Key)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("anthropic API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("anthropic API request failed: %w", err)
+	}
+
+	var decoded apiResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", fmt.Errorf("anthropic API returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		if decoded.Error != nil {
+			return "", fmt.Errorf("anthropic API returned %s: %s", resp.Status, decoded.Error.Message)
+		}
+		return "", fmt.Errorf("anthropic API returned %s", resp.Status)
+	}
+	var text strings.Builder
+	for _, block := range decoded.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return checkSummary(text.String())
+}
+
+// checkSummary trims the summary an LLM replied with and rejects an empty one
+func checkSummary(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("the LLM returned an empty summary")
+	}
+	return text, nil
+}
@@ -0,0 +1,96 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestNewSummarizer(t *testing.T) {
+	originalLookPath := lookPath
+	t.Cleanup(func() { lookPath = originalLookPath })
+
+	lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	if s, err := NewSummarizer(); err != nil || s.Name() != "claude" {
+		t.Errorf("Expected the claude CLI, got %v, %v", s, err)
+	}
+
+	lookPath = func(name string) (string, error) { return "", exec.ErrNotFound }
+	t.Setenv(APIKeyEnv, "sk-test")
+	if s, err := NewSummarizer(); err != nil || s.Name() != "api:"+apiModel {
+		t.Errorf("Expected the API, got %v, %v", s, err)
+	}
+
+	t.Setenv(APIKeyEnv, "")
+	if _, err := NewSummarizer(); err == nil || !strings.Contains(err.Error(), APIKeyEnv) {
+		t.Errorf("Expected an error naming %s, got %v", APIKeyEnv, err)
+	}
+}
+
+func TestClaudeCLI(t *testing.T) {
+	originalExecCommand := execCommand
+	t.Cleanup(func() { execCommand = originalExecCommand })
+	var args []string
+	execCommand = func(ctx context.Context, name string, arg ...string) *exec.Cmd {
+		args = append([]string{name}, arg...)
+		// Answer with the number of transcript lines read from stdin
+		return exec.CommandContext(ctx, "sh", "-c", `echo "  Read $(wc -l) lines.  "`)
+	}
+
+	got, err := ClaudeCLI{Program: "/usr/bin/claude"}.Summarize(context.Background(), "H: hi\n\nA: hello\n")
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if got != "Read 3 lines." {
+		t.Errorf("Summarize() = %q", got)
+	}
+	if len(args) != 3 || args[0] != "/usr/bin/claude" || args[1] != "-p" || args[2] != prompt {
+		t.Errorf("Unexpected command %v", args)
+	}
+
+	execCommand = func(ctx context.Context, name string, arg ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "echo not logged in >&2; exit 1")
+	}
+	if _, err := (ClaudeCLI{Program: "claude"}).Summarize(context.Background(), "H: hi\n"); err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("Expected the error output of claude, got %v", err)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-test" || r.Header.Get("anthropic-version") != apiVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+			return
+		}
+		var request struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Model != apiModel ||
+			len(request.Messages) != 1 || !strings.HasSuffix(request.Messages[0].Content, "H: hi\n") {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"type":"error","error":{"message":"unexpected request"}}`)
+			return
+		}
+		io.WriteString(w, `{"content":[{"type":"text","text":"The user said hi.\n"}]}`)
+	}))
+	defer server.Close()
+
+	got, err := API{Key: "sk-test", URL: server.URL}.Summarize(context.Background(), "H: hi\n")
+	if err != nil || got != "The user said hi." {
+		t.Errorf("Summarize() = %q, %v", got, err)
+	}
+
+	_, err = API{Key: "wrong", URL: server.URL}.Summarize(context.Background(), "H: hi\n")
+	if err == nil || !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Errorf("Expected the API error message, got %v", err)
+	}
+}
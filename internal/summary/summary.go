@@ -0,0 +1,213 @@
+// Package summary summarizes sessions: Extract takes what was asked, which files were touched and how
+// the session ended from the log as is, while a Summarizer has an LLM write a paragraph, kept in a Cache.
+package summary
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Limits of the summary: the length of the prompt and answer excerpts and the number of files listed
+const (
+	promptMaxRunes = 200
+	answerMaxRunes = 300
+	maxFiles       = 5
+)
+
+// fileTools maps the tools touching files to the input field holding the path and whether they change the file
+var fileTools = map[string]struct {
+	field   string
+	changes bool
+}{
+	"Read":         {"file_path", false},
+	"Edit":         {"file_path", true},
+	"MultiEdit":    {"file_path", true},
+	"Write":        {"file_path", true},
+	"NotebookEdit": {"notebook_path", true},
+}
+
+// Summary is an extractive summary of a session
+type Summary struct {
+	Prompt    string   // The first prompt of the user
+	Files     []string // The files touched most, changed ones first, relative to the working directory when inside it
+	MoreFiles int      // The number of touched files left out of Files
+	Answer    string   // The start of the last answer of the assistant
+}
+
+// Extract summarizes log. Prompts and answers are those kept by the current filter rules, so command
+// output and interrupted requests are skipped; files are taken from every tool call.
+func Extract(log *types.ConversationLog) Summary {
+	var s Summary
+	messages := formatter.SortMessagesChronologically(log.Messages)
+	for _, msg := range filter.Current().FilterMessages(messages, true) {
+		text := strings.TrimSpace(types.ExtractTextContent(msg.Message))
+		if text == "" {
+			continue
+		}
+		switch filter.MessageRole(msg) {
+		case filter.RoleUser:
+			if s.Prompt == "" {
+				s.Prompt = truncate(strings.Join(strings.Fields(text), " "), promptMaxRunes)
+			}
+		case filter.RoleAssistant:
+			s.Answer = excerpt(text)
+		}
+	}
+	s.Files, s.MoreFiles = touchedFiles(messages)
+	return s
+}
+
+// IsEmpty reports whether nothing was found to summarize
+func (s Summary) IsEmpty() bool {
+	return s.Prompt == "" && len(s.Files) == 0 && s.Answer == ""
+}
+
+// Map returns the summary with f applied to its texts and file paths, e.g. to redact them
+func (s Summary) Map(f func(string) string) Summary {
+	mapped := Summary{Prompt: f(s.Prompt), MoreFiles: s.MoreFiles, Answer: f(s.Answer)}
+	for _, file := range s.Files {
+		mapped.Files = append(mapped.Files, f(file))
+	}
+	return mapped
+}
+
+// Markdown renders the summary as a "Summary" section, or returns "" for an empty summary
+func (s Summary) Markdown() string {
+	if s.IsEmpty() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Summary\n\n")
+	if s.Prompt != "" {
+		fmt.Fprintf(&sb, "- **Asked:** %s\n", s.Prompt)
+	}
+	if len(s.Files) > 0 {
+		files := make([]string, len(s.Files))
+		for i, file := range s.Files {
+			files[i] = "`" + file + "`"
+		}
+		line := strings.Join(files, ", ")
+		if s.MoreFiles > 0 {
+			line += fmt.Sprintf(" and %d more", s.MoreFiles)
+		}
+		fmt.Fprintf(&sb, "- **Files:** %s\n", line)
+	}
+	if s.Answer != "" {
+		fmt.Fprintf(&sb, "- **Outcome:** %s\n", s.Answer)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// touchedFiles returns the files read or changed by the tool calls in messages, at most maxFiles of them,
+// and the number of the others. Changed files come first, then the most touched, then the first touched.
+func touchedFiles(messages []types.Message) ([]string, int) {
+	type file struct {
+		path    string
+		changes int
+		touches int
+		order   int
+	}
+	byPath := make(map[string]*file)
+	var files []*file
+	for _, msg := range messages {
+		if msg.Type != "assistant" {
+			continue
+		}
+		for _, block := range msg.Message.Blocks() {
+			use, ok := block.(types.ToolUseBlock)
+			if !ok {
+				continue
+			}
+			tool, ok := fileTools[use.Name]
+			if !ok {
+				continue
+			}
+			path, _ := use.Input[tool.field].(string)
+			if path == "" {
+				continue
+			}
+			path = relativeTo(msg.CWD, path)
+			f := byPath[path]
+			if f == nil {
+				f = &file{path: path, order: len(files)}
+				byPath[path] = f
+				files = append(files, f)
+			}
+			f.touches++
+			if tool.changes {
+				f.changes++
+			}
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a.changes > 0) != (b.changes > 0) {
+			return a.changes > 0
+		}
+		if a.touches != b.touches {
+			return a.touches > b.touches
+		}
+		return a.order < b.order
+	})
+	var paths []string
+	for _, f := range files[:min(len(files), maxFiles)] {
+		paths = append(paths, f.path)
+	}
+	return paths, len(files) - len(paths)
+}
+
+// relativeTo returns path relative to the working directory cwd if it is inside it
+func relativeTo(cwd, path string) string {
+	if cwd == "" {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// excerpt returns the first paragraph of prose in text, outside code blocks, on one line and truncated
+func excerpt(text string) string {
+	var paragraph []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	return truncate(strings.Join(strings.Fields(strings.Join(paragraph, " ")), " "), answerMaxRunes)
+}
+
+// truncate shortens text to at most max runes, marking truncation with an ellipsis
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,69 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+const sessionContent = `{"type":"user","cwd":"/src/app","message":{"role":"user","content":"<command-name>/clear</command-name>"},"uuid":"c1","timestamp":"2025-07-06T05:00:00Z"}
+{"type":"user","cwd":"/src/app","message":{"role":"user","content":"Fix the flaky\ntest in the parser"},"uuid":"u1","timestamp":"2025-07-06T05:01:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/src/app/README.md"}}]},"uuid":"a1","timestamp":"2025-07-06T05:01:10Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/src/app/parser.go"}}]},"uuid":"a2","timestamp":"2025-07-06T05:01:20Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Edit","input":{"file_path":"/src/app/parser_test.go"}}]},"uuid":"a3","timestamp":"2025-07-06T05:01:30Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Read","input":{"file_path":"/src/app/parser.go"}}]},"uuid":"a4","timestamp":"2025-07-06T05:01:40Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":[{"type":"tool_use","id":"t5","name":"Write","input":{"file_path":"/etc/app.conf"}}]},"uuid":"a5","timestamp":"2025-07-06T05:01:50Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":"Fixed.\n\n` + "```go\\nfunc x() {}\\n```" + `"},"uuid":"a6","timestamp":"2025-07-06T05:02:00Z"}
+{"type":"assistant","cwd":"/src/app","message":{"role":"assistant","content":"` + "```\\nok\\n```" + `\nThe test no longer depends on\nthe clock.\n\nDetails follow."},"uuid":"a7","timestamp":"2025-07-06T05:03:00Z"}
+`
+
+func TestExtract(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	log, err := parser.ParseJSONLFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse session: %v", err)
+	}
+
+	got := Extract(log)
+	if want := "Fix the flaky test in the parser"; got.Prompt != want {
+		t.Errorf("Prompt = %q, want %q", got.Prompt, want)
+	}
+	if want := []string{"parser_test.go", "/etc/app.conf", "parser.go", "README.md"}; strings.Join(got.Files, ",") != strings.Join(want, ",") || got.MoreFiles != 0 {
+		t.Errorf("Files = %v (+%d), want %v", got.Files, got.MoreFiles, want)
+	}
+	if want := "The test no longer depends on the clock."; got.Answer != want {
+		t.Errorf("Answer = %q, want %q", got.Answer, want)
+	}
+
+	want := "## Summary\n\n" +
+		"- **Asked:** Fix the flaky test in the parser\n" +
+		"- **Files:** `parser_test.go`, `/etc/app.conf`, `parser.go`, `README.md`\n" +
+		"- **Outcome:** The test no longer depends on the clock.\n\n"
+	if markdown := got.Markdown(); markdown != want {
+		t.Errorf("Markdown() = %q, want %q", markdown, want)
+	}
+}
+
+func TestTouchedFilesLimit(t *testing.T) {
+	s := Summary{Files: []string{"a", "b"}, MoreFiles: 3}
+	if want := "- **Files:** `a`, `b` and 3 more\n"; !strings.Contains(s.Markdown(), want) {
+		t.Errorf("Expected %q in:\n%s", want, s.Markdown())
+	}
+	if (Summary{}).Markdown() != "" {
+		t.Error("Expected no markdown for an empty summary")
+	}
+}
+
+func TestMap(t *testing.T) {
+	s := Summary{Prompt: "key sk-1", Files: []string{"sk-2.txt"}, MoreFiles: 1, Answer: "sk-3"}
+	got := s.Map(func(text string) string { return strings.ReplaceAll(text, "sk-", "[x]") })
+	if got.Prompt != "key [x]1" || got.Files[0] != "[x]2.txt" || got.Answer != "[x]3" || got.MoreFiles != 1 {
+		t.Errorf("Map() = %+v", got)
+	}
+}
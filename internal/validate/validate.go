@@ -0,0 +1,241 @@
+// Package validate checks the lines of Claude Code JSONL logs against the fields Claude Code writes:
+// required fields, their JSON types and the known values of the type fields. It reports every
+// deviation with its line number, to check logs written by other tools that claim to be compatible.
+// Fields it does not know are allowed, since Claude Code keeps adding them.
+package validate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+// LineTypes are the known values of the type field of a line
+var LineTypes = []string{"user", "assistant", "system", "summary", "file-history-snapshot"}
+
+// BlockTypes are the known values of the type field of a content block
+var BlockTypes = []string{"text", "thinking", "redacted_thinking", "tool_use", "tool_result", "image", "document", "server_tool_use", "web_search_tool_result"}
+
+// Issue is a deviation of one line from the schema
+type Issue struct {
+	Line int
+	// Field is the path of the field, e.g. "message.content[1].id", or "" for the line as a whole
+	Field   string
+	Message string
+}
+
+// String formats the issue, e.g. "line 3: message.role: expected string, got number"
+func (i Issue) String() string {
+	if i.Field == "" {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Field, i.Message)
+}
+
+// File checks every line of the file at path, which may be compressed, and returns the issues found and the number of lines checked
+func File(ctx context.Context, path string) ([]Issue, int, error) {
+	file, err := parser.OpenLog(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+	return Reader(ctx, file)
+}
+
+// Reader checks every line of r like File. Empty lines are skipped, as the parser does.
+func Reader(ctx context.Context, r io.Reader) ([]Issue, int, error) {
+	reader := bufio.NewReader(r)
+	var issues []Issue
+	checked := 0
+	for lineNum := 1; ; lineNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, fmt.Errorf("failed to read line %d: %w", lineNum, err)
+		}
+		if line := strings.TrimSpace(string(data)); line != "" {
+			issues = append(issues, Line(lineNum, []byte(line))...)
+			checked++
+		}
+		if err != nil {
+			return issues, checked, nil
+		}
+	}
+}
+
+// Line checks one line, numbered n in the reported issues
+func Line(n int, data []byte) []Issue {
+	c := &checker{line: n}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.report("", "invalid JSON: %v", err)
+		return c.issues
+	}
+	line, ok := value.(map[string]interface{})
+	if !ok {
+		c.report("", "expected an object, got %s", kind(value))
+		return c.issues
+	}
+	c.checkLine(line)
+	return c.issues
+}
+
+// checker collects the issues of one line
+type checker struct {
+	line   int
+	issues []Issue
+}
+
+// report adds an issue for the field at path
+func (c *checker) report(path, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Line: c.line, Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkLine checks the fields of a log line
+func (c *checker) checkLine(line map[string]interface{}) {
+	lineType, ok := c.field(line, "", "type", true, "string").(string)
+	if !ok {
+		return
+	}
+	if !slices.Contains(LineTypes, lineType) {
+		c.report("type", "unknown type %q (known: %s)", lineType, strings.Join(LineTypes, ", "))
+		return
+	}
+
+	switch lineType {
+	case "summary":
+		c.field(line, "", "summary", true, "string")
+		c.field(line, "", "leafUuid", true, "string")
+		return
+	case "file-history-snapshot":
+		return
+	}
+
+	// Conversation lines: user, assistant and system
+	c.field(line, "", "uuid", true, "string")
+	c.field(line, "", "sessionId", true, "string")
+	c.field(line, "", "parentUuid", true, "string", "null")
+	if timestamp, ok := c.field(line, "", "timestamp", true, "string").(string); ok {
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			c.report("timestamp", "not an RFC 3339 time: %q", timestamp)
+		}
+	}
+	for _, name := range []string{"isSidechain", "isMeta", "isApiErrorMessage"} {
+		c.field(line, "", name, false, "boolean")
+	}
+	for _, name := range []string{"userType", "cwd", "version", "requestId", "gitBranch"} {
+		c.field(line, "", name, false, "string")
+	}
+
+	if lineType == "system" {
+		c.field(line, "", "content", false, "string")
+		c.field(line, "", "message", false, "object")
+		return
+	}
+	message, ok := c.field(line, "", "message", true, "object").(map[string]interface{})
+	if !ok {
+		return
+	}
+	if role, ok := c.field(message, "message", "role", true, "string").(string); ok && role != lineType {
+		c.report("message.role", "role %q does not match the line type %q", role, lineType)
+	}
+	c.field(message, "message", "model", false, "string")
+	c.field(message, "message", "id", false, "string")
+	c.checkContent(message, "message")
+}
+
+// checkContent checks the content field of a message or tool result: a string or a list of blocks
+func (c *checker) checkContent(parent map[string]interface{}, path string) {
+	blocks, ok := c.field(parent, path, "content", path == "message", "string", "array").([]interface{})
+	if !ok {
+		return
+	}
+	for i, item := range blocks {
+		c.checkBlock(item, fmt.Sprintf("%s.content[%d]", path, i))
+	}
+}
+
+// checkBlock checks a content block
+func (c *checker) checkBlock(item interface{}, path string) {
+	block, ok := item.(map[string]interface{})
+	if !ok {
+		c.report(path, "expected an object, got %s", kind(item))
+		return
+	}
+	blockType, ok := c.field(block, path, "type", true, "string").(string)
+	if !ok {
+		return
+	}
+	switch blockType {
+	case "text":
+		c.field(block, path, "text", true, "string")
+	case "thinking":
+		c.field(block, path, "thinking", true, "string")
+	case "tool_use":
+		c.field(block, path, "id", true, "string")
+		c.field(block, path, "name", true, "string")
+		c.field(block, path, "input", true, "object")
+	case "tool_result":
+		c.field(block, path, "tool_use_id", true, "string")
+		c.field(block, path, "is_error", false, "boolean")
+		c.checkContent(block, path)
+	case "image":
+		if source, ok := c.field(block, path, "source", true, "object").(map[string]interface{}); ok {
+			c.field(source, path+".source", "type", true, "string")
+		}
+	default:
+		if !slices.Contains(BlockTypes, blockType) {
+			c.report(path+".type", "unknown block type %q (known: %s)", blockType, strings.Join(BlockTypes, ", "))
+		}
+	}
+}
+
+// field returns the value of a field if it has one of the kinds, and reports it otherwise:
+// missing if required, or of the wrong kind. It returns nil for reported and missing fields.
+func (c *checker) field(object map[string]interface{}, parent, name string, required bool, kinds ...string) interface{} {
+	path := name
+	if parent != "" {
+		path = parent + "." + name
+	}
+	value, ok := object[name]
+	if !ok {
+		if required {
+			c.report(path, "missing required field")
+		}
+		return nil
+	}
+	if !slices.Contains(kinds, kind(value)) {
+		c.report(path, "expected %s, got %s", strings.Join(kinds, " or "), kind(value))
+		return nil
+	}
+	return value
+}
+
+// kind names the JSON type of a decoded value
+func kind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
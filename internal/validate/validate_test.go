@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLine(t *testing.T) {
+	const header = `"uuid":"u1","sessionId":"s1","parentUuid":null,"timestamp":"2025-07-06T05:00:00.000Z"`
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{
+			name: "valid user line",
+			line: `{"type":"user",` + header + `,"message":{"role":"user","content":"Hello"}}`,
+		},
+		{
+			name: "valid assistant blocks",
+			line: `{"type":"assistant",` + header + `,"message":{"role":"assistant","content":[{"type":"text","text":"Hi"},{"type":"tool_use","id":"t1","name":"Bash","input":{}}]}}`,
+		},
+		{
+			name: "valid summary",
+			line: `{"type":"summary","summary":"Title","leafUuid":"u1"}`,
+		},
+		{
+			name: "invalid JSON",
+			line: `{"type":`,
+			want: []string{"line 1: invalid JSON: unexpected end of JSON input"},
+		},
+		{
+			name: "not an object",
+			line: `[1]`,
+			want: []string{"line 1: expected an object, got array"},
+		},
+		{
+			name: "unknown type",
+			line: `{"type":"note"}`,
+			want: []string{`line 1: type: unknown type "note" (known: user, assistant, system, summary, file-history-snapshot)`},
+		},
+		{
+			name: "missing and mistyped fields",
+			line: `{"type":"user","uuid":1,"parentUuid":null,"timestamp":"yesterday","isMeta":"no","message":{"role":"assistant","content":42}}`,
+			want: []string{
+				"line 1: uuid: expected string, got number",
+				"line 1: sessionId: missing required field",
+				`line 1: timestamp: not an RFC 3339 time: "yesterday"`,
+				"line 1: isMeta: expected boolean, got string",
+				`line 1: message.role: role "assistant" does not match the line type "user"`,
+				"line 1: message.content: expected string or array, got number",
+			},
+		},
+		{
+			name: "invalid blocks",
+			line: `{"type":"user",` + header + `,"message":{"role":"user","content":["text",{"type":"tool_result","content":[{"type":"text"}]},{"type":"video"}]}}`,
+			want: []string{
+				"line 1: message.content[0]: expected an object, got string",
+				"line 1: message.content[1].tool_use_id: missing required field",
+				"line 1: message.content[1].content[0].text: missing required field",
+				`line 1: message.content[2].type: unknown block type "video" (known: ` + strings.Join(BlockTypes, ", ") + ")",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range Line(1, []byte(tt.line)) {
+				got = append(got, issue.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Line() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	input := `{"type":"summary","summary":"Title","leafUuid":"u1"}` + "\n\n" + `{"type":"summary"}` + "\n" + `not json`
+	issues, checked, err := Reader(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Reader failed: %v", err)
+	}
+	if checked != 3 {
+		t.Errorf("Expected 3 checked lines, got %d", checked)
+	}
+	if len(issues) != 3 || issues[0].Line != 3 || issues[2].Line != 4 {
+		t.Errorf("Expected two issues on line 3 and one on line 4 (the last line without a newline), got %+v", issues)
+	}
+}
+
+func TestFileSample(t *testing.T) {
+	issues, checked, err := File(context.Background(), filepath.Join("..", "..", "testdata", "sample.jsonl"))
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	if checked == 0 || len(issues) != 0 {
+		t.Errorf("Expected the sample log to be valid, got %d lines and issues %+v", checked, issues)
+	}
+}
@@ -0,0 +1,107 @@
+// Package webhook posts chat messages to Slack and Discord incoming webhooks, to share a session in a
+// team channel.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payload fields holding the text of a message
+const (
+	SlackField   = "text"
+	DiscordField = "content"
+)
+
+// maxRetries bounds the attempts at a message the webhook asks to send again later
+const maxRetries = 3
+
+// maxRetryDelay caps the wait before sending a rate limited message again
+const maxRetryDelay = 30 * time.Second
+
+// Webhook is an incoming webhook of a channel
+type Webhook struct {
+	URL    string
+	Field  string       // SlackField or DiscordField
+	Client *http.Client // Nil means http.DefaultClient
+}
+
+// Post sends messages to the webhook in order, one request each. Rate limited messages are sent again
+// after the delay the webhook asks for. Errors do not include the URL, which holds the webhook's secret.
+func (w Webhook) Post(ctx context.Context, messages []string) error {
+	for i, message := range messages {
+		if err := w.post(ctx, message); err != nil {
+			return fmt.Errorf("failed to post message %d of %d: %w", i+1, len(messages), err)
+		}
+	}
+	return nil
+}
+
+// post sends one message, waiting and trying again while the webhook is rate limited
+func (w Webhook) post(ctx context.Context, message string) error {
+	payload := map[string]interface{}{w.Field: message}
+	if w.Field == DiscordField {
+		// Discord would ping the users, roles and @everyone mentioned in the session
+		payload["allowed_mentions"] = map[string][]string{"parse": {}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("invalid webhook URL")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("webhook request failed")
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries:
+			select {
+			case <-time.After(retryDelay(resp.Header.Get("Retry-After"))):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		default:
+			if text := strings.TrimSpace(string(data)); text != "" {
+				return fmt.Errorf("webhook returned %s: %s", resp.Status, text)
+			}
+			return fmt.Errorf("webhook returned %s", resp.Status)
+		}
+	}
+}
+
+// retryDelay returns the wait a Retry-After header asks for in seconds, capped at maxRetryDelay
+func retryDelay(header string) time.Duration {
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+	if delay := time.Duration(seconds * float64(time.Second)); delay < maxRetryDelay {
+		return delay
+	}
+	return maxRetryDelay
+}
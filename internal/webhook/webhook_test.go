@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPost(t *testing.T) {
+	var received []string
+	limited := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Content         string `json:"content"`
+			AllowedMentions *struct {
+				Parse []string `json:"parse"`
+			} `json:"allowed_mentions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Mentions in the session must not ping anyone
+		if payload.AllowedMentions == nil || payload.AllowedMentions.Parse == nil || len(payload.AllowedMentions.Parse) != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// The second message is rate limited once
+		if payload.Content == "two" && !limited {
+			limited = true
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		received = append(received, payload.Content)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := Webhook{URL: server.URL, Field: DiscordField}
+	if err := hook.Post(context.Background(), []string{"one", "two", "three"}); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if strings.Join(received, ",") != "one,two,three" {
+		t.Errorf("Received %q", received)
+	}
+}
+
+func TestPostError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "no_service")
+	}))
+	defer server.Close()
+
+	err := Webhook{URL: server.URL + "/T000/B000/secret", Field: SlackField}.Post(context.Background(), []string{"a", "b"})
+	if err == nil || !strings.Contains(err.Error(), "message 1 of 2") || !strings.Contains(err.Error(), "404 Not Found: no_service") {
+		t.Errorf("Expected the webhook error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Error reveals the webhook URL: %v", err)
+	}
+}
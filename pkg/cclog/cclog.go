@@ -0,0 +1,162 @@
+// Package cclog converts Claude Code conversation logs to Markdown.
+// It is the stable API for Go programs that embed cclog instead of running the command.
+package cclog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/export"
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Options controls how a conversation is converted. The zero value matches running cclog without flags.
+type Options struct {
+	IncludeAll        bool // Keep the messages the filter rules drop and show tool calls as placeholders
+	ShowUUID          bool // Show the UUID of each message
+	ShowModel         bool // Name the model of each assistant message and the Claude Code version where it changes
+	ShowRequestIDs    bool // Show request IDs of assistant messages and keep API errors, marked with their request IDs
+	CollapseThreshold int  // Collapse messages longer than this many lines (0 disables)
+	OmitTimestamps    bool // Leave out the time of each message
+	MaxToolOutput     int  // Truncate tool results longer than this many characters (0 disables)
+	PairTools         bool // With IncludeAll, show each tool result right after its tool call
+	ToolSummary       bool // With IncludeAll, add a table of the tool calls after each assistant turn
+	TableOfContents   bool // List the user's prompts at the top, linking to them
+	MessageAnchors    bool // Put an anchor derived from its UUID before each message
+	Wrap              int  // Hard-wrap prose lines at this many columns, leaving code untouched (0 disables)
+	// Timezone renders message timestamps in this location (nil means the system timezone)
+	Timezone *time.Location
+	// Profile is an output profile such as "print" ("" means the default)
+	Profile string
+	// Rules filters the messages (nil means the rules installed with filter.SetCurrent)
+	Rules *filter.RuleSet
+	// Tools selects which tools' calls and results are kept
+	Tools filter.ToolFilter
+	// Roles keeps only the messages of these roles (empty keeps all)
+	Roles []string
+}
+
+// Session describes a conversation log found by ListSessions
+type Session struct {
+	Path      string
+	SessionID string
+	Title     string
+	Project   string // Base name of the working directory of the session
+	Messages  int
+	ModTime   time.Time
+}
+
+// Parse reads a JSONL conversation log file
+func Parse(path string) (*types.ConversationLog, error) {
+	return ParseContext(context.Background(), path)
+}
+
+// ParseContext reads a conversation log like Parse, stopping with ctx.Err() when ctx is done
+func ParseContext(ctx context.Context, path string) (*types.ConversationLog, error) {
+	return parser.ParseJSONLFileContext(ctx, path)
+}
+
+// Convert renders log as Markdown. The input log is not modified.
+func Convert(log *types.ConversationLog, opts Options) (string, error) {
+	var sb strings.Builder
+	if err := ConvertTo(&sb, log, opts); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// ConvertTo writes the Markdown of Convert to w one message at a time, so large conversations
+// are not held in memory twice. It returns the first error of w.
+func ConvertTo(w io.Writer, log *types.ConversationLog, opts Options) error {
+	if err := filter.ValidateRoles(opts.Roles); err != nil {
+		return err
+	}
+	formatOptions, err := formatter.ApplyProfile(formatter.FormatOptions{
+		ShowUUID:          opts.ShowUUID,
+		ShowModel:         opts.ShowModel,
+		ShowRequestIDs:    opts.ShowRequestIDs,
+		ShowPlaceholders:  opts.IncludeAll,
+		CollapseThreshold: opts.CollapseThreshold,
+		OmitTimestamps:    opts.OmitTimestamps,
+		MaxToolOutput:     opts.MaxToolOutput,
+		PairTools:         opts.PairTools,
+		ToolSummary:       opts.ToolSummary,
+		TableOfContents:   opts.TableOfContents,
+		MessageAnchors:    opts.MessageAnchors,
+		Wrap:              opts.Wrap,
+		Timezone:          opts.Timezone,
+	}, opts.Profile)
+	if err != nil {
+		return err
+	}
+
+	rules := opts.Rules
+	if rules == nil {
+		rules = filter.Current()
+	}
+	if opts.ShowRequestIDs {
+		rules = rules.Without(filter.APIErrorRule)
+	}
+	selected := rules.FilterConversationLog(log, !opts.IncludeAll)
+	selected = filter.FilterRoles(opts.Tools.Apply(selected), opts.Roles)
+	return formatter.WriteConversationMarkdown(w, selected, formatOptions)
+}
+
+// ListSessions finds the conversation logs under root, newest first.
+// Paths excluded by a .cclogignore file in root are left out, and files that cannot be parsed are skipped.
+func ListSessions(root string) ([]Session, error) {
+	return ListSessionsContext(context.Background(), root)
+}
+
+// ListSessionsContext finds the conversation logs like ListSessions. When ctx is done, the search
+// stops and ctx.Err() is returned.
+func ListSessionsContext(ctx context.Context, root string) ([]Session, error) {
+	paths, _, err := export.FindSessions(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		log, err := parser.ParseJSONLFileContext(ctx, path)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, Session{
+			Path:      path,
+			SessionID: types.SessionID(log),
+			Title:     types.ExtractTitle(filter.Current().FilterConversationLog(log, true)),
+			Project:   types.ProjectName(log),
+			Messages:  len(log.Messages),
+			ModTime:   info.ModTime(),
+		})
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].ModTime.After(sessions[j].ModTime)
+	})
+	return sessions, nil
+}
+
+// String returns a one-line description of the session, e.g. "myproject: Fix the build (12 messages)"
+func (s Session) String() string {
+	if s.Project == "" {
+		return fmt.Sprintf("%s (%d messages)", s.Title, s.Messages)
+	}
+	return fmt.Sprintf("%s: %s (%d messages)", s.Project, s.Title, s.Messages)
+}
@@ -0,0 +1,108 @@
+package cclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+const sessionContent = `{"type":"user","cwd":"/home/me/myproject","message":{"role":"user","content":"hello"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"assistant","cwd":"/home/me/myproject","message":{"role":"assistant","content":[{"type":"text","text":"hi there"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]},"uuid":"u2","timestamp":"2025-07-06T05:01:30.618Z"}
+`
+
+func writeSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+}
+
+func TestParseAndConvert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	writeSession(t, path, sessionContent)
+
+	log, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	markdown, err := Convert(log, Options{Timezone: time.UTC})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	for _, want := range []string{"# Conversation Log", "hello", "hi there", "05:01:29"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, markdown)
+		}
+	}
+
+	markdown, err = Convert(log, Options{IncludeAll: true, Roles: []string{filter.RoleAssistant}, Tools: filter.ToolFilter{Exclude: []string{"bash"}}})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if strings.Contains(markdown, "hello") || strings.Contains(markdown, "Bash") {
+		t.Errorf("Expected only the assistant reply without tool calls, got:\n%s", markdown)
+	}
+	if len(log.Messages) != 2 {
+		t.Errorf("Convert should not modify the input log, got %d messages", len(log.Messages))
+	}
+}
+
+func TestConvertRejectsInvalidOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	writeSession(t, path, sessionContent)
+	log, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if _, err := Convert(log, Options{Profile: "poster"}); err == nil {
+		t.Error("Expected error for an unknown profile")
+	}
+	if _, err := Convert(log, Options{Roles: []string{"system"}}); err == nil {
+		t.Error("Expected error for an unknown role")
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	root := t.TempDir()
+	older := filepath.Join(root, "project-a", "older.jsonl")
+	newer := filepath.Join(root, "project-b", "newer.jsonl")
+	writeSession(t, older, sessionContent)
+	writeSession(t, newer, sessionContent)
+	writeSession(t, filepath.Join(root, "broken.jsonl"), "not json\n")
+	writeSession(t, filepath.Join(root, "notes.txt"), "ignored")
+
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	sessions, err := ListSessions(root)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d: %v", len(sessions), sessions)
+	}
+	if sessions[0].Path != newer || sessions[1].Path != older {
+		t.Errorf("Expected newest first, got %s, %s", sessions[0].Path, sessions[1].Path)
+	}
+	if sessions[0].SessionID != "newer" {
+		t.Errorf("Expected the session ID from the file name, got %q", sessions[0].SessionID)
+	}
+	if got := sessions[0].String(); got != "myproject: hello (2 messages)" {
+		t.Errorf("Unexpected session description %q", got)
+	}
+
+	if _, err := ListSessions(filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected error for a missing root")
+	}
+}
@@ -0,0 +1,77 @@
+package filepicker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/annenpolka/cclog/internal/htmldoc"
+	"github.com/annenpolka/cclog/internal/parser"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Ways of opening a converted session, set with SetOpenWith
+const (
+	OpenWithEditor  = "editor"  // Markdown in the editor (default)
+	OpenWithBrowser = "browser" // Rendered HTML in the default browser
+)
+
+// openWith is how converted sessions are opened
+var openWith = OpenWithEditor
+
+// SetOpenWith sets how converted sessions are opened: OpenWithEditor or OpenWithBrowser ("" means the editor)
+func SetOpenWith(mode string) error {
+	switch mode {
+	case "", OpenWithEditor:
+		openWith = OpenWithEditor
+	case OpenWithBrowser:
+		openWith = OpenWithBrowser
+	default:
+		return fmt.Errorf("unknown openWith %q (available: %s, %s)", mode, OpenWithEditor, OpenWithBrowser)
+	}
+	return nil
+}
+
+// openInBrowserMsg reports whether a session could be opened in the browser
+type openInBrowserMsg struct {
+	path string
+	err  error
+}
+
+// browserCommand is a variable that can be replaced in tests; it returns the command that opens path
+// in the default browser of the operating system
+var browserCommand = openInBrowserCommand
+
+// convertAndOpenInBrowser converts a JSONL file to an HTML page and opens it in the default browser.
+// The page stays in the temp directory, since browsers may load it after the command returns;
+// RemoveStaleTempFiles deletes it on a later run.
+func convertAndOpenInBrowser(jsonlPath string, enableFiltering bool) tea.Cmd {
+	return func() tea.Msg {
+		markdownContent, err := convertJSONLToMarkdown(jsonlPath, enableFiltering)
+		if err != nil {
+			return openInBrowserMsg{err: err}
+		}
+		title := parser.TrimLogExt(filepath.Base(jsonlPath))
+		page, err := htmldoc.Render(markdownContent, title)
+		if err != nil {
+			return openInBrowserMsg{err: err}
+		}
+
+		tempFile, err := createTempFile("cclog_*.html")
+		if err != nil {
+			return openInBrowserMsg{err: err}
+		}
+		if _, err := tempFile.WriteString(page); err != nil {
+			tempFile.Close()
+			os.Remove(tempFile.Name())
+			return openInBrowserMsg{err: err}
+		}
+		tempFile.Close()
+
+		if err := browserCommand(tempFile.Name()).Run(); err != nil {
+			os.Remove(tempFile.Name())
+			return openInBrowserMsg{err: fmt.Errorf("failed to open browser: %w", err)}
+		}
+		return openInBrowserMsg{path: tempFile.Name()}
+	}
+}
@@ -0,0 +1,80 @@
+package filepicker
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSetOpenWith(t *testing.T) {
+	defer SetOpenWith("")
+
+	if err := SetOpenWith(OpenWithBrowser); err != nil || openWith != OpenWithBrowser {
+		t.Fatalf("Expected browser mode, got %q (%v)", openWith, err)
+	}
+	if err := SetOpenWith(""); err != nil || openWith != OpenWithEditor {
+		t.Errorf("Expected empty to mean the editor, got %q (%v)", openWith, err)
+	}
+	if err := SetOpenWith("pager"); err == nil {
+		t.Error("Expected error for an unknown mode")
+	}
+}
+
+func TestConvertAndOpenInBrowser(t *testing.T) {
+	SetTempDir(t.TempDir())
+	defer SetTempDir("")
+
+	var opened string
+	original := browserCommand
+	defer func() { browserCommand = original }()
+	browserCommand = func(path string) *exec.Cmd {
+		opened = path
+		return exec.Command("true")
+	}
+
+	msg := convertAndOpenInBrowser("../../testdata/sample.jsonl", true)().(openInBrowserMsg)
+	if msg.err != nil {
+		t.Fatalf("convertAndOpenInBrowser failed: %v", msg.err)
+	}
+	if opened != msg.path || !strings.HasSuffix(opened, ".html") {
+		t.Errorf("Expected the browser to open %q, opened %q", msg.path, opened)
+	}
+	page, err := os.ReadFile(msg.path)
+	if err != nil {
+		t.Fatalf("Expected the page to be kept for the browser: %v", err)
+	}
+	if !strings.Contains(string(page), "<title>sample</title>") {
+		t.Errorf("Expected an HTML page titled after the session, got:\n%.300s", page)
+	}
+
+	m := NewModel(".", false)
+	updated, _ := m.Update(msg)
+	if got := updated.(Model); got.statusIsError || !strings.Contains(got.statusMessage, "Opened in browser") {
+		t.Errorf("Unexpected status %q", got.statusMessage)
+	}
+}
+
+func TestConvertAndOpenInBrowserFailure(t *testing.T) {
+	dir := t.TempDir()
+	SetTempDir(dir)
+	defer SetTempDir("")
+
+	original := browserCommand
+	defer func() { browserCommand = original }()
+	browserCommand = func(string) *exec.Cmd { return exec.Command("false") }
+
+	msg := convertAndOpenInBrowser("../../testdata/sample.jsonl", true)().(openInBrowserMsg)
+	if msg.err == nil {
+		t.Fatal("Expected an error when the browser cannot be started")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the page to be removed, found %d files", len(entries))
+	}
+
+	m := NewModel(".", false)
+	updated, _ := m.Update(msg)
+	if got := updated.(Model); !got.statusIsError {
+		t.Errorf("Expected an error status, got %q", got.statusMessage)
+	}
+}
@@ -0,0 +1,252 @@
+package filepicker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copySessionIDMsg represents the result of copying sessionId to clipboard
+type copySessionIDMsg struct {
+	success bool
+	method  string
+	error   error
+}
+
+// copyMarkdownMsg represents the result of copying converted markdown to clipboard
+type copyMarkdownMsg struct {
+	success bool
+	method  string
+	error   error
+}
+
+// copyFilePathMsg represents the result of copying the absolute file path to clipboard
+type copyFilePathMsg struct {
+	success bool
+	method  string
+	error   error
+}
+
+// copyResumeCommandMsg represents the result of copying the resume command to clipboard
+type copyResumeCommandMsg struct {
+	success bool
+	method  string
+	error   error
+}
+
+// clipboardCommands lists external clipboard commands tried when the system clipboard fails
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// clipboardLookPath is a variable that can be replaced in tests to mock exec.LookPath
+var clipboardLookPath = exec.LookPath
+
+// systemClipboardWrite is a variable that can be replaced in tests to mock the system clipboard
+var systemClipboardWrite = clipboard.WriteAll
+
+// openOSC52Output opens the terminal used for OSC52 escape sequences
+var openOSC52Output = func() (io.WriteCloser, error) {
+	return os.OpenFile(ttyPath, os.O_WRONLY, 0)
+}
+
+// copySessionID copies the sessionId from the selected file to clipboard
+func copySessionID(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		sessionId, err := extractSessionID(filePath)
+		if err != nil {
+			return copySessionIDMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		method, err := writeToClipboard(sessionId)
+		if err != nil {
+			return copySessionIDMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		return copySessionIDMsg{
+			success: true,
+			method:  method,
+			error:   nil,
+		}
+	}
+}
+
+// copyMarkdown converts the selected JSONL file to markdown and copies it to clipboard
+func copyMarkdown(filePath string, enableFiltering bool) tea.Cmd {
+	return func() tea.Msg {
+		markdownContent, err := convertJSONLToMarkdown(filePath, enableFiltering)
+		if err != nil {
+			return copyMarkdownMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		method, err := writeToClipboard(markdownContent)
+		if err != nil {
+			return copyMarkdownMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		return copyMarkdownMsg{
+			success: true,
+			method:  method,
+			error:   nil,
+		}
+	}
+}
+
+// copyFilePath copies the absolute path of the selected file to clipboard
+func copyFilePath(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return copyFilePathMsg{
+				success: false,
+				error:   fmt.Errorf("failed to resolve absolute path: %w", err),
+			}
+		}
+
+		method, err := writeToClipboard(absPath)
+		if err != nil {
+			return copyFilePathMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		return copyFilePathMsg{
+			success: true,
+			method:  method,
+			error:   nil,
+		}
+	}
+}
+
+// copyResumeCommand copies the claude resume command for the selected file to clipboard
+func copyResumeCommand(filePath string) tea.Cmd {
+	return func() tea.Msg {
+		commandLine, err := generateResumeCommandLine(filePath)
+		if err != nil {
+			return copyResumeCommandMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		method, err := writeToClipboard(commandLine)
+		if err != nil {
+			return copyResumeCommandMsg{
+				success: false,
+				error:   err,
+			}
+		}
+
+		return copyResumeCommandMsg{
+			success: true,
+			method:  method,
+			error:   nil,
+		}
+	}
+}
+
+// writeToClipboard writes text to the clipboard and returns the mechanism that was used.
+// It tries the system clipboard first, then external clipboard commands, then OSC52.
+func writeToClipboard(text string) (string, error) {
+	systemErr := systemClipboardWrite(text)
+	if systemErr == nil {
+		return "system clipboard", nil
+	}
+
+	if name, err := writeWithClipboardCommand(text); err == nil {
+		return name, nil
+	}
+
+	if err := writeOSC52(text); err == nil {
+		return "OSC52", nil
+	}
+
+	// Provide user-friendly error messages for common clipboard issues
+	if strings.Contains(systemErr.Error(), "xclip") || strings.Contains(systemErr.Error(), "xsel") {
+		return "", fmt.Errorf("clipboard functionality requires xclip or xsel on Linux")
+	}
+	if strings.Contains(systemErr.Error(), "not available") {
+		return "", fmt.Errorf("clipboard functionality is not available in this environment")
+	}
+	return "", fmt.Errorf("failed to copy to clipboard: %w", systemErr)
+}
+
+// ClipboardBackends lists the clipboard mechanisms available here, in the order copying tries them
+func ClipboardBackends() []string {
+	var backends []string
+	if !clipboard.Unsupported {
+		backends = append(backends, "system clipboard")
+	}
+	for _, args := range clipboardCommands {
+		if _, err := clipboardLookPath(args[0]); err == nil {
+			backends = append(backends, args[0])
+		}
+	}
+	if out, err := openOSC52Output(); err == nil {
+		out.Close()
+		backends = append(backends, "OSC52")
+	}
+	return backends
+}
+
+// writeWithClipboardCommand pipes text into the first available clipboard command
+func writeWithClipboardCommand(text string) (string, error) {
+	for _, args := range clipboardCommands {
+		if _, err := clipboardLookPath(args[0]); err != nil {
+			continue
+		}
+
+		cmd := execCommand(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			continue
+		}
+		return args[0], nil
+	}
+	return "", fmt.Errorf("no clipboard command available")
+}
+
+// writeOSC52 sends text to the terminal clipboard using the OSC52 escape sequence
+func writeOSC52(text string) error {
+	out, err := openOSC52Output()
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.WriteString(out, osc52Sequence(text, os.Getenv("TMUX") != ""))
+	return err
+}
+
+// osc52Sequence builds the OSC52 escape sequence, wrapped for tmux passthrough if needed
+func osc52Sequence(text string, inTmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if inTmux {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
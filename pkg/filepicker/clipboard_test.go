@@ -1,9 +1,31 @@
 package filepicker
 
 import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
+// nopWriteCloser wraps an io.Writer with a no-op Close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestMain(m *testing.M) {
+	// Never write OSC52 escape sequences to the real terminal during tests
+	openOSC52Output = func() (io.WriteCloser, error) {
+		return nopWriteCloser{io.Discard}, nil
+	}
+	os.Exit(m.Run())
+}
+
 func TestCopySessionID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -125,3 +147,223 @@ func TestCopySessionIDErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyMarkdownErrorHandling(t *testing.T) {
+	// Conversion errors should be reported before the clipboard is touched
+	cmd := copyMarkdown("non-existent.jsonl", true)
+	msg := cmd()
+
+	result, ok := msg.(copyMarkdownMsg)
+	if !ok {
+		t.Fatalf("Expected copyMarkdownMsg, got %T", msg)
+	}
+
+	if result.error == nil {
+		t.Error("Expected error for non-existent file but got none")
+	}
+	if result.success {
+		t.Error("Expected success=false for non-existent file")
+	}
+}
+
+func TestCopyMarkdownKeyHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []FileInfo
+		expectCmd bool
+	}{
+		{
+			name:      "jsonl file returns copy command",
+			files:     []FileInfo{{Name: "sample.jsonl", Path: "../../testdata/sample.jsonl"}},
+			expectCmd: true,
+		},
+		{
+			name:      "directory does not copy",
+			files:     []FileInfo{{Name: "dir", Path: "/tmp/dir", IsDir: true}},
+			expectCmd: false,
+		},
+		{
+			name:      "empty list does not copy",
+			files:     []FileInfo{},
+			expectCmd: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(".", false)
+			m.preview.SetVisible(false)
+			m.files = tt.files
+
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+			if tt.expectCmd && cmd == nil {
+				t.Error("Expected copy command for 'y' key")
+			}
+			if !tt.expectCmd && cmd != nil {
+				if _, ok := cmd().(copyMarkdownMsg); ok {
+					t.Error("Did not expect copy command for 'y' key")
+				}
+			}
+		})
+	}
+}
+
+func TestCopyResumeCommandErrorHandling(t *testing.T) {
+	cmd := copyResumeCommand("session-123.txt")
+	msg := cmd()
+
+	result, ok := msg.(copyResumeCommandMsg)
+	if !ok {
+		t.Fatalf("Expected copyResumeCommandMsg, got %T", msg)
+	}
+	if result.error == nil {
+		t.Error("Expected error for non-JSONL file but got none")
+	}
+}
+
+func TestCopyPathKeyHandlers(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected func(tea.Msg) bool
+	}{
+		{
+			key:      "Y",
+			expected: func(msg tea.Msg) bool { _, ok := msg.(copyFilePathMsg); return ok },
+		},
+		{
+			key:      "C",
+			expected: func(msg tea.Msg) bool { _, ok := msg.(copyResumeCommandMsg); return ok },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			m := NewModel(".", false)
+			m.preview.SetVisible(false)
+			m.files = []FileInfo{{Name: "session-123.jsonl", Path: "/path/to/session-123.jsonl"}}
+
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+			if cmd == nil {
+				t.Fatalf("Expected command for %q key", tt.key)
+			}
+			if msg := cmd(); !tt.expected(msg) {
+				t.Errorf("Unexpected message type %T for %q key", msg, tt.key)
+			}
+		})
+	}
+}
+
+func TestWriteToClipboardFallback(t *testing.T) {
+	origSystem := systemClipboardWrite
+	origLookPath := clipboardLookPath
+	origExec := execCommand
+	origOSC52 := openOSC52Output
+	defer func() {
+		systemClipboardWrite = origSystem
+		clipboardLookPath = origLookPath
+		execCommand = origExec
+		openOSC52Output = origOSC52
+	}()
+
+	failingSystem := func(string) error { return errors.New("No clipboard utilities available. Please install xsel, xclip") }
+	noCommands := func(string) (string, error) { return "", exec.ErrNotFound }
+
+	t.Run("system clipboard is preferred", func(t *testing.T) {
+		systemClipboardWrite = func(string) error { return nil }
+		method, err := writeToClipboard("text")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if method != "system clipboard" {
+			t.Errorf("Expected system clipboard, got %q", method)
+		}
+	})
+
+	t.Run("falls back to clipboard command", func(t *testing.T) {
+		systemClipboardWrite = failingSystem
+		clipboardLookPath = func(file string) (string, error) {
+			if file == "wl-copy" {
+				return "/usr/bin/wl-copy", nil
+			}
+			return "", exec.ErrNotFound
+		}
+		var gotName string
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			gotName = name
+			return exec.Command("true")
+		}
+
+		method, err := writeToClipboard("text")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if method != "wl-copy" || gotName != "wl-copy" {
+			t.Errorf("Expected wl-copy, got method %q exec %q", method, gotName)
+		}
+	})
+
+	t.Run("falls back to OSC52", func(t *testing.T) {
+		systemClipboardWrite = failingSystem
+		clipboardLookPath = noCommands
+		var out strings.Builder
+		openOSC52Output = func() (io.WriteCloser, error) {
+			return nopWriteCloser{&out}, nil
+		}
+
+		method, err := writeToClipboard("hello")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if method != "OSC52" {
+			t.Errorf("Expected OSC52, got %q", method)
+		}
+		if !strings.Contains(out.String(), "aGVsbG8=") {
+			t.Errorf("Expected base64 payload in OSC52 output, got %q", out.String())
+		}
+	})
+
+	t.Run("reports friendly error when all mechanisms fail", func(t *testing.T) {
+		systemClipboardWrite = failingSystem
+		clipboardLookPath = noCommands
+		openOSC52Output = func() (io.WriteCloser, error) {
+			return nil, errors.New("no tty")
+		}
+
+		_, err := writeToClipboard("text")
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+		if !strings.Contains(err.Error(), "xclip or xsel") {
+			t.Errorf("Expected friendly error, got %v", err)
+		}
+	})
+}
+
+func TestOSC52Sequence(t *testing.T) {
+	plain := osc52Sequence("hi", false)
+	if plain != "\x1b]52;c;aGk=\a" {
+		t.Errorf("Unexpected OSC52 sequence: %q", plain)
+	}
+
+	tmux := osc52Sequence("hi", true)
+	if !strings.HasPrefix(tmux, "\x1bPtmux;") || !strings.HasSuffix(tmux, "\x1b\\") {
+		t.Errorf("Expected tmux passthrough wrapping, got %q", tmux)
+	}
+}
+
+func TestClipboardStatusMessage(t *testing.T) {
+	m := NewModel(".", false)
+
+	updated, _ := m.Update(copySessionIDMsg{success: true, method: "OSC52"})
+	model := updated.(Model)
+	if !strings.Contains(model.View(), "Copied sessionId to clipboard (via OSC52)") {
+		t.Error("Expected status message naming the clipboard mechanism")
+	}
+
+	updated, _ = model.Update(copySessionIDMsg{success: false, error: errors.New("boom")})
+	model = updated.(Model)
+	if !model.statusIsError || !strings.Contains(model.statusMessage, "boom") {
+		t.Errorf("Expected error status, got %q", model.statusMessage)
+	}
+}
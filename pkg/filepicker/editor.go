@@ -0,0 +1,81 @@
+package filepicker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// editorCommand is the editor set with SetEditor, split into arguments (nil uses $EDITOR, $VISUAL or a default)
+var editorCommand []string
+
+// SetEditor sets the command that opens files, e.g. "code --wait", overriding $EDITOR and $VISUAL.
+// The command is split into arguments like a shell does, so quoted paths with spaces work. "" restores the default.
+func SetEditor(command string) error {
+	args, err := splitCommand(command)
+	if err != nil {
+		return fmt.Errorf("invalid editor command %q: %w", command, err)
+	}
+	editorCommand = args
+	return nil
+}
+
+// splitCommand splits a command line into arguments like the shell of the platform does, see splitCommandLine.
+// Backslashes are literal on Windows, where they separate the directories of paths.
+func splitCommand(command string) ([]string, error) {
+	return splitCommandLine(command, backslashEscapes)
+}
+
+// splitCommandLine splits a command line into arguments at unquoted whitespace. Single quotes keep
+// everything literally, double quotes keep whitespace, and, if escapes is set, a backslash outside
+// single quotes escapes the next character.
+func splitCommandLine(command string, escapes bool) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\' && escapes:
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,95 @@
+package filepicker
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"vim", []string{"vim"}},
+		{"  code   --wait ", []string{"code", "--wait"}},
+		{`"/Applications/Sublime Text.app/bin/subl" -w`, []string{"/Applications/Sublime Text.app/bin/subl", "-w"}},
+		{`emacsclient -a '' -c`, []string{"emacsclient", "-a", "", "-c"}},
+		{`my\ editor "it's"`, []string{"my editor", "it's"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.command, true)
+		if err != nil {
+			t.Errorf("splitCommand(%q) failed: %v", tt.command, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+
+	for _, command := range []string{`code "unterminated`, `vim \`} {
+		if _, err := splitCommandLine(command, true); err == nil {
+			t.Errorf("Expected error for %q", command)
+		}
+	}
+}
+
+func TestGetEditorCommand(t *testing.T) {
+	t.Setenv("EDITOR", "nvim -R")
+	t.Setenv("VISUAL", "")
+	defer SetEditor("")
+
+	cmd := getEditorCommand("/tmp/session.md")
+	if want := []string{"nvim", "-R", "/tmp/session.md"}; cmd == nil || !slices.Equal(cmd.Args, want) {
+		t.Errorf("Expected $EDITOR with its arguments, got %v", cmd)
+	}
+
+	if err := SetEditor("code --wait"); err != nil {
+		t.Fatalf("SetEditor failed: %v", err)
+	}
+	cmd = getEditorCommand("/tmp/session.md")
+	if want := []string{"code", "--wait", "/tmp/session.md"}; cmd == nil || !slices.Equal(cmd.Args, want) {
+		t.Errorf("Expected the configured editor to override $EDITOR, got %v", cmd)
+	}
+	if cmd := getEditorCommand("/tmp/other.md"); cmd.Args[2] != "/tmp/other.md" || len(cmd.Args) != 3 {
+		t.Errorf("Expected each command to get only its own file, got %v", cmd.Args)
+	}
+
+	if err := SetEditor(`code "`); err == nil {
+		t.Error("Expected error for a malformed editor command")
+	}
+}
+
+func TestEditorName(t *testing.T) {
+	tests := map[string]string{
+		"code":                "code",
+		"/usr/local/bin/subl": "subl",
+		`C:\Program Files\Microsoft VS Code\Code.exe`:                       "code",
+		`C:\Users\me\AppData\Local\Programs\Microsoft VS Code\bin\code.cmd`: "code",
+		"notepad.EXE": "notepad",
+		".exe":        ".exe",
+	}
+	for path, want := range tests {
+		if got := editorName(path); got != want {
+			t.Errorf("editorName(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	if !isBackgroundEditor(`C:\Program Files\Microsoft VS Code\Code.exe`) || isBackgroundEditor("/usr/bin/vim") {
+		t.Error("Expected VS Code to be detected as a background editor, and vim not")
+	}
+	if len(defaultEditors()) == 0 {
+		t.Error("Expected default editors for this platform")
+	}
+}
+
+func TestSplitCommandLineWindowsPaths(t *testing.T) {
+	got, err := splitCommandLine(`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`, false)
+	if want := []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst"}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("Expected backslashes to be kept, got %q (%v)", got, err)
+	}
+	if got, _ := splitCommandLine(`C:\tools\vim.exe`, false); !slices.Equal(got, []string{`C:\tools\vim.exe`}) {
+		t.Errorf("Expected a literal path, got %q", got)
+	}
+}
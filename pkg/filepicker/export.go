@@ -0,0 +1,94 @@
+package filepicker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/outfile"
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/safepath"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// exportSlugMaxRunes caps the title part of export file names
+const exportSlugMaxRunes = 50
+
+// exportDir is where opened sessions are kept as markdown ("" writes temp files deleted after editing)
+var exportDir string
+
+// SetExportDir keeps the markdown of sessions opened in the editor in dir, as <project>/<date>-<slug>-<session>.md,
+// instead of temp files. "" restores temp files.
+func SetExportDir(dir string) {
+	exportDir = dir
+}
+
+// exportFileName returns the path of the export of a session relative to the export directory.
+// The unfiltered markdown (filtering toggled off) gets an "-all" suffix so both versions can be kept,
+// and markdown filtered with customized filter rules a suffix identifying the rules, so an export is
+// only reused for the filtering it was written with.
+func exportFileName(log *types.ConversationLog, enableFiltering bool) string {
+	project := "unknown"
+	if name := types.ProjectName(log); name != "" {
+		project = types.SlugifyTitle(name, exportSlugMaxRunes)
+	}
+
+	date := "undated"
+	for _, msg := range log.Messages {
+		if !msg.Timestamp.IsZero() {
+			date = msg.Timestamp.Local().Format("2006-01-02")
+			break
+		}
+	}
+
+	// The session ID prefix keeps sessions with the same title on the same day apart
+	session := types.SlugifyTitle(parser.TrimLogExt(filepath.Base(log.FilePath)), 8)
+	name := fmt.Sprintf("%s-%s-%s", date, types.SlugifyTitle(types.ExtractTitle(log), exportSlugMaxRunes), session)
+	if !enableFiltering {
+		name += "-all"
+	} else if rules := filter.Current().Names(); !slices.Equal(rules, filter.Default().Names()) {
+		sum := sha256.Sum256([]byte(strings.Join(rules, "\x00")))
+		name += "-rules-" + hex.EncodeToString(sum[:4])
+	}
+	return filepath.Join(project, name+".md")
+}
+
+// exportMarkdown writes the markdown of a JSONL file to the export directory and returns its path.
+// An export of the same filtering that is newer than the session is reused as is.
+func exportMarkdown(jsonlPath string, enableFiltering bool) (string, error) {
+	info, err := os.Stat(jsonlPath)
+	if err != nil {
+		return "", err
+	}
+	log, err := parser.ParseJSONLFile(jsonlPath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := safepath.Join(exportDir, exportFileName(log, enableFiltering))
+	if err != nil {
+		return "", err
+	}
+	if existing, err := os.Stat(path); err == nil && !existing.ModTime().Before(info.ModTime()) {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+	err = outfile.Create(path, func(w io.Writer) error {
+		return writeLogMarkdown(w, log, enableFiltering)
+	})
+	if err != nil {
+		// A partial export would be reused as is next time
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+	return path, nil
+}
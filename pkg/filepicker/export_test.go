@@ -0,0 +1,117 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/filter"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	SetExportDir(dir)
+	defer SetExportDir("")
+
+	session := filepath.Join(t.TempDir(), "41eb70c6-2cac-4420-834b-ceaea98a7494.jsonl")
+	data, err := os.ReadFile("../../testdata/sample.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(session, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := exportMarkdown(session, true)
+	if err != nil {
+		t.Fatalf("exportMarkdown failed: %v", err)
+	}
+	rel, _ := filepath.Rel(dir, path)
+	if !strings.HasPrefix(rel, filepath.Join("cclog", "2025-07-0")) || !strings.HasSuffix(rel, "-41eb70c6.md") {
+		t.Errorf("Unexpected export path %q", rel)
+	}
+
+	// An up-to-date export is reused as is
+	if err := os.WriteFile(path, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := exportMarkdown(session, true); err != nil || again != path {
+		t.Fatalf("Expected the same export, got %q (%v)", again, err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "edited" {
+		t.Errorf("Expected the export to be reused, got %q", content)
+	}
+
+	// A session changed after the export is converted again
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(session, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exportMarkdown(session, true); err != nil {
+		t.Fatalf("exportMarkdown failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), "# Conversation Log") {
+		t.Errorf("Expected the export to be rewritten, got %.100q", content)
+	}
+
+	// The unfiltered markdown is kept separately
+	all, err := exportMarkdown(session, false)
+	if err != nil || all == path || !strings.HasSuffix(all, "-41eb70c6-all.md") {
+		t.Errorf("Expected a separate unfiltered export, got %q (%v)", all, err)
+	}
+}
+
+func TestExportMarkdownMissingSession(t *testing.T) {
+	SetExportDir(t.TempDir())
+	defer SetExportDir("")
+
+	if _, err := exportMarkdown("missing.jsonl", true); err == nil {
+		t.Error("Expected error for a missing session")
+	}
+}
+
+func TestExportMarkdownFilterRules(t *testing.T) {
+	dir := t.TempDir()
+	SetExportDir(dir)
+	defer SetExportDir("")
+
+	session := "../../testdata/sample.jsonl"
+	path, err := exportMarkdown(session, true)
+	if err != nil {
+		t.Fatalf("exportMarkdown failed: %v", err)
+	}
+
+	// An export written with other filter rules is not reused
+	filter.SetCurrent(filter.Default().Without("meta"))
+	defer filter.SetCurrent(filter.Default())
+	custom, err := exportMarkdown(session, true)
+	if err != nil {
+		t.Fatalf("exportMarkdown failed: %v", err)
+	}
+	if custom == path || !strings.Contains(filepath.Base(custom), "-rules-") {
+		t.Errorf("Expected a separate export for the customized rules, got %q and %q", path, custom)
+	}
+}
+
+func TestConvertAndOpenInEditorExportFailure(t *testing.T) {
+	// A file where the export directory should be makes the export fail
+	blocker := filepath.Join(t.TempDir(), "exports")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetExportDir(blocker)
+	defer SetExportDir("")
+
+	msg, ok := convertAndOpenInEditor("../../testdata/sample.jsonl", true)().(exportFailedMsg)
+	if !ok || msg.err == nil {
+		t.Fatalf("Expected an export failure instead of opening the JSONL file, got %#v", msg)
+	}
+
+	m := NewModel(".", false)
+	updated, _ := m.Update(msg)
+	if got := updated.(Model); !got.statusIsError || !strings.Contains(got.statusMessage, "Export failed") {
+		t.Errorf("Expected an error status, got %q", got.statusMessage)
+	}
+}
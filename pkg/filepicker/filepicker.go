@@ -4,11 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 	"time"
 
-	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/ignore"
 	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/filter"
 	"github.com/annenpolka/cclog/pkg/types"
 )
 
@@ -20,6 +20,7 @@ type FileInfo struct {
 	ModTime           time.Time
 	ConversationTitle string
 	ProjectName       string
+	ParseError        string // Set when the session could not be parsed
 }
 
 func (f FileInfo) FilterValue() string {
@@ -32,9 +33,14 @@ func (f FileInfo) Title() string {
 	}
 
 	// For JSONL files, display "date [project] title" format
-	if filepath.Ext(f.Name) == ".jsonl" {
+	if parser.IsLogFile(f.Name) {
 		dateStr := f.ModTime.Format("2006-01-02 15:04")
 
+		// Unparsable sessions have no title, so show the file name instead
+		if f.ParseError != "" {
+			return dateStr + " " + f.Name
+		}
+
 		// Add project name if available
 		var projectPart string
 		if f.ProjectName != "" {
@@ -108,8 +114,17 @@ func GetFiles(dir string) ([]FileInfo, error) {
 		}
 
 		// Extract conversation title and project name for JSONL files
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".jsonl" {
-			title, projectName := extractConversationInfo(fileInfo.Path)
+		if !entry.IsDir() && parser.IsLogFile(entry.Name()) {
+			title, projectName, err := inspectFile(fileInfo.Path, info)
+			if !listedProject(projectName) {
+				continue
+			}
+			// Keep unparsable files visible so corrupted logs can be found
+			if err != nil {
+				fileInfo.ParseError = err.Error()
+				files = append(files, fileInfo)
+				continue
+			}
 			// Skip empty files (when title extraction fails due to empty file)
 			if title == "" {
 				continue
@@ -150,15 +165,22 @@ func GetFiles(dir string) ([]FileInfo, error) {
 
 // extractConversationInfo extracts title and project name from JSONL conversation file
 func extractConversationInfo(filePath string) (string, string) {
+	title, projectName, _ := inspectConversation(filePath)
+	return title, projectName
+}
+
+// inspectConversation extracts title and project name from a JSONL conversation file.
+// An empty title without error means the file has no meaningful messages.
+func inspectConversation(filePath string) (string, string, error) {
 	// Parse the JSONL file to extract conversation information
 	log, err := parser.ParseJSONLFile(filePath)
 	if err != nil {
-		return "", ""
+		return "", "", err
 	}
 
 	// Skip empty files - return empty string to indicate this file should be filtered out
 	if len(log.Messages) == 0 {
-		return "", ""
+		return "", "", nil
 	}
 
 	// Extract project name from CWD field of the first message that has one
@@ -171,27 +193,16 @@ func extractConversationInfo(filePath string) (string, string) {
 	}
 
 	// Apply filtering to check if any meaningful messages remain after filtering
-	filteredLog := &types.ConversationLog{
-		Messages: make([]types.Message, 0),
-		FilePath: log.FilePath,
-	}
-
-	// Manually filter messages using the same logic as formatter.FilterConversationLog
-	for _, msg := range log.Messages {
-		// Apply the same filtering logic as IsContentfulMessage
-		if formatter.IsContentfulMessage(msg) {
-			filteredLog.Messages = append(filteredLog.Messages, msg)
-		}
-	}
+	filteredLog := filter.Current().FilterConversationLog(log, true)
 
 	// Skip files with no meaningful messages after filtering
 	if len(filteredLog.Messages) == 0 {
-		return "", ""
+		return "", "", nil
 	}
 
 	// Extract title using existing title extraction logic
 	title := types.ExtractTitle(filteredLog)
-	return title, projectName
+	return title, projectName, nil
 }
 
 // extractConversationTitle extracts title from JSONL conversation file (backward compatibility)
@@ -200,62 +211,64 @@ func extractConversationTitle(filePath string) string {
 	return title
 }
 
-// extractMessageContent extracts string content from message
-func extractMessageContent(message any) string {
-	// Handle different message content types
-	switch v := message.(type) {
-	case map[string]any:
-		if content, ok := v["content"]; ok {
-			switch contentVal := content.(type) {
-			case string:
-				return contentVal
-			case []any:
-				// Handle array-based content (Claude's complex message format)
-				var result strings.Builder
-				for _, item := range contentVal {
-					if itemMap, ok := item.(map[string]any); ok {
-						if text, ok := itemMap["text"].(string); ok {
-							result.WriteString(text)
-						} else if itemType, ok := itemMap["type"].(string); ok && itemType == "text" {
-							if text, ok := itemMap["text"].(string); ok {
-								result.WriteString(text)
-							}
-						}
-					}
-				}
-				return result.String()
-			}
-		}
-	case string:
-		return v
-	}
-	return ""
+// GetFilesRecursive recursively collects all .jsonl files from a directory and its subdirectories,
+// within the limits set by SetScanLimits
+func GetFilesRecursive(rootDir string) ([]FileInfo, error) {
+	files, _, _, err := scanFilesRecursive(rootDir, nil)
+	return files, err
 }
 
-// GetFilesRecursive recursively collects all .jsonl files from a directory and its subdirectories
-func GetFilesRecursive(rootDir string) ([]FileInfo, error) {
+// scanFilesRecursive collects .jsonl files like GetFilesRecursive and also returns the number of files scanned
+// and whether the scan stopped at the MaxFiles limit.
+// progress, if not nil, is called with the files found so far, newest first, at most every scanProgressInterval.
+// Returning false from progress stops the walk with errScanStopped.
+func scanFilesRecursive(rootDir string, progress func(files []FileInfo, scanned int) bool) ([]FileInfo, int, bool, error) {
 	var allFiles []FileInfo
+	scanned := 0
+	limited := false
+	limits := scanLimits
+	lastProgress := time.Now()
+
+	// Paths excluded by the root's .cclogignore are never listed
+	ignored, err := ignore.Load(rootDir)
+	if err != nil {
+		return nil, 0, false, err
+	}
 
-	err := filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+	err = filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories
+		rel, relErr := filepath.Rel(rootDir, path)
+		if relErr != nil {
+			rel = d.Name()
+		}
+
+		// Skip ignored and too deep directories without reading them
 		if d.IsDir() {
+			if rel != "." && (limits.ignored(rel) || limits.tooDeep(rel) || ignored.Match(rel, true)) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Only include .jsonl files
-		if filepath.Ext(d.Name()) != ".jsonl" {
+		// Only include .jsonl files, compressed or not
+		if !parser.IsLogFile(d.Name()) || limits.ignored(rel) || ignored.Match(rel, false) {
 			return nil
 		}
 
+		if limits.MaxFiles > 0 && scanned >= limits.MaxFiles {
+			limited = true
+			return filepath.SkipAll
+		}
+
 		// Get file info for modification time
 		info, err := d.Info()
 		if err != nil {
 			return err
 		}
+		scanned++
 
 		fileInfo := FileInfo{
 			Name:    d.Name(),
@@ -266,28 +279,44 @@ func GetFilesRecursive(rootDir string) ([]FileInfo, error) {
 		}
 
 		// Extract conversation title and project name for JSONL files
-		title, projectName := extractConversationInfo(path)
-		// Skip empty files (when title extraction fails due to empty file)
-		if title == "" {
-			return nil
+		title, projectName, parseErr := inspectFile(path, info)
+		switch {
+		case !listedProject(projectName):
+			// Sessions of other projects are not listed
+		case parseErr != nil:
+			// Keep unparsable files visible so corrupted logs can be found
+			fileInfo.ParseError = parseErr.Error()
+			allFiles = append(allFiles, fileInfo)
+		case title != "": // Skip empty files (when title extraction fails due to empty file)
+			fileInfo.ConversationTitle = title
+			fileInfo.ProjectName = projectName
+			allFiles = append(allFiles, fileInfo)
 		}
-		fileInfo.ConversationTitle = title
-		fileInfo.ProjectName = projectName
 
-		allFiles = append(allFiles, fileInfo)
+		if progress != nil && time.Since(lastProgress) >= scanProgressInterval {
+			lastProgress = time.Now()
+			if !progress(sortByModTime(allFiles), scanned) {
+				return errScanStopped
+			}
+		}
 		return nil
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, scanned, limited, err
 	}
 
-	// Sort by modification time (newest first)
-	sort.Slice(allFiles, func(i, j int) bool {
-		return allFiles[i].ModTime.After(allFiles[j].ModTime)
-	})
+	return sortByModTime(allFiles), scanned, limited, nil
+}
 
-	return allFiles, nil
+// sortByModTime returns a copy of files sorted by modification time, newest first
+func sortByModTime(files []FileInfo) []FileInfo {
+	sorted := make([]FileInfo, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ModTime.After(sorted[j].ModTime)
+	})
+	return sorted
 }
 
 // extractProjectName extracts project name from cwd path
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/annenpolka/cclog/pkg/filter"
 )
 
 func TestFileInfo_FilterValue(t *testing.T) {
@@ -968,3 +970,33 @@ func TestGetFilesRecursive_SkipsFilteredEmptyFiles(t *testing.T) {
 		t.Errorf("Expected normal.jsonl, got %s", files[0].Name)
 	}
 }
+
+// TestListingAndPreviewShareFilterRules guards against the TUI drifting from the formatter:
+// titles in the listing and the preview both follow the configured rule set.
+func TestListingAndPreviewShareFilterRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"WIP scratch note"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+{"type":"user","message":{"role":"user","content":"Add a parser"},"uuid":"u2","timestamp":"2025-07-06T05:01:30.618Z"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	defer filter.SetCurrent(filter.Current())
+	rules, err := filter.FromConfig(filter.Config{ExcludePatterns: []string{"^WIP"}})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	filter.SetCurrent(rules)
+
+	if title, _ := extractConversationInfo(path); title != "Add a parser" {
+		t.Errorf("Expected the listing title to skip the excluded message, got %q", title)
+	}
+	preview, err := GeneratePreview(path, true)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if strings.Contains(preview, "WIP scratch note") {
+		t.Errorf("Expected the preview to skip the excluded message:\n%s", preview)
+	}
+}
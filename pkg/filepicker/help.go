@@ -0,0 +1,223 @@
+package filepicker
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpOverlayTwoColumnWidth is the terminal width from which the help overlay uses two columns
+const helpOverlayTwoColumnWidth = 100
+
+// actionHelp describes an action in the help overlay
+type actionHelp struct {
+	action Action
+	desc   string
+}
+
+// helpSection groups related actions in the help overlay
+type helpSection struct {
+	title   string
+	actions []actionHelp
+}
+
+// helpSections lists every action shown in the help overlay
+var helpSections = []helpSection{
+	{
+		title: "Files",
+		actions: []actionHelp{
+			{ActionUp, "Move up"},
+			{ActionDown, "Move down"},
+			{ActionOpen, "Enter directory / open in editor"},
+			{ActionBack, "Back to previous directory"},
+			{ActionHome, "Go to Claude projects directory"},
+			{ActionRecent, "Toggle recently viewed sessions"},
+			{ActionSource, "Switch source directory"},
+			{ActionRefresh, "Reload list and preview"},
+			{ActionOtherFiles, "Show/hide non-.jsonl files"},
+			{ActionFilter, "Toggle message filter"},
+			{ActionResume, "Resume session"},
+			{ActionResumeDangerous, "Resume, skipping permissions"},
+		},
+	},
+	{
+		title: "Clipboard",
+		actions: []actionHelp{
+			{ActionCopySessionID, "Copy sessionId"},
+			{ActionCopyMarkdown, "Copy markdown"},
+			{ActionCopyPath, "Copy file path"},
+			{ActionCopyResumeCommand, "Copy resume command"},
+		},
+	},
+	{
+		title: "Preview",
+		actions: []actionHelp{
+			{ActionPreview, "Toggle preview"},
+			{ActionScrollDown, "Scroll down"},
+			{ActionScrollUp, "Scroll up"},
+			{ActionTop, "Go to top"},
+			{ActionBottom, "Go to bottom"},
+			{ActionNextMessage, "Next message"},
+			{ActionPrevMessage, "Previous message"},
+			{ActionGotoMessage, "Go to message number"},
+			{ActionSearch, "Search"},
+			{ActionNextMatch, "Next match"},
+			{ActionPrevMatch, "Previous match"},
+			{ActionClearSearch, "Clear search"},
+			{ActionExpand, "Expand/collapse long messages"},
+			{ActionFullPreview, "Load all messages of a long session"},
+			{ActionRawPreview, "Toggle raw JSONL lines"},
+			{ActionPreviewFilter, "Show/hide tool results, system messages and thinking"},
+			{ActionLayout, "Toggle stacked/side-by-side layout"},
+			{ActionGrowPreview, "Grow preview"},
+			{ActionShrinkPreview, "Shrink preview"},
+		},
+	},
+	{
+		title: "General",
+		actions: []actionHelp{
+			{ActionHelp, "Toggle this help"},
+			{ActionQuit, "Quit"},
+		},
+	},
+}
+
+// handleHelpKey processes a key press while the help overlay is shown.
+// Help, esc and quit close the overlay, the movement and scroll keys scroll it, and other keys are ignored.
+func (m Model) handleHelpKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if key == "esc" {
+		m.showHelp = false
+		m.helpOffset = 0
+		return m, nil
+	}
+
+	switch m.keys.Action(key) {
+	case ActionHelp, ActionQuit:
+		m.showHelp = false
+		m.helpOffset = 0
+	case ActionDown, ActionScrollDown:
+		m.helpOffset = min(m.helpOffset+1, m.maxHelpOffset())
+	case ActionUp, ActionScrollUp:
+		m.helpOffset = max(m.helpOffset-1, 0)
+	case ActionTop:
+		m.helpOffset = 0
+	case ActionBottom:
+		m.helpOffset = m.maxHelpOffset()
+	}
+	return m, nil
+}
+
+// helpHeight returns the number of overlay lines that fit above the footer
+func (m Model) helpHeight() int {
+	return max(m.terminalHeight-1, 1)
+}
+
+// maxHelpOffset returns the largest scroll offset of the help overlay
+func (m Model) maxHelpOffset() int {
+	return max(len(m.helpLines())-m.helpHeight(), 0)
+}
+
+// helpView renders the visible part of the help overlay followed by the footer
+func (m Model) helpView() string {
+	lines := m.helpLines()
+	offset := min(m.helpOffset, m.maxHelpOffset())
+	lines = lines[offset:min(offset+m.helpHeight(), len(lines))]
+
+	footer := fmt.Sprintf("Press %s or esc to close", m.keys.helpKeys(ActionHelp))
+	if m.maxHelpOffset() > 0 {
+		footer += fmt.Sprintf(", %s to scroll", m.keys.helpKeys(ActionUp, ActionDown))
+	}
+	return strings.Join(lines, "\n") + "\n" + helpDescStyle.Render(footer)
+}
+
+// helpLines renders the full help overlay with the current modes and all key bindings
+func (m Model) helpLines() []string {
+	var sections []string
+	sections = append(sections, m.renderModes())
+	for _, section := range helpSections {
+		sections = append(sections, m.renderHelpSection(section))
+	}
+
+	var body string
+	if m.terminalWidth >= helpOverlayTwoColumnWidth {
+		// Modes, files and clipboard on the left; preview and general on the right
+		left := strings.Join(sections[:3], "\n\n")
+		right := strings.Join(sections[3:], "\n\n")
+		columnWidth := m.terminalWidth / 2
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(columnWidth).Render(left),
+			right)
+	} else {
+		body = strings.Join(sections, "\n\n")
+	}
+
+	return strings.Split(headerStyle.Render("cclog help")+"\n\n"+body, "\n")
+}
+
+// renderModes renders the current mode states for the help overlay
+func (m Model) renderModes() string {
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+	layout := "stacked"
+	if m.isSideBySide() {
+		layout = "side by side"
+	}
+	list := m.dir
+	if m.showRecent {
+		list = "recently viewed sessions"
+	}
+	messages := "collapsed"
+	if m.expandMessages {
+		messages = "expanded"
+	}
+	format := "markdown"
+	if m.rawPreview {
+		format = "raw JSONL"
+	}
+
+	rows := [][2]string{
+		{"List", list},
+		{"Recursive", onOff(m.recursive)},
+		{"Other files", onOff(m.showOtherFiles)},
+		{"Filtering", onOff(m.enableFiltering)},
+		{"Preview", onOff(m.preview.IsVisible())},
+		{"Layout", layout},
+		{"Long messages", messages},
+		{"Format", format},
+	}
+
+	var s strings.Builder
+	s.WriteString(modeStyle.Render("Modes"))
+	for _, row := range rows {
+		s.WriteString("\n  " + helpKeyStyle.Render(fmt.Sprintf("%-14s", row[0])) + helpDescStyle.Render(row[1]))
+	}
+	return s.String()
+}
+
+// renderHelpSection renders one group of key bindings, skipping unbound actions
+func (m Model) renderHelpSection(section helpSection) string {
+	var s strings.Builder
+	s.WriteString(modeStyle.Render(section.title))
+	for _, item := range section.actions {
+		keys := m.keys[item.action]
+		if len(keys) == 0 {
+			continue
+		}
+		labels := make([]string, len(keys))
+		for i, key := range keys {
+			labels[i] = keyLabel(key)
+		}
+		s.WriteString("\n  " + helpKeyStyle.Render(fmt.Sprintf("%-14s", strings.Join(labels, ", "))) + helpDescStyle.Render(item.desc))
+	}
+	return s.String()
+}
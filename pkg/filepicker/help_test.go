@@ -0,0 +1,70 @@
+package filepicker
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpOverlay(t *testing.T) {
+	model := NewModel("/logs", true)
+	model.terminalHeight = 100
+	model.files = []FileInfo{{Name: "a.jsonl", Path: "/logs/a.jsonl"}, {Name: "b.jsonl", Path: "/logs/b.jsonl"}}
+	if err := model.SetKeyMap(map[string][]string{"copyPath": {"P"}, "preview": {"ctrl+p"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	m := updated.(Model)
+	if !m.showHelp {
+		t.Fatal("? should open the help overlay")
+	}
+
+	view := m.View()
+	for _, want := range []string{"Modes", "Recursive", "on", "Filtering", "P", "Copy file path", "ctrl+p", "Toggle preview", "Press ? or esc to close"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Help overlay should contain %q:\n%s", want, view)
+		}
+	}
+
+	// Movement keys scroll the overlay instead of the file list
+	m.terminalHeight = 10
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = updated.(Model)
+	if m.cursor != 0 || m.helpOffset != 1 {
+		t.Errorf("j should scroll the help overlay (cursor %d, offset %d)", m.cursor, m.helpOffset)
+	}
+	if !strings.Contains(m.View(), "to scroll") {
+		t.Error("Overlay taller than the terminal should show the scroll hint")
+	}
+
+	// Other keys are ignored while the overlay is open
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(Model)
+	if !m.enableFiltering || !m.showHelp {
+		t.Errorf("Keys should not reach the file list while help is open (filtering %v, showHelp %v)", m.enableFiltering, m.showHelp)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).showHelp {
+		t.Error("esc should close the help overlay")
+	}
+}
+
+func TestHelpOverlayFitsTerminalHeight(t *testing.T) {
+	for _, width := range []int{60, 120} {
+		model := NewModel("/logs", false)
+		model.terminalWidth = width
+		model.terminalHeight = 15
+		model.showHelp = true
+
+		lines := strings.Split(model.View(), "\n")
+		if len(lines) > model.terminalHeight {
+			t.Errorf("Width %d: help overlay has %d lines, terminal height is %d", width, len(lines), model.terminalHeight)
+		}
+		if !strings.Contains(lines[len(lines)-1], "esc to close") {
+			t.Errorf("Width %d: close hint should stay visible, last line %q", width, lines[len(lines)-1])
+		}
+	}
+}
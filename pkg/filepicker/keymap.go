@@ -0,0 +1,179 @@
+package filepicker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action names a TUI command that can be bound to keys
+type Action string
+
+const (
+	ActionQuit              Action = "quit"
+	ActionUp                Action = "up"
+	ActionDown              Action = "down"
+	ActionOpen              Action = "open"
+	ActionPreview           Action = "preview"
+	ActionFilter            Action = "filter"
+	ActionCopySessionID     Action = "copySessionId"
+	ActionCopyMarkdown      Action = "copyMarkdown"
+	ActionCopyPath          Action = "copyPath"
+	ActionCopyResumeCommand Action = "copyResumeCommand"
+	ActionResume            Action = "resume"
+	ActionResumeDangerous   Action = "resumeDangerous"
+	ActionRecent            Action = "recent"
+	ActionSource            Action = "source"
+	ActionRefresh           Action = "refresh"
+	ActionBack              Action = "back"
+	ActionHome              Action = "home"
+	ActionOtherFiles        Action = "otherFiles"
+	ActionScrollDown        Action = "scrollDown"
+	ActionScrollUp          Action = "scrollUp"
+	ActionTop               Action = "top"
+	ActionBottom            Action = "bottom"
+	ActionSearch            Action = "search"
+	ActionNextMatch         Action = "nextMatch"
+	ActionPrevMatch         Action = "prevMatch"
+	ActionClearSearch       Action = "clearSearch"
+	ActionNextMessage       Action = "nextMessage"
+	ActionPrevMessage       Action = "prevMessage"
+	ActionGotoMessage       Action = "gotoMessage"
+	ActionExpand            Action = "expand"
+	ActionFullPreview       Action = "fullPreview"
+	ActionRawPreview        Action = "rawPreview"
+	ActionPreviewFilter     Action = "previewFilter"
+	ActionLayout            Action = "layout"
+	ActionGrowPreview       Action = "growPreview"
+	ActionShrinkPreview     Action = "shrinkPreview"
+	ActionHelp              Action = "help"
+)
+
+// KeyMap binds each action to the keys that trigger it.
+// Keys use bubbletea's key names, e.g. "q", "ctrl+c", "up", "pgdn".
+type KeyMap map[Action][]string
+
+// DefaultKeyMap returns the built-in key bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		ActionQuit:              {"q", "ctrl+c"},
+		ActionUp:                {"up", "k"},
+		ActionDown:              {"down", "j"},
+		ActionOpen:              {"enter"},
+		ActionPreview:           {"p"},
+		ActionFilter:            {"s"},
+		ActionCopySessionID:     {"c"},
+		ActionCopyMarkdown:      {"y"},
+		ActionCopyPath:          {"Y"},
+		ActionCopyResumeCommand: {"C"},
+		ActionResume:            {"r"},
+		ActionResumeDangerous:   {"R"},
+		ActionRecent:            {"h"},
+		ActionSource:            {"o"},
+		ActionRefresh:           {"ctrl+r"},
+		ActionBack:              {"backspace"},
+		ActionHome:              {"~"},
+		ActionOtherFiles:        {"."},
+		ActionScrollDown:        {"d", "pgdn"},
+		ActionScrollUp:          {"u", "pgup"},
+		ActionTop:               {"g"},
+		ActionBottom:            {"G"},
+		ActionSearch:            {"/"},
+		ActionNextMatch:         {"n"},
+		ActionPrevMatch:         {"N"},
+		ActionClearSearch:       {"esc"},
+		ActionNextMessage:       {"]"},
+		ActionPrevMessage:       {"["},
+		ActionGotoMessage:       {":"},
+		ActionExpand:            {"e"},
+		ActionFullPreview:       {"L"},
+		ActionRawPreview:        {"J"},
+		ActionPreviewFilter:     {"F"},
+		ActionLayout:            {"v"},
+		ActionGrowPreview:       {"+", "="},
+		ActionShrinkPreview:     {"-"},
+		ActionHelp:              {"?"},
+	}
+}
+
+// Action returns the action bound to key, or "" if the key is unbound
+func (k KeyMap) Action(key string) Action {
+	for action, keys := range k {
+		for _, bound := range keys {
+			if bound == key {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// helpKeys returns the first key of each action joined for the help bar, or "" if any action is unbound
+func (k KeyMap) helpKeys(actions ...Action) string {
+	labels := make([]string, 0, len(actions))
+	for _, action := range actions {
+		keys := k[action]
+		if len(keys) == 0 {
+			return ""
+		}
+		labels = append(labels, keyLabel(keys[0]))
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyLabel returns the short form of a key name shown in the help bar
+func keyLabel(key string) string {
+	switch key {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	}
+	return key
+}
+
+// withKeyOverrides returns the default bindings with the actions in overrides rebound.
+// An empty key list unbinds the action. Unknown actions and keys bound to several actions are errors.
+func withKeyOverrides(overrides map[string][]string) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	for name, bound := range overrides {
+		action := Action(name)
+		if _, ok := keys[action]; !ok {
+			return nil, fmt.Errorf("unknown key binding action %q", name)
+		}
+		keys[action] = bound
+	}
+
+	// Check actions in a stable order so the reported conflict does not vary between runs
+	actions := make([]string, 0, len(keys))
+	for action := range keys {
+		actions = append(actions, string(action))
+	}
+	sort.Strings(actions)
+
+	owners := make(map[string]Action)
+	for _, name := range actions {
+		action := Action(name)
+		for _, key := range keys[action] {
+			if key == "" {
+				return nil, fmt.Errorf("empty key bound to %q", action)
+			}
+			if owner, ok := owners[key]; ok && owner != action {
+				return nil, fmt.Errorf("key %q is bound to both %q and %q", key, owner, action)
+			}
+			owners[key] = action
+		}
+	}
+	return keys, nil
+}
+
+// SetKeyMap rebinds the TUI keys, starting from the defaults and applying overrides keyed by action name
+func (m *Model) SetKeyMap(overrides map[string][]string) error {
+	keys, err := withKeyOverrides(overrides)
+	if err != nil {
+		return err
+	}
+	m.keys = keys
+	m.preview.keys = keys
+	return nil
+}
@@ -0,0 +1,131 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDefaultKeyMapHasNoConflicts(t *testing.T) {
+	if _, err := withKeyOverrides(nil); err != nil {
+		t.Fatalf("Default key map should be valid: %v", err)
+	}
+}
+
+func TestSetKeyMapRebindsActions(t *testing.T) {
+	model := NewModel("/logs", false)
+	model.files = []FileInfo{{Name: "a.jsonl", Path: "/logs/a.jsonl"}, {Name: "b.jsonl", Path: "/logs/b.jsonl"}}
+
+	err := model.SetKeyMap(map[string][]string{
+		"down":    {"ctrl+n"},
+		"preview": {"P"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if got := updated.(Model).cursor; got != 0 {
+		t.Errorf("Old binding j should no longer move the cursor, cursor = %d", got)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if got := updated.(Model).cursor; got != 1 {
+		t.Errorf("ctrl+n should move the cursor down, cursor = %d", got)
+	}
+
+	help := model.View()
+	if !strings.Contains(help, "P:preview") {
+		t.Errorf("Help bar should show the rebound preview key:\n%s", help)
+	}
+	if strings.Contains(help, "p:preview") {
+		t.Errorf("Help bar should not show the old preview key:\n%s", help)
+	}
+}
+
+func TestSetKeyMapUnbindsAction(t *testing.T) {
+	model := NewModel("/logs", false)
+	if err := model.SetKeyMap(map[string][]string{"scrollDown": {}, "scrollUp": {"pgup"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if action := model.keys.Action("d"); action != "" {
+		t.Errorf("d should be unbound, got %q", action)
+	}
+	if model.preview.keys.Action("pgup") != ActionScrollUp {
+		t.Error("Preview should use the rebound scroll keys")
+	}
+	if strings.Contains(model.View(), ":scroll") {
+		t.Error("Help bar should omit the scroll entry when scrolling down is unbound")
+	}
+}
+
+func TestSetKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   string
+	}{
+		{
+			name:      "unknown action",
+			overrides: map[string][]string{"delete": {"x"}},
+			wantErr:   `unknown key binding action "delete"`,
+		},
+		{
+			name:      "key already bound to another action",
+			overrides: map[string][]string{"quit": {"q", "p"}},
+			wantErr:   `key "p" is bound to both "preview" and "quit"`,
+		},
+		{
+			name:      "empty key",
+			overrides: map[string][]string{"quit": {""}},
+			wantErr:   `empty key bound to "quit"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewModel("/logs", false)
+			err := model.SetKeyMap(tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if model.keys.Action("q") != ActionQuit {
+				t.Error("Invalid overrides should leave the default bindings in place")
+			}
+		})
+	}
+}
+
+func TestRefreshReloadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	session := `{"type":"user","message":{"role":"user","content":"Hello"},"uuid":"1"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte(session), 0644); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	model := NewModel(dir, false)
+	model.files = []FileInfo{{Name: "a.jsonl", Path: filepath.Join(dir, "a.jsonl")}}
+	if err := os.WriteFile(filepath.Join(dir, "b.jsonl"), []byte(session), 0644); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if cmd == nil {
+		t.Fatal("Refresh should return a command")
+	}
+	msg, ok := cmd().(filesRefreshedMsg)
+	if !ok {
+		t.Fatalf("Expected filesRefreshedMsg, got %T", cmd())
+	}
+	found := false
+	for _, f := range msg.files {
+		if f.Name == "b.jsonl" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the new session to be listed after refresh, got %v", msg.files)
+	}
+}
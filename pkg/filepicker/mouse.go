@@ -0,0 +1,109 @@
+package filepicker
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is the maximum time between two clicks on the same file to open it
+const doubleClickInterval = 500 * time.Millisecond
+
+// listTopOffset is the number of lines above the file list (directory header and blank line)
+const listTopOffset = 2
+
+// mouseArea identifies the part of the screen under the mouse pointer
+type mouseArea int
+
+const (
+	mouseAreaNone mouseArea = iota
+	mouseAreaList
+	mouseAreaPreview
+)
+
+// handleMouse scrolls the list or preview with the wheel, selects files on click and opens them on double-click
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	area, index := m.hitTest(msg.X, msg.Y)
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown:
+		up := msg.Button == tea.MouseButtonWheelUp
+		switch area {
+		case mouseAreaPreview:
+			if up {
+				m.preview.markdownBubble.Viewport.ScrollUp(3)
+			} else {
+				m.preview.markdownBubble.Viewport.ScrollDown(3)
+			}
+		case mouseAreaList:
+			if up && m.cursor > 0 {
+				m.cursor--
+			} else if !up && m.cursor < len(m.files)-1 {
+				m.cursor++
+			} else {
+				return m, nil
+			}
+			m.ensureCursorVisible()
+			return m, m.updatePreviewContent()
+		}
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		if area != mouseAreaList || index < 0 {
+			return m, nil
+		}
+
+		now := time.Now()
+		doubleClick := index == m.lastClickIndex && now.Sub(m.lastClickTime) <= doubleClickInterval
+		m.lastClickIndex = index
+		m.lastClickTime = now
+
+		if doubleClick {
+			m.lastClickTime = time.Time{} // A third click starts a new double-click
+			return m, m.openSelected()
+		}
+		if index != m.cursor {
+			m.cursor = index
+			return m, m.updatePreviewContent()
+		}
+	}
+
+	return m, nil
+}
+
+// hitTest returns the screen area at the given cell and, for list rows, the file index (-1 otherwise)
+func (m Model) hitTest(x, y int) (mouseArea, int) {
+	if y < listTopOffset {
+		return mouseAreaNone, -1
+	}
+
+	// Reproduce the scroll window used by View
+	list := m
+	list.fitListToHeight()
+	start := list.scrollOffset
+	end := start + list.maxDisplayFiles
+	if end > len(m.files) {
+		end = len(m.files)
+	}
+
+	sideBySide := m.preview.IsVisible() && m.isSideBySide()
+	if sideBySide {
+		if listWidth, _ := m.sideBySideWidths(); x > listWidth {
+			return mouseAreaPreview, -1
+		}
+	}
+
+	if index := start + y - listTopOffset; index < end {
+		return mouseAreaList, index
+	}
+
+	if m.preview.IsVisible() && !sideBySide {
+		listWidth := m.terminalWidth
+		listLines := strings.Count(m.renderFileList(listWidth), "\n")
+		// The preview starts after the list, a blank line and the separator
+		if y >= listTopOffset+listLines+2 {
+			return mouseAreaPreview, -1
+		}
+	}
+
+	return mouseAreaList, -1
+}
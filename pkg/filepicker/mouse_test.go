@@ -0,0 +1,138 @@
+package filepicker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newMouseTestModel creates a model with dirs so that selecting them does not read files
+func newMouseTestModel(fileCount int) Model {
+	model := NewModel("/tmp", false)
+	model.terminalWidth = 80
+	model.terminalHeight = 40
+	for i := 0; i < fileCount; i++ {
+		name := fmt.Sprintf("dir%d", i)
+		model.files = append(model.files, FileInfo{Name: name, Path: "/tmp/" + name, IsDir: true})
+	}
+	model.updatePreviewSize()
+	return model
+}
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+}
+
+func TestMouseHitTest(t *testing.T) {
+	model := newMouseTestModel(3)
+
+	if area, index := model.hitTest(5, 0); area != mouseAreaNone || index != -1 {
+		t.Errorf("Header should not be a target, got area %v index %d", area, index)
+	}
+	if area, index := model.hitTest(5, listTopOffset+1); area != mouseAreaList || index != 1 {
+		t.Errorf("Expected second list row, got area %v index %d", area, index)
+	}
+
+	// Find the first preview line in the rendered view
+	lines := strings.Split(model.View(), "\n")
+	previewTop := -1
+	for i, line := range lines {
+		if strings.Contains(line, "No preview available") {
+			previewTop = i
+			break
+		}
+	}
+	if previewTop < 0 {
+		t.Fatal("Preview not found in view")
+	}
+	if area, _ := model.hitTest(5, previewTop); area != mouseAreaPreview {
+		t.Errorf("Expected preview at line %d, got area %v", previewTop, area)
+	}
+}
+
+func TestMouseSideBySideHitTest(t *testing.T) {
+	model := newMouseTestModel(3)
+	model.terminalWidth = 160
+	model.layout = previewLayoutSideBySide
+	model.updatePreviewSize()
+
+	listWidth, _ := model.sideBySideWidths()
+	if area, index := model.hitTest(listWidth/2, listTopOffset); area != mouseAreaList || index != 0 {
+		t.Errorf("Expected first list row, got area %v index %d", area, index)
+	}
+	if area, _ := model.hitTest(listWidth+5, listTopOffset); area != mouseAreaPreview {
+		t.Errorf("Expected preview right of the list, got area %v", area)
+	}
+}
+
+func TestMouseClickSelectsFile(t *testing.T) {
+	model := newMouseTestModel(3)
+
+	updated, _ := model.Update(click(5, listTopOffset+2))
+	model = updated.(Model)
+
+	if model.cursor != 2 {
+		t.Errorf("Expected click to select file 2, got cursor %d", model.cursor)
+	}
+
+	// Clicking below the last file does nothing
+	updated, _ = model.Update(click(5, listTopOffset+3))
+	model = updated.(Model)
+	if model.cursor != 2 {
+		t.Errorf("Expected cursor to stay at 2, got %d", model.cursor)
+	}
+}
+
+func TestMouseDoubleClickOpens(t *testing.T) {
+	model := newMouseTestModel(3)
+
+	updated, _ := model.Update(click(5, listTopOffset+1))
+	model = updated.(Model)
+	updated, cmd := model.Update(click(5, listTopOffset+1))
+	model = updated.(Model)
+
+	if cmd == nil {
+		t.Fatal("Expected double-click to open the selected entry")
+	}
+	if model.dir != "/tmp/dir1" {
+		t.Errorf("Expected double-click to enter dir1, got %s", model.dir)
+	}
+}
+
+func TestMouseWheelOverList(t *testing.T) {
+	model := newMouseTestModel(3)
+
+	updated, _ := model.Update(tea.MouseMsg{X: 5, Y: listTopOffset, Button: tea.MouseButtonWheelDown})
+	model = updated.(Model)
+	if model.cursor != 1 {
+		t.Errorf("Expected wheel down to move cursor to 1, got %d", model.cursor)
+	}
+
+	updated, _ = model.Update(tea.MouseMsg{X: 5, Y: listTopOffset, Button: tea.MouseButtonWheelUp})
+	model = updated.(Model)
+	if model.cursor != 0 {
+		t.Errorf("Expected wheel up to move cursor to 0, got %d", model.cursor)
+	}
+}
+
+func TestMouseWheelOverPreview(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	model := newSearchTestModel(strings.Join(lines, "\n"))
+	model.terminalWidth = 160
+	model.layout = previewLayoutSideBySide
+
+	updated, _ := model.Update(tea.MouseMsg{X: 150, Y: listTopOffset, Button: tea.MouseButtonWheelDown})
+	model = updated.(Model)
+
+	if offset := model.preview.markdownBubble.Viewport.YOffset; offset != 3 {
+		t.Errorf("Expected wheel down over the preview to scroll 3 lines, got offset %d", offset)
+	}
+	if model.cursor != 0 {
+		t.Errorf("Wheel over the preview should not move the cursor, got %d", model.cursor)
+	}
+}
@@ -0,0 +1,114 @@
+package filepicker
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxDirHistory caps the number of directories remembered for the back key
+	maxDirHistory = 50
+	// breadcrumbSeparator separates path segments in the header
+	breadcrumbSeparator = " › "
+)
+
+// SetHomeDir sets the directory the home key jumps to and breadcrumbs are shown relative to,
+// normally the default Claude projects directory
+func (m *Model) SetHomeDir(dir string) {
+	m.homeDir = dir
+}
+
+// changeDir lists dir, remembering the current directory for the back key
+func (m *Model) changeDir(dir string) tea.Cmd {
+	if !m.showRecent && cleanSourceDir(dir) != cleanSourceDir(m.dir) {
+		m.dirHistory = append(m.dirHistory, m.dir)
+		if len(m.dirHistory) > maxDirHistory {
+			m.dirHistory = m.dirHistory[len(m.dirHistory)-maxDirHistory:]
+		}
+	}
+
+	m.showRecent = false
+	m.dir = dir
+	m.cursor = 0
+	m.scrollOffset = 0
+	return loadFiles(m.dir, m.recursive)
+}
+
+// goBack returns to the previously visited directory
+func (m *Model) goBack() tea.Cmd {
+	if len(m.dirHistory) == 0 {
+		m.statusMessage = "No previous directory"
+		m.statusIsError = false
+		return nil
+	}
+
+	previous := m.dirHistory[len(m.dirHistory)-1]
+	m.dirHistory = m.dirHistory[:len(m.dirHistory)-1]
+
+	m.showRecent = false
+	m.dir = previous
+	m.cursor = 0
+	m.scrollOffset = 0
+	return loadFiles(m.dir, m.recursive)
+}
+
+// goHome jumps to the home directory
+func (m *Model) goHome() tea.Cmd {
+	if m.homeDir == "" {
+		return nil
+	}
+	return m.changeDir(m.homeDir)
+}
+
+// breadcrumb returns the directory shown in the header, fitted to width columns.
+// Directories inside the home directory are shown as segments starting from the home directory's name.
+func (m Model) breadcrumb(width int) string {
+	if m.homeDir == "" {
+		return fitPath(m.dir, width)
+	}
+
+	rel, err := filepath.Rel(cleanSourceDir(m.homeDir), cleanSourceDir(m.dir))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fitPath(m.dir, width)
+	}
+
+	segments := []string{filepath.Base(m.homeDir)}
+	if rel != "." {
+		segments = append(segments, strings.Split(rel, string(filepath.Separator))...)
+	}
+
+	// Drop leading segments until the breadcrumb fits, keeping the current directory visible
+	crumb := strings.Join(segments, breadcrumbSeparator)
+	for i := 1; width > 0 && lipgloss.Width(crumb) > width && i < len(segments); i++ {
+		crumb = "…" + breadcrumbSeparator + strings.Join(segments[i:], breadcrumbSeparator)
+	}
+	return crumb
+}
+
+// fitPath truncates a path that does not fit in width columns
+func fitPath(path string, width int) string {
+	if width > 0 && len(path) > width {
+		return types.TruncateTitle(path, width)
+	}
+	return path
+}
+
+// visibleFiles drops files other than .jsonl from a directory listing unless they are shown.
+// Directories, including "..", are always kept.
+func (m Model) visibleFiles(files []FileInfo) []FileInfo {
+	if m.showOtherFiles {
+		return files
+	}
+	visible := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		if file.IsDir || parser.IsLogFile(file.Name) {
+			visible = append(visible, file)
+		}
+	}
+	return visible
+}
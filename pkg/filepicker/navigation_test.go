@@ -0,0 +1,139 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDirectoryHistory(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	model := NewModel(root, false)
+	model.SetHomeDir(root)
+	model.files = []FileInfo{{Name: "project", Path: project, IsDir: true}}
+
+	// Enter a directory, then go back with backspace
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := updated.(Model)
+	if m.dir != project {
+		t.Fatalf("Expected to enter %s, got %s", project, m.dir)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(Model)
+	if m.dir != root {
+		t.Errorf("Backspace should return to %s, got %s", root, m.dir)
+	}
+	if cmd == nil {
+		t.Error("Going back should reload the file list")
+	}
+
+	// History is empty again
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(Model)
+	if m.dir != root || m.statusMessage != "No previous directory" {
+		t.Errorf("Back with empty history should stay in %s, got %s (status %q)", root, m.dir, m.statusMessage)
+	}
+
+	// ~ jumps home and remembers where we came from
+	m.dir = project
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("~")})
+	m = updated.(Model)
+	if m.dir != root {
+		t.Errorf("~ should jump to %s, got %s", root, m.dir)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got := updated.(Model).dir; got != project {
+		t.Errorf("Back after ~ should return to %s, got %s", project, got)
+	}
+}
+
+func TestBreadcrumb(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "user", ".claude", "projects")
+
+	tests := []struct {
+		name  string
+		dir   string
+		width int
+		want  string
+	}{
+		{name: "home directory", dir: home, width: 80, want: "projects"},
+		{name: "inside home", dir: filepath.Join(home, "-Users-me-app", "sub"), width: 80, want: "projects › -Users-me-app › sub"},
+		{name: "too narrow drops leading segments", dir: filepath.Join(home, "-Users-me-app", "sub"), width: 20, want: "… › sub"},
+		{name: "outside home shows the path", dir: "/srv/logs", width: 80, want: "/srv/logs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewModel(tt.dir, false)
+			model.SetHomeDir(home)
+			if got := model.breadcrumb(tt.width); got != tt.want {
+				t.Errorf("breadcrumb() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreadcrumbInHeader(t *testing.T) {
+	home := t.TempDir()
+	model := NewModel(filepath.Join(home, "project"), false)
+	model.SetHomeDir(home)
+
+	header := strings.SplitN(model.View(), "\n", 2)[0]
+	if !strings.Contains(header, filepath.Base(home)+" › project") {
+		t.Errorf("Header should show the breadcrumb, got %q", header)
+	}
+}
+
+func TestOtherFilesToggle(t *testing.T) {
+	dir := t.TempDir()
+	session := `{"type":"user","message":{"role":"user","content":"Hello"},"uuid":"1"}` + "\n"
+	for name, content := range map[string]string{"session.jsonl": session, "notes.txt": "notes"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	names := func(m Model) map[string]bool {
+		found := make(map[string]bool)
+		for _, f := range m.files {
+			found[f.Name] = true
+		}
+		return found
+	}
+
+	model := NewModel(dir, false)
+	updated, _ := model.Update(loadFiles(dir, false)())
+	m := updated.(Model)
+	if found := names(m); found["notes.txt"] || !found["session.jsonl"] || !found["sub"] {
+		t.Errorf("Non-.jsonl files should be hidden by default, got %v", found)
+	}
+	if !strings.Contains(m.View(), "[JSONL ONLY]") {
+		t.Error("Header should show that other files are hidden")
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Toggling should reload the listing")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if !names(m)["notes.txt"] {
+		t.Errorf("Other files should be shown after toggling, got %v", names(m))
+	}
+	if !strings.Contains(m.View(), "[ALL FILES]") {
+		t.Error("Header should show that all files are listed")
+	}
+}
@@ -0,0 +1,34 @@
+package filepicker
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// plainReplacer maps the Unicode symbols used by the TUI and the preview border to ASCII
+var plainReplacer = strings.NewReplacer(
+	"📁", "DIR:",
+	"›", ">",
+	"…", "...",
+	"─", "-",
+	"│", "|",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"↑", "^",
+	"↓", "v",
+	"⚠", "!",
+	"█", "_",
+	"•", "*",
+)
+
+// SetPlain enables plain output: no colors or other ANSI styling, and ASCII symbols only.
+// Used for NO_COLOR, screen readers and dumb terminals.
+func (m *Model) SetPlain(plain bool) {
+	m.plain = plain
+}
+
+// plainText removes ANSI escape sequences and replaces Unicode symbols with ASCII
+func plainText(s string) string {
+	return plainReplacer.Replace(ansi.Strip(s))
+}
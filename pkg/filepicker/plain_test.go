@@ -0,0 +1,33 @@
+package filepicker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlainView(t *testing.T) {
+	model := NewModel("/logs", false)
+	model.files = []FileInfo{
+		{Name: "dir", Path: "/logs/dir", IsDir: true},
+		{Name: "corrupt.jsonl", Path: "/logs/corrupt.jsonl", ParseError: "bad line"},
+	}
+	model.statusMessage = "Copied sessionId to clipboard (via OSC52)"
+	model.SetPlain(true)
+
+	view := model.View()
+
+	if strings.Contains(view, "\x1b[") {
+		t.Errorf("Plain view should not contain ANSI escape sequences:\n%q", view)
+	}
+	for _, r := range view {
+		if r > 127 {
+			t.Errorf("Plain view should only contain ASCII, found %q in:\n%s", r, view)
+			break
+		}
+	}
+	for _, want := range []string{"DIR: /logs", "! unparsable", "No preview available", "Copied sessionId"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Plain view should contain %q:\n%s", want, view)
+		}
+	}
+}
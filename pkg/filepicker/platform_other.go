@@ -0,0 +1,27 @@
+//go:build !windows
+
+package filepicker
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// backslashEscapes makes a backslash escape the next character in editor commands, like in POSIX shells
+const backslashEscapes = true
+
+// ttyPath is the terminal OSC52 escape sequences are written to
+const ttyPath = "/dev/tty"
+
+// defaultEditors lists the editors tried, in order, when none is configured
+func defaultEditors() []string {
+	return []string{"nano", "vim", "vi", "emacs"}
+}
+
+// openInBrowserCommand returns the command that opens path in the default browser
+func openInBrowserCommand(path string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", path)
+	}
+	return exec.Command("xdg-open", path)
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package filepicker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// backslashEscapes is off: backslashes in editor commands separate the directories of Windows paths
+const backslashEscapes = false
+
+// ttyPath is the console OSC52 escape sequences are written to
+const ttyPath = "CONOUT$"
+
+// defaultEditors lists the editors tried, in order, when none is configured: VS Code on the PATH or in its
+// per-user install location, then Notepad++ and Notepad, which ships with Windows
+func defaultEditors() []string {
+	editors := []string{"code"}
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		editors = append(editors, filepath.Join(dir, "Programs", "Microsoft VS Code", "bin", "code.cmd"))
+	}
+	return append(editors, "notepad++", "notepad")
+}
+
+// openInBrowserCommand returns the command that opens path in the default browser
+func openInBrowserCommand(path string) *exec.Cmd {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+}
@@ -3,10 +3,11 @@ package filepicker
 import (
 	"github.com/annenpolka/cclog/internal/formatter"
 	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/pkg/types"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/philistino/teacup/markdown"
-	"os"
+	"math"
 	"strings"
 )
 
@@ -16,59 +17,101 @@ type PreviewModel struct {
 	visible        bool
 	width          int
 	height         int
-	tempFile       string  // Store temporary markdown file path
+	generation     int     // Incremented on every SetContent to drop stale renders
+	raw            bool    // Show the content as plain text instead of rendering it as markdown
 	splitRatio     float64 // Split ratio for preview height (0.2 to 0.8)
 	minHeight      int     // Minimum preview height
 	maxHeight      int     // Maximum preview height
+	search         previewSearch
+	navigation     previewNavigation
+	keys           KeyMap // Bindings for the scroll keys
 }
 
+// renderMarkdown is a variable that can be replaced in tests to avoid glamour rendering
+var renderMarkdown = markdown.RenderMarkdown
+
 func NewPreviewModel() *PreviewModel {
-	borderColor := lipgloss.AdaptiveColor{Light: "#CCCCCC", Dark: "#444444"}
-	markdownBubble := markdown.New(true, false, borderColor)
+	markdownBubble := markdown.New(true, false, previewBorderColor)
 	return &PreviewModel{
 		markdownBubble: markdownBubble,
 		content:        "",
 		visible:        true,
 		width:          0,
 		height:         0,
-		tempFile:       "",
 		splitRatio:     0.8, // Default 80% for preview
 		minHeight:      10,  // Minimum 10 lines
 		maxHeight:      0,   // No maximum by default
+		keys:           DefaultKeyMap(),
 	}
 }
 
+// SetContent shows markdown content in the preview and returns the command that renders it.
+// Rendering happens in memory; a render finishing after newer content was set is dropped.
 func (p *PreviewModel) SetContent(content string) tea.Cmd {
 	p.content = content
-
-	// Clean up previous temp file
-	if p.tempFile != "" {
-		os.Remove(p.tempFile)
-		p.tempFile = ""
-	}
+	// Search results and message offsets refer to the previous content
+	p.search = previewSearch{}
+	p.navigation = previewNavigation{}
+	p.generation++
 
 	if content == "" {
 		return nil
 	}
 
-	// Create temporary markdown file
-	tempFile, err := os.CreateTemp("", "cclog_preview_*.md")
-	if err != nil {
-		return nil
+	// Reset scroll position to top when loading new content
+	p.markdownBubble.GotoTop()
+	return p.render()
+}
+
+// previewRenderedMsg carries the rendered markdown of the content set in generation
+type previewRenderedMsg struct {
+	generation int
+	rendered   string
+	err        error
+}
+
+// render renders the current content at the viewport width in the background
+func (p *PreviewModel) render() tea.Cmd {
+	content, width, generation, raw := p.content, p.markdownBubble.Viewport.Width, p.generation, p.raw
+	return func() tea.Msg {
+		rendered, err := renderContent(width, content, raw)
+		return previewRenderedMsg{generation: generation, rendered: rendered, err: err}
 	}
+}
 
-	// Write markdown content to temp file
-	if _, err := tempFile.Write([]byte(content)); err != nil {
-		tempFile.Close()
-		os.Remove(tempFile.Name())
-		return nil
+// renderContent renders markdown for the preview, or returns raw content as it is
+func renderContent(width int, content string, raw bool) (string, error) {
+	if raw {
+		return content, nil
 	}
-	tempFile.Close()
+	return renderMarkdown(width, content)
+}
 
-	p.tempFile = tempFile.Name()
-	// Reset scroll position to top when loading new content
-	p.markdownBubble.GotoTop()
-	return p.markdownBubble.SetFileName(p.tempFile)
+// SetRaw switches between rendering the content as markdown and showing it as plain text.
+// It applies to the content set next.
+func (p *PreviewModel) SetRaw(raw bool) {
+	p.raw = raw
+}
+
+// IsRaw reports whether the content is shown as plain text
+func (p *PreviewModel) IsRaw() bool {
+	return p.raw
+}
+
+// showRendered puts a finished render into the viewport unless newer content was set meanwhile
+func (p *PreviewModel) showRendered(msg previewRenderedMsg) {
+	if msg.generation != p.generation {
+		return
+	}
+	if msg.err != nil {
+		p.markdownBubble.Viewport.SetContent(msg.err.Error())
+		return
+	}
+	viewport := &p.markdownBubble.Viewport
+	viewport.SetContent(lipgloss.NewStyle().
+		Width(viewport.Width).
+		Height(viewport.Height).
+		Render(msg.rendered))
 }
 
 func (p *PreviewModel) GetContent() string {
@@ -87,6 +130,7 @@ func (p *PreviewModel) SetSize(width, height int) {
 	p.width = width
 	p.height = height
 	p.markdownBubble.SetSize(width, height)
+	p.invalidateHeadingOffsets()
 }
 
 func (p *PreviewModel) GetSize() (int, int) {
@@ -110,7 +154,13 @@ func (p *PreviewModel) GetSplitRatio() float64 {
 
 // AdjustSplitRatio adjusts the split ratio by the given delta
 func (p *PreviewModel) AdjustSplitRatio(delta float64) {
-	p.splitRatio += delta
+	p.SetSplitRatio(p.splitRatio + delta)
+}
+
+// SetSplitRatio sets the split ratio, constrained to the 0.2 - 0.8 range
+func (p *PreviewModel) SetSplitRatio(ratio float64) {
+	// Round to avoid drift from repeated float adjustments
+	p.splitRatio = math.Round(ratio*100) / 100
 
 	// Constrain to 0.2 - 0.8 range
 	if p.splitRatio < 0.2 {
@@ -120,31 +170,30 @@ func (p *PreviewModel) AdjustSplitRatio(delta float64) {
 	}
 }
 
-// Cleanup removes temporary files
-func (p *PreviewModel) Cleanup() {
-	if p.tempFile != "" {
-		os.Remove(p.tempFile)
-		p.tempFile = ""
-	}
-}
+// Cleanup releases resources held by the preview. The preview renders in memory and holds
+// none anymore; Cleanup is kept so existing callers keep compiling.
+func (p *PreviewModel) Cleanup() {}
 
 func (p *PreviewModel) Update(msg tea.Msg) (*PreviewModel, tea.Cmd) {
 	var cmd tea.Cmd
 
 	// Handle scroll keys for markdown preview
 	switch msg := msg.(type) {
+	case previewRenderedMsg:
+		p.showRendered(msg)
+		return p, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "d", "pgdn":
+		switch p.keys.Action(msg.String()) {
+		case ActionScrollDown:
 			// Scroll down using viewport
 			p.markdownBubble.Viewport.ScrollDown(3)
-		case "u", "pgup":
+		case ActionScrollUp:
 			// Scroll up using viewport
 			p.markdownBubble.Viewport.ScrollUp(3)
-		case "g":
+		case ActionTop:
 			// Go to top
 			p.markdownBubble.GotoTop()
-		case "G":
+		case ActionBottom:
 			// Go to bottom by setting YOffset to maximum value
 			p.markdownBubble.Viewport.YOffset = p.markdownBubble.Viewport.TotalLineCount() - p.markdownBubble.Viewport.Height
 			if p.markdownBubble.Viewport.YOffset < 0 {
@@ -165,7 +214,7 @@ func (p *PreviewModel) View() string {
 	if p.content == "" {
 		style := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
+			BorderForeground(emptyPreviewBorderColor).
 			Padding(1)
 		return style.Render("No preview available")
 	}
@@ -173,27 +222,57 @@ func (p *PreviewModel) View() string {
 	return p.markdownBubble.View()
 }
 
-func GeneratePreview(jsonlPath string, enableFiltering bool) (string, error) {
+// previewCollapseThreshold is the number of lines after which preview messages are collapsed
+const previewCollapseThreshold = 30
+
+// GeneratePreview converts a JSONL file to markdown for the preview pane.
+// Messages longer than the optional collapseThreshold lines are truncated (0 disables collapsing).
+func GeneratePreview(jsonlPath string, enableFiltering bool, collapseThreshold ...int) (string, error) {
+	threshold := 0
+	if len(collapseThreshold) > 0 {
+		threshold = collapseThreshold[0]
+	}
+	markdown, _, err := previewMarkdown(jsonlPath, enableFiltering, previewFilter{}, threshold, 0)
+	return markdown, err
+}
+
+// previewMarkdown converts a JSONL file to markdown for the preview pane like GeneratePreview,
+// formatting only the first limit messages after filtering (0 formats all).
+// It also returns the number of messages after filtering.
+func previewMarkdown(jsonlPath string, enableFiltering bool, categories previewFilter, collapseThreshold, limit int) (string, int, error) {
 	if jsonlPath == "" {
-		return "", nil
+		return "", 0, nil
 	}
 
 	// Parse JSONL file
 	log, err := parser.ParseJSONLFile(jsonlPath)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	// Apply filtering based on enableFiltering parameter
-	filteredLog := formatter.FilterConversationLog(log, enableFiltering)
+	// Apply filtering based on enableFiltering and the categories chosen in the filter menu
+	filteredLog := &types.ConversationLog{
+		FilePath: log.FilePath,
+		Messages: filterPreviewMessages(log.Messages, enableFiltering, categories),
+	}
+	total := len(filteredLog.Messages)
+	if limit > 0 && total > limit {
+		filteredLog = &types.ConversationLog{
+			FilePath: filteredLog.FilePath,
+			Messages: formatter.SortMessagesChronologically(filteredLog.Messages)[:limit],
+		}
+	}
 
 	// Convert to markdown
 	markdown := formatter.FormatConversationToMarkdown(filteredLog, formatter.FormatOptions{
-		ShowUUID:         false,
-		ShowPlaceholders: !enableFiltering, // Show placeholders when filtering is disabled (--include-all equivalent)
+		ShowUUID:          false,
+		ShowPlaceholders:  !enableFiltering, // Show placeholders when filtering is disabled (--include-all equivalent)
+		CollapseThreshold: collapseThreshold,
+		TruncateCollapsed: true, // Glamour does not render <details>, so collapsed lines are dropped
+		ShowThinking:      categories.showThinking,
 	})
 
-	return markdown, nil
+	return markdown, total, nil
 }
 
 // calculatePreviewHeight calculates preview and list heights based on terminal dimensions
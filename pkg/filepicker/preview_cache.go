@@ -0,0 +1,179 @@
+package filepicker
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// previewCacheSize is the number of generated previews kept for files that are selected again
+	previewCacheSize = 32
+	// previewMessageLimit is the number of messages the preview shows until the full preview is loaded
+	previewMessageLimit = 200
+)
+
+// previewKey identifies a generated preview: the file as it was when the preview was generated,
+// so that a session being written is regenerated, and the options that change the markdown
+type previewKey struct {
+	path      string
+	modTime   time.Time
+	size      int64
+	filtering bool
+	collapse  int
+	full      bool
+	raw       bool
+	filter    previewFilter
+}
+
+// previewCache keeps the most recently used previews so that moving the cursor back and forth
+// does not parse and format the same sessions again
+type previewCache struct {
+	entries map[previewKey]string
+	order   []previewKey // Least recently used first
+}
+
+// newPreviewCache creates an empty cache
+func newPreviewCache() *previewCache {
+	return &previewCache{entries: make(map[previewKey]string)}
+}
+
+// get returns the cached preview for key and marks it as recently used
+func (c *previewCache) get(key previewKey) (string, bool) {
+	content, ok := c.entries[key]
+	if ok {
+		c.touch(key)
+	}
+	return content, ok
+}
+
+// put caches the preview for key, evicting the least recently used one when the cache is full
+func (c *previewCache) put(key previewKey, content string) {
+	if _, ok := c.entries[key]; ok {
+		c.entries[key] = content
+		c.touch(key)
+		return
+	}
+	if len(c.order) >= previewCacheSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = content
+	c.order = append(c.order, key)
+}
+
+// touch moves key to the end of the usage order
+func (c *previewCache) touch(key previewKey) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(append(c.order[:i:i], c.order[i+1:]...), key)
+			return
+		}
+	}
+}
+
+// previewKeyFor returns the key of the preview of a JSONL file with the current options
+func (m *Model) previewKeyFor(path string, collapseThreshold int) (previewKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return previewKey{}, err
+	}
+	return previewKey{
+		path:      path,
+		modTime:   info.ModTime(),
+		size:      info.Size(),
+		filtering: m.enableFiltering,
+		collapse:  collapseThreshold,
+		full:      m.fullPreviewPath == path,
+		raw:       m.rawPreview,
+		filter:    m.previewFilter,
+	}, nil
+}
+
+// generatePreview generates the preview for key. Unless it is a full preview, only the first
+// previewMessageLimit messages (or lines of a raw preview) are shown, followed by a note naming
+// fullKeys, the keys that load the rest.
+func generatePreview(key previewKey, fullKeys string) (string, error) {
+	limit := previewMessageLimit
+	if key.full {
+		limit = 0
+	}
+	if key.raw {
+		content, total, err := rawPreview(key.path, limit)
+		if err != nil {
+			return "", err
+		}
+		if limit > 0 && total > limit {
+			content += "\n// " + truncationNote(limit, total, "lines", fullKeys) + "\n"
+		}
+		return content, nil
+	}
+
+	content, total, err := previewMarkdown(key.path, key.filtering, key.filter, key.collapse, limit)
+	if err != nil {
+		return "", err
+	}
+	if limit > 0 && total > limit {
+		content += "---\n\n*" + truncationNote(limit, total, "messages", fullKeys) + "*\n"
+	}
+	return content, nil
+}
+
+// truncationNote tells that a preview shows only the first limit of total items
+func truncationNote(limit, total int, items, fullKeys string) string {
+	note := fmt.Sprintf("The preview shows the first %d of %d %s.", limit, total, items)
+	if fullKeys != "" {
+		note += fmt.Sprintf(" Press %s to load the rest.", fullKeys)
+	}
+	return note
+}
+
+// previewLoadedMsg carries a preview generated in the background
+type previewLoadedMsg struct {
+	key        previewKey
+	generation int // Value of Model.previewGeneration when the preview was requested
+	content    string
+	err        error
+}
+
+// loadPreview generates the preview for key in the background
+func loadPreview(key previewKey, generation int, fullKeys string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := generatePreview(key, fullKeys)
+		return previewLoadedMsg{key: key, generation: generation, content: content, err: err}
+	}
+}
+
+// showLoadedPreview caches a preview generated in the background and shows it, unless another
+// preview was requested since, e.g. because the cursor moved on
+func (m *Model) showLoadedPreview(msg previewLoadedMsg) tea.Cmd {
+	if msg.err == nil {
+		m.cache().put(msg.key, msg.content)
+	}
+	if msg.generation != m.previewGeneration {
+		return nil
+	}
+	m.previewPath = msg.key.path
+	if msg.err != nil {
+		return m.preview.SetContent("Error generating preview: " + msg.err.Error())
+	}
+	return m.preview.SetContent(msg.content)
+}
+
+// cache returns the preview cache, creating it for models not made by NewModel
+func (m *Model) cache() *previewCache {
+	if m.previewCache == nil {
+		m.previewCache = newPreviewCache()
+	}
+	return m.previewCache
+}
+
+// loadingText is shown in the preview while a session is converted in the background
+func (m Model) loadingText() string {
+	if m.plain {
+		return "*Loading preview...*"
+	}
+	return "*Loading preview…*"
+}
@@ -0,0 +1,155 @@
+package filepicker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeLongSession writes a session of n user messages and returns its path
+func writeLongSession(t *testing.T, dir string, n int, text string) string {
+	t.Helper()
+	var lines strings.Builder
+	for i := 0; i < n; i++ {
+		lines.WriteString(fmt.Sprintf(`{"type":"user","message":{"role":"user","content":"%s %d"},"uuid":"u%d","timestamp":"2025-07-06T05:%02d:%02d.000Z"}`+"\n", text, i, i, i/60%60, i%60))
+	}
+	path := filepath.Join(dir, "long.jsonl")
+	if err := os.WriteFile(path, []byte(lines.String()), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	return path
+}
+
+func TestPreviewCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newPreviewCache()
+	for i := 0; i < previewCacheSize; i++ {
+		c.put(previewKey{path: fmt.Sprint(i)}, "content")
+	}
+	c.get(previewKey{path: "0"})
+	c.put(previewKey{path: "new"}, "content")
+
+	if _, ok := c.get(previewKey{path: "0"}); !ok {
+		t.Error("Expected the recently used preview to be kept")
+	}
+	if _, ok := c.get(previewKey{path: "1"}); ok {
+		t.Error("Expected the least recently used preview to be evicted")
+	}
+	if len(c.entries) != previewCacheSize || len(c.order) != previewCacheSize {
+		t.Errorf("Expected %d cached previews, got %d entries and %d in order", previewCacheSize, len(c.entries), len(c.order))
+	}
+}
+
+// previewLoaded runs cmd and returns the background preview it generates, if any
+func previewLoaded(cmd tea.Cmd) (previewLoadedMsg, bool) {
+	if cmd == nil {
+		return previewLoadedMsg{}, false
+	}
+	switch msg := cmd().(type) {
+	case previewLoadedMsg:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if loaded, ok := previewLoaded(c); ok {
+				return loaded, true
+			}
+		}
+	}
+	return previewLoadedMsg{}, false
+}
+
+// loadSelectedPreview requests the preview of the selected file and shows it once it is generated
+func loadSelectedPreview(t *testing.T, m *Model) {
+	t.Helper()
+	if msg, ok := previewLoaded(m.updatePreviewContent()); ok {
+		m.showLoadedPreview(msg)
+	}
+}
+
+func TestPreviewLoadsInBackground(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLongSession(t, dir, 3, "first")
+	m := NewModel(dir, false)
+	m.files = []FileInfo{{Name: "long.jsonl", Path: path}, {Name: "sub", Path: filepath.Join(dir, "sub"), IsDir: true}}
+
+	msg, ok := previewLoaded(m.updatePreviewContent())
+	if !ok || m.preview.GetContent() != m.loadingText() {
+		t.Fatalf("Expected a placeholder while the preview is generated, got %q", m.preview.GetContent())
+	}
+
+	// The cursor moves on before the preview is ready: the result is cached but not shown
+	m.cursor = 1
+	m.updatePreviewContent()
+	m.showLoadedPreview(msg)
+	if m.preview.GetContent() != "" {
+		t.Errorf("Expected a stale preview to be dropped, got:\n%s", m.preview.GetContent())
+	}
+
+	m.cursor = 0
+	if _, ok := previewLoaded(m.updatePreviewContent()); ok || !strings.Contains(m.preview.GetContent(), "first 2") {
+		t.Errorf("Expected the cached preview at once, got:\n%s", m.preview.GetContent())
+	}
+}
+
+func TestPreviewCacheReusedUntilFileChanges(t *testing.T) {
+	path := writeLongSession(t, t.TempDir(), 3, "first")
+	info, _ := os.Stat(path)
+	m := NewModel(filepath.Dir(path), false)
+	m.files = []FileInfo{{Name: "long.jsonl", Path: path}}
+
+	loadSelectedPreview(t, &m)
+	if !strings.Contains(m.preview.GetContent(), "first 2") {
+		t.Fatalf("Expected the preview of the session, got:\n%s", m.preview.GetContent())
+	}
+
+	// Same size and modification time: the cached preview is used
+	writeLongSession(t, filepath.Dir(path), 3, "other")
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	loadSelectedPreview(t, &m)
+	if !strings.Contains(m.preview.GetContent(), "first 2") {
+		t.Errorf("Expected the cached preview, got:\n%s", m.preview.GetContent())
+	}
+
+	// A newer file is previewed again, keeping the old preview instead of a placeholder meanwhile
+	os.Chtimes(path, info.ModTime().Add(time.Second), info.ModTime().Add(time.Second))
+	msg, ok := previewLoaded(m.updatePreviewContent())
+	if !ok || !strings.Contains(m.preview.GetContent(), "first 2") {
+		t.Fatalf("Expected the old preview while the new one is generated, got:\n%s", m.preview.GetContent())
+	}
+	m.showLoadedPreview(msg)
+	if !strings.Contains(m.preview.GetContent(), "other 2") {
+		t.Errorf("Expected a new preview of the changed file, got:\n%s", m.preview.GetContent())
+	}
+}
+
+func TestPreviewMessageLimit(t *testing.T) {
+	path := writeLongSession(t, t.TempDir(), previewMessageLimit+50, "message")
+	m := NewModel(filepath.Dir(path), false)
+	m.files = []FileInfo{{Name: "long.jsonl", Path: path}}
+
+	loadSelectedPreview(t, &m)
+	content := m.preview.GetContent()
+	if strings.Contains(content, fmt.Sprintf("message %d\n", previewMessageLimit)) {
+		t.Error("Expected messages after the limit to be left out of the preview")
+	}
+	want := fmt.Sprintf("The preview shows the first %d of %d messages. Press L to load the rest.", previewMessageLimit, previewMessageLimit+50)
+	if !strings.Contains(content, want) {
+		t.Errorf("Expected a note about the limit, got the end:\n%s", content[len(content)-200:])
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	m = updated.(Model)
+	msg, ok := previewLoaded(cmd)
+	if !ok {
+		t.Fatal("Expected L to generate the full preview")
+	}
+	updated, _ = m.Update(msg)
+	content = updated.(Model).preview.GetContent()
+	if !strings.Contains(content, fmt.Sprintf("message %d\n", previewMessageLimit+49)) || strings.Contains(content, "The preview shows") {
+		t.Error("Expected L to load all messages")
+	}
+}
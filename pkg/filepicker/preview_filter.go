@@ -0,0 +1,83 @@
+package filepicker
+
+import (
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/pkg/filter"
+	"github.com/annenpolka/cclog/pkg/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewFilter selects message categories for the preview on top of the global filter toggle.
+// The zero value shows what the global filter lets through, without thinking.
+type previewFilter struct {
+	hideToolResults bool // Leave out user messages that only carry tool results
+	hideSystem      bool // Leave out system and meta messages
+	showThinking    bool // Show the assistant's thinking, including messages that hold nothing else
+}
+
+// previewFilterItem is a category of the preview filter menu, toggled by its key
+type previewFilterItem struct {
+	key   string
+	label string
+	shown func(f previewFilter) bool
+	flip  func(f *previewFilter)
+}
+
+// previewFilterItems lists the categories of the filter menu in order
+var previewFilterItems = []previewFilterItem{
+	{"1", "tool results", func(f previewFilter) bool { return !f.hideToolResults }, func(f *previewFilter) { f.hideToolResults = !f.hideToolResults }},
+	{"2", "system", func(f previewFilter) bool { return !f.hideSystem }, func(f *previewFilter) { f.hideSystem = !f.hideSystem }},
+	{"3", "thinking", func(f previewFilter) bool { return f.showThinking }, func(f *previewFilter) { f.showThinking = !f.showThinking }},
+}
+
+// filterPreviewMessages applies the global filter and the categories of f to messages.
+// Messages with thinking are kept when thinking is shown, even if the global filter finds no text in them.
+func filterPreviewMessages(messages []types.Message, enableFiltering bool, f previewFilter) []types.Message {
+	var kept []types.Message
+	for _, msg := range messages {
+		switch {
+		case f.hideToolResults && filter.MessageRole(msg) == filter.RoleTool:
+		case f.hideSystem && (msg.Type == "system" || msg.IsMeta):
+		case !enableFiltering || formatter.IsContentfulMessage(msg),
+			f.showThinking && types.ExtractThinking(msg.Message) != "":
+			kept = append(kept, msg)
+		}
+	}
+	return kept
+}
+
+// handleFilterMenuKey processes a key press while the preview filter menu is open.
+// The category keys toggle a category and regenerate the preview; esc and the menu key close it.
+func (m Model) handleFilterMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch {
+	case key == "ctrl+c":
+		return m, tea.Quit
+	case key == "esc" || m.keys.Action(key) == ActionPreviewFilter:
+		m.filterMenu = false
+		return m, nil
+	}
+	for _, item := range previewFilterItems {
+		if item.key == key {
+			item.flip(&m.previewFilter)
+			return m, m.updatePreviewContent()
+		}
+	}
+	return m, nil
+}
+
+// filterMenuStatus describes the categories and their keys in the status line below the preview
+func (m Model) filterMenuStatus() string {
+	parts := []string{"Show:"}
+	for _, item := range previewFilterItems {
+		mark := "[ ]"
+		if item.shown(m.previewFilter) {
+			mark = "[x]"
+		}
+		parts = append(parts, item.key+" "+mark+" "+item.label)
+	}
+	parts = append(parts, "(esc close)")
+	return searchPromptStyle.Render(strings.Join(parts, "  "))
+}
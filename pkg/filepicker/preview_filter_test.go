@@ -0,0 +1,85 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFilterPreviewMessages(t *testing.T) {
+	user := types.Message{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("Fix it")}}
+	toolResult := types.Message{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(types.ToolResultBlock{ToolUseID: "t1", Content: types.TextContent("ok")})}}
+	system := types.Message{Type: "system", Message: &types.MessageBody{Content: types.TextContent("Compacted")}}
+	thinking := types.Message{Type: "assistant", Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ThinkingBlock{Thinking: "Plan"})}}
+	messages := []types.Message{user, toolResult, system, thinking}
+
+	tests := []struct {
+		name      string
+		filtering bool
+		filter    previewFilter
+		want      int
+	}{
+		{"global filter only", true, previewFilter{}, 1},
+		{"unfiltered", false, previewFilter{}, 4},
+		{"unfiltered without tool results and system", false, previewFilter{hideToolResults: true, hideSystem: true}, 2},
+		{"filtered with thinking", true, previewFilter{showThinking: true}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterPreviewMessages(messages, tt.filtering, tt.filter); len(got) != tt.want {
+				t.Errorf("Expected %d messages, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
+
+func TestPreviewFilterMenu(t *testing.T) {
+	stubRenderMarkdown(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	session := `{"type":"user","message":{"role":"user","content":"Fix the build"},"uuid":"u1","timestamp":"2025-07-06T05:00:00.000Z"}` + "\n" +
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"thinking","thinking":"Run make first"}]},"uuid":"a1","timestamp":"2025-07-06T05:00:01.000Z"}` + "\n"
+	if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	m := NewModel(dir, false)
+	m.files = []FileInfo{{Name: "session.jsonl", Path: path}}
+	loadSelectedPreview(t, &m)
+	if strings.Contains(m.preview.GetContent(), "Run make first") {
+		t.Fatal("Expected thinking to be hidden by default")
+	}
+
+	key := func(s string) tea.Cmd {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		m = updated.(Model)
+		return cmd
+	}
+	key("F")
+	if !m.filterMenu || !strings.Contains(m.View(), "3 [ ] thinking") {
+		t.Fatalf("Expected F to open the filter menu, got:\n%s", m.View())
+	}
+
+	msg, ok := previewLoaded(key("3"))
+	if !ok {
+		t.Fatal("Expected toggling a category to regenerate the preview")
+	}
+	m.showLoadedPreview(msg)
+	if !strings.Contains(m.preview.GetContent(), "> Run make first") || !strings.Contains(m.View(), "3 [x] thinking") {
+		t.Errorf("Expected the thinking in the preview, got:\n%s", m.preview.GetContent())
+	}
+
+	// Keys other than the categories do nothing while the menu is open
+	key("j")
+	if !m.filterMenu {
+		t.Error("Expected the menu to stay open")
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.filterMenu || strings.Contains(m.View(), "esc close") {
+		t.Error("Expected esc to close the menu")
+	}
+}
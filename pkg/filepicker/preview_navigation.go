@@ -0,0 +1,147 @@
+package filepicker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// messageHeadingPattern matches the per-message headings written by the formatter (e.g. "### User")
+var messageHeadingPattern = regexp.MustCompile(`^#{3} \w+$`)
+
+// previewNavigation holds message boundary offsets and the goto prompt state
+type previewNavigation struct {
+	headingOffsets []int  // Rendered line offsets of message headings
+	offsetsValid   bool   // Whether headingOffsets matches the current content and width
+	gotoActive     bool   // Whether the user is typing a message number
+	gotoInput      string // Message number typed so far
+}
+
+// invalidateHeadingOffsets marks the message offsets as stale after content or size changes
+func (p *PreviewModel) invalidateHeadingOffsets() {
+	p.navigation.headingOffsets = nil
+	p.navigation.offsetsValid = false
+}
+
+// HeadingOffsets returns the rendered line offsets of each message heading.
+// Offsets are computed on first use after the content is set and cached until it changes.
+func (p *PreviewModel) HeadingOffsets() []int {
+	if p.navigation.offsetsValid {
+		return p.navigation.headingOffsets
+	}
+
+	p.navigation.headingOffsets = nil
+	if p.content != "" {
+		if lines, err := p.renderedLines(); err == nil {
+			p.navigation.headingOffsets = findHeadingOffsets(lines)
+		}
+	}
+	p.navigation.offsetsValid = true
+	return p.navigation.headingOffsets
+}
+
+// NextMessage scrolls the preview to the next message heading
+func (p *PreviewModel) NextMessage() {
+	current := p.markdownBubble.Viewport.YOffset
+	for _, offset := range p.HeadingOffsets() {
+		if offset > current {
+			p.markdownBubble.Viewport.SetYOffset(offset)
+			return
+		}
+	}
+}
+
+// PrevMessage scrolls the preview to the previous message heading
+func (p *PreviewModel) PrevMessage() {
+	current := p.markdownBubble.Viewport.YOffset
+	offsets := p.HeadingOffsets()
+	for i := len(offsets) - 1; i >= 0; i-- {
+		if offsets[i] < current {
+			p.markdownBubble.Viewport.SetYOffset(offsets[i])
+			return
+		}
+	}
+	p.markdownBubble.GotoTop()
+}
+
+// GotoMessage scrolls the preview to the n-th message (1-based)
+func (p *PreviewModel) GotoMessage(n int) bool {
+	offsets := p.HeadingOffsets()
+	if n < 1 || n > len(offsets) {
+		return false
+	}
+	p.markdownBubble.Viewport.SetYOffset(offsets[n-1])
+	return true
+}
+
+// StartGoto enters the ":N" goto prompt
+func (p *PreviewModel) StartGoto() {
+	p.navigation.gotoActive = true
+	p.navigation.gotoInput = ""
+}
+
+// IsGotoInputActive reports whether the preview is capturing keys for the goto prompt
+func (p *PreviewModel) IsGotoInputActive() bool {
+	return p.navigation.gotoActive
+}
+
+// handleGotoKey processes a key press while in goto input mode
+func (p *PreviewModel) handleGotoKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		p.navigation.gotoActive = false
+		p.navigation.gotoInput = ""
+	case tea.KeyEnter:
+		p.navigation.gotoActive = false
+		if n, err := strconv.Atoi(p.navigation.gotoInput); err == nil {
+			p.GotoMessage(n)
+		}
+		p.navigation.gotoInput = ""
+	case tea.KeyBackspace:
+		if len(p.navigation.gotoInput) > 0 {
+			p.navigation.gotoInput = p.navigation.gotoInput[:len(p.navigation.gotoInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r >= '0' && r <= '9' {
+				p.navigation.gotoInput += string(r)
+			}
+		}
+	}
+}
+
+// IsCapturingInput reports whether the preview is consuming key presses for a prompt
+func (p *PreviewModel) IsCapturingInput() bool {
+	return p.IsSearchInputActive() || p.IsGotoInputActive()
+}
+
+// handleInputKey routes a key press to the active prompt
+func (p *PreviewModel) handleInputKey(msg tea.KeyMsg) {
+	if p.IsGotoInputActive() {
+		p.handleGotoKey(msg)
+		return
+	}
+	p.handleSearchKey(msg)
+}
+
+// StatusLine returns the prompt or search status shown below the preview
+func (p *PreviewModel) StatusLine() string {
+	if p.navigation.gotoActive {
+		return searchPromptStyle.Render(":" + p.navigation.gotoInput + "█")
+	}
+	return p.SearchStatus()
+}
+
+// findHeadingOffsets returns indices of lines that are message headings
+func findHeadingOffsets(lines []string) []int {
+	var offsets []int
+	for i, line := range lines {
+		if messageHeadingPattern.MatchString(strings.TrimSpace(ansi.Strip(line))) {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
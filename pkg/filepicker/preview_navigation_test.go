@@ -0,0 +1,108 @@
+package filepicker
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// navigationTestContent has message headings at lines 0, 4 and 8
+var navigationTestContent = strings.Join([]string{
+	"### User", "", "question", "",
+	"### Assistant", "", "answer", "",
+	"### User", "", "follow up", "",
+	"#### Not a message heading", "", "tail",
+}, "\n")
+
+func TestFindHeadingOffsets(t *testing.T) {
+	lines := strings.Split(navigationTestContent, "\n")
+	lines[4] = "  \x1b[1m### Assistant\x1b[0m  "
+
+	offsets := findHeadingOffsets(lines)
+	expected := []int{0, 4, 8}
+	if len(offsets) != len(expected) {
+		t.Fatalf("Expected offsets %v, got %v", expected, offsets)
+	}
+	for i := range expected {
+		if offsets[i] != expected[i] {
+			t.Errorf("Expected offsets %v, got %v", expected, offsets)
+			break
+		}
+	}
+}
+
+func TestPreviewMessageNavigationKeys(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	m := newSearchTestModel(navigationTestContent)
+	press := func(key string) {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(Model)
+	}
+
+	press("]")
+	if got := m.preview.markdownBubble.Viewport.YOffset; got != 4 {
+		t.Errorf("Expected ] to jump to offset 4, got %d", got)
+	}
+	press("]")
+	if got := m.preview.markdownBubble.Viewport.YOffset; got != 8 {
+		t.Errorf("Expected ] to jump to offset 8, got %d", got)
+	}
+	press("[")
+	if got := m.preview.markdownBubble.Viewport.YOffset; got != 4 {
+		t.Errorf("Expected [ to jump back to offset 4, got %d", got)
+	}
+	press("[")
+	press("[")
+	if got := m.preview.markdownBubble.Viewport.YOffset; got != 0 {
+		t.Errorf("Expected [ to stop at the top, got %d", got)
+	}
+}
+
+func TestPreviewGotoMessage(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	m := newSearchTestModel(navigationTestContent)
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune(":")},
+		{Type: tea.KeyRunes, Runes: []rune("3")},
+	} {
+		updated, _ := m.Update(key)
+		m = updated.(Model)
+	}
+
+	if !strings.Contains(m.preview.StatusLine(), ":3") {
+		t.Errorf("Expected goto prompt, got %q", m.preview.StatusLine())
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	if m.preview.IsGotoInputActive() {
+		t.Error("Expected goto prompt to close after enter")
+	}
+	if got := m.preview.markdownBubble.Viewport.YOffset; got != 8 {
+		t.Errorf("Expected :3 to jump to offset 8, got %d", got)
+	}
+
+	if m.preview.GotoMessage(10) {
+		t.Error("Expected GotoMessage to reject out-of-range message numbers")
+	}
+}
+
+func TestPreviewHeadingOffsetsInvalidatedOnContentChange(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	p := NewPreviewModel()
+	p.SetSize(80, 5)
+	p.content = navigationTestContent
+	if len(p.HeadingOffsets()) != 3 {
+		t.Fatalf("Expected 3 headings, got %v", p.HeadingOffsets())
+	}
+
+	p.SetContent("")
+	if len(p.HeadingOffsets()) != 0 {
+		t.Errorf("Expected offsets to be recomputed for new content, got %v", p.HeadingOffsets())
+	}
+}
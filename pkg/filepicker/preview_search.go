@@ -0,0 +1,205 @@
+package filepicker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// previewSearch holds the state of a search inside the preview pane
+type previewSearch struct {
+	inputActive bool     // Whether the user is typing a query
+	query       string   // Current search query
+	lines       []string // Rendered preview lines the search runs against
+	matches     []int    // Line indices containing the query
+	current     int      // Index into matches of the current match
+}
+
+// StartSearch enters search input mode
+func (p *PreviewModel) StartSearch() {
+	p.search.inputActive = true
+	p.search.query = ""
+}
+
+// IsSearchInputActive reports whether the preview is capturing keys for a search query
+func (p *PreviewModel) IsSearchInputActive() bool {
+	return p.search.inputActive
+}
+
+// HasSearchQuery reports whether a search query is currently applied
+func (p *PreviewModel) HasSearchQuery() bool {
+	return !p.search.inputActive && p.search.query != ""
+}
+
+// ClearSearch removes the current search and its highlights
+func (p *PreviewModel) ClearSearch() {
+	hadQuery := p.search.query != "" && len(p.search.lines) > 0
+	lines := p.search.lines
+	p.search = previewSearch{}
+	if hadQuery {
+		p.setViewportLines(lines)
+	}
+}
+
+// SearchMatchCount returns the number of lines matching the current query
+func (p *PreviewModel) SearchMatchCount() int {
+	return len(p.search.matches)
+}
+
+// handleSearchKey processes a key press while in search input mode
+func (p *PreviewModel) handleSearchKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		p.search.inputActive = false
+		p.search.query = ""
+	case tea.KeyEnter:
+		p.search.inputActive = false
+		p.applySearch()
+	case tea.KeyBackspace:
+		runes := []rune(p.search.query)
+		if len(runes) > 0 {
+			p.search.query = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		p.search.query += " "
+	case tea.KeyRunes:
+		p.search.query += string(msg.Runes)
+	}
+}
+
+// applySearch renders the preview content, finds matching lines and jumps to the first match
+func (p *PreviewModel) applySearch() {
+	if p.search.query == "" || p.content == "" {
+		p.search = previewSearch{}
+		return
+	}
+
+	lines, err := p.renderedLines()
+	if err != nil {
+		p.search = previewSearch{}
+		return
+	}
+
+	p.search.lines = lines
+	p.search.matches = findMatchingLines(p.search.lines, p.search.query)
+	p.search.current = 0
+	p.showSearchMatch()
+}
+
+// NextMatch moves to the next search match, wrapping around
+func (p *PreviewModel) NextMatch() {
+	if len(p.search.matches) == 0 {
+		return
+	}
+	p.search.current = (p.search.current + 1) % len(p.search.matches)
+	p.showSearchMatch()
+}
+
+// PrevMatch moves to the previous search match, wrapping around
+func (p *PreviewModel) PrevMatch() {
+	if len(p.search.matches) == 0 {
+		return
+	}
+	p.search.current = (p.search.current - 1 + len(p.search.matches)) % len(p.search.matches)
+	p.showSearchMatch()
+}
+
+// showSearchMatch highlights all matches and scrolls to the current one
+func (p *PreviewModel) showSearchMatch() {
+	pattern := searchPattern(p.search.query)
+
+	lines := make([]string, len(p.search.lines))
+	copy(lines, p.search.lines)
+	for i, lineIdx := range p.search.matches {
+		style := searchMatchStyle
+		if i == p.search.current {
+			style = searchCurrentMatchStyle
+		}
+		lines[lineIdx] = highlightMatches(p.search.lines[lineIdx], pattern, style)
+	}
+	p.setViewportLines(lines)
+
+	if len(p.search.matches) > 0 {
+		// Keep a little context above the match
+		offset := p.search.matches[p.search.current] - 2
+		if offset < 0 {
+			offset = 0
+		}
+		p.markdownBubble.Viewport.SetYOffset(offset)
+	}
+}
+
+// renderedLines renders the preview content into the lines shown by the viewport
+func (p *PreviewModel) renderedLines() ([]string, error) {
+	viewport := &p.markdownBubble.Viewport
+	rendered, err := renderContent(viewport.Width, p.content, p.raw)
+	if err != nil {
+		return nil, err
+	}
+	content := lipgloss.NewStyle().
+		Width(viewport.Width).
+		Height(viewport.Height).
+		Render(rendered)
+	return strings.Split(content, "\n"), nil
+}
+
+// setViewportLines replaces the viewport content the same way the markdown bubble renders it
+func (p *PreviewModel) setViewportLines(lines []string) {
+	viewport := &p.markdownBubble.Viewport
+	yOffset := viewport.YOffset
+	content := lipgloss.NewStyle().
+		Width(viewport.Width).
+		Height(viewport.Height).
+		Render(strings.Join(lines, "\n"))
+	viewport.SetContent(content)
+	viewport.SetYOffset(yOffset)
+}
+
+// SearchStatus returns the text for the search status line, or empty when no search is active
+func (p *PreviewModel) SearchStatus() string {
+	if p.search.inputActive {
+		return searchPromptStyle.Render("/" + p.search.query + "█")
+	}
+	if p.search.query == "" {
+		return ""
+	}
+	if len(p.search.matches) == 0 {
+		return searchPromptStyle.Render(fmt.Sprintf("/%s: no matches", p.search.query))
+	}
+	return searchPromptStyle.Render(fmt.Sprintf("/%s: %d/%d (n/N next/prev, esc clear)",
+		p.search.query, p.search.current+1, len(p.search.matches)))
+}
+
+// searchPattern builds a case-insensitive literal pattern for query
+func searchPattern(query string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+}
+
+// findMatchingLines returns indices of lines whose visible text contains query
+func findMatchingLines(lines []string, query string) []int {
+	if query == "" {
+		return nil
+	}
+	pattern := searchPattern(query)
+
+	var matches []int
+	for i, line := range lines {
+		if pattern.MatchString(ansi.Strip(line)) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// highlightMatches renders a line as plain text with every match styled.
+// Styling is dropped from matched lines so that highlights never overlap existing escape codes.
+func highlightMatches(line string, pattern *regexp.Regexp, style lipgloss.Style) string {
+	plain := ansi.Strip(line)
+	return pattern.ReplaceAllStringFunc(plain, func(match string) string {
+		return style.Render(match)
+	})
+}
@@ -0,0 +1,152 @@
+package filepicker
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stubRenderMarkdown replaces glamour rendering with a pass-through for the duration of a test
+func stubRenderMarkdown(t *testing.T) {
+	t.Helper()
+	orig := renderMarkdown
+	renderMarkdown = func(width int, content string) (string, error) {
+		return content, nil
+	}
+	t.Cleanup(func() { renderMarkdown = orig })
+}
+
+// typeQuery sends a search query followed by enter to the model
+func typeQuery(m Model, query string) Model {
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = updated.(Model)
+	for _, r := range query {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updated.(Model)
+}
+
+func newSearchTestModel(content string) Model {
+	m := NewModel(".", false)
+	m.preview.SetSize(80, 5)
+	m.preview.content = content
+	// Simulate the markdown bubble having rendered the content
+	m.preview.setViewportLines(strings.Split(content, "\n"))
+	return m
+}
+
+func TestFindMatchingLines(t *testing.T) {
+	lines := []string{
+		"### User",
+		"Hello \x1b[1mWorld\x1b[0m",
+		"nothing here",
+		"world again",
+	}
+
+	matches := findMatchingLines(lines, "world")
+	if len(matches) != 2 || matches[0] != 1 || matches[1] != 3 {
+		t.Errorf("Expected matches [1 3], got %v", matches)
+	}
+
+	if matches := findMatchingLines(lines, ""); matches != nil {
+		t.Errorf("Expected no matches for empty query, got %v", matches)
+	}
+}
+
+func TestPreviewSearchKeys(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	content := strings.Join([]string{
+		"line 0", "line 1", "needle one", "line 3", "line 4",
+		"line 5", "line 6", "needle two", "line 8", "line 9",
+	}, "\n")
+	m := newSearchTestModel(content)
+
+	m = typeQuery(m, "needle")
+
+	if m.preview.IsSearchInputActive() {
+		t.Fatal("Search input should be closed after enter")
+	}
+	if m.preview.SearchMatchCount() != 2 {
+		t.Fatalf("Expected 2 matches, got %d", m.preview.SearchMatchCount())
+	}
+	if m.preview.markdownBubble.Viewport.YOffset != 0 {
+		t.Errorf("Expected first match at offset 0, got %d", m.preview.markdownBubble.Viewport.YOffset)
+	}
+	if !strings.Contains(m.preview.SearchStatus(), "1/2") {
+		t.Errorf("Expected status 1/2, got %q", m.preview.SearchStatus())
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = updated.(Model)
+	if m.preview.markdownBubble.Viewport.YOffset != 5 {
+		t.Errorf("Expected second match at offset 5, got %d", m.preview.markdownBubble.Viewport.YOffset)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = updated.(Model)
+	if !strings.Contains(m.preview.SearchStatus(), "1/2") {
+		t.Errorf("Expected N to return to first match, got %q", m.preview.SearchStatus())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.preview.HasSearchQuery() || m.preview.SearchStatus() != "" {
+		t.Error("Expected esc to clear the search")
+	}
+}
+
+func TestPreviewSearchInputCapturesKeys(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	m := newSearchTestModel("some content")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = updated.(Model)
+
+	// "q" must be typed into the query instead of quitting
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = updated.(Model)
+	if cmd != nil {
+		t.Error("Keys typed into the search should not produce commands")
+	}
+	if !strings.Contains(m.preview.SearchStatus(), "/q") {
+		t.Errorf("Expected query to contain typed key, got %q", m.preview.SearchStatus())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.preview.IsSearchInputActive() {
+		t.Error("Expected esc to cancel search input")
+	}
+}
+
+func TestPreviewSearchNoMatches(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	m := newSearchTestModel("alpha\nbeta")
+	m = typeQuery(m, "gamma")
+
+	if m.preview.SearchMatchCount() != 0 {
+		t.Errorf("Expected no matches, got %d", m.preview.SearchMatchCount())
+	}
+	if !strings.Contains(m.preview.SearchStatus(), "no matches") {
+		t.Errorf("Expected no matches status, got %q", m.preview.SearchStatus())
+	}
+}
+
+func TestPreviewSetContentClearsSearch(t *testing.T) {
+	stubRenderMarkdown(t)
+
+	m := newSearchTestModel("alpha\nbeta")
+	m = typeQuery(m, "beta")
+	m.preview.SetContent("")
+
+	if m.preview.HasSearchQuery() {
+		t.Error("Expected new content to clear the search")
+	}
+}
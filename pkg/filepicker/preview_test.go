@@ -3,6 +3,7 @@ package filepicker
 import (
 	tea "github.com/charmbracelet/bubbletea"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -167,28 +168,41 @@ func TestPreviewModel_DefaultState(t *testing.T) {
 	}
 }
 
-func TestPreviewModel_Cleanup(t *testing.T) {
-	preview := NewPreviewModel()
-
-	// Set some content to create temp file
-	_ = preview.SetContent("# Test Content\n\nThis is a test.")
+func TestPreviewModel_RendersInMemory(t *testing.T) {
+	stubRenderMarkdown(t)
+	dir := t.TempDir()
+	SetTempDir(dir)
+	t.Cleanup(func() { SetTempDir("") })
 
-	// Check that temp file was created
-	if preview.tempFile == "" {
-		t.Errorf("SetContent should create a temp file")
+	preview := NewPreviewModel()
+	preview.SetSize(80, 10)
+	cmd := preview.SetContent("# Test Content\n\nThis is a test.")
+	if cmd == nil {
+		t.Fatal("SetContent should return a render command")
 	}
+	preview.Update(cmd())
 
-	// Check temp file exists
-	if _, err := os.Stat(preview.tempFile); os.IsNotExist(err) {
-		t.Errorf("Temp file should exist after SetContent")
+	if !strings.Contains(preview.markdownBubble.Viewport.View(), "This is a test.") {
+		t.Errorf("Expected the rendered content in the viewport, got:\n%s", preview.markdownBubble.Viewport.View())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no temporary files, found %d", len(entries))
 	}
+}
 
-	// Cleanup should remove temp file
-	preview.Cleanup()
+func TestPreviewModel_DropsStaleRender(t *testing.T) {
+	stubRenderMarkdown(t)
+	preview := NewPreviewModel()
+	preview.SetSize(80, 10)
+
+	stale := preview.SetContent("old content")
+	current := preview.SetContent("new content")
+	preview.Update(current())
+	preview.Update(stale())
 
-	// Check temp file is removed
-	if preview.tempFile != "" {
-		t.Errorf("Cleanup should clear tempFile path")
+	view := preview.markdownBubble.Viewport.View()
+	if !strings.Contains(view, "new content") || strings.Contains(view, "old content") {
+		t.Errorf("Expected a render of replaced content to be dropped, got:\n%s", view)
 	}
 }
 
@@ -252,3 +266,40 @@ func TestPreviewModel_KeyBindings_GoToBottom(t *testing.T) {
 		t.Errorf("After 'G' key press, should be at bottom (YOffset>0), got YOffset=%d, totalLines=%d, height=%d", finalOffset, totalLines, height)
 	}
 }
+
+func TestGeneratePreviewCollapse(t *testing.T) {
+	tempDir := t.TempDir()
+	path := tempDir + "/long.jsonl"
+	content := `{"type":"assistant","message":{"role":"assistant","content":"l1\nl2\nl3\nl4\nl5"},"uuid":"a","timestamp":"2025-07-06T05:01:44.663Z"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	collapsed, err := GeneratePreview(path, true, 2)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if !strings.Contains(collapsed, "3 more lines collapsed") || strings.Contains(collapsed, "l5") {
+		t.Errorf("Expected collapsed preview, got:\n%s", collapsed)
+	}
+
+	expanded, err := GeneratePreview(path, true)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if !strings.Contains(expanded, "l5") {
+		t.Errorf("Expected full content without threshold, got:\n%s", expanded)
+	}
+}
+
+func TestExpandMessagesToggle(t *testing.T) {
+	m := NewModel(".", false)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if !updated.(Model).expandMessages {
+		t.Error("Expected 'e' to expand messages")
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if updated.(Model).expandMessages {
+		t.Error("Expected second 'e' to collapse messages again")
+	}
+}
@@ -0,0 +1,30 @@
+package filepicker
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// projectFilter limits file listings to the sessions of matching projects ("" lists all)
+var projectFilter string
+
+// SetProjectFilter lists only the sessions whose project name matches pattern, exactly or as a glob.
+// An empty pattern lists every session.
+func SetProjectFilter(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid project pattern %q: %w", pattern, err)
+	}
+	projectFilter = pattern
+	return nil
+}
+
+// listedProject reports whether sessions of the named project are listed.
+// Sessions without a project, including unparsable ones, are hidden while a filter is set.
+func listedProject(projectName string) bool {
+	if projectFilter == "" {
+		return true
+	}
+	return projectName != "" && types.MatchProject(projectFilter, projectName)
+}
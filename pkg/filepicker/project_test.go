@@ -2,6 +2,9 @@ package filepicker
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -271,3 +274,55 @@ func TestExtractConversationInfo_WithSummaryFirst(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectFilter(t *testing.T) {
+	root := t.TempDir()
+	for name, cwd := range map[string]string{
+		"a/one.jsonl":   "/src/cclog",
+		"b/two.jsonl":   "/src/cclog-web",
+		"c/three.jsonl": "/src/other",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		session := `{"type":"user","cwd":"` + cwd + `","message":{"role":"user","content":"hello"},"uuid":"u1"}` + "\n"
+		if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+			t.Fatalf("Failed to write session: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "broken.jsonl"), []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+	defer SetProjectFilter("")
+
+	if err := SetProjectFilter("["); err == nil {
+		t.Error("Expected error for a malformed pattern")
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", "broken.jsonl,one.jsonl,three.jsonl,two.jsonl"},
+		{"cclog", "one.jsonl"},
+		{"cclog*", "one.jsonl,two.jsonl"},
+	}
+	for _, tt := range tests {
+		if err := SetProjectFilter(tt.pattern); err != nil {
+			t.Fatalf("SetProjectFilter failed: %v", err)
+		}
+		files, err := GetFilesRecursive(root)
+		if err != nil {
+			t.Fatalf("GetFilesRecursive failed: %v", err)
+		}
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name)
+		}
+		sort.Strings(names)
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("Pattern %q listed %s, want %s", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package filepicker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/parser"
+)
+
+// rawPreview pretty-prints the JSONL lines of a session for the raw preview, each under a
+// "// line N" header with its line number in the file. Lines that are not valid JSON are shown
+// as they are, with the parse error in the header. Only the first limit lines are shown unless
+// limit is 0; total is the number of non-empty lines in the file.
+func rawPreview(path string, limit int) (content string, total int, err error) {
+	file, err := parser.OpenLog(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var sb strings.Builder
+	// Lines are read whole, however long, since a raw view is most useful for lines the parser rejects
+	reader := bufio.NewReader(file)
+	for lineNum := 1; ; lineNum++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return "", 0, fmt.Errorf("error reading file %s: %w", path, readErr)
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			total++
+			if limit == 0 || total <= limit {
+				writeRawLine(&sb, lineNum, line)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	return sb.String(), total, nil
+}
+
+// writeRawLine writes one JSONL line indented, or as is with the error if it is not valid JSON
+func writeRawLine(sb *strings.Builder, lineNum int, line []byte) {
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, line, "", "  "); err != nil {
+		fmt.Fprintf(sb, "// line %d: invalid JSON: %v\n", lineNum, err)
+		sb.Write(line)
+		sb.WriteString("\n")
+		return
+	}
+	fmt.Fprintf(sb, "// line %d\n", lineNum)
+	sb.Write(indented.Bytes())
+	sb.WriteString("\n")
+}
@@ -0,0 +1,64 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRawPreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"type":"user","uuid":"u1"}` + "\n\n" + `{"type":"assistant","message":` + "\n" + `{"b":[1,2]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+
+	got, total, err := rawPreview(path, 0)
+	if err != nil {
+		t.Fatalf("rawPreview failed: %v", err)
+	}
+	want := "// line 1\n{\n  \"type\": \"user\",\n  \"uuid\": \"u1\"\n}\n\n" +
+		"// line 3: invalid JSON: unexpected end of JSON input\n{\"type\":\"assistant\",\"message\":\n\n" +
+		"// line 4\n{\n  \"b\": [\n    1,\n    2\n  ]\n}\n"
+	if got != want || total != 3 {
+		t.Errorf("Unexpected raw preview (%d lines):\n%s\nwant:\n%s", total, got, want)
+	}
+
+	got, total, err = rawPreview(path, 1)
+	if err != nil || total != 3 || strings.Contains(got, "line 3") {
+		t.Errorf("Expected only the first line of 3, got %d lines:\n%s", total, got)
+	}
+}
+
+func TestRawPreviewToggle(t *testing.T) {
+	stubRenderMarkdown(t)
+	dir := t.TempDir()
+	path := writeLongSession(t, dir, previewMessageLimit+1, "hello")
+	m := NewModel(dir, false)
+	m.files = []FileInfo{{Name: "long.jsonl", Path: path}}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	m = updated.(Model)
+	msg, ok := previewLoaded(cmd)
+	if !ok || !m.rawPreview || !m.preview.IsRaw() {
+		t.Fatal("Expected J to switch to the raw preview and load it")
+	}
+	m.showLoadedPreview(msg)
+	content := m.preview.GetContent()
+	if !strings.HasPrefix(content, "// line 1\n{\n  \"type\": \"user\",") {
+		t.Errorf("Expected pretty-printed JSONL lines, got:\n%.200s", content)
+	}
+	if !strings.HasSuffix(content, "// The preview shows the first 200 of 201 lines. Press L to load the rest.\n") {
+		t.Errorf("Expected a note about the remaining lines, got:\n%s", content[max(len(content)-200, 0):])
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	m = updated.(Model)
+	loadSelectedPreview(t, &m)
+	if m.preview.IsRaw() || !strings.Contains(m.preview.GetContent(), "### User") {
+		t.Errorf("Expected J to switch back to markdown, got:\n%.200s", m.preview.GetContent())
+	}
+}
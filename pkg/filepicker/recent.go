@@ -0,0 +1,77 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxRecentSessions is the number of recently viewed sessions that are remembered
+const maxRecentSessions = 20
+
+// addRecentSession moves path to the front of recent, dropping duplicates and old entries
+func addRecentSession(recent []string, path string) []string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+
+	updated := []string{path}
+	for _, p := range recent {
+		if p != path && len(updated) < maxRecentSessions {
+			updated = append(updated, p)
+		}
+	}
+	return updated
+}
+
+// getRecentFiles returns file info for the recent session paths in order, skipping missing files
+func getRecentFiles(paths []string) []FileInfo {
+	var files []FileInfo
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		title, projectName, parseErr := inspectFile(path, info)
+		file := FileInfo{
+			Name:              info.Name(),
+			Path:              path,
+			Size:              info.Size(),
+			ModTime:           info.ModTime(),
+			ConversationTitle: title,
+			ProjectName:       projectName,
+		}
+		if parseErr != nil {
+			file.ParseError = parseErr.Error()
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// loadRecentFiles loads the recently viewed sessions as the file list
+func loadRecentFiles(paths []string) tea.Cmd {
+	return func() tea.Msg {
+		return filesLoadedMsg{files: getRecentFiles(paths)}
+	}
+}
+
+// RecentSessions returns the recently viewed session paths, most recent first
+func (m Model) RecentSessions() []string {
+	return m.recentSessions
+}
+
+// SetRecentSessions sets the recently viewed session paths, most recent first
+func (m *Model) SetRecentSessions(paths []string) {
+	if len(paths) > maxRecentSessions {
+		paths = paths[:maxRecentSessions]
+	}
+	m.recentSessions = paths
+}
+
+// recordRecentSession remembers that the session at path was opened
+func (m *Model) recordRecentSession(path string) {
+	m.recentSessions = addRecentSession(m.recentSessions, path)
+}
@@ -0,0 +1,102 @@
+package filepicker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAddRecentSession(t *testing.T) {
+	recent := addRecentSession(nil, "/logs/a.jsonl")
+	recent = addRecentSession(recent, "/logs/b.jsonl")
+	recent = addRecentSession(recent, "/logs/a.jsonl")
+
+	if len(recent) != 2 || recent[0] != "/logs/a.jsonl" || recent[1] != "/logs/b.jsonl" {
+		t.Errorf("Expected [/logs/a.jsonl /logs/b.jsonl], got %v", recent)
+	}
+
+	for i := 0; i < maxRecentSessions+5; i++ {
+		recent = addRecentSession(recent, fmt.Sprintf("/logs/%d.jsonl", i))
+	}
+	if len(recent) != maxRecentSessions {
+		t.Errorf("Expected %d recent sessions, got %d", maxRecentSessions, len(recent))
+	}
+	if want := fmt.Sprintf("/logs/%d.jsonl", maxRecentSessions+4); recent[0] != want {
+		t.Errorf("Expected most recent session %s first, got %s", want, recent[0])
+	}
+}
+
+func TestGetRecentFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	content := `{"type":"user","message":{"role":"user","content":"test"},"uuid":"test-uuid","timestamp":"2025-07-06T05:01:44.663Z"}`
+
+	older := filepath.Join(tempDir, "older.jsonl")
+	newer := filepath.Join(tempDir, "project", "newer.jsonl")
+	if err := os.MkdirAll(filepath.Dir(newer), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, path := range []string{older, newer} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	files := getRecentFiles([]string{newer, filepath.Join(tempDir, "deleted.jsonl"), older})
+
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files (missing file skipped), got %d", len(files))
+	}
+	if files[0].Path != newer || files[1].Path != older {
+		t.Errorf("Expected recent order to be kept, got %s, %s", files[0].Path, files[1].Path)
+	}
+	if files[0].ConversationTitle != "test" {
+		t.Errorf("Expected conversation title 'test', got %q", files[0].ConversationTitle)
+	}
+}
+
+func TestModelToggleRecentView(t *testing.T) {
+	tempDir := t.TempDir()
+	session := filepath.Join(tempDir, "session.jsonl")
+	content := `{"type":"user","message":{"role":"user","content":"test"},"uuid":"test-uuid","timestamp":"2025-07-06T05:01:44.663Z"}`
+	if err := os.WriteFile(session, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	model := NewModel(tempDir, false)
+	model.SetRecentSessions([]string{session})
+	model.files = []FileInfo{{Name: "other", Path: filepath.Join(tempDir, "other"), IsDir: true}}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	model = updated.(Model)
+	if !model.showRecent {
+		t.Fatal("Expected 'h' to switch to the recent sessions view")
+	}
+
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+	if len(model.files) != 1 || model.files[0].Path != session {
+		t.Errorf("Expected recent sessions to be listed, got %+v", model.files)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	model = updated.(Model)
+	if model.showRecent {
+		t.Error("Expected 'h' to switch back to the directory listing")
+	}
+}
+
+func TestModelRecordsOpenedSessions(t *testing.T) {
+	model := NewModel("/tmp", false)
+	model.files = []FileInfo{{Name: "a.jsonl", Path: "/logs/a.jsonl"}}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+
+	recent := model.RecentSessions()
+	if len(recent) != 1 || recent[0] != "/logs/a.jsonl" {
+		t.Errorf("Expected opened session to be recorded, got %v", recent)
+	}
+}
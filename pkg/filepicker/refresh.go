@@ -0,0 +1,71 @@
+package filepicker
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshTickMsg triggers a periodic re-scan of the listed directory
+type refreshTickMsg struct{}
+
+// SetRefreshInterval re-scans the listed directory every interval so new sessions appear
+// without restarting. Zero disables periodic refreshes; the refresh key always works.
+func (m *Model) SetRefreshInterval(interval time.Duration) {
+	m.refreshInterval = interval
+}
+
+// refreshTick schedules the next periodic refresh
+func refreshTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+// refreshFiles re-reads dir in one pass. Unlike loadFiles, a recursive refresh is not streamed
+// in batches, so the list never shrinks to a partial result while it is being rebuilt.
+func refreshFiles(dir string, recursive bool) tea.Cmd {
+	return func() tea.Msg {
+		var files []FileInfo
+		var err error
+		if recursive {
+			files, err = GetFilesRecursive(dir)
+		} else {
+			files, err = GetFiles(dir)
+		}
+		if err != nil {
+			return nil // Keep the current list if the directory can't be read right now
+		}
+		return filesRefreshedMsg{dir: dir, files: files}
+	}
+}
+
+// filesRefreshedMsg carries a re-scan of an already listed directory
+type filesRefreshedMsg struct {
+	dir   string
+	files []FileInfo
+}
+
+// applyRefresh replaces the list with a re-scan, keeping the cursor on the selected file and the scroll position.
+// It returns whether the selected file changed, so the preview is only regenerated when needed.
+func (m *Model) applyRefresh(files []FileInfo) bool {
+	var previous FileInfo
+	hadSelection := m.cursor >= 0 && m.cursor < len(m.files)
+	if hadSelection {
+		previous = m.files[m.cursor]
+	}
+
+	m.files = m.visibleFiles(files)
+	if hadSelection {
+		m.selectPath(previous.Path)
+	}
+	// ensureCursorVisible clamps the cursor if the selected file was removed and keeps the scroll offset otherwise
+	m.ensureCursorVisible()
+
+	if len(m.files) == 0 {
+		return hadSelection
+	}
+	current := m.files[m.cursor]
+	return !hadSelection || current.Path != previous.Path ||
+		!current.ModTime.Equal(previous.ModTime) || current.Size != previous.Size
+}
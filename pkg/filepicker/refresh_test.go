@@ -0,0 +1,74 @@
+package filepicker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRefreshKeepsCursorAndScroll(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var files []FileInfo
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		files = append(files, FileInfo{Name: name + ".jsonl", Path: filepath.Join(dir, name+".jsonl"), ModTime: now.Add(-time.Duration(i) * time.Minute)})
+	}
+
+	model := NewModel(dir, false)
+	model.maxDisplayFiles = 3
+	model.files = files
+	model.cursor = 3 // d.jsonl
+	model.scrollOffset = 2
+
+	// A new session sorts first, shifting every index by one
+	added := FileInfo{Name: "new.jsonl", Path: filepath.Join(dir, "new.jsonl"), ModTime: now.Add(time.Minute)}
+	updated, _ := model.Update(filesRefreshedMsg{dir: dir, files: append([]FileInfo{added}, files...)})
+	m := updated.(Model)
+	if m.files[m.cursor].Name != "d.jsonl" {
+		t.Errorf("Expected cursor to stay on d.jsonl, got %s", m.files[m.cursor].Name)
+	}
+	if m.scrollOffset != 2 {
+		t.Errorf("Expected scroll offset to be kept at 2, got %d", m.scrollOffset)
+	}
+	if len(m.files) != 7 {
+		t.Errorf("Expected the new session to be listed, got %d files", len(m.files))
+	}
+}
+
+func TestApplyRefreshReportsSelectionChanges(t *testing.T) {
+	dir := t.TempDir()
+	session := FileInfo{Name: "a.jsonl", Path: filepath.Join(dir, "a.jsonl"), Size: 10}
+
+	model := NewModel(dir, false)
+	model.files = []FileInfo{session}
+	if model.applyRefresh([]FileInfo{session}) {
+		t.Error("An unchanged selection should not regenerate the preview")
+	}
+
+	grown := session
+	grown.Size = 20
+	if !model.applyRefresh([]FileInfo{grown}) {
+		t.Error("A session that grew should regenerate the preview")
+	}
+}
+
+func TestStaleRefreshIsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	model := NewModel(dir, false)
+	model.files = []FileInfo{{Name: "a.jsonl", Path: filepath.Join(dir, "a.jsonl")}}
+
+	updated, _ := model.Update(filesRefreshedMsg{dir: filepath.Join(dir, "other"), files: nil})
+	if got := len(updated.(Model).files); got != 1 {
+		t.Errorf("A refresh of another directory should be ignored, got %d files", got)
+	}
+}
+
+func TestRefreshTick(t *testing.T) {
+	model := NewModel(t.TempDir(), false)
+	model.SetRefreshInterval(time.Minute)
+
+	_, cmd := model.Update(refreshTickMsg{})
+	if cmd == nil {
+		t.Fatal("A refresh tick should re-scan and schedule the next tick")
+	}
+}
@@ -4,42 +4,61 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/annenpolka/cclog/internal/parser"
+	"github.com/annenpolka/cclog/internal/shellquote"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // execCommand is a variable that can be replaced in tests to mock os/exec.Command
 var execCommand = exec.Command
 
-// generateResumeCommand generates the claude resume command and its arguments
-func generateResumeCommand(filePath string, dangerous bool) (string, []string, error) {
+// resumeArgs returns the arguments of claude that resume the session of a JSONL file.
+// The arguments are passed to claude directly, never through a shell, so a crafted file name
+// cannot run other commands; names that claude would parse as an option are rejected.
+func resumeArgs(filePath string, dangerous bool) ([]string, error) {
 	sessionId, err := extractSessionID(filePath)
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+	if strings.HasPrefix(sessionId, "-") {
+		return nil, fmt.Errorf("invalid sessionId %q: must not start with '-'", sessionId)
 	}
 
 	args := []string{"-r", sessionId}
 	if dangerous {
 		args = append(args, "--dangerously-skip-permissions")
 	}
+	return args, nil
+}
+
+// generateResumeCommand generates the claude resume command and its arguments
+func generateResumeCommand(filePath string, dangerous bool) (string, []string, error) {
+	args, err := resumeArgs(filePath, dangerous)
+	if err != nil {
+		return "", nil, err
+	}
 	return "claude", args, nil
 }
 
+// generateResumeCommandLine generates the claude resume command as a single string,
+// with the arguments quoted for pasting into the shell of the platform
+func generateResumeCommandLine(filePath string) (string, error) {
+	cmdName, cmdArgs, err := generateResumeCommand(filePath, false)
+	if err != nil {
+		return "", err
+	}
+	return shellquote.Command(append([]string{cmdName}, cmdArgs...)), nil
+}
+
 // generateResumeCommandWithDirectoryChange generates the claude resume command, its arguments, and the directory to execute in
 func generateResumeCommandWithDirectoryChange(filePath string, dangerous bool) (string, []string, string, error) {
-	sessionId, err := extractSessionID(filePath)
+	args, err := resumeArgs(filePath, dangerous)
 	if err != nil {
 		return "", nil, "", err
 	}
-
-	dir := filepath.Dir(filePath)
-
-	args := []string{"-r", sessionId}
-	if dangerous {
-		args = append(args, "--dangerously-skip-permissions")
-	}
-	return "claude", args, dir, nil
+	return "claude", args, filepath.Dir(filePath), nil
 }
 
 // extractCWDFromJSONL extracts CWD from JSONL file
@@ -61,7 +80,7 @@ func extractCWDFromJSONL(filePath string) (string, error) {
 
 // generateResumeCommandWithCWDChange generates the claude resume command, its arguments, and the CWD to execute in
 func generateResumeCommandWithCWDChange(filePath string, dangerous bool) (string, []string, string, error) {
-	sessionId, err := extractSessionID(filePath)
+	args, err := resumeArgs(filePath, dangerous)
 	if err != nil {
 		return "", nil, "", err
 	}
@@ -70,14 +89,20 @@ func generateResumeCommandWithCWDChange(filePath string, dangerous bool) (string
 	if err != nil {
 		return "", nil, "", err
 	}
-
-	args := []string{"-r", sessionId}
-	if dangerous {
-		args = append(args, "--dangerously-skip-permissions")
+	// The directory is set as the working directory of the process rather than passed to cd,
+	// but relative paths would still depend on where cclog runs
+	if !filepath.IsAbs(cwd) {
+		return "", nil, "", fmt.Errorf("working directory %q of the session is not an absolute path", cwd)
 	}
 	return "claude", args, cwd, nil
 }
 
+// ResumeCommand returns the claude command and arguments that resume the session of a JSONL file,
+// and the working directory of the session to run them in
+func ResumeCommand(filePath string, dangerous bool) (string, []string, string, error) {
+	return generateResumeCommandWithCWDChange(filePath, dangerous)
+}
+
 // resumeMsg represents the result of executing a resume command
 type resumeMsg struct {
 	success bool
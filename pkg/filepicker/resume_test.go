@@ -70,6 +70,15 @@ func TestResumeCommand(t *testing.T) {
 			expectedErr:     false,
 			description:     "sessionIdへのインジェクション試行が正しく処理される",
 		},
+		{
+			name:            "sessionId_option_injection_attempt",
+			filePath:        "/path/to/--dangerously-skip-permissions.jsonl",
+			dangerous:       false,
+			expectedCmdName: "",
+			expectedArgs:    nil,
+			expectedErr:     true,
+			description:     "オプションとして解釈されるsessionIdはエラーになる",
+		},
 	}
 
 	for _, tt := range tests {
@@ -380,3 +389,58 @@ func TestResumeKeyHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateResumeCommandLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		filePath    string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "jsonl file",
+			filePath: "/path/to/session-123.jsonl",
+			expected: "claude -r session-123",
+		},
+		{
+			name:     "shell metacharacters are quoted",
+			filePath: "/path/to/session-id;evil $(command).jsonl",
+			expected: "claude -r 'session-id;evil $(command)'",
+		},
+		{
+			name:        "non jsonl file",
+			filePath:    "/path/to/session-123.txt",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandLine, err := generateResumeCommandLine(tt.filePath)
+			if tt.expectedErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if commandLine != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, commandLine)
+			}
+		})
+	}
+}
+
+func TestResumeWithRelativeCWD(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "session-123.jsonl")
+	content := `{"cwd":"-rf","sessionId":"session-123","type":"user","message":"test"}`
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := generateResumeCommandWithCWDChange(filePath, false); err == nil {
+		t.Error("Expected error for a working directory that is not absolute")
+	}
+}
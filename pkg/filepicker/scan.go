@@ -0,0 +1,107 @@
+package filepicker
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// spinnerInterval is the time between spinner frames while a scan runs
+const spinnerInterval = 100 * time.Millisecond
+
+// scanProgressInterval is how often a recursive scan sends the files found so far
+var scanProgressInterval = 100 * time.Millisecond
+
+var (
+	spinnerFrames      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	plainSpinnerFrames = []string{"|", "/", "-", "\\"}
+)
+
+// errScanStopped stops a recursive walk whose results are no longer wanted
+var errScanStopped = errors.New("scan stopped")
+
+// fileScan walks a directory tree in the background, streaming the files found so far
+// so that large trees fill the list progressively instead of blocking the UI
+type fileScan struct {
+	dir     string
+	batches chan filesLoadedMsg
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// spinnerTickMsg advances the scan spinner
+type spinnerTickMsg struct{}
+
+// startFileScan starts walking dir in the background
+func startFileScan(dir string) *fileScan {
+	scan := &fileScan{
+		dir:     dir,
+		batches: make(chan filesLoadedMsg),
+		stop:    make(chan struct{}),
+	}
+	go scan.run()
+	return scan
+}
+
+// run walks the tree, sending partial batches while walking and a final batch when done
+func (s *fileScan) run() {
+	defer close(s.batches)
+
+	send := func(msg filesLoadedMsg) bool {
+		select {
+		case s.batches <- msg:
+			return true
+		case <-s.stop:
+			return false
+		}
+	}
+
+	files, scanned, limited, err := scanFilesRecursive(s.dir, func(files []FileInfo, scanned int) bool {
+		return send(filesLoadedMsg{files: files, scanned: scanned, partial: true, scan: s})
+	})
+	if errors.Is(err, errScanStopped) {
+		return
+	}
+	if err != nil {
+		files = []FileInfo{}
+	}
+	send(filesLoadedMsg{files: files, scanned: scanned, limited: limited, scan: s})
+}
+
+// next waits for the next batch of the scan
+func (s *fileScan) next() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-s.batches
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// cancel stops the scan. It is safe to call more than once.
+func (s *fileScan) cancel() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// spinnerTick schedules the next spinner frame
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// scanStatus renders the spinner and the number of files scanned, or "" when no scan is running
+func (m Model) scanStatus() string {
+	if !m.scanning {
+		return ""
+	}
+	frames := spinnerFrames
+	if m.plain {
+		frames = plainSpinnerFrames
+	}
+	return frames[m.spinnerFrame%len(frames)] + " scanned " + strconv.Itoa(m.scanned) + " files"
+}
@@ -0,0 +1,118 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const scanSession = `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1","timestamp":"2025-07-06T05:01:29.618Z"}
+`
+
+func TestLoadFilesRecursiveScan(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a/one.jsonl", "b/two.jsonl", "b/notes.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(scanSession), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// Small trees finish within one progress interval, so the first batch is the final one
+	msg, ok := loadFiles(root, true)().(filesLoadedMsg)
+	if !ok {
+		t.Fatal("Expected filesLoadedMsg")
+	}
+	if msg.partial || msg.scan == nil {
+		t.Errorf("Expected a final batch from a scan, got partial=%t scan=%v", msg.partial, msg.scan)
+	}
+	if len(msg.files) != 2 || msg.scanned != 2 {
+		t.Errorf("Expected 2 files scanned and listed, got %d listed, %d scanned", len(msg.files), msg.scanned)
+	}
+}
+
+func TestScanFilesRecursiveProgress(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "one.jsonl"), []byte(scanSession), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "two.jsonl"), []byte(scanSession), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Report after every file, then stop after the first batch
+	defer func(interval time.Duration) { scanProgressInterval = interval }(scanProgressInterval)
+	scanProgressInterval = 0
+
+	calls := 0
+	_, scanned, _, err := scanFilesRecursive(root, func(files []FileInfo, scanned int) bool {
+		calls++
+		if len(files) != 1 || scanned != 1 {
+			t.Errorf("Expected the first file in the first batch, got %d files, %d scanned", len(files), scanned)
+		}
+		return false
+	})
+	if calls != 1 || scanned != 1 {
+		t.Errorf("Stopping should end the walk after one batch, got %d batches, %d scanned", calls, scanned)
+	}
+	if err != errScanStopped {
+		t.Errorf("Expected errScanStopped, got %v", err)
+	}
+}
+
+func TestProgressiveListing(t *testing.T) {
+	root := t.TempDir()
+	model := NewModel(root, true)
+	scan := &fileScan{dir: root, batches: make(chan filesLoadedMsg), stop: make(chan struct{})}
+
+	older := FileInfo{Name: "older.jsonl", Path: filepath.Join(root, "older.jsonl"), ModTime: time.Now().Add(-time.Hour)}
+	newer := FileInfo{Name: "newer.jsonl", Path: filepath.Join(root, "newer.jsonl"), ModTime: time.Now()}
+
+	updated, cmd := model.Update(filesLoadedMsg{files: []FileInfo{older}, scanned: 1, partial: true, scan: scan})
+	m := updated.(Model)
+	if !m.scanning || cmd == nil {
+		t.Fatal("A partial batch should mark the scan running and wait for more")
+	}
+	if view := m.View(); !strings.Contains(view, "scanned 1 files") {
+		t.Errorf("Expected scan counter in header:\n%s", view)
+	}
+
+	// A newer file sorts first, but the cursor stays on the selected file
+	updated, _ = m.Update(filesLoadedMsg{files: []FileInfo{newer, older}, scanned: 2, partial: true, scan: scan})
+	m = updated.(Model)
+	if m.files[m.cursor].Path != older.Path {
+		t.Errorf("Expected cursor to stay on %s, got %s", older.Path, m.files[m.cursor].Path)
+	}
+
+	updated, _ = m.Update(filesLoadedMsg{files: []FileInfo{newer, older}, scanned: 2, scan: scan})
+	m = updated.(Model)
+	if m.scanning || strings.Contains(m.View(), "scanned") {
+		t.Error("The final batch should end the scan and hide the counter")
+	}
+	if _, cmd := m.Update(spinnerTickMsg{}); cmd != nil {
+		t.Error("The spinner should stop once the scan is done")
+	}
+}
+
+func TestStaleScanIsCancelled(t *testing.T) {
+	root := t.TempDir()
+	model := NewModel(root, true)
+	model.files = []FileInfo{{Name: "current.jsonl", Path: filepath.Join(root, "current.jsonl")}}
+	scan := &fileScan{dir: filepath.Join(root, "elsewhere"), batches: make(chan filesLoadedMsg), stop: make(chan struct{})}
+
+	updated, _ := model.Update(filesLoadedMsg{files: []FileInfo{{Name: "stale.jsonl"}}, partial: true, scan: scan})
+	m := updated.(Model)
+	if len(m.files) != 1 || m.files[0].Name != "current.jsonl" {
+		t.Errorf("Batches from a scan of another directory should be ignored, got %+v", m.files)
+	}
+	select {
+	case <-scan.stop:
+	default:
+		t.Error("Expected the stale scan to be cancelled")
+	}
+}
@@ -0,0 +1,60 @@
+package filepicker
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ScanLimits bounds recursive scans so huge synced directories stay fast
+type ScanLimits struct {
+	// MaxDepth is the number of directory levels below the root that are searched (0 means unlimited).
+	// With 1, only files in the root and its immediate subdirectories are listed.
+	MaxDepth int
+	// MaxFiles stops the scan after this many .jsonl files (0 means unlimited)
+	MaxFiles int
+	// Ignore lists glob patterns for files and directories to skip. A pattern matches
+	// the base name (e.g. "archive*") or the slash-separated path relative to the root (e.g. "old/2023-*").
+	Ignore []string
+}
+
+// scanLimits applies to every recursive scan
+var scanLimits ScanLimits
+
+// SetScanLimits sets the limits used by recursive scans, rejecting malformed ignore patterns
+func SetScanLimits(limits ScanLimits) error {
+	if limits.MaxDepth < 0 || limits.MaxFiles < 0 {
+		return fmt.Errorf("scan limits must not be negative")
+	}
+	for _, pattern := range limits.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+	scanLimits = limits
+	return nil
+}
+
+// ignored reports whether the entry at rel, relative to the scan root, matches an ignore pattern
+func (l ScanLimits) ignored(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := rel[strings.LastIndex(rel, "/")+1:]
+	for _, pattern := range l.Ignore {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tooDeep reports whether a directory at rel, relative to the scan root, is below MaxDepth
+func (l ScanLimits) tooDeep(rel string) bool {
+	if l.MaxDepth == 0 || rel == "." {
+		return false
+	}
+	return strings.Count(filepath.ToSlash(rel), "/")+1 > l.MaxDepth
+}
@@ -0,0 +1,109 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGetFilesRecursiveWithLimits(t *testing.T) {
+	root := t.TempDir()
+	session := `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1"}` + "\n"
+	for _, name := range []string{
+		"top.jsonl",
+		"project/one.jsonl",
+		"project/deep/two.jsonl",
+		"archive-2023/old.jsonl",
+		"other/skip.jsonl",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+			t.Fatalf("Failed to write session: %v", err)
+		}
+	}
+	defer SetScanLimits(ScanLimits{})
+
+	names := func() string {
+		files, err := GetFilesRecursive(root)
+		if err != nil {
+			t.Fatalf("GetFilesRecursive failed: %v", err)
+		}
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name)
+		}
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name   string
+		limits ScanLimits
+		want   string
+	}{
+		{"no limits", ScanLimits{}, "old.jsonl,one.jsonl,skip.jsonl,top.jsonl,two.jsonl"},
+		{"max depth", ScanLimits{MaxDepth: 1}, "old.jsonl,one.jsonl,skip.jsonl,top.jsonl"},
+		{"ignore by name and path", ScanLimits{Ignore: []string{"archive*", "other/skip.jsonl"}}, "one.jsonl,top.jsonl,two.jsonl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetScanLimits(tt.limits); err != nil {
+				t.Fatalf("SetScanLimits failed: %v", err)
+			}
+			if got := names(); got != tt.want {
+				t.Errorf("Listed %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := SetScanLimits(ScanLimits{MaxFiles: 2}); err != nil {
+		t.Fatalf("SetScanLimits failed: %v", err)
+	}
+	files, scanned, limited, err := scanFilesRecursive(root, nil)
+	if err != nil || len(files) != 2 || scanned != 2 || !limited {
+		t.Errorf("Expected the scan to stop after 2 files, got %d files, %d scanned, limited=%t, err=%v", len(files), scanned, limited, err)
+	}
+}
+
+func TestSetScanLimitsRejectsBadPatterns(t *testing.T) {
+	defer SetScanLimits(ScanLimits{})
+	if err := SetScanLimits(ScanLimits{Ignore: []string{"[unclosed"}}); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+	if err := SetScanLimits(ScanLimits{MaxDepth: -1}); err == nil {
+		t.Error("Expected an error for a negative depth")
+	}
+}
+
+func TestGetFilesRecursiveRespectsIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	session := `{"type":"user","message":{"role":"user","content":"hello"},"uuid":"u1"}` + "\n"
+	files := map[string]string{
+		"project/one.jsonl":     session,
+		"experiments/try.jsonl": session,
+		"project/draft.jsonl":   session,
+		".cclogignore":          "experiments/\ndraft.jsonl\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	listed, err := GetFilesRecursive(root)
+	if err != nil {
+		t.Fatalf("GetFilesRecursive failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != "one.jsonl" {
+		t.Errorf("Expected only one.jsonl, got %+v", listed)
+	}
+}
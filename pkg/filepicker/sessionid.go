@@ -3,7 +3,8 @@ package filepicker
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
+
+	"github.com/annenpolka/cclog/internal/parser"
 )
 
 // extractSessionID extracts the sessionId from the filename by removing the extension
@@ -11,13 +12,13 @@ func extractSessionID(filePath string) (string, error) {
 	// Get the base filename without directory
 	filename := filepath.Base(filePath)
 
-	// Check if file has .jsonl extension
-	if !strings.HasSuffix(strings.ToLower(filename), ".jsonl") {
+	// Check if file has a .jsonl extension, compressed or not
+	if !parser.IsLogFile(filename) {
 		return "", fmt.Errorf("file %s is not a JSONL file", filename)
 	}
 
-	// Remove the .jsonl extension to get sessionId
-	sessionId := strings.TrimSuffix(filename, filepath.Ext(filename))
+	// Remove the extension to get sessionId
+	sessionId := parser.TrimLogExt(filename)
 
 	// Check if sessionId is empty after removing extension
 	if sessionId == "" {
@@ -0,0 +1,31 @@
+package filepicker
+
+import (
+	"errors"
+	"os"
+
+	"github.com/annenpolka/cclog/internal/index"
+)
+
+// sessionIndex provides the titles and projects of indexed sessions without parsing them (nil parses every session)
+var sessionIndex *index.Index
+
+// SetSessionIndex makes listings take the title and project of sessions unchanged since they were
+// indexed from ix, for instant startup with many sessions. Changed and unindexed sessions are parsed.
+func SetSessionIndex(ix *index.Index) {
+	sessionIndex = ix
+}
+
+// inspectFile returns the title and project of the session at path like inspectConversation,
+// from the session index if it is up to date for the file
+func inspectFile(path string, info os.FileInfo) (string, string, error) {
+	if sessionIndex != nil {
+		if session, ok := sessionIndex.Lookup(path, info.Size(), info.ModTime()); ok {
+			if session.ParseError != "" {
+				return "", "", errors.New(session.ParseError)
+			}
+			return session.Title, session.Project, nil
+		}
+	}
+	return inspectConversation(path)
+}
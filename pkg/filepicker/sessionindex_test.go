@@ -0,0 +1,61 @@
+package filepicker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/annenpolka/cclog/internal/index"
+)
+
+func TestInspectFileUsesIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	content := `{"type":"user","uuid":"u1","cwd":"/work/webapp","timestamp":"2025-07-06T05:00:00Z","message":{"role":"user","content":"Indexed title"}}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := index.Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	if _, err := ix.Sync(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	SetSessionIndex(ix)
+	defer SetSessionIndex(nil)
+
+	// Same size and modification time: the indexed title is used without parsing the file
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(content, "Indexed", "Changed", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	title, project, err := inspectFile(path, info)
+	if err != nil || title != "Indexed title" || project != "webapp" {
+		t.Errorf("Expected the indexed title and project, got %q, %q, %v", title, project, err)
+	}
+
+	// A changed file is parsed
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := info.ModTime().Add(1)
+	if err := os.Chtimes(path, changed, changed); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = os.Stat(path)
+	if title, _, _ := inspectFile(path, info); title != "Changed title" {
+		t.Errorf("Expected the changed file to be parsed, got %q", title)
+	}
+}
@@ -0,0 +1,64 @@
+package filepicker
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Source is a root directory the file list can be switched to
+type Source struct {
+	Name string // Short label shown when switching, e.g. "Claude projects"
+	Dir  string
+}
+
+// SetSources sets the list sources cycled with the source key. Sources with the same directory are merged.
+func (m *Model) SetSources(sources []Source) {
+	m.sources = nil
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		if source.Dir == "" {
+			continue
+		}
+		key := cleanSourceDir(source.Dir)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		m.sources = append(m.sources, source)
+	}
+}
+
+// Sources returns the list sources cycled with the source key
+func (m Model) Sources() []Source {
+	return m.sources
+}
+
+// nextSource switches the list to the source after the current one
+func (m *Model) nextSource() tea.Cmd {
+	if len(m.sources) == 0 {
+		return nil
+	}
+
+	// Continue from the source being listed, or start over if the user navigated elsewhere
+	current := -1
+	for i, source := range m.sources {
+		if cleanSourceDir(source.Dir) == cleanSourceDir(m.dir) {
+			current = i
+			break
+		}
+	}
+	source := m.sources[(current+1)%len(m.sources)]
+
+	m.statusMessage = "Source: " + source.Name + " (" + source.Dir + ")"
+	m.statusIsError = false
+	return m.changeDir(source.Dir)
+}
+
+// cleanSourceDir normalizes a directory for comparison
+func cleanSourceDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return filepath.Clean(dir)
+}
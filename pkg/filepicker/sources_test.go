@@ -0,0 +1,74 @@
+package filepicker
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSetSourcesMergesDuplicates(t *testing.T) {
+	model := NewModel("/tmp", false)
+	model.SetSources([]Source{
+		{Name: "Claude projects", Dir: "/home/user/.claude/projects"},
+		{Name: "working directory", Dir: "/home/user/.claude/projects/"},
+		{Name: "extra root", Dir: ""},
+		{Name: "extra root", Dir: "/srv/logs"},
+	})
+
+	sources := model.Sources()
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %+v", sources)
+	}
+	if sources[0].Name != "Claude projects" || sources[1].Dir != "/srv/logs" {
+		t.Errorf("Unexpected sources: %+v", sources)
+	}
+}
+
+func TestModelCyclesSources(t *testing.T) {
+	model := NewModel("/a", false)
+	model.SetSources([]Source{
+		{Name: "first", Dir: "/a"},
+		{Name: "second", Dir: "/b"},
+		{Name: "third", Dir: "/c"},
+	})
+	model.cursor = 3
+
+	pressSource := func() tea.Cmd {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+		model = updated.(Model)
+		return cmd
+	}
+
+	if cmd := pressSource(); cmd == nil {
+		t.Fatal("Expected switching source to load files")
+	}
+	if model.dir != "/b" || model.cursor != 0 {
+		t.Errorf("Expected second source with cursor reset, got dir %s cursor %d", model.dir, model.cursor)
+	}
+	if model.statusMessage != "Source: second (/b)" {
+		t.Errorf("Unexpected status message %q", model.statusMessage)
+	}
+
+	pressSource()
+	pressSource()
+	if model.dir != "/a" {
+		t.Errorf("Expected sources to wrap around to /a, got %s", model.dir)
+	}
+
+	// After navigating away from a source, the cycle restarts at the first source
+	model.dir = "/a/sub"
+	pressSource()
+	if model.dir != "/a" {
+		t.Errorf("Expected first source after navigating away, got %s", model.dir)
+	}
+}
+
+func TestModelSourceKeyWithoutSources(t *testing.T) {
+	model := NewModel("/a", false)
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	model = updated.(Model)
+
+	if cmd != nil || model.dir != "/a" {
+		t.Errorf("Expected no change without sources, got dir %s", model.dir)
+	}
+}
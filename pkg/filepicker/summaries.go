@@ -0,0 +1,34 @@
+package filepicker
+
+import (
+	"strings"
+
+	"github.com/annenpolka/cclog/internal/summary"
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// summaryCache provides the LLM summaries of 'cclog summarize --llm' (nil shows none)
+var summaryCache *summary.Cache
+
+// SetSummaryCache shows the cached summary of the selected session, if it is unchanged since it was
+// summarized, below the file list
+func SetSummaryCache(c *summary.Cache) {
+	summaryCache = c
+}
+
+// selectedSummary returns the cached summary of the session under the cursor on one line of at most
+// width characters, or ""
+func (m Model) selectedSummary(width int) string {
+	if summaryCache == nil || m.cursor < 0 || m.cursor >= len(m.files) {
+		return ""
+	}
+	file := m.files[m.cursor]
+	if file.IsDir {
+		return ""
+	}
+	text, ok := summaryCache.Lookup(file.Path, file.Size, file.ModTime)
+	if !ok {
+		return ""
+	}
+	return types.TruncateTitle(strings.Join(strings.Fields(text), " "), width)
+}
@@ -0,0 +1,36 @@
+package filepicker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/internal/summary"
+)
+
+func TestSelectedSummary(t *testing.T) {
+	modTime := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	cache, err := summary.LoadCache(filepath.Join(t.TempDir(), "summaries.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put("/logs/one.jsonl", 10, modTime, "claude", "Fixed the\nflaky test in the parser.")
+
+	m := Model{files: []FileInfo{
+		{Name: "one.jsonl", Path: "/logs/one.jsonl", Size: 10, ModTime: modTime},
+		{Name: "two.jsonl", Path: "/logs/two.jsonl", Size: 10, ModTime: modTime},
+	}}
+	if got := m.selectedSummary(80); got != "" {
+		t.Errorf("Expected no summary without a cache, got %q", got)
+	}
+
+	SetSummaryCache(cache)
+	defer SetSummaryCache(nil)
+	if got := m.selectedSummary(80); got != "Fixed the flaky test in the parser." {
+		t.Errorf("selectedSummary() = %q", got)
+	}
+	m.cursor = 1
+	if got := m.selectedSummary(80); got != "" {
+		t.Errorf("Expected no summary for an unsummarized session, got %q", got)
+	}
+}
@@ -0,0 +1,56 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StaleTempFileAge is how old temporary files must be before RemoveStaleTempFiles deletes them.
+// Files opened in background editors and browsers are left in place at least this long.
+const StaleTempFileAge = 24 * time.Hour
+
+// tempFilePrefix starts the names of all temporary files, so stale ones can be found again
+const tempFilePrefix = "cclog_"
+
+// tempDir is where editor markdown and browser HTML files are written ("" uses the system temp directory)
+var tempDir string
+
+// SetTempDir sets the directory for temporary markdown files, e.g. when the system temp directory is read-only
+func SetTempDir(dir string) {
+	tempDir = dir
+}
+
+// createTempFile creates a temporary file in the configured temp directory
+func createTempFile(pattern string) (*os.File, error) {
+	return os.CreateTemp(tempDir, pattern)
+}
+
+// RemoveStaleTempFiles deletes the temporary markdown and HTML files of earlier runs that were last
+// modified more than maxAge ago. Files handed to background editors and browsers are not deleted when
+// the editor returns, since it may still be loading them; they are removed here instead.
+func RemoveStaleTempFiles(maxAge time.Duration) {
+	dir := tempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, tempFilePrefix) {
+			continue
+		}
+		if ext := filepath.Ext(name); ext != ".md" && ext != ".html" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
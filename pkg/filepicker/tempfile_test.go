@@ -0,0 +1,74 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRemoveStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	SetTempDir(dir)
+	defer SetTempDir("")
+
+	old := time.Now().Add(-2 * StaleTempFileAge)
+	files := map[string]bool{ // Name and whether it is removed
+		"cclog_123.md":         true,
+		"cclog_preview_456.md": true,
+		"cclog_789.html":       true,
+		"cclog_notes.txt":      false,
+		"other_123.md":         false,
+	}
+	for name := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := filepath.Join(dir, "cclog_recent.md")
+	if err := os.WriteFile(recent, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files["cclog_recent.md"] = false
+
+	RemoveStaleTempFiles(StaleTempFileAge)
+
+	for name, removed := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if removed && err == nil {
+			t.Errorf("Expected %s to be removed", name)
+		}
+		if !removed && err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		}
+	}
+}
+
+func TestBackgroundEditorKeepsTempFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the editor")
+	}
+	dir := t.TempDir()
+	editor := filepath.Join(dir, "code")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEditor(editor); err != nil {
+		t.Fatal(err)
+	}
+	defer SetEditor("")
+
+	markdown := filepath.Join(dir, "cclog_session.md")
+	if err := os.WriteFile(markdown, []byte("# Conversation Log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	openMarkdownInEditor(markdown, true)()
+	if _, err := os.Stat(markdown); err != nil {
+		t.Errorf("Expected the file to be left for the background editor: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package filepicker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme defines the colors used by the TUI.
+// Values are lipgloss colors: ANSI 256 color numbers (e.g. "33") or hex codes (e.g. "#0087ff").
+type Theme struct {
+	SelectedForeground      string `json:"selectedForeground"`
+	SelectedBackground      string `json:"selectedBackground"`
+	NormalFile              string `json:"normalFile"`
+	Directory               string `json:"directory"`
+	JSONLFile               string `json:"jsonlFile"`
+	UnparsableFile          string `json:"unparsableFile"`
+	ErrorBadge              string `json:"errorBadge"`
+	Cursor                  string `json:"cursor"`
+	Header                  string `json:"header"`
+	Mode                    string `json:"mode"`
+	ScrollIndicator         string `json:"scrollIndicator"`
+	Status                  string `json:"status"`
+	StatusError             string `json:"statusError"`
+	HelpKey                 string `json:"helpKey"`
+	HelpDesc                string `json:"helpDesc"`
+	HelpSeparator           string `json:"helpSeparator"`
+	SearchMatchForeground   string `json:"searchMatchForeground"`
+	SearchMatchBackground   string `json:"searchMatchBackground"`
+	SearchCurrentForeground string `json:"searchCurrentForeground"`
+	SearchCurrentBackground string `json:"searchCurrentBackground"`
+	SearchPrompt            string `json:"searchPrompt"`
+	PreviewBorder           string `json:"previewBorder"`
+	EmptyPreviewBorder      string `json:"emptyPreviewBorder"`
+}
+
+// DarkTheme returns the color preset for dark terminal backgrounds
+func DarkTheme() Theme {
+	return Theme{
+		SelectedForeground:      "15",  // Bright white text
+		SelectedBackground:      "33",  // Bright blue background
+		NormalFile:              "250", // Light gray
+		Directory:               "39",  // Bright blue
+		JSONLFile:               "148", // Green
+		UnparsableFile:          "240", // Greyed out
+		ErrorBadge:              "196", // Red
+		Cursor:                  "196", // Bright red
+		Header:                  "39",  // Blue
+		Mode:                    "226", // Yellow
+		ScrollIndicator:         "240", // Subtle gray
+		Status:                  "42",  // Green
+		StatusError:             "196", // Red
+		HelpKey:                 "241",
+		HelpDesc:                "239",
+		HelpSeparator:           "237",
+		SearchMatchForeground:   "0",
+		SearchMatchBackground:   "226", // Yellow
+		SearchCurrentForeground: "0",
+		SearchCurrentBackground: "208", // Orange
+		SearchPrompt:            "226", // Yellow
+		PreviewBorder:           "#444444",
+		EmptyPreviewBorder:      "240",
+	}
+}
+
+// LightTheme returns the color preset for light terminal backgrounds
+func LightTheme() Theme {
+	return Theme{
+		SelectedForeground:      "15",  // White text
+		SelectedBackground:      "25",  // Dark blue background
+		NormalFile:              "235", // Dark gray
+		Directory:               "25",  // Dark blue
+		JSONLFile:               "28",  // Dark green
+		UnparsableFile:          "245", // Greyed out
+		ErrorBadge:              "160", // Dark red
+		Cursor:                  "160", // Dark red
+		Header:                  "25",  // Dark blue
+		Mode:                    "130", // Dark orange, yellow is unreadable on white
+		ScrollIndicator:         "245", // Subtle gray
+		Status:                  "28",  // Dark green
+		StatusError:             "160", // Dark red
+		HelpKey:                 "241",
+		HelpDesc:                "239",
+		HelpSeparator:           "237",
+		SearchMatchForeground:   "0",
+		SearchMatchBackground:   "226", // Yellow
+		SearchCurrentForeground: "0",
+		SearchCurrentBackground: "214", // Light orange
+		SearchPrompt:            "130", // Dark orange
+		PreviewBorder:           "#CCCCCC",
+		EmptyPreviewBorder:      "250",
+	}
+}
+
+// Styles derived from the active theme
+var (
+	// Help text styles
+	helpKeyStyle       lipgloss.Style
+	helpDescStyle      lipgloss.Style
+	helpSeparatorStyle lipgloss.Style
+
+	// File selection and file type specific styles
+	selectedFileStyle    lipgloss.Style
+	normalFileStyle      lipgloss.Style
+	directoryStyle       lipgloss.Style
+	jsonlFileStyle       lipgloss.Style
+	unparsableFileStyle  lipgloss.Style
+	parseErrorBadgeStyle lipgloss.Style
+
+	// UI element styles
+	cursorStyle          lipgloss.Style
+	headerStyle          lipgloss.Style
+	modeStyle            lipgloss.Style
+	scrollIndicatorStyle lipgloss.Style
+
+	// Status message styles
+	statusStyle      lipgloss.Style
+	statusErrorStyle lipgloss.Style
+
+	// Preview search styles
+	searchMatchStyle        lipgloss.Style
+	searchCurrentMatchStyle lipgloss.Style
+	searchPromptStyle       lipgloss.Style
+
+	// Preview border colors
+	previewBorderColor      lipgloss.AdaptiveColor
+	emptyPreviewBorderColor lipgloss.AdaptiveColor
+)
+
+func init() {
+	applyTheme(LightTheme(), DarkTheme())
+}
+
+// ApplyTheme sets the TUI colors from a named preset with optional per-color overrides.
+// Presets are "dark", "light" and "auto" (or empty), which follows the terminal background.
+// Override keys are the JSON names of Theme fields, e.g. {"directory": "#005f87"}.
+// It must be called before NewModel for the preview border to pick up the theme.
+func ApplyTheme(name string, overrides map[string]string) error {
+	var light, dark Theme
+	switch name {
+	case "", "auto":
+		light, dark = LightTheme(), DarkTheme()
+	case "dark":
+		light, dark = DarkTheme(), DarkTheme()
+	case "light":
+		light, dark = LightTheme(), LightTheme()
+	default:
+		return fmt.Errorf("unknown theme %q (available: auto, dark, light)", name)
+	}
+
+	var err error
+	if light, err = light.withOverrides(overrides); err != nil {
+		return err
+	}
+	if dark, err = dark.withOverrides(overrides); err != nil {
+		return err
+	}
+
+	applyTheme(light, dark)
+	return nil
+}
+
+// withOverrides returns a copy of the theme with the given colors replaced
+func (t Theme) withOverrides(overrides map[string]string) (Theme, error) {
+	if len(overrides) == 0 {
+		return t, nil
+	}
+
+	// Round-trip through JSON so overrides use the same keys as the config file
+	data, err := json.Marshal(t)
+	if err != nil {
+		return Theme{}, err
+	}
+	var colors map[string]string
+	if err := json.Unmarshal(data, &colors); err != nil {
+		return Theme{}, err
+	}
+
+	for key, value := range overrides {
+		if _, ok := colors[key]; !ok {
+			return Theme{}, fmt.Errorf("unknown theme color %q", key)
+		}
+		colors[key] = value
+	}
+
+	data, err = json.Marshal(colors)
+	if err != nil {
+		return Theme{}, err
+	}
+	var result Theme
+	if err := json.Unmarshal(data, &result); err != nil {
+		return Theme{}, err
+	}
+	return result, nil
+}
+
+// applyTheme rebuilds all styles, choosing between the light and dark theme by terminal background
+func applyTheme(light, dark Theme) {
+	color := func(lightColor, darkColor string) lipgloss.AdaptiveColor {
+		return lipgloss.AdaptiveColor{Light: lightColor, Dark: darkColor}
+	}
+
+	helpKeyStyle = lipgloss.NewStyle().Foreground(color(light.HelpKey, dark.HelpKey))
+	helpDescStyle = lipgloss.NewStyle().Foreground(color(light.HelpDesc, dark.HelpDesc))
+	helpSeparatorStyle = lipgloss.NewStyle().Foreground(color(light.HelpSeparator, dark.HelpSeparator))
+
+	selectedFileStyle = lipgloss.NewStyle().
+		Foreground(color(light.SelectedForeground, dark.SelectedForeground)).
+		Background(color(light.SelectedBackground, dark.SelectedBackground)).
+		Bold(true).
+		Padding(0, 1) // Horizontal padding for better visibility
+	normalFileStyle = lipgloss.NewStyle().Foreground(color(light.NormalFile, dark.NormalFile))
+	directoryStyle = lipgloss.NewStyle().Foreground(color(light.Directory, dark.Directory)).Bold(true)
+	jsonlFileStyle = lipgloss.NewStyle().Foreground(color(light.JSONLFile, dark.JSONLFile))
+	unparsableFileStyle = lipgloss.NewStyle().Foreground(color(light.UnparsableFile, dark.UnparsableFile))
+	parseErrorBadgeStyle = lipgloss.NewStyle().Foreground(color(light.ErrorBadge, dark.ErrorBadge))
+
+	cursorStyle = lipgloss.NewStyle().Foreground(color(light.Cursor, dark.Cursor)).Bold(true)
+	headerStyle = lipgloss.NewStyle().Foreground(color(light.Header, dark.Header)).Bold(true)
+	modeStyle = lipgloss.NewStyle().Foreground(color(light.Mode, dark.Mode)).Bold(true)
+	scrollIndicatorStyle = lipgloss.NewStyle().Foreground(color(light.ScrollIndicator, dark.ScrollIndicator))
+
+	statusStyle = lipgloss.NewStyle().Foreground(color(light.Status, dark.Status))
+	statusErrorStyle = lipgloss.NewStyle().Foreground(color(light.StatusError, dark.StatusError))
+
+	searchMatchStyle = lipgloss.NewStyle().
+		Foreground(color(light.SearchMatchForeground, dark.SearchMatchForeground)).
+		Background(color(light.SearchMatchBackground, dark.SearchMatchBackground))
+	searchCurrentMatchStyle = lipgloss.NewStyle().
+		Foreground(color(light.SearchCurrentForeground, dark.SearchCurrentForeground)).
+		Background(color(light.SearchCurrentBackground, dark.SearchCurrentBackground)).
+		Bold(true)
+	searchPromptStyle = lipgloss.NewStyle().Foreground(color(light.SearchPrompt, dark.SearchPrompt))
+
+	previewBorderColor = color(light.PreviewBorder, dark.PreviewBorder)
+	emptyPreviewBorderColor = color(light.EmptyPreviewBorder, dark.EmptyPreviewBorder)
+}
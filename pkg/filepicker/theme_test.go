@@ -0,0 +1,55 @@
+package filepicker
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyTheme(t *testing.T) {
+	t.Cleanup(func() {
+		if err := ApplyTheme("", nil); err != nil {
+			t.Fatalf("Failed to restore default theme: %v", err)
+		}
+	})
+
+	if err := ApplyTheme("light", map[string]string{"directory": "#005f87"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := lipgloss.AdaptiveColor{Light: "#005f87", Dark: "#005f87"}
+	if got := directoryStyle.GetForeground(); got != want {
+		t.Errorf("directory color = %v, expected %v", got, want)
+	}
+	light := LightTheme()
+	if got := modeStyle.GetForeground(); got != (lipgloss.AdaptiveColor{Light: light.Mode, Dark: light.Mode}) {
+		t.Errorf("mode color = %v, expected light preset %s", got, light.Mode)
+	}
+	if got := NewPreviewModel().markdownBubble.BorderColor; got != (lipgloss.AdaptiveColor{Light: light.PreviewBorder, Dark: light.PreviewBorder}) {
+		t.Errorf("preview border = %v, expected light preset %s", got, light.PreviewBorder)
+	}
+}
+
+func TestApplyThemeAutoFollowsBackground(t *testing.T) {
+	t.Cleanup(func() { ApplyTheme("", nil) })
+
+	if err := ApplyTheme("auto", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := lipgloss.AdaptiveColor{Light: LightTheme().Directory, Dark: DarkTheme().Directory}
+	if got := directoryStyle.GetForeground(); got != want {
+		t.Errorf("directory color = %v, expected %v", got, want)
+	}
+}
+
+func TestApplyThemeErrors(t *testing.T) {
+	t.Cleanup(func() { ApplyTheme("", nil) })
+
+	if err := ApplyTheme("solarized", nil); err == nil {
+		t.Error("Expected error for unknown preset")
+	}
+	if err := ApplyTheme("dark", map[string]string{"nonexistent": "1"}); err == nil {
+		t.Error("Expected error for unknown color name")
+	}
+}
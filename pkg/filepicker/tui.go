@@ -1,77 +1,82 @@
 package filepicker
 
 import (
-	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/annenpolka/cclog/internal/formatter"
+	"github.com/annenpolka/cclog/internal/outfile"
 	"github.com/annenpolka/cclog/internal/parser"
 	"github.com/annenpolka/cclog/pkg/types"
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/term"
 )
 
-// Define styles for help text and UI elements
-var (
-	helpKeyStyle       = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "241", Dark: "241"})
-	helpDescStyle      = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "239", Dark: "239"})
-	helpSeparatorStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "237", Dark: "237"})
-
-	// File selection and highlighting styles
-	selectedFileStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("15")). // Bright white text
-				Background(lipgloss.Color("33")). // Bright blue background
-				Bold(true).
-				Padding(0, 1) // Horizontal padding for better visibility
-
-	// File type specific styles
-	normalFileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "235", Dark: "250"}) // Adaptive gray
-
-	directoryStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")). // Bright blue for directories
-			Bold(true)
-
-	jsonlFileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("148")) // Green for JSONL files
-
-	// UI element styles
-	cursorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")). // Bright red cursor
-			Bold(true)
-
-	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")). // Blue header text
-			Bold(true)
-
-	modeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("226")). // Yellow mode indicators
-			Bold(true)
-
-	scrollIndicatorStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("240")) // Subtle gray for scroll hints
+// previewLayout selects where the preview is drawn relative to the file list
+type previewLayout int
+
+const (
+	previewLayoutAuto       previewLayout = iota // Side by side on wide terminals, stacked otherwise
+	previewLayoutStacked                         // Preview below the file list
+	previewLayoutSideBySide                      // Preview to the right of the file list
+)
+
+// splitRatioStep is how much +/- grow or shrink the preview pane
+const splitRatioStep = 0.1
+
+const (
+	sideBySideMinWidth  = 140 // Terminals wider than this show the preview beside the list by default
+	sideBySideListRatio = 0.4 // Share of the terminal width used by the list in side-by-side layout
 )
 
 type Model struct {
-	dir              string
-	files            []FileInfo
-	cursor           int
-	selected         string
-	recursive        bool
-	maxDisplayFiles  int
-	scrollOffset     int
-	terminalWidth    int
-	terminalHeight   int
-	useCompactLayout bool
-	contentAlignment string
-	maxTitleChars    int
-	preview          *PreviewModel
-	enableFiltering  bool
+	dir               string
+	files             []FileInfo
+	cursor            int
+	selected          string
+	recursive         bool
+	maxDisplayFiles   int
+	scrollOffset      int
+	terminalWidth     int
+	terminalHeight    int
+	useCompactLayout  bool
+	contentAlignment  string
+	maxTitleChars     int
+	preview           *PreviewModel
+	enableFiltering   bool
+	statusMessage     string
+	statusIsError     bool
+	expandMessages    bool
+	layout            previewLayout
+	recentSessions    []string      // Sessions opened through cclog, most recent first
+	sources           []Source      // Root directories cycled with the source key
+	lastClickIndex    int           // File index of the last left click, for double-click detection
+	lastClickTime     time.Time     // Time of the last left click
+	showRecent        bool          // Whether the list shows recent sessions instead of the directory
+	plain             bool          // Render without colors or Unicode symbols
+	keys              KeyMap        // Key bindings, also shown in the help bar
+	showHelp          bool          // Whether the full-screen help overlay is shown
+	homeDir           string        // Directory the home key jumps to, shown as the breadcrumb root
+	dirHistory        []string      // Previously listed directories, most recent last
+	showOtherFiles    bool          // Whether the directory listing includes files other than .jsonl
+	helpOffset        int           // Scroll offset of the help overlay
+	scanning          bool          // Whether a recursive scan is still adding files to the list
+	scanned           int           // Number of files the running scan has examined
+	spinnerFrame      int           // Current frame of the scan spinner
+	refreshInterval   time.Duration // Time between periodic re-scans of the listing (0 disables them)
+	previewCache      *previewCache // Previews generated so far, shared by the copies of the model
+	fullPreviewPath   string        // Session whose preview shows all messages instead of the first previewMessageLimit
+	rawPreview        bool          // Whether the preview shows the pretty-printed JSONL lines instead of markdown
+	previewFilter     previewFilter // Message categories shown in the preview on top of the global filter
+	filterMenu        bool          // Whether the preview filter menu takes the keys
+	previewPath       string        // File whose preview is shown or being generated
+	previewGeneration int           // Incremented on each preview request so that stale background results are dropped
 }
 
 func NewModel(dir string, recursive bool) Model {
@@ -88,21 +93,55 @@ func NewModel(dir string, recursive bool) Model {
 		contentAlignment: "left", // Default alignment
 		maxTitleChars:    40,     // Default title character limit
 		preview:          NewPreviewModel(),
-		enableFiltering:  true, // Default to filtering enabled
+		previewCache:     newPreviewCache(),
+		keys:             DefaultKeyMap(),
+		enableFiltering:  true,      // Default to filtering enabled
+		scanning:         recursive, // The spinner shows until the first batch of the initial scan arrives
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		loadFiles(m.dir, m.recursive),
 		GetInitialWindowSize(),
-	)
+	}
+	if m.scanning {
+		cmds = append(cmds, spinnerTick())
+	}
+	if m.refreshInterval > 0 {
+		cmds = append(cmds, refreshTick(m.refreshInterval))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	// Mouse events are routed by position rather than passed to every component
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		if m.showHelp {
+			return m, nil
+		}
+		return m.handleMouse(mouseMsg)
+	}
+
+	// The help overlay takes all keys until it is closed
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showHelp {
+		return m.handleHelpKey(keyMsg)
+	}
+
+	// The preview filter menu takes all keys until it is closed
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.filterMenu {
+		return m.handleFilterMenuKey(keyMsg)
+	}
+
+	// Route keys to the preview while a search query or message number is being typed
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.preview.IsCapturingInput() && keyMsg.String() != "ctrl+c" {
+		m.preview.handleInputKey(keyMsg)
+		return m, nil
+	}
+
 	// Update preview
 	m.preview, cmd = m.preview.Update(msg)
 	if cmd != nil {
@@ -118,10 +157,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updatePreviewSize()
 		return m, tea.Batch(cmds...)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		// Clear status message from the previous action
+		m.statusMessage = ""
+		m.statusIsError = false
+		switch m.keys.Action(msg.String()) {
+		case ActionQuit:
 			return m, tea.Quit
-		case "p":
+		case ActionPreview:
 			// Toggle preview
 			m.preview.SetVisible(!m.preview.IsVisible())
 			// Update preview content if visible
@@ -131,7 +173,140 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, tea.Batch(cmds...)
-		case "s":
+		case ActionGrowPreview:
+			// Grow the preview pane
+			m.preview.AdjustSplitRatio(splitRatioStep)
+			m.updatePreviewSize()
+			return m, tea.Batch(cmds...)
+		case ActionShrinkPreview:
+			// Shrink the preview pane
+			m.preview.AdjustSplitRatio(-splitRatioStep)
+			m.updatePreviewSize()
+			return m, tea.Batch(cmds...)
+		case ActionSource:
+			// Switch the list to the next source directory
+			return m, m.nextSource()
+		case ActionHelp:
+			// Show the full-screen help overlay
+			m.showHelp = true
+			return m, tea.Batch(cmds...)
+		case ActionBack:
+			// Return to the previously listed directory
+			return m, m.goBack()
+		case ActionHome:
+			// Jump to the Claude projects directory
+			return m, m.goHome()
+		case ActionOtherFiles:
+			// Show or hide files other than .jsonl in the directory listing
+			m.showOtherFiles = !m.showOtherFiles
+			if m.showRecent {
+				return m, tea.Batch(cmds...)
+			}
+			return m, loadFiles(m.dir, m.recursive)
+		case ActionRefresh:
+			// Re-read the listing and the previewed session, e.g. while a session is being written
+			if m.showRecent {
+				return m, loadRecentFiles(m.recentSessions)
+			}
+			return m, refreshFiles(m.dir, m.recursive)
+		case ActionRecent:
+			// Toggle between the directory listing and recently viewed sessions
+			m.showRecent = !m.showRecent
+			m.cursor = 0
+			m.scrollOffset = 0
+			if m.showRecent {
+				return m, loadRecentFiles(m.recentSessions)
+			}
+			return m, loadFiles(m.dir, m.recursive)
+		case ActionLayout:
+			// Toggle between stacked and side-by-side preview layout
+			if m.isSideBySide() {
+				m.layout = previewLayoutStacked
+			} else {
+				m.layout = previewLayoutSideBySide
+			}
+			m.updatePreviewSize()
+			if m.preview.IsVisible() {
+				if cmd := m.updatePreviewContent(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionSearch:
+			// Start searching inside the preview
+			if m.preview.IsVisible() && m.preview.GetContent() != "" {
+				m.preview.StartSearch()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionNextMessage:
+			// Jump to next message in the preview
+			if m.preview.IsVisible() {
+				m.preview.NextMessage()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionPrevMessage:
+			// Jump to previous message in the preview
+			if m.preview.IsVisible() {
+				m.preview.PrevMessage()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionGotoMessage:
+			// Start goto-message prompt
+			if m.preview.IsVisible() && m.preview.GetContent() != "" {
+				m.preview.StartGoto()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionNextMatch:
+			// Jump to next search match
+			if m.preview.HasSearchQuery() {
+				m.preview.NextMatch()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionPrevMatch:
+			// Jump to previous search match
+			if m.preview.HasSearchQuery() {
+				m.preview.PrevMatch()
+			}
+			return m, tea.Batch(cmds...)
+		case ActionClearSearch:
+			// Clear preview search
+			m.preview.ClearSearch()
+			return m, tea.Batch(cmds...)
+		case ActionExpand:
+			// Toggle expansion of long messages in the preview
+			m.expandMessages = !m.expandMessages
+			if m.preview.IsVisible() {
+				if cmd := m.updatePreviewContent(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionFullPreview:
+			// Show all messages of the selected session instead of the first previewMessageLimit
+			if m.preview.IsVisible() && len(m.files) > 0 && !m.files[m.cursor].IsDir {
+				m.fullPreviewPath = m.files[m.cursor].Path
+				if cmd := m.updatePreviewContent(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionRawPreview:
+			// Toggle between rendered markdown and the raw JSONL lines
+			m.rawPreview = !m.rawPreview
+			m.preview.SetRaw(m.rawPreview)
+			if m.preview.IsVisible() {
+				if cmd := m.updatePreviewContent(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionPreviewFilter:
+			// Open the menu that shows and hides message categories in the preview
+			if m.preview.IsVisible() {
+				m.filterMenu = true
+			}
+			return m, tea.Batch(cmds...)
+		case ActionFilter:
 			// Toggle filtering
 			m.enableFiltering = !m.enableFiltering
 			// Update preview content with new filtering state
@@ -141,7 +316,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, tea.Batch(cmds...)
-		case "c":
+		case ActionCopySessionID:
 			// Copy sessionId to clipboard
 			if len(m.files) > 0 {
 				selectedItem := m.files[m.cursor]
@@ -150,25 +325,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, tea.Batch(cmds...)
-		case "r":
+		case ActionCopyResumeCommand:
+			// Copy claude resume command to clipboard
+			if len(m.files) > 0 {
+				selectedItem := m.files[m.cursor]
+				if !selectedItem.IsDir {
+					return m, copyResumeCommand(selectedItem.Path)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionCopyPath:
+			// Copy absolute file path to clipboard
+			if len(m.files) > 0 {
+				selectedItem := m.files[m.cursor]
+				if !selectedItem.IsDir {
+					return m, copyFilePath(selectedItem.Path)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionCopyMarkdown:
+			// Copy converted markdown to clipboard with current filtering state
+			if len(m.files) > 0 {
+				selectedItem := m.files[m.cursor]
+				if !selectedItem.IsDir {
+					return m, copyMarkdown(selectedItem.Path, m.enableFiltering)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case ActionResume:
 			// Resume with normal command (with CWD directory change)
 			if len(m.files) > 0 {
 				selectedItem := m.files[m.cursor]
 				if !selectedItem.IsDir {
+					m.recordRecentSession(selectedItem.Path)
 					return m, executeResumeCommandWithCWDChange(selectedItem.Path, false)
 				}
 			}
 			return m, tea.Batch(cmds...)
-		case "R":
+		case ActionResumeDangerous:
 			// Resume with dangerous permissions skip (with CWD directory change)
 			if len(m.files) > 0 {
 				selectedItem := m.files[m.cursor]
 				if !selectedItem.IsDir {
+					m.recordRecentSession(selectedItem.Path)
 					return m, executeResumeCommandWithCWDChange(selectedItem.Path, true)
 				}
 			}
 			return m, tea.Batch(cmds...)
-		case "up", "k":
+		case ActionUp:
 			if m.cursor > 0 {
 				m.cursor--
 				// Ensure cursor visibility after movement
@@ -180,7 +384,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-		case "down", "j":
+		case ActionDown:
 			if m.cursor < len(m.files)-1 {
 				m.cursor++
 				// Ensure cursor visibility after movement
@@ -192,40 +396,97 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-		case "enter":
+		case ActionOpen:
 			if len(m.files) > 0 {
-				selectedItem := m.files[m.cursor]
-				if selectedItem.IsDir {
-					// Navigate into directory
-					m.dir = selectedItem.Path
-					m.cursor = 0
-					m.scrollOffset = 0
-					return m, loadFiles(m.dir, m.recursive)
-				} else {
-					// Convert to markdown and open in editor with current filtering state
-					return m, convertAndOpenInEditor(selectedItem.Path, m.enableFiltering)
-				}
+				return m, m.openSelected()
 			}
 		}
 	case filesLoadedMsg:
-		m.files = msg.files
+		// Drop batches of a scan whose directory is no longer listed
+		if msg.scan != nil && (msg.scan.dir != m.dir || m.showRecent) {
+			msg.scan.cancel()
+			break
+		}
+
+		// Batches of a running scan keep the selected file under the cursor as files are added
+		previous := m.selectedPath()
+		wasScanning := m.scanning
+		m.files = m.visibleFiles(msg.files)
+		if wasScanning {
+			m.selectPath(previous)
+		}
 		// Reset cursor and scroll when loading new files
 		if m.cursor >= len(m.files) {
 			m.cursor = 0
 		}
 		m.scrollOffset = 0
-		// Initialize preview size and content if visible
-		if m.preview.IsVisible() {
+		// A refresh keeps the cursor, which must stay on screen
+		m.ensureCursorVisible()
+
+		m.scanning = msg.partial
+		m.scanned = msg.scanned
+		if msg.limited {
+			m.statusMessage = "Listing stopped after " + strconv.Itoa(msg.scanned) + " files (max files limit)"
+			m.statusIsError = false
+		}
+		if msg.partial {
+			cmds = append(cmds, msg.scan.next())
+			if !wasScanning {
+				cmds = append(cmds, spinnerTick())
+			}
+		}
+
+		// Initialize preview size and content if visible, re-rendering only when the selection changed
+		if m.preview.IsVisible() && (!wasScanning || m.selectedPath() != previous) {
 			m.updatePreviewSize()
 			if cmd := m.updatePreviewContent(); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
 		}
+	case filesRefreshedMsg:
+		// Ignore re-scans of a directory that is no longer listed
+		if msg.dir != m.dir || m.showRecent || m.scanning {
+			break
+		}
+		if m.applyRefresh(msg.files) && m.preview.IsVisible() {
+			if cmd := m.updatePreviewContent(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case previewLoadedMsg:
+		if cmd := m.showLoadedPreview(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	case refreshTickMsg:
+		// Skip this round while a scan is still running or recent sessions are shown
+		if !m.scanning && !m.showRecent {
+			cmds = append(cmds, refreshFiles(m.dir, m.recursive))
+		}
+		cmds = append(cmds, refreshTick(m.refreshInterval))
+	case spinnerTickMsg:
+		if m.scanning {
+			m.spinnerFrame++
+			cmds = append(cmds, spinnerTick())
+		}
 	case copySessionIDMsg:
-		// Handle clipboard copy result
-		// For now, we silently handle success/failure
-		// In a more advanced implementation, we could show a status message
-		_ = msg
+		m.setClipboardStatus("sessionId", msg.method, msg.error)
+	case copyMarkdownMsg:
+		m.setClipboardStatus("markdown", msg.method, msg.error)
+	case copyFilePathMsg:
+		m.setClipboardStatus("file path", msg.method, msg.error)
+	case copyResumeCommandMsg:
+		m.setClipboardStatus("resume command", msg.method, msg.error)
+	case exportFailedMsg:
+		m.statusMessage = "Export failed: " + msg.err.Error()
+		m.statusIsError = true
+	case openInBrowserMsg:
+		if msg.err != nil {
+			m.statusMessage = "Open failed: " + msg.err.Error()
+			m.statusIsError = true
+		} else {
+			m.statusMessage = "Opened in browser: " + msg.path
+			m.statusIsError = false
+		}
 	case resumeMsg:
 		// Handle resume command execution result
 		// For now, we silently handle success/failure
@@ -236,41 +497,255 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if m.showHelp {
+		if m.plain {
+			return plainText(m.helpView())
+		}
+		return m.helpView()
+	}
+
 	var s strings.Builder
 
 	// Show current directory with mode indicator using colorful styles
 	modeStr := ""
-	if m.recursive {
+	if m.showRecent {
+		modeStr = " " + modeStyle.Render("[RECENT]")
+	} else if m.recursive {
 		modeStr = " " + modeStyle.Render("[RECURSIVE]")
 	}
+	if !m.showRecent && !m.recursive {
+		if m.showOtherFiles {
+			modeStr += " " + modeStyle.Render("[ALL FILES]")
+		} else {
+			modeStr += " " + modeStyle.Render("[JSONL ONLY]")
+		}
+	}
 	if m.enableFiltering {
 		modeStr += " " + modeStyle.Render("[FILTERED]")
 	} else {
 		modeStr += " " + modeStyle.Render("[UNFILTERED]")
 	}
 
-	// Truncate directory path for narrow terminals
-	dirPath := m.dir
-	if m.terminalWidth > 0 && len(dirPath) > m.terminalWidth-20 { // Reserve space for emoji, modes, and spaces
-		availableWidth := m.terminalWidth - 20 // "📁 " + modes + "..."
-		if availableWidth > 0 {
-			dirPath = types.TruncateTitle(dirPath, availableWidth)
-		}
+	// Fit the directory breadcrumb to narrow terminals
+	dirPath := m.breadcrumb(m.terminalWidth - 20) // Reserve space for "📁 " and modes
+	if m.showRecent {
+		dirPath = "Recently viewed sessions"
+	}
+
+	if status := m.scanStatus(); status != "" {
+		modeStr += " " + statusStyle.Render(status)
 	}
 
 	s.WriteString("📁 " + headerStyle.Render(dirPath) + modeStr + "\n\n")
 
-	// Calculate available space for file list using dynamic layout
-	listHeight := m.getListHeight()
+	sideBySide := m.preview.IsVisible() && m.isSideBySide()
+	listWidth := m.terminalWidth
+	if sideBySide {
+		listWidth, _ = m.sideBySideWidths()
+	}
+	list := m.renderFileList(listWidth)
 
-	// Adjust maxDisplayFiles based on available space
-	originalMaxDisplay := m.maxDisplayFiles
-	if listHeight > 0 {
-		m.maxDisplayFiles = listHeight
+	// Show preview if visible, either below or beside the file list
+	if m.preview.IsVisible() {
+		previewView := m.preview.View()
+		if m.filterMenu {
+			previewView += "\n" + m.filterMenuStatus()
+		} else if status := m.preview.StatusLine(); status != "" {
+			previewView += "\n" + status
+		}
+
+		if sideBySide {
+			listColumn := lipgloss.NewStyle().MaxWidth(listWidth).Render(strings.TrimSuffix(list, "\n"))
+			listColumn = lipgloss.NewStyle().Width(listWidth).Render(listColumn)
+			s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listColumn, " ", previewView))
+		} else {
+			s.WriteString(list)
+			s.WriteString("\n" + strings.Repeat("─", m.terminalWidth) + "\n")
+			s.WriteString(previewView)
+		}
+	} else {
+		s.WriteString(list)
 	}
 
-	// Ensure cursor is visible with updated display count
-	m.ensureCursorVisible()
+	// Show help text based on layout
+	if !m.useCompactLayout {
+		s.WriteString("\n")
+		if m.preview.IsVisible() {
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionOpen), desc: "open"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionResumeDangerous), desc: "resume (dangerous)"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionScrollDown, ActionScrollUp), desc: "scroll"},
+				{keys: m.keys.helpKeys(ActionTop, ActionBottom), desc: "top/bot"},
+				{keys: m.keys.helpKeys(ActionSearch), desc: "search"},
+				{keys: m.keys.helpKeys(ActionPrevMessage, ActionNextMessage), desc: "prev/next msg"},
+				{keys: m.keys.helpKeys(ActionExpand), desc: "expand"},
+				{keys: m.keys.helpKeys(ActionLayout), desc: "layout"},
+				{keys: m.keys.helpKeys(ActionGrowPreview, ActionShrinkPreview), desc: "resize"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		} else {
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionOpen), desc: "open"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionResumeDangerous), desc: "resume (dangerous)"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		}
+	} else if m.terminalWidth < 40 {
+		// Very narrow: minimal help
+		if m.preview.IsVisible() {
+			s.WriteString("\n")
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionScrollDown, ActionScrollUp), desc: "scroll"},
+				{keys: m.keys.helpKeys(ActionTop, ActionBottom), desc: "top/bot"},
+				{keys: m.keys.helpKeys(ActionSearch), desc: "search"},
+				{keys: m.keys.helpKeys(ActionPrevMessage, ActionNextMessage), desc: "prev/next msg"},
+				{keys: m.keys.helpKeys(ActionExpand), desc: "expand"},
+				{keys: m.keys.helpKeys(ActionLayout), desc: "layout"},
+				{keys: m.keys.helpKeys(ActionGrowPreview, ActionShrinkPreview), desc: "resize"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume, ActionResumeDangerous), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		} else {
+			s.WriteString("\n")
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionOpen), desc: "open"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume, ActionResumeDangerous), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		}
+	} else {
+		// Compact: abbreviated help
+		if m.preview.IsVisible() {
+			s.WriteString("\n")
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionOpen), desc: "open"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionResumeDangerous), desc: "resume (dangerous)"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionScrollDown, ActionScrollUp), desc: "scroll"},
+				{keys: m.keys.helpKeys(ActionTop, ActionBottom), desc: "top/bot"},
+				{keys: m.keys.helpKeys(ActionSearch), desc: "search"},
+				{keys: m.keys.helpKeys(ActionPrevMessage, ActionNextMessage), desc: "prev/next msg"},
+				{keys: m.keys.helpKeys(ActionExpand), desc: "expand"},
+				{keys: m.keys.helpKeys(ActionLayout), desc: "layout"},
+				{keys: m.keys.helpKeys(ActionGrowPreview, ActionShrinkPreview), desc: "resize"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		} else {
+			s.WriteString("\n")
+			s.WriteString(renderHelp([]helpItem{
+				{keys: m.keys.helpKeys(ActionUp, ActionDown), desc: "move"},
+				{keys: m.keys.helpKeys(ActionOpen), desc: "open"},
+				{keys: m.keys.helpKeys(ActionPreview), desc: "preview"},
+				{keys: m.keys.helpKeys(ActionFilter), desc: "filter"},
+				{keys: m.keys.helpKeys(ActionCopySessionID), desc: "copy sessionId"},
+				{keys: m.keys.helpKeys(ActionCopyMarkdown), desc: "copy markdown"},
+				{keys: m.keys.helpKeys(ActionCopyPath), desc: "copy path"},
+				{keys: m.keys.helpKeys(ActionCopyResumeCommand), desc: "copy resume cmd"},
+				{keys: m.keys.helpKeys(ActionResume), desc: "resume"},
+				{keys: m.keys.helpKeys(ActionResumeDangerous), desc: "resume (dangerous)"},
+				{keys: m.keys.helpKeys(ActionRecent), desc: "recent"},
+				{keys: m.keys.helpKeys(ActionSource), desc: "source"},
+				{keys: m.keys.helpKeys(ActionHelp), desc: "help"},
+				{keys: m.keys.helpKeys(ActionQuit), desc: "quit"},
+			}))
+		}
+	}
+
+	if m.plain {
+		return plainText(s.String())
+	}
+
+	return s.String()
+}
+
+// openSelected navigates into the selected directory or opens the selected file in an editor or browser
+func (m *Model) openSelected() tea.Cmd {
+	selectedItem := m.files[m.cursor]
+	if selectedItem.IsDir {
+		// Navigate into directory
+		return m.changeDir(selectedItem.Path)
+	}
+
+	// Open corrupted sessions as raw JSONL so they can be inspected
+	if selectedItem.ParseError != "" {
+		return openInEditor(selectedItem.Path)
+	}
+
+	// Convert to markdown and open in editor (or browser) with current filtering state
+	m.recordRecentSession(selectedItem.Path)
+	if openWith == OpenWithBrowser {
+		return convertAndOpenInBrowser(selectedItem.Path, m.enableFiltering)
+	}
+	return convertAndOpenInEditor(selectedItem.Path, m.enableFiltering)
+}
+
+// renderFileList renders the visible part of the file list and the last status message.
+// width is the number of columns available to the list.
+func (m Model) renderFileList(width int) string {
+	var list strings.Builder
+	prefixWidth := 3 // cursor + spaces
+
+	// Adjust maxDisplayFiles to the space available for the file list
+	originalMaxDisplay := m.maxDisplayFiles
+	m.fitListToHeight()
+
+	// Keep titles within the list column
+	titleChars := m.maxTitleChars
+	if width < m.terminalWidth && titleChars > width-prefixWidth {
+		titleChars = width - prefixWidth
+	}
 
 	// Calculate display range with scrolling
 	totalFiles := len(m.files)
@@ -298,121 +773,57 @@ func (m Model) View() string {
 		title := file.Title()
 
 		// Calculate available width for content
-		prefixWidth := 3 // cursor + spaces
-		availableWidth := m.terminalWidth - prefixWidth
+		availableWidth := width - prefixWidth
 
 		// Truncate title first, then apply colorful styling
-		truncatedTitle := types.TruncateTitle(title, m.maxTitleChars)
+		truncatedTitle := types.TruncateTitle(title, titleChars)
 		styledTitle := m.getStyledTitle(truncatedTitle, file.IsDir, i == m.cursor)
+		if file.ParseError != "" {
+			if i != m.cursor {
+				styledTitle = unparsableFileStyle.Render(truncatedTitle)
+			}
+			styledTitle += " " + parseErrorBadgeStyle.Render("⚠ unparsable")
+		}
 
 		// Create responsive content line
 		displayLine := m.formatResponsiveColorLine(cursor, styledTitle, availableWidth)
-		s.WriteString(displayLine + "\n")
+		list.WriteString(displayLine + "\n")
 	}
 
 	// Show bottom scroll indicator with styling
 	if totalFiles > m.maxDisplayFiles {
 		remainingBelow := totalFiles - displayEnd
 		if remainingBelow > 0 {
-			s.WriteString(scrollIndicatorStyle.Render("↓ "+strconv.Itoa(remainingBelow)+" more below") + "\n")
+			list.WriteString(scrollIndicatorStyle.Render("↓ "+strconv.Itoa(remainingBelow)+" more below") + "\n")
 		}
 	}
 
 	// Restore original maxDisplayFiles
 	m.maxDisplayFiles = originalMaxDisplay
 
-	// Show preview if visible
-	if m.preview.IsVisible() {
-		s.WriteString("\n" + strings.Repeat("─", m.terminalWidth) + "\n")
-		s.WriteString(m.preview.View())
-	}
-
-	// Show help text based on layout
-	if !m.useCompactLayout {
-		s.WriteString("\n")
-		if m.preview.IsVisible() {
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "↑↓/jk", desc: "move"},
-				{keys: "enter", desc: "open"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r", desc: "resume"},
-				{keys: "R", desc: "resume (dangerous)"},
-				{keys: "d/u", desc: "scroll"},
-				{keys: "g/G", desc: "top/bot"},
-				{keys: "q", desc: "quit"},
-			}))
+	// Show status message from the last action, or else the summary of the selected session
+	if m.statusMessage != "" {
+		if m.statusIsError {
+			list.WriteString(statusErrorStyle.Render(m.statusMessage) + "\n")
 		} else {
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "↑↓/jk", desc: "move"},
-				{keys: "enter", desc: "open"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r", desc: "resume"},
-				{keys: "R", desc: "resume (dangerous)"},
-				{keys: "q", desc: "quit"},
-			}))
-		}
-	} else if m.terminalWidth < 40 {
-		// Very narrow: minimal help
-		if m.preview.IsVisible() {
-			s.WriteString("\n")
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "jk", desc: "move"},
-				{keys: "du", desc: "scroll"},
-				{keys: "gG", desc: "top/bot"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r/R", desc: "resume"},
-				{keys: "q", desc: "quit"},
-			}))
-		} else {
-			s.WriteString("\n")
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "jk", desc: "move"},
-				{keys: "enter", desc: "open"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r/R", desc: "resume"},
-				{keys: "q", desc: "quit"},
-			}))
-		}
-	} else {
-		// Compact: abbreviated help
-		if m.preview.IsVisible() {
-			s.WriteString("\n")
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "↑↓/jk", desc: "move"},
-				{keys: "enter", desc: "open"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r", desc: "resume"},
-				{keys: "R", desc: "resume (dangerous)"},
-				{keys: "d/u", desc: "scroll"},
-				{keys: "g/G", desc: "top/bot"},
-				{keys: "q", desc: "quit"},
-			}))
-		} else {
-			s.WriteString("\n")
-			s.WriteString(renderHelp([]helpItem{
-				{keys: "↑↓/jk", desc: "move"},
-				{keys: "enter", desc: "open"},
-				{keys: "p", desc: "preview"},
-				{keys: "s", desc: "filter"},
-				{keys: "c", desc: "copy sessionId"},
-				{keys: "r", desc: "resume"},
-				{keys: "R", desc: "resume (dangerous)"},
-				{keys: "q", desc: "quit"},
-			}))
+			list.WriteString(statusStyle.Render(m.statusMessage) + "\n")
 		}
+	} else if text := m.selectedSummary(width - prefixWidth); text != "" {
+		list.WriteString(strings.Repeat(" ", prefixWidth) + scrollIndicatorStyle.Render(text) + "\n")
 	}
 
-	return s.String()
+	return list.String()
+}
+
+// setClipboardStatus sets the status message for a clipboard copy result
+func (m *Model) setClipboardStatus(what, method string, err error) {
+	if err != nil {
+		m.statusMessage = "Copy failed: " + err.Error()
+		m.statusIsError = true
+		return
+	}
+	m.statusMessage = "Copied " + what + " to clipboard (via " + method + ")"
+	m.statusIsError = false
 }
 
 // helpItem represents a help text item with keys and description
@@ -424,8 +835,11 @@ type helpItem struct {
 // renderHelp renders the help text with styling
 func renderHelp(items []helpItem) string {
 	var parts []string
-	for i, item := range items {
-		if i > 0 {
+	for _, item := range items {
+		if item.keys == "" {
+			continue // Unbound action
+		}
+		if len(parts) > 0 {
 			parts = append(parts, helpSeparatorStyle.Render(" "))
 		}
 		parts = append(parts, helpKeyStyle.Render(item.keys)+helpSeparatorStyle.Render(":")+helpDescStyle.Render(item.desc))
@@ -433,26 +847,40 @@ func renderHelp(items []helpItem) string {
 	return strings.Join(parts, "")
 }
 
+// SplitRatio returns the share of the height given to the preview pane
+func (m Model) SplitRatio() float64 {
+	return m.preview.GetSplitRatio()
+}
+
+// SetSplitRatio sets the share of the height given to the preview pane
+func (m *Model) SetSplitRatio(ratio float64) {
+	m.preview.SetSplitRatio(ratio)
+	m.updatePreviewSize()
+}
+
 // GetSelectedFile returns the path of the selected file, or empty string if none selected
 func (m Model) GetSelectedFile() string {
 	return m.selected
 }
 
 type filesLoadedMsg struct {
-	files []FileInfo
+	files   []FileInfo
+	scanned int       // Number of files examined by a recursive scan
+	partial bool      // More batches of the scan follow
+	limited bool      // The scan stopped at the MaxFiles limit
+	scan    *fileScan // Recursive scan that produced the batch, nil for one-shot listings
 }
 
+// loadFiles lists dir. Recursive listings are scanned in the background and arrive in batches.
 func loadFiles(dir string, recursive bool) tea.Cmd {
-	return func() tea.Msg {
-		var files []FileInfo
-		var err error
-
-		if recursive {
-			files, err = GetFilesRecursive(dir)
-		} else {
-			files, err = GetFiles(dir)
+	if recursive {
+		return func() tea.Msg {
+			return startFileScan(dir).next()()
 		}
+	}
 
+	return func() tea.Msg {
+		files, err := GetFiles(dir)
 		if err != nil {
 			return filesLoadedMsg{files: []FileInfo{}}
 		}
@@ -460,6 +888,27 @@ func loadFiles(dir string, recursive bool) tea.Cmd {
 	}
 }
 
+// selectedPath returns the path of the file under the cursor, or "" if the list is empty
+func (m Model) selectedPath() string {
+	if m.cursor < 0 || m.cursor >= len(m.files) {
+		return ""
+	}
+	return m.files[m.cursor].Path
+}
+
+// selectPath moves the cursor to the file with the given path, if it is listed
+func (m *Model) selectPath(path string) {
+	if path == "" {
+		return
+	}
+	for i, file := range m.files {
+		if file.Path == path {
+			m.cursor = i
+			return
+		}
+	}
+}
+
 // openInEditor opens the specified file in the default editor
 func openInEditor(filepath string) tea.Cmd {
 	return tea.ExecProcess(getEditorCommand(filepath), func(err error) tea.Msg {
@@ -468,60 +917,83 @@ func openInEditor(filepath string) tea.Cmd {
 	})
 }
 
-// getEditorCommand returns the command to open a file in the default editor
+// getEditorCommand returns the command to open a file in the editor set with SetEditor, $EDITOR,
+// $VISUAL or the first default editor found. Editor commands may include arguments, e.g. "code --wait".
 func getEditorCommand(filepath string) *exec.Cmd {
-	// Get editor from environment variables
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = os.Getenv("VISUAL")
-	}
-	if editor == "" {
-		// Default editors to try
-		editors := []string{"nano", "vim", "vi", "emacs"}
-		for _, e := range editors {
-			if _, err := exec.LookPath(e); err == nil {
-				editor = e
-				break
-			}
-		}
-	}
-
-	if editor == "" {
+	args, _ := EditorCommand()
+	if len(args) == 0 {
 		return nil // No editor found
 	}
 
 	// Create command to open file in editor
-	cmd := exec.Command(editor, filepath)
+	cmd := exec.Command(args[0], append(slices.Clone(args[1:]), filepath)...)
 	return cmd
 }
 
-// convertAndOpenInEditor converts JSONL file to markdown and opens it in editor
+// EditorCommand returns the editor command files are opened with, split into arguments, and where
+// it comes from: "editor setting", "$EDITOR", "$VISUAL" or "default". It returns nil if no editor is found.
+func EditorCommand() ([]string, string) {
+	if len(editorCommand) > 0 {
+		return editorCommand, "editor setting"
+	}
+	for _, variable := range []string{"EDITOR", "VISUAL"} {
+		// A malformed variable is skipped like an unset one
+		if args, _ := splitCommand(os.Getenv(variable)); len(args) > 0 {
+			return args, "$" + variable
+		}
+	}
+	// Default editors of the platform to try
+	for _, e := range defaultEditors() {
+		if _, err := exec.LookPath(e); err == nil {
+			return []string{e}, "default"
+		}
+	}
+	return nil, ""
+}
+
+// exportFailedMsg reports that a session could not be written to the export directory to open it
+type exportFailedMsg struct {
+	err error
+}
+
+// convertAndOpenInEditor converts JSONL file to markdown and opens it in editor. A failed conversion
+// opens the JSONL file instead, unless the markdown was to be kept in the export directory, which is
+// reported in the status line.
 func convertAndOpenInEditor(jsonlPath string, enableFiltering bool) tea.Cmd {
 	return func() tea.Msg {
-		// Convert JSONL to markdown
-		markdownContent, err := convertJSONLToMarkdown(jsonlPath, enableFiltering)
+		// Keep the markdown in the export directory, if configured
+		if exportDir != "" {
+			path, err := exportMarkdown(jsonlPath, enableFiltering)
+			if err != nil {
+				return exportFailedMsg{err: err}
+			}
+			return openMarkdownInEditor(path, false)()
+		}
+
+		log, err := parser.ParseJSONLFile(jsonlPath)
 		if err != nil {
 			// If conversion fails, fall back to opening original file
 			return openInEditor(jsonlPath)()
 		}
 
 		// Create temporary markdown file
-		tempFile, err := os.CreateTemp("", "cclog_*.md")
+		tempFile, err := createTempFile("cclog_*.md")
 		if err != nil {
 			// If temp file creation fails, fall back to opening original file
 			return openInEditor(jsonlPath)()
 		}
 
-		// Write markdown content to temp file
-		if _, err := tempFile.Write([]byte(markdownContent)); err != nil {
-			tempFile.Close()
+		// Write the markdown to the temp file as it is formatted
+		err = outfile.Write(tempFile, func(w io.Writer) error {
+			return writeLogMarkdown(w, log, enableFiltering)
+		})
+		if err != nil {
 			os.Remove(tempFile.Name())
 			return openInEditor(jsonlPath)()
 		}
-		tempFile.Close()
 
 		// Open temp file in editor with cleanup
-		return openMarkdownInEditor(tempFile.Name())()
+		return openMarkdownInEditor(tempFile.Name(), true)()
 	}
 }
 
@@ -533,49 +1005,66 @@ func convertJSONLToMarkdown(jsonlPath string, enableFiltering bool) (string, err
 		return "", err
 	}
 
+	return formatLogToMarkdown(log, enableFiltering), nil
+}
+
+// formatLogToMarkdown converts a parsed conversation to markdown, filtered unless enableFiltering is false
+func formatLogToMarkdown(log *types.ConversationLog, enableFiltering bool) string {
+	var sb strings.Builder
+	writeLogMarkdown(&sb, log, enableFiltering)
+	return sb.String()
+}
+
+// writeLogMarkdown writes the markdown of formatLogToMarkdown to w as it is formatted
+func writeLogMarkdown(w io.Writer, log *types.ConversationLog, enableFiltering bool) error {
 	// Apply filtering based on enableFiltering parameter
 	filteredLog := formatter.FilterConversationLog(log, enableFiltering)
 
 	// Convert to markdown
-	markdown := formatter.FormatConversationToMarkdown(filteredLog, formatter.FormatOptions{
+	return formatter.WriteConversationMarkdown(w, filteredLog, formatter.FormatOptions{
 		ShowUUID:         false,
 		ShowPlaceholders: !enableFiltering, // Show placeholders when filtering is disabled (--include-all equivalent)
 	})
-
-	return markdown, nil
 }
 
-// openMarkdownInEditor opens a markdown file in editor and cleans up after
-func openMarkdownInEditor(markdownPath string) tea.Cmd {
+// openMarkdownInEditor opens a markdown file in editor and, if temporary, removes it after a terminal editor exits
+func openMarkdownInEditor(markdownPath string, temporary bool) tea.Cmd {
+	cleanup := func() {
+		if temporary {
+			os.Remove(markdownPath)
+		}
+	}
 	return func() tea.Msg {
 		cmd := getEditorCommand(markdownPath)
 		if cmd == nil {
-			os.Remove(markdownPath)
+			cleanup()
 			return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{}}
 		}
 
 		// Check if the editor is VS Code or other background editors
 		editorName := cmd.Args[0]
 		if isBackgroundEditor(editorName) {
-			// For background editors, use --wait flag and don't use ExecProcess
-			cmd.Args = append(cmd.Args[:1], append([]string{"--wait"}, cmd.Args[1:]...)...)
+			// For background editors, use --wait flag (unless configured already) and don't use ExecProcess
+			if !slices.Contains(cmd.Args, "--wait") && !slices.Contains(cmd.Args, "-w") {
+				cmd.Args = append(cmd.Args[:1], append([]string{"--wait"}, cmd.Args[1:]...)...)
+			}
 
 			// Run the command and wait for it to complete
 			if err := cmd.Run(); err != nil {
 				// If command fails, clean up and return
-				os.Remove(markdownPath)
+				cleanup()
 				return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{}}
 			}
 
-			// Clean up after editor closes
-			os.Remove(markdownPath)
+			// Leave the file in place: background editors may return before they have read it, e.g. when
+			// a wrapper script ignores --wait. RemoveStaleTempFiles deletes it on a later run.
 			return tea.Quit
 		}
 
 		// For terminal editors, use ExecProcess
 		return tea.ExecProcess(cmd, func(err error) tea.Msg {
 			// Clean up temporary file after editor closes
-			os.Remove(markdownPath)
+			cleanup()
 			// Return to TUI after editor exits
 			return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{}}
 		})()
@@ -584,20 +1073,21 @@ func openMarkdownInEditor(markdownPath string) tea.Cmd {
 
 // isBackgroundEditor checks if the editor runs in background
 func isBackgroundEditor(editorPath string) bool {
-	// Extract basename from path
-	editorName := editorPath
-	if lastSlash := strings.LastIndex(editorPath, "/"); lastSlash >= 0 {
-		editorName = editorPath[lastSlash+1:]
-	}
-
 	// Known background editors
 	backgroundEditors := []string{"code", "codium", "subl", "atom"}
-	for _, bg := range backgroundEditors {
-		if editorName == bg {
-			return true
+	return slices.Contains(backgroundEditors, editorName(editorPath))
+}
+
+// editorName returns the lowercase name of an editor command without its directory and, for Windows
+// commands such as C:\Program Files\Microsoft VS Code\Code.exe or code.cmd, its extension
+func editorName(editorPath string) string {
+	name := strings.ToLower(editorPath[strings.LastIndexAny(editorPath, `/\`)+1:])
+	for _, ext := range []string{".exe", ".cmd", ".bat"} {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok && trimmed != "" {
+			return trimmed
 		}
 	}
-	return false
+	return name
 }
 
 // GetInitialWindowSize gets the current terminal size
@@ -676,7 +1166,7 @@ func (m Model) getStyledTitle(title string, isDir bool, isSelected bool) string
 	case isDir:
 		// Directory gets distinctive blue color and bold formatting
 		return directoryStyle.Render(title)
-	case strings.HasSuffix(title, ".jsonl"):
+	case parser.IsLogFile(title):
 		// JSONL files get green color for easy identification
 		return jsonlFileStyle.Render(title)
 	default:
@@ -700,12 +1190,49 @@ func (m Model) formatResponsiveColorLine(cursor, styledTitle string, availableWi
 	return line
 }
 
+// isSideBySide reports whether the preview is drawn to the right of the file list
+func (m Model) isSideBySide() bool {
+	switch m.layout {
+	case previewLayoutStacked:
+		return false
+	case previewLayoutSideBySide:
+		return true
+	default:
+		return m.terminalWidth > sideBySideMinWidth
+	}
+}
+
+// sideBySideWidths returns the list and preview widths for the side-by-side layout
+func (m Model) sideBySideWidths() (int, int) {
+	listWidth := int(float64(m.terminalWidth) * sideBySideListRatio)
+	previewWidth := m.terminalWidth - listWidth - 1 // One column gap between list and preview
+	if previewWidth < 0 {
+		previewWidth = 0
+	}
+	return listWidth, previewWidth
+}
+
+// fullHeight returns the height available below the header and above the help line
+func (m Model) fullHeight() int {
+	height := m.terminalHeight - 5 // Full height minus header and help
+	if height < 1 {
+		height = 1 // Ensure minimum height
+	}
+	return height
+}
+
 // updatePreviewSize adjusts the preview size based on terminal dimensions
 func (m *Model) updatePreviewSize() {
 	if m.preview == nil {
 		return
 	}
 
+	if m.isSideBySide() {
+		_, previewWidth := m.sideBySideWidths()
+		m.preview.SetSize(previewWidth, m.fullHeight())
+		return
+	}
+
 	previewWidth := m.terminalWidth // Use full terminal width
 	if previewWidth < 0 {
 		previewWidth = 0
@@ -731,14 +1258,20 @@ func (m *Model) updateDynamicLayout(splitRatio float64) {
 	m.preview.SetSize(previewWidth, m.preview.height)
 }
 
+// fitListToHeight sizes the displayed file window to the list height and keeps the cursor in it
+func (m *Model) fitListToHeight() {
+	if listHeight := m.getListHeight(); listHeight > 0 {
+		m.maxDisplayFiles = listHeight
+	}
+
+	// Ensure cursor is visible with updated display count
+	m.ensureCursorVisible()
+}
+
 // getListHeight returns the height available for the file list
 func (m *Model) getListHeight() int {
-	if !m.preview.IsVisible() {
-		listHeight := m.terminalHeight - 5 // Full height minus header and help
-		if listHeight < 1 {
-			listHeight = 1 // Ensure minimum height
-		}
-		return listHeight
+	if !m.preview.IsVisible() || m.isSideBySide() {
+		return m.fullHeight()
 	}
 
 	_, listHeight := calculatePreviewHeight(m.terminalHeight, m.preview.GetSplitRatio(), 10)
@@ -795,63 +1328,37 @@ func (m *Model) updatePreviewContent() tea.Cmd {
 		return nil
 	}
 
+	// Previews still being generated are no longer wanted
+	m.previewGeneration++
+	previous := m.previewPath
 	selectedFile := m.files[m.cursor]
+	m.previewPath = selectedFile.Path
 	if selectedFile.IsDir {
 		// Clear preview for directories
 		return m.preview.SetContent("")
 	}
-
-	// Generate preview for JSONL files
-	if strings.HasSuffix(selectedFile.Path, ".jsonl") {
-		content, err := GeneratePreview(selectedFile.Path, m.enableFiltering)
-		if err != nil {
-			return m.preview.SetContent("Error generating preview: " + err.Error())
-		} else {
-			return m.preview.SetContent(content)
-		}
-	} else {
+	if !parser.IsLogFile(selectedFile.Path) {
 		return m.preview.SetContent("Preview not available for this file type")
 	}
-}
-
-// copySessionIDMsg represents the result of copying sessionId to clipboard
-type copySessionIDMsg struct {
-	success bool
-	error   error
-}
-
-// copySessionID copies the sessionId from the selected file to clipboard
-func copySessionID(filePath string) tea.Cmd {
-	return func() tea.Msg {
-		sessionId, err := extractSessionID(filePath)
-		if err != nil {
-			return copySessionIDMsg{
-				success: false,
-				error:   err,
-			}
-		}
-
-		err = clipboard.WriteAll(sessionId)
-		if err != nil {
-			// Provide user-friendly error messages for common clipboard issues
-			var enhancedErr error
-			if strings.Contains(err.Error(), "xclip") || strings.Contains(err.Error(), "xsel") {
-				enhancedErr = fmt.Errorf("clipboard functionality requires xclip or xsel on Linux")
-			} else if strings.Contains(err.Error(), "not available") {
-				enhancedErr = fmt.Errorf("clipboard functionality is not available in this environment")
-			} else {
-				enhancedErr = fmt.Errorf("failed to copy to clipboard: %w", err)
-			}
 
-			return copySessionIDMsg{
-				success: false,
-				error:   enhancedErr,
-			}
-		}
+	// Generate preview for JSONL files
+	collapseThreshold := previewCollapseThreshold
+	if m.expandMessages {
+		collapseThreshold = 0
+	}
+	key, err := m.previewKeyFor(selectedFile.Path, collapseThreshold)
+	if err != nil {
+		return m.preview.SetContent("Error generating preview: " + err.Error())
+	}
+	if content, ok := m.cache().get(key); ok {
+		return m.preview.SetContent(content)
+	}
 
-		return copySessionIDMsg{
-			success: true,
-			error:   nil,
-		}
+	// Large sessions take a while to convert, so the preview is generated in the background.
+	// A session that is already shown, e.g. one being written, keeps its preview until the new one is ready.
+	load := loadPreview(key, m.previewGeneration, m.keys.helpKeys(ActionFullPreview))
+	if previous == selectedFile.Path {
+		return load
 	}
+	return tea.Batch(m.preview.SetContent(m.loadingText()), load)
 }
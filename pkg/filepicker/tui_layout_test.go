@@ -1,9 +1,11 @@
 package filepicker
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func TestModelUpdatePreviewSize(t *testing.T) {
@@ -233,3 +235,129 @@ func TestModelMinimumSizeConstraints(t *testing.T) {
 		t.Errorf("Preview height should not be negative: %d", height)
 	}
 }
+
+func TestModelSideBySideLayout(t *testing.T) {
+	tests := []struct {
+		name          string
+		terminalWidth int
+		layout        previewLayout
+		want          bool
+	}{
+		{name: "auto on standard terminal", terminalWidth: 120, layout: previewLayoutAuto, want: false},
+		{name: "auto at threshold", terminalWidth: sideBySideMinWidth, layout: previewLayoutAuto, want: false},
+		{name: "auto on wide terminal", terminalWidth: 160, layout: previewLayoutAuto, want: true},
+		{name: "forced stacked on wide terminal", terminalWidth: 160, layout: previewLayoutStacked, want: false},
+		{name: "forced side by side on narrow terminal", terminalWidth: 100, layout: previewLayoutSideBySide, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewModel("/tmp", false)
+			model.terminalWidth = tt.terminalWidth
+			model.layout = tt.layout
+
+			if got := model.isSideBySide(); got != tt.want {
+				t.Errorf("isSideBySide() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelSideBySidePreviewSize(t *testing.T) {
+	model := NewModel("/tmp", false)
+	model.terminalWidth = 200
+	model.terminalHeight = 50
+
+	model.updatePreviewSize()
+
+	width, height := model.preview.GetSize()
+	if width != 119 { // 200 - 80 (list) - 1 (gap)
+		t.Errorf("preview width = %d, expected 119", width)
+	}
+	if height != 45 { // Full height beside the list
+		t.Errorf("preview height = %d, expected 45", height)
+	}
+	if listHeight := model.getListHeight(); listHeight != 45 {
+		t.Errorf("list height = %d, expected 45", listHeight)
+	}
+}
+
+func TestModelToggleLayoutKey(t *testing.T) {
+	model := NewModel("/tmp", false)
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	model = updated.(Model)
+
+	if !model.isSideBySide() {
+		t.Fatal("Expected side-by-side layout on a wide terminal")
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	model = updated.(Model)
+	if model.isSideBySide() {
+		t.Error("Expected 'v' to switch to stacked layout")
+	}
+	if width, _ := model.preview.GetSize(); width != 160 {
+		t.Errorf("Stacked preview width = %d, expected 160", width)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	model = updated.(Model)
+	if !model.isSideBySide() {
+		t.Error("Expected 'v' to switch back to side-by-side layout")
+	}
+}
+
+func TestModelSideBySideView(t *testing.T) {
+	model := NewModel("/tmp", false)
+	model.terminalWidth = 160
+	model.terminalHeight = 30
+	model.layout = previewLayoutSideBySide
+	model.files = []FileInfo{{Name: "a-very-long-directory-name-that-does-not-fit-in-the-list-column", IsDir: true}}
+	model.updatePreviewSize()
+
+	lines := strings.Split(model.View(), "\n")
+	// The last line is the help text, which the terminal wraps
+	for i, line := range lines[:len(lines)-1] {
+		if lipgloss.Width(line) > model.terminalWidth {
+			t.Errorf("line %d is %d columns wide, exceeding terminal width %d", i, lipgloss.Width(line), model.terminalWidth)
+		}
+	}
+	if strings.Contains(lines[2], "does-not-fit-in-the-list-column") {
+		t.Errorf("list entry should be truncated to the list column: %q", lines[2])
+	}
+	if !strings.Contains(lines[2], "╭") || !strings.Contains(lines[4], "No preview available") {
+		t.Error("Expected the preview to be drawn beside the list")
+	}
+}
+
+func TestModelSplitRatioKeys(t *testing.T) {
+	model := NewModel("/tmp", false)
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	model = updated.(Model)
+
+	_, initialHeight := model.preview.GetSize()
+	initialListHeight := model.getListHeight()
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	model = updated.(Model)
+
+	if ratio := model.SplitRatio(); ratio != 0.7 {
+		t.Errorf("SplitRatio() after '-' = %f, expected 0.7", ratio)
+	}
+	_, height := model.preview.GetSize()
+	if height >= initialHeight {
+		t.Errorf("Preview height should shrink immediately: %d -> %d", initialHeight, height)
+	}
+	if listHeight := model.getListHeight(); listHeight <= initialListHeight {
+		t.Errorf("List height should grow immediately: %d -> %d", initialListHeight, listHeight)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	model = updated.(Model)
+	if ratio := model.SplitRatio(); ratio != 0.8 {
+		t.Errorf("SplitRatio() after '+' = %f, expected 0.8", ratio)
+	}
+	if _, height := model.preview.GetSize(); height != initialHeight {
+		t.Errorf("Preview height = %d, expected %d after restoring the ratio", height, initialHeight)
+	}
+}
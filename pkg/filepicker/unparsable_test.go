@@ -0,0 +1,74 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeUnparsableFixtures(t *testing.T) (string, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.jsonl")
+	corrupt := filepath.Join(dir, "corrupt.jsonl")
+	empty := filepath.Join(dir, "empty.jsonl")
+
+	validContent := `{"type":"user","message":{"role":"user","content":"test"},"uuid":"test-uuid","timestamp":"2025-07-06T05:01:44.663Z"}`
+	corruptContent := validContent + "\n{\"type\":\"user\",\"message\":\n"
+
+	for path, content := range map[string]string{valid: validContent, corrupt: corruptContent, empty: ""} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+	}
+	return dir, valid, corrupt
+}
+
+func TestGetFilesKeepsUnparsableSessions(t *testing.T) {
+	dir, valid, corrupt := writeUnparsableFixtures(t)
+
+	for name, getFiles := range map[string]func(string) ([]FileInfo, error){
+		"GetFiles":          GetFiles,
+		"GetFilesRecursive": GetFilesRecursive,
+	} {
+		t.Run(name, func(t *testing.T) {
+			files, err := getFiles(dir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			found := make(map[string]FileInfo)
+			for _, f := range files {
+				found[f.Path] = f
+			}
+
+			if f, ok := found[corrupt]; !ok {
+				t.Error("Unparsable session should be listed")
+			} else if !strings.Contains(f.ParseError, "line 2") {
+				t.Errorf("Expected parse error for line 2, got %q", f.ParseError)
+			}
+			if f := found[valid]; f.ParseError != "" {
+				t.Errorf("Valid session should have no parse error, got %q", f.ParseError)
+			}
+			if _, ok := found[filepath.Join(dir, "empty.jsonl")]; ok {
+				t.Error("Empty session should still be skipped")
+			}
+		})
+	}
+}
+
+func TestUnparsableSessionDisplay(t *testing.T) {
+	file := FileInfo{Name: "corrupt.jsonl", Path: "/logs/corrupt.jsonl", ParseError: "failed to unmarshal line 2"}
+	if title := file.Title(); !strings.HasSuffix(title, " corrupt.jsonl") {
+		t.Errorf("Expected title to show the file name, got %q", title)
+	}
+
+	model := NewModel("/logs", false)
+	model.preview.SetVisible(false)
+	model.files = []FileInfo{file}
+	if view := model.View(); !strings.Contains(view, "⚠ unparsable") {
+		t.Errorf("Expected error badge in view, got:\n%s", view)
+	}
+}
@@ -0,0 +1,198 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Rule decides whether a message should be excluded from filtered output.
+// Exclude receives the message together with its extracted text content.
+type Rule struct {
+	Name    string
+	Exclude func(msg types.Message, content string) bool
+}
+
+// RuleSet is an ordered collection of rules applied to messages
+type RuleSet struct {
+	rules []Rule
+}
+
+// DefaultRules returns the rules used to remove system noise from conversations
+func DefaultRules() []Rule {
+	return []Rule{
+		TypeRule("system"),
+		TypeRule("summary"),
+		{Name: "meta", Exclude: func(msg types.Message, _ string) bool { return msg.IsMeta }},
+		{Name: "empty", Exclude: func(_ types.Message, content string) bool { return content == "" }},
+		ContainsRule(APIErrorRule, "API Error"),
+		ContainsRule("interrupted", "[Request interrupted"),
+		ContainsRule("command", "<command-name>"),
+		ContainsRule("bash-input", "<bash-input>"),
+		ContainsRule("command-output", "<local-command-stdout>"),
+		ContainsRule("caveat", "Caveat: The messages below were generated"),
+	}
+}
+
+// APIErrorRule is the name of the default rule that drops the error messages of failed API requests
+const APIErrorRule = "api-error"
+
+// TypeRule excludes messages of the given type
+func TypeRule(msgType string) Rule {
+	return Rule{
+		Name: "type:" + msgType,
+		Exclude: func(msg types.Message, _ string) bool {
+			return msg.Type == msgType
+		},
+	}
+}
+
+// ContainsRule excludes messages whose content contains the given substring
+func ContainsRule(name, substr string) Rule {
+	return Rule{
+		Name: name,
+		Exclude: func(_ types.Message, content string) bool {
+			return strings.Contains(content, substr)
+		},
+	}
+}
+
+// RegexpRule excludes messages whose content matches the given regular expression
+func RegexpRule(name string, pattern *regexp.Regexp) Rule {
+	return Rule{
+		Name: name,
+		Exclude: func(_ types.Message, content string) bool {
+			return pattern.MatchString(content)
+		},
+	}
+}
+
+// Config customizes the default rules, e.g. from the user settings
+type Config struct {
+	// Disable names default rules to skip, e.g. "command-output" to keep local command output
+	Disable []string
+	// ExcludePatterns are regular expressions; messages whose content matches one are excluded
+	ExcludePatterns []string
+}
+
+// FromConfig builds a rule set from the default rules without the disabled ones,
+// followed by one rule per exclude pattern (named "pattern:<expr>")
+func FromConfig(config Config) (*RuleSet, error) {
+	defaults := DefaultRules()
+	for _, name := range config.Disable {
+		if !hasRule(defaults, name) {
+			return nil, fmt.Errorf("unknown filter rule %q (rules: %s)", name, strings.Join(ruleNames(defaults), ", "))
+		}
+	}
+
+	var rules []Rule
+	for _, rule := range defaults {
+		if !containsFold(config.Disable, rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+	for _, expr := range config.ExcludePatterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", expr, err)
+		}
+		rules = append(rules, RegexpRule("pattern:"+expr, pattern))
+	}
+	return NewRuleSet(rules...), nil
+}
+
+// hasRule reports whether rules contain a rule with the given name, ignoring case
+func hasRule(rules []Rule, name string) bool {
+	return containsFold(ruleNames(rules), name)
+}
+
+// ruleNames returns the names of rules in order
+func ruleNames(rules []Rule) []string {
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+// current is the rule set used by Current, configured once at startup
+var current = Default()
+
+// SetCurrent replaces the rule set returned by Current. It is not safe to call concurrently with filtering.
+func SetCurrent(rules *RuleSet) {
+	current = rules
+}
+
+// Current returns the configured rule set: the default rules unless SetCurrent was called
+func Current() *RuleSet {
+	return current
+}
+
+// NewRuleSet creates a rule set from the given rules
+func NewRuleSet(rules ...Rule) *RuleSet {
+	return &RuleSet{rules: rules}
+}
+
+// Default returns a rule set with the default rules
+func Default() *RuleSet {
+	return NewRuleSet(DefaultRules()...)
+}
+
+// Rules returns a copy of the rules in this set
+func (r *RuleSet) Rules() []Rule {
+	rules := make([]Rule, len(r.rules))
+	copy(rules, r.rules)
+	return rules
+}
+
+// Names returns the names of the rules in this set, in order
+func (r *RuleSet) Names() []string {
+	return ruleNames(r.rules)
+}
+
+// Without returns a rule set with the rules of r except those with the given names, ignoring case
+func (r *RuleSet) Without(names ...string) *RuleSet {
+	var rules []Rule
+	for _, rule := range r.rules {
+		if !containsFold(names, rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+	return NewRuleSet(rules...)
+}
+
+// IsContentful determines if a message contains meaningful content
+func (r *RuleSet) IsContentful(msg types.Message) bool {
+	content := types.ExtractTextContent(msg.Message)
+	for _, rule := range r.rules {
+		if rule.Exclude(msg, content) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterMessages filters a slice of messages based on content quality
+func (r *RuleSet) FilterMessages(messages []types.Message, enableFiltering bool) []types.Message {
+	if !enableFiltering {
+		return messages
+	}
+
+	var filtered []types.Message
+	for _, msg := range messages {
+		if r.IsContentful(msg) {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
+// FilterConversationLog filters messages in a conversation log
+func (r *RuleSet) FilterConversationLog(log *types.ConversationLog, enableFiltering bool) *types.ConversationLog {
+	return &types.ConversationLog{
+		Messages: r.FilterMessages(log.Messages, enableFiltering),
+		FilePath: log.FilePath,
+	}
+}
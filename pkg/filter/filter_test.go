@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestDefaultIsContentful(t *testing.T) {
+	timestamp, _ := time.Parse(time.RFC3339, "2025-07-06T05:01:29.618Z")
+
+	tests := []struct {
+		name     string
+		message  types.Message
+		expected bool
+	}{
+		{
+			name: "normal user message",
+			message: types.Message{
+				Type:      "user",
+				Timestamp: timestamp,
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Hello")},
+			},
+			expected: true,
+		},
+		{
+			name: "caveat message should be filtered",
+			message: types.Message{
+				Type:      "user",
+				Timestamp: timestamp,
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("Caveat: The messages below were generated by the user while running local commands.")},
+			},
+			expected: false,
+		},
+		{
+			name: "command output should be filtered",
+			message: types.Message{
+				Type:      "user",
+				Timestamp: timestamp,
+				Message:   &types.MessageBody{Role: "user", Content: types.TextContent("<local-command-stdout>done</local-command-stdout>")},
+			},
+			expected: false,
+		},
+		{
+			name: "tool use without text should be filtered",
+			message: types.Message{
+				Type:      "assistant",
+				Timestamp: timestamp,
+				Message:   &types.MessageBody{Role: "assistant", Content: types.BlockContent(types.ToolUseBlock{Name: "Bash"})},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Default().IsContentful(tt.message)
+			if result != tt.expected {
+				t.Errorf("IsContentful() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCustomRuleSet(t *testing.T) {
+	messages := []types.Message{
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("keep me")}},
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("TODO drop me")}},
+		{Type: "system", Message: &types.MessageBody{Role: "system", Content: types.TextContent("system text")}},
+	}
+
+	rules := NewRuleSet(ContainsRule("todo", "TODO"))
+	filtered := rules.FilterMessages(messages, true)
+
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(filtered))
+	}
+	if filtered[1].Type != "system" {
+		t.Error("Custom rule set should not apply default system rule")
+	}
+}
+
+func TestFilterMessagesDisabled(t *testing.T) {
+	messages := []types.Message{
+		{Type: "system"},
+		{Type: "summary"},
+	}
+
+	filtered := Default().FilterMessages(messages, false)
+	if len(filtered) != len(messages) {
+		t.Errorf("Expected %d messages with filtering disabled, got %d", len(messages), len(filtered))
+	}
+}
+
+func TestRuleSetWithout(t *testing.T) {
+	rs := Default().Without("API-Error", "caveat")
+	if slices.Contains(rs.Names(), APIErrorRule) || slices.Contains(rs.Names(), "caveat") {
+		t.Errorf("Expected the rules to be removed, got %v", rs.Names())
+	}
+	if len(rs.Names()) != len(DefaultRules())-2 {
+		t.Errorf("Expected the other rules to be kept, got %v", rs.Names())
+	}
+}
+
+func TestRulesReturnsCopy(t *testing.T) {
+	rs := Default()
+	rules := rs.Rules()
+	rules[0] = TypeRule("user")
+
+	if rs.Rules()[0].Name != "type:system" {
+		t.Error("Modifying returned rules should not change the rule set")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	output := types.Message{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("<local-command-stdout>ok</local-command-stdout>")}}
+	ticket := types.Message{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.TextContent("See ACME-1234")}}
+
+	rules, err := FromConfig(Config{Disable: []string{"command-output"}, ExcludePatterns: []string{`ACME-\d+`}})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if !rules.IsContentful(output) {
+		t.Error("Disabling command-output should keep command output")
+	}
+	if rules.IsContentful(ticket) {
+		t.Error("Messages matching an exclude pattern should be filtered")
+	}
+	names := rules.Names()
+	if names[len(names)-1] != `pattern:ACME-\d+` {
+		t.Errorf("Expected the pattern rule last, got %v", names)
+	}
+
+	if _, err := FromConfig(Config{Disable: []string{"nonexistent"}}); err == nil {
+		t.Error("Expected error for an unknown rule")
+	}
+	if _, err := FromConfig(Config{ExcludePatterns: []string{"("}}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
+
+func TestSetCurrent(t *testing.T) {
+	defer SetCurrent(Current())
+	custom := NewRuleSet(TypeRule("user"))
+	SetCurrent(custom)
+	if Current() != custom {
+		t.Error("Current should return the rule set passed to SetCurrent")
+	}
+}
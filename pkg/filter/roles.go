@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// Roles that messages can be selected by
+const (
+	RoleUser      = "user"      // Prompts written by the user
+	RoleAssistant = "assistant" // Replies and tool calls of the assistant
+	RoleTool      = "tool"      // User messages that only carry tool results
+)
+
+// Roles lists the valid role names
+var Roles = []string{RoleUser, RoleAssistant, RoleTool}
+
+// ValidateRoles checks that every name in roles is a known role
+func ValidateRoles(roles []string) error {
+	for _, role := range roles {
+		if !containsFold(Roles, role) {
+			return fmt.Errorf("unknown role %q (valid roles: %s)", role, strings.Join(Roles, ", "))
+		}
+	}
+	return nil
+}
+
+// MessageRole returns the role of a message: user messages that only hold tool results belong to RoleTool
+func MessageRole(msg types.Message) string {
+	if msg.Type != "user" {
+		return msg.Type
+	}
+	blocks := msg.Message.Blocks()
+	if len(blocks) == 0 {
+		return RoleUser
+	}
+	for _, block := range blocks {
+		if _, ok := block.(types.ToolResultBlock); !ok {
+			return RoleUser
+		}
+	}
+	return RoleTool
+}
+
+// FilterRoles returns a copy of log with only the messages of the given roles.
+// An empty roles list keeps every message and returns log as is.
+func FilterRoles(log *types.ConversationLog, roles []string) *types.ConversationLog {
+	if len(roles) == 0 {
+		return log
+	}
+	var messages []types.Message
+	for _, msg := range log.Messages {
+		if containsFold(roles, MessageRole(msg)) {
+			messages = append(messages, msg)
+		}
+	}
+	return &types.ConversationLog{Messages: messages, FilePath: log.FilePath}
+}
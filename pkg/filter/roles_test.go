@@ -0,0 +1,35 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func TestFilterRoles(t *testing.T) {
+	messages := append(toolMessages(), types.Message{
+		Type:    "user",
+		Message: &types.MessageBody{Role: "user", Content: types.TextContent("Thanks, now add a test")},
+	})
+	log := &types.ConversationLog{Messages: messages}
+
+	tests := []struct {
+		roles []string
+		want  int
+	}{
+		{[]string{"user"}, 1},
+		{[]string{"assistant"}, 1},
+		{[]string{"Tool"}, 2},
+		{[]string{"user", "assistant"}, 2},
+		{nil, 4},
+	}
+	for _, tt := range tests {
+		if got := FilterRoles(log, tt.roles); len(got.Messages) != tt.want {
+			t.Errorf("FilterRoles(%v) kept %d messages, want %d", tt.roles, len(got.Messages), tt.want)
+		}
+	}
+
+	if err := ValidateRoles([]string{"user", "robot"}); err == nil {
+		t.Error("Expected error for an unknown role")
+	}
+}
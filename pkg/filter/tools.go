@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+// ToolFilter selects which tools' calls and results are kept in a conversation.
+// Tool names are matched case-insensitively. The zero value keeps every tool.
+type ToolFilter struct {
+	// Include keeps only these tools when not empty
+	Include []string
+	// Exclude drops these tools, even if they are included
+	Exclude []string
+}
+
+// Active reports whether the filter drops any tool
+func (f ToolFilter) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+// Keeps reports whether the calls of the named tool are kept
+func (f ToolFilter) Keeps(name string) bool {
+	if len(f.Include) > 0 && !containsFold(f.Include, name) {
+		return false
+	}
+	return !containsFold(f.Exclude, name)
+}
+
+// String describes the filter, e.g. "include=Bash,Edit exclude=WebSearch"
+func (f ToolFilter) String() string {
+	return "include=" + strings.Join(f.Include, ",") + " exclude=" + strings.Join(f.Exclude, ",")
+}
+
+// Apply returns a copy of log without the tool_use blocks of dropped tools and the tool_result
+// blocks answering them. Messages left without content are removed, and messages that lose a
+// tool result also lose its toolUseResult metadata. The input log is not modified.
+func (f ToolFilter) Apply(log *types.ConversationLog) *types.ConversationLog {
+	if !f.Active() {
+		return log
+	}
+
+	// Tool_use ids of dropped tools
+	dropped := make(map[string]bool)
+	for _, msg := range log.Messages {
+		for _, block := range msg.Message.Blocks() {
+			if use, ok := block.(types.ToolUseBlock); ok && use.ID != "" && !f.Keeps(use.Name) {
+				dropped[use.ID] = true
+			}
+		}
+	}
+
+	var messages []types.Message
+	for _, msg := range log.Messages {
+		blocks := msg.Message.Blocks()
+		if blocks == nil {
+			messages = append(messages, msg)
+			continue
+		}
+
+		var content []types.ContentBlock
+		removedResult := false
+		for _, block := range blocks {
+			switch b := block.(type) {
+			case types.ToolUseBlock:
+				if !f.Keeps(b.Name) {
+					continue
+				}
+			case types.ToolResultBlock:
+				if dropped[b.ToolUseID] {
+					removedResult = true
+					continue
+				}
+			}
+			content = append(content, block)
+		}
+		if len(content) == len(blocks) {
+			messages = append(messages, msg)
+			continue
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		body := *msg.Message
+		body.Content = types.BlockContent(content...)
+		if removedResult {
+			body.ToolUseResult = nil
+			msg.ToolUseResult = nil
+		}
+		msg.Message = &body
+		messages = append(messages, msg)
+	}
+
+	return &types.ConversationLog{Messages: messages, FilePath: log.FilePath}
+}
+
+// containsFold reports whether names contains name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/annenpolka/cclog/pkg/types"
+)
+
+func toolMessages() []types.Message {
+	use := func(id, name string) types.ToolUseBlock {
+		return types.ToolUseBlock{ID: id, Name: name}
+	}
+	result := func(id string) types.ToolResultBlock {
+		return types.ToolResultBlock{ToolUseID: id, Content: types.TextContent("output")}
+	}
+	return []types.Message{
+		{Type: "assistant", Message: &types.MessageBody{Role: "assistant", Content: types.BlockContent(
+			types.TextBlock{Text: "Let me look."},
+			use("t1", "Read"),
+			use("t2", "Edit"),
+		)}},
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(result("t1"))},
+			ToolUseResult: map[string]interface{}{"type": "text"}},
+		{Type: "user", Message: &types.MessageBody{Role: "user", Content: types.BlockContent(result("t2"))}},
+	}
+}
+
+func TestToolFilterApply(t *testing.T) {
+	log := &types.ConversationLog{Messages: toolMessages()}
+
+	filtered := ToolFilter{Include: []string{"edit"}}.Apply(log)
+	if len(filtered.Messages) != 2 {
+		t.Fatalf("Expected the Read result message to be dropped, got %d messages", len(filtered.Messages))
+	}
+	blocks := filtered.Messages[0].Message.Blocks()
+	if len(blocks) != 2 || blocks[0].BlockType() != "text" || blocks[1].(types.ToolUseBlock).Name != "Edit" {
+		t.Errorf("Expected the text and the Edit call, got %+v", blocks)
+	}
+	if len(log.Messages[0].Message.Blocks()) != 3 {
+		t.Error("Apply should not modify the input log")
+	}
+
+	filtered = ToolFilter{Exclude: []string{"Edit"}}.Apply(log)
+	if len(filtered.Messages) != 2 || filtered.Messages[1].ToolUseResult == nil {
+		t.Errorf("Expected the Edit result to be dropped and the Read result kept, got %+v", filtered.Messages)
+	}
+
+	if (ToolFilter{}).Apply(log) != log {
+		t.Error("An inactive filter should return the log as is")
+	}
+}
+
+func TestToolFilterKeeps(t *testing.T) {
+	f := ToolFilter{Include: []string{"Bash", "Edit"}, Exclude: []string{"edit"}}
+	for name, want := range map[string]bool{"Bash": true, "bash": true, "Edit": false, "Read": false} {
+		if got := f.Keeps(name); got != want {
+			t.Errorf("Keeps(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
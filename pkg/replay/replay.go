@@ -0,0 +1,244 @@
+// Package replay plays a conversation back in the terminal one message at a time,
+// waiting between messages as long as the original conversation did (scaled by the speed).
+package replay
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/philistino/teacup/markdown"
+)
+
+const (
+	// MaxDelay is the longest wait between two messages at normal speed; longer breaks, e.g. overnight, are shortened to it
+	MaxDelay = 5 * time.Second
+	// DefaultDelay is the wait before messages without a timestamp at normal speed
+	DefaultDelay = time.Second
+	// MinSpeed and MaxSpeed bound the playback speed
+	MinSpeed = 0.25
+	MaxSpeed = 64.0
+)
+
+// renderMarkdown is a variable that can be replaced in tests to avoid glamour rendering
+var renderMarkdown = markdown.RenderMarkdown
+
+// Frame is a message of the replay and how long to wait before showing it at normal speed
+type Frame struct {
+	Delay    time.Duration
+	Markdown string
+}
+
+// NewFrames pairs messages with their rendered markdown blocks (see formatter.FormatMessagesToMarkdown).
+// The first message is shown at once; each following one waits as long as it came after the previous
+// message, at most MaxDelay.
+func NewFrames(messages []types.Message, blocks []string) []Frame {
+	frames := make([]Frame, len(blocks))
+	for i, block := range blocks {
+		frames[i].Markdown = block
+		if i == 0 || i >= len(messages) {
+			continue
+		}
+		previous, current := messages[i-1].Timestamp, messages[i].Timestamp
+		switch {
+		case previous.IsZero() || current.IsZero():
+			frames[i].Delay = DefaultDelay
+		case current.Sub(previous) > MaxDelay:
+			frames[i].Delay = MaxDelay
+		case current.After(previous):
+			frames[i].Delay = current.Sub(previous)
+		}
+	}
+	return frames
+}
+
+// tickMsg shows the next frame, unless it was scheduled before the last pause, skip or speed change
+type tickMsg struct {
+	generation int
+}
+
+// Model is the Bubble Tea model of the replay
+type Model struct {
+	frames     []Frame
+	rendered   []string // Rendered markdown of the frames shown so far
+	speed      float64
+	paused     bool
+	generation int // Incremented to cancel the scheduled tick
+	scroll     int // Lines scrolled up from the end of the conversation
+	width      int
+	height     int
+	plain      bool
+}
+
+// NewModel creates a replay of frames at speed times the original pace (1 is the original pace)
+func NewModel(frames []Frame, speed float64) Model {
+	return Model{
+		frames: frames,
+		speed:  clampSpeed(speed),
+		width:  80,
+		height: 24,
+	}
+}
+
+// SetPlain shows the markdown as is, without colors, and uses ASCII symbols only
+func (m *Model) SetPlain(plain bool) {
+	m.plain = plain
+}
+
+// Speed returns the playback speed
+func (m Model) Speed() float64 {
+	return m.speed
+}
+
+// Shown returns the number of messages shown so far
+func (m Model) Shown() int {
+	return len(m.rendered)
+}
+
+// Paused reports whether the playback is paused
+func (m Model) Paused() bool {
+	return m.paused
+}
+
+// Init schedules the first message
+func (m Model) Init() tea.Cmd {
+	return m.schedule()
+}
+
+// Update handles ticks, key presses and window resizes
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		if msg.generation != m.generation || m.paused {
+			return m, nil
+		}
+		m.showNext()
+		return m, m.schedule()
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		for i := range m.rendered {
+			m.rendered[i] = m.render(m.frames[i].Markdown)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case " ":
+			m.paused = !m.paused
+			return m, m.reschedule()
+		case "+", "=":
+			m.speed = clampSpeed(m.speed * 2)
+			return m, m.reschedule()
+		case "-":
+			m.speed = clampSpeed(m.speed / 2)
+			return m, m.reschedule()
+		case "n", "right":
+			m.showNext()
+			return m, m.reschedule()
+		case "up", "k":
+			m.scroll++
+		case "down", "j":
+			if m.scroll > 0 {
+				m.scroll--
+			}
+		}
+	}
+	return m, nil
+}
+
+// View shows the end of the conversation so far above a status line
+func (m Model) View() string {
+	lines := strings.Split(strings.TrimRight(strings.Join(m.rendered, "\n"), "\n"), "\n")
+	visible := max(m.height-1, 1)
+	end := len(lines) - min(m.scroll, max(len(lines)-visible, 0))
+	start := max(end-visible, 0)
+
+	var sb strings.Builder
+	for _, line := range lines[start:end] {
+		sb.WriteString(line + "\n")
+	}
+	for i := end - start; i < visible; i++ {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(m.status())
+	return sb.String()
+}
+
+// status describes the playback and the keys
+func (m Model) status() string {
+	state := "▶"
+	switch {
+	case len(m.rendered) == len(m.frames):
+		state = "■ end"
+	case m.paused:
+		state = "⏸ paused"
+	}
+	status := fmt.Sprintf("%s  %d/%d  %gx  space pause · +/- speed · n next · ↑/↓ scroll · q quit",
+		state, len(m.rendered), len(m.frames), m.speed)
+	if m.plain {
+		return strings.NewReplacer("▶", ">", "■", "#", "⏸", "||", "·", "|", "↑", "up", "↓", "down").Replace(status)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(ansi.Truncate(status, m.width, "…"))
+}
+
+// showNext shows the next frame, if any, and scrolls to the end
+func (m *Model) showNext() {
+	if len(m.rendered) == len(m.frames) {
+		return
+	}
+	m.rendered = append(m.rendered, m.render(m.frames[len(m.rendered)].Markdown))
+	m.scroll = 0
+}
+
+// render renders a frame's markdown for the terminal, or leaves it as is in plain mode
+func (m Model) render(content string) string {
+	if m.plain {
+		return content
+	}
+	out, err := renderMarkdown(m.width, content)
+	if err != nil {
+		return content
+	}
+	return out
+}
+
+// reschedule cancels the scheduled tick and, unless paused, waits for the next frame anew
+func (m *Model) reschedule() tea.Cmd {
+	m.generation++
+	if m.paused {
+		return nil
+	}
+	return m.schedule()
+}
+
+// schedule waits for the next frame at the current speed
+func (m Model) schedule() tea.Cmd {
+	if len(m.rendered) == len(m.frames) {
+		return nil
+	}
+	delay := time.Duration(float64(m.frames[len(m.rendered)].Delay) / m.speed)
+	generation := m.generation
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return tickMsg{generation: generation}
+	})
+}
+
+// clampSpeed limits speed to MinSpeed..MaxSpeed; 0 or less means the original pace
+func clampSpeed(speed float64) float64 {
+	switch {
+	case speed <= 0:
+		return 1
+	case speed < MinSpeed:
+		return MinSpeed
+	case speed > MaxSpeed:
+		return MaxSpeed
+	}
+	return speed
+}
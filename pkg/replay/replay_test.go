@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/annenpolka/cclog/pkg/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewFrames(t *testing.T) {
+	ts := time.Date(2025, 7, 6, 5, 0, 0, 0, time.UTC)
+	messages := []types.Message{
+		{Timestamp: ts},
+		{Timestamp: ts.Add(2 * time.Second)},
+		{Timestamp: ts.Add(10 * time.Hour)},
+		{},
+		{Timestamp: ts}, // Out of order
+	}
+	frames := NewFrames(messages, []string{"a", "b", "c", "d", "e"})
+
+	want := []time.Duration{0, 2 * time.Second, MaxDelay, DefaultDelay, DefaultDelay}
+	for i, frame := range frames {
+		if frame.Delay != want[i] {
+			t.Errorf("Frame %d: expected delay %v, got %v", i, want[i], frame.Delay)
+		}
+	}
+	if frames[2].Markdown != "c" {
+		t.Errorf("Expected frames to keep their markdown, got %q", frames[2].Markdown)
+	}
+}
+
+func newTestModel() Model {
+	m := NewModel([]Frame{{Markdown: "first"}, {Delay: time.Second, Markdown: "second"}, {Delay: time.Second, Markdown: "third"}}, 1)
+	m.SetPlain(true)
+	return m
+}
+
+func update(t *testing.T, m Model, msg tea.Msg) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
+
+func key(s string) tea.KeyMsg {
+	if s == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestModelPlayback(t *testing.T) {
+	m := newTestModel()
+	if m.Init() == nil {
+		t.Fatal("Expected the first message to be scheduled")
+	}
+
+	m, cmd := update(t, m, tickMsg{})
+	if m.Shown() != 1 || cmd == nil {
+		t.Fatalf("Expected the first message shown and the next scheduled, got %d", m.Shown())
+	}
+
+	// Pausing cancels the scheduled tick
+	m, cmd = update(t, m, key(" "))
+	if !m.Paused() || cmd != nil {
+		t.Fatal("Expected space to pause the replay")
+	}
+	m, _ = update(t, m, tickMsg{})
+	if m.Shown() != 1 {
+		t.Errorf("Expected no message while paused, got %d", m.Shown())
+	}
+	if !strings.Contains(m.View(), "|| paused  1/3") {
+		t.Errorf("Expected the status to show the pause, got:\n%s", m.View())
+	}
+
+	// Resuming schedules a new tick and ignores the old one
+	m, cmd = update(t, m, key(" "))
+	if m.Paused() || cmd == nil {
+		t.Fatal("Expected space to resume the replay")
+	}
+	m, _ = update(t, m, tickMsg{generation: m.generation - 1})
+	if m.Shown() != 1 {
+		t.Errorf("Expected a stale tick to be ignored, got %d", m.Shown())
+	}
+	m, _ = update(t, m, tickMsg{generation: m.generation})
+	if m.Shown() != 2 {
+		t.Errorf("Expected the second message, got %d", m.Shown())
+	}
+
+	m, cmd = update(t, m, key("n"))
+	if m.Shown() != 3 || cmd != nil {
+		t.Errorf("Expected n to show the last message with nothing left to schedule, got %d", m.Shown())
+	}
+	view := m.View()
+	for _, want := range []string{"first", "second", "third", "# end  3/3"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the view to contain %q, got:\n%s", want, view)
+		}
+	}
+
+	if _, cmd := update(t, m, key("q")); cmd == nil {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestModelSpeed(t *testing.T) {
+	m := newTestModel()
+	m, _ = update(t, m, key("+"))
+	m, _ = update(t, m, key("+"))
+	if m.Speed() != 4 {
+		t.Errorf("Expected speed 4, got %g", m.Speed())
+	}
+	for i := 0; i < 10; i++ {
+		m, _ = update(t, m, key("-"))
+	}
+	if m.Speed() != MinSpeed {
+		t.Errorf("Expected speed %g, got %g", MinSpeed, m.Speed())
+	}
+	if NewModel(nil, 0).Speed() != 1 || NewModel(nil, 1000).Speed() != MaxSpeed {
+		t.Error("Expected speeds out of range to be clamped")
+	}
+}
+
+func TestModelScroll(t *testing.T) {
+	m := newTestModel()
+	m, _ = update(t, m, tea.WindowSizeMsg{Width: 40, Height: 2})
+	m, _ = update(t, m, key("n"))
+	m, _ = update(t, m, key("n"))
+
+	if view := m.View(); !strings.Contains(view, "second") || strings.Contains(view, "first") {
+		t.Errorf("Expected only the end of the conversation, got:\n%s", view)
+	}
+	m, _ = update(t, m, key("k"))
+	if view := m.View(); !strings.Contains(view, "first") {
+		t.Errorf("Expected scrolling up to show the first message, got:\n%s", view)
+	}
+}
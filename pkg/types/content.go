@@ -0,0 +1,320 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// MessageBody is the message field of a log line: the API message of user and assistant lines
+type MessageBody struct {
+	ID      string  `json:"id,omitempty"`
+	Type    string  `json:"type,omitempty"`
+	Role    string  `json:"role,omitempty"`
+	Model   string  `json:"model,omitempty"`
+	Content Content `json:"content"`
+	// Usage is the token usage of assistant messages
+	Usage *Usage `json:"usage,omitempty"`
+	// Summary is the title held by summary lines
+	Summary string `json:"summary,omitempty"`
+	// ToolUseResult is the tool result metadata of older logs, which keep it inside the message
+	ToolUseResult interface{} `json:"toolUseResult,omitempty"`
+}
+
+// UnmarshalJSON decodes a message object. A plain string, as written by some tools, becomes the text content.
+func (b *MessageBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = MessageBody{Content: TextContent(text)}
+		return nil
+	}
+	type fields MessageBody
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*b = MessageBody(f)
+	return nil
+}
+
+// Blocks returns the content blocks of the message, or nil for plain text messages and a nil body
+func (b *MessageBody) Blocks() []ContentBlock {
+	if b == nil {
+		return nil
+	}
+	return b.Content.Blocks
+}
+
+// Content is the content of a message or a tool result: plain text, or a list of blocks if Blocks is not nil
+type Content struct {
+	Text   string
+	Blocks []ContentBlock
+}
+
+// TextContent returns plain text content
+func TextContent(text string) Content {
+	return Content{Text: text}
+}
+
+// BlockContent returns content made of blocks
+func BlockContent(blocks ...ContentBlock) Content {
+	if blocks == nil {
+		blocks = []ContentBlock{}
+	}
+	return Content{Blocks: blocks}
+}
+
+// IsBlocks reports whether the content is a list of blocks rather than plain text
+func (c Content) IsBlocks() bool {
+	return c.Blocks != nil
+}
+
+// PlainText joins the text blocks of the content by newlines, or returns the text of plain text content
+func (c Content) PlainText() string {
+	if !c.IsBlocks() {
+		return c.Text
+	}
+	var parts []string
+	for _, block := range c.Blocks {
+		if text, ok := block.(TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// UnmarshalJSON decodes a string or an array of blocks. Blocks of unknown types, and blocks that are
+// malformed, are kept as UnknownBlock. Content of any other shape is kept as the text of its JSON, a
+// single object as one block, so one odd message does not fail the whole log.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = Content{Text: text} // Also null
+		return nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			*c = Content{Blocks: []ContentBlock{decodeBlock(data)}}
+		} else {
+			*c = Content{Text: string(trimmed)}
+		}
+		return nil
+	}
+	blocks := make([]ContentBlock, 0, len(raw))
+	for _, item := range raw {
+		blocks = append(blocks, decodeBlock(item))
+	}
+	*c = Content{Blocks: blocks}
+	return nil
+}
+
+// MarshalJSON encodes plain text content as a string and block content as an array
+func (c Content) MarshalJSON() ([]byte, error) {
+	if !c.IsBlocks() {
+		return json.Marshal(c.Text)
+	}
+	return json.Marshal(c.Blocks)
+}
+
+// ContentBlock is one block of block content: TextBlock, ThinkingBlock, ToolUseBlock, ToolResultBlock,
+// ImageBlock or UnknownBlock
+type ContentBlock interface {
+	// BlockType returns the type field of the block, e.g. "text" or "tool_use"
+	BlockType() string
+}
+
+// TextBlock is a block of text
+type TextBlock struct {
+	Text string `json:"text"`
+}
+
+// ThinkingBlock holds the extended thinking of an assistant message
+type ThinkingBlock struct {
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// ToolUseBlock is a tool call of the assistant
+type ToolUseBlock struct {
+	ID    string                 `json:"id"`
+	Name  string                 `json:"name"`
+	Input map[string]interface{} `json:"input"`
+}
+
+// ToolResultBlock is the output of a tool call, sent back in a user message
+type ToolResultBlock struct {
+	ToolUseID string  `json:"tool_use_id"`
+	Content   Content `json:"content"`
+	IsError   bool    `json:"is_error,omitempty"`
+	// ToolUseResult is the toolUseResult metadata of the result's line, set when the block is moved
+	// out of that line, e.g. next to its tool call
+	ToolUseResult interface{} `json:"toolUseResult,omitempty"`
+}
+
+// ImageBlock is an image attached to a prompt or returned by a tool
+type ImageBlock struct {
+	Source ImageSource `json:"source"`
+}
+
+// ImageSource is the data of an image block: base64 data with its media type, or a URL
+type ImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// UnknownBlock keeps the decoded fields, including "type", of blocks cclog has no type for
+type UnknownBlock struct {
+	Fields map[string]interface{}
+}
+
+// BlockType implements ContentBlock
+func (TextBlock) BlockType() string { return "text" }
+
+// BlockType implements ContentBlock
+func (ThinkingBlock) BlockType() string { return "thinking" }
+
+// BlockType implements ContentBlock
+func (ToolUseBlock) BlockType() string { return "tool_use" }
+
+// BlockType implements ContentBlock
+func (ToolResultBlock) BlockType() string { return "tool_result" }
+
+// BlockType implements ContentBlock
+func (ImageBlock) BlockType() string { return "image" }
+
+// BlockType implements ContentBlock
+func (b UnknownBlock) BlockType() string {
+	blockType, _ := b.Fields["type"].(string)
+	return blockType
+}
+
+// MarshalJSON adds the type field to the block
+func (b TextBlock) MarshalJSON() ([]byte, error) {
+	type fields TextBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fields
+	}{b.BlockType(), fields(b)})
+}
+
+// MarshalJSON adds the type field to the block
+func (b ThinkingBlock) MarshalJSON() ([]byte, error) {
+	type fields ThinkingBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fields
+	}{b.BlockType(), fields(b)})
+}
+
+// MarshalJSON adds the type field to the block
+func (b ToolUseBlock) MarshalJSON() ([]byte, error) {
+	type fields ToolUseBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fields
+	}{b.BlockType(), fields(b)})
+}
+
+// MarshalJSON adds the type field to the block
+func (b ToolResultBlock) MarshalJSON() ([]byte, error) {
+	type fields ToolResultBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fields
+	}{b.BlockType(), fields(b)})
+}
+
+// MarshalJSON adds the type field to the block
+func (b ImageBlock) MarshalJSON() ([]byte, error) {
+	type fields ImageBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		fields
+	}{b.BlockType(), fields(b)})
+}
+
+// MarshalJSON encodes the fields of the block as they were decoded
+func (b UnknownBlock) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Fields)
+}
+
+// decodeBlock decodes one content block by its type field. A block that does not decode as its type
+// is kept as an UnknownBlock of its fields, and one that is not an object as an UnknownBlock whose
+// "value" field holds it.
+func decodeBlock(data []byte) ContentBlock {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return malformedBlock(data)
+	}
+
+	var block ContentBlock
+	var err error
+	switch header.Type {
+	case "text":
+		var b TextBlock
+		err = json.Unmarshal(data, &b)
+		block = b
+	case "thinking":
+		var b ThinkingBlock
+		err = json.Unmarshal(data, &b)
+		block = b
+	case "tool_use":
+		var b ToolUseBlock
+		err = json.Unmarshal(data, &b)
+		block = b
+	case "tool_result":
+		var b ToolResultBlock
+		err = json.Unmarshal(data, &b)
+		block = b
+	case "image":
+		var b ImageBlock
+		err = json.Unmarshal(data, &b)
+		block = b
+	default:
+		var b UnknownBlock
+		err = json.Unmarshal(data, &b.Fields)
+		if b.Fields != nil { // Nil for null
+			block = b
+		}
+	}
+	if err != nil || block == nil {
+		return malformedBlock(data)
+	}
+	return block
+}
+
+// malformedBlock keeps a block that does not decode as its type as an UnknownBlock
+func malformedBlock(data []byte) UnknownBlock {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err == nil && fields != nil {
+		return UnknownBlock{Fields: fields}
+	}
+	var value interface{}
+	json.Unmarshal(data, &value)
+	return UnknownBlock{Fields: map[string]interface{}{"value": value}}
+}
+
+// ExtractTextContent extracts the plain text content from a message's message field
+func ExtractTextContent(message *MessageBody) string {
+	if message == nil {
+		return ""
+	}
+	return message.Content.PlainText()
+}
+
+// ExtractThinking extracts the text of the thinking blocks in a message's message field
+func ExtractThinking(message *MessageBody) string {
+	var parts []string
+	for _, block := range message.Blocks() {
+		if thinking, ok := block.(ThinkingBlock); ok && strings.TrimSpace(thinking.Thinking) != "" {
+			parts = append(parts, thinking.Thinking)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
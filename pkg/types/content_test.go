@@ -0,0 +1,164 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractTextContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *MessageBody
+		want    string
+	}{
+		{
+			name:    "nil message",
+			message: nil,
+			want:    "",
+		},
+		{
+			name:    "string content",
+			message: &MessageBody{Role: "user", Content: TextContent("Hello")},
+			want:    "Hello",
+		},
+		{
+			name: "array content joins text blocks",
+			message: &MessageBody{Role: "assistant", Content: BlockContent(
+				TextBlock{Text: "First"},
+				ToolUseBlock{Name: "Bash"},
+				TextBlock{Text: "Second"},
+			)},
+			want: "First\nSecond",
+		},
+		{
+			name:    "missing content field",
+			message: &MessageBody{Role: "user"},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractTextContent(tt.message)
+			if got != tt.want {
+				t.Errorf("ExtractTextContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractThinking(t *testing.T) {
+	message := &MessageBody{Role: "assistant", Content: BlockContent(
+		ThinkingBlock{Thinking: "Check the tests first"},
+		TextBlock{Text: "Running the tests"},
+		ThinkingBlock{Thinking: "  "},
+	)}
+	if got := ExtractThinking(message); got != "Check the tests first" {
+		t.Errorf("ExtractThinking() = %q", got)
+	}
+	if got := ExtractThinking(&MessageBody{Content: TextContent("plain")}); got != "" {
+		t.Errorf("Expected no thinking in string content, got %q", got)
+	}
+}
+
+func TestMessageBodyUnmarshalJSON(t *testing.T) {
+	data := `{"role":"assistant","model":"claude-sonnet-4","content":[
+		{"type":"text","text":"Listing files"},
+		{"type":"thinking","thinking":"ls is enough","signature":"sig"},
+		{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}},
+		{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"a.go"}],"is_error":true},
+		{"type":"image","source":{"type":"base64","media_type":"image/png","data":"aGk="}},
+		{"type":"server_tool_use","id":"s1"}
+	]}`
+	var body MessageBody
+	if err := json.Unmarshal([]byte(data), &body); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if body.Role != "assistant" || body.Model != "claude-sonnet-4" || len(body.Blocks()) != 6 {
+		t.Fatalf("Unexpected body %+v", body)
+	}
+
+	blocks := body.Blocks()
+	if text, ok := blocks[0].(TextBlock); !ok || text.Text != "Listing files" {
+		t.Errorf("Expected a text block, got %#v", blocks[0])
+	}
+	if thinking, ok := blocks[1].(ThinkingBlock); !ok || thinking.Thinking != "ls is enough" || thinking.Signature != "sig" {
+		t.Errorf("Expected a thinking block, got %#v", blocks[1])
+	}
+	if use, ok := blocks[2].(ToolUseBlock); !ok || use.ID != "t1" || use.Name != "Bash" || use.Input["command"] != "ls" {
+		t.Errorf("Expected a tool_use block, got %#v", blocks[2])
+	}
+	if result, ok := blocks[3].(ToolResultBlock); !ok || result.ToolUseID != "t1" || !result.IsError || result.Content.PlainText() != "a.go" {
+		t.Errorf("Expected a tool_result block, got %#v", blocks[3])
+	}
+	if image, ok := blocks[4].(ImageBlock); !ok || image.Source.MediaType != "image/png" || image.Source.Data != "aGk=" {
+		t.Errorf("Expected an image block, got %#v", blocks[4])
+	}
+	if unknown, ok := blocks[5].(UnknownBlock); !ok || unknown.BlockType() != "server_tool_use" || unknown.Fields["id"] != "s1" {
+		t.Errorf("Expected an unknown block, got %#v", blocks[5])
+	}
+
+	// Encoding gives back the same blocks
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded MessageBody
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal of %s failed: %v", encoded, err)
+	}
+	for i, block := range decoded.Blocks() {
+		if block.BlockType() != blocks[i].BlockType() {
+			t.Errorf("Block %d: got type %q after a round trip, want %q", i, block.BlockType(), blocks[i].BlockType())
+		}
+	}
+}
+
+func TestMessageBodyUnmarshalJSONString(t *testing.T) {
+	var body MessageBody
+	if err := json.Unmarshal([]byte(`"test"`), &body); err != nil || ExtractTextContent(&body) != "test" {
+		t.Errorf("Expected a string message to become text content, got %+v, %v", body, err)
+	}
+}
+
+func TestContentUnmarshalJSON(t *testing.T) {
+	var content Content
+	if err := json.Unmarshal([]byte(`"Hello"`), &content); err != nil || content.IsBlocks() || content.Text != "Hello" {
+		t.Errorf("Expected plain text content, got %+v, %v", content, err)
+	}
+	if err := json.Unmarshal([]byte(`[]`), &content); err != nil || !content.IsBlocks() {
+		t.Errorf("Expected empty block content, got %+v, %v", content, err)
+	}
+	if err := json.Unmarshal([]byte(`42`), &content); err != nil || content.IsBlocks() || content.Text != "42" {
+		t.Errorf("Expected a number to be kept as text, got %+v, %v", content, err)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"text","text":"Hi"}`), &content); err != nil || content.PlainText() != "Hi" {
+		t.Errorf("Expected an object to be kept as a block, got %+v, %v", content, err)
+	}
+}
+
+func TestContentUnmarshalJSONMalformedBlocks(t *testing.T) {
+	var content Content
+	data := `[{"type":"text","text":1},{"type":"tool_use","id":"t1","name":"Bash","input":"ls"},"stray",null,{"type":"text","text":"kept"}]`
+	if err := json.Unmarshal([]byte(data), &content); err != nil {
+		t.Fatalf("Expected malformed blocks not to fail the content: %v", err)
+	}
+	if len(content.Blocks) != 5 {
+		t.Fatalf("Expected 5 blocks, got %d", len(content.Blocks))
+	}
+	if unknown, ok := content.Blocks[0].(UnknownBlock); !ok || unknown.BlockType() != "text" {
+		t.Errorf("Expected a malformed text block to be kept as an unknown block, got %#v", content.Blocks[0])
+	}
+	if unknown, ok := content.Blocks[1].(UnknownBlock); !ok || unknown.Fields["input"] != "ls" {
+		t.Errorf("Expected a tool_use block with string input to be kept as an unknown block, got %#v", content.Blocks[1])
+	}
+	if unknown, ok := content.Blocks[2].(UnknownBlock); !ok || unknown.Fields["value"] != "stray" {
+		t.Errorf("Expected a string block to be kept as an unknown block, got %#v", content.Blocks[2])
+	}
+	if _, ok := content.Blocks[3].(UnknownBlock); !ok {
+		t.Errorf("Expected a null block to be kept as an unknown block, got %#v", content.Blocks[3])
+	}
+	if content.PlainText() != "kept" {
+		t.Errorf("PlainText = %q, want the text of the well-formed block", content.PlainText())
+	}
+}
@@ -6,19 +6,21 @@ import (
 
 // Message represents a single message in the JSONL conversation log
 type Message struct {
-	ParentUUID    *string     `json:"parentUuid"`
-	IsSidechain   bool        `json:"isSidechain"`
-	UserType      string      `json:"userType"`
-	CWD           string      `json:"cwd"`
-	SessionID     string      `json:"sessionId"`
-	Version       string      `json:"version"`
-	Type          string      `json:"type"`
-	Message       interface{} `json:"message"`
-	IsMeta        bool        `json:"isMeta,omitempty"`
-	UUID          string      `json:"uuid"`
-	Timestamp     time.Time   `json:"timestamp"`
-	RequestID     string      `json:"requestId,omitempty"`
-	ToolUseResult interface{} `json:"toolUseResult,omitempty"`
+	ParentUUID    *string      `json:"parentUuid"`
+	IsSidechain   bool         `json:"isSidechain"`
+	UserType      string       `json:"userType"`
+	CWD           string       `json:"cwd"`
+	SessionID     string       `json:"sessionId"`
+	Version       string       `json:"version"`
+	Type          string       `json:"type"`
+	Message       *MessageBody `json:"message"`
+	IsMeta        bool         `json:"isMeta,omitempty"`
+	UUID          string       `json:"uuid"`
+	Timestamp     time.Time    `json:"timestamp"`
+	RequestID     string       `json:"requestId,omitempty"`
+	ToolUseResult interface{}  `json:"toolUseResult,omitempty"`
+	// IsAPIErrorMessage marks the messages Claude Code writes when an API request fails
+	IsAPIErrorMessage bool `json:"isApiErrorMessage,omitempty"`
 }
 
 // ConversationLog represents a collection of messages from a JSONL file
@@ -27,12 +29,6 @@ type ConversationLog struct {
 	FilePath string    `json:"filePath"`
 }
 
-// ClaudeMessage represents the structure of Claude's message content
-type ClaudeMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
 // Summary represents conversation summary information
 type Summary struct {
 	Type     string `json:"type"`
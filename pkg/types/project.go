@@ -0,0 +1,33 @@
+package types
+
+import (
+	"path"
+	"path/filepath"
+)
+
+// ProjectName names the project of a conversation after the base name of the first working directory
+// recorded in its messages, e.g. "cclog" for /home/me/src/cclog. It returns "" if no message has one.
+func ProjectName(log *ConversationLog) string {
+	if log == nil {
+		return ""
+	}
+	for _, msg := range log.Messages {
+		if msg.CWD == "" {
+			continue
+		}
+		if name := filepath.Base(filepath.Clean(msg.CWD)); name != "/" && name != "." {
+			return name
+		}
+	}
+	return ""
+}
+
+// MatchProject reports whether project matches pattern, either exactly or as a glob such as "cclog-*".
+// An empty pattern matches every project.
+func MatchProject(pattern, project string) bool {
+	if pattern == "" || pattern == project {
+		return true
+	}
+	ok, _ := path.Match(pattern, project)
+	return ok
+}
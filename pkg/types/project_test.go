@@ -0,0 +1,44 @@
+package types
+
+import "testing"
+
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		want     string
+	}{
+		{"first working directory", []Message{{CWD: ""}, {CWD: "/home/me/src/cclog/"}, {CWD: "/tmp/other"}}, "cclog"},
+		{"root is not a project", []Message{{CWD: "/"}, {CWD: "/srv/app"}}, "app"},
+		{"no working directory", []Message{{Type: "summary"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := ProjectName(&ConversationLog{Messages: tt.messages}); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := ProjectName(nil); got != "" {
+		t.Errorf("Expected no project for a nil log, got %q", got)
+	}
+}
+
+func TestMatchProject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		project string
+		want    bool
+	}{
+		{"", "cclog", true},
+		{"cclog", "cclog", true},
+		{"cclog", "cclog-web", false},
+		{"cclog*", "cclog-web", true},
+		{"cc?og", "cclog", true},
+		{"web", "", false},
+		{"[", "[", true},
+	}
+	for _, tt := range tests {
+		if got := MatchProject(tt.pattern, tt.project); got != tt.want {
+			t.Errorf("MatchProject(%q, %q) = %v, want %v", tt.pattern, tt.project, got, tt.want)
+		}
+	}
+}
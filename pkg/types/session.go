@@ -0,0 +1,27 @@
+package types
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SessionID returns the sessionId recorded in the messages of a conversation, or the name of its file
+// without the .jsonl extension (and the .gz or .zst of compressed logs), which Claude Code names after the sessionId. It returns "" if neither is known.
+func SessionID(log *ConversationLog) string {
+	if log == nil {
+		return ""
+	}
+	for _, msg := range log.Messages {
+		if msg.SessionID != "" {
+			return msg.SessionID
+		}
+	}
+	if log.FilePath == "" {
+		return ""
+	}
+	name := filepath.Base(log.FilePath)
+	for _, ext := range []string{".gz", ".zst"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,21 @@
+package types
+
+import "testing"
+
+func TestSessionID(t *testing.T) {
+	tests := []struct {
+		name string
+		log  *ConversationLog
+		want string
+	}{
+		{"recorded in the messages", &ConversationLog{FilePath: "/logs/renamed.jsonl", Messages: []Message{{Type: "summary"}, {SessionID: "abc-123"}}}, "abc-123"},
+		{"file name", &ConversationLog{FilePath: "/logs/def-456.jsonl", Messages: []Message{{Type: "summary"}}}, "def-456"},
+		{"unknown", &ConversationLog{}, ""},
+		{"nil log", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := SessionID(tt.log); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
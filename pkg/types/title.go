@@ -1,8 +1,8 @@
 package types
 
 import (
-	"encoding/json"
 	"strings"
+	"unicode"
 )
 
 const (
@@ -51,83 +51,28 @@ func extractTitleFromSummary(msg Message) string {
 	if msg.Message == nil {
 		return ""
 	}
-
-	// Try to parse as Summary struct
-	if summaryMap, ok := msg.Message.(map[string]any); ok {
-		if summaryText, exists := summaryMap["summary"]; exists {
-			if title, ok := summaryText.(string); ok {
-				return title
-			}
-		}
-	}
-
-	// Try to parse as JSON
-	if msgBytes, err := json.Marshal(msg.Message); err == nil {
-		var summary Summary
-		if err := json.Unmarshal(msgBytes, &summary); err == nil {
-			return summary.Summary
-		}
-	}
-
-	return ""
+	return msg.Message.Summary
 }
 
-// extractTitleFromUserMessage extracts title from user message
+// extractTitleFromUserMessage extracts title from user message: its text,
+// or the first text block or plain text tool result
 func extractTitleFromUserMessage(msg Message) string {
 	if msg.Message == nil {
 		return ""
 	}
-
-	// Try to parse as map
-	if msgMap, ok := msg.Message.(map[string]any); ok {
-		if content, exists := msgMap["content"]; exists {
-			// Handle string content
-			if title, ok := content.(string); ok {
-				return title
-			}
-
-			// Handle array content
-			if contentArray, ok := content.([]interface{}); ok {
-				return extractTitleFromArrayContent(contentArray)
-			}
-		}
-	}
-
-	// Try to parse as JSON
-	if msgBytes, err := json.Marshal(msg.Message); err == nil {
-		var claudeMsg ClaudeMessage
-		if err := json.Unmarshal(msgBytes, &claudeMsg); err == nil {
-			return claudeMsg.Content
-		}
+	content := msg.Message.Content
+	if !content.IsBlocks() {
+		return content.Text
 	}
-
-	return ""
-}
-
-// extractTitleFromArrayContent extracts title from array-based content
-func extractTitleFromArrayContent(contentArray []interface{}) string {
-	for _, item := range contentArray {
-		if itemMap, ok := item.(map[string]interface{}); ok {
-			// Handle text type blocks
-			if itemType, exists := itemMap["type"]; exists {
-				if itemType == "text" {
-					if text, exists := itemMap["text"]; exists {
-						if textStr, ok := text.(string); ok && textStr != "" {
-							return textStr
-						}
-					}
-				}
+	for _, block := range content.Blocks {
+		switch b := block.(type) {
+		case TextBlock:
+			if b.Text != "" {
+				return b.Text
 			}
-
-			// Handle tool_result type blocks
-			if itemType, exists := itemMap["type"]; exists {
-				if itemType == "tool_result" {
-					if content, exists := itemMap["content"]; exists {
-						if contentStr, ok := content.(string); ok && contentStr != "" {
-							return contentStr
-						}
-					}
-				}
+		case ToolResultBlock:
+			if !b.Content.IsBlocks() && b.Content.Text != "" {
+				return b.Content.Text
 			}
 		}
 	}
@@ -164,3 +109,34 @@ func TruncateTitle(title string, width ...int) string {
 	truncated := string(runes[:w-len(ellipsisRunes)])
 	return truncated + ellipsis
 }
+
+// SlugifyTitle turns a title into a file name component: lowercase letters and digits (including non-ASCII)
+// separated by single hyphens, cut to at most maxRunes runes. An empty result becomes "conversation".
+func SlugifyTitle(title string, maxRunes int) string {
+	var sb strings.Builder
+	pendingHyphen := false
+	count := 0
+	for _, r := range strings.ToLower(title) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = count > 0
+			continue
+		}
+		if pendingHyphen {
+			if count+1 >= maxRunes {
+				break
+			}
+			sb.WriteRune('-')
+			count++
+			pendingHyphen = false
+		}
+		if count >= maxRunes {
+			break
+		}
+		sb.WriteRune(r)
+		count++
+	}
+	if sb.Len() == 0 {
+		return "conversation"
+	}
+	return sb.String()
+}
@@ -16,7 +16,7 @@ func TestExtractTitle(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "summary",
-					Message:   map[string]interface{}{"type": "summary", "summary": "User requested Go CLI tool development using TDD"},
+					Message:   &MessageBody{Type: "summary", Summary: "User requested Go CLI tool development using TDD"},
 					Timestamp: time.Now(),
 				},
 			},
@@ -27,7 +27,7 @@ func TestExtractTitle(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "goでこれらを人間が読みやすいmarkdownにパースするコマンドラインツールを作る"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("goでこれらを人間が読みやすいmarkdownにパースするコマンドラインツールを作る")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -38,7 +38,7 @@ func TestExtractTitle(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "これは非常に長いタイトルのテストです。２０文字を超えるタイトルは適切に切り詰められるべきです。"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("これは非常に長いタイトルのテストです。２０文字を超えるタイトルは適切に切り詰められるべきです。")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -49,7 +49,7 @@ func TestExtractTitle(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "system",
-					Message:   map[string]interface{}{"role": "system", "content": "System message"},
+					Message:   &MessageBody{Role: "system", Content: TextContent("System message")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -88,7 +88,7 @@ func TestExtractTitle_NoTruncation(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "これは非常に長いタイトルのテストです。２０文字を超えるタイトルでも完全に返されるべきです。動的文字制限システムが適切に動作するように。"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("これは非常に長いタイトルのテストです。２０文字を超えるタイトルでも完全に返されるべきです。動的文字制限システムが適切に動作するように。")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -99,7 +99,7 @@ func TestExtractTitle_NoTruncation(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "summary",
-					Message:   map[string]interface{}{"type": "summary", "summary": "User requested Go CLI tool development using Test-Driven Development methodology with comprehensive test coverage"},
+					Message:   &MessageBody{Type: "summary", Summary: "User requested Go CLI tool development using Test-Driven Development methodology with comprehensive test coverage"},
 					Timestamp: time.Now(),
 				},
 			},
@@ -175,7 +175,7 @@ func TestExtractTitle_NewlineReplacement(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "これは改行を含む\nタイトルのテストです"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("これは改行を含む\nタイトルのテストです")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -186,7 +186,7 @@ func TestExtractTitle_NewlineReplacement(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "複数の\n改行が\n含まれる\nタイトル"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("複数の\n改行が\n含まれる\nタイトル")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -197,7 +197,7 @@ func TestExtractTitle_NewlineReplacement(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "user",
-					Message:   map[string]interface{}{"role": "user", "content": "CRLF改行\r\nテスト"},
+					Message:   &MessageBody{Role: "user", Content: TextContent("CRLF改行\r\nテスト")},
 					Timestamp: time.Now(),
 				},
 			},
@@ -208,7 +208,7 @@ func TestExtractTitle_NewlineReplacement(t *testing.T) {
 			messages: []Message{
 				{
 					Type:      "summary",
-					Message:   map[string]interface{}{"type": "summary", "summary": "改行を含む\nサマリーの\nテスト"},
+					Message:   &MessageBody{Type: "summary", Summary: "改行を含む\nサマリーの\nテスト"},
 					Timestamp: time.Now(),
 				},
 			},
@@ -240,16 +240,8 @@ func TestExtractTitle_ArrayContent(t *testing.T) {
 			name: "User message with array content containing text",
 			messages: []Message{
 				{
-					Type: "user",
-					Message: map[string]interface{}{
-						"role": "user",
-						"content": []interface{}{
-							map[string]interface{}{
-								"type": "text",
-								"text": "goでこれらを人間が読みやすいmarkdownにパースするコマンドラインツールを作る",
-							},
-						},
-					},
+					Type:      "user",
+					Message:   &MessageBody{Role: "user", Content: BlockContent(TextBlock{Text: "goでこれらを人間が読みやすいmarkdownにパースするコマンドラインツールを作る"})},
 					Timestamp: time.Now(),
 				},
 			},
@@ -260,19 +252,10 @@ func TestExtractTitle_ArrayContent(t *testing.T) {
 			messages: []Message{
 				{
 					Type: "user",
-					Message: map[string]interface{}{
-						"role": "user",
-						"content": []interface{}{
-							map[string]interface{}{
-								"type": "text",
-								"text": "最初のテキストブロック",
-							},
-							map[string]interface{}{
-								"type": "text",
-								"text": "二番目のテキストブロック",
-							},
-						},
-					},
+					Message: &MessageBody{Role: "user", Content: BlockContent(
+						TextBlock{Text: "最初のテキストブロック"},
+						TextBlock{Text: "二番目のテキストブロック"},
+					)},
 					Timestamp: time.Now(),
 				},
 			},
@@ -282,17 +265,8 @@ func TestExtractTitle_ArrayContent(t *testing.T) {
 			name: "User message with array content containing tool result",
 			messages: []Message{
 				{
-					Type: "user",
-					Message: map[string]interface{}{
-						"role": "user",
-						"content": []interface{}{
-							map[string]interface{}{
-								"tool_use_id": "toolu_012QmdQYhr9RbGGztxjfpYmd",
-								"type":        "tool_result",
-								"content":     "- /Users/annenpolka/junks/cclog/",
-							},
-						},
-					},
+					Type:      "user",
+					Message:   &MessageBody{Role: "user", Content: BlockContent(ToolResultBlock{ToolUseID: "toolu_012QmdQYhr9RbGGztxjfpYmd", Content: TextContent("- /Users/annenpolka/junks/cclog/")})},
 					Timestamp: time.Now(),
 				},
 			},
@@ -302,16 +276,8 @@ func TestExtractTitle_ArrayContent(t *testing.T) {
 			name: "User message with array content but no text blocks should fallback",
 			messages: []Message{
 				{
-					Type: "user",
-					Message: map[string]interface{}{
-						"role": "user",
-						"content": []interface{}{
-							map[string]interface{}{
-								"type": "tool_use",
-								"name": "LS",
-							},
-						},
-					},
+					Type:      "user",
+					Message:   &MessageBody{Role: "user", Content: BlockContent(ToolUseBlock{Name: "LS"})},
 					Timestamp: time.Now(),
 				},
 			},
@@ -398,3 +364,24 @@ func TestTruncateTitleWithSpecificWidth(t *testing.T) {
 		})
 	}
 }
+
+func TestSlugifyTitle(t *testing.T) {
+	tests := []struct {
+		title    string
+		maxRunes int
+		want     string
+	}{
+		{"Fix the Parser!", 50, "fix-the-parser"},
+		{"  leading and trailing  ", 50, "leading-and-trailing"},
+		{"パーサーを直す", 50, "パーサーを直す"},
+		{"a/b\\c:d", 50, "a-b-c-d"},
+		{"!!!", 50, "conversation"},
+		{"one two three", 7, "one-two"},
+		{"one two three", 8, "one-two"},
+	}
+	for _, tt := range tests {
+		if got := SlugifyTitle(tt.title, tt.maxRunes); got != tt.want {
+			t.Errorf("SlugifyTitle(%q, %d) = %q, want %q", tt.title, tt.maxRunes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+// Usage is the token usage the API reported for an assistant message
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// Total returns the number of tokens of all kinds
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:              u.InputTokens + other.InputTokens,
+		OutputTokens:             u.OutputTokens + other.OutputTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens + other.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens + other.CacheReadInputTokens,
+	}
+}
+
+// SumUsage adds up the usage of the messages. Claude Code writes each content block of an answer on
+// its own line, repeating the usage of the whole answer, so lines with the same message ID are counted once.
+func SumUsage(messages []Message) Usage {
+	var total Usage
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		if msg.Message == nil || msg.Message.Usage == nil {
+			continue
+		}
+		if id := msg.Message.ID; id != "" {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		total = total.Add(*msg.Message.Usage)
+	}
+	return total
+}
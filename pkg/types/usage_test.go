@@ -0,0 +1,33 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSumUsage(t *testing.T) {
+	lines := []string{
+		// Two content blocks of the same answer repeat its usage
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"a"}],"usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}}],"usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`{"type":"assistant","message":{"id":"msg_2","role":"assistant","content":"b","usage":{"input_tokens":1,"output_tokens":2}}}`,
+		`{"type":"user","message":{"role":"user","content":"no usage"}}`,
+	}
+	var messages []Message
+	for _, line := range lines {
+		var msg Message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("Failed to parse %s: %v", line, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	got := SumUsage(messages)
+	want := Usage{InputTokens: 11, OutputTokens: 7, CacheCreationInputTokens: 100, CacheReadInputTokens: 1000}
+	if got != want {
+		t.Errorf("SumUsage() = %+v, want %+v", got, want)
+	}
+	if got.Total() != 1118 {
+		t.Errorf("Total() = %d, want 1118", got.Total())
+	}
+}